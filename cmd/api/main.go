@@ -1,12 +1,15 @@
 package main
 
 import (
+	"context"
 	"log"
 
 	"github.com/gin-gonic/gin"
 	"github.com/mihaamiharu/test-case-management-be/internal/api"
 	"github.com/mihaamiharu/test-case-management-be/internal/config"
 	"github.com/mihaamiharu/test-case-management-be/internal/db"
+	"github.com/mihaamiharu/test-case-management-be/internal/events"
+	"github.com/mihaamiharu/test-case-management-be/internal/mail"
 	"github.com/mihaamiharu/test-case-management-be/internal/repository"
 	"github.com/mihaamiharu/test-case-management-be/internal/service"
 	"github.com/mihaamiharu/test-case-management-be/internal/services"
@@ -28,30 +31,136 @@ func main() {
 
 	// Initialize repositories
 	userRepo := repository.NewUserRepository(database)
+	sessionRepo := repository.NewSessionRepository(database)
 	projectRepo := repository.NewProjectRepository(database)
 	projectAccessRepo := repository.NewProjectAccessRepository(database)
 	testSuiteRepo := repository.NewTestSuiteRepository(database)
 	testCaseRepo := repository.NewTestCaseRepository(database)
 	tagRepo := repository.NewTagRepository(database)
+	sharedStepRepo := repository.NewSharedStepRepository(database)
+	testExecutionRepo := repository.NewTestExecutionRepository(database)
+	commentRepo := repository.NewCommentRepository(database)
+	reviewRepo := repository.NewReviewRepository(database)
+	customFieldRepo := repository.NewCustomFieldRepository(database)
+	workflowRepo := repository.NewWorkflowRepository(database)
+	auditRepo := repository.NewAuditRepository(database)
+	trashRepo := repository.NewTrashRepository(database)
+	archiveRepo := repository.NewArchiveRepository(database)
+	apiTokenRepo := repository.NewAPITokenRepository(database)
+	userTokenRepo := repository.NewUserTokenRepository(database)
+	loginThrottleRepo := repository.NewLoginThrottleRepository(database)
+	twoFactorRepo := repository.NewTwoFactorRepository(database)
+	teamRepo := repository.NewTeamRepository(database)
+	organizationRepo := repository.NewOrganizationRepository(database)
+	defectRepo := repository.NewDefectRepository(database)
+	webhookRepo := repository.NewWebhookRepository(database)
+	notificationRepo := repository.NewNotificationRepository(database)
+	issueTrackerRepo := repository.NewIssueTrackerRepository(database)
+
+	// Initialize the mail sender
+	mailer, err := mail.NewSender(cfg.Mail)
+	if err != nil {
+		log.Fatalf("Failed to configure mail: %v", err)
+	}
+
+	// Initialize the realtime event broker
+	broker, err := events.NewBroker(cfg.Events)
+	if err != nil {
+		log.Fatalf("Failed to configure events: %v", err)
+	}
+
+	projectAccessService := services.NewProjectAccessService(projectAccessRepo, projectRepo, userRepo, teamRepo, archiveRepo, organizationRepo)
+
+	// Events reach realtime subscribers, the webhooks of their project and the users they concern
+	webhookService := service.NewWebhookService(webhookRepo, cfg)
+	notificationService, err := service.NewNotificationService(notificationRepo, testCaseRepo, userRepo, projectRepo, projectAccessService, mailer, cfg)
+	if err != nil {
+		log.Fatalf("Failed to configure notifications: %v", err)
+	}
+	publisher := events.Publishers{broker, webhookService, notificationService}
 
 	// Initialize services
-	authService := service.NewAuthService(userRepo, cfg)
+	twoFactorService := service.NewTwoFactorService(twoFactorRepo, userRepo, cfg)
+	authService := service.NewAuthService(userRepo, sessionRepo, twoFactorService, cfg)
 	projectService := services.NewProjectService(projectRepo)
-	projectAccessService := services.NewProjectAccessService(projectAccessRepo, projectRepo, userRepo)
-	testCaseService := service.NewTestCaseService(testCaseRepo, tagRepo)
+	testCaseService := service.NewTestCaseService(testCaseRepo, tagRepo, sharedStepRepo, reviewRepo, customFieldRepo, userRepo, projectRepo, organizationRepo, workflowRepo, publisher)
 	testSuiteService := service.NewTestSuiteService(testSuiteRepo)
 	tagService := service.NewTagService(tagRepo)
+	sharedStepService := service.NewSharedStepService(sharedStepRepo)
+	testExecutionService := service.NewTestExecutionService(testExecutionRepo, testCaseService, publisher)
+	commentService := service.NewCommentService(commentRepo, testCaseRepo, userRepo, publisher)
+	reviewService := service.NewReviewService(reviewRepo, testCaseRepo, userRepo, commentService, workflowRepo)
+	customFieldService := service.NewCustomFieldService(customFieldRepo)
+	workflowService := service.NewWorkflowService(workflowRepo)
+	auditService := service.NewAuditService(auditRepo)
+	trashService := service.NewTrashService(trashRepo, cfg.TrashRetention)
+	archiveService := service.NewArchiveService(archiveRepo, projectRepo, testSuiteRepo)
+	apiTokenService := service.NewAPITokenService(apiTokenRepo, userRepo, archiveRepo)
+	accountService := service.NewAccountService(userRepo, userTokenRepo, sessionRepo, mailer, cfg)
+	userService := service.NewUserService(userRepo, sessionRepo, accountService, cfg)
+	loginThrottleService := service.NewLoginThrottleService(loginThrottleRepo, cfg)
+	teamService := service.NewTeamService(teamRepo, organizationRepo)
+	defectService := service.NewDefectService(defectRepo, testExecutionRepo, publisher)
+	issueTrackerService := service.NewIssueTrackerService(issueTrackerRepo, defectRepo, cfg)
+	organizationService := service.NewOrganizationService(organizationRepo, userRepo, projectRepo)
+	oidcService, err := service.NewOIDCService(cfg, userRepo, projectRepo, projectAccessRepo, organizationRepo)
+	if err != nil {
+		log.Fatalf("Failed to configure single sign-on: %v", err)
+	}
 
 	// Initialize handlers
-	authHandler := api.NewAuthHandler(authService)
+	authHandler := api.NewAuthHandler(authService, apiTokenService, oidcService, accountService)
 	projectHandler := api.NewProjectHandler(projectService, projectAccessService)
 	testSuiteHandler := api.NewTestSuiteHandler(testSuiteService)
 	testCaseHandler := api.NewTestCaseHandler(testCaseService)
 	tagHandler := api.NewTagHandler(tagService)
+	sharedStepHandler := api.NewSharedStepHandler(sharedStepService)
+	testExecutionHandler := api.NewTestExecutionHandler(testExecutionService, projectAccessService)
+	commentHandler := api.NewCommentHandler(commentService, projectAccessService)
+	reviewHandler := api.NewReviewHandler(reviewService, projectAccessService)
+	customFieldHandler := api.NewCustomFieldHandler(customFieldService, projectAccessService)
+	workflowHandler := api.NewWorkflowHandler(workflowService, projectAccessService)
+	auditHandler := api.NewAuditHandler(auditService, projectAccessService)
+	trashHandler := api.NewTrashHandler(trashService, projectService, projectAccessService)
+	archiveHandler := api.NewArchiveHandler(archiveService, testSuiteService, projectAccessService)
+	apiTokenHandler := api.NewAPITokenHandler(apiTokenService, projectAccessService)
+	userHandler := api.NewUserHandler(userService)
+	accountHandler := api.NewAccountHandler(accountService)
+	loginProtectionHandler := api.NewLoginProtectionHandler(loginThrottleService, authService, userService, auditService)
+	twoFactorHandler := api.NewTwoFactorHandler(twoFactorService, authService)
+	projectAccessHandler := api.NewProjectAccessHandler(projectAccessService, projectService)
+	teamHandler := api.NewTeamHandler(teamService)
+	organizationHandler := api.NewOrganizationHandler(organizationService)
+	eventHandler := api.NewEventHandler(broker, testExecutionService, projectAccessService, cfg.Events.Heartbeat)
+	defectHandler := api.NewDefectHandler(defectService)
+	webhookHandler := api.NewWebhookHandler(webhookService, projectAccessService)
+	notificationHandler := api.NewNotificationHandler(notificationService)
+	issueTrackerHandler := api.NewIssueTrackerHandler(issueTrackerService, projectAccessService)
+
+	// Purge items that have been in the trash longer than the retention period
+	go trashService.RunPurgeJob(context.Background(), cfg.TrashPurgeInterval)
+
+	// Deliver queued webhook events and retry failed deliveries
+	go webhookService.RunDeliveryJob(context.Background(), cfg.Webhooks.PollInterval)
+
+	// Email the daily digest of unread notifications
+	if cfg.Notifications.DigestEnabled {
+		go notificationService.RunDigestJob(context.Background())
+	}
+
+	// Sync the status of defects with the issues they were reported as
+	if cfg.IssueTrackers.SyncInterval > 0 {
+		go issueTrackerService.RunSyncJob(context.Background(), cfg.IssueTrackers.SyncInterval)
+	}
 
 	// Initialize router
 	router := gin.Default()
-	api.SetupRouter(router, authHandler, projectHandler, testSuiteHandler, testCaseHandler, tagHandler)
+	// Only the configured proxies may set the client IP, which login protection and the
+	// audit log rely on; with none, the IP of the connection is used
+	if err := router.SetTrustedProxies(cfg.TrustedProxies); err != nil {
+		log.Fatalf("Invalid TRUSTED_PROXIES: %v", err)
+	}
+	api.SetupRouter(router, authHandler, projectHandler, testSuiteHandler, testCaseHandler, tagHandler, sharedStepHandler, testExecutionHandler, commentHandler, reviewHandler, customFieldHandler, workflowHandler, auditHandler, trashHandler, archiveHandler, apiTokenHandler, userHandler, accountHandler, loginProtectionHandler, twoFactorHandler, projectAccessHandler, teamHandler, organizationHandler, eventHandler, defectHandler, webhookHandler, notificationHandler, issueTrackerHandler)
 
 	// Start server
 	log.Printf("Server starting on port %s", cfg.ServerPort)
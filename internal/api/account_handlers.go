@@ -0,0 +1,102 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mihaamiharu/test-case-management-be/internal/models"
+	"github.com/mihaamiharu/test-case-management-be/internal/service"
+)
+
+// AccountHandler handles email verification and forgotten passwords
+type AccountHandler struct {
+	accountService *service.AccountService
+}
+
+// NewAccountHandler creates a new account handler
+func NewAccountHandler(accountService *service.AccountService) *AccountHandler {
+	return &AccountHandler{
+		accountService: accountService,
+	}
+}
+
+// VerifyEmail handles verifying an email address with the token from a verification email
+func (h *AccountHandler) VerifyEmail(c *gin.Context) {
+	var request models.EmailVerificationRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, err := h.accountService.VerifyEmail(request.Token)
+	if err != nil {
+		if err == service.ErrInvalidUserToken {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify email address"})
+		return
+	}
+
+	setAuditEntity(c, "user", user.ID, 0)
+
+	c.JSON(http.StatusOK, user.ToResponse())
+}
+
+// ResendVerification handles sending another verification email to the current user
+func (h *AccountHandler) ResendVerification(c *gin.Context) {
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found in context"})
+		return
+	}
+
+	if err := h.accountService.SendVerification(user.(*models.User)); err != nil {
+		if err == service.ErrEmailAlreadyVerified {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to send verification email"})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"message": "Verification email sent"})
+}
+
+// ForgotPassword handles requesting a password reset email. The response is the same
+// whether or not the email address belongs to a user.
+func (h *AccountHandler) ForgotPassword(c *gin.Context) {
+	var request models.ForgotPasswordRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.accountService.ForgotPassword(request.Email); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to request password reset"})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"message": "If the email address belongs to an account, a password reset link has been sent to it"})
+}
+
+// ResetPassword handles setting a new password with the token from a password reset email
+func (h *AccountHandler) ResetPassword(c *gin.Context) {
+	var request models.PasswordResetRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.accountService.ResetPassword(request.Token, request.NewPassword); err != nil {
+		if err == service.ErrInvalidUserToken || errors.Is(err, service.ErrWeakPassword) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reset password"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Password reset successfully; log in with your new password"})
+}
@@ -0,0 +1,168 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mihaamiharu/test-case-management-be/internal/models"
+	"github.com/mihaamiharu/test-case-management-be/internal/repository"
+	"github.com/mihaamiharu/test-case-management-be/internal/service"
+	"github.com/mihaamiharu/test-case-management-be/internal/services"
+)
+
+// resultRoutes are the routes that record test results, which the results scope allows
+var resultRoutes = map[string]bool{
+	"POST /api/v1/test-run-cases/:runId":       true,
+	"PUT /api/v1/test-executions/:id":          true,
+	"POST /api/v1/test-executions/:id/defects": true,
+	"POST /api/v1/defects/:id/external-issue":  true,
+}
+
+// APITokenHandler handles personal API tokens
+type APITokenHandler struct {
+	apiTokenService      *service.APITokenService
+	projectAccessService *services.ProjectAccessService
+}
+
+// NewAPITokenHandler creates a new API token handler
+func NewAPITokenHandler(apiTokenService *service.APITokenService, projectAccessService *services.ProjectAccessService) *APITokenHandler {
+	return &APITokenHandler{
+		apiTokenService:      apiTokenService,
+		projectAccessService: projectAccessService,
+	}
+}
+
+// ScopeMiddleware restricts requests authenticated with an API token to the scopes and the
+// project of the token. Sessions and API tokens can only be managed after logging in.
+func (h *APITokenHandler) ScopeMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		value, exists := c.Get("apiToken")
+		if !exists {
+			c.Next()
+			return
+		}
+		token := value.(*models.APIToken)
+
+		path := c.FullPath()
+		if strings.HasPrefix(path, "/api/v1/auth/") || strings.HasPrefix(path, "/api/v1/api-tokens") {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "API tokens cannot manage sessions or API tokens"})
+			return
+		}
+
+		var write bool
+		switch c.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+		default:
+			write = true
+		}
+		if err := h.apiTokenService.CheckScope(token, write, resultRoutes[c.Request.Method+" "+path]); err != nil {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+
+		if token.ProjectID != nil {
+			targets := requestTargets(c)
+			if len(targets) == 0 {
+				c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": service.ErrAPITokenProjectRequired.Error()})
+				return
+			}
+			for _, target := range targets {
+				if err := h.apiTokenService.CheckProject(token, target.entityType, target.id); err != nil {
+					if errors.Is(err, service.ErrAPITokenProjectDenied) {
+						c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": err.Error()})
+						return
+					}
+					c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+					return
+				}
+			}
+		}
+
+		c.Next()
+	}
+}
+
+// CreateAPIToken handles creating a personal API token. The token is only shown in this response.
+func (h *APITokenHandler) CreateAPIToken(c *gin.Context) {
+	var tokenCreate models.APITokenCreate
+	if err := c.ShouldBindJSON(&tokenCreate); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Get user from context
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found in context"})
+		return
+	}
+	userModel := user.(*models.User)
+
+	// A token can only be restricted to a project the user can see
+	if tokenCreate.ProjectID != nil && !authorizeProject(c, h.projectAccessService, *tokenCreate.ProjectID, models.PermissionViewProject) {
+		return
+	}
+
+	token, value, err := h.apiTokenService.Create(userModel.ID, &tokenCreate)
+	if err != nil {
+		if err == service.ErrAPITokenExpiryInPast {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	var projectID int64
+	if token.ProjectID != nil {
+		projectID = *token.ProjectID
+	}
+	setAuditEntity(c, "api_token", token.ID, projectID)
+	setAuditAfter(c, token.ToResponse())
+
+	c.JSON(http.StatusCreated, models.APITokenCreatedResponse{
+		APITokenResponse: token.ToResponse(),
+		Token:            value,
+	})
+}
+
+// ListAPITokens handles listing the personal API tokens of the current user
+func (h *APITokenHandler) ListAPITokens(c *gin.Context) {
+	tokens, err := h.apiTokenService.List(c.GetInt64("userID"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	responses := make([]models.APITokenResponse, len(tokens))
+	for i, token := range tokens {
+		responses[i] = token.ToResponse()
+	}
+
+	c.JSON(http.StatusOK, responses)
+}
+
+// RevokeAPIToken handles revoking a personal API token of the current user
+func (h *APITokenHandler) RevokeAPIToken(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid API token ID"})
+		return
+	}
+
+	if err := h.apiTokenService.Revoke(c.GetInt64("userID"), id); err != nil {
+		if err == repository.ErrAPITokenNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "API token not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	setAuditEntity(c, "api_token", id, 0)
+
+	c.JSON(http.StatusOK, gin.H{"message": "API token revoked successfully"})
+}
@@ -0,0 +1,163 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mihaamiharu/test-case-management-be/internal/models"
+	"github.com/mihaamiharu/test-case-management-be/internal/repository"
+	"github.com/mihaamiharu/test-case-management-be/internal/service"
+	"github.com/mihaamiharu/test-case-management-be/internal/services"
+)
+
+// ArchiveHandler handles archiving projects and test suites and keeps archived ones read-only
+type ArchiveHandler struct {
+	archiveService       *service.ArchiveService
+	testSuiteService     *service.TestSuiteService
+	projectAccessService *services.ProjectAccessService
+}
+
+// NewArchiveHandler creates a new archive handler
+func NewArchiveHandler(
+	archiveService *service.ArchiveService,
+	testSuiteService *service.TestSuiteService,
+	projectAccessService *services.ProjectAccessService,
+) *ArchiveHandler {
+	return &ArchiveHandler{
+		archiveService:       archiveService,
+		testSuiteService:     testSuiteService,
+		projectAccessService: projectAccessService,
+	}
+}
+
+// ReadOnlyMiddleware rejects writes to anything that belongs to an archived project or test
+// suite. The entity is found from the route parameters and from project_id and suite_id in
+// a JSON body.
+func (h *ArchiveHandler) ReadOnlyMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		switch c.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			c.Next()
+			return
+		}
+
+		// Archiving and unarchiving check the state themselves
+		if strings.HasSuffix(c.FullPath(), "/archive") || strings.HasSuffix(c.FullPath(), "/unarchive") {
+			c.Next()
+			return
+		}
+
+		for _, target := range requestTargets(c) {
+			if err := h.archiveService.CheckWritable(target.entityType, target.id); err != nil {
+				if isArchivedError(err) {
+					c.AbortWithStatusJSON(http.StatusConflict, gin.H{"error": err.Error()})
+					return
+				}
+				c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+				return
+			}
+		}
+
+		c.Next()
+	}
+}
+
+// ArchiveProject handles archiving a project. Only maintainers can archive it.
+func (h *ArchiveHandler) ArchiveProject(c *gin.Context) {
+	h.setProjectArchived(c, true)
+}
+
+// UnarchiveProject handles unarchiving a project. Only maintainers can unarchive it.
+func (h *ArchiveHandler) UnarchiveProject(c *gin.Context) {
+	h.setProjectArchived(c, false)
+}
+
+// setProjectArchived archives or unarchives a project for a maintainer
+func (h *ArchiveHandler) setProjectArchived(c *gin.Context, archived bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid project ID"})
+		return
+	}
+
+	// Archiving is a project setting, which only maintainers may change
+	if !authorizeProject(c, h.projectAccessService, id, models.PermissionManageProject) {
+		return
+	}
+
+	project, err := h.archiveService.SetProjectArchived(id, archived)
+	if err != nil {
+		if errors.Is(err, repository.ErrProjectNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Project not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	setAuditEntity(c, "project", id, id)
+	setAuditAction(c, models.AuditActionUpdate)
+	setAuditAfter(c, project.ToResponse())
+
+	c.JSON(http.StatusOK, project.ToResponse())
+}
+
+// ArchiveTestSuite handles archiving a test suite
+func (h *ArchiveHandler) ArchiveTestSuite(c *gin.Context) {
+	h.setTestSuiteArchived(c, true)
+}
+
+// UnarchiveTestSuite handles unarchiving a test suite
+func (h *ArchiveHandler) UnarchiveTestSuite(c *gin.Context) {
+	h.setTestSuiteArchived(c, false)
+}
+
+// setTestSuiteArchived archives or unarchives a test suite for an editor of its project
+func (h *ArchiveHandler) setTestSuiteArchived(c *gin.Context, archived bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid test suite ID"})
+		return
+	}
+
+	suite, err := h.testSuiteService.GetTestSuiteByID(id)
+	if err != nil {
+		if err == repository.ErrTestSuiteNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Test suite not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if !authorizeProject(c, h.projectAccessService, suite.ProjectID, models.PermissionEditTests) {
+		return
+	}
+
+	suite, err = h.archiveService.SetTestSuiteArchived(id, archived)
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrTestSuiteNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Test suite not found"})
+		case isArchivedError(err):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	setAuditEntity(c, "test_suite", id, suite.ProjectID)
+	setAuditAction(c, models.AuditActionUpdate)
+	setAuditAfter(c, suite.ToResponse())
+
+	c.JSON(http.StatusOK, suite.ToResponse())
+}
+
+// isArchivedError reports whether an error is caused by an archived project or test suite
+func isArchivedError(err error) bool {
+	return errors.Is(err, service.ErrProjectArchived) || errors.Is(err, service.ErrTestSuiteArchived)
+}
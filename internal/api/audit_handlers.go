@@ -0,0 +1,283 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mihaamiharu/test-case-management-be/internal/models"
+	"github.com/mihaamiharu/test-case-management-be/internal/service"
+	"github.com/mihaamiharu/test-case-management-be/internal/services"
+)
+
+// auditContextKey is the context key under which handlers describe the change they make
+const auditContextKey = "audit"
+
+// AuditHandler handles audit log recording and queries
+type AuditHandler struct {
+	auditService         *service.AuditService
+	projectAccessService *services.ProjectAccessService
+}
+
+// NewAuditHandler creates a new audit handler
+func NewAuditHandler(auditService *service.AuditService, projectAccessService *services.ProjectAccessService) *AuditHandler {
+	return &AuditHandler{
+		auditService:         auditService,
+		projectAccessService: projectAccessService,
+	}
+}
+
+// AuditMiddleware records every successful create, update and delete request.
+// Handlers describe the entity and its state before and after the change with
+// setAuditEntity, setAuditBefore and setAuditAfter; otherwise the entry is derived
+// from the route.
+func (h *AuditHandler) AuditMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		action, ok := auditActionForMethod(c.Request.Method)
+		if !ok || c.Writer.Status() >= http.StatusBadRequest {
+			return
+		}
+
+		entry := auditEntry(c)
+		if entry.Action == "" {
+			entry.Action = action
+		}
+		if entry.EntityType == "" {
+			entry.EntityType, entry.EntityID = entityFromRoute(c)
+		}
+		if userID, exists := c.Get("userID"); exists {
+			id := userID.(int64)
+			entry.ActorID = &id
+		}
+		entry.Method = c.Request.Method
+		entry.Path = c.Request.URL.Path
+		entry.IPAddress = c.ClientIP()
+		if organizationID := currentOrganizationID(c); organizationID != 0 {
+			entry.OrganizationID = &organizationID
+		}
+
+		if err := h.auditService.Record(entry); err != nil {
+			log.Printf("failed to record audit entry for %s %s: %v", entry.Method, entry.Path, err)
+		}
+	}
+}
+
+// ListAuditLog handles querying the audit log of the current organization. Organization
+// admins can query all of it; project maintainers can query the entries of their projects.
+func (h *AuditHandler) ListAuditLog(c *gin.Context) {
+	filter, ok := h.authorizedFilter(c)
+	if !ok {
+		return
+	}
+
+	entries, err := h.auditService.List(filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if entries == nil {
+		entries = []*models.AuditEntry{}
+	}
+
+	c.JSON(http.StatusOK, entries)
+}
+
+// ExportAuditLog handles exporting the audit log as CSV with the same filters as ListAuditLog
+func (h *AuditHandler) ExportAuditLog(c *gin.Context) {
+	filter, ok := h.authorizedFilter(c)
+	if !ok {
+		return
+	}
+
+	// The export is built before anything is sent, so that a failure is reported as an
+	// error rather than a truncated file
+	var buf bytes.Buffer
+	if err := h.auditService.ExportCSV(filter, &buf); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Header("Content-Disposition", "attachment; filename=audit-log.csv")
+	c.Data(http.StatusOK, "text/csv", buf.Bytes())
+}
+
+// authorizedFilter parses the query filters, limits them to the current organization and
+// checks that the user may see the entries
+func (h *AuditHandler) authorizedFilter(c *gin.Context) (*models.AuditFilter, bool) {
+	filter, err := parseAuditFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return nil, false
+	}
+
+	// Get user from context
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found in context"})
+		return nil, false
+	}
+	userModel := user.(*models.User)
+
+	filter.OrganizationID = currentOrganizationID(c)
+	if isOrganizationAdmin(c) {
+		// Admins of the installation also see the entries that belong to no organization,
+		// but never those of other organizations
+		filter.IncludeInstallation = userModel.Role == models.RoleAdmin
+		return filter, true
+	}
+	if filter.ProjectID == 0 {
+		c.JSON(http.StatusForbidden, gin.H{"error": "project_id is required unless you are an organization admin"})
+		return nil, false
+	}
+
+	if !authorizeProject(c, h.projectAccessService, filter.ProjectID, models.PermissionManageProject) {
+		return nil, false
+	}
+
+	return filter, true
+}
+
+// parseAuditFilter reads the audit log filters from the query string
+func parseAuditFilter(c *gin.Context) (*models.AuditFilter, error) {
+	filter := &models.AuditFilter{
+		EntityType: c.Query("entity_type"),
+		Action:     models.AuditAction(c.Query("action")),
+	}
+
+	ids := map[string]*int64{
+		"actor_id":   &filter.ActorID,
+		"project_id": &filter.ProjectID,
+		"entity_id":  &filter.EntityID,
+	}
+	for name, target := range ids {
+		if value := c.Query(name); value != "" {
+			id, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s", name)
+			}
+			*target = id
+		}
+	}
+
+	switch filter.Action {
+	case "", models.AuditActionCreate, models.AuditActionUpdate, models.AuditActionDelete,
+		models.AuditActionLock, models.AuditActionUnlock:
+	default:
+		return nil, errors.New("action must be create, update, delete, lock or unlock")
+	}
+
+	times := map[string]*time.Time{
+		"from": &filter.From,
+		"to":   &filter.To,
+	}
+	for name, target := range times {
+		if value := c.Query(name); value != "" {
+			parsed, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				return nil, fmt.Errorf("%s must be an RFC 3339 timestamp", name)
+			}
+			*target = parsed
+		}
+	}
+
+	if value := c.Query("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, errors.New("invalid limit")
+		}
+		filter.Limit = limit
+	}
+	if value := c.Query("offset"); value != "" {
+		offset, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, errors.New("invalid offset")
+		}
+		filter.Offset = offset
+	}
+
+	return filter, nil
+}
+
+// auditEntry returns the audit entry of the current request, creating it on first use
+func auditEntry(c *gin.Context) *models.AuditEntry {
+	if value, exists := c.Get(auditContextKey); exists {
+		return value.(*models.AuditEntry)
+	}
+	entry := &models.AuditEntry{}
+	c.Set(auditContextKey, entry)
+	return entry
+}
+
+// setAuditEntity describes the entity a request changes. A projectID of 0 means
+// the entity does not belong to a project.
+func setAuditEntity(c *gin.Context, entityType string, entityID, projectID int64) {
+	entry := auditEntry(c)
+	entry.EntityType = entityType
+	entry.EntityID = &entityID
+	if projectID != 0 {
+		entry.ProjectID = &projectID
+	}
+}
+
+// setAuditAction overrides the action derived from the request method, such as for a
+// POST that restores an entity
+func setAuditAction(c *gin.Context, action models.AuditAction) {
+	auditEntry(c).Action = action
+}
+
+// setAuditBefore records the state of the entity before the change
+func setAuditBefore(c *gin.Context, state interface{}) {
+	auditEntry(c).Before = marshalAuditState(state)
+}
+
+// setAuditAfter records the state of the entity after the change
+func setAuditAfter(c *gin.Context, state interface{}) {
+	auditEntry(c).After = marshalAuditState(state)
+}
+
+// marshalAuditState encodes an entity right away so later changes to it are not recorded
+func marshalAuditState(state interface{}) json.RawMessage {
+	data, err := json.Marshal(state)
+	if err != nil {
+		log.Printf("failed to encode audit state: %v", err)
+		return nil
+	}
+	return data
+}
+
+// auditActionForMethod maps an HTTP method to the audit action it performs
+func auditActionForMethod(method string) (models.AuditAction, bool) {
+	switch method {
+	case http.MethodPost:
+		return models.AuditActionCreate, true
+	case http.MethodPut, http.MethodPatch:
+		return models.AuditActionUpdate, true
+	case http.MethodDelete:
+		return models.AuditActionDelete, true
+	}
+	return "", false
+}
+
+// entityFromRoute derives the entity of a request from its route, such as
+// test_suite for /api/v1/test-suites/:id
+func entityFromRoute(c *gin.Context) (string, *int64) {
+	segments := strings.Split(strings.TrimPrefix(c.FullPath(), "/api/v1/"), "/")
+	entityType := strings.TrimSuffix(strings.ReplaceAll(segments[0], "-", "_"), "s")
+
+	var entityID *int64
+	if id, err := strconv.ParseInt(c.Param("id"), 10, 64); err == nil {
+		entityID = &id
+	}
+
+	return entityType, entityID
+}
@@ -0,0 +1,63 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mihaamiharu/test-case-management-be/internal/models"
+	"github.com/mihaamiharu/test-case-management-be/internal/repository"
+	"github.com/mihaamiharu/test-case-management-be/internal/service"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeAuditRepository lists fixed entries, or fails with err
+type fakeAuditRepository struct {
+	repository.AuditRepositoryInterface
+	entries []*models.AuditEntry
+	err     error
+}
+
+func (r *fakeAuditRepository) List(filter *models.AuditFilter) ([]*models.AuditEntry, error) {
+	return r.entries, r.err
+}
+
+// exportAuditLog requests the CSV export as an admin of organization 1
+func exportAuditLog(repo *fakeAuditRepository) *httptest.ResponseRecorder {
+	gin.SetMode(gin.TestMode)
+	handler := NewAuditHandler(service.NewAuditService(repo), nil)
+
+	router := gin.New()
+	router.GET("/api/v1/audit-log/export", func(c *gin.Context) {
+		c.Set("user", &models.User{ID: 1, Role: models.RoleUser})
+		c.Set(organizationIDContextKey, int64(1))
+		c.Set(organizationRoleContextKey, models.OrganizationRoleAdmin)
+	}, handler.ExportAuditLog)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/audit-log/export", nil))
+	return w
+}
+
+func TestAuditHandler_ExportAuditLog(t *testing.T) {
+	// Test case: the entries are sent as a CSV attachment
+	w := exportAuditLog(&fakeAuditRepository{entries: []*models.AuditEntry{
+		{ID: 3, Action: models.AuditActionCreate, EntityType: "project", Method: http.MethodPost, Path: "/api/v1/projects", CreatedAt: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)},
+	}})
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "text/csv", w.Header().Get("Content-Type"))
+	assert.Equal(t, "attachment; filename=audit-log.csv", w.Header().Get("Content-Disposition"))
+	lines := strings.Split(strings.TrimSpace(w.Body.String()), "\n")
+	assert.Len(t, lines, 2)
+	assert.True(t, strings.HasPrefix(lines[1], "3,2026-01-02T03:04:05Z,,create,project"))
+
+	// Test case: a failed export is an error response, not a CSV attachment
+	w = exportAuditLog(&fakeAuditRepository{err: errors.New("connection lost")})
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Empty(t, w.Header().Get("Content-Disposition"))
+	assert.JSONEq(t, `{"error":"connection lost"}`, w.Body.String())
+}
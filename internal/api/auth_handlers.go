@@ -1,8 +1,13 @@
 package api
 
 import (
+	"errors"
+	"log"
 	"net/http"
+	"net/url"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/mihaamiharu/test-case-management-be/internal/models"
@@ -12,13 +17,31 @@ import (
 
 // AuthHandler handles authentication-related API endpoints
 type AuthHandler struct {
-	authService *service.AuthService
+	authService     *service.AuthService
+	apiTokenService *service.APITokenService
+	oidcService     *service.OIDCService
+	accountService  *service.AccountService
 }
 
+// oidcStateCookie holds the signed login state between the SSO login redirect and the callback
+const oidcStateCookie = "oidc_state"
+
+// loginCompletedKey is the context key set when a login issued tokens, as opposed to failing
+// or waiting for a two-factor code
+const loginCompletedKey = "loginCompleted"
+
 // NewAuthHandler creates a new authentication handler
-func NewAuthHandler(authService *service.AuthService) *AuthHandler {
+func NewAuthHandler(
+	authService *service.AuthService,
+	apiTokenService *service.APITokenService,
+	oidcService *service.OIDCService,
+	accountService *service.AccountService,
+) *AuthHandler {
 	return &AuthHandler{
-		authService: authService,
+		authService:     authService,
+		apiTokenService: apiTokenService,
+		oidcService:     oidcService,
+		accountService:  accountService,
 	}
 }
 
@@ -36,21 +59,38 @@ func (h *AuthHandler) Register(c *gin.Context) {
 			c.JSON(http.StatusConflict, gin.H{"error": "User with this email already exists"})
 			return
 		}
+		if err == service.ErrSSORequired {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, service.ErrWeakPassword) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to register user"})
 		return
 	}
 
-	// Generate token for the newly registered user
-	token, err := h.authService.Login(userCreate.Email, userCreate.Password)
+	// A failed email only delays verification; the user can ask for another one
+	if err := h.accountService.SendVerification(user); err != nil {
+		log.Printf("failed to send verification email to user %d: %v", user.ID, err)
+	}
+
+	// Start a session for the newly registered user, who cannot have two-factor authentication yet
+	tokens, _, err := h.authService.Login(userCreate.Email, userCreate.Password, sessionClient(c))
+	if err == service.ErrEmailNotVerified {
+		c.JSON(http.StatusCreated, gin.H{
+			"message": "Check your email to verify your address before logging in",
+			"user":    user.ToResponse(),
+		})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "User registered but failed to generate token"})
 		return
 	}
 
-	c.JSON(http.StatusCreated, gin.H{
-		"token": token,
-		"user":  user.ToResponse(),
-	})
+	c.JSON(http.StatusCreated, tokenResponse(tokens, user))
 }
 
 // Login handles user login
@@ -61,15 +101,23 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		return
 	}
 
-	token, err := h.authService.Login(userLogin.Email, userLogin.Password)
+	tokens, challenge, err := h.authService.Login(userLogin.Email, userLogin.Password, sessionClient(c))
 	if err != nil {
 		if err == service.ErrInvalidCredentials {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid email or password"})
 			return
 		}
+		if err == service.ErrSSORequired || err == service.ErrUserDeactivated || err == service.ErrEmailNotVerified {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to login"})
 		return
 	}
+	if challenge != nil {
+		c.JSON(http.StatusOK, challengeResponse(challenge))
+		return
+	}
 
 	// Get user information
 	user, err := h.authService.GetUserByEmail(userLogin.Email)
@@ -78,10 +126,171 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"token": token,
-		"user":  user.ToResponse(),
-	})
+	c.Set(loginCompletedKey, true)
+	c.JSON(http.StatusOK, tokenResponse(tokens, user))
+}
+
+// OIDCLogin redirects to the login page of the OpenID Connect provider
+func (h *AuthHandler) OIDCLogin(c *gin.Context) {
+	authURL, state, err := h.oidcService.StartLogin(c.Request.Context())
+	if err != nil {
+		if err == service.ErrOIDCDisabled {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(oidcStateCookie, state, 600, "/api/v1/auth/oidc", "", c.Request.TLS != nil, true)
+	c.Redirect(http.StatusFound, authURL)
+}
+
+// OIDCCallback completes a login at the OpenID Connect provider. Users are created on their
+// first login. The tokens, or the challenge of a user with two-factor authentication, are
+// returned as JSON, or in the fragment of the configured frontend URL.
+func (h *AuthHandler) OIDCCallback(c *gin.Context) {
+	if errorCode := c.Query("error"); errorCode != "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Single sign-on failed: " + errorCode + " " + c.Query("error_description")})
+		return
+	}
+
+	state, err := c.Cookie(oidcStateCookie)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": service.ErrInvalidOIDCState.Error()})
+		return
+	}
+	c.SetCookie(oidcStateCookie, "", -1, "/api/v1/auth/oidc", "", c.Request.TLS != nil, true)
+
+	user, err := h.oidcService.FinishLogin(c.Request.Context(), state, c.Query("state"), c.Query("code"))
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrOIDCDisabled):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case errors.Is(err, service.ErrInvalidOIDCState):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, service.ErrOIDCLoginFailed):
+			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		case errors.Is(err, service.ErrUserDeactivated):
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to complete single sign-on"})
+		}
+		return
+	}
+
+	tokens, challenge, err := h.authService.CompleteLogin(user, sessionClient(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start session"})
+		return
+	}
+
+	redirect := h.oidcService.PostLoginRedirect()
+	if challenge != nil {
+		if redirect != "" {
+			fragment := url.Values{
+				"two_factor_required": {"true"},
+				"challenge_token":     {challenge.ChallengeToken},
+				"expires_at":          {challenge.ExpiresAt.Format(time.RFC3339)},
+			}
+			c.Redirect(http.StatusFound, redirect+"#"+fragment.Encode())
+			return
+		}
+		c.JSON(http.StatusOK, challengeResponse(challenge))
+		return
+	}
+
+	if redirect != "" {
+		fragment := url.Values{
+			"token":              {tokens.AccessToken},
+			"expires_at":         {tokens.AccessExpiresAt.Format(time.RFC3339)},
+			"refresh_token":      {tokens.RefreshToken},
+			"refresh_expires_at": {tokens.RefreshExpiresAt.Format(time.RFC3339)},
+		}
+		c.Redirect(http.StatusFound, redirect+"#"+fragment.Encode())
+		return
+	}
+
+	c.JSON(http.StatusOK, tokenResponse(tokens, user))
+}
+
+// Refresh exchanges a refresh token for a new access token and refresh token
+func (h *AuthHandler) Refresh(c *gin.Context) {
+	var request models.RefreshRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tokens, err := h.authService.Refresh(request.RefreshToken)
+	if err != nil {
+		if err == service.ErrInvalidRefreshToken || err == service.ErrRefreshTokenReused {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to refresh token"})
+		return
+	}
+
+	c.JSON(http.StatusOK, tokens)
+}
+
+// Logout revokes the session of the current access token
+func (h *AuthHandler) Logout(c *gin.Context) {
+	sessionID := c.GetInt64("sessionID")
+	if err := h.authService.Logout(sessionID); err != nil {
+		if err == repository.ErrSessionNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Session not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to logout"})
+		return
+	}
+
+	setAuditEntity(c, "session", sessionID, 0)
+	setAuditAction(c, models.AuditActionDelete)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Logged out successfully"})
+}
+
+// ListSessions lists the active sessions of the current user
+func (h *AuthHandler) ListSessions(c *gin.Context) {
+	sessions, err := h.authService.ListSessions(c.GetInt64("userID"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list sessions"})
+		return
+	}
+
+	currentSessionID := c.GetInt64("sessionID")
+	responses := make([]models.SessionResponse, len(sessions))
+	for i, session := range sessions {
+		responses[i] = session.ToResponse(currentSessionID)
+	}
+
+	c.JSON(http.StatusOK, responses)
+}
+
+// RevokeSession revokes one of the sessions of the current user
+func (h *AuthHandler) RevokeSession(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid session ID"})
+		return
+	}
+
+	if err := h.authService.RevokeSession(c.GetInt64("userID"), id); err != nil {
+		if err == repository.ErrSessionNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Session not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke session"})
+		return
+	}
+
+	setAuditEntity(c, "session", id, 0)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Session revoked successfully"})
 }
 
 // Me returns the current authenticated user
@@ -101,7 +310,7 @@ func (h *AuthHandler) Me(c *gin.Context) {
 	c.JSON(http.StatusOK, user.ToResponse())
 }
 
-// AuthMiddleware authenticates requests
+// AuthMiddleware authenticates requests with a JWT access token or a personal API token
 func (h *AuthHandler) AuthMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
@@ -121,6 +330,31 @@ func (h *AuthHandler) AuthMiddleware() gin.HandlerFunc {
 		// Remove "Bearer " prefix
 		tokenString := authHeader[7:]
 
+		// Personal API tokens have no session
+		if strings.HasPrefix(tokenString, models.APITokenPrefix) {
+			user, apiToken, err := h.apiTokenService.Authenticate(tokenString)
+			if err != nil {
+				if err == service.ErrInvalidAPIToken {
+					c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+					c.Abort()
+					return
+				}
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check token"})
+				c.Abort()
+				return
+			}
+
+			if !h.checkAccountRestrictions(c, user) {
+				return
+			}
+
+			c.Set("user", user)
+			c.Set("userID", user.ID)
+			c.Set("apiToken", apiToken)
+			c.Next()
+			return
+		}
+
 		// Validate token
 		token, err := h.authService.ValidateToken(tokenString)
 		if err != nil || !token.Valid {
@@ -129,17 +363,125 @@ func (h *AuthHandler) AuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
-		// Get user from token
+		// Reject tokens of sessions that were logged out, revoked or refreshed
+		revoked, err := h.authService.IsTokenRevoked(token)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check token"})
+			c.Abort()
+			return
+		}
+		if revoked {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Token has been revoked"})
+			c.Abort()
+			return
+		}
+
+		// Get user and session from token
 		user, err := h.authService.GetUserFromToken(token)
 		if err != nil {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
 			c.Abort()
 			return
 		}
+		sessionID, err := h.authService.GetSessionIDFromToken(token)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
+			c.Abort()
+			return
+		}
+
+		// Deactivating a user revokes its sessions, but this also covers a failed revocation
+		if user.IsDeactivated() {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": service.ErrUserDeactivated.Error()})
+			c.Abort()
+			return
+		}
+		if !h.checkAccountRestrictions(c, user) {
+			return
+		}
 
-		// Set user and userID in context
+		// Set user, userID and sessionID in context
 		c.Set("user", user)
 		c.Set("userID", user.ID)
+		c.Set("sessionID", sessionID)
 		c.Next()
 	}
 }
+
+// checkAccountRestrictions aborts the request when the user must reset their password or
+// set up two-factor authentication first
+func (h *AuthHandler) checkAccountRestrictions(c *gin.Context, user *models.User) bool {
+	if !checkPasswordReset(c, user) {
+		return false
+	}
+
+	required, err := h.authService.RequiresTwoFactorSetup(user)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to check two-factor authentication"})
+		return false
+	}
+	if !required || twoFactorSetupRoutes[c.Request.Method+" "+c.FullPath()] {
+		return true
+	}
+	c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+		"error":                     "You must set up two-factor authentication before continuing",
+		"two_factor_setup_required": true,
+	})
+	return false
+}
+
+// twoFactorSetupRoutes are the routes users who must set up two-factor authentication can still use
+var twoFactorSetupRoutes = map[string]bool{
+	"GET /api/v1/auth/me":          true,
+	"POST /api/v1/auth/logout":     true,
+	"GET /api/v1/auth/2fa":         true,
+	"POST /api/v1/auth/2fa/setup":  true,
+	"POST /api/v1/auth/2fa/enable": true,
+}
+
+// passwordResetRoutes are the routes users who must reset their password can still use
+var passwordResetRoutes = map[string]bool{
+	"GET /api/v1/auth/me":       true,
+	"PUT /api/v1/auth/password": true,
+	"POST /api/v1/auth/logout":  true,
+}
+
+// checkPasswordReset aborts the request when the user must reset their password first
+func checkPasswordReset(c *gin.Context, user *models.User) bool {
+	if !user.PasswordResetRequired || passwordResetRoutes[c.Request.Method+" "+c.FullPath()] {
+		return true
+	}
+	c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+		"error":                   "You must change your password before continuing",
+		"password_reset_required": true,
+	})
+	return false
+}
+
+// sessionClient describes the client of a request for the session it starts
+func sessionClient(c *gin.Context) service.SessionClient {
+	return service.SessionClient{
+		UserAgent: c.Request.UserAgent(),
+		IPAddress: c.ClientIP(),
+	}
+}
+
+// challengeResponse builds the response to a login that needs a two-factor code
+func challengeResponse(challenge *models.TwoFactorChallenge) gin.H {
+	return gin.H{
+		"two_factor_required": true,
+		"challenge_token":     challenge.ChallengeToken,
+		"expires_at":          challenge.ExpiresAt,
+	}
+}
+
+// tokenResponse builds the response to a login or registration
+func tokenResponse(tokens *models.AuthTokens, user *models.User) gin.H {
+	return gin.H{
+		"token":              tokens.AccessToken,
+		"expires_at":         tokens.AccessExpiresAt,
+		"refresh_token":      tokens.RefreshToken,
+		"refresh_expires_at": tokens.RefreshExpiresAt,
+		"user":               user.ToResponse(),
+	}
+}
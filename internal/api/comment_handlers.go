@@ -0,0 +1,254 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mihaamiharu/test-case-management-be/internal/models"
+	"github.com/mihaamiharu/test-case-management-be/internal/repository"
+	"github.com/mihaamiharu/test-case-management-be/internal/service"
+	"github.com/mihaamiharu/test-case-management-be/internal/services"
+)
+
+// CommentHandler handles comment and activity feed requests
+type CommentHandler struct {
+	commentService       *service.CommentService
+	projectAccessService *services.ProjectAccessService
+}
+
+// NewCommentHandler creates a new comment handler
+func NewCommentHandler(commentService *service.CommentService, projectAccessService *services.ProjectAccessService) *CommentHandler {
+	return &CommentHandler{
+		commentService:       commentService,
+		projectAccessService: projectAccessService,
+	}
+}
+
+// CreateComment handles adding a comment or reply to a test case
+func (h *CommentHandler) CreateComment(c *gin.Context) {
+	testCaseID, err := strconv.ParseInt(c.Param("testCaseId"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid test case ID"})
+		return
+	}
+
+	var commentCreate models.CommentCreate
+	if err := c.ShouldBindJSON(&commentCreate); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Get user ID from context (set by auth middleware)
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	comment := &models.Comment{
+		TestCaseID: testCaseID,
+		StepID:     commentCreate.StepID,
+		ParentID:   commentCreate.ParentID,
+		Body:       commentCreate.Body,
+		CreatedBy:  userID.(int64),
+	}
+
+	err = h.commentService.CreateComment(comment)
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrTestCaseNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "test case not found"})
+		case errors.Is(err, repository.ErrTestStepNotFound),
+			errors.Is(err, repository.ErrCommentNotFound),
+			errors.Is(err, service.ErrCommentStepMismatch),
+			errors.Is(err, service.ErrCommentParentMismatch):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, comment.ToResponse())
+}
+
+// ListComments handles listing the comment threads of a test case
+func (h *CommentHandler) ListComments(c *gin.Context) {
+	testCaseID, err := strconv.ParseInt(c.Param("testCaseId"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid test case ID"})
+		return
+	}
+
+	threads, err := h.commentService.ListCommentThreads(testCaseID)
+	if err != nil {
+		if errors.Is(err, repository.ErrTestCaseNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "test case not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	response := make([]*models.CommentResponse, len(threads))
+	for i, thread := range threads {
+		response[i] = thread.ToResponse()
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// UpdateComment handles editing a comment
+func (h *CommentHandler) UpdateComment(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid comment ID"})
+		return
+	}
+
+	var commentUpdate models.CommentUpdate
+	if err := c.ShouldBindJSON(&commentUpdate); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Get user ID from context (set by auth middleware)
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	comment, err := h.commentService.UpdateComment(id, commentUpdate.Body, userID.(int64))
+	if err != nil {
+		handleCommentError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, comment.ToResponse())
+}
+
+// DeleteComment handles deleting a comment and its replies
+func (h *CommentHandler) DeleteComment(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid comment ID"})
+		return
+	}
+
+	// Get user ID from context (set by auth middleware)
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	if err := h.commentService.DeleteComment(id, userID.(int64)); err != nil {
+		handleCommentError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// ResolveComment handles marking a comment thread as resolved
+func (h *CommentHandler) ResolveComment(c *gin.Context) {
+	h.setResolved(c, true)
+}
+
+// UnresolveComment handles reopening a resolved comment thread
+func (h *CommentHandler) UnresolveComment(c *gin.Context) {
+	h.setResolved(c, false)
+}
+
+func (h *CommentHandler) setResolved(c *gin.Context, resolved bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid comment ID"})
+		return
+	}
+
+	// Get user ID from context (set by auth middleware)
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	// Threads are resolved by the author of their comment or by users who may edit the test
+	// case, whatever the route itself requires
+	comment, err := h.commentService.GetComment(id)
+	if err != nil {
+		handleCommentError(c, err)
+		return
+	}
+	if comment.CreatedBy != userID.(int64) && !h.mayEditTestCase(c, comment.TestCaseID) {
+		return
+	}
+
+	comment, err = h.commentService.SetCommentResolved(id, resolved, userID.(int64))
+	if err != nil {
+		handleCommentError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, comment.ToResponse())
+}
+
+// mayEditTestCase checks that the current user may edit a test case, and responds with
+// the reason when not
+func (h *CommentHandler) mayEditTestCase(c *gin.Context, testCaseID int64) bool {
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found in context"})
+		return false
+	}
+
+	err := h.projectAccessService.AuthorizeEntity(currentOrganizationID(c), "test_case", testCaseID, user.(*models.User), models.PermissionEditTests)
+	switch {
+	case err == nil:
+		return true
+	case errors.Is(err, services.ErrPermissionDenied):
+		c.JSON(http.StatusForbidden, gin.H{"error": "only the author or editors of the test case can resolve this thread"})
+	case errors.Is(err, repository.ErrProjectNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Not found"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check project access"})
+	}
+	return false
+}
+
+// GetActivity handles retrieving the activity feed of a test case
+func (h *CommentHandler) GetActivity(c *gin.Context) {
+	testCaseID, err := strconv.ParseInt(c.Param("testCaseId"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid test case ID"})
+		return
+	}
+
+	activity, err := h.commentService.GetActivity(testCaseID)
+	if err != nil {
+		if errors.Is(err, repository.ErrTestCaseNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "test case not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, activity)
+}
+
+// handleCommentError maps comment service errors to HTTP responses
+func handleCommentError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, repository.ErrCommentNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "comment not found"})
+	case errors.Is(err, service.ErrNotCommentAuthor):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
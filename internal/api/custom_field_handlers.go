@@ -0,0 +1,170 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mihaamiharu/test-case-management-be/internal/models"
+	"github.com/mihaamiharu/test-case-management-be/internal/repository"
+	"github.com/mihaamiharu/test-case-management-be/internal/service"
+	"github.com/mihaamiharu/test-case-management-be/internal/services"
+)
+
+// CustomFieldHandler handles custom field definition requests
+type CustomFieldHandler struct {
+	customFieldService   *service.CustomFieldService
+	projectAccessService *services.ProjectAccessService
+}
+
+// NewCustomFieldHandler creates a new custom field handler
+func NewCustomFieldHandler(customFieldService *service.CustomFieldService, projectAccessService *services.ProjectAccessService) *CustomFieldHandler {
+	return &CustomFieldHandler{
+		customFieldService:   customFieldService,
+		projectAccessService: projectAccessService,
+	}
+}
+
+// ListCustomFields handles listing the custom fields of a project
+func (h *CustomFieldHandler) ListCustomFields(c *gin.Context) {
+	projectID, err := strconv.ParseInt(c.Param("projectId"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid project ID"})
+		return
+	}
+
+	definitions, err := h.customFieldService.ListDefinitions(projectID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if definitions == nil {
+		definitions = []*models.CustomFieldDefinition{}
+	}
+
+	c.JSON(http.StatusOK, definitions)
+}
+
+// CreateCustomField handles defining a new custom field for a project
+func (h *CustomFieldHandler) CreateCustomField(c *gin.Context) {
+	var definitionCreate models.CustomFieldDefinitionCreate
+	if err := c.ShouldBindJSON(&definitionCreate); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userModel, ok := h.requireManageAccess(c, definitionCreate.ProjectID)
+	if !ok {
+		return
+	}
+
+	definition := &models.CustomFieldDefinition{
+		ProjectID: definitionCreate.ProjectID,
+		Name:      definitionCreate.Name,
+		Label:     definitionCreate.Label,
+		Type:      definitionCreate.Type,
+		Options:   definitionCreate.Options,
+		Required:  definitionCreate.Required,
+		Position:  definitionCreate.Position,
+		CreatedBy: userModel.ID,
+	}
+
+	if err := h.customFieldService.CreateDefinition(definition); err != nil {
+		handleCustomFieldError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, definition)
+}
+
+// UpdateCustomField handles updating a custom field
+func (h *CustomFieldHandler) UpdateCustomField(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid custom field ID"})
+		return
+	}
+
+	var definitionUpdate models.CustomFieldDefinitionUpdate
+	if err := c.ShouldBindJSON(&definitionUpdate); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	existing, err := h.customFieldService.GetDefinition(id)
+	if err != nil {
+		handleCustomFieldError(c, err)
+		return
+	}
+	if _, ok := h.requireManageAccess(c, existing.ProjectID); !ok {
+		return
+	}
+
+	definition, err := h.customFieldService.UpdateDefinition(id, &definitionUpdate)
+	if err != nil {
+		handleCustomFieldError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, definition)
+}
+
+// DeleteCustomField handles deleting a custom field together with its values
+func (h *CustomFieldHandler) DeleteCustomField(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid custom field ID"})
+		return
+	}
+
+	existing, err := h.customFieldService.GetDefinition(id)
+	if err != nil {
+		handleCustomFieldError(c, err)
+		return
+	}
+	if _, ok := h.requireManageAccess(c, existing.ProjectID); !ok {
+		return
+	}
+
+	if err := h.customFieldService.DeleteDefinition(id); err != nil {
+		handleCustomFieldError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// requireManageAccess checks that the current user may change the custom fields of a project
+func (h *CustomFieldHandler) requireManageAccess(c *gin.Context, projectID int64) (*models.User, bool) {
+	// Get user from context
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found in context"})
+		return nil, false
+	}
+	userModel := user.(*models.User)
+
+	// Custom fields are project settings, which only maintainers may change
+	if !authorizeProject(c, h.projectAccessService, projectID, models.PermissionManageProject) {
+		return nil, false
+	}
+
+	return userModel, true
+}
+
+// handleCustomFieldError maps custom field errors to HTTP responses
+func handleCustomFieldError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, repository.ErrCustomFieldNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "custom field not found"})
+	case errors.Is(err, repository.ErrCustomFieldExists):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrInvalidCustomFieldName),
+		errors.Is(err, service.ErrCustomFieldOptionsMissing):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
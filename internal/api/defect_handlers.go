@@ -0,0 +1,100 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mihaamiharu/test-case-management-be/internal/models"
+	"github.com/mihaamiharu/test-case-management-be/internal/repository"
+	"github.com/mihaamiharu/test-case-management-be/internal/service"
+)
+
+// DefectHandler handles requests about defects found by test executions
+type DefectHandler struct {
+	defectService *service.DefectService
+}
+
+// NewDefectHandler creates a new defect handler
+func NewDefectHandler(defectService *service.DefectService) *DefectHandler {
+	return &DefectHandler{
+		defectService: defectService,
+	}
+}
+
+// CreateDefect handles recording a defect found by an execution
+func (h *DefectHandler) CreateDefect(c *gin.Context) {
+	executionID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid test execution ID"})
+		return
+	}
+
+	var defectCreate models.DefectCreate
+	if err := c.ShouldBindJSON(&defectCreate); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	defect, err := h.defectService.CreateDefect(executionID, &defectCreate, c.GetInt64("userID"))
+	if err != nil {
+		if errors.Is(err, repository.ErrTestExecutionNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "test execution not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	setAuditEntity(c, "defect", defect.ID, defect.ProjectID)
+	setAuditAfter(c, defect)
+
+	c.JSON(http.StatusCreated, defect)
+}
+
+// ListDefectsByExecution handles listing the defects found by an execution
+func (h *DefectHandler) ListDefectsByExecution(c *gin.Context) {
+	executionID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid test execution ID"})
+		return
+	}
+
+	defects, err := h.defectService.ListDefectsByExecution(executionID)
+	if err != nil {
+		if errors.Is(err, repository.ErrTestExecutionNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "test execution not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if defects == nil {
+		defects = []*models.Defect{}
+	}
+
+	c.JSON(http.StatusOK, defects)
+}
+
+// GetDefect handles retrieving a defect
+func (h *DefectHandler) GetDefect(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid defect ID"})
+		return
+	}
+
+	defect, err := h.defectService.GetDefect(id)
+	if err != nil {
+		if errors.Is(err, repository.ErrDefectNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "defect not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, defect)
+}
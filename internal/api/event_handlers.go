@@ -0,0 +1,132 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mihaamiharu/test-case-management-be/internal/events"
+	"github.com/mihaamiharu/test-case-management-be/internal/models"
+	"github.com/mihaamiharu/test-case-management-be/internal/repository"
+	"github.com/mihaamiharu/test-case-management-be/internal/service"
+	"github.com/mihaamiharu/test-case-management-be/internal/services"
+)
+
+// defaultEventHeartbeat is used when no heartbeat interval is configured
+const defaultEventHeartbeat = 30 * time.Second
+
+// EventHandler streams realtime events to clients with Server-Sent Events
+type EventHandler struct {
+	broker               events.Broker
+	executionService     *service.TestExecutionService
+	projectAccessService *services.ProjectAccessService
+	heartbeat            time.Duration
+}
+
+// NewEventHandler creates a new event handler that sends a keep-alive comment on streams
+// that were idle for a heartbeat
+func NewEventHandler(
+	broker events.Broker,
+	executionService *service.TestExecutionService,
+	projectAccessService *services.ProjectAccessService,
+	heartbeat time.Duration,
+) *EventHandler {
+	if heartbeat <= 0 {
+		heartbeat = defaultEventHeartbeat
+	}
+	return &EventHandler{
+		broker:               broker,
+		executionService:     executionService,
+		projectAccessService: projectAccessService,
+		heartbeat:            heartbeat,
+	}
+}
+
+// StreamProjectEvents handles streaming the events of a project
+func (h *EventHandler) StreamProjectEvents(c *gin.Context) {
+	projectID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid project ID"})
+		return
+	}
+
+	h.stream(c, events.Filter{ProjectID: projectID})
+}
+
+// StreamTestRunEvents handles streaming the events of a test run, together with the events
+// of its project that belong to no run, such as test case edits and comments
+func (h *EventHandler) StreamTestRunEvents(c *gin.Context) {
+	runID, err := strconv.ParseInt(c.Param("runId"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid test run ID"})
+		return
+	}
+
+	projectID, err := h.executionService.GetRunProjectID(runID)
+	if err != nil {
+		if errors.Is(err, repository.ErrTestRunNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "test run not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	h.stream(c, events.Filter{ProjectID: projectID, TestRunID: runID})
+}
+
+// stream sends the events that match a filter until the client disconnects. Access to the
+// project is checked again at every heartbeat, so revoking it ends the stream.
+func (h *EventHandler) stream(c *gin.Context, filter events.Filter) {
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found in context"})
+		return
+	}
+	userModel := user.(*models.User)
+	organizationID := currentOrganizationID(c)
+
+	subscription := h.broker.Subscribe(filter)
+	defer subscription.Close()
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	c.Writer.Flush()
+
+	ticker := time.NewTicker(h.heartbeat)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case event, ok := <-subscription.Events():
+			if !ok {
+				return
+			}
+			data, err := json.Marshal(event)
+			if err != nil {
+				log.Printf("failed to encode %s event %d: %v", event.Type, event.ID, err)
+				continue
+			}
+			fmt.Fprintf(c.Writer, "id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.Type, data)
+		case <-ticker.C:
+			err := h.projectAccessService.Authorize(organizationID, filter.ProjectID, userModel, models.PermissionViewProject)
+			if err != nil {
+				fmt.Fprint(c.Writer, "event: access_revoked\ndata: {}\n\n")
+				c.Writer.Flush()
+				return
+			}
+			fmt.Fprint(c.Writer, ": ping\n\n")
+		}
+		c.Writer.Flush()
+	}
+}
@@ -0,0 +1,205 @@
+package api
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mihaamiharu/test-case-management-be/internal/issuetracker"
+	"github.com/mihaamiharu/test-case-management-be/internal/models"
+	"github.com/mihaamiharu/test-case-management-be/internal/repository"
+	"github.com/mihaamiharu/test-case-management-be/internal/service"
+	"github.com/mihaamiharu/test-case-management-be/internal/services"
+)
+
+// issueTrackerWebhookLimit is the largest webhook request body read, in bytes
+const issueTrackerWebhookLimit = 1 << 20
+
+// IssueTrackerHandler handles requests about the external issue trackers of projects
+type IssueTrackerHandler struct {
+	issueTrackerService  *service.IssueTrackerService
+	projectAccessService *services.ProjectAccessService
+}
+
+// NewIssueTrackerHandler creates a new issue tracker handler
+func NewIssueTrackerHandler(issueTrackerService *service.IssueTrackerService, projectAccessService *services.ProjectAccessService) *IssueTrackerHandler {
+	return &IssueTrackerHandler{
+		issueTrackerService:  issueTrackerService,
+		projectAccessService: projectAccessService,
+	}
+}
+
+// GetIssueTracker handles retrieving the issue tracker of a project
+func (h *IssueTrackerHandler) GetIssueTracker(c *gin.Context) {
+	projectID, ok := h.manageProject(c)
+	if !ok {
+		return
+	}
+
+	tracker, err := h.issueTrackerService.GetTracker(projectID)
+	if err != nil {
+		handleIssueTrackerError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, tracker)
+}
+
+// ConfigureIssueTracker handles connecting a project to an issue tracker
+func (h *IssueTrackerHandler) ConfigureIssueTracker(c *gin.Context) {
+	projectID, ok := h.manageProject(c)
+	if !ok {
+		return
+	}
+
+	var trackerConfig models.IssueTrackerConfig
+	if err := c.ShouldBindJSON(&trackerConfig); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if existing, err := h.issueTrackerService.GetTracker(projectID); err == nil {
+		setAuditBefore(c, existing)
+	}
+
+	tracker, err := h.issueTrackerService.ConfigureTracker(projectID, &trackerConfig, c.GetInt64("userID"))
+	if err != nil {
+		handleIssueTrackerError(c, err)
+		return
+	}
+
+	setAuditEntity(c, "issue_tracker", projectID, projectID)
+	setAuditAfter(c, tracker)
+
+	c.JSON(http.StatusOK, tracker)
+}
+
+// DeleteIssueTracker handles disconnecting a project from its issue tracker
+func (h *IssueTrackerHandler) DeleteIssueTracker(c *gin.Context) {
+	projectID, ok := h.manageProject(c)
+	if !ok {
+		return
+	}
+
+	existing, err := h.issueTrackerService.GetTracker(projectID)
+	if err != nil {
+		handleIssueTrackerError(c, err)
+		return
+	}
+	setAuditEntity(c, "issue_tracker", projectID, projectID)
+	setAuditBefore(c, existing)
+
+	if err := h.issueTrackerService.DeleteTracker(projectID); err != nil {
+		handleIssueTrackerError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// SyncIssueTracker handles reading the status of the issues of the linked defects of a
+// project now rather than at the next scheduled sync
+func (h *IssueTrackerHandler) SyncIssueTracker(c *gin.Context) {
+	projectID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid project ID"})
+		return
+	}
+
+	changed, err := h.issueTrackerService.SyncProject(c.Request.Context(), projectID)
+	if err != nil {
+		handleIssueTrackerError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"updated": changed})
+}
+
+// CreateExternalIssue handles reporting a defect to the issue tracker of its project
+func (h *IssueTrackerHandler) CreateExternalIssue(c *gin.Context) {
+	defectID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid defect ID"})
+		return
+	}
+
+	defect, err := h.issueTrackerService.CreateExternalIssue(c.Request.Context(), defectID)
+	if err != nil {
+		handleIssueTrackerError(c, err)
+		return
+	}
+
+	setAuditEntity(c, "defect", defect.ID, defect.ProjectID)
+	setAuditAfter(c, defect)
+
+	c.JSON(http.StatusCreated, defect)
+}
+
+// ReceiveWebhook handles an issue change sent by the webhook of the issue tracker of a
+// project. The request is authenticated by the webhook secret rather than a user.
+func (h *IssueTrackerHandler) ReceiveWebhook(c *gin.Context) {
+	projectID, err := strconv.ParseInt(c.Param("projectId"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid project ID"})
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, issueTrackerWebhookLimit))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "failed to read request body"})
+		return
+	}
+
+	if err := h.issueTrackerService.HandleWebhook(projectID, c.Request.Header, body); err != nil {
+		// Projects without an issue tracker are indistinguishable from bad signatures, so
+		// that the endpoint does not reveal which projects exist
+		if errors.Is(err, repository.ErrIssueTrackerNotFound) || errors.Is(err, issuetracker.ErrInvalidSignature) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid webhook signature"})
+			return
+		}
+		handleIssueTrackerError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// manageProject parses the project of the request and checks that the current user may
+// manage its settings
+func (h *IssueTrackerHandler) manageProject(c *gin.Context) (int64, bool) {
+	projectID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid project ID"})
+		return 0, false
+	}
+
+	if _, exists := c.Get("user"); !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found in context"})
+		return 0, false
+	}
+
+	// The issue tracker holds the credentials of the project, which only maintainers may see
+	return projectID, authorizeProject(c, h.projectAccessService, projectID, models.PermissionManageProject)
+}
+
+// handleIssueTrackerError maps issue tracker errors to HTTP responses
+func handleIssueTrackerError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, repository.ErrIssueTrackerNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "project has no issue tracker"})
+	case errors.Is(err, repository.ErrDefectNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "defect not found"})
+	case errors.Is(err, repository.ErrDefectAlreadyLinked):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrIssueTrackerTokenRequired),
+		errors.Is(err, service.ErrInvalidIssueTracker),
+		errors.Is(err, service.ErrIssueTrackerHostNotAllowed):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrIssueTrackerRequest):
+		c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,172 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mihaamiharu/test-case-management-be/internal/models"
+	"github.com/mihaamiharu/test-case-management-be/internal/repository"
+	"github.com/mihaamiharu/test-case-management-be/internal/service"
+)
+
+// LoginProtectionHandler handles brute-force protection of password logins
+type LoginProtectionHandler struct {
+	throttleService *service.LoginThrottleService
+	authService     *service.AuthService
+	userService     *service.UserService
+	auditService    *service.AuditService
+}
+
+// NewLoginProtectionHandler creates a new login protection handler
+func NewLoginProtectionHandler(
+	throttleService *service.LoginThrottleService,
+	authService *service.AuthService,
+	userService *service.UserService,
+	auditService *service.AuditService,
+) *LoginProtectionHandler {
+	return &LoginProtectionHandler{
+		throttleService: throttleService,
+		authService:     authService,
+		userService:     userService,
+		auditService:    auditService,
+	}
+}
+
+// LoginProtectionMiddleware rejects logins of email and IP addresses with too many recent
+// failures, counts failed logins and records lockouts in the audit log
+func (h *LoginProtectionHandler) LoginProtectionMiddleware() gin.HandlerFunc {
+	return h.protect(func(c *gin.Context) string {
+		return peekJSONString(c, "email")
+	})
+}
+
+// TwoFactorProtectionMiddleware protects the second step of a login like the first one.
+// Wrong codes count as failed logins of the user the challenge was issued to.
+func (h *LoginProtectionHandler) TwoFactorProtectionMiddleware() gin.HandlerFunc {
+	return h.protect(func(c *gin.Context) string {
+		user, err := h.authService.TwoFactorChallengeUser(peekJSONString(c, "challenge_token"))
+		if err != nil {
+			return ""
+		}
+		return user.Email
+	})
+}
+
+// protect throttles a login step whose account is identified by loginEmail. Failures are
+// reset once a login completes, not when only its password was right.
+func (h *LoginProtectionHandler) protect(loginEmail func(c *gin.Context) string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		email := loginEmail(c)
+		ip := c.ClientIP()
+
+		wait, err := h.throttleService.Check(email, ip)
+		if err != nil {
+			if err == service.ErrLoginThrottled || err == service.ErrLoginLocked {
+				c.Header("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+				c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"error": err.Error()})
+				return
+			}
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to check failed logins"})
+			return
+		}
+
+		c.Next()
+
+		switch {
+		case c.GetBool(loginCompletedKey):
+			if err := h.throttleService.RecordSuccess(email); err != nil {
+				log.Printf("failed to reset failed logins: %v", err)
+			}
+		case c.Writer.Status() == http.StatusUnauthorized:
+			locked, err := h.throttleService.RecordFailure(email, ip)
+			if err != nil {
+				log.Printf("failed to record failed login: %v", err)
+				return
+			}
+			for _, throttle := range locked {
+				h.recordLockout(c, throttle)
+			}
+		}
+	}
+}
+
+// UnlockUser handles lifting the lockout of a user after too many failed logins
+func (h *LoginProtectionHandler) UnlockUser(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	user, err := h.userService.Get(id)
+	if err != nil {
+		if err == repository.ErrUserNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get user"})
+		return
+	}
+
+	if err := h.throttleService.Unlock(user.Email); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to unlock user"})
+		return
+	}
+
+	setAuditEntity(c, "user", user.ID, 0)
+	setAuditAction(c, models.AuditActionUnlock)
+
+	c.JSON(http.StatusOK, gin.H{"message": "User unlocked successfully"})
+}
+
+// recordLockout records a lockout in the audit log. Lockouts of the email address of a
+// user are recorded for that user.
+func (h *LoginProtectionHandler) recordLockout(c *gin.Context, throttle *models.LoginThrottle) {
+	entry := &models.AuditEntry{
+		Action:     models.AuditActionLock,
+		EntityType: "login_" + string(throttle.Scope),
+		Method:     c.Request.Method,
+		Path:       c.Request.URL.Path,
+		IPAddress:  c.ClientIP(),
+		After:      marshalAuditState(throttle),
+	}
+	if throttle.Scope == models.LoginThrottleAccount {
+		user, err := h.authService.GetUserByEmail(throttle.Subject)
+		if err == nil {
+			entry.EntityType = "user"
+			entry.EntityID = &user.ID
+		} else if !errors.Is(err, repository.ErrUserNotFound) {
+			log.Printf("failed to get locked out user: %v", err)
+		}
+	}
+
+	if err := h.auditService.Record(entry); err != nil {
+		log.Printf("failed to record audit entry for lockout of %s: %v", throttle.Subject, err)
+	}
+}
+
+// peekJSONString peeks at a string field of a JSON request body, leaving the body to the handler
+func peekJSONString(c *gin.Context, field string) string {
+	if c.Request.Body == nil {
+		return ""
+	}
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		return ""
+	}
+	c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+	var fields map[string]interface{}
+	if json.Unmarshal(body, &fields) != nil {
+		return ""
+	}
+	value, _ := fields[field].(string)
+	return value
+}
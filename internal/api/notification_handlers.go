@@ -0,0 +1,143 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mihaamiharu/test-case-management-be/internal/models"
+	"github.com/mihaamiharu/test-case-management-be/internal/repository"
+	"github.com/mihaamiharu/test-case-management-be/internal/service"
+)
+
+// NotificationHandler handles the notification requests of the current user
+type NotificationHandler struct {
+	notificationService *service.NotificationService
+}
+
+// NewNotificationHandler creates a new notification handler
+func NewNotificationHandler(notificationService *service.NotificationService) *NotificationHandler {
+	return &NotificationHandler{
+		notificationService: notificationService,
+	}
+}
+
+// ListNotifications handles listing the notifications of the current user, newest first
+func (h *NotificationHandler) ListNotifications(c *gin.Context) {
+	filter := &models.NotificationFilter{UnreadOnly: c.Query("unread") == "true"}
+	if value := c.Query("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid limit"})
+			return
+		}
+		filter.Limit = limit
+	}
+	if value := c.Query("offset"); value != "" {
+		offset, err := strconv.Atoi(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid offset"})
+			return
+		}
+		filter.Offset = offset
+	}
+
+	list, err := h.notificationService.List(currentOrganizationID(c), c.GetInt64("userID"), filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, list)
+}
+
+// CountUnread handles counting the unread notifications of the current user
+func (h *NotificationHandler) CountUnread(c *gin.Context) {
+	count, err := h.notificationService.CountUnread(currentOrganizationID(c), c.GetInt64("userID"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"unread_count": count})
+}
+
+// MarkRead handles marking a notification of the current user as read
+func (h *NotificationHandler) MarkRead(c *gin.Context) {
+	h.setRead(c, true)
+}
+
+// MarkUnread handles marking a notification of the current user as unread again
+func (h *NotificationHandler) MarkUnread(c *gin.Context) {
+	h.setRead(c, false)
+}
+
+// setRead marks the notification of the request as read or unread
+func (h *NotificationHandler) setRead(c *gin.Context, read bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid notification ID"})
+		return
+	}
+
+	organizationID, userID := currentOrganizationID(c), c.GetInt64("userID")
+	if read {
+		err = h.notificationService.MarkRead(organizationID, userID, id)
+	} else {
+		err = h.notificationService.MarkUnread(organizationID, userID, id)
+	}
+	if err != nil {
+		if errors.Is(err, repository.ErrNotificationNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "notification not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// MarkAllRead handles marking every notification of the current user as read
+func (h *NotificationHandler) MarkAllRead(c *gin.Context) {
+	marked, err := h.notificationService.MarkAllRead(currentOrganizationID(c), c.GetInt64("userID"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"marked": marked})
+}
+
+// GetPreferences handles retrieving the notification preferences of the current user
+func (h *NotificationHandler) GetPreferences(c *gin.Context) {
+	preferences, err := h.notificationService.GetPreferences(c.GetInt64("userID"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, preferences)
+}
+
+// UpdatePreferences handles changing the notification preferences of the current user
+func (h *NotificationHandler) UpdatePreferences(c *gin.Context) {
+	var update models.NotificationPreferencesUpdate
+	if err := c.ShouldBindJSON(&update); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	preferences, err := h.notificationService.UpdatePreferences(c.GetInt64("userID"), &update)
+	if err != nil {
+		if errors.Is(err, service.ErrUnknownNotificationType) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, preferences)
+}
@@ -0,0 +1,336 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mihaamiharu/test-case-management-be/internal/models"
+	"github.com/mihaamiharu/test-case-management-be/internal/repository"
+	"github.com/mihaamiharu/test-case-management-be/internal/service"
+)
+
+// Context keys of the organization a request acts in
+const (
+	organizationIDContextKey   = "organizationID"
+	organizationRoleContextKey = "organizationRole"
+)
+
+// organizationFreeRoutes are the route prefixes users can use without being a member of an
+// organization, such as to create their first one
+var organizationFreeRoutes = []string{
+	"/api/v1/auth/",
+	"/api/v1/api-tokens",
+	"/api/v1/admin/",
+	"/api/v1/organizations",
+}
+
+// OrganizationHandler handles organization and organization membership endpoints
+type OrganizationHandler struct {
+	organizationService *service.OrganizationService
+}
+
+// NewOrganizationHandler creates a new organization handler
+func NewOrganizationHandler(organizationService *service.OrganizationService) *OrganizationHandler {
+	return &OrganizationHandler{
+		organizationService: organizationService,
+	}
+}
+
+// OrganizationMiddleware finds the organization a request acts in and checks that the user
+// is a member of it. Requests name it with the X-Organization-ID header; otherwise API tokens
+// restricted to a project act in the organization of the project and other requests in the
+// oldest organization of the user. Every organization-scoped query uses this organization.
+func (h *OrganizationHandler) OrganizationMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var organizationID int64
+		if value := c.GetHeader(models.OrganizationHeader); value != "" {
+			id, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid " + models.OrganizationHeader + " header"})
+				return
+			}
+			organizationID = id
+		}
+
+		if token, exists := c.Get("apiToken"); exists && token.(*models.APIToken).ProjectID != nil {
+			projectOrganizationID, err := h.organizationService.ProjectOrganizationID(*token.(*models.APIToken).ProjectID)
+			if err != nil && !errors.Is(err, repository.ErrProjectNotFound) {
+				c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to check organization"})
+				return
+			}
+			if err == nil {
+				if organizationID != 0 && organizationID != projectOrganizationID {
+					c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": service.ErrOrganizationProjectDenied.Error()})
+					return
+				}
+				organizationID = projectOrganizationID
+			}
+		}
+
+		member, err := h.organizationService.Resolve(c.GetInt64("userID"), organizationID)
+		if err != nil {
+			switch {
+			case errors.Is(err, service.ErrNoOrganization):
+				if isOrganizationFreeRoute(c) {
+					c.Next()
+					return
+				}
+				c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			case errors.Is(err, service.ErrNotOrganizationMember):
+				c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			default:
+				c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to check organization"})
+			}
+			return
+		}
+
+		c.Set(organizationIDContextKey, member.OrganizationID)
+		c.Set(organizationRoleContextKey, member.Role)
+		c.Header(models.OrganizationHeader, strconv.FormatInt(member.OrganizationID, 10))
+		c.Next()
+	}
+}
+
+// RequireOrganizationAdmin restricts a route to admins of the current organization
+func (h *OrganizationHandler) RequireOrganizationAdmin() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !isOrganizationAdmin(c) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": service.ErrNotOrganizationAdmin.Error()})
+			return
+		}
+		c.Next()
+	}
+}
+
+// ListOrganizations handles listing the organizations of the current user
+func (h *OrganizationHandler) ListOrganizations(c *gin.Context) {
+	organizations, err := h.organizationService.ListByUser(c.GetInt64("userID"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list organizations"})
+		return
+	}
+
+	if organizations == nil {
+		organizations = []*models.Organization{}
+	}
+
+	c.JSON(http.StatusOK, organizations)
+}
+
+// CreateOrganization handles creating an organization with the current user as its admin
+func (h *OrganizationHandler) CreateOrganization(c *gin.Context) {
+	var organizationCreate models.OrganizationCreate
+	if err := c.ShouldBindJSON(&organizationCreate); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	organization, err := h.organizationService.Create(c.GetInt64("userID"), &organizationCreate)
+	if err != nil {
+		h.respondWithError(c, err)
+		return
+	}
+
+	setAuditEntity(c, "organization", organization.ID, 0)
+	setAuditAfter(c, organization)
+
+	c.JSON(http.StatusCreated, organization)
+}
+
+// GetOrganization handles retrieving an organization of the current user
+func (h *OrganizationHandler) GetOrganization(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid organization ID"})
+		return
+	}
+
+	organization, err := h.organizationService.Get(c.GetInt64("userID"), id)
+	if err != nil {
+		h.respondWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, organization)
+}
+
+// UpdateOrganization handles renaming an organization
+func (h *OrganizationHandler) UpdateOrganization(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid organization ID"})
+		return
+	}
+
+	var organizationUpdate models.OrganizationUpdate
+	if err := c.ShouldBindJSON(&organizationUpdate); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if before, err := h.organizationService.Get(c.GetInt64("userID"), id); err == nil {
+		setAuditBefore(c, before)
+	}
+
+	organization, err := h.organizationService.Update(c.GetInt64("userID"), id, &organizationUpdate)
+	if err != nil {
+		h.respondWithError(c, err)
+		return
+	}
+
+	setAuditEntity(c, "organization", organization.ID, 0)
+	setAuditAfter(c, organization)
+
+	c.JSON(http.StatusOK, organization)
+}
+
+// ListMembers handles listing the members of an organization
+func (h *OrganizationHandler) ListMembers(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid organization ID"})
+		return
+	}
+
+	members, err := h.organizationService.ListMembers(c.GetInt64("userID"), id)
+	if err != nil {
+		h.respondWithError(c, err)
+		return
+	}
+
+	if members == nil {
+		members = []*models.OrganizationMember{}
+	}
+
+	c.JSON(http.StatusOK, members)
+}
+
+// AddMember handles adding a user to an organization by email
+func (h *OrganizationHandler) AddMember(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid organization ID"})
+		return
+	}
+
+	var memberAdd models.OrganizationMemberAdd
+	if err := c.ShouldBindJSON(&memberAdd); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	member, err := h.organizationService.AddMember(c.GetInt64("userID"), id, &memberAdd)
+	if err != nil {
+		h.respondWithError(c, err)
+		return
+	}
+
+	setAuditEntity(c, "organization", id, 0)
+	setAuditAction(c, models.AuditActionUpdate)
+	setAuditAfter(c, member)
+
+	c.JSON(http.StatusCreated, member)
+}
+
+// UpdateMember handles changing the role of a member of an organization
+func (h *OrganizationHandler) UpdateMember(c *gin.Context) {
+	id, userID, ok := h.memberParams(c)
+	if !ok {
+		return
+	}
+
+	var memberUpdate models.OrganizationMemberUpdate
+	if err := c.ShouldBindJSON(&memberUpdate); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	member, err := h.organizationService.UpdateMemberRole(c.GetInt64("userID"), id, userID, memberUpdate.Role)
+	if err != nil {
+		h.respondWithError(c, err)
+		return
+	}
+
+	setAuditEntity(c, "organization", id, 0)
+	setAuditAfter(c, member)
+
+	c.JSON(http.StatusOK, member)
+}
+
+// RemoveMember handles removing a user from an organization, or leaving it
+func (h *OrganizationHandler) RemoveMember(c *gin.Context) {
+	id, userID, ok := h.memberParams(c)
+	if !ok {
+		return
+	}
+
+	if err := h.organizationService.RemoveMember(c.GetInt64("userID"), id, userID); err != nil {
+		h.respondWithError(c, err)
+		return
+	}
+
+	setAuditEntity(c, "organization", id, 0)
+	setAuditAction(c, models.AuditActionUpdate)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Organization member removed successfully"})
+}
+
+// memberParams parses the organization and user IDs of a membership route
+func (h *OrganizationHandler) memberParams(c *gin.Context) (int64, int64, bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid organization ID"})
+		return 0, 0, false
+	}
+	userID, err := strconv.ParseInt(c.Param("userId"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return 0, 0, false
+	}
+	return id, userID, true
+}
+
+// respondWithError maps organization service errors to responses
+func (h *OrganizationHandler) respondWithError(c *gin.Context, err error) {
+	switch err {
+	case repository.ErrOrganizationNotFound, service.ErrNotOrganizationMember:
+		c.JSON(http.StatusNotFound, gin.H{"error": "Organization not found"})
+	case repository.ErrUserNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+	case repository.ErrOrganizationMemberNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case service.ErrNotOrganizationAdmin:
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case repository.ErrOrganizationExists, repository.ErrOrganizationMemberExists, service.ErrLastOrganizationAdmin:
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case service.ErrInvalidOrganizationSlug:
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+// currentOrganizationID returns the organization the request acts in, found by
+// OrganizationMiddleware
+func currentOrganizationID(c *gin.Context) int64 {
+	return c.GetInt64(organizationIDContextKey)
+}
+
+// isOrganizationAdmin reports whether the current user is an admin of the current organization
+func isOrganizationAdmin(c *gin.Context) bool {
+	role, _ := c.Get(organizationRoleContextKey)
+	return role == models.OrganizationRoleAdmin
+}
+
+// isOrganizationFreeRoute reports whether a request can be made without an organization
+func isOrganizationFreeRoute(c *gin.Context) bool {
+	for _, prefix := range organizationFreeRoutes {
+		if strings.HasPrefix(c.FullPath(), prefix) {
+			return true
+		}
+	}
+	return false
+}
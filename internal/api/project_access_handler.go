@@ -1,6 +1,7 @@
 package api
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 
@@ -24,7 +25,67 @@ func NewProjectAccessHandler(projectAccessService *services.ProjectAccessService
 	}
 }
 
-// GrantAccess handles granting access to a project
+// routePermissions are the permissions of the writes that need less than the edit permission
+var routePermissions = map[string]models.Permission{
+	// Restricting an API token to a project only needs access to the project
+	"POST /api/v1/api-tokens": models.PermissionViewProject,
+}
+
+// PermissionMiddleware checks that the current user has the permission a request needs in
+// the project of every entity it reads or writes, which are found like in ReadOnlyMiddleware.
+// Reads need the view permission, recording test results the execute permission and other
+// writes the edit permission, unless routePermissions says otherwise. Handlers that need
+// more, such as changing project settings, check it with authorizeProject. Entities of
+// other organizations than the current one are reported as not found.
+func (h *ProjectAccessHandler) PermissionMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// Get user from context
+		user, exists := c.Get("user")
+		if !exists {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "User not found in context"})
+			return
+		}
+		userModel := user.(*models.User)
+
+		permission := requestPermission(c)
+		for _, target := range requestTargets(c) {
+			err := h.projectAccessService.AuthorizeEntity(currentOrganizationID(c), target.entityType, target.id, userModel, permission)
+			if err != nil {
+				if errors.Is(err, services.ErrPermissionDenied) {
+					c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": err.Error()})
+					return
+				}
+				if errors.Is(err, repository.ErrProjectNotFound) {
+					c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "Not found"})
+					return
+				}
+				c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to check project access"})
+				return
+			}
+		}
+
+		c.Next()
+	}
+}
+
+// requestPermission returns the permission a request needs in the projects it reads or writes
+func requestPermission(c *gin.Context) models.Permission {
+	route := c.Request.Method + " " + c.FullPath()
+	if permission, ok := routePermissions[route]; ok {
+		return permission
+	}
+	if resultRoutes[route] {
+		return models.PermissionExecuteTests
+	}
+
+	switch c.Request.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return models.PermissionViewProject
+	}
+	return models.PermissionEditTests
+}
+
+// GrantAccess handles granting a user a role in a project
 func (h *ProjectAccessHandler) GrantAccess(c *gin.Context) {
 	projectID, err := strconv.ParseInt(c.Param("id"), 10, 64)
 	if err != nil {
@@ -38,52 +99,81 @@ func (h *ProjectAccessHandler) GrantAccess(c *gin.Context) {
 		return
 	}
 
-	// Get user from context
-	user, exists := c.Get("user")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found in context"})
+	if !authorizeProject(c, h.projectAccessService, projectID, models.PermissionManageProject) {
 		return
 	}
-	userModel := user.(*models.User)
 
-	// Check if user is the owner of the project
-	isOwner, err := h.projectService.IsOwner(projectID, userModel.ID)
+	access, err := h.projectAccessService.GrantAccess(projectID, &accessCreate)
 	if err != nil {
-		if err == repository.ErrProjectNotFound {
+		switch err {
+		case repository.ErrProjectNotFound:
 			c.JSON(http.StatusNotFound, gin.H{"error": "Project not found"})
-			return
+		case repository.ErrUserNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		case repository.ErrOrganizationMemberNotFound:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Only members of the organization of the project can be granted access"})
+		case repository.ErrProjectAccessExists:
+			c.JSON(http.StatusConflict, gin.H{"error": "User already has access to this project"})
+		case services.ErrGrantToOwner:
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to grant access"})
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check project ownership"})
 		return
 	}
 
-	// Only allow the owner to grant access to the project
-	if !isOwner {
-		c.JSON(http.StatusForbidden, gin.H{"error": "You don't have permission to grant access to this project"})
+	setAuditEntity(c, "project_access", access.ID, projectID)
+	setAuditAfter(c, access.ToResponse())
+
+	c.JSON(http.StatusCreated, access.ToResponse())
+}
+
+// UpdateAccess handles changing the role of a user in a project
+func (h *ProjectAccessHandler) UpdateAccess(c *gin.Context) {
+	projectID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid project ID"})
 		return
 	}
 
-	// Don't allow granting access to the owner
-	if accessCreate.UserID == userModel.ID {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "You can't grant access to yourself as the owner"})
+	accessID, err := strconv.ParseInt(c.Param("accessId"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid access ID"})
 		return
 	}
 
-	access, err := h.projectAccessService.GrantAccess(projectID, &accessCreate)
+	var accessUpdate models.ProjectAccessUpdate
+	if err := c.ShouldBindJSON(&accessUpdate); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if !authorizeProject(c, h.projectAccessService, projectID, models.PermissionManageProject) {
+		return
+	}
+
+	if before := h.findAccess(projectID, accessID); before != nil {
+		setAuditBefore(c, before.ToResponse())
+	}
+
+	access, err := h.projectAccessService.UpdateAccess(projectID, accessID, &accessUpdate)
 	if err != nil {
-		if err == repository.ErrProjectAccessExists {
-			c.JSON(http.StatusConflict, gin.H{"error": "User already has access to this project"})
+		if err == repository.ErrProjectAccessNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Access record not found"})
 			return
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to grant access"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update access"})
 		return
 	}
 
-	c.JSON(http.StatusCreated, access.ToResponse())
+	setAuditEntity(c, "project_access", accessID, projectID)
+	setAuditAfter(c, access.ToResponse())
+
+	c.JSON(http.StatusOK, access.ToResponse())
 }
 
-// UpdateAccess handles updating access to a project
-func (h *ProjectAccessHandler) UpdateAccess(c *gin.Context) {
+// RevokeAccess handles revoking access to a project
+func (h *ProjectAccessHandler) RevokeAccess(c *gin.Context) {
 	projectID, err := strconv.ParseInt(c.Param("id"), 10, 64)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid project ID"})
@@ -96,52 +186,130 @@ func (h *ProjectAccessHandler) UpdateAccess(c *gin.Context) {
 		return
 	}
 
-	var accessUpdate models.ProjectAccessUpdate
-	if err := c.ShouldBindJSON(&accessUpdate); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if !authorizeProject(c, h.projectAccessService, projectID, models.PermissionManageProject) {
 		return
 	}
 
-	// Get user from context
-	user, exists := c.Get("user")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found in context"})
-		return
+	if before := h.findAccess(projectID, accessID); before != nil {
+		setAuditBefore(c, before.ToResponse())
 	}
-	userModel := user.(*models.User)
 
-	// Check if user is the owner of the project
-	isOwner, err := h.projectService.IsOwner(projectID, userModel.ID)
-	if err != nil {
-		if err == repository.ErrProjectNotFound {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Project not found"})
+	if err := h.projectAccessService.RevokeAccess(projectID, accessID); err != nil {
+		if err == repository.ErrProjectAccessNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Access record not found"})
 			return
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check project ownership"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke access"})
 		return
 	}
 
-	// Only allow the owner to update access to the project
-	if !isOwner {
-		c.JSON(http.StatusForbidden, gin.H{"error": "You don't have permission to update access to this project"})
+	setAuditEntity(c, "project_access", accessID, projectID)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Access revoked successfully"})
+}
+
+// findAccess looks up an access record of a project for the audit log
+func (h *ProjectAccessHandler) findAccess(projectID, accessID int64) *models.ProjectAccess {
+	access, err := h.projectAccessService.GetAccess(projectID, accessID)
+	if err != nil {
+		return nil
+	}
+	return access
+}
+
+// ListAccess handles listing all access records for a project
+func (h *ProjectAccessHandler) ListAccess(c *gin.Context) {
+	projectID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid project ID"})
 		return
 	}
 
-	access, err := h.projectAccessService.UpdateAccess(accessID, &accessUpdate)
+	if !authorizeProject(c, h.projectAccessService, projectID, models.PermissionManageProject) {
+		return
+	}
+
+	accessList, err := h.projectAccessService.GetProjectAccess(projectID)
 	if err != nil {
-		if err == repository.ErrProjectAccessNotFound {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Access record not found"})
-			return
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve access records"})
+		return
+	}
+
+	// Convert to response objects
+	responses := []models.ProjectAccessResponse{}
+	for _, access := range accessList {
+		responses = append(responses, access.ToResponse())
+	}
+
+	c.JSON(http.StatusOK, responses)
+}
+
+// ListTeamAccess handles listing the teams with access to a project
+func (h *ProjectAccessHandler) ListTeamAccess(c *gin.Context) {
+	projectID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid project ID"})
+		return
+	}
+
+	if !authorizeProject(c, h.projectAccessService, projectID, models.PermissionManageProject) {
+		return
+	}
+
+	accessList, err := h.projectAccessService.GetProjectTeamAccess(projectID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve team access records"})
+		return
+	}
+
+	if accessList == nil {
+		accessList = []*models.ProjectTeamAccess{}
+	}
+
+	c.JSON(http.StatusOK, accessList)
+}
+
+// GrantTeamAccess handles granting every member of a team a role in a project
+func (h *ProjectAccessHandler) GrantTeamAccess(c *gin.Context) {
+	projectID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid project ID"})
+		return
+	}
+
+	var accessCreate models.ProjectTeamAccessCreate
+	if err := c.ShouldBindJSON(&accessCreate); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if !authorizeProject(c, h.projectAccessService, projectID, models.PermissionManageProject) {
+		return
+	}
+
+	access, err := h.projectAccessService.GrantTeamAccess(projectID, &accessCreate)
+	if err != nil {
+		switch err {
+		case repository.ErrProjectNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"error": "Project not found"})
+		case repository.ErrTeamNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"error": "Team not found"})
+		case repository.ErrProjectTeamAccessExists:
+			c.JSON(http.StatusConflict, gin.H{"error": "Team already has access to this project"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to grant team access"})
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update access"})
 		return
 	}
 
-	c.JSON(http.StatusOK, access.ToResponse())
+	setAuditEntity(c, "project_team_access", access.ID, projectID)
+	setAuditAfter(c, access)
+
+	c.JSON(http.StatusCreated, access)
 }
 
-// RevokeAccess handles revoking access to a project
-func (h *ProjectAccessHandler) RevokeAccess(c *gin.Context) {
+// UpdateTeamAccess handles changing the role of a team in a project
+func (h *ProjectAccessHandler) UpdateTeamAccess(c *gin.Context) {
 	projectID, err := strconv.ParseInt(c.Param("id"), 10, 64)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid project ID"})
@@ -154,45 +322,76 @@ func (h *ProjectAccessHandler) RevokeAccess(c *gin.Context) {
 		return
 	}
 
-	// Get user from context
-	user, exists := c.Get("user")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found in context"})
+	var accessUpdate models.ProjectAccessUpdate
+	if err := c.ShouldBindJSON(&accessUpdate); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-	userModel := user.(*models.User)
 
-	// Check if user is the owner of the project
-	isOwner, err := h.projectService.IsOwner(projectID, userModel.ID)
+	if !authorizeProject(c, h.projectAccessService, projectID, models.PermissionManageProject) {
+		return
+	}
+
+	if before, err := h.projectAccessService.GetTeamAccess(projectID, accessID); err == nil {
+		setAuditBefore(c, before)
+	}
+
+	access, err := h.projectAccessService.UpdateTeamAccess(projectID, accessID, &accessUpdate)
 	if err != nil {
-		if err == repository.ErrProjectNotFound {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Project not found"})
+		if err == repository.ErrProjectTeamAccessNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Team access record not found"})
 			return
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check project ownership"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update team access"})
 		return
 	}
 
-	// Only allow the owner to revoke access to the project
-	if !isOwner {
-		c.JSON(http.StatusForbidden, gin.H{"error": "You don't have permission to revoke access to this project"})
+	setAuditEntity(c, "project_team_access", accessID, projectID)
+	setAuditAfter(c, access)
+
+	c.JSON(http.StatusOK, access)
+}
+
+// RevokeTeamAccess handles revoking the access of a team to a project
+func (h *ProjectAccessHandler) RevokeTeamAccess(c *gin.Context) {
+	projectID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid project ID"})
 		return
 	}
 
-	if err := h.projectAccessService.RevokeAccess(accessID); err != nil {
-		if err == repository.ErrProjectAccessNotFound {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Access record not found"})
+	accessID, err := strconv.ParseInt(c.Param("accessId"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid access ID"})
+		return
+	}
+
+	if !authorizeProject(c, h.projectAccessService, projectID, models.PermissionManageProject) {
+		return
+	}
+
+	if before, err := h.projectAccessService.GetTeamAccess(projectID, accessID); err == nil {
+		setAuditBefore(c, before)
+	}
+
+	if err := h.projectAccessService.RevokeTeamAccess(projectID, accessID); err != nil {
+		if err == repository.ErrProjectTeamAccessNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Team access record not found"})
 			return
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke access"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke team access"})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Access revoked successfully"})
+	setAuditEntity(c, "project_team_access", accessID, projectID)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Team access revoked successfully"})
 }
 
-// ListAccess handles listing all access records for a project
-func (h *ProjectAccessHandler) ListAccess(c *gin.Context) {
+// GetEffectiveAccess handles explaining the role of a user in a project: its ownership, its
+// own grant, the grants of its teams and the admin role. Users can explain their own
+// access; maintainers can explain anyone's.
+func (h *ProjectAccessHandler) GetEffectiveAccess(c *gin.Context) {
 	projectID, err := strconv.ParseInt(c.Param("id"), 10, 64)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid project ID"})
@@ -207,34 +406,104 @@ func (h *ProjectAccessHandler) ListAccess(c *gin.Context) {
 	}
 	userModel := user.(*models.User)
 
-	// Check if user is the owner of the project
-	isOwner, err := h.projectService.IsOwner(projectID, userModel.ID)
+	userID := userModel.ID
+	if value := c.Query("user_id"); value != "" {
+		if userID, err = strconv.ParseInt(value, 10, 64); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+			return
+		}
+	}
+
+	if userID != userModel.ID && !authorizeProject(c, h.projectAccessService, projectID, models.PermissionManageProject) {
+		return
+	}
+
+	access, err := h.projectAccessService.EffectiveAccess(currentOrganizationID(c), projectID, userID)
 	if err != nil {
-		if err == repository.ErrProjectNotFound {
+		switch err {
+		case repository.ErrProjectNotFound:
 			c.JSON(http.StatusNotFound, gin.H{"error": "Project not found"})
-			return
+		case repository.ErrUserNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to resolve project access"})
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check project ownership"})
 		return
 	}
 
-	// Only allow the owner to list access records for the project
-	if !isOwner {
-		c.JSON(http.StatusForbidden, gin.H{"error": "You don't have permission to view access records for this project"})
+	c.JSON(http.StatusOK, access)
+}
+
+// TransferOwnership handles making another user the owner of a project. The previous
+// owner becomes a maintainer.
+func (h *ProjectAccessHandler) TransferOwnership(c *gin.Context) {
+	projectID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid project ID"})
 		return
 	}
 
-	accessList, err := h.projectAccessService.GetProjectAccess(projectID)
+	var transfer models.ProjectOwnershipTransfer
+	if err := c.ShouldBindJSON(&transfer); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if !authorizeProject(c, h.projectAccessService, projectID, models.PermissionOwnProject) {
+		return
+	}
+
+	if before, err := h.projectService.GetByID(projectID); err == nil {
+		setAuditBefore(c, before.ToResponse())
+	}
+
+	project, err := h.projectAccessService.TransferOwnership(projectID, transfer.UserID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve access records"})
+		switch err {
+		case repository.ErrProjectNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"error": "Project not found"})
+		case repository.ErrUserNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		case repository.ErrOrganizationMemberNotFound:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Only members of the organization of the project can own it"})
+		case repository.ErrProjectExists:
+			c.JSON(http.StatusConflict, gin.H{"error": "The new owner already has a project with this name"})
+		case services.ErrAlreadyOwner, services.ErrInactiveNewOwner:
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to transfer project ownership"})
+		}
 		return
 	}
 
-	// Convert to response objects
-	var responses []models.ProjectAccessResponse
-	for _, access := range accessList {
-		responses = append(responses, access.ToResponse())
+	setAuditEntity(c, "project", projectID, projectID)
+	setAuditAction(c, models.AuditActionUpdate)
+	setAuditAfter(c, project.ToResponse())
+
+	c.JSON(http.StatusOK, project.ToResponse())
+}
+
+// authorizeProject checks that the current user has a permission in a project. It responds
+// with an error and returns false otherwise.
+func authorizeProject(c *gin.Context, projectAccessService *services.ProjectAccessService, projectID int64, permission models.Permission) bool {
+	// Get user from context
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found in context"})
+		return false
 	}
 
-	c.JSON(http.StatusOK, responses)
+	err := projectAccessService.Authorize(currentOrganizationID(c), projectID, user.(*models.User), permission)
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrProjectNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Project not found"})
+		case errors.Is(err, services.ErrPermissionDenied):
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check project access"})
+		}
+		return false
+	}
+	return true
 }
@@ -24,7 +24,7 @@ func NewProjectHandler(projectService *services.ProjectService, projectAccessSer
 	}
 }
 
-// CreateProject handles creating a new project
+// CreateProject handles creating a new project in the current organization
 func (h *ProjectHandler) CreateProject(c *gin.Context) {
 	var projectCreate models.ProjectCreate
 	if err := c.ShouldBindJSON(&projectCreate); err != nil {
@@ -40,7 +40,7 @@ func (h *ProjectHandler) CreateProject(c *gin.Context) {
 	}
 	userModel := user.(*models.User)
 
-	project, err := h.projectService.Create(&projectCreate, userModel.ID)
+	project, err := h.projectService.Create(&projectCreate, currentOrganizationID(c), userModel.ID)
 	if err != nil {
 		if err == repository.ErrProjectExists {
 			c.JSON(http.StatusConflict, gin.H{"error": "Project with this name already exists"})
@@ -50,6 +50,9 @@ func (h *ProjectHandler) CreateProject(c *gin.Context) {
 		return
 	}
 
+	setAuditEntity(c, "project", project.ID, project.ID)
+	setAuditAfter(c, project.ToResponse())
+
 	c.JSON(http.StatusCreated, project.ToResponse())
 }
 
@@ -61,28 +64,7 @@ func (h *ProjectHandler) GetProject(c *gin.Context) {
 		return
 	}
 
-	// Get user from context
-	user, exists := c.Get("user")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found in context"})
-		return
-	}
-	userModel := user.(*models.User)
-
-	// Check if user has access to the project
-	hasAccess, err := h.projectAccessService.HasViewAccess(id, userModel.ID)
-	if err != nil {
-		if err == repository.ErrProjectNotFound {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Project not found"})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check project access"})
-		return
-	}
-
-	// Only allow users with access to view the project
-	if !hasAccess && userModel.Role != models.RoleAdmin {
-		c.JSON(http.StatusForbidden, gin.H{"error": "You don't have permission to view this project"})
+	if !authorizeProject(c, h.projectAccessService, id, models.PermissionViewProject) {
 		return
 	}
 
@@ -113,30 +95,21 @@ func (h *ProjectHandler) UpdateProject(c *gin.Context) {
 		return
 	}
 
-	// Get user from context
-	user, exists := c.Get("user")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found in context"})
+	// Only maintainers may change the project details
+	if !authorizeProject(c, h.projectAccessService, id, models.PermissionManageProject) {
 		return
 	}
-	userModel := user.(*models.User)
 
-	// Check if user has edit access to the project
-	hasEditAccess, err := h.projectAccessService.HasEditAccess(id, userModel.ID)
+	before, err := h.projectService.GetByID(id)
 	if err != nil {
 		if err == repository.ErrProjectNotFound {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Project not found"})
 			return
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check project access"})
-		return
-	}
-
-	// Only allow users with edit access to update the project (unless admin)
-	if !hasEditAccess && userModel.Role != models.RoleAdmin {
-		c.JSON(http.StatusForbidden, gin.H{"error": "You don't have permission to update this project"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve project"})
 		return
 	}
+	setAuditBefore(c, before.ToResponse())
 
 	project, err := h.projectService.Update(id, &projectUpdate)
 	if err != nil {
@@ -148,6 +121,9 @@ func (h *ProjectHandler) UpdateProject(c *gin.Context) {
 		return
 	}
 
+	setAuditEntity(c, "project", project.ID, project.ID)
+	setAuditAfter(c, project.ToResponse())
+
 	c.JSON(http.StatusOK, project.ToResponse())
 }
 
@@ -159,29 +135,13 @@ func (h *ProjectHandler) DeleteProject(c *gin.Context) {
 		return
 	}
 
-	// Get user from context
-	user, exists := c.Get("user")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found in context"})
+	// Only the owner may delete the project
+	if !authorizeProject(c, h.projectAccessService, id, models.PermissionOwnProject) {
 		return
 	}
-	userModel := user.(*models.User)
 
-	// Check if user is the owner of the project
-	isOwner, err := h.projectService.IsOwner(id, userModel.ID)
-	if err != nil {
-		if err == repository.ErrProjectNotFound {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Project not found"})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check project ownership"})
-		return
-	}
-
-	// Only allow the owner to delete the project (unless admin)
-	if !isOwner && userModel.Role != models.RoleAdmin {
-		c.JSON(http.StatusForbidden, gin.H{"error": "You don't have permission to delete this project"})
-		return
+	if project, err := h.projectService.GetByID(id); err == nil {
+		setAuditBefore(c, project.ToResponse())
 	}
 
 	if err := h.projectService.Delete(id); err != nil {
@@ -193,13 +153,17 @@ func (h *ProjectHandler) DeleteProject(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Project deleted successfully"})
+	setAuditEntity(c, "project", id, id)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Project moved to the trash"})
 }
 
-// ListProjects handles retrieving all projects with pagination
+// ListProjects handles retrieving the projects of the current organization with pagination.
+// Archived projects are only included with include_archived=true.
 func (h *ProjectHandler) ListProjects(c *gin.Context) {
 	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
 	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "10"))
+	includeArchived, _ := strconv.ParseBool(c.DefaultQuery("include_archived", "false"))
 
 	// Get user from context
 	user, exists := c.Get("user")
@@ -212,11 +176,12 @@ func (h *ProjectHandler) ListProjects(c *gin.Context) {
 	var projects []*models.Project
 	var err error
 
-	// If admin, show all projects, otherwise show only projects the user has access to
-	if userModel.Role == models.RoleAdmin {
-		projects, err = h.projectService.List(page, pageSize)
+	// Organization admins see all projects, others only the projects they have access to
+	organizationID := currentOrganizationID(c)
+	if isOrganizationAdmin(c) {
+		projects, err = h.projectService.List(organizationID, page, pageSize, includeArchived)
 	} else {
-		projects, err = h.projectAccessService.GetAccessibleProjects(userModel.ID, page, pageSize)
+		projects, err = h.projectAccessService.GetAccessibleProjects(organizationID, userModel.ID, page, pageSize, includeArchived)
 	}
 
 	if err != nil {
@@ -0,0 +1,90 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// targetParamEntities maps route parameters to the entity they identify
+var targetParamEntities = map[string]string{
+	"projectId":    "project",
+	"suiteId":      "test_suite",
+	"testCaseId":   "test_case",
+	"stepId":       "test_step",
+	"noteId":       "step_note",
+	"attachmentId": "step_attachment",
+	"runId":        "test_run",
+}
+
+// targetRouteEntities maps the first path segment of routes with an :id parameter to the entity it identifies
+var targetRouteEntities = map[string]string{
+	"projects":        "project",
+	"test-suites":     "test_suite",
+	"test-cases":      "test_case",
+	"custom-fields":   "custom_field",
+	"shared-steps":    "shared_step",
+	"comments":        "comment",
+	"reviews":         "review",
+	"test-executions": "test_execution",
+	"defects":         "defect",
+	"webhooks":        "webhook",
+}
+
+// requestTarget is an entity a request reads or writes
+type requestTarget struct {
+	entityType string
+	id         int64
+}
+
+// requestTargets lists the entities a request reads or writes
+func requestTargets(c *gin.Context) []requestTarget {
+	var targets []requestTarget
+
+	for _, param := range c.Params {
+		entityType, ok := targetParamEntities[param.Key]
+		if param.Key == "id" {
+			segments := strings.Split(strings.TrimPrefix(c.FullPath(), "/api/v1/"), "/")
+			entityType, ok = targetRouteEntities[segments[0]]
+		}
+		if !ok {
+			continue
+		}
+		if id, err := strconv.ParseInt(param.Value, 10, 64); err == nil {
+			targets = append(targets, requestTarget{entityType: entityType, id: id})
+		}
+	}
+
+	// Listing entities can filter them by project
+	if id, err := strconv.ParseInt(c.Query("project_id"), 10, 64); err == nil {
+		targets = append(targets, requestTarget{entityType: "project", id: id})
+	}
+
+	// Creating or moving an entity names its project and suite in the body
+	if c.ContentType() == "application/json" && c.Request.Body != nil {
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			return targets
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		var parents struct {
+			ProjectID int64 `json:"project_id"`
+			SuiteID   int64 `json:"suite_id"`
+		}
+		if json.Unmarshal(body, &parents) == nil {
+			if parents.ProjectID != 0 {
+				targets = append(targets, requestTarget{entityType: "project", id: parents.ProjectID})
+			}
+			if parents.SuiteID != 0 {
+				targets = append(targets, requestTarget{entityType: "test_suite", id: parents.SuiteID})
+			}
+		}
+	}
+
+	return targets
+}
@@ -0,0 +1,160 @@
+package api
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mihaamiharu/test-case-management-be/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+// serveTarget routes a request through a router with a single route and returns the
+// targets and permission found for it, and the body the handler read afterwards
+func serveTarget(t *testing.T, method, route, target, contentType, body string) ([]requestTarget, models.Permission, string) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	var targets []requestTarget
+	var permission models.Permission
+	var handlerBody string
+	router := gin.New()
+	router.Handle(method, route, func(c *gin.Context) {
+		targets = requestTargets(c)
+		permission = requestPermission(c)
+		data, _ := io.ReadAll(c.Request.Body)
+		handlerBody = string(data)
+		c.Status(http.StatusNoContent)
+	})
+
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+
+	return targets, permission, handlerBody
+}
+
+func TestRequestTargets(t *testing.T) {
+	tests := []struct {
+		name        string
+		method      string
+		route       string
+		target      string
+		contentType string
+		body        string
+		want        []requestTarget
+		permission  models.Permission
+	}{
+		{
+			// Test case: a named route parameter identifies its entity
+			name:       "path parameter",
+			method:     http.MethodGet,
+			route:      "/api/v1/project-test-suites/:projectId",
+			target:     "/api/v1/project-test-suites/4",
+			want:       []requestTarget{{entityType: "project", id: 4}},
+			permission: models.PermissionViewProject,
+		},
+		{
+			// Test case: nested parameters each identify their entity
+			name:       "nested path parameters",
+			method:     http.MethodDelete,
+			route:      "/api/v1/test-cases/:testCaseId/steps/:stepId",
+			target:     "/api/v1/test-cases/5/steps/6",
+			want:       []requestTarget{{entityType: "test_case", id: 5}, {entityType: "test_step", id: 6}},
+			permission: models.PermissionEditTests,
+		},
+		{
+			// Test case: an :id parameter identifies the entity of the first path segment
+			name:       "id segment",
+			method:     http.MethodPut,
+			route:      "/api/v1/test-suites/:id",
+			target:     "/api/v1/test-suites/7",
+			want:       []requestTarget{{entityType: "test_suite", id: 7}},
+			permission: models.PermissionEditTests,
+		},
+		{
+			// Test case: an :id parameter of a route without an entity is no target
+			name:       "id segment without entity",
+			method:     http.MethodGet,
+			route:      "/api/v1/users/:id",
+			target:     "/api/v1/users/8",
+			permission: models.PermissionViewProject,
+		},
+		{
+			// Test case: a list filtered by project targets the project
+			name:       "project query",
+			method:     http.MethodGet,
+			route:      "/api/v1/test-suites",
+			target:     "/api/v1/test-suites?project_id=9",
+			want:       []requestTarget{{entityType: "project", id: 9}},
+			permission: models.PermissionViewProject,
+		},
+		{
+			// Test case: the project and suite named in a JSON body are targets
+			name:        "json body",
+			method:      http.MethodPost,
+			route:       "/api/v1/test-cases",
+			target:      "/api/v1/test-cases",
+			contentType: "application/json",
+			body:        `{"project_id": 2, "suite_id": 3, "title": "Login"}`,
+			want:        []requestTarget{{entityType: "project", id: 2}, {entityType: "test_suite", id: 3}},
+			permission:  models.PermissionEditTests,
+		},
+		{
+			// Test case: a body that is not JSON is not read
+			name:        "form body",
+			method:      http.MethodPost,
+			route:       "/api/v1/test-cases",
+			target:      "/api/v1/test-cases",
+			contentType: "application/x-www-form-urlencoded",
+			body:        "project_id=2",
+			permission:  models.PermissionEditTests,
+		},
+		{
+			// Test case: a route without parameters, query or body has no targets
+			name:       "no target",
+			method:     http.MethodGet,
+			route:      "/api/v1/projects",
+			target:     "/api/v1/projects",
+			permission: models.PermissionViewProject,
+		},
+		{
+			// Test case: recording a test result needs the execute permission
+			name:       "result route",
+			method:     http.MethodPost,
+			route:      "/api/v1/test-executions/:id/defects",
+			target:     "/api/v1/test-executions/10/defects",
+			want:       []requestTarget{{entityType: "test_execution", id: 10}},
+			permission: models.PermissionExecuteTests,
+		},
+		{
+			// Test case: routePermissions lowers the permission of a write
+			name:        "route permission",
+			method:      http.MethodPost,
+			route:       "/api/v1/api-tokens",
+			target:      "/api/v1/api-tokens",
+			contentType: "application/json",
+			body:        `{"project_id": 2}`,
+			want:        []requestTarget{{entityType: "project", id: 2}},
+			permission:  models.PermissionViewProject,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			targets, permission, body := serveTarget(t, tt.method, tt.route, tt.target, tt.contentType, tt.body)
+
+			assert.Equal(t, tt.want, targets)
+			assert.Equal(t, tt.permission, permission)
+			// The handler can still read the body after its targets were found
+			assert.Equal(t, tt.body, body)
+		})
+	}
+}
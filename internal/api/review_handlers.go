@@ -0,0 +1,194 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mihaamiharu/test-case-management-be/internal/models"
+	"github.com/mihaamiharu/test-case-management-be/internal/repository"
+	"github.com/mihaamiharu/test-case-management-be/internal/service"
+	"github.com/mihaamiharu/test-case-management-be/internal/services"
+)
+
+// ReviewHandler handles test case review requests
+type ReviewHandler struct {
+	reviewService        *service.ReviewService
+	projectAccessService *services.ProjectAccessService
+}
+
+// NewReviewHandler creates a new review handler
+func NewReviewHandler(reviewService *service.ReviewService, projectAccessService *services.ProjectAccessService) *ReviewHandler {
+	return &ReviewHandler{
+		reviewService:        reviewService,
+		projectAccessService: projectAccessService,
+	}
+}
+
+// GetReviewSettings handles retrieving the review rules of a project
+func (h *ReviewHandler) GetReviewSettings(c *gin.Context) {
+	projectID, err := strconv.ParseInt(c.Param("projectId"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid project ID"})
+		return
+	}
+
+	settings, err := h.reviewService.GetSettings(projectID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, settings)
+}
+
+// UpdateReviewSettings handles changing the review rules of a project
+func (h *ReviewHandler) UpdateReviewSettings(c *gin.Context) {
+	projectID, err := strconv.ParseInt(c.Param("projectId"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid project ID"})
+		return
+	}
+
+	var settingsUpdate models.ReviewSettingsUpdate
+	if err := c.ShouldBindJSON(&settingsUpdate); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Get user from context
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found in context"})
+		return
+	}
+	userModel := user.(*models.User)
+
+	// Only maintainers may change the review rules of a project
+	if !authorizeProject(c, h.projectAccessService, projectID, models.PermissionManageProject) {
+		return
+	}
+
+	settings, err := h.reviewService.UpdateSettings(projectID, *settingsUpdate.RequiredApprovals, userModel.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, settings)
+}
+
+// RequestReview handles requesting a review of a test case from specific users
+func (h *ReviewHandler) RequestReview(c *gin.Context) {
+	testCaseID, err := strconv.ParseInt(c.Param("testCaseId"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid test case ID"})
+		return
+	}
+
+	var reviewRequest models.ReviewRequest
+	if err := c.ShouldBindJSON(&reviewRequest); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Get user ID from context (set by auth middleware)
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	reviews, err := h.reviewService.RequestReview(testCaseID, reviewRequest.ReviewerIDs, userID.(int64))
+	if err != nil {
+		handleReviewError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, reviews)
+}
+
+// ListReviews handles listing the reviews of a test case
+func (h *ReviewHandler) ListReviews(c *gin.Context) {
+	testCaseID, err := strconv.ParseInt(c.Param("testCaseId"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid test case ID"})
+		return
+	}
+
+	reviews, err := h.reviewService.ListReviews(testCaseID)
+	if err != nil {
+		handleReviewError(c, err)
+		return
+	}
+
+	if reviews == nil {
+		reviews = []*models.TestCaseReview{}
+	}
+
+	c.JSON(http.StatusOK, reviews)
+}
+
+// ApproveReview handles a reviewer approving a test case version
+func (h *ReviewHandler) ApproveReview(c *gin.Context) {
+	h.decide(c, h.reviewService.Approve)
+}
+
+// RequestChanges handles a reviewer requesting changes to a test case version
+func (h *ReviewHandler) RequestChanges(c *gin.Context) {
+	h.decide(c, h.reviewService.RequestChanges)
+}
+
+func (h *ReviewHandler) decide(c *gin.Context, decide func(reviewID, userID int64, comment string) (*models.TestCaseReview, error)) {
+	reviewID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid review ID"})
+		return
+	}
+
+	var decision models.ReviewDecision
+	if err := c.ShouldBindJSON(&decision); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Get user ID from context (set by auth middleware)
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	review, err := decide(reviewID, userID.(int64), decision.Comment)
+	if err != nil {
+		handleReviewError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, review)
+}
+
+// handleReviewError maps review service errors to HTTP responses
+func handleReviewError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, repository.ErrTestCaseNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "test case not found"})
+	case errors.Is(err, repository.ErrReviewNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "review not found"})
+	case errors.Is(err, repository.ErrUserNotFound):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "reviewer not found"})
+	case errors.Is(err, service.ErrNotReviewer):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, repository.ErrReviewAlreadyRequested),
+		errors.Is(err, service.ErrReviewAlreadyDecided),
+		errors.Is(err, service.ErrReviewOutdated):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrSelfReview),
+		errors.Is(err, service.ErrReviewCommentRequired),
+		errors.Is(err, service.ErrTestCaseNotReviewable):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -2,6 +2,8 @@ package api
 
 import (
 	"github.com/gin-gonic/gin"
+	"github.com/mihaamiharu/test-case-management-be/internal/middleware"
+	"github.com/mihaamiharu/test-case-management-be/internal/models"
 )
 
 // SetupRouter configures the API routes
@@ -12,6 +14,28 @@ func SetupRouter(
 	testSuiteHandler *TestSuiteHandler,
 	testCaseHandler *TestCaseHandler,
 	tagHandler *TagHandler,
+	sharedStepHandler *SharedStepHandler,
+	testExecutionHandler *TestExecutionHandler,
+	commentHandler *CommentHandler,
+	reviewHandler *ReviewHandler,
+	customFieldHandler *CustomFieldHandler,
+	workflowHandler *WorkflowHandler,
+	auditHandler *AuditHandler,
+	trashHandler *TrashHandler,
+	archiveHandler *ArchiveHandler,
+	apiTokenHandler *APITokenHandler,
+	userHandler *UserHandler,
+	accountHandler *AccountHandler,
+	loginProtectionHandler *LoginProtectionHandler,
+	twoFactorHandler *TwoFactorHandler,
+	projectAccessHandler *ProjectAccessHandler,
+	teamHandler *TeamHandler,
+	organizationHandler *OrganizationHandler,
+	eventHandler *EventHandler,
+	defectHandler *DefectHandler,
+	webhookHandler *WebhookHandler,
+	notificationHandler *NotificationHandler,
+	issueTrackerHandler *IssueTrackerHandler,
 ) {
 	// Public routes
 	public := router.Group("/api/v1")
@@ -19,32 +43,100 @@ func SetupRouter(
 		auth := public.Group("/auth")
 		{
 			auth.POST("/register", authHandler.Register)
-			auth.POST("/login", authHandler.Login)
+			auth.POST("/login", loginProtectionHandler.LoginProtectionMiddleware(), authHandler.Login)
+			auth.POST("/2fa/verify", loginProtectionHandler.TwoFactorProtectionMiddleware(), twoFactorHandler.Verify)
+			auth.POST("/refresh", authHandler.Refresh)
+			auth.POST("/verify-email", accountHandler.VerifyEmail)
+			auth.POST("/forgot-password", accountHandler.ForgotPassword)
+			auth.POST("/reset-password", accountHandler.ResetPassword)
+			auth.GET("/oidc/login", authHandler.OIDCLogin)
+			auth.GET("/oidc/callback", authHandler.OIDCCallback)
 		}
 
-		// Temporarily make these endpoints public for testing
-		testSuites := public.Group("/test-suites")
+		// Issue changes sent by the issue tracker of a project, signed with its webhook secret
+		public.POST("/issue-tracker-webhooks/:projectId", issueTrackerHandler.ReceiveWebhook)
+
+	}
+
+	// Protected routes
+	protected := router.Group("/api/v1")
+	protected.Use(authHandler.AuthMiddleware())
+	protected.Use(apiTokenHandler.ScopeMiddleware())
+	protected.Use(organizationHandler.OrganizationMiddleware())
+	protected.Use(projectAccessHandler.PermissionMiddleware())
+	protected.Use(auditHandler.AuditMiddleware())
+	protected.Use(archiveHandler.ReadOnlyMiddleware())
+	{
+		// Sessions and the profile of the current user
+		authProtected := protected.Group("/auth")
 		{
-			testSuites.GET("", testSuiteHandler.ListTestSuites)
-			testSuites.GET("/:id", testSuiteHandler.GetTestSuite)
+			authProtected.GET("/me", authHandler.Me)
+			authProtected.PUT("/me", userHandler.UpdateProfile)
+			authProtected.PUT("/password", userHandler.ChangePassword)
+			authProtected.POST("/verify-email/resend", accountHandler.ResendVerification)
+			authProtected.POST("/logout", authHandler.Logout)
+			authProtected.GET("/sessions", authHandler.ListSessions)
+			authProtected.DELETE("/sessions/:id", authHandler.RevokeSession)
 		}
 
-		tags := public.Group("/tags")
+		// Two-factor authentication of the current user
+		twoFactor := protected.Group("/auth/2fa")
 		{
-			tags.GET("", tagHandler.ListTags)
-			tags.GET("/:id", tagHandler.GetTag)
+			twoFactor.GET("", twoFactorHandler.GetStatus)
+			twoFactor.POST("/setup", twoFactorHandler.Setup)
+			twoFactor.POST("/enable", twoFactorHandler.Enable)
+			twoFactor.POST("/disable", twoFactorHandler.Disable)
+			twoFactor.POST("/recovery-codes", twoFactorHandler.RegenerateRecoveryCodes)
 		}
 
-		testCases := public.Group("/test-cases")
+		// Personal API tokens
+		apiTokens := protected.Group("/api-tokens")
 		{
-			testCases.GET("/:id", testCaseHandler.GetTestCase)
+			apiTokens.POST("", apiTokenHandler.CreateAPIToken)
+			apiTokens.GET("", apiTokenHandler.ListAPITokens)
+			apiTokens.DELETE("/:id", apiTokenHandler.RevokeAPIToken)
+		}
+
+		// User administration
+		adminUsers := protected.Group("/admin/users")
+		adminUsers.Use(middleware.RoleMiddleware(models.RoleAdmin))
+		{
+			adminUsers.GET("", userHandler.ListUsers)
+			adminUsers.GET("/:id", userHandler.GetUser)
+			adminUsers.PUT("/:id/role", userHandler.UpdateUserRole)
+			adminUsers.POST("/:id/deactivate", userHandler.DeactivateUser)
+			adminUsers.POST("/:id/reactivate", userHandler.ReactivateUser)
+			adminUsers.POST("/:id/password-reset", userHandler.ForcePasswordReset)
+			adminUsers.POST("/:id/unlock", loginProtectionHandler.UnlockUser)
+			adminUsers.DELETE("/:id/2fa", twoFactorHandler.ResetUserTwoFactor)
+		}
+
+		// Organizations of the current user and their members
+		organizations := protected.Group("/organizations")
+		{
+			organizations.GET("", organizationHandler.ListOrganizations)
+			organizations.POST("", organizationHandler.CreateOrganization)
+			organizations.GET("/:id", organizationHandler.GetOrganization)
+			organizations.PUT("/:id", organizationHandler.UpdateOrganization)
+			organizations.GET("/:id/members", organizationHandler.ListMembers)
+			organizations.POST("/:id/members", organizationHandler.AddMember)
+			organizations.PUT("/:id/members/:userId", organizationHandler.UpdateMember)
+			organizations.DELETE("/:id/members/:userId", organizationHandler.RemoveMember)
+		}
+
+		// Teams of the current organization; only organization admins can change them
+		teams := protected.Group("/teams")
+		{
+			teams.GET("", teamHandler.ListTeams)
+			teams.GET("/:id", teamHandler.GetTeam)
+			teams.GET("/:id/members", teamHandler.ListMembers)
+			teams.POST("", organizationHandler.RequireOrganizationAdmin(), teamHandler.CreateTeam)
+			teams.PUT("/:id", organizationHandler.RequireOrganizationAdmin(), teamHandler.UpdateTeam)
+			teams.DELETE("/:id", organizationHandler.RequireOrganizationAdmin(), teamHandler.DeleteTeam)
+			teams.POST("/:id/members", organizationHandler.RequireOrganizationAdmin(), teamHandler.AddMembers)
+			teams.DELETE("/:id/members/:userId", organizationHandler.RequireOrganizationAdmin(), teamHandler.RemoveMember)
 		}
-	}
 
-	// Protected routes
-	protected := router.Group("/api/v1")
-	protected.Use(authHandler.AuthMiddleware())
-	{
 		// Projects
 		projects := protected.Group("/projects")
 		{
@@ -53,39 +145,127 @@ func SetupRouter(
 			projects.GET("/:id", projectHandler.GetProject)
 			projects.PUT("/:id", projectHandler.UpdateProject)
 			projects.DELETE("/:id", projectHandler.DeleteProject)
+			projects.POST("/:id/restore", trashHandler.RestoreProject)
+			projects.POST("/:id/archive", archiveHandler.ArchiveProject)
+			projects.POST("/:id/unarchive", archiveHandler.UnarchiveProject)
+			projects.POST("/:id/transfer", projectAccessHandler.TransferOwnership)
+
+			// Project roles of users and teams
+			projects.GET("/:id/access", projectAccessHandler.ListAccess)
+			projects.POST("/:id/access", projectAccessHandler.GrantAccess)
+			projects.PUT("/:id/access/:accessId", projectAccessHandler.UpdateAccess)
+			projects.DELETE("/:id/access/:accessId", projectAccessHandler.RevokeAccess)
+			projects.GET("/:id/team-access", projectAccessHandler.ListTeamAccess)
+			projects.POST("/:id/team-access", projectAccessHandler.GrantTeamAccess)
+			projects.PUT("/:id/team-access/:accessId", projectAccessHandler.UpdateTeamAccess)
+			projects.DELETE("/:id/team-access/:accessId", projectAccessHandler.RevokeTeamAccess)
+			projects.GET("/:id/effective-access", projectAccessHandler.GetEffectiveAccess)
+
+			// Realtime events of a project
+			projects.GET("/:id/events", eventHandler.StreamProjectEvents)
+
+			// Webhooks of a project
+			projects.GET("/:id/webhooks", webhookHandler.ListWebhooks)
+
+			// Issue tracker of a project
+			projects.GET("/:id/issue-tracker", issueTrackerHandler.GetIssueTracker)
+			projects.PUT("/:id/issue-tracker", issueTrackerHandler.ConfigureIssueTracker)
+			projects.DELETE("/:id/issue-tracker", issueTrackerHandler.DeleteIssueTracker)
+			projects.POST("/:id/issue-tracker/sync", issueTrackerHandler.SyncIssueTracker)
 		}
 
+		// Trash
+		protected.GET("/trashed-projects", trashHandler.ListTrashedProjects)
+		protected.GET("/project-trash/:projectId", trashHandler.ListProjectTrash)
+
 		// Project test suites
 		protected.GET("/project-test-suites/:projectId", testSuiteHandler.ListTestSuitesByProject)
 
 		// Project test cases
 		protected.GET("/project-test-cases/:projectId", testCaseHandler.ListTestCasesByProject)
+		protected.GET("/project-test-cases-export/:projectId", testCaseHandler.ExportTestCases)
+		protected.POST("/project-test-cases-import/:projectId", testCaseHandler.ImportTestCases)
 
-		// Test Suites (protected operations)
+		// Project workflows
+		protected.GET("/project-workflow/:projectId", workflowHandler.GetWorkflow)
+		protected.PUT("/project-workflow/:projectId", workflowHandler.UpdateWorkflow)
+
+		// Audit log
+		protected.GET("/audit-log", auditHandler.ListAuditLog)
+		protected.GET("/audit-log/export", auditHandler.ExportAuditLog)
+
+		// Custom fields
+		protected.GET("/project-custom-fields/:projectId", customFieldHandler.ListCustomFields)
+		customFields := protected.Group("/custom-fields")
+		{
+			customFields.POST("", customFieldHandler.CreateCustomField)
+			customFields.PUT("/:id", customFieldHandler.UpdateCustomField)
+			customFields.DELETE("/:id", customFieldHandler.DeleteCustomField)
+		}
+
+		// Test Suites
 		testSuitesProtected := protected.Group("/test-suites")
 		{
+			testSuitesProtected.GET("", testSuiteHandler.ListTestSuites)
+			testSuitesProtected.GET("/:id", testSuiteHandler.GetTestSuite)
 			testSuitesProtected.POST("", testSuiteHandler.CreateTestSuite)
 			testSuitesProtected.PUT("/:id", testSuiteHandler.UpdateTestSuite)
 			testSuitesProtected.DELETE("/:id", testSuiteHandler.DeleteTestSuite)
+			testSuitesProtected.POST("/:id/restore", trashHandler.RestoreTestSuite)
+			testSuitesProtected.POST("/:id/archive", archiveHandler.ArchiveTestSuite)
+			testSuitesProtected.POST("/:id/unarchive", archiveHandler.UnarchiveTestSuite)
 		}
 
 		// Suite test cases
 		protected.GET("/suite-test-cases/:suiteId", testCaseHandler.ListTestCasesBySuite)
 
-		// Test Cases (protected operations)
+		// Test Cases
 		testCasesProtected := protected.Group("/test-cases")
 		{
+			testCasesProtected.GET("/:id", testCaseHandler.GetTestCase)
 			testCasesProtected.POST("", testCaseHandler.CreateTestCase)
 			testCasesProtected.PUT("/:id", testCaseHandler.UpdateTestCase)
 			testCasesProtected.DELETE("/:id", testCaseHandler.DeleteTestCase)
+			testCasesProtected.POST("/:id/restore", trashHandler.RestoreTestCase)
 		}
 
 		// Test case steps
 		protected.POST("/test-case-steps/:testCaseId", testCaseHandler.AddTestStep)
+		protected.PUT("/test-case-steps/:testCaseId/order", testCaseHandler.ReorderTestSteps)
+
+		// Test case data rows and expanded scenarios
+		protected.GET("/test-case-data-rows/:testCaseId", testCaseHandler.GetDataRows)
+		protected.PUT("/test-case-data-rows/:testCaseId", testCaseHandler.UpdateDataRows)
+		protected.GET("/test-case-scenarios/:testCaseId", testCaseHandler.GetScenarios)
+
+		// Test case comments and activity
+		protected.GET("/test-case-comments/:testCaseId", commentHandler.ListComments)
+		protected.POST("/test-case-comments/:testCaseId", commentHandler.CreateComment)
+		protected.GET("/test-case-activity/:testCaseId", commentHandler.GetActivity)
+
+		// Test case reviews
+		protected.GET("/test-case-reviews/:testCaseId", reviewHandler.ListReviews)
+		protected.POST("/test-case-reviews/:testCaseId", reviewHandler.RequestReview)
+		protected.POST("/reviews/:id/approve", reviewHandler.ApproveReview)
+		protected.POST("/reviews/:id/request-changes", reviewHandler.RequestChanges)
+
+		// Project review settings
+		protected.GET("/project-review-settings/:projectId", reviewHandler.GetReviewSettings)
+		protected.PUT("/project-review-settings/:projectId", reviewHandler.UpdateReviewSettings)
+
+		// Comments
+		comments := protected.Group("/comments")
+		{
+			comments.PUT("/:id", commentHandler.UpdateComment)
+			comments.DELETE("/:id", commentHandler.DeleteComment)
+			comments.POST("/:id/resolve", commentHandler.ResolveComment)
+			comments.DELETE("/:id/resolve", commentHandler.UnresolveComment)
+		}
 
 		// Test Steps
 		protected.PUT("/test-steps/:stepId", testCaseHandler.UpdateTestStep)
 		protected.DELETE("/test-steps/:stepId", testCaseHandler.DeleteTestStep)
+		protected.PUT("/test-steps/:stepId/position", testCaseHandler.MoveTestStep)
 
 		// Step notes
 		protected.POST("/step-notes/:stepId", testCaseHandler.AddStepNote)
@@ -95,9 +275,63 @@ func SetupRouter(
 		protected.POST("/step-attachments/:stepId", testCaseHandler.UploadStepAttachment)
 		protected.DELETE("/step-attachments/:attachmentId", testCaseHandler.DeleteStepAttachment)
 
-		// Tags (protected operations)
+		// Project shared steps
+		protected.GET("/project-shared-steps/:projectId", sharedStepHandler.ListSharedStepsByProject)
+
+		// Shared steps
+		sharedSteps := protected.Group("/shared-steps")
+		{
+			sharedSteps.POST("", sharedStepHandler.CreateSharedStep)
+			sharedSteps.GET("/:id", sharedStepHandler.GetSharedStep)
+			sharedSteps.PUT("/:id", sharedStepHandler.UpdateSharedStep)
+			sharedSteps.DELETE("/:id", sharedStepHandler.DeleteSharedStep)
+			sharedSteps.GET("/:id/usage", sharedStepHandler.GetSharedStepUsage)
+		}
+
+		// Test run executions
+		protected.POST("/test-run-cases/:runId", testExecutionHandler.AddTestCaseToRun)
+		protected.GET("/test-run-executions/:runId", testExecutionHandler.ListExecutionsByRun)
+		protected.GET("/test-executions/:id", testExecutionHandler.GetExecution)
+		protected.PUT("/test-executions/:id", testExecutionHandler.RecordResult)
+		protected.PUT("/test-run-assignee/:runId", testExecutionHandler.AssignRun)
+
+		// Defects found by test executions
+		protected.POST("/test-executions/:id/defects", defectHandler.CreateDefect)
+		protected.GET("/test-executions/:id/defects", defectHandler.ListDefectsByExecution)
+		protected.GET("/defects/:id", defectHandler.GetDefect)
+		protected.POST("/defects/:id/external-issue", issueTrackerHandler.CreateExternalIssue)
+
+		// Webhooks
+		webhooks := protected.Group("/webhooks")
+		{
+			webhooks.POST("", webhookHandler.CreateWebhook)
+			webhooks.GET("/:id", webhookHandler.GetWebhook)
+			webhooks.PUT("/:id", webhookHandler.UpdateWebhook)
+			webhooks.DELETE("/:id", webhookHandler.DeleteWebhook)
+			webhooks.GET("/:id/deliveries", webhookHandler.ListDeliveries)
+			webhooks.POST("/:id/deliveries/:deliveryId/redeliver", webhookHandler.Redeliver)
+		}
+
+		// Notifications of the current user
+		notifications := protected.Group("/notifications")
+		{
+			notifications.GET("", notificationHandler.ListNotifications)
+			notifications.GET("/unread-count", notificationHandler.CountUnread)
+			notifications.POST("/read-all", notificationHandler.MarkAllRead)
+			notifications.POST("/:id/read", notificationHandler.MarkRead)
+			notifications.POST("/:id/unread", notificationHandler.MarkUnread)
+		}
+		protected.GET("/notification-preferences", notificationHandler.GetPreferences)
+		protected.PUT("/notification-preferences", notificationHandler.UpdatePreferences)
+
+		// Realtime events of a test run
+		protected.GET("/test-run-events/:runId", eventHandler.StreamTestRunEvents)
+
+		// Tags of the current organization
 		tagsProtected := protected.Group("/tags")
 		{
+			tagsProtected.GET("", tagHandler.ListTags)
+			tagsProtected.GET("/:id", tagHandler.GetTag)
 			tagsProtected.POST("", tagHandler.CreateTag)
 			tagsProtected.DELETE("/:id", tagHandler.DeleteTag)
 		}
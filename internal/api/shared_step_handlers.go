@@ -0,0 +1,227 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mihaamiharu/test-case-management-be/internal/models"
+	"github.com/mihaamiharu/test-case-management-be/internal/repository"
+	"github.com/mihaamiharu/test-case-management-be/internal/service"
+)
+
+// SharedStepHandler handles shared step library requests
+type SharedStepHandler struct {
+	sharedStepService *service.SharedStepService
+}
+
+// NewSharedStepHandler creates a new shared step handler
+func NewSharedStepHandler(sharedStepService *service.SharedStepService) *SharedStepHandler {
+	return &SharedStepHandler{
+		sharedStepService: sharedStepService,
+	}
+}
+
+// CreateSharedStep handles creating a new shared step
+func (h *SharedStepHandler) CreateSharedStep(c *gin.Context) {
+	var sharedStepCreate models.SharedStepCreate
+	if err := c.ShouldBindJSON(&sharedStepCreate); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Get user ID from context (set by auth middleware)
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	sharedStep := &models.SharedStep{
+		ProjectID:   sharedStepCreate.ProjectID,
+		Name:        sharedStepCreate.Name,
+		Description: sharedStepCreate.Description,
+		CreatedBy:   userID.(int64),
+		UpdatedBy:   userID.(int64),
+		Items:       toSharedStepItems(sharedStepCreate.Items),
+	}
+
+	err := h.sharedStepService.CreateSharedStep(sharedStep)
+	if err != nil {
+		if err == repository.ErrSharedStepExists {
+			c.JSON(http.StatusConflict, gin.H{"error": "Shared step with this name already exists in this project"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create shared step"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, sharedStep.ToResponse())
+}
+
+// GetSharedStep handles retrieving a shared step by ID
+func (h *SharedStepHandler) GetSharedStep(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid shared step ID"})
+		return
+	}
+
+	sharedStep, err := h.sharedStepService.GetSharedStepByID(id)
+	if err != nil {
+		if err == repository.ErrSharedStepNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Shared step not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve shared step"})
+		return
+	}
+
+	c.JSON(http.StatusOK, sharedStep.ToResponse())
+}
+
+// UpdateSharedStep handles updating a shared step and its items
+func (h *SharedStepHandler) UpdateSharedStep(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid shared step ID"})
+		return
+	}
+
+	// Get existing shared step
+	sharedStep, err := h.sharedStepService.GetSharedStepByID(id)
+	if err != nil {
+		if err == repository.ErrSharedStepNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Shared step not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve shared step"})
+		return
+	}
+
+	var sharedStepUpdate models.SharedStepUpdate
+	if err := c.ShouldBindJSON(&sharedStepUpdate); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Get user ID from context (set by auth middleware)
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	// Update fields if provided
+	if sharedStepUpdate.Name != "" {
+		sharedStep.Name = sharedStepUpdate.Name
+	}
+	if sharedStepUpdate.Description != "" {
+		sharedStep.Description = sharedStepUpdate.Description
+	}
+	if sharedStepUpdate.Items != nil {
+		sharedStep.Items = toSharedStepItems(sharedStepUpdate.Items)
+	}
+	sharedStep.UpdatedBy = userID.(int64)
+
+	err = h.sharedStepService.UpdateSharedStep(sharedStep)
+	if err != nil {
+		switch err {
+		case repository.ErrSharedStepExists:
+			c.JSON(http.StatusConflict, gin.H{"error": "Shared step with this name already exists in this project"})
+		case repository.ErrSharedStepNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"error": "Shared step not found"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update shared step"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, sharedStep.ToResponse())
+}
+
+// DeleteSharedStep handles deleting a shared step
+func (h *SharedStepHandler) DeleteSharedStep(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid shared step ID"})
+		return
+	}
+
+	err = h.sharedStepService.DeleteSharedStep(id)
+	if err != nil {
+		switch err {
+		case repository.ErrSharedStepNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"error": "Shared step not found"})
+		case repository.ErrSharedStepInUse:
+			c.JSON(http.StatusConflict, gin.H{"error": "Shared step is still used by test cases"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete shared step"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Shared step deleted successfully"})
+}
+
+// ListSharedStepsByProject handles listing the shared step library of a project
+func (h *SharedStepHandler) ListSharedStepsByProject(c *gin.Context) {
+	projectID, err := strconv.ParseInt(c.Param("projectId"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid project ID"})
+		return
+	}
+
+	sharedSteps, err := h.sharedStepService.ListSharedStepsByProject(projectID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve shared steps"})
+		return
+	}
+
+	// Convert to response objects
+	responses := make([]*models.SharedStepResponse, 0, len(sharedSteps))
+	for _, sharedStep := range sharedSteps {
+		responses = append(responses, sharedStep.ToResponse())
+	}
+
+	c.JSON(http.StatusOK, responses)
+}
+
+// GetSharedStepUsage handles listing the test cases that use a shared step
+func (h *SharedStepHandler) GetSharedStepUsage(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid shared step ID"})
+		return
+	}
+
+	usage, err := h.sharedStepService.GetSharedStepUsage(id)
+	if err != nil {
+		if err == repository.ErrSharedStepNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Shared step not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve shared step usage"})
+		return
+	}
+
+	// Always return an array (empty if no results)
+	if usage == nil {
+		usage = []*models.SharedStepUsage{}
+	}
+
+	c.JSON(http.StatusOK, usage)
+}
+
+// toSharedStepItems converts item create requests to shared step items
+func toSharedStepItems(itemCreates []*models.SharedStepItemCreate) []*models.SharedStepItem {
+	items := make([]*models.SharedStepItem, len(itemCreates))
+	for i, itemCreate := range itemCreates {
+		items[i] = &models.SharedStepItem{
+			StepType:       itemCreate.StepType,
+			Description:    itemCreate.Description,
+			ExpectedResult: itemCreate.ExpectedResult,
+		}
+	}
+	return items
+}
@@ -22,9 +22,9 @@ func NewTagHandler(tagService *service.TagService) *TagHandler {
 	}
 }
 
-// ListTags handles listing all tags
+// ListTags handles listing all tags of the current organization
 func (h *TagHandler) ListTags(c *gin.Context) {
-	tags, err := h.tagService.ListTags()
+	tags, err := h.tagService.ListTags(currentOrganizationID(c))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve tags"})
 		return
@@ -49,15 +49,23 @@ func (h *TagHandler) CreateTag(c *gin.Context) {
 
 	// Create tag from request data
 	tag := &models.Tag{
-		Name: tagCreate.Name,
+		OrganizationID: currentOrganizationID(c),
+		Name:           tagCreate.Name,
 	}
 
 	err := h.tagService.CreateTag(tag)
 	if err != nil {
+		if err == repository.ErrTagExists {
+			c.JSON(http.StatusConflict, gin.H{"error": "Tag with this name already exists"})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create tag"})
 		return
 	}
 
+	setAuditEntity(c, "tag", tag.ID, 0)
+	setAuditAfter(c, tag.ToResponse())
+
 	c.JSON(http.StatusCreated, tag.ToResponse())
 }
 
@@ -69,7 +77,7 @@ func (h *TagHandler) GetTag(c *gin.Context) {
 		return
 	}
 
-	tag, err := h.tagService.GetTagByID(id)
+	tag, err := h.tagService.GetTagByID(currentOrganizationID(c), id)
 	if err != nil {
 		if err == repository.ErrTagNotFound {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Tag not found"})
@@ -90,7 +98,12 @@ func (h *TagHandler) DeleteTag(c *gin.Context) {
 		return
 	}
 
-	err = h.tagService.DeleteTag(id)
+	if tag, err := h.tagService.GetTagByID(currentOrganizationID(c), id); err == nil {
+		setAuditEntity(c, "tag", tag.ID, 0)
+		setAuditBefore(c, tag.ToResponse())
+	}
+
+	err = h.tagService.DeleteTag(currentOrganizationID(c), id)
 	if err != nil {
 		if err == repository.ErrTagNotFound {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Tag not found"})
@@ -0,0 +1,234 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mihaamiharu/test-case-management-be/internal/models"
+	"github.com/mihaamiharu/test-case-management-be/internal/repository"
+	"github.com/mihaamiharu/test-case-management-be/internal/service"
+)
+
+// TeamHandler handles team and team membership endpoints
+type TeamHandler struct {
+	teamService *service.TeamService
+}
+
+// NewTeamHandler creates a new team handler
+func NewTeamHandler(teamService *service.TeamService) *TeamHandler {
+	return &TeamHandler{
+		teamService: teamService,
+	}
+}
+
+// ListTeams handles listing all teams of the current organization, or the teams of the
+// current user with mine=true
+func (h *TeamHandler) ListTeams(c *gin.Context) {
+	mine, _ := strconv.ParseBool(c.DefaultQuery("mine", "false"))
+
+	var teams []*models.Team
+	var err error
+	if mine {
+		teams, err = h.teamService.ListByMember(currentOrganizationID(c), c.GetInt64("userID"))
+	} else {
+		teams, err = h.teamService.List(currentOrganizationID(c))
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list teams"})
+		return
+	}
+
+	if teams == nil {
+		teams = []*models.Team{}
+	}
+
+	c.JSON(http.StatusOK, teams)
+}
+
+// GetTeam handles retrieving a team by ID
+func (h *TeamHandler) GetTeam(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid team ID"})
+		return
+	}
+
+	team, err := h.teamService.Get(currentOrganizationID(c), id)
+	if err != nil {
+		h.respondWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, team)
+}
+
+// CreateTeam handles creating a team
+func (h *TeamHandler) CreateTeam(c *gin.Context) {
+	var teamCreate models.TeamCreate
+	if err := c.ShouldBindJSON(&teamCreate); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	team, err := h.teamService.Create(currentOrganizationID(c), c.GetInt64("userID"), &teamCreate)
+	if err != nil {
+		h.respondWithError(c, err)
+		return
+	}
+
+	setAuditEntity(c, "team", team.ID, 0)
+	setAuditAfter(c, team)
+
+	c.JSON(http.StatusCreated, team)
+}
+
+// UpdateTeam handles renaming a team and changing its description
+func (h *TeamHandler) UpdateTeam(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid team ID"})
+		return
+	}
+
+	var teamUpdate models.TeamUpdate
+	if err := c.ShouldBindJSON(&teamUpdate); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if before, err := h.teamService.Get(currentOrganizationID(c), id); err == nil {
+		setAuditBefore(c, before)
+	}
+
+	team, err := h.teamService.Update(currentOrganizationID(c), id, &teamUpdate)
+	if err != nil {
+		h.respondWithError(c, err)
+		return
+	}
+
+	setAuditEntity(c, "team", team.ID, 0)
+	setAuditAfter(c, team)
+
+	c.JSON(http.StatusOK, team)
+}
+
+// DeleteTeam handles deleting a team. Its members lose the project access they had through it.
+func (h *TeamHandler) DeleteTeam(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid team ID"})
+		return
+	}
+
+	if before, err := h.teamService.Get(currentOrganizationID(c), id); err == nil {
+		setAuditBefore(c, before)
+	}
+
+	if err := h.teamService.Delete(currentOrganizationID(c), id); err != nil {
+		h.respondWithError(c, err)
+		return
+	}
+
+	setAuditEntity(c, "team", id, 0)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Team deleted successfully"})
+}
+
+// ListMembers handles listing the members of a team
+func (h *TeamHandler) ListMembers(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid team ID"})
+		return
+	}
+
+	members, err := h.teamService.ListMembers(currentOrganizationID(c), id)
+	if err != nil {
+		h.respondWithError(c, err)
+		return
+	}
+
+	if members == nil {
+		members = []*models.TeamMember{}
+	}
+
+	c.JSON(http.StatusOK, members)
+}
+
+// AddMembers handles adding users to a team
+func (h *TeamHandler) AddMembers(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid team ID"})
+		return
+	}
+
+	var memberAdd models.TeamMemberAdd
+	if err := c.ShouldBindJSON(&memberAdd); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if before, err := h.teamService.ListMembers(currentOrganizationID(c), id); err == nil {
+		setAuditBefore(c, before)
+	}
+
+	members, err := h.teamService.AddMembers(currentOrganizationID(c), id, memberAdd.UserIDs)
+	if err != nil {
+		h.respondWithError(c, err)
+		return
+	}
+
+	setAuditEntity(c, "team", id, 0)
+	setAuditAction(c, models.AuditActionUpdate)
+	setAuditAfter(c, members)
+
+	c.JSON(http.StatusOK, members)
+}
+
+// RemoveMember handles removing a user from a team
+func (h *TeamHandler) RemoveMember(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid team ID"})
+		return
+	}
+	userID, err := strconv.ParseInt(c.Param("userId"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	if before, err := h.teamService.ListMembers(currentOrganizationID(c), id); err == nil {
+		setAuditBefore(c, before)
+	}
+
+	if err := h.teamService.RemoveMember(currentOrganizationID(c), id, userID); err != nil {
+		h.respondWithError(c, err)
+		return
+	}
+
+	setAuditEntity(c, "team", id, 0)
+	setAuditAction(c, models.AuditActionUpdate)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Team member removed successfully"})
+}
+
+// respondWithError maps team service errors to responses
+func (h *TeamHandler) respondWithError(c *gin.Context, err error) {
+	switch err {
+	case repository.ErrTeamNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": "Team not found"})
+	case repository.ErrUserNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+	case repository.ErrOrganizationMemberNotFound:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Only members of the organization can join its teams"})
+	case repository.ErrTeamMemberNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case repository.ErrTeamExists:
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -53,6 +53,7 @@ func (h *TestCaseHandler) CreateTestCase(c *gin.Context) {
 		Priority:      testCaseCreate.Priority,
 		CreatedBy:     userID.(int64),
 		UpdatedBy:     userID.(int64),
+		CustomFields:  testCaseCreate.CustomFields,
 	}
 
 	// Convert step creates to steps
@@ -63,6 +64,7 @@ func (h *TestCaseHandler) CreateTestCase(c *gin.Context) {
 				StepType:       stepCreate.StepType,
 				Description:    stepCreate.Description,
 				ExpectedResult: stepCreate.ExpectedResult,
+				SharedStepID:   stepCreate.SharedStepID,
 			}
 		}
 		testCase.Steps = steps
@@ -79,10 +81,21 @@ func (h *TestCaseHandler) CreateTestCase(c *gin.Context) {
 	// Create test case with tags
 	err := h.testCaseService.CreateTestCase(testCase, tagIDs)
 	if err != nil {
+		if isSharedStepReferenceError(err) || isCustomFieldError(err) || isWorkflowValueError(err) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, service.ErrApprovalRequired) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
+	setAuditEntity(c, "test_case", testCase.ID, testCase.ProjectID)
+	setAuditAfter(c, testCase.ToResponse())
+
 	c.JSON(http.StatusCreated, testCase.ToResponse())
 }
 
@@ -126,6 +139,8 @@ func (h *TestCaseHandler) UpdateTestCase(c *gin.Context) {
 		return
 	}
 
+	setAuditBefore(c, testCase.ToResponse())
+
 	// Parse update data
 	var testCaseUpdate models.TestCaseUpdate
 	if err := c.ShouldBindJSON(&testCaseUpdate); err != nil {
@@ -161,16 +176,34 @@ func (h *TestCaseHandler) UpdateTestCase(c *gin.Context) {
 	// Update steps if provided
 	if testCaseUpdate.Steps != nil {
 		steps := make([]*models.TestStep, len(testCaseUpdate.Steps))
-		for i, stepCreate := range testCaseUpdate.Steps {
+		for i, stepUpsert := range testCaseUpdate.Steps {
 			steps[i] = &models.TestStep{
-				StepType:       stepCreate.StepType,
-				Description:    stepCreate.Description,
-				ExpectedResult: stepCreate.ExpectedResult,
+				StepType:       stepUpsert.StepType,
+				Description:    stepUpsert.Description,
+				ExpectedResult: stepUpsert.ExpectedResult,
+				SharedStepID:   stepUpsert.SharedStepID,
+			}
+			if stepUpsert.ID != nil {
+				steps[i].ID = *stepUpsert.ID
 			}
 		}
 		testCase.Steps = steps
 	}
 
+	// Merge custom fields; a null value clears the field
+	if testCaseUpdate.CustomFields != nil {
+		customFields := make(map[string]interface{}, len(testCase.CustomFields)+len(testCaseUpdate.CustomFields))
+		for name, value := range testCase.CustomFields {
+			customFields[name] = value
+		}
+		for name, value := range testCaseUpdate.CustomFields {
+			customFields[name] = value
+		}
+		testCase.CustomFields = customFields
+	} else {
+		testCase.CustomFields = nil
+	}
+
 	// Convert tag names to tag IDs
 	var tagIDs []int64
 	if testCaseUpdate.Tags != nil {
@@ -182,10 +215,22 @@ func (h *TestCaseHandler) UpdateTestCase(c *gin.Context) {
 	// Update test case with tags
 	err = h.testCaseService.UpdateTestCase(testCase, tagIDs)
 	if err != nil {
+		if isSharedStepReferenceError(err) || errors.Is(err, repository.ErrStepNotInTestCase) ||
+			isCustomFieldError(err) || isWorkflowValueError(err) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, service.ErrApprovalRequired) || errors.Is(err, service.ErrTransitionNotAllowed) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
+	setAuditEntity(c, "test_case", testCase.ID, testCase.ProjectID)
+	setAuditAfter(c, testCase.ToResponse())
+
 	c.JSON(http.StatusOK, testCase.ToResponse())
 }
 
@@ -197,7 +242,19 @@ func (h *TestCaseHandler) DeleteTestCase(c *gin.Context) {
 		return
 	}
 
-	err = h.testCaseService.DeleteTestCase(id)
+	// Get user ID from context (set by auth middleware)
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	if testCase, err := h.testCaseService.GetTestCaseByID(id); err == nil {
+		setAuditEntity(c, "test_case", testCase.ID, testCase.ProjectID)
+		setAuditBefore(c, testCase.ToResponse())
+	}
+
+	err = h.testCaseService.DeleteTestCase(id, userID.(int64))
 	if err != nil {
 		if errors.Is(err, repository.ErrTestCaseNotFound) {
 			c.JSON(http.StatusNotFound, gin.H{"error": "test case not found"})
@@ -218,8 +275,12 @@ func (h *TestCaseHandler) ListTestCasesByProject(c *gin.Context) {
 		return
 	}
 
-	testCases, err := h.testCaseService.ListTestCasesByProject(projectID)
+	testCases, err := h.testCaseService.ListTestCasesByProject(projectID, listOptions(c))
 	if err != nil {
+		if isCustomFieldError(err) || errors.Is(err, service.ErrInvalidSort) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -246,8 +307,12 @@ func (h *TestCaseHandler) ListTestCasesBySuite(c *gin.Context) {
 		return
 	}
 
-	testCases, err := h.testCaseService.ListTestCasesBySuite(suiteID)
+	testCases, err := h.testCaseService.ListTestCasesBySuite(suiteID, listOptions(c))
 	if err != nil {
+		if isCustomFieldError(err) || errors.Is(err, service.ErrInvalidSort) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -266,7 +331,7 @@ func (h *TestCaseHandler) ListTestCasesBySuite(c *gin.Context) {
 	c.JSON(http.StatusOK, response)
 }
 
-// AddTestStep handles adding a step to a test case
+// AddTestStep handles adding a step to a test case, optionally at a given position
 func (h *TestCaseHandler) AddTestStep(c *gin.Context) {
 	testCaseID, err := strconv.ParseInt(c.Param("testCaseId"), 10, 64)
 	if err != nil {
@@ -274,8 +339,8 @@ func (h *TestCaseHandler) AddTestStep(c *gin.Context) {
 		return
 	}
 
-	var stepCreate models.TestStepCreate
-	if err := c.ShouldBindJSON(&stepCreate); err != nil {
+	var stepAdd models.TestStepAdd
+	if err := c.ShouldBindJSON(&stepAdd); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
@@ -283,21 +348,117 @@ func (h *TestCaseHandler) AddTestStep(c *gin.Context) {
 	// Create step from request data
 	step := &models.TestStep{
 		TestCaseID:     testCaseID,
-		StepNumber:     1, // Default to 1, would be determined by service
-		StepType:       stepCreate.StepType,
-		Description:    stepCreate.Description,
-		ExpectedResult: stepCreate.ExpectedResult,
+		StepType:       stepAdd.StepType,
+		Description:    stepAdd.Description,
+		ExpectedResult: stepAdd.ExpectedResult,
+		SharedStepID:   stepAdd.SharedStepID,
+	}
+
+	position := 0
+	if stepAdd.Position != nil {
+		position = *stepAdd.Position
 	}
 
-	err = h.testCaseService.AddTestStep(testCaseID, step)
+	err = h.testCaseService.AddTestStep(testCaseID, step, position)
 	if err != nil {
+		if errors.Is(err, repository.ErrTestCaseNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "test case not found"})
+			return
+		}
+		if isSharedStepReferenceError(err) || errors.Is(err, service.ErrInvalidStepPosition) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
+	h.auditStep(c, "test_step", step.ID, testCaseID)
+	setAuditAfter(c, step.ToResponse())
+
 	c.JSON(http.StatusCreated, step)
 }
 
+// MoveTestStep handles moving a step to another position within its test case
+func (h *TestCaseHandler) MoveTestStep(c *gin.Context) {
+	stepID, err := strconv.ParseInt(c.Param("stepId"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid step ID"})
+		return
+	}
+
+	var stepMove models.TestStepMove
+	if err := c.ShouldBindJSON(&stepMove); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if before, err := h.testCaseService.GetStepByID(stepID); err == nil {
+		h.auditStep(c, "test_step", stepID, before.TestCaseID)
+		setAuditBefore(c, before.ToResponse())
+	}
+
+	steps, err := h.testCaseService.MoveTestStep(stepID, stepMove.Position)
+	if err != nil {
+		if errors.Is(err, repository.ErrTestStepNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "test step not found"})
+			return
+		}
+		if errors.Is(err, service.ErrInvalidStepPosition) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	for _, step := range steps {
+		if step.ID == stepID {
+			setAuditAfter(c, step.ToResponse())
+		}
+	}
+
+	c.JSON(http.StatusOK, toTestStepResponses(steps))
+}
+
+// ReorderTestSteps handles reordering all steps of a test case
+func (h *TestCaseHandler) ReorderTestSteps(c *gin.Context) {
+	testCaseID, err := strconv.ParseInt(c.Param("testCaseId"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid test case ID"})
+		return
+	}
+
+	var stepOrder models.TestStepOrder
+	if err := c.ShouldBindJSON(&stepOrder); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if before, err := h.testCaseService.GetTestCaseByID(testCaseID); err == nil {
+		setAuditEntity(c, "test_case", testCaseID, before.ProjectID)
+		setAuditBefore(c, toTestStepResponses(before.Steps))
+	}
+
+	steps, err := h.testCaseService.ReorderTestSteps(testCaseID, stepOrder.StepIDs)
+	if err != nil {
+		if errors.Is(err, repository.ErrTestCaseNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "test case not found"})
+			return
+		}
+		if errors.Is(err, repository.ErrInvalidStepOrder) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	setAuditAfter(c, toTestStepResponses(steps))
+
+	c.JSON(http.StatusOK, toTestStepResponses(steps))
+}
+
 // UpdateTestStep handles updating a test step
 func (h *TestCaseHandler) UpdateTestStep(c *gin.Context) {
 	stepID, err := strconv.ParseInt(c.Param("stepId"), 10, 64)
@@ -319,10 +480,20 @@ func (h *TestCaseHandler) UpdateTestStep(c *gin.Context) {
 		StepType:       stepUpdate.StepType,
 		Description:    stepUpdate.Description,
 		ExpectedResult: stepUpdate.ExpectedResult,
+		SharedStepID:   stepUpdate.SharedStepID,
+	}
+
+	if before, err := h.testCaseService.GetStepByID(stepID); err == nil {
+		h.auditStep(c, "test_step", stepID, before.TestCaseID)
+		setAuditBefore(c, before.ToResponse())
 	}
 
 	err = h.testCaseService.UpdateTestStep(stepID, step)
 	if err != nil {
+		if isSharedStepReferenceError(err) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -334,6 +505,8 @@ func (h *TestCaseHandler) UpdateTestStep(c *gin.Context) {
 		return
 	}
 
+	setAuditAfter(c, updatedStep.ToResponse())
+
 	c.JSON(http.StatusOK, updatedStep.ToResponse())
 }
 
@@ -345,8 +518,17 @@ func (h *TestCaseHandler) DeleteTestStep(c *gin.Context) {
 		return
 	}
 
+	if before, err := h.testCaseService.GetStepByID(stepID); err == nil {
+		h.auditStep(c, "test_step", stepID, before.TestCaseID)
+		setAuditBefore(c, before.ToResponse())
+	}
+
 	err = h.testCaseService.DeleteTestStep(stepID)
 	if err != nil {
+		if errors.Is(err, repository.ErrTestStepNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "test step not found"})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -395,6 +577,9 @@ func (h *TestCaseHandler) AddStepNote(c *gin.Context) {
 		return
 	}
 
+	h.auditStep(c, "step_note", note.ID, updatedStep.TestCaseID)
+	setAuditAfter(c, note)
+
 	c.JSON(http.StatusCreated, updatedStep.ToResponse())
 }
 
@@ -406,6 +591,14 @@ func (h *TestCaseHandler) DeleteStepNote(c *gin.Context) {
 		return
 	}
 
+	if note, err := h.testCaseService.GetStepNote(noteID); err == nil {
+		setAuditEntity(c, "step_note", noteID, 0)
+		if step, err := h.testCaseService.GetStepByID(note.StepID); err == nil {
+			h.auditStep(c, "step_note", noteID, step.TestCaseID)
+		}
+		setAuditBefore(c, note)
+	}
+
 	err = h.testCaseService.DeleteStepNote(noteID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -511,6 +704,9 @@ func (h *TestCaseHandler) UploadStepAttachment(c *gin.Context) {
 		return
 	}
 
+	h.auditStep(c, "step_attachment", attachment.ID, updatedStep.TestCaseID)
+	setAuditAfter(c, attachment)
+
 	c.JSON(http.StatusCreated, updatedStep.ToResponse())
 }
 
@@ -529,6 +725,12 @@ func (h *TestCaseHandler) DeleteStepAttachment(c *gin.Context) {
 		return
 	}
 
+	setAuditEntity(c, "step_attachment", attachmentID, 0)
+	if step, err := h.testCaseService.GetStepByID(attachment.StepID); err == nil {
+		h.auditStep(c, "step_attachment", attachmentID, step.TestCaseID)
+	}
+	setAuditBefore(c, attachment)
+
 	// Delete the attachment record
 	err = h.testCaseService.DeleteStepAttachment(attachmentID)
 	if err != nil {
@@ -543,3 +745,214 @@ func (h *TestCaseHandler) DeleteStepAttachment(c *gin.Context) {
 
 	c.Status(http.StatusNoContent)
 }
+
+// auditStep describes a change to a step or one of its notes or attachments for the audit log
+func (h *TestCaseHandler) auditStep(c *gin.Context, entityType string, entityID, testCaseID int64) {
+	projectID, _ := h.testCaseService.GetProjectID(testCaseID)
+	setAuditEntity(c, entityType, entityID, projectID)
+}
+
+// isSharedStepReferenceError reports whether err was caused by an invalid shared step reference
+func isSharedStepReferenceError(err error) bool {
+	return errors.Is(err, repository.ErrSharedStepNotFound) || errors.Is(err, service.ErrSharedStepProjectMismatch)
+}
+
+// toTestStepResponses converts steps to their API representation
+// isCustomFieldError reports whether err is caused by invalid custom field values
+func isCustomFieldError(err error) bool {
+	return errors.Is(err, service.ErrUnknownCustomField) || errors.Is(err, service.ErrInvalidCustomFieldValue) ||
+		errors.Is(err, service.ErrCustomFieldRequired)
+}
+
+// isWorkflowValueError reports whether err is caused by a status or priority outside the project workflow
+func isWorkflowValueError(err error) bool {
+	return errors.Is(err, service.ErrInvalidStatus) || errors.Is(err, service.ErrInvalidPriority) ||
+		errors.Is(err, service.ErrStatusManagedByReview)
+}
+
+// listOptions reads custom field filters (cf.<name>=value) and the sort parameter of list requests
+func listOptions(c *gin.Context) *models.TestCaseListOptions {
+	options := &models.TestCaseListOptions{
+		CustomFieldFilters: make(map[string]string),
+		Sort:               c.Query("sort"),
+	}
+	for key, values := range c.Request.URL.Query() {
+		if name, ok := strings.CutPrefix(key, "cf."); ok && len(values) > 0 {
+			options.CustomFieldFilters[name] = values[0]
+		}
+	}
+	return options
+}
+
+func toTestStepResponses(steps []*models.TestStep) []*models.TestStepResponse {
+	response := make([]*models.TestStepResponse, len(steps))
+	for i, step := range steps {
+		response[i] = step.ToResponse()
+	}
+	return response
+}
+
+// GetDataRows handles retrieving the parameter data table of a test case
+func (h *TestCaseHandler) GetDataRows(c *gin.Context) {
+	testCaseID, err := strconv.ParseInt(c.Param("testCaseId"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid test case ID"})
+		return
+	}
+
+	testCase, err := h.testCaseService.GetTestCaseByID(testCaseID)
+	if err != nil {
+		if errors.Is(err, repository.ErrTestCaseNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "test case not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	rows := make([]*models.TestCaseDataRowResponse, len(testCase.DataRows))
+	for i, row := range testCase.DataRows {
+		rows[i] = row.ToResponse()
+	}
+
+	parameters := testCase.Parameters
+	if parameters == nil {
+		parameters = []string{}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"parameters": parameters,
+		"rows":       rows,
+	})
+}
+
+// UpdateDataRows handles replacing the parameter data table of a test case
+func (h *TestCaseHandler) UpdateDataRows(c *gin.Context) {
+	testCaseID, err := strconv.ParseInt(c.Param("testCaseId"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid test case ID"})
+		return
+	}
+
+	var rowsUpdate models.TestCaseDataRowsUpdate
+	if err := c.ShouldBindJSON(&rowsUpdate); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	rows := make([]*models.TestCaseDataRow, len(rowsUpdate.Rows))
+	for i, rowCreate := range rowsUpdate.Rows {
+		rows[i] = &models.TestCaseDataRow{
+			Name:   rowCreate.Name,
+			Values: rowCreate.Values,
+		}
+	}
+
+	err = h.testCaseService.UpdateDataRows(testCaseID, rows)
+	if err != nil {
+		if errors.Is(err, repository.ErrTestCaseNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "test case not found"})
+			return
+		}
+		if errors.Is(err, service.ErrMissingParameterValue) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	response := make([]*models.TestCaseDataRowResponse, len(rows))
+	for i, row := range rows {
+		response[i] = row.ToResponse()
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// GetScenarios handles expanding a test case into one scenario per data row
+func (h *TestCaseHandler) GetScenarios(c *gin.Context) {
+	testCaseID, err := strconv.ParseInt(c.Param("testCaseId"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid test case ID"})
+		return
+	}
+
+	scenarios, err := h.testCaseService.GetScenarios(testCaseID)
+	if err != nil {
+		if errors.Is(err, repository.ErrTestCaseNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "test case not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	response := make([]*models.TestScenarioResponse, len(scenarios))
+	for i, scenario := range scenarios {
+		response[i] = scenario.ToResponse()
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// ExportTestCases handles exporting the test cases of a project as CSV
+func (h *TestCaseHandler) ExportTestCases(c *gin.Context) {
+	projectID, err := strconv.ParseInt(c.Param("projectId"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid project ID"})
+		return
+	}
+
+	c.Header("Content-Type", "text/csv")
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=project-%d-test-cases.csv", projectID))
+	if err := h.testCaseService.ExportTestCasesCSV(projectID, c.Writer); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+}
+
+// ImportTestCases handles importing test cases into a project from CSV, sent either
+// as the "file" field of a multipart form or as the request body
+func (h *TestCaseHandler) ImportTestCases(c *gin.Context) {
+	projectID, err := strconv.ParseInt(c.Param("projectId"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid project ID"})
+		return
+	}
+
+	// Get user ID from context (set by auth middleware)
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var reader io.Reader = c.Request.Body
+	if strings.HasPrefix(c.ContentType(), "multipart/") {
+		fileHeader, err := c.FormFile("file")
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "file is required"})
+			return
+		}
+		file, err := fileHeader.Open()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to read file"})
+			return
+		}
+		defer file.Close()
+		reader = file
+	}
+
+	result, err := h.testCaseService.ImportTestCasesCSV(projectID, userID.(int64), reader)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidCSV) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
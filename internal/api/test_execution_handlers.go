@@ -0,0 +1,211 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mihaamiharu/test-case-management-be/internal/models"
+	"github.com/mihaamiharu/test-case-management-be/internal/repository"
+	"github.com/mihaamiharu/test-case-management-be/internal/service"
+	"github.com/mihaamiharu/test-case-management-be/internal/services"
+)
+
+// TestExecutionHandler handles test run execution requests
+type TestExecutionHandler struct {
+	executionService     *service.TestExecutionService
+	projectAccessService *services.ProjectAccessService
+}
+
+// NewTestExecutionHandler creates a new test execution handler
+func NewTestExecutionHandler(executionService *service.TestExecutionService, projectAccessService *services.ProjectAccessService) *TestExecutionHandler {
+	return &TestExecutionHandler{
+		executionService:     executionService,
+		projectAccessService: projectAccessService,
+	}
+}
+
+// AssignRun handles assigning a test run to a user who can execute tests in its project
+func (h *TestExecutionHandler) AssignRun(c *gin.Context) {
+	runID, err := strconv.ParseInt(c.Param("runId"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid test run ID"})
+		return
+	}
+
+	var assign models.TestRunAssign
+	if err := c.ShouldBindJSON(&assign); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if assign.AssignedTo != nil {
+		projectID, err := h.executionService.GetRunProjectID(runID)
+		if err != nil {
+			if errors.Is(err, repository.ErrTestRunNotFound) {
+				c.JSON(http.StatusNotFound, gin.H{"error": "test run not found"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		access, err := h.projectAccessService.EffectiveAccess(currentOrganizationID(c), projectID, *assign.AssignedTo)
+		if err != nil {
+			if errors.Is(err, repository.ErrUserNotFound) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "assignee not found"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if !access.Level.Allows(models.PermissionExecuteTests) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "the assignee needs the tester role in the project"})
+			return
+		}
+	}
+
+	run, err := h.executionService.AssignRun(runID, assign.AssignedTo, c.GetInt64("userID"))
+	if err != nil {
+		if errors.Is(err, repository.ErrTestRunNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "test run not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	setAuditEntity(c, "test_run", run.ID, run.ProjectID)
+	setAuditAfter(c, run)
+
+	c.JSON(http.StatusOK, run)
+}
+
+// AddTestCaseToRun handles adding a test case to a test run.
+// Parameterized test cases produce one execution per data row.
+func (h *TestExecutionHandler) AddTestCaseToRun(c *gin.Context) {
+	runID, err := strconv.ParseInt(c.Param("runId"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid test run ID"})
+		return
+	}
+
+	var caseAdd models.TestRunCaseAdd
+	if err := c.ShouldBindJSON(&caseAdd); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Get user ID from context (set by auth middleware)
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	executions, err := h.executionService.AddTestCaseToRun(runID, caseAdd.TestCaseID, userID.(int64))
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrTestRunNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "test run not found"})
+		case errors.Is(err, repository.ErrTestCaseNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "test case not found"})
+		case errors.Is(err, repository.ErrTestCaseAlreadyInRun):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		case errors.Is(err, service.ErrTestCaseProjectMismatch):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	response := make([]*models.TestExecutionResponse, len(executions))
+	for i, execution := range executions {
+		response[i] = execution.ToResponse()
+	}
+
+	c.JSON(http.StatusCreated, response)
+}
+
+// ListExecutionsByRun handles listing the executions of a test run
+func (h *TestExecutionHandler) ListExecutionsByRun(c *gin.Context) {
+	runID, err := strconv.ParseInt(c.Param("runId"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid test run ID"})
+		return
+	}
+
+	executions, err := h.executionService.ListExecutionsByRun(runID)
+	if err != nil {
+		if errors.Is(err, repository.ErrTestRunNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "test run not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	response := make([]*models.TestExecutionResponse, len(executions))
+	for i, execution := range executions {
+		response[i] = execution.ToResponse()
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// GetExecution handles retrieving an execution with its expanded steps
+func (h *TestExecutionHandler) GetExecution(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid test execution ID"})
+		return
+	}
+
+	execution, err := h.executionService.GetExecution(id)
+	if err != nil {
+		if errors.Is(err, repository.ErrTestExecutionNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "test execution not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, execution.ToResponse())
+}
+
+// RecordResult handles recording the result of an execution
+func (h *TestExecutionHandler) RecordResult(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid test execution ID"})
+		return
+	}
+
+	var result models.TestExecutionResult
+	if err := c.ShouldBindJSON(&result); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Get user ID from context (set by auth middleware)
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	execution, err := h.executionService.RecordResult(id, &result, userID.(int64))
+	if err != nil {
+		if errors.Is(err, repository.ErrTestExecutionNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "test execution not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, execution.ToResponse())
+}
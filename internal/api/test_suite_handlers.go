@@ -23,9 +23,16 @@ func NewTestSuiteHandler(testSuiteService *service.TestSuiteService) *TestSuiteH
 	}
 }
 
-// ListTestSuites handles listing all test suites
+// ListTestSuites handles listing the test suites of the project in the project_id query
+// parameter, which PermissionMiddleware checks the user may view
 func (h *TestSuiteHandler) ListTestSuites(c *gin.Context) {
-	suites, err := h.testSuiteService.ListTestSuites()
+	projectID, err := strconv.ParseInt(c.Query("project_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "project_id is required"})
+		return
+	}
+
+	suites, err := h.testSuiteService.ListTestSuitesByProject(projectID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve test suites"})
 		return
@@ -70,10 +77,17 @@ func (h *TestSuiteHandler) CreateTestSuite(c *gin.Context) {
 			c.JSON(http.StatusConflict, gin.H{"error": "Test suite with this name already exists in this project"})
 			return
 		}
+		if err == repository.ErrTestSuiteInTrash {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create test suite"})
 		return
 	}
 
+	setAuditEntity(c, "test_suite", suite.ID, suite.ProjectID)
+	setAuditAfter(c, suite.ToResponse())
+
 	c.JSON(http.StatusCreated, suite.ToResponse())
 }
 
@@ -117,6 +131,8 @@ func (h *TestSuiteHandler) UpdateTestSuite(c *gin.Context) {
 		return
 	}
 
+	setAuditBefore(c, suite.ToResponse())
+
 	// Parse update data
 	var suiteUpdate models.TestSuiteUpdate
 	if err := c.ShouldBindJSON(&suiteUpdate); err != nil {
@@ -146,10 +162,17 @@ func (h *TestSuiteHandler) UpdateTestSuite(c *gin.Context) {
 			c.JSON(http.StatusConflict, gin.H{"error": "Test suite with this name already exists in this project"})
 			return
 		}
+		if err == repository.ErrTestSuiteInTrash {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update test suite"})
 		return
 	}
 
+	setAuditEntity(c, "test_suite", suite.ID, suite.ProjectID)
+	setAuditAfter(c, suite.ToResponse())
+
 	c.JSON(http.StatusOK, suite.ToResponse())
 }
 
@@ -161,6 +184,11 @@ func (h *TestSuiteHandler) DeleteTestSuite(c *gin.Context) {
 		return
 	}
 
+	if suite, err := h.testSuiteService.GetTestSuiteByID(id); err == nil {
+		setAuditEntity(c, "test_suite", suite.ID, suite.ProjectID)
+		setAuditBefore(c, suite.ToResponse())
+	}
+
 	err = h.testSuiteService.DeleteTestSuite(id)
 	if err != nil {
 		if err == repository.ErrTestSuiteNotFound {
@@ -171,7 +199,7 @@ func (h *TestSuiteHandler) DeleteTestSuite(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Test suite deleted successfully"})
+	c.JSON(http.StatusOK, gin.H{"message": "Test suite moved to the trash"})
 }
 
 // ListTestSuitesByProject handles listing test suites by project
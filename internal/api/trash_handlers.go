@@ -0,0 +1,186 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mihaamiharu/test-case-management-be/internal/models"
+	"github.com/mihaamiharu/test-case-management-be/internal/repository"
+	"github.com/mihaamiharu/test-case-management-be/internal/service"
+	"github.com/mihaamiharu/test-case-management-be/internal/services"
+)
+
+// TrashHandler handles listing and restoring deleted projects, test suites and test cases
+type TrashHandler struct {
+	trashService         *service.TrashService
+	projectService       *services.ProjectService
+	projectAccessService *services.ProjectAccessService
+}
+
+// NewTrashHandler creates a new trash handler
+func NewTrashHandler(trashService *service.TrashService, projectService *services.ProjectService, projectAccessService *services.ProjectAccessService) *TrashHandler {
+	return &TrashHandler{
+		trashService:         trashService,
+		projectService:       projectService,
+		projectAccessService: projectAccessService,
+	}
+}
+
+// ListProjectTrash handles listing the trashed test suites and test cases of a project
+func (h *TrashHandler) ListProjectTrash(c *gin.Context) {
+	projectID, err := strconv.ParseInt(c.Param("projectId"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid project ID"})
+		return
+	}
+
+	if !authorizeProject(c, h.projectAccessService, projectID, models.PermissionEditTests) {
+		return
+	}
+
+	items, err := h.trashService.ListProjectTrash(projectID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if items == nil {
+		items = []*models.TrashItem{}
+	}
+
+	c.JSON(http.StatusOK, items)
+}
+
+// ListTrashedProjects handles listing the trashed projects of the current user in the current
+// organization. Organization admins see the trashed projects of every owner.
+func (h *TrashHandler) ListTrashedProjects(c *gin.Context) {
+	// Get user from context
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found in context"})
+		return
+	}
+	userModel := user.(*models.User)
+
+	ownerID := userModel.ID
+	if isOrganizationAdmin(c) {
+		ownerID = 0
+	}
+
+	items, err := h.trashService.ListTrashedProjects(currentOrganizationID(c), ownerID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if items == nil {
+		items = []*models.TrashItem{}
+	}
+
+	c.JSON(http.StatusOK, items)
+}
+
+// RestoreProject handles taking a project out of the trash. Only the owner or an organization
+// admin can restore it.
+func (h *TrashHandler) RestoreProject(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid project ID"})
+		return
+	}
+
+	// Get user from context
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found in context"})
+		return
+	}
+	userModel := user.(*models.User)
+
+	// Trashed projects are hidden from the project service, so ownership comes from the trash
+	item, err := h.trashService.GetItem(models.TrashProject, id)
+	if err != nil {
+		handleTrashError(c, err)
+		return
+	}
+	if item.OwnerID != userModel.ID && !isOrganizationAdmin(c) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You don't have permission to restore this project"})
+		return
+	}
+
+	if err := h.trashService.Restore(models.TrashProject, id); err != nil {
+		handleTrashError(c, err)
+		return
+	}
+
+	project, err := h.projectService.GetByID(id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	setAuditEntity(c, models.TrashProject, id, id)
+	setAuditAction(c, models.AuditActionUpdate)
+	setAuditAfter(c, project.ToResponse())
+
+	c.JSON(http.StatusOK, project.ToResponse())
+}
+
+// RestoreTestSuite handles taking a test suite out of the trash
+func (h *TrashHandler) RestoreTestSuite(c *gin.Context) {
+	h.restore(c, models.TrashTestSuite)
+}
+
+// RestoreTestCase handles taking a test case out of the trash
+func (h *TrashHandler) RestoreTestCase(c *gin.Context) {
+	h.restore(c, models.TrashTestCase)
+}
+
+// restore takes a test suite or test case out of the trash for an editor of its project
+func (h *TrashHandler) restore(c *gin.Context, entityType string) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid ID"})
+		return
+	}
+
+	item, err := h.trashService.GetItem(entityType, id)
+	if err != nil {
+		handleTrashError(c, err)
+		return
+	}
+
+	// The project itself may be in the trash, which only its owner can restore
+	if _, err := h.projectService.GetByID(item.ProjectID); errors.Is(err, repository.ErrProjectNotFound) {
+		handleTrashError(c, repository.ErrParentInTrash)
+		return
+	}
+	if !authorizeProject(c, h.projectAccessService, item.ProjectID, models.PermissionEditTests) {
+		return
+	}
+
+	if err := h.trashService.Restore(entityType, id); err != nil {
+		handleTrashError(c, err)
+		return
+	}
+
+	setAuditEntity(c, entityType, id, item.ProjectID)
+	setAuditAction(c, models.AuditActionUpdate)
+	setAuditBefore(c, item)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Restored from the trash"})
+}
+
+// handleTrashError maps trash errors to HTTP responses
+func handleTrashError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, repository.ErrNotInTrash):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, repository.ErrParentInTrash):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,206 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mihaamiharu/test-case-management-be/internal/models"
+	"github.com/mihaamiharu/test-case-management-be/internal/repository"
+	"github.com/mihaamiharu/test-case-management-be/internal/service"
+)
+
+// TwoFactorHandler handles two-factor authentication endpoints
+type TwoFactorHandler struct {
+	twoFactorService *service.TwoFactorService
+	authService      *service.AuthService
+}
+
+// NewTwoFactorHandler creates a new two-factor authentication handler
+func NewTwoFactorHandler(twoFactorService *service.TwoFactorService, authService *service.AuthService) *TwoFactorHandler {
+	return &TwoFactorHandler{
+		twoFactorService: twoFactorService,
+		authService:      authService,
+	}
+}
+
+// GetStatus handles reporting whether the current user has enabled two-factor authentication
+func (h *TwoFactorHandler) GetStatus(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	status, err := h.twoFactorService.Status(user)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get two-factor authentication status"})
+		return
+	}
+
+	c.JSON(http.StatusOK, status)
+}
+
+// Setup handles generating a TOTP secret and its provisioning URI for the current user.
+// Two-factor authentication is enabled once a code confirms it.
+func (h *TwoFactorHandler) Setup(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	setup, err := h.twoFactorService.Setup(user)
+	if err != nil {
+		h.respondWithError(c, err)
+		return
+	}
+
+	// The secret must not end up in the audit log
+	setAuditEntity(c, "user_two_factor", user.ID, 0)
+
+	c.JSON(http.StatusOK, setup)
+}
+
+// Enable handles confirming the TOTP secret of the current user with a code. The response
+// holds the recovery codes, which are not shown again.
+func (h *TwoFactorHandler) Enable(c *gin.Context) {
+	var request models.TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	codes, err := h.twoFactorService.Enable(user, request.Code)
+	if err != nil {
+		h.respondWithError(c, err)
+		return
+	}
+
+	setAuditEntity(c, "user_two_factor", user.ID, 0)
+	setAuditAction(c, models.AuditActionUpdate)
+
+	c.JSON(http.StatusOK, models.RecoveryCodesResponse{RecoveryCodes: codes})
+}
+
+// Disable handles turning off two-factor authentication of the current user
+func (h *TwoFactorHandler) Disable(c *gin.Context) {
+	var request models.TwoFactorDisableRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	if err := h.twoFactorService.Disable(user, &request); err != nil {
+		h.respondWithError(c, err)
+		return
+	}
+
+	setAuditEntity(c, "user_two_factor", user.ID, 0)
+	setAuditAction(c, models.AuditActionDelete)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Two-factor authentication disabled"})
+}
+
+// RegenerateRecoveryCodes handles replacing the recovery codes of the current user
+func (h *TwoFactorHandler) RegenerateRecoveryCodes(c *gin.Context) {
+	var request models.TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	codes, err := h.twoFactorService.RegenerateRecoveryCodes(user, request.Code)
+	if err != nil {
+		h.respondWithError(c, err)
+		return
+	}
+
+	setAuditEntity(c, "user_two_factor", user.ID, 0)
+	setAuditAction(c, models.AuditActionUpdate)
+
+	c.JSON(http.StatusOK, models.RecoveryCodesResponse{RecoveryCodes: codes})
+}
+
+// Verify handles completing a login with the challenge it returned and a TOTP or recovery code
+func (h *TwoFactorHandler) Verify(c *gin.Context) {
+	var request models.TwoFactorVerifyRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tokens, user, err := h.authService.VerifyTwoFactor(request.ChallengeToken, request.Code, sessionClient(c))
+	if err != nil {
+		switch err {
+		case service.ErrInvalidTwoFactorCode, service.ErrInvalidTwoFactorChallenge:
+			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		case service.ErrUserDeactivated:
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to login"})
+		}
+		return
+	}
+
+	c.Set(loginCompletedKey, true)
+	c.JSON(http.StatusOK, tokenResponse(tokens, user))
+}
+
+// ResetUserTwoFactor handles turning off two-factor authentication of a user who lost
+// their authenticator app and recovery codes
+func (h *TwoFactorHandler) ResetUserTwoFactor(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	if err := h.twoFactorService.Reset(id); err != nil {
+		h.respondWithError(c, err)
+		return
+	}
+
+	setAuditEntity(c, "user_two_factor", id, 0)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Two-factor authentication reset"})
+}
+
+// respondWithError maps two-factor authentication service errors to responses
+func (h *TwoFactorHandler) respondWithError(c *gin.Context, err error) {
+	switch err {
+	case repository.ErrUserNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+	case service.ErrInvalidTwoFactorCode, service.ErrIncorrectPassword:
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case service.ErrTwoFactorNotSetUp, service.ErrTwoFactorNotEnabled, service.ErrTwoFactorAlreadyEnabled:
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case service.ErrTwoFactorRequired:
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+// currentUser returns the authenticated user of a request
+func currentUser(c *gin.Context) (*models.User, bool) {
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found in context"})
+		return nil, false
+	}
+	return user.(*models.User), true
+}
@@ -0,0 +1,226 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mihaamiharu/test-case-management-be/internal/models"
+	"github.com/mihaamiharu/test-case-management-be/internal/repository"
+	"github.com/mihaamiharu/test-case-management-be/internal/service"
+)
+
+// UserHandler handles user administration and self-service profile endpoints
+type UserHandler struct {
+	userService *service.UserService
+}
+
+// NewUserHandler creates a new user handler
+func NewUserHandler(userService *service.UserService) *UserHandler {
+	return &UserHandler{
+		userService: userService,
+	}
+}
+
+// ListUsers handles listing users, optionally searching by username or email and
+// filtering by role and status
+func (h *UserHandler) ListUsers(c *gin.Context) {
+	filter := &models.UserFilter{
+		Search: c.Query("search"),
+		Role:   models.Role(c.Query("role")),
+		Status: c.Query("status"),
+	}
+	if value := c.Query("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit"})
+			return
+		}
+		filter.Limit = limit
+	}
+	if value := c.Query("offset"); value != "" {
+		offset, err := strconv.Atoi(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid offset"})
+			return
+		}
+		filter.Offset = offset
+	}
+
+	users, err := h.userService.List(filter)
+	if err != nil {
+		if err == service.ErrInvalidUserStatus {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list users"})
+		return
+	}
+
+	responses := make([]models.UserResponse, len(users))
+	for i, user := range users {
+		responses[i] = user.ToResponse()
+	}
+
+	c.JSON(http.StatusOK, responses)
+}
+
+// GetUser handles retrieving a user by ID
+func (h *UserHandler) GetUser(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	user, err := h.userService.Get(id)
+	if err != nil {
+		if err == repository.ErrUserNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get user"})
+		return
+	}
+
+	c.JSON(http.StatusOK, user.ToResponse())
+}
+
+// UpdateUserRole handles changing the role of a user
+func (h *UserHandler) UpdateUserRole(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	var roleUpdate models.UserRoleUpdate
+	if err := c.ShouldBindJSON(&roleUpdate); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	h.respondWithUser(c, id, func() (*models.User, error) {
+		return h.userService.UpdateRole(c.GetInt64("userID"), id, roleUpdate.Role)
+	})
+}
+
+// DeactivateUser handles deactivating a user. Deactivated users cannot log in and
+// their sessions and API tokens stop working.
+func (h *UserHandler) DeactivateUser(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	h.respondWithUser(c, id, func() (*models.User, error) {
+		return h.userService.Deactivate(c.GetInt64("userID"), id)
+	})
+}
+
+// ReactivateUser handles reactivating a deactivated user
+func (h *UserHandler) ReactivateUser(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	h.respondWithUser(c, id, func() (*models.User, error) {
+		return h.userService.Reactivate(id)
+	})
+}
+
+// ForcePasswordReset handles logging a user out everywhere and requiring it to change
+// its password after logging in again
+func (h *UserHandler) ForcePasswordReset(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	h.respondWithUser(c, id, func() (*models.User, error) {
+		return h.userService.ForcePasswordReset(id)
+	})
+}
+
+// UpdateProfile handles changing the username and email of the current user
+func (h *UserHandler) UpdateProfile(c *gin.Context) {
+	var profileUpdate models.UserProfileUpdate
+	if err := c.ShouldBindJSON(&profileUpdate); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	id := c.GetInt64("userID")
+	h.respondWithUser(c, id, func() (*models.User, error) {
+		return h.userService.UpdateProfile(id, &profileUpdate)
+	})
+}
+
+// ChangePassword handles changing the password of the current user. The other sessions
+// of the user are logged out.
+func (h *UserHandler) ChangePassword(c *gin.Context) {
+	var passwordChange models.PasswordChange
+	if err := c.ShouldBindJSON(&passwordChange); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	id := c.GetInt64("userID")
+	if err := h.userService.ChangePassword(id, c.GetInt64("sessionID"), &passwordChange); err != nil {
+		h.respondWithError(c, err)
+		return
+	}
+
+	setAuditEntity(c, "user", id, 0)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Password changed successfully"})
+}
+
+// respondWithUser applies a change to a user, records it in the audit log and responds
+// with the changed user
+func (h *UserHandler) respondWithUser(c *gin.Context, id int64, change func() (*models.User, error)) {
+	before, err := h.userService.Get(id)
+	if err != nil {
+		h.respondWithError(c, err)
+		return
+	}
+
+	user, err := change()
+	if err != nil {
+		h.respondWithError(c, err)
+		return
+	}
+
+	setAuditEntity(c, "user", user.ID, 0)
+	setAuditAction(c, models.AuditActionUpdate)
+	setAuditBefore(c, before.ToResponse())
+	setAuditAfter(c, user.ToResponse())
+
+	c.JSON(http.StatusOK, user.ToResponse())
+}
+
+// respondWithError maps user service errors to responses
+func (h *UserHandler) respondWithError(c *gin.Context, err error) {
+	if errors.Is(err, service.ErrWeakPassword) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	switch err {
+	case repository.ErrUserNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+	case repository.ErrUserExists:
+		c.JSON(http.StatusConflict, gin.H{"error": "User with this email already exists"})
+	case service.ErrCannotModifySelf, service.ErrUserNotDeactivated:
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case service.ErrIncorrectPassword, service.ErrPasswordUnchanged:
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
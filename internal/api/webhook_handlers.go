@@ -0,0 +1,222 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mihaamiharu/test-case-management-be/internal/models"
+	"github.com/mihaamiharu/test-case-management-be/internal/repository"
+	"github.com/mihaamiharu/test-case-management-be/internal/service"
+	"github.com/mihaamiharu/test-case-management-be/internal/services"
+)
+
+// WebhookHandler handles project webhook requests
+type WebhookHandler struct {
+	webhookService       *service.WebhookService
+	projectAccessService *services.ProjectAccessService
+}
+
+// NewWebhookHandler creates a new webhook handler
+func NewWebhookHandler(webhookService *service.WebhookService, projectAccessService *services.ProjectAccessService) *WebhookHandler {
+	return &WebhookHandler{
+		webhookService:       webhookService,
+		projectAccessService: projectAccessService,
+	}
+}
+
+// ListWebhooks handles listing the webhooks of a project
+func (h *WebhookHandler) ListWebhooks(c *gin.Context) {
+	projectID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid project ID"})
+		return
+	}
+
+	if !h.requireManageAccess(c, projectID) {
+		return
+	}
+
+	webhooks, err := h.webhookService.ListWebhooks(projectID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if webhooks == nil {
+		webhooks = []*models.Webhook{}
+	}
+
+	c.JSON(http.StatusOK, webhooks)
+}
+
+// CreateWebhook handles creating a webhook. The response is the only one that contains
+// the secret its payloads are signed with.
+func (h *WebhookHandler) CreateWebhook(c *gin.Context) {
+	var webhookCreate models.WebhookCreate
+	if err := c.ShouldBindJSON(&webhookCreate); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if !h.requireManageAccess(c, webhookCreate.ProjectID) {
+		return
+	}
+
+	created, err := h.webhookService.CreateWebhook(&webhookCreate, c.GetInt64("userID"))
+	if err != nil {
+		handleWebhookError(c, err)
+		return
+	}
+
+	setAuditEntity(c, "webhook", created.ID, created.ProjectID)
+	setAuditAfter(c, created.Webhook)
+
+	c.JSON(http.StatusCreated, created)
+}
+
+// GetWebhook handles retrieving a webhook
+func (h *WebhookHandler) GetWebhook(c *gin.Context) {
+	webhook, ok := h.loadWebhook(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, webhook)
+}
+
+// UpdateWebhook handles updating a webhook
+func (h *WebhookHandler) UpdateWebhook(c *gin.Context) {
+	var webhookUpdate models.WebhookUpdate
+	if err := c.ShouldBindJSON(&webhookUpdate); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	existing, ok := h.loadWebhook(c)
+	if !ok {
+		return
+	}
+	setAuditEntity(c, "webhook", existing.ID, existing.ProjectID)
+	setAuditBefore(c, existing)
+
+	webhook, err := h.webhookService.UpdateWebhook(existing.ID, &webhookUpdate)
+	if err != nil {
+		handleWebhookError(c, err)
+		return
+	}
+
+	setAuditAfter(c, webhook)
+
+	c.JSON(http.StatusOK, webhook)
+}
+
+// DeleteWebhook handles deleting a webhook together with its delivery log
+func (h *WebhookHandler) DeleteWebhook(c *gin.Context) {
+	existing, ok := h.loadWebhook(c)
+	if !ok {
+		return
+	}
+	setAuditEntity(c, "webhook", existing.ID, existing.ProjectID)
+	setAuditBefore(c, existing)
+
+	if err := h.webhookService.DeleteWebhook(existing.ID); err != nil {
+		handleWebhookError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// ListDeliveries handles listing the latest deliveries of a webhook
+func (h *WebhookHandler) ListDeliveries(c *gin.Context) {
+	webhook, ok := h.loadWebhook(c)
+	if !ok {
+		return
+	}
+
+	deliveries, err := h.webhookService.ListDeliveries(webhook.ID)
+	if err != nil {
+		handleWebhookError(c, err)
+		return
+	}
+
+	if deliveries == nil {
+		deliveries = []*models.WebhookDelivery{}
+	}
+
+	c.JSON(http.StatusOK, deliveries)
+}
+
+// Redeliver handles sending the payload of an earlier delivery of a webhook again
+func (h *WebhookHandler) Redeliver(c *gin.Context) {
+	deliveryID, err := strconv.ParseInt(c.Param("deliveryId"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid webhook delivery ID"})
+		return
+	}
+
+	webhook, ok := h.loadWebhook(c)
+	if !ok {
+		return
+	}
+
+	delivery, err := h.webhookService.Redeliver(webhook.ID, deliveryID)
+	if err != nil {
+		handleWebhookError(c, err)
+		return
+	}
+
+	setAuditEntity(c, "webhook", webhook.ID, webhook.ProjectID)
+	setAuditAfter(c, delivery)
+
+	c.JSON(http.StatusAccepted, delivery)
+}
+
+// loadWebhook retrieves the webhook of the request and checks that the current user may
+// manage the webhooks of its project
+func (h *WebhookHandler) loadWebhook(c *gin.Context) (*models.Webhook, bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid webhook ID"})
+		return nil, false
+	}
+
+	webhook, err := h.webhookService.GetWebhook(id)
+	if err != nil {
+		handleWebhookError(c, err)
+		return nil, false
+	}
+	if !h.requireManageAccess(c, webhook.ProjectID) {
+		return nil, false
+	}
+
+	return webhook, true
+}
+
+// requireManageAccess checks that the current user may manage the webhooks of a project
+func (h *WebhookHandler) requireManageAccess(c *gin.Context, projectID int64) bool {
+	if _, exists := c.Get("user"); !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found in context"})
+		return false
+	}
+
+	// Webhooks are project settings, which only maintainers may change
+	return authorizeProject(c, h.projectAccessService, projectID, models.PermissionManageProject)
+}
+
+// handleWebhookError maps webhook errors to HTTP responses
+func handleWebhookError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, repository.ErrWebhookNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "webhook not found"})
+	case errors.Is(err, service.ErrInvalidWebhookURL):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, repository.ErrWebhookDeliveryNotFound),
+		errors.Is(err, service.ErrWebhookDeliveryMismatch):
+		c.JSON(http.StatusNotFound, gin.H{"error": "webhook delivery not found"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,78 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mihaamiharu/test-case-management-be/internal/models"
+	"github.com/mihaamiharu/test-case-management-be/internal/service"
+	"github.com/mihaamiharu/test-case-management-be/internal/services"
+)
+
+// WorkflowHandler handles project workflow requests
+type WorkflowHandler struct {
+	workflowService      *service.WorkflowService
+	projectAccessService *services.ProjectAccessService
+}
+
+// NewWorkflowHandler creates a new workflow handler
+func NewWorkflowHandler(workflowService *service.WorkflowService, projectAccessService *services.ProjectAccessService) *WorkflowHandler {
+	return &WorkflowHandler{
+		workflowService:      workflowService,
+		projectAccessService: projectAccessService,
+	}
+}
+
+// GetWorkflow handles retrieving the statuses, priorities and transitions of a project
+func (h *WorkflowHandler) GetWorkflow(c *gin.Context) {
+	projectID, err := strconv.ParseInt(c.Param("projectId"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid project ID"})
+		return
+	}
+
+	workflow, err := h.workflowService.GetWorkflow(projectID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, workflow)
+}
+
+// UpdateWorkflow handles replacing the workflow of a project
+func (h *WorkflowHandler) UpdateWorkflow(c *gin.Context) {
+	projectID, err := strconv.ParseInt(c.Param("projectId"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid project ID"})
+		return
+	}
+
+	var workflowUpdate models.WorkflowUpdate
+	if err := c.ShouldBindJSON(&workflowUpdate); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Only maintainers may change the workflow of a project
+	if !authorizeProject(c, h.projectAccessService, projectID, models.PermissionManageProject) {
+		return
+	}
+
+	workflow, err := h.workflowService.UpdateWorkflow(projectID, &workflowUpdate)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidWorkflow):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, service.ErrWorkflowValueInUse):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, workflow)
+}
@@ -3,6 +3,7 @@ package config
 import (
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/joho/godotenv"
@@ -10,14 +11,116 @@ import (
 
 // Config holds all configuration for the application
 type Config struct {
-	DBHost         string
-	DBPort         string
-	DBUser         string
-	DBPassword     string
-	DBName         string
-	ServerPort     string
-	JWTSecret      string
-	JWTExpiryHours int
+	DBHost             string
+	DBPort             string
+	DBUser             string
+	DBPassword         string
+	DBName             string
+	ServerPort         string
+	TrustedProxies     []string // proxies whose X-Forwarded-For header gives the client IP; none when empty
+	JWTSecret          string
+	JWTAccessExpiry    time.Duration
+	RefreshTokenExpiry time.Duration
+	TrashRetention     time.Duration
+	TrashPurgeInterval time.Duration
+	OIDC               OIDCConfig
+	Accounts           AccountConfig
+	Mail               MailConfig
+	LoginProtection    LoginProtectionConfig
+	TwoFactor          TwoFactorConfig
+	Events             EventsConfig
+	Webhooks           WebhooksConfig
+	Notifications      NotificationsConfig
+	IssueTrackers      IssueTrackersConfig
+}
+
+// IssueTrackersConfig holds the configuration for reporting defects to external issue trackers
+type IssueTrackersConfig struct {
+	Timeout      time.Duration // time an issue tracker has to respond to a request
+	SyncInterval time.Duration // interval at which linked issues are polled for status changes; 0 disables
+
+	// AllowedNetworks are the IP addresses and CIDR ranges outside the public internet that
+	// trackers may be reached at, for self-hosted trackers on the internal network. Empty by
+	// default, since maintainers could otherwise probe the internal network.
+	AllowedNetworks []string
+}
+
+// NotificationsConfig holds the configuration for the daily email digest of notifications
+type NotificationsConfig struct {
+	DigestEnabled bool
+	DigestTime    string // time of day the digest is sent, as HH:MM in the server time zone
+}
+
+// WebhooksConfig holds the configuration for delivering events to project webhooks
+type WebhooksConfig struct {
+	Timeout      time.Duration // time a webhook has to respond to a request
+	MaxAttempts  int           // attempts of a delivery before it is marked failed
+	RetryBase    time.Duration // wait after the first failed attempt, doubled after each further failure
+	RetryMax     time.Duration
+	PollInterval time.Duration // interval at which due deliveries are looked for
+
+	// AllowPrivateHosts lets webhooks reach loopback, private and link-local addresses.
+	// Off by default, since maintainers could otherwise probe the internal network.
+	AllowPrivateHosts bool
+}
+
+// EventsConfig holds the configuration for the realtime event stream
+type EventsConfig struct {
+	Driver     string        // memory; other drivers can share events between servers
+	BufferSize int           // events buffered per subscriber before it misses events
+	Heartbeat  time.Duration // interval of keep-alive comments on idle streams
+}
+
+// TwoFactorConfig holds the configuration for two-factor authentication with TOTP
+type TwoFactorConfig struct {
+	Issuer          string        // name authenticator apps show for the account
+	RequiredRoles   string        // roles that must use two-factor authentication, e.g. "admin"
+	EncryptionKey   string        // key TOTP secrets are encrypted with; derived from JWTSecret when empty
+	ChallengeExpiry time.Duration // time between a password login and entering its code
+}
+
+// LoginProtectionConfig holds the configuration for brute-force protection of password logins
+type LoginProtectionConfig struct {
+	MaxAccountFailures int           // failures of an email address before it is locked; 0 disables
+	MaxIPFailures      int           // failures from an IP address before it is locked; 0 disables
+	FailureWindow      time.Duration // failures older than this are forgotten
+	LockoutDuration    time.Duration
+	DelayBase          time.Duration // wait after the first failure, doubled after each further failure
+	DelayMax           time.Duration
+}
+
+// AccountConfig holds the configuration for email verification, password resets and the password policy
+type AccountConfig struct {
+	AppURL                   string // frontend URL that links in emails point to
+	RequireEmailVerification bool
+	EmailVerificationExpiry  time.Duration
+	PasswordResetExpiry      time.Duration
+	PasswordMinLength        int
+}
+
+// MailConfig holds the configuration for sending email
+type MailConfig struct {
+	Driver       string // log, file or smtp
+	From         string
+	Dir          string // directory the file driver writes messages to
+	SMTPHost     string
+	SMTPPort     string
+	SMTPUsername string
+	SMTPPassword string
+}
+
+// OIDCConfig holds the configuration for single sign-on with an OpenID Connect provider
+type OIDCConfig struct {
+	IssuerURL         string
+	ClientID          string
+	ClientSecret      string
+	RedirectURL       string
+	Scopes            string
+	GroupsClaim       string
+	RoleMapping       string // group=role pairs, e.g. "qa-admins=admin,qa=tester"
+	ProjectMapping    string // group=projectID:level pairs, e.g. "qa=12:edit,support=12:view"
+	PostLoginRedirect string // frontend URL that receives the tokens in its fragment
+	SSODomains        string // email domains that must log in with SSO, e.g. "example.com"
 }
 
 // LoadConfig loads configuration from environment variables
@@ -26,14 +129,84 @@ func LoadConfig() (*Config, error) {
 	_ = godotenv.Load()
 
 	config := &Config{
-		DBHost:         getEnv("DB_HOST", "localhost"),
-		DBPort:         getEnv("DB_PORT", "3306"),
-		DBUser:         getEnv("DB_USER", "root"),
-		DBPassword:     getEnv("DB_PASSWORD", "password"),
-		DBName:         getEnv("DB_NAME", "test_case_manager"),
-		ServerPort:     getEnv("SERVER_PORT", "8080"),
-		JWTSecret:      getEnv("JWT_SECRET", "your-secret-key"),
-		JWTExpiryHours: getEnvAsInt("JWT_EXPIRY_HOURS", 24),
+		DBHost:             getEnv("DB_HOST", "localhost"),
+		DBPort:             getEnv("DB_PORT", "3306"),
+		DBUser:             getEnv("DB_USER", "root"),
+		DBPassword:         getEnv("DB_PASSWORD", "password"),
+		DBName:             getEnv("DB_NAME", "test_case_manager"),
+		ServerPort:         getEnv("SERVER_PORT", "8080"),
+		TrustedProxies:     getEnvAsList("TRUSTED_PROXIES"),
+		JWTSecret:          getEnv("JWT_SECRET", "your-secret-key"),
+		JWTAccessExpiry:    getEnvAsDuration("JWT_ACCESS_EXPIRY", 15*time.Minute),
+		RefreshTokenExpiry: getEnvAsDuration("REFRESH_TOKEN_EXPIRY", 30*24*time.Hour),
+		TrashRetention:     getEnvAsDuration("TRASH_RETENTION", 30*24*time.Hour),
+		TrashPurgeInterval: getEnvAsDuration("TRASH_PURGE_INTERVAL", time.Hour),
+		OIDC: OIDCConfig{
+			IssuerURL:         getEnv("OIDC_ISSUER_URL", ""),
+			ClientID:          getEnv("OIDC_CLIENT_ID", ""),
+			ClientSecret:      getEnv("OIDC_CLIENT_SECRET", ""),
+			RedirectURL:       getEnv("OIDC_REDIRECT_URL", ""),
+			Scopes:            getEnv("OIDC_SCOPES", "openid email profile"),
+			GroupsClaim:       getEnv("OIDC_GROUPS_CLAIM", "groups"),
+			RoleMapping:       getEnv("OIDC_ROLE_MAPPING", ""),
+			ProjectMapping:    getEnv("OIDC_PROJECT_MAPPING", ""),
+			PostLoginRedirect: getEnv("OIDC_POST_LOGIN_REDIRECT", ""),
+			SSODomains:        getEnv("SSO_DOMAINS", ""),
+		},
+		Accounts: AccountConfig{
+			AppURL:                   getEnv("APP_URL", "http://localhost:3000"),
+			RequireEmailVerification: getEnvAsBool("REQUIRE_EMAIL_VERIFICATION", false),
+			EmailVerificationExpiry:  getEnvAsDuration("EMAIL_VERIFICATION_EXPIRY", 48*time.Hour),
+			PasswordResetExpiry:      getEnvAsDuration("PASSWORD_RESET_EXPIRY", time.Hour),
+			PasswordMinLength:        getEnvAsInt("PASSWORD_MIN_LENGTH", 8),
+		},
+		Mail: MailConfig{
+			Driver:       getEnv("MAIL_DRIVER", "log"),
+			From:         getEnv("MAIL_FROM", "no-reply@localhost"),
+			Dir:          getEnv("MAIL_DIR", "mail"),
+			SMTPHost:     getEnv("SMTP_HOST", ""),
+			SMTPPort:     getEnv("SMTP_PORT", "587"),
+			SMTPUsername: getEnv("SMTP_USERNAME", ""),
+			SMTPPassword: getEnv("SMTP_PASSWORD", ""),
+		},
+		LoginProtection: LoginProtectionConfig{
+			MaxAccountFailures: getEnvAsInt("LOGIN_MAX_ACCOUNT_FAILURES", 5),
+			MaxIPFailures:      getEnvAsInt("LOGIN_MAX_IP_FAILURES", 50),
+			FailureWindow:      getEnvAsDuration("LOGIN_FAILURE_WINDOW", 15*time.Minute),
+			LockoutDuration:    getEnvAsDuration("LOGIN_LOCKOUT_DURATION", 15*time.Minute),
+			DelayBase:          getEnvAsDuration("LOGIN_DELAY_BASE", time.Second),
+			DelayMax:           getEnvAsDuration("LOGIN_DELAY_MAX", 30*time.Second),
+		},
+		TwoFactor: TwoFactorConfig{
+			Issuer:          getEnv("TWO_FACTOR_ISSUER", "Test Case Management"),
+			RequiredRoles:   getEnv("TWO_FACTOR_REQUIRED_ROLES", ""),
+			EncryptionKey:   getEnv("TWO_FACTOR_ENCRYPTION_KEY", ""),
+			ChallengeExpiry: getEnvAsDuration("TWO_FACTOR_CHALLENGE_EXPIRY", 5*time.Minute),
+		},
+		Events: EventsConfig{
+			Driver:     getEnv("EVENTS_DRIVER", "memory"),
+			BufferSize: getEnvAsInt("EVENTS_BUFFER_SIZE", 64),
+			Heartbeat:  getEnvAsDuration("EVENTS_HEARTBEAT", 30*time.Second),
+		},
+		Webhooks: WebhooksConfig{
+			Timeout:      getEnvAsDuration("WEBHOOK_TIMEOUT", 10*time.Second),
+			MaxAttempts:  getEnvAsInt("WEBHOOK_MAX_ATTEMPTS", 6),
+			RetryBase:    getEnvAsDuration("WEBHOOK_RETRY_BASE", 30*time.Second),
+			RetryMax:     getEnvAsDuration("WEBHOOK_RETRY_MAX", time.Hour),
+			PollInterval: getEnvAsDuration("WEBHOOK_POLL_INTERVAL", 15*time.Second),
+
+			AllowPrivateHosts: getEnvAsBool("WEBHOOK_ALLOW_PRIVATE_HOSTS", false),
+		},
+		Notifications: NotificationsConfig{
+			DigestEnabled: getEnvAsBool("NOTIFICATION_DIGEST_ENABLED", true),
+			DigestTime:    getEnv("NOTIFICATION_DIGEST_TIME", "08:00"),
+		},
+		IssueTrackers: IssueTrackersConfig{
+			Timeout:      getEnvAsDuration("ISSUE_TRACKER_TIMEOUT", 15*time.Second),
+			SyncInterval: getEnvAsDuration("ISSUE_TRACKER_SYNC_INTERVAL", 15*time.Minute),
+
+			AllowedNetworks: getEnvAsList("ISSUE_TRACKER_ALLOWED_NETWORKS"),
+		},
 	}
 
 	return config, nil
@@ -56,6 +229,27 @@ func getEnvAsInt(key string, defaultValue int) int {
 	return defaultValue
 }
 
+// Helper function to get an environment variable as a boolean
+func getEnvAsBool(key string, defaultValue bool) bool {
+	valueStr := getEnv(key, "")
+	if value, err := strconv.ParseBool(valueStr); err == nil {
+		return value
+	}
+	return defaultValue
+}
+
+// Helper function to get a comma-separated environment variable as a list, which is nil
+// when the variable is empty
+func getEnvAsList(key string) []string {
+	var values []string
+	for _, value := range strings.Split(getEnv(key, ""), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
 // Helper function to get an environment variable as a duration
 func getEnvAsDuration(key string, defaultValue time.Duration) time.Duration {
 	valueStr := getEnv(key, "")
@@ -0,0 +1,175 @@
+package events
+
+import (
+	"fmt"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/mihaamiharu/test-case-management-be/internal/config"
+)
+
+// Type names a kind of event
+type Type string
+
+const (
+	TestCaseCreated     Type = "test_case.created"
+	TestCaseUpdated     Type = "test_case.updated"
+	TestCaseDeleted     Type = "test_case.deleted"
+	TestRunCaseAdded    Type = "test_run.case_added"
+	TestRunAssigned     Type = "test_run.assigned"
+	TestRunStarted      Type = "test_run.started"
+	TestRunCompleted    Type = "test_run.completed"
+	TestExecutionResult Type = "test_execution.result"
+	DefectOpened        Type = "defect.opened"
+	CommentCreated      Type = "comment.created"
+	CommentUpdated      Type = "comment.updated"
+	CommentDeleted      Type = "comment.deleted"
+	CommentResolved     Type = "comment.resolved"
+)
+
+// Event is something that happened in a project, optionally in one of its test runs
+type Event struct {
+	ID         int64       `json:"id"`
+	Type       Type        `json:"type"`
+	ProjectID  int64       `json:"project_id"`
+	TestRunID  int64       `json:"test_run_id,omitempty"`
+	ActorID    int64       `json:"actor_id,omitempty"`
+	Data       interface{} `json:"data"`
+	OccurredAt time.Time   `json:"occurred_at"`
+}
+
+// Filter selects the events a subscriber receives. Subscribers of a test run receive the
+// events of the run and the events of its project that belong to no run.
+type Filter struct {
+	ProjectID int64
+	TestRunID int64
+}
+
+// Matches reports whether an event passes the filter
+func (f Filter) Matches(event *Event) bool {
+	if event.ProjectID != f.ProjectID {
+		return false
+	}
+	return f.TestRunID == 0 || event.TestRunID == 0 || event.TestRunID == f.TestRunID
+}
+
+// Publisher publishes events
+type Publisher interface {
+	Publish(event *Event)
+}
+
+// Publishers publishes every event to each of its publishers in turn
+type Publishers []Publisher
+
+// Publish publishes an event to every publisher
+func (p Publishers) Publish(event *Event) {
+	if event.OccurredAt.IsZero() {
+		event.OccurredAt = time.Now()
+	}
+	for _, publisher := range p {
+		publisher.Publish(event)
+	}
+}
+
+// Subscription receives the events that match its filter until it is closed
+type Subscription interface {
+	Events() <-chan *Event
+	Close()
+}
+
+// Broker delivers published events to the subscriptions whose filter they match
+type Broker interface {
+	Publisher
+	Subscribe(filter Filter) Subscription
+}
+
+// NewBroker creates the broker selected by the events configuration
+func NewBroker(cfg config.EventsConfig) (Broker, error) {
+	switch cfg.Driver {
+	case "", "memory":
+		return NewMemoryBroker(cfg.BufferSize), nil
+	default:
+		return nil, fmt.Errorf("unknown events driver %q", cfg.Driver)
+	}
+}
+
+// MemoryBroker delivers events to subscribers of the same process. Subscribers that fall
+// more than a buffer behind miss events rather than slowing down publishers.
+type MemoryBroker struct {
+	bufferSize    int
+	lastID        int64
+	mu            sync.RWMutex
+	subscriptions map[*memorySubscription]bool
+}
+
+// NewMemoryBroker creates an in-process broker that buffers bufferSize events per subscriber
+func NewMemoryBroker(bufferSize int) *MemoryBroker {
+	if bufferSize < 1 {
+		bufferSize = 1
+	}
+	return &MemoryBroker{
+		bufferSize:    bufferSize,
+		subscriptions: make(map[*memorySubscription]bool),
+	}
+}
+
+// Publish numbers an event and delivers it to every matching subscription without blocking
+func (b *MemoryBroker) Publish(event *Event) {
+	event.ID = atomic.AddInt64(&b.lastID, 1)
+	if event.OccurredAt.IsZero() {
+		event.OccurredAt = time.Now()
+	}
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	for subscription := range b.subscriptions {
+		if !subscription.filter.Matches(event) {
+			continue
+		}
+		select {
+		case subscription.events <- event:
+		default:
+			log.Printf("dropping %s event %d for a slow subscriber of project %d", event.Type, event.ID, event.ProjectID)
+		}
+	}
+}
+
+// Subscribe starts receiving the events that match a filter
+func (b *MemoryBroker) Subscribe(filter Filter) Subscription {
+	subscription := &memorySubscription{
+		broker: b,
+		filter: filter,
+		events: make(chan *Event, b.bufferSize),
+	}
+
+	b.mu.Lock()
+	b.subscriptions[subscription] = true
+	b.mu.Unlock()
+
+	return subscription
+}
+
+// memorySubscription is a subscription of a MemoryBroker
+type memorySubscription struct {
+	broker *MemoryBroker
+	filter Filter
+	events chan *Event
+	once   sync.Once
+}
+
+// Events returns the channel events are delivered on; it is closed by Close
+func (s *memorySubscription) Events() <-chan *Event {
+	return s.events
+}
+
+// Close stops the subscription
+func (s *memorySubscription) Close() {
+	s.once.Do(func() {
+		s.broker.mu.Lock()
+		delete(s.broker.subscriptions, s)
+		s.broker.mu.Unlock()
+		close(s.events)
+	})
+}
@@ -0,0 +1,62 @@
+package events
+
+import (
+	"testing"
+
+	"github.com/mihaamiharu/test-case-management-be/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFilter_Matches(t *testing.T) {
+	project := Filter{ProjectID: 1}
+	run := Filter{ProjectID: 1, TestRunID: 7}
+
+	// Test case: project subscribers receive every event of the project
+	assert.True(t, project.Matches(&Event{ProjectID: 1}))
+	assert.True(t, project.Matches(&Event{ProjectID: 1, TestRunID: 8}))
+	assert.False(t, project.Matches(&Event{ProjectID: 2}))
+
+	// Test case: run subscribers receive the events of the run and of its project, but not of other runs
+	assert.True(t, run.Matches(&Event{ProjectID: 1, TestRunID: 7}))
+	assert.True(t, run.Matches(&Event{ProjectID: 1}))
+	assert.False(t, run.Matches(&Event{ProjectID: 1, TestRunID: 8}))
+	assert.False(t, run.Matches(&Event{ProjectID: 2, TestRunID: 7}))
+}
+
+func TestMemoryBroker(t *testing.T) {
+	broker := NewMemoryBroker(1)
+	first := broker.Subscribe(Filter{ProjectID: 1})
+	second := broker.Subscribe(Filter{ProjectID: 1})
+	other := broker.Subscribe(Filter{ProjectID: 2})
+
+	// Test case: events are numbered and delivered to every matching subscription
+	broker.Publish(&Event{Type: TestCaseUpdated, ProjectID: 1})
+	event := <-first.Events()
+	assert.Equal(t, int64(1), event.ID)
+	assert.False(t, event.OccurredAt.IsZero())
+	assert.Equal(t, event, <-second.Events())
+	assert.Empty(t, other.Events())
+
+	// Test case: a full subscription misses events instead of blocking the publisher
+	broker.Publish(&Event{Type: CommentCreated, ProjectID: 1})
+	broker.Publish(&Event{Type: CommentDeleted, ProjectID: 1})
+	assert.Equal(t, CommentCreated, (<-first.Events()).Type)
+	assert.Empty(t, first.Events())
+
+	// Test case: closed subscriptions receive nothing more and can be closed twice
+	other.Close()
+	other.Close()
+	broker.Publish(&Event{Type: TestCaseDeleted, ProjectID: 2})
+	_, open := <-other.Events()
+	assert.False(t, open)
+}
+
+func TestNewBroker(t *testing.T) {
+	broker, err := NewBroker(config.EventsConfig{Driver: "memory", BufferSize: 8})
+	require.NoError(t, err)
+	assert.IsType(t, &MemoryBroker{}, broker)
+
+	_, err = NewBroker(config.EventsConfig{Driver: "carrier-pigeon"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,104 @@
+package issuetracker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/mihaamiharu/test-case-management-be/internal/models"
+)
+
+// GitHub reports defects as issues of a GitHub repository, authenticated with a token that
+// may write its issues
+type GitHub struct {
+	cfg     *models.IssueTracker
+	baseURL string
+	client  *http.Client
+}
+
+// githubIssue is a GitHub issue. Closed issues have a state reason of completed or
+// not_planned.
+type githubIssue struct {
+	Number      int64  `json:"number"`
+	HTMLURL     string `json:"html_url"`
+	State       string `json:"state"`
+	StateReason string `json:"state_reason"`
+}
+
+// CreateIssue reports a defect as a new issue
+func (g *GitHub) CreateIssue(ctx context.Context, defect *models.Defect) (*Issue, error) {
+	request := map[string]interface{}{
+		"title":  defect.Title,
+		"body":   issueBody(defect),
+		"labels": []string{"defect", "severity:" + string(defect.Severity)},
+	}
+	var created githubIssue
+	if err := doJSON(ctx, g.client, http.MethodPost, g.issuesURL(), g.header(), request, &created); err != nil {
+		return nil, fmt.Errorf("failed to create GitHub issue: %w", err)
+	}
+	return g.toIssue(&created), nil
+}
+
+// GetIssue reads the state of an issue by number
+func (g *GitHub) GetIssue(ctx context.Context, key string) (*Issue, error) {
+	var issue githubIssue
+	if err := doJSON(ctx, g.client, http.MethodGet, g.issuesURL()+"/"+key, g.header(), nil, &issue); err != nil {
+		return nil, fmt.Errorf("failed to get GitHub issue %s: %w", key, err)
+	}
+	return g.toIssue(&issue), nil
+}
+
+// ParseWebhook reads an issue change of a GitHub issues webhook, which is signed with
+// the webhook secret in the X-Hub-Signature-256 header
+func (g *GitHub) ParseWebhook(header http.Header, body []byte) (*Issue, error) {
+	if err := verifyHMAC(g.cfg.WebhookSecret, header.Get("X-Hub-Signature-256"), body); err != nil {
+		return nil, err
+	}
+	if header.Get("X-GitHub-Event") != "issues" {
+		return nil, nil
+	}
+
+	var event struct {
+		Issue *githubIssue `json:"issue"`
+	}
+	if err := json.Unmarshal(body, &event); err != nil {
+		return nil, fmt.Errorf("invalid GitHub webhook: %v", err)
+	}
+	if event.Issue == nil {
+		return nil, nil
+	}
+	return g.toIssue(event.Issue), nil
+}
+
+// issuesURL returns the API address of the issues of the repository
+func (g *GitHub) issuesURL() string {
+	return g.baseURL + "/repos/" + g.cfg.ProjectKey + "/issues"
+}
+
+// header returns the headers of API requests
+func (g *GitHub) header() http.Header {
+	return http.Header{
+		"Authorization":        {"Bearer " + g.cfg.Token},
+		"Accept":               {"application/vnd.github+json"},
+		"X-Github-Api-Version": {"2022-11-28"},
+	}
+}
+
+// toIssue maps the state of a GitHub issue to a defect status. Issues closed as not
+// planned close their defect; other closed issues resolve it.
+func (g *GitHub) toIssue(issue *githubIssue) *Issue {
+	defectStatus := models.DefectStatusOpen
+	status := issue.State
+	if issue.State == "closed" {
+		defectStatus = models.DefectStatusResolved
+		if issue.StateReason == "not_planned" {
+			defectStatus = models.DefectStatusClosed
+		}
+		if issue.StateReason != "" {
+			status += " (" + issue.StateReason + ")"
+		}
+	}
+	return &Issue{Key: strconv.FormatInt(issue.Number, 10), URL: issue.HTMLURL, Status: status, DefectStatus: defectStatus}
+}
@@ -0,0 +1,100 @@
+package issuetracker
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/mihaamiharu/test-case-management-be/internal/models"
+)
+
+// GitLab reports defects as issues of a GitLab project, authenticated with an access token
+// that may write its issues
+type GitLab struct {
+	cfg     *models.IssueTracker
+	baseURL string
+	client  *http.Client
+}
+
+// gitlabIssue is a GitLab issue; its state is opened or closed
+type gitlabIssue struct {
+	IID    int64    `json:"iid"`
+	WebURL string   `json:"web_url"`
+	State  string   `json:"state"`
+	Labels []string `json:"labels"`
+}
+
+// CreateIssue reports a defect as a new issue
+func (g *GitLab) CreateIssue(ctx context.Context, defect *models.Defect) (*Issue, error) {
+	request := map[string]interface{}{
+		"title":       defect.Title,
+		"description": issueBody(defect),
+		"labels":      "defect,severity::" + string(defect.Severity),
+	}
+	var created gitlabIssue
+	if err := doJSON(ctx, g.client, http.MethodPost, g.issuesURL(), g.header(), request, &created); err != nil {
+		return nil, fmt.Errorf("failed to create GitLab issue: %w", err)
+	}
+	return g.toIssue(&created), nil
+}
+
+// GetIssue reads the state of an issue by its number in the project
+func (g *GitLab) GetIssue(ctx context.Context, key string) (*Issue, error) {
+	var issue gitlabIssue
+	if err := doJSON(ctx, g.client, http.MethodGet, g.issuesURL()+"/"+key, g.header(), nil, &issue); err != nil {
+		return nil, fmt.Errorf("failed to get GitLab issue %s: %w", key, err)
+	}
+	return g.toIssue(&issue), nil
+}
+
+// ParseWebhook reads an issue change of a GitLab issue hook, which carries the secret
+// token of the hook in the X-Gitlab-Token header
+func (g *GitLab) ParseWebhook(header http.Header, body []byte) (*Issue, error) {
+	if g.cfg.WebhookSecret == "" || subtle.ConstantTimeCompare([]byte(header.Get("X-Gitlab-Token")), []byte(g.cfg.WebhookSecret)) != 1 {
+		return nil, ErrInvalidSignature
+	}
+
+	var event struct {
+		ObjectKind       string `json:"object_kind"`
+		ObjectAttributes struct {
+			IID   int64  `json:"iid"`
+			URL   string `json:"url"`
+			State string `json:"state"`
+		} `json:"object_attributes"`
+	}
+	if err := json.Unmarshal(body, &event); err != nil {
+		return nil, fmt.Errorf("invalid GitLab webhook: %v", err)
+	}
+	if event.ObjectKind != "issue" {
+		return nil, nil
+	}
+	return g.toIssue(&gitlabIssue{
+		IID:    event.ObjectAttributes.IID,
+		WebURL: event.ObjectAttributes.URL,
+		State:  event.ObjectAttributes.State,
+	}), nil
+}
+
+// issuesURL returns the API address of the issues of the project
+func (g *GitLab) issuesURL() string {
+	return g.baseURL + "/api/v4/projects/" + url.PathEscape(strings.Trim(g.cfg.ProjectKey, "/")) + "/issues"
+}
+
+// header returns the headers of API requests
+func (g *GitLab) header() http.Header {
+	return http.Header{"Private-Token": {g.cfg.Token}}
+}
+
+// toIssue maps the state of a GitLab issue to a defect status
+func (g *GitLab) toIssue(issue *gitlabIssue) *Issue {
+	defectStatus := models.DefectStatusOpen
+	if issue.State == "closed" {
+		defectStatus = models.DefectStatusResolved
+	}
+	return &Issue{Key: strconv.FormatInt(issue.IID, 10), URL: issue.WebURL, Status: issue.State, DefectStatus: defectStatus}
+}
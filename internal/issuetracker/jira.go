@@ -0,0 +1,116 @@
+package issuetracker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/mihaamiharu/test-case-management-be/internal/models"
+)
+
+// jiraIssueType is the issue type defects are reported as
+const jiraIssueType = "Bug"
+
+// Jira reports defects to a Jira project through the REST API, authenticated with the
+// email address and API token of an account
+type Jira struct {
+	cfg     *models.IssueTracker
+	baseURL string
+	client  *http.Client
+}
+
+// jiraStatus is the status of a Jira issue. Its category key is new, indeterminate or
+// done whatever the workflow calls the status.
+type jiraStatus struct {
+	Name           string `json:"name"`
+	StatusCategory struct {
+		Key string `json:"key"`
+	} `json:"statusCategory"`
+}
+
+// jiraIssue is a Jira issue with its status
+type jiraIssue struct {
+	Key    string `json:"key"`
+	Fields struct {
+		Status jiraStatus `json:"status"`
+	} `json:"fields"`
+}
+
+// CreateIssue reports a defect as a new bug
+func (j *Jira) CreateIssue(ctx context.Context, defect *models.Defect) (*Issue, error) {
+	request := map[string]interface{}{
+		"fields": map[string]interface{}{
+			"project":     map[string]string{"key": j.cfg.ProjectKey},
+			"issuetype":   map[string]string{"name": jiraIssueType},
+			"summary":     defect.Title,
+			"description": issueBody(defect),
+			"labels":      []string{"defect", "severity-" + string(defect.Severity)},
+		},
+	}
+	var created struct {
+		Key string `json:"key"`
+	}
+	if err := doJSON(ctx, j.client, http.MethodPost, j.baseURL+"/rest/api/2/issue", j.header(), request, &created); err != nil {
+		return nil, fmt.Errorf("failed to create Jira issue: %w", err)
+	}
+
+	return &Issue{Key: created.Key, URL: j.browseURL(created.Key), DefectStatus: models.DefectStatusOpen}, nil
+}
+
+// GetIssue reads the status of an issue
+func (j *Jira) GetIssue(ctx context.Context, key string) (*Issue, error) {
+	var issue jiraIssue
+	endpoint := j.baseURL + "/rest/api/2/issue/" + url.PathEscape(key) + "?fields=status"
+	if err := doJSON(ctx, j.client, http.MethodGet, endpoint, j.header(), nil, &issue); err != nil {
+		return nil, fmt.Errorf("failed to get Jira issue %s: %w", key, err)
+	}
+	return j.toIssue(&issue), nil
+}
+
+// ParseWebhook reads an issue update of a Jira webhook, which is signed with the
+// webhook secret in the X-Hub-Signature header
+func (j *Jira) ParseWebhook(header http.Header, body []byte) (*Issue, error) {
+	if err := verifyHMAC(j.cfg.WebhookSecret, header.Get("X-Hub-Signature"), body); err != nil {
+		return nil, err
+	}
+
+	var event struct {
+		WebhookEvent string     `json:"webhookEvent"`
+		Issue        *jiraIssue `json:"issue"`
+	}
+	if err := json.Unmarshal(body, &event); err != nil {
+		return nil, fmt.Errorf("invalid Jira webhook: %v", err)
+	}
+	if !strings.HasPrefix(event.WebhookEvent, "jira:issue_") || event.Issue == nil {
+		return nil, nil
+	}
+	return j.toIssue(event.Issue), nil
+}
+
+// header returns the headers of API requests
+func (j *Jira) header() http.Header {
+	req := &http.Request{Header: http.Header{}}
+	req.SetBasicAuth(j.cfg.Username, j.cfg.Token)
+	return req.Header
+}
+
+// browseURL returns the address of an issue in the Jira web interface
+func (j *Jira) browseURL(key string) string {
+	return j.baseURL + "/browse/" + key
+}
+
+// toIssue maps the status category of a Jira issue to a defect status
+func (j *Jira) toIssue(issue *jiraIssue) *Issue {
+	status := issue.Fields.Status
+	defectStatus := models.DefectStatusOpen
+	switch status.StatusCategory.Key {
+	case "indeterminate":
+		defectStatus = models.DefectStatusInProgress
+	case "done":
+		defectStatus = models.DefectStatusResolved
+	}
+	return &Issue{Key: issue.Key, URL: j.browseURL(issue.Key), Status: status.Name, DefectStatus: defectStatus}
+}
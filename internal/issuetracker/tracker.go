@@ -0,0 +1,147 @@
+package issuetracker
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/mihaamiharu/test-case-management-be/internal/models"
+)
+
+var (
+	ErrInvalidSignature = errors.New("invalid webhook signature")
+	ErrIssueNotFound    = errors.New("issue not found")
+)
+
+// errorBodyLimit is the number of bytes of an error response kept in the error
+const errorBodyLimit = 512
+
+// Issue is an issue in an external tracker, with its status there and the defect status
+// that status maps to
+type Issue struct {
+	Key          string
+	URL          string
+	Status       string
+	DefectStatus models.DefectStatus
+}
+
+// Tracker creates and reads issues in an external issue tracker
+type Tracker interface {
+	// CreateIssue reports a defect as a new issue
+	CreateIssue(ctx context.Context, defect *models.Defect) (*Issue, error)
+	// GetIssue reads the current status of an issue
+	GetIssue(ctx context.Context, key string) (*Issue, error)
+	// ParseWebhook checks the signature of a webhook request of the tracker and returns the
+	// issue it reports a change of, or nil for requests about anything else. Requests are
+	// rejected when the tracker has no webhook secret.
+	ParseWebhook(header http.Header, body []byte) (*Issue, error)
+}
+
+// New creates the tracker client of an issue tracker configuration. Requests are sent with
+// client, which sets their timeout.
+func New(cfg *models.IssueTracker, client *http.Client) (Tracker, error) {
+	baseURL := strings.TrimSuffix(cfg.BaseURL, "/")
+	switch cfg.Provider {
+	case models.IssueTrackerJira:
+		if baseURL == "" {
+			return nil, errors.New("Jira needs the base URL of the site, e.g. https://example.atlassian.net")
+		}
+		if cfg.Username == "" {
+			return nil, errors.New("Jira needs the email address of the account the API token belongs to")
+		}
+		return &Jira{cfg: cfg, baseURL: baseURL, client: client}, nil
+	case models.IssueTrackerGitHub:
+		if baseURL == "" {
+			baseURL = "https://api.github.com"
+		}
+		if strings.Count(cfg.ProjectKey, "/") != 1 {
+			return nil, errors.New("GitHub needs the repository as owner/name")
+		}
+		return &GitHub{cfg: cfg, baseURL: baseURL, client: client}, nil
+	case models.IssueTrackerGitLab:
+		if baseURL == "" {
+			baseURL = "https://gitlab.com"
+		}
+		return &GitLab{cfg: cfg, baseURL: baseURL, client: client}, nil
+	default:
+		return nil, fmt.Errorf("unknown issue tracker %q", cfg.Provider)
+	}
+}
+
+// issueBody describes a defect in the body of the issue it is reported as
+func issueBody(defect *models.Defect) string {
+	return fmt.Sprintf("%s\n\nSeverity: %s\nFound by test execution %d of test run %d, test case %d.",
+		defect.Description, defect.Severity, defect.TestExecutionID, defect.TestRunID, defect.TestCaseID)
+}
+
+// doJSON sends a request with an optional JSON body and decodes the JSON response into
+// result. 404 responses are reported as ErrIssueNotFound.
+func doJSON(ctx context.Context, client *http.Client, method, url string, header http.Header, body, result interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to encode request: %v", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, url, reader)
+	if err != nil {
+		return err
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	if req.Header.Get("Accept") == "" {
+		req.Header.Set("Accept", "application/json")
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return ErrIssueNotFound
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		text, _ := io.ReadAll(io.LimitReader(resp.Body, errorBodyLimit))
+		return fmt.Errorf("%s %s returned %d: %s", method, url, resp.StatusCode, strings.TrimSpace(string(text)))
+	}
+
+	if result == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+		return fmt.Errorf("failed to decode response: %v", err)
+	}
+	return nil
+}
+
+// verifyHMAC checks a "sha256=<hex>" signature of a body keyed with a secret
+func verifyHMAC(secret, signature string, body []byte) error {
+	if secret == "" {
+		return ErrInvalidSignature
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	expected := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+	if !hmac.Equal([]byte(expected), []byte(signature)) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+package mail
+
+import (
+	"fmt"
+	"log"
+	"net"
+	"net/smtp"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mihaamiharu/test-case-management-be/internal/config"
+)
+
+// Message is a plain text email
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Sender sends email
+type Sender interface {
+	Send(msg *Message) error
+}
+
+// NewSender creates the sender selected by the mail configuration
+func NewSender(cfg config.MailConfig) (Sender, error) {
+	switch cfg.Driver {
+	case "", "log":
+		return &LogSender{from: cfg.From}, nil
+	case "file":
+		return NewFileSender(cfg.Dir, cfg.From), nil
+	case "smtp":
+		if cfg.SMTPHost == "" {
+			return nil, fmt.Errorf("SMTP_HOST is required for the smtp mail driver")
+		}
+		return &SMTPSender{cfg: cfg}, nil
+	default:
+		return nil, fmt.Errorf("unknown mail driver %q", cfg.Driver)
+	}
+}
+
+// LogSender writes messages to the server log instead of sending them, for development
+type LogSender struct {
+	from string
+}
+
+// Send logs a message
+func (s *LogSender) Send(msg *Message) error {
+	log.Printf("mail from %s to %s: %s\n%s", s.from, msg.To, msg.Subject, msg.Body)
+	return nil
+}
+
+// FileSender writes each message to a file in a directory instead of sending it, for
+// development and tests
+type FileSender struct {
+	dir  string
+	from string
+	mu   sync.Mutex
+	sent int
+}
+
+// NewFileSender creates a sender that writes messages to dir
+func NewFileSender(dir, from string) *FileSender {
+	return &FileSender{dir: dir, from: from}
+}
+
+// Send writes a message to a new .eml file
+func (s *FileSender) Send(msg *Message) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := os.MkdirAll(s.dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create mail directory: %v", err)
+	}
+
+	s.sent++
+	name := fmt.Sprintf("%s-%03d.eml", time.Now().Format("20060102-150405.000000"), s.sent)
+	if err := os.WriteFile(filepath.Join(s.dir, name), format(s.from, msg), 0o644); err != nil {
+		return fmt.Errorf("failed to write mail: %v", err)
+	}
+	return nil
+}
+
+// SMTPSender sends messages through an SMTP server
+type SMTPSender struct {
+	cfg config.MailConfig
+}
+
+// Send sends a message
+func (s *SMTPSender) Send(msg *Message) error {
+	var auth smtp.Auth
+	if s.cfg.SMTPUsername != "" {
+		auth = smtp.PlainAuth("", s.cfg.SMTPUsername, s.cfg.SMTPPassword, s.cfg.SMTPHost)
+	}
+
+	addr := net.JoinHostPort(s.cfg.SMTPHost, s.cfg.SMTPPort)
+	if err := smtp.SendMail(addr, auth, s.cfg.From, []string{msg.To}, format(s.cfg.From, msg)); err != nil {
+		return fmt.Errorf("failed to send mail: %v", err)
+	}
+	return nil
+}
+
+// format renders a message with its headers
+func format(from string, msg *Message) []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", from)
+	fmt.Fprintf(&b, "To: %s\r\n", msg.To)
+	fmt.Fprintf(&b, "Subject: %s\r\n", msg.Subject)
+	fmt.Fprintf(&b, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n\r\n")
+	b.WriteString(strings.ReplaceAll(msg.Body, "\n", "\r\n"))
+	return []byte(b.String())
+}
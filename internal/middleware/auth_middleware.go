@@ -2,54 +2,13 @@ package middleware
 
 import (
 	"net/http"
-	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/mihaamiharu/test-case-management-be/internal/models"
-	"github.com/mihaamiharu/test-case-management-be/internal/service"
 )
 
-// AuthMiddleware protects routes by requiring a valid JWT token
-func AuthMiddleware(authService *service.AuthService) gin.HandlerFunc {
-	return func(c *gin.Context) {
-		authHeader := c.GetHeader("Authorization")
-		if authHeader == "" {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Authorization header is required"})
-			c.Abort()
-			return
-		}
-
-		// Check if the header has the Bearer prefix
-		parts := strings.Split(authHeader, " ")
-		if len(parts) != 2 || parts[0] != "Bearer" {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Authorization header format must be Bearer {token}"})
-			c.Abort()
-			return
-		}
-
-		tokenString := parts[1]
-		token, err := authService.ValidateToken(tokenString)
-		if err != nil || !token.Valid {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired token"})
-			c.Abort()
-			return
-		}
-
-		// Get user from token
-		user, err := authService.GetUserFromToken(token)
-		if err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Failed to get user from token"})
-			c.Abort()
-			return
-		}
-
-		// Set user in context
-		c.Set("user", user)
-		c.Next()
-	}
-}
-
-// RoleMiddleware restricts access to specific user roles
+// RoleMiddleware restricts access to specific user roles. It runs after the authentication
+// middleware of the API, which sets the user in the context.
 func RoleMiddleware(roles ...models.Role) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		userInterface, exists := c.Get("user")
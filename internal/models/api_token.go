@@ -0,0 +1,88 @@
+package models
+
+import (
+	"time"
+)
+
+// APITokenPrefix starts every personal API token so it can be told apart from a JWT
+const APITokenPrefix = "tcm_"
+
+// APITokenScope defines what a personal API token may do
+type APITokenScope string
+
+const (
+	// APITokenScopeRead allows reading everything the user can see
+	APITokenScopeRead APITokenScope = "read"
+
+	// APITokenScopeWrite allows reading and changing everything the user can change
+	APITokenScopeWrite APITokenScope = "write"
+
+	// APITokenScopeResults allows reading and recording test results
+	APITokenScopeResults APITokenScope = "results"
+)
+
+// APIToken represents a personal access token for CI pipelines and scripts
+type APIToken struct {
+	ID          int64           `json:"id"`
+	UserID      int64           `json:"user_id"`
+	Name        string          `json:"name"`
+	TokenPrefix string          `json:"token_prefix"`
+	TokenHash   string          `json:"-"`
+	Scopes      []APITokenScope `json:"scopes"`
+	ProjectID   *int64          `json:"project_id"`
+	ExpiresAt   *time.Time      `json:"expires_at"`
+	LastUsedAt  *time.Time      `json:"last_used_at"`
+	RevokedAt   *time.Time      `json:"revoked_at,omitempty"`
+	CreatedAt   time.Time       `json:"created_at"`
+}
+
+// APITokenCreate represents data needed to create a personal API token
+type APITokenCreate struct {
+	Name      string          `json:"name" binding:"required,max=100"`
+	Scopes    []APITokenScope `json:"scopes" binding:"required,min=1,dive,oneof=read write results"`
+	ProjectID *int64          `json:"project_id"`
+	ExpiresAt *time.Time      `json:"expires_at"`
+}
+
+// APITokenResponse represents the API token data to be returned in API responses
+type APITokenResponse struct {
+	ID          int64           `json:"id"`
+	Name        string          `json:"name"`
+	TokenPrefix string          `json:"token_prefix"`
+	Scopes      []APITokenScope `json:"scopes"`
+	ProjectID   *int64          `json:"project_id"`
+	ExpiresAt   *time.Time      `json:"expires_at"`
+	LastUsedAt  *time.Time      `json:"last_used_at"`
+	CreatedAt   time.Time       `json:"created_at"`
+}
+
+// APITokenCreatedResponse represents a newly created API token. The token itself is only
+// returned here.
+type APITokenCreatedResponse struct {
+	APITokenResponse
+	Token string `json:"token"`
+}
+
+// ToResponse converts an APIToken to APITokenResponse
+func (t *APIToken) ToResponse() APITokenResponse {
+	return APITokenResponse{
+		ID:          t.ID,
+		Name:        t.Name,
+		TokenPrefix: t.TokenPrefix,
+		Scopes:      t.Scopes,
+		ProjectID:   t.ProjectID,
+		ExpiresAt:   t.ExpiresAt,
+		LastUsedAt:  t.LastUsedAt,
+		CreatedAt:   t.CreatedAt,
+	}
+}
+
+// HasScope reports whether the token has a scope
+func (t *APIToken) HasScope(scope APITokenScope) bool {
+	for _, s := range t.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,52 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// AuditAction represents the kind of change an audit entry records
+type AuditAction string
+
+const (
+	AuditActionCreate AuditAction = "create"
+	AuditActionUpdate AuditAction = "update"
+	AuditActionDelete AuditAction = "delete"
+	AuditActionLock   AuditAction = "lock"
+	AuditActionUnlock AuditAction = "unlock"
+)
+
+// AuditEntry represents a recorded change to an entity
+type AuditEntry struct {
+	ID             int64           `json:"id"`
+	ActorID        *int64          `json:"actor_id"`
+	Action         AuditAction     `json:"action"`
+	EntityType     string          `json:"entity_type"`
+	EntityID       *int64          `json:"entity_id"`
+	ProjectID      *int64          `json:"project_id"`
+	OrganizationID *int64          `json:"organization_id"`
+	Method         string          `json:"method"`
+	Path           string          `json:"path"`
+	IPAddress      string          `json:"ip_address"`
+	Before         json.RawMessage `json:"before"`
+	After          json.RawMessage `json:"after"`
+	CreatedAt      time.Time       `json:"created_at"`
+}
+
+// AuditFilter represents the criteria of an audit log query.
+// Zero values do not filter.
+type AuditFilter struct {
+	OrganizationID int64
+	// IncludeInstallation also matches entries that belong to no organization, such as
+	// login lockouts
+	IncludeInstallation bool
+	ActorID             int64
+	ProjectID           int64
+	EntityType          string
+	EntityID            int64
+	Action              AuditAction
+	From                time.Time
+	To                  time.Time
+	Limit               int
+	Offset              int
+}
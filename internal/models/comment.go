@@ -0,0 +1,107 @@
+package models
+
+import (
+	"time"
+)
+
+// Comment represents a markdown comment on a test case or one of its steps.
+// Replies reference their parent comment.
+type Comment struct {
+	ID         int64          `json:"id"`
+	TestCaseID int64          `json:"test_case_id"`
+	StepID     *int64         `json:"step_id,omitempty"`
+	ParentID   *int64         `json:"parent_id,omitempty"`
+	Body       string         `json:"body"`
+	CreatedBy  int64          `json:"created_by"`
+	IsResolved bool           `json:"is_resolved"`
+	ResolvedBy *int64         `json:"resolved_by,omitempty"`
+	ResolvedAt *time.Time     `json:"resolved_at,omitempty"`
+	EditedAt   *time.Time     `json:"edited_at,omitempty"`
+	CreatedAt  time.Time      `json:"created_at"`
+	UpdatedAt  time.Time      `json:"updated_at"`
+	Mentions   []*UserSummary `json:"mentions,omitempty"`
+	Replies    []*Comment     `json:"replies,omitempty"`
+}
+
+// UserSummary represents the public identity of a user referenced by other records
+type UserSummary struct {
+	ID       int64  `json:"id"`
+	Username string `json:"username"`
+}
+
+// CommentCreate represents data needed to create a new comment
+type CommentCreate struct {
+	Body     string `json:"body" binding:"required,max=10000"`
+	StepID   *int64 `json:"step_id"`
+	ParentID *int64 `json:"parent_id"`
+}
+
+// CommentUpdate represents data needed to edit a comment
+type CommentUpdate struct {
+	Body string `json:"body" binding:"required,max=10000"`
+}
+
+// ActivityType identifies the kind of entry in a test case activity feed
+type ActivityType string
+
+const (
+	ActivityTypeComment ActivityType = "comment"
+	ActivityTypeEdit    ActivityType = "edit"
+)
+
+// ActivityItem represents a single entry in the activity feed of a test case
+type ActivityItem struct {
+	Type       ActivityType     `json:"type"`
+	UserID     int64            `json:"user_id"`
+	OccurredAt time.Time        `json:"occurred_at"`
+	Comment    *CommentResponse `json:"comment,omitempty"`
+	Edit       *TestCaseHistory `json:"edit,omitempty"`
+}
+
+// CommentResponse represents the comment data to be returned in API responses
+type CommentResponse struct {
+	ID         int64              `json:"id"`
+	TestCaseID int64              `json:"test_case_id"`
+	StepID     *int64             `json:"step_id,omitempty"`
+	ParentID   *int64             `json:"parent_id,omitempty"`
+	Body       string             `json:"body"`
+	CreatedBy  int64              `json:"created_by"`
+	IsResolved bool               `json:"is_resolved"`
+	ResolvedBy *int64             `json:"resolved_by,omitempty"`
+	ResolvedAt *time.Time         `json:"resolved_at,omitempty"`
+	EditedAt   *time.Time         `json:"edited_at,omitempty"`
+	CreatedAt  time.Time          `json:"created_at"`
+	Mentions   []*UserSummary     `json:"mentions"`
+	Replies    []*CommentResponse `json:"replies,omitempty"`
+}
+
+// ToResponse converts a Comment to CommentResponse
+func (c *Comment) ToResponse() *CommentResponse {
+	response := &CommentResponse{
+		ID:         c.ID,
+		TestCaseID: c.TestCaseID,
+		StepID:     c.StepID,
+		ParentID:   c.ParentID,
+		Body:       c.Body,
+		CreatedBy:  c.CreatedBy,
+		IsResolved: c.IsResolved,
+		ResolvedBy: c.ResolvedBy,
+		ResolvedAt: c.ResolvedAt,
+		EditedAt:   c.EditedAt,
+		CreatedAt:  c.CreatedAt,
+		Mentions:   c.Mentions,
+	}
+
+	if response.Mentions == nil {
+		response.Mentions = []*UserSummary{}
+	}
+
+	if len(c.Replies) > 0 {
+		response.Replies = make([]*CommentResponse, len(c.Replies))
+		for i, reply := range c.Replies {
+			response.Replies[i] = reply.ToResponse()
+		}
+	}
+
+	return response
+}
@@ -0,0 +1,73 @@
+package models
+
+import (
+	"time"
+)
+
+// CustomFieldType represents the kind of value a custom field holds
+type CustomFieldType string
+
+const (
+	CustomFieldText        CustomFieldType = "text"
+	CustomFieldNumber      CustomFieldType = "number"
+	CustomFieldEnum        CustomFieldType = "enum"
+	CustomFieldMultiSelect CustomFieldType = "multi_select"
+	CustomFieldUser        CustomFieldType = "user"
+	CustomFieldDate        CustomFieldType = "date"
+	CustomFieldBoolean     CustomFieldType = "boolean"
+)
+
+// CustomFieldDefinition represents a custom field that the test cases of a project can carry
+type CustomFieldDefinition struct {
+	ID        int64           `json:"id"`
+	ProjectID int64           `json:"project_id"`
+	Name      string          `json:"name"`
+	Label     string          `json:"label"`
+	Type      CustomFieldType `json:"type"`
+	Options   []string        `json:"options,omitempty"`
+	Required  bool            `json:"required"`
+	Position  int             `json:"position"`
+	CreatedBy int64           `json:"created_by"`
+	CreatedAt time.Time       `json:"created_at"`
+	UpdatedAt time.Time       `json:"updated_at"`
+}
+
+// CustomFieldDefinitionCreate represents data needed to define a new custom field
+type CustomFieldDefinitionCreate struct {
+	ProjectID int64           `json:"project_id" binding:"required"`
+	Name      string          `json:"name" binding:"required,min=1,max=50"`
+	Label     string          `json:"label" binding:"required,max=100"`
+	Type      CustomFieldType `json:"type" binding:"required,oneof=text number enum multi_select user date boolean"`
+	Options   []string        `json:"options" binding:"omitempty,dive,required,max=100"`
+	Required  bool            `json:"required"`
+	Position  int             `json:"position"`
+}
+
+// CustomFieldDefinitionUpdate represents data needed to update a custom field.
+// The name and type of a field cannot change once values exist.
+type CustomFieldDefinitionUpdate struct {
+	Label    string   `json:"label" binding:"omitempty,max=100"`
+	Options  []string `json:"options" binding:"omitempty,dive,required,max=100"`
+	Required *bool    `json:"required"`
+	Position *int     `json:"position"`
+}
+
+// TestCaseListOptions represents filtering and sorting of test case lists.
+// CustomFieldFilters maps a custom field name to the value it must match.
+type TestCaseListOptions struct {
+	CustomFieldFilters map[string]string
+	Sort               string
+}
+
+// TestCaseImportResult summarizes a CSV import of test cases
+type TestCaseImportResult struct {
+	Created int                    `json:"created"`
+	Updated int                    `json:"updated"`
+	Errors  []*TestCaseImportError `json:"errors"`
+}
+
+// TestCaseImportError describes a CSV row that could not be imported
+type TestCaseImportError struct {
+	Row   int    `json:"row"`
+	Error string `json:"error"`
+}
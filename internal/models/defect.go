@@ -0,0 +1,54 @@
+package models
+
+import (
+	"time"
+)
+
+// DefectSeverity represents how severe a defect is
+type DefectSeverity string
+
+const (
+	DefectSeverityCritical DefectSeverity = "critical"
+	DefectSeverityHigh     DefectSeverity = "high"
+	DefectSeverityMedium   DefectSeverity = "medium"
+	DefectSeverityLow      DefectSeverity = "low"
+)
+
+// DefectStatus represents the state of a defect
+type DefectStatus string
+
+const (
+	DefectStatusOpen       DefectStatus = "open"
+	DefectStatusInProgress DefectStatus = "in_progress"
+	DefectStatusResolved   DefectStatus = "resolved"
+	DefectStatusClosed     DefectStatus = "closed"
+)
+
+// Defect represents an issue found by a test execution
+type Defect struct {
+	ID               int64          `json:"id"`
+	TestExecutionID  int64          `json:"test_execution_id"`
+	TestRunID        int64          `json:"test_run_id"`
+	TestCaseID       int64          `json:"test_case_id"`
+	ProjectID        int64          `json:"project_id"`
+	Title            string         `json:"title"`
+	Description      string         `json:"description"`
+	Severity         DefectSeverity `json:"severity"`
+	Status           DefectStatus   `json:"status"`
+	ReportedBy       int64          `json:"reported_by"`
+	AssignedTo       *int64         `json:"assigned_to,omitempty"`
+	ExternalID       *string        `json:"external_id,omitempty"`
+	ExternalURL      *string        `json:"external_url,omitempty"`
+	ExternalStatus   *string        `json:"external_status,omitempty"`
+	ExternalSyncedAt *time.Time     `json:"external_synced_at,omitempty"`
+	CreatedAt        time.Time      `json:"created_at"`
+	UpdatedAt        time.Time      `json:"updated_at"`
+}
+
+// DefectCreate represents data needed to record a defect found by an execution
+type DefectCreate struct {
+	Title       string         `json:"title" binding:"required,max=200"`
+	Description string         `json:"description" binding:"required"`
+	Severity    DefectSeverity `json:"severity" binding:"omitempty,oneof=critical high medium low"`
+	AssignedTo  *int64         `json:"assigned_to"`
+}
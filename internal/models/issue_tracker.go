@@ -0,0 +1,38 @@
+package models
+
+import "time"
+
+// IssueTrackerProvider names a supported external issue tracker
+type IssueTrackerProvider string
+
+const (
+	IssueTrackerJira   IssueTrackerProvider = "jira"
+	IssueTrackerGitHub IssueTrackerProvider = "github"
+	IssueTrackerGitLab IssueTrackerProvider = "gitlab"
+)
+
+// IssueTracker represents the external issue tracker defects of a project are reported to
+type IssueTracker struct {
+	ProjectID     int64                `json:"project_id"`
+	Provider      IssueTrackerProvider `json:"provider"`
+	BaseURL       string               `json:"base_url"`
+	ProjectKey    string               `json:"project_key"`
+	Username      string               `json:"username,omitempty"`
+	Token         string               `json:"-"`
+	WebhookSecret string               `json:"-"`
+	CreatedBy     *int64               `json:"created_by,omitempty"`
+	CreatedAt     time.Time            `json:"created_at"`
+	UpdatedAt     time.Time            `json:"updated_at"`
+}
+
+// IssueTrackerConfig represents data needed to connect a project to an issue tracker. The
+// project key is the Jira project key, the GitHub owner/repository or the GitLab project
+// path. An empty token or webhook secret keeps the current one.
+type IssueTrackerConfig struct {
+	Provider      IssueTrackerProvider `json:"provider" binding:"required,oneof=jira github gitlab"`
+	BaseURL       string               `json:"base_url" binding:"omitempty,url,max=500"`
+	ProjectKey    string               `json:"project_key" binding:"required,max=255"`
+	Username      string               `json:"username" binding:"max=255"`
+	Token         string               `json:"token" binding:"max=500"`
+	WebhookSecret string               `json:"webhook_secret" binding:"max=255"`
+}
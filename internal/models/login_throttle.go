@@ -0,0 +1,28 @@
+package models
+
+import (
+	"time"
+)
+
+// LoginThrottleScope is what failed logins are counted for
+type LoginThrottleScope string
+
+const (
+	LoginThrottleAccount LoginThrottleScope = "account"
+	LoginThrottleIP      LoginThrottleScope = "ip"
+)
+
+// LoginThrottle represents the recent failed logins of an email address or an IP address
+type LoginThrottle struct {
+	ID            int64              `json:"id"`
+	Scope         LoginThrottleScope `json:"scope"`
+	Subject       string             `json:"subject"`
+	Failures      int                `json:"failures"`
+	LastFailureAt time.Time          `json:"last_failure_at"`
+	LockedUntil   *time.Time         `json:"locked_until,omitempty"`
+}
+
+// IsLocked reports whether the subject is locked out at a time
+func (t *LoginThrottle) IsLocked(at time.Time) bool {
+	return t.LockedUntil != nil && at.Before(*t.LockedUntil)
+}
@@ -0,0 +1,80 @@
+package models
+
+import "time"
+
+// NotificationType represents the kind of change a notification is about
+type NotificationType string
+
+const (
+	// NotificationTestCaseUpdated tells the owner of a test case that someone else edited it
+	NotificationTestCaseUpdated NotificationType = "test_case.updated"
+	// NotificationTestRunAssigned tells a user that a test run was assigned to them
+	NotificationTestRunAssigned NotificationType = "test_run.assigned"
+	// NotificationDefectOpened tells the owner of a test case that a defect was opened against it
+	NotificationDefectOpened NotificationType = "defect.opened"
+)
+
+// NotificationTypes lists every notification type
+var NotificationTypes = []NotificationType{
+	NotificationTestCaseUpdated,
+	NotificationTestRunAssigned,
+	NotificationDefectOpened,
+}
+
+// IsValid reports whether the notification type is known
+func (t NotificationType) IsValid() bool {
+	for _, known := range NotificationTypes {
+		if t == known {
+			return true
+		}
+	}
+	return false
+}
+
+// Notification tells a user about a change to one of their test cases or test runs
+type Notification struct {
+	ID             int64            `json:"id"`
+	UserID         int64            `json:"user_id"`
+	OrganizationID int64            `json:"organization_id"`
+	ProjectID      *int64           `json:"project_id,omitempty"`
+	Type           NotificationType `json:"type"`
+	Title          string           `json:"title"`
+	EntityType     string           `json:"entity_type"`
+	EntityID       int64            `json:"entity_id"`
+	ActorID        *int64           `json:"actor_id,omitempty"`
+	ReadAt         *time.Time       `json:"read_at,omitempty"`
+	EmailedAt      *time.Time       `json:"emailed_at,omitempty"`
+	CreatedAt      time.Time        `json:"created_at"`
+}
+
+// NotificationFilter narrows and pages the notifications of a user, newest first
+type NotificationFilter struct {
+	UnreadOnly bool
+	Limit      int
+	Offset     int
+}
+
+// NotificationList represents a page of notifications with the number of unread ones
+type NotificationList struct {
+	Notifications []*Notification `json:"notifications"`
+	UnreadCount   int             `json:"unread_count"`
+}
+
+// NotificationPreference represents the choices of a user about a type of notification.
+// Notifications the user does not want in the app are not recorded, so they are not
+// emailed either.
+type NotificationPreference struct {
+	Type        NotificationType `json:"type" binding:"required"`
+	InApp       bool             `json:"in_app"`
+	EmailDigest bool             `json:"email_digest"`
+}
+
+// NotificationPreferencesUpdate represents data needed to change notification preferences
+type NotificationPreferencesUpdate struct {
+	Preferences []NotificationPreference `json:"preferences" binding:"required,dive"`
+}
+
+// TestRunAssign represents data needed to assign a test run; a null user unassigns it
+type TestRunAssign struct {
+	AssignedTo *int64 `json:"assigned_to"`
+}
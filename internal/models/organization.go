@@ -0,0 +1,63 @@
+package models
+
+import (
+	"time"
+)
+
+// OrganizationRole is the role of a user in an organization
+type OrganizationRole string
+
+const (
+	// OrganizationRoleAdmin manages the members, teams and every project of an organization
+	OrganizationRoleAdmin OrganizationRole = "admin"
+	// OrganizationRoleMember can create projects and be granted access to the projects of
+	// an organization
+	OrganizationRoleMember OrganizationRole = "member"
+)
+
+// OrganizationHeader names the organization a request acts in. Without it, requests act in
+// the first organization of the user.
+const OrganizationHeader = "X-Organization-ID"
+
+// Organization represents a tenant that owns projects, tags and teams
+type Organization struct {
+	ID        int64            `json:"id"`
+	Name      string           `json:"name"`
+	Slug      string           `json:"slug"`
+	CreatedBy *int64           `json:"created_by,omitempty"`
+	Role      OrganizationRole `json:"role,omitempty"`
+	CreatedAt time.Time        `json:"created_at"`
+	UpdatedAt time.Time        `json:"updated_at"`
+}
+
+// OrganizationCreate represents data needed to create an organization
+type OrganizationCreate struct {
+	Name string `json:"name" binding:"required,max=100"`
+	Slug string `json:"slug" binding:"required,max=100"`
+}
+
+// OrganizationUpdate represents data needed to rename an organization
+type OrganizationUpdate struct {
+	Name string `json:"name" binding:"required,max=100"`
+}
+
+// OrganizationMember represents a user in an organization
+type OrganizationMember struct {
+	OrganizationID int64            `json:"organization_id"`
+	UserID         int64            `json:"user_id"`
+	Username       string           `json:"username"`
+	Email          string           `json:"email"`
+	Role           OrganizationRole `json:"role"`
+	CreatedAt      time.Time        `json:"created_at"`
+}
+
+// OrganizationMemberAdd represents data needed to add a user to an organization by email
+type OrganizationMemberAdd struct {
+	Email string           `json:"email" binding:"required,email"`
+	Role  OrganizationRole `json:"role" binding:"required,oneof=admin member"`
+}
+
+// OrganizationMemberUpdate represents data needed to change the role of a member
+type OrganizationMemberUpdate struct {
+	Role OrganizationRole `json:"role" binding:"required,oneof=admin member"`
+}
@@ -6,12 +6,14 @@ import (
 
 // Project represents a project in the system
 type Project struct {
-	ID          int64     `json:"id"`
-	Name        string    `json:"name"`
-	Description string    `json:"description"`
-	OwnerID     int64     `json:"owner_id"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	ID             int64      `json:"id"`
+	OrganizationID int64      `json:"organization_id"`
+	Name           string     `json:"name"`
+	Description    string     `json:"description"`
+	OwnerID        int64      `json:"owner_id"`
+	ArchivedAt     *time.Time `json:"archived_at"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
 }
 
 // ProjectCreate represents data needed to create a new project
@@ -26,24 +28,33 @@ type ProjectUpdate struct {
 	Description string `json:"description" binding:"max=500"`
 }
 
+// ProjectOwnershipTransfer represents data needed to make another user the owner of a project
+type ProjectOwnershipTransfer struct {
+	UserID int64 `json:"user_id" binding:"required"`
+}
+
 // ProjectResponse represents the project data to be returned in API responses
 type ProjectResponse struct {
-	ID          int64     `json:"id"`
-	Name        string    `json:"name"`
-	Description string    `json:"description"`
-	OwnerID     int64     `json:"owner_id"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	ID             int64      `json:"id"`
+	OrganizationID int64      `json:"organization_id"`
+	Name           string     `json:"name"`
+	Description    string     `json:"description"`
+	OwnerID        int64      `json:"owner_id"`
+	ArchivedAt     *time.Time `json:"archived_at"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
 }
 
 // ToResponse converts a Project to ProjectResponse
 func (p *Project) ToResponse() ProjectResponse {
 	return ProjectResponse{
-		ID:          p.ID,
-		Name:        p.Name,
-		Description: p.Description,
-		OwnerID:     p.OwnerID,
-		CreatedAt:   p.CreatedAt,
-		UpdatedAt:   p.UpdatedAt,
+		ID:             p.ID,
+		OrganizationID: p.OrganizationID,
+		Name:           p.Name,
+		Description:    p.Description,
+		OwnerID:        p.OwnerID,
+		ArchivedAt:     p.ArchivedAt,
+		CreatedAt:      p.CreatedAt,
+		UpdatedAt:      p.UpdatedAt,
 	}
 }
@@ -4,17 +4,148 @@ import (
 	"time"
 )
 
-// AccessLevel defines the level of access a user has to a project
+// AccessLevel is the role a user has in a project. Each role can do everything the roles
+// below it can do.
 type AccessLevel string
 
 const (
-	// AccessLevelView allows a user to view a project but not modify it
-	AccessLevelView AccessLevel = "view"
+	// AccessLevelViewer allows a user to view a project but not modify it
+	AccessLevelViewer AccessLevel = "viewer"
+
+	// AccessLevelTester also allows a user to execute test runs and record results and defects
+	AccessLevelTester AccessLevel = "tester"
+
+	// AccessLevelEditor also allows a user to edit test suites, test cases and shared steps
+	AccessLevelEditor AccessLevel = "editor"
+
+	// AccessLevelMaintainer also allows a user to manage the access and settings of a project
+	AccessLevelMaintainer AccessLevel = "maintainer"
+
+	// AccessLevelOwner is the role of the project owner, who can also delete the project and
+	// transfer its ownership. It cannot be granted.
+	AccessLevelOwner AccessLevel = "owner"
+
+	// AccessLevelNone is the effective access of a user without any access to a project
+	AccessLevelNone AccessLevel = "none"
 
-	// AccessLevelEdit allows a user to view and edit a project
+	// AccessLevelView and AccessLevelEdit are the former access levels, still accepted as
+	// names for the viewer and editor roles
+	AccessLevelView AccessLevel = "view"
 	AccessLevelEdit AccessLevel = "edit"
 )
 
+// accessLevelRanks orders the roles from least to most privileged
+var accessLevelRanks = map[AccessLevel]int{
+	AccessLevelViewer:     1,
+	AccessLevelTester:     2,
+	AccessLevelEditor:     3,
+	AccessLevelMaintainer: 4,
+	AccessLevelOwner:      5,
+}
+
+// Normalize maps the former access level names to their roles
+func (l AccessLevel) Normalize() AccessLevel {
+	switch l {
+	case AccessLevelView:
+		return AccessLevelViewer
+	case AccessLevelEdit:
+		return AccessLevelEditor
+	}
+	return l
+}
+
+// IsGrantable reports whether a role can be granted to a user or team
+func (l AccessLevel) IsGrantable() bool {
+	switch l.Normalize() {
+	case AccessLevelViewer, AccessLevelTester, AccessLevelEditor, AccessLevelMaintainer:
+		return true
+	}
+	return false
+}
+
+// Includes reports whether an access level allows everything level allows
+func (l AccessLevel) Includes(level AccessLevel) bool {
+	rank, ok := accessLevelRanks[l.Normalize()]
+	return ok && rank >= accessLevelRanks[level.Normalize()]
+}
+
+// Allows reports whether an access level grants a permission
+func (l AccessLevel) Allows(permission Permission) bool {
+	level, ok := permissionLevels[permission]
+	return ok && l.Includes(level)
+}
+
+// Permission is something a user can do in a project
+type Permission string
+
+const (
+	// PermissionViewProject allows reading a project and everything in it
+	PermissionViewProject Permission = "view_project"
+
+	// PermissionExecuteTests allows adding test cases to runs and recording results and defects
+	PermissionExecuteTests Permission = "execute_tests"
+
+	// PermissionEditTests allows changing test suites, test cases, steps, shared steps,
+	// comments and reviews
+	PermissionEditTests Permission = "edit_tests"
+
+	// PermissionManageProject allows changing the project details, access, workflow, custom
+	// fields and review settings, archiving, and reading the audit log
+	PermissionManageProject Permission = "manage_project"
+
+	// PermissionOwnProject allows deleting and restoring the project and transferring its ownership
+	PermissionOwnProject Permission = "own_project"
+)
+
+// permissionLevels maps each permission to the lowest role that has it
+var permissionLevels = map[Permission]AccessLevel{
+	PermissionViewProject:   AccessLevelViewer,
+	PermissionExecuteTests:  AccessLevelTester,
+	PermissionEditTests:     AccessLevelEditor,
+	PermissionManageProject: AccessLevelMaintainer,
+	PermissionOwnProject:    AccessLevelOwner,
+}
+
+// RequiredLevel returns the lowest role that has a permission
+func (p Permission) RequiredLevel() AccessLevel {
+	return permissionLevels[p]
+}
+
+// AccessSourceType describes where the access of a user to a project comes from
+type AccessSourceType string
+
+const (
+	AccessSourceOwner  AccessSourceType = "owner"
+	AccessSourceDirect AccessSourceType = "direct"
+	AccessSourceTeam   AccessSourceType = "team"
+	AccessSourceAdmin  AccessSourceType = "admin"
+)
+
+// AccessSource is one reason a user has access to a project
+type AccessSource struct {
+	Type     AccessSourceType `json:"type"`
+	Level    AccessLevel      `json:"level"`
+	AccessID *int64           `json:"access_id,omitempty"` // the project_access or project_team_access record
+	TeamID   *int64           `json:"team_id,omitempty"`
+	TeamName string           `json:"team_name,omitempty"`
+}
+
+// EffectiveAccess is the highest access level a user has to a project and all the reasons for it
+type EffectiveAccess struct {
+	ProjectID int64          `json:"project_id"`
+	UserID    int64          `json:"user_id"`
+	Level     AccessLevel    `json:"level"`
+	Sources   []AccessSource `json:"sources"`
+}
+
+// AddSource records a reason for access and raises the effective level to it
+func (a *EffectiveAccess) AddSource(source AccessSource) {
+	a.Sources = append(a.Sources, source)
+	if !a.Level.Includes(source.Level) {
+		a.Level = source.Level
+	}
+}
+
 // ProjectAccess represents a user's access to a project
 type ProjectAccess struct {
 	ID        int64       `json:"id"`
@@ -28,12 +159,12 @@ type ProjectAccess struct {
 // ProjectAccessCreate represents data needed to grant access to a project
 type ProjectAccessCreate struct {
 	UserID int64       `json:"user_id" binding:"required"`
-	Level  AccessLevel `json:"level" binding:"required,oneof=view edit"`
+	Level  AccessLevel `json:"level" binding:"required,oneof=viewer tester editor maintainer view edit"`
 }
 
 // ProjectAccessUpdate represents data needed to update project access
 type ProjectAccessUpdate struct {
-	Level AccessLevel `json:"level" binding:"required,oneof=view edit"`
+	Level AccessLevel `json:"level" binding:"required,oneof=viewer tester editor maintainer view edit"`
 }
 
 // ProjectAccessResponse represents the project access data to be returned in API responses
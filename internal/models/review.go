@@ -0,0 +1,52 @@
+package models
+
+import (
+	"time"
+)
+
+// ReviewStatus represents the decision of a reviewer
+type ReviewStatus string
+
+const (
+	ReviewStatusPending          ReviewStatus = "pending"
+	ReviewStatusApproved         ReviewStatus = "approved"
+	ReviewStatusChangesRequested ReviewStatus = "changes_requested"
+)
+
+// ReviewSettings represents the review rules of a project
+type ReviewSettings struct {
+	ProjectID         int64     `json:"project_id"`
+	RequiredApprovals int       `json:"required_approvals"`
+	UpdatedBy         int64     `json:"updated_by,omitempty"`
+	UpdatedAt         time.Time `json:"updated_at"`
+}
+
+// TestCaseReview represents a review of one version of a test case by one reviewer
+type TestCaseReview struct {
+	ID          int64        `json:"id"`
+	TestCaseID  int64        `json:"test_case_id"`
+	Version     int          `json:"version"`
+	ReviewerID  int64        `json:"reviewer_id"`
+	RequestedBy int64        `json:"requested_by"`
+	Status      ReviewStatus `json:"status"`
+	CommentID   *int64       `json:"comment_id,omitempty"`
+	DecidedAt   *time.Time   `json:"decided_at,omitempty"`
+	CreatedAt   time.Time    `json:"created_at"`
+	UpdatedAt   time.Time    `json:"updated_at"`
+}
+
+// ReviewSettingsUpdate represents data needed to change the review rules of a project
+type ReviewSettingsUpdate struct {
+	RequiredApprovals *int `json:"required_approvals" binding:"required,min=0,max=10"`
+}
+
+// ReviewRequest represents data needed to request a review of a test case
+type ReviewRequest struct {
+	ReviewerIDs []int64 `json:"reviewer_ids" binding:"required,min=1,dive,required"`
+}
+
+// ReviewDecision represents a reviewer's decision. A comment is required
+// when requesting changes.
+type ReviewDecision struct {
+	Comment string `json:"comment" binding:"max=10000"`
+}
@@ -0,0 +1,118 @@
+package models
+
+import (
+	"time"
+)
+
+// SharedStep represents a reusable group of steps that test cases can reference
+type SharedStep struct {
+	ID          int64             `json:"id"`
+	ProjectID   int64             `json:"project_id"`
+	Name        string            `json:"name"`
+	Description string            `json:"description"`
+	Version     int               `json:"version"`
+	CreatedBy   int64             `json:"created_by"`
+	UpdatedBy   int64             `json:"updated_by"`
+	CreatedAt   time.Time         `json:"created_at"`
+	UpdatedAt   time.Time         `json:"updated_at"`
+	Items       []*SharedStepItem `json:"items,omitempty"`
+}
+
+// SharedStepItem represents a single step inside a shared step group
+type SharedStepItem struct {
+	ID             int64     `json:"id"`
+	SharedStepID   int64     `json:"shared_step_id"`
+	StepNumber     int       `json:"step_number"`
+	StepType       StepType  `json:"step_type"`
+	Description    string    `json:"description"`
+	ExpectedResult string    `json:"expected_result"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// SharedStepUsage represents a test case that references a shared step
+type SharedStepUsage struct {
+	TestCaseID int64  `json:"test_case_id"`
+	Title      string `json:"title"`
+	SuiteID    int64  `json:"suite_id"`
+	StepID     int64  `json:"step_id"`
+	StepNumber int    `json:"step_number"`
+}
+
+// SharedStepCreate represents data needed to create a new shared step
+type SharedStepCreate struct {
+	ProjectID   int64                   `json:"project_id" binding:"required"`
+	Name        string                  `json:"name" binding:"required,min=3,max=100"`
+	Description string                  `json:"description"`
+	Items       []*SharedStepItemCreate `json:"items" binding:"required,min=1,dive"`
+}
+
+// SharedStepUpdate represents data needed to update a shared step
+type SharedStepUpdate struct {
+	Name        string                  `json:"name" binding:"omitempty,min=3,max=100"`
+	Description string                  `json:"description"`
+	Items       []*SharedStepItemCreate `json:"items" binding:"omitempty,min=1,dive"`
+}
+
+// SharedStepItemCreate represents data needed to create a step inside a shared step group
+type SharedStepItemCreate struct {
+	StepType       StepType `json:"step_type" binding:"required,oneof=given when then and but"`
+	Description    string   `json:"description" binding:"required"`
+	ExpectedResult string   `json:"expected_result"`
+}
+
+// SharedStepResponse represents the shared step data to be returned in API responses
+type SharedStepResponse struct {
+	ID          int64                     `json:"id"`
+	ProjectID   int64                     `json:"project_id"`
+	Name        string                    `json:"name"`
+	Description string                    `json:"description"`
+	Version     int                       `json:"version"`
+	CreatedBy   int64                     `json:"created_by"`
+	UpdatedBy   int64                     `json:"updated_by"`
+	CreatedAt   time.Time                 `json:"created_at"`
+	UpdatedAt   time.Time                 `json:"updated_at"`
+	Items       []*SharedStepItemResponse `json:"items"`
+}
+
+// SharedStepItemResponse represents the shared step item data to be returned in API responses
+type SharedStepItemResponse struct {
+	ID             int64    `json:"id"`
+	StepNumber     int      `json:"step_number"`
+	StepType       StepType `json:"step_type"`
+	Description    string   `json:"description"`
+	ExpectedResult string   `json:"expected_result"`
+}
+
+// ToResponse converts a SharedStep to SharedStepResponse
+func (s *SharedStep) ToResponse() *SharedStepResponse {
+	response := &SharedStepResponse{
+		ID:          s.ID,
+		ProjectID:   s.ProjectID,
+		Name:        s.Name,
+		Description: s.Description,
+		Version:     s.Version,
+		CreatedBy:   s.CreatedBy,
+		UpdatedBy:   s.UpdatedBy,
+		CreatedAt:   s.CreatedAt,
+		UpdatedAt:   s.UpdatedAt,
+		Items:       make([]*SharedStepItemResponse, len(s.Items)),
+	}
+
+	for i, item := range s.Items {
+		response.Items[i] = item.ToResponse()
+	}
+
+	return response
+}
+
+// ToResponse converts a SharedStepItem to SharedStepItemResponse
+func (i *SharedStepItem) ToResponse() *SharedStepItemResponse {
+	return &SharedStepItemResponse{
+		ID:             i.ID,
+		StepNumber:     i.StepNumber,
+		StepType:       i.StepType,
+		Description:    i.Description,
+		ExpectedResult: i.ExpectedResult,
+	}
+}
@@ -6,9 +6,10 @@ import (
 
 // Tag represents a label that can be applied to test cases
 type Tag struct {
-	ID        int64     `json:"id"`
-	Name      string    `json:"name"`
-	CreatedAt time.Time `json:"created_at"`
+	ID             int64     `json:"id"`
+	OrganizationID int64     `json:"organization_id"`
+	Name           string    `json:"name"`
+	CreatedAt      time.Time `json:"created_at"`
 }
 
 // TagCreate represents data needed to create a new tag
@@ -0,0 +1,60 @@
+package models
+
+import (
+	"time"
+)
+
+// Team represents a group of users that can be granted project access as a unit
+type Team struct {
+	ID             int64     `json:"id"`
+	OrganizationID int64     `json:"organization_id"`
+	Name           string    `json:"name"`
+	Description    string    `json:"description"`
+	CreatedBy      *int64    `json:"created_by,omitempty"`
+	MemberCount    int       `json:"member_count"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// TeamCreate represents data needed to create a team
+type TeamCreate struct {
+	Name        string `json:"name" binding:"required,max=100"`
+	Description string `json:"description"`
+}
+
+// TeamUpdate represents data needed to update a team
+type TeamUpdate struct {
+	Name        string `json:"name" binding:"required,max=100"`
+	Description string `json:"description"`
+}
+
+// TeamMember represents a user in a team
+type TeamMember struct {
+	TeamID    int64     `json:"team_id"`
+	UserID    int64     `json:"user_id"`
+	Username  string    `json:"username"`
+	Email     string    `json:"email"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// TeamMemberAdd represents data needed to add users to a team
+type TeamMemberAdd struct {
+	UserIDs []int64 `json:"user_ids" binding:"required,min=1"`
+}
+
+// ProjectTeamAccess represents the access of every member of a team to a project
+type ProjectTeamAccess struct {
+	ID        int64       `json:"id"`
+	ProjectID int64       `json:"project_id"`
+	TeamID    int64       `json:"team_id"`
+	TeamName  string      `json:"team_name"`
+	Level     AccessLevel `json:"level"`
+	CreatedAt time.Time   `json:"created_at"`
+	UpdatedAt time.Time   `json:"updated_at"`
+}
+
+// ProjectTeamAccessCreate represents data needed to grant a team access to a project
+type ProjectTeamAccessCreate struct {
+	TeamID int64       `json:"team_id" binding:"required"`
+	Level  AccessLevel `json:"level" binding:"required,oneof=viewer tester editor maintainer view edit"`
+}
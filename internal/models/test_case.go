@@ -4,16 +4,21 @@ import (
 	"time"
 )
 
-// TestCaseStatus represents the status of a test case
+// TestCaseStatus represents the status of a test case. Projects can configure their own
+// statuses; the constants are the built-in ones, which the review workflow relies on.
 type TestCaseStatus string
 
 const (
 	StatusDraft      TestCaseStatus = "draft"
+	StatusInReview   TestCaseStatus = "in_review"
+	StatusApproved   TestCaseStatus = "approved"
+	StatusRejected   TestCaseStatus = "rejected"
 	StatusActive     TestCaseStatus = "active"
 	StatusDeprecated TestCaseStatus = "deprecated"
 )
 
-// TestCasePriority represents the priority of a test case
+// TestCasePriority represents the priority of a test case. Projects can configure their own
+// priorities; the constants are the built-in ones.
 type TestCasePriority string
 
 const (
@@ -35,27 +40,31 @@ const (
 
 // TestCase represents a test case in the system
 type TestCase struct {
-	ID            int64            `json:"id"`
-	ProjectID     int64            `json:"project_id"`
-	SuiteID       int64            `json:"suite_id"`
-	Title         string           `json:"title"`
-	Description   string           `json:"description"`
-	Preconditions string           `json:"preconditions"`
-	Status        TestCaseStatus   `json:"status"`
-	Priority      TestCasePriority `json:"priority"`
-	CreatedBy     int64            `json:"created_by"`
-	UpdatedBy     int64            `json:"updated_by"`
-	Version       int              `json:"version"`
-	CreatedAt     time.Time        `json:"created_at"`
-	UpdatedAt     time.Time        `json:"updated_at"`
-	Steps         []*TestStep      `json:"steps,omitempty"`
-	Tags          []*Tag           `json:"tags,omitempty"`
+	ID            int64                  `json:"id"`
+	ProjectID     int64                  `json:"project_id"`
+	SuiteID       int64                  `json:"suite_id"`
+	Title         string                 `json:"title"`
+	Description   string                 `json:"description"`
+	Preconditions string                 `json:"preconditions"`
+	Status        TestCaseStatus         `json:"status"`
+	Priority      TestCasePriority       `json:"priority"`
+	CreatedBy     int64                  `json:"created_by"`
+	UpdatedBy     int64                  `json:"updated_by"`
+	Version       int                    `json:"version"`
+	CreatedAt     time.Time              `json:"created_at"`
+	UpdatedAt     time.Time              `json:"updated_at"`
+	Steps         []*TestStep            `json:"steps,omitempty"`
+	Tags          []*Tag                 `json:"tags,omitempty"`
+	Parameters    []string               `json:"parameters,omitempty"`
+	DataRows      []*TestCaseDataRow     `json:"data_rows,omitempty"`
+	CustomFields  map[string]interface{} `json:"custom_fields,omitempty"`
 }
 
 // TestStep represents a step in a test case
 type TestStep struct {
 	ID             int64             `json:"id"`
 	TestCaseID     int64             `json:"test_case_id"`
+	SharedStepID   *int64            `json:"shared_step_id,omitempty"`
 	StepNumber     int               `json:"step_number"`
 	StepType       StepType          `json:"step_type"`
 	Description    string            `json:"description"`
@@ -64,6 +73,7 @@ type TestStep struct {
 	UpdatedAt      time.Time         `json:"updated_at"`
 	Notes          []*StepNote       `json:"notes,omitempty"`
 	Attachments    []*StepAttachment `json:"attachments,omitempty"`
+	SharedStep     *SharedStep       `json:"shared_step,omitempty"`
 }
 
 // StepNote represents a note attached to a test step
@@ -104,33 +114,61 @@ type TestCaseHistory struct {
 
 // TestCaseCreate represents data needed to create a new test case
 type TestCaseCreate struct {
-	ProjectID     int64             `json:"project_id" binding:"required"`
-	SuiteID       int64             `json:"suite_id" binding:"required"`
-	Title         string            `json:"title" binding:"required"`
-	Description   string            `json:"description"`
-	Preconditions string            `json:"preconditions"`
-	Status        TestCaseStatus    `json:"status" binding:"required,oneof=draft active deprecated"`
-	Priority      TestCasePriority  `json:"priority" binding:"required,oneof=low medium high"`
-	Steps         []*TestStepCreate `json:"steps"`
-	Tags          []string          `json:"tags"`
+	ProjectID     int64                  `json:"project_id" binding:"required"`
+	SuiteID       int64                  `json:"suite_id" binding:"required"`
+	Title         string                 `json:"title" binding:"required"`
+	Description   string                 `json:"description"`
+	Preconditions string                 `json:"preconditions"`
+	Status        TestCaseStatus         `json:"status" binding:"omitempty,max=50"`
+	Priority      TestCasePriority       `json:"priority" binding:"omitempty,max=50"`
+	Steps         []*TestStepCreate      `json:"steps"`
+	Tags          []string               `json:"tags"`
+	CustomFields  map[string]interface{} `json:"custom_fields"`
 }
 
-// TestStepCreate represents data needed to create a new test step
+// TestStepCreate represents data needed to create a new test step.
+// A step either carries its own description or references a shared step.
 type TestStepCreate struct {
 	StepType       StepType `json:"step_type" binding:"required,oneof=given when then and but"`
-	Description    string   `json:"description" binding:"required"`
+	Description    string   `json:"description" binding:"required_without=SharedStepID"`
 	ExpectedResult string   `json:"expected_result"`
+	SharedStepID   *int64   `json:"shared_step_id"`
+}
+
+// TestStepAdd represents data needed to add a step to an existing test case.
+// Without a position the step is appended.
+type TestStepAdd struct {
+	TestStepCreate
+	Position *int `json:"position" binding:"omitempty,min=1"`
+}
+
+// TestStepMove represents data needed to move a step to another position
+type TestStepMove struct {
+	Position int `json:"position" binding:"required,min=1"`
+}
+
+// TestStepOrder represents the complete new order of the steps of a test case
+type TestStepOrder struct {
+	StepIDs []int64 `json:"step_ids" binding:"required,min=1"`
 }
 
 // TestCaseUpdate represents data needed to update a test case
 type TestCaseUpdate struct {
-	Title         string            `json:"title"`
-	Description   string            `json:"description"`
-	Preconditions string            `json:"preconditions"`
-	Status        TestCaseStatus    `json:"status" binding:"omitempty,oneof=draft active deprecated"`
-	Priority      TestCasePriority  `json:"priority" binding:"omitempty,oneof=low medium high"`
-	Steps         []*TestStepCreate `json:"steps"`
-	Tags          []string          `json:"tags"`
+	Title         string                 `json:"title"`
+	Description   string                 `json:"description"`
+	Preconditions string                 `json:"preconditions"`
+	Status        TestCaseStatus         `json:"status" binding:"omitempty,max=50"`
+	Priority      TestCasePriority       `json:"priority" binding:"omitempty,max=50"`
+	Steps         []*TestStepUpsert      `json:"steps"`
+	Tags          []string               `json:"tags"`
+	CustomFields  map[string]interface{} `json:"custom_fields"`
+}
+
+// TestStepUpsert represents a step in a test case update. Steps that carry the ID
+// of an existing step are updated in place; steps without an ID are created.
+type TestStepUpsert struct {
+	ID *int64 `json:"id"`
+	TestStepCreate
 }
 
 // StepNoteCreate represents data needed to create a new step note
@@ -140,26 +178,30 @@ type StepNoteCreate struct {
 
 // TestCaseResponse represents the test case data to be returned in API responses
 type TestCaseResponse struct {
-	ID            int64               `json:"id"`
-	ProjectID     int64               `json:"project_id"`
-	SuiteID       int64               `json:"suite_id"`
-	Title         string              `json:"title"`
-	Description   string              `json:"description"`
-	Preconditions string              `json:"preconditions"`
-	Status        TestCaseStatus      `json:"status"`
-	Priority      TestCasePriority    `json:"priority"`
-	CreatedBy     int64               `json:"created_by"`
-	UpdatedBy     int64               `json:"updated_by"`
-	Version       int                 `json:"version"`
-	CreatedAt     time.Time           `json:"created_at"`
-	UpdatedAt     time.Time           `json:"updated_at"`
-	Steps         []*TestStepResponse `json:"steps,omitempty"`
-	Tags          []*TagResponse      `json:"tags,omitempty"`
+	ID            int64                      `json:"id"`
+	ProjectID     int64                      `json:"project_id"`
+	SuiteID       int64                      `json:"suite_id"`
+	Title         string                     `json:"title"`
+	Description   string                     `json:"description"`
+	Preconditions string                     `json:"preconditions"`
+	Status        TestCaseStatus             `json:"status"`
+	Priority      TestCasePriority           `json:"priority"`
+	CreatedBy     int64                      `json:"created_by"`
+	UpdatedBy     int64                      `json:"updated_by"`
+	Version       int                        `json:"version"`
+	CreatedAt     time.Time                  `json:"created_at"`
+	UpdatedAt     time.Time                  `json:"updated_at"`
+	Steps         []*TestStepResponse        `json:"steps,omitempty"`
+	Tags          []*TagResponse             `json:"tags,omitempty"`
+	Parameters    []string                   `json:"parameters,omitempty"`
+	DataRows      []*TestCaseDataRowResponse `json:"data_rows,omitempty"`
+	CustomFields  map[string]interface{}     `json:"custom_fields"`
 }
 
 // TestStepResponse represents the test step data to be returned in API responses
 type TestStepResponse struct {
 	ID             int64                     `json:"id"`
+	SharedStepID   *int64                    `json:"shared_step_id,omitempty"`
 	StepNumber     int                       `json:"step_number"`
 	StepType       StepType                  `json:"step_type"`
 	Description    string                    `json:"description"`
@@ -168,6 +210,7 @@ type TestStepResponse struct {
 	UpdatedAt      time.Time                 `json:"updated_at"`
 	Notes          []*StepNoteResponse       `json:"notes,omitempty"`
 	Attachments    []*StepAttachmentResponse `json:"attachments,omitempty"`
+	SharedStep     *SharedStepResponse       `json:"shared_step,omitempty"`
 }
 
 // StepNoteResponse represents the step note data to be returned in API responses
@@ -220,6 +263,20 @@ func (tc *TestCase) ToResponse() *TestCaseResponse {
 		}
 	}
 
+	response.Parameters = tc.Parameters
+
+	response.CustomFields = tc.CustomFields
+	if response.CustomFields == nil {
+		response.CustomFields = map[string]interface{}{}
+	}
+
+	if tc.DataRows != nil {
+		response.DataRows = make([]*TestCaseDataRowResponse, len(tc.DataRows))
+		for i, row := range tc.DataRows {
+			response.DataRows[i] = row.ToResponse()
+		}
+	}
+
 	return response
 }
 
@@ -227,6 +284,7 @@ func (tc *TestCase) ToResponse() *TestCaseResponse {
 func (ts *TestStep) ToResponse() *TestStepResponse {
 	response := &TestStepResponse{
 		ID:             ts.ID,
+		SharedStepID:   ts.SharedStepID,
 		StepNumber:     ts.StepNumber,
 		StepType:       ts.StepType,
 		Description:    ts.Description,
@@ -249,6 +307,10 @@ func (ts *TestStep) ToResponse() *TestStepResponse {
 		}
 	}
 
+	if ts.SharedStep != nil {
+		response.SharedStep = ts.SharedStep.ToResponse()
+	}
+
 	return response
 }
 
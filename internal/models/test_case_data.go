@@ -0,0 +1,83 @@
+package models
+
+import (
+	"time"
+)
+
+// TestCaseDataRow represents one row of parameter values for a parameterized test case
+type TestCaseDataRow struct {
+	ID         int64             `json:"id"`
+	TestCaseID int64             `json:"test_case_id"`
+	RowNumber  int               `json:"row_number"`
+	Name       string            `json:"name"`
+	Values     map[string]string `json:"values"`
+	CreatedAt  time.Time         `json:"created_at"`
+	UpdatedAt  time.Time         `json:"updated_at"`
+}
+
+// TestScenario represents a test case expanded with the values of one data row
+type TestScenario struct {
+	TestCaseID int64             `json:"test_case_id"`
+	DataRowID  *int64            `json:"data_row_id,omitempty"`
+	RowNumber  int               `json:"row_number,omitempty"`
+	Name       string            `json:"name,omitempty"`
+	Values     map[string]string `json:"values,omitempty"`
+	Steps      []*TestStep       `json:"steps"`
+}
+
+// TestCaseDataRowCreate represents data needed to create a data row
+type TestCaseDataRowCreate struct {
+	Name   string            `json:"name" binding:"max=100"`
+	Values map[string]string `json:"values" binding:"required"`
+}
+
+// TestCaseDataRowsUpdate represents data needed to replace the data table of a test case
+type TestCaseDataRowsUpdate struct {
+	Rows []*TestCaseDataRowCreate `json:"rows" binding:"dive"`
+}
+
+// TestCaseDataRowResponse represents the data row data to be returned in API responses
+type TestCaseDataRowResponse struct {
+	ID        int64             `json:"id"`
+	RowNumber int               `json:"row_number"`
+	Name      string            `json:"name"`
+	Values    map[string]string `json:"values"`
+}
+
+// TestScenarioResponse represents the expanded scenario data to be returned in API responses
+type TestScenarioResponse struct {
+	TestCaseID int64               `json:"test_case_id"`
+	DataRowID  *int64              `json:"data_row_id,omitempty"`
+	RowNumber  int                 `json:"row_number,omitempty"`
+	Name       string              `json:"name,omitempty"`
+	Values     map[string]string   `json:"values,omitempty"`
+	Steps      []*TestStepResponse `json:"steps"`
+}
+
+// ToResponse converts a TestCaseDataRow to TestCaseDataRowResponse
+func (r *TestCaseDataRow) ToResponse() *TestCaseDataRowResponse {
+	return &TestCaseDataRowResponse{
+		ID:        r.ID,
+		RowNumber: r.RowNumber,
+		Name:      r.Name,
+		Values:    r.Values,
+	}
+}
+
+// ToResponse converts a TestScenario to TestScenarioResponse
+func (s *TestScenario) ToResponse() *TestScenarioResponse {
+	response := &TestScenarioResponse{
+		TestCaseID: s.TestCaseID,
+		DataRowID:  s.DataRowID,
+		RowNumber:  s.RowNumber,
+		Name:       s.Name,
+		Values:     s.Values,
+		Steps:      make([]*TestStepResponse, len(s.Steps)),
+	}
+
+	for i, step := range s.Steps {
+		response.Steps[i] = step.ToResponse()
+	}
+
+	return response
+}
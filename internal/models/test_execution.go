@@ -0,0 +1,108 @@
+package models
+
+import (
+	"time"
+)
+
+// ExecutionStatus represents the result status of a test execution
+type ExecutionStatus string
+
+const (
+	ExecutionStatusPending ExecutionStatus = "pending"
+	ExecutionStatusPassed  ExecutionStatus = "passed"
+	ExecutionStatusFailed  ExecutionStatus = "failed"
+	ExecutionStatusBlocked ExecutionStatus = "blocked"
+	ExecutionStatusSkipped ExecutionStatus = "skipped"
+)
+
+// TestRunStatus represents the state of a test run
+type TestRunStatus string
+
+const (
+	TestRunStatusPlanned    TestRunStatus = "planned"
+	TestRunStatusInProgress TestRunStatus = "in_progress"
+	TestRunStatusCompleted  TestRunStatus = "completed"
+	TestRunStatusAborted    TestRunStatus = "aborted"
+)
+
+// TestRun represents a test run with the number of its executions per status
+type TestRun struct {
+	ID          int64                   `json:"id"`
+	ProjectID   int64                   `json:"project_id"`
+	Name        string                  `json:"name"`
+	Status      TestRunStatus           `json:"status"`
+	StartedAt   *time.Time              `json:"started_at,omitempty"`
+	CompletedAt *time.Time              `json:"completed_at,omitempty"`
+	AssignedTo  *int64                  `json:"assigned_to,omitempty"`
+	Results     map[ExecutionStatus]int `json:"results"`
+}
+
+// TestExecution represents the execution of a test case, or of one of its data rows, in a test run
+type TestExecution struct {
+	ID            int64             `json:"id"`
+	TestRunID     int64             `json:"test_run_id"`
+	TestCaseID    int64             `json:"test_case_id"`
+	DataRowID     *int64            `json:"data_row_id,omitempty"`
+	Parameters    map[string]string `json:"parameters,omitempty"`
+	Status        ExecutionStatus   `json:"status"`
+	ExecutedBy    *int64            `json:"executed_by,omitempty"`
+	ExecutionTime *int              `json:"execution_time,omitempty"`
+	Notes         string            `json:"notes"`
+	ExecutedAt    *time.Time        `json:"executed_at,omitempty"`
+	CreatedAt     time.Time         `json:"created_at"`
+	UpdatedAt     time.Time         `json:"updated_at"`
+	Scenario      *TestScenario     `json:"scenario,omitempty"`
+}
+
+// TestRunCaseAdd represents data needed to add a test case to a test run
+type TestRunCaseAdd struct {
+	TestCaseID int64 `json:"test_case_id" binding:"required"`
+}
+
+// TestExecutionResult represents data needed to record the result of a test execution
+type TestExecutionResult struct {
+	Status        ExecutionStatus `json:"status" binding:"required,oneof=passed failed blocked skipped"`
+	ExecutionTime *int            `json:"execution_time" binding:"omitempty,min=0"`
+	Notes         string          `json:"notes"`
+}
+
+// TestExecutionResponse represents the test execution data to be returned in API responses
+type TestExecutionResponse struct {
+	ID            int64                 `json:"id"`
+	TestRunID     int64                 `json:"test_run_id"`
+	TestCaseID    int64                 `json:"test_case_id"`
+	DataRowID     *int64                `json:"data_row_id,omitempty"`
+	Parameters    map[string]string     `json:"parameters,omitempty"`
+	Status        ExecutionStatus       `json:"status"`
+	ExecutedBy    *int64                `json:"executed_by,omitempty"`
+	ExecutionTime *int                  `json:"execution_time,omitempty"`
+	Notes         string                `json:"notes"`
+	ExecutedAt    *time.Time            `json:"executed_at,omitempty"`
+	CreatedAt     time.Time             `json:"created_at"`
+	UpdatedAt     time.Time             `json:"updated_at"`
+	Scenario      *TestScenarioResponse `json:"scenario,omitempty"`
+}
+
+// ToResponse converts a TestExecution to TestExecutionResponse
+func (e *TestExecution) ToResponse() *TestExecutionResponse {
+	response := &TestExecutionResponse{
+		ID:            e.ID,
+		TestRunID:     e.TestRunID,
+		TestCaseID:    e.TestCaseID,
+		DataRowID:     e.DataRowID,
+		Parameters:    e.Parameters,
+		Status:        e.Status,
+		ExecutedBy:    e.ExecutedBy,
+		ExecutionTime: e.ExecutionTime,
+		Notes:         e.Notes,
+		ExecutedAt:    e.ExecutedAt,
+		CreatedAt:     e.CreatedAt,
+		UpdatedAt:     e.UpdatedAt,
+	}
+
+	if e.Scenario != nil {
+		response.Scenario = e.Scenario.ToResponse()
+	}
+
+	return response
+}
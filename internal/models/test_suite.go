@@ -6,12 +6,13 @@ import (
 
 // TestSuite represents a collection of test cases
 type TestSuite struct {
-	ID          int64     `json:"id"`
-	ProjectID   int64     `json:"project_id"`
-	Name        string    `json:"name"`
-	Description string    `json:"description"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	ID          int64      `json:"id"`
+	ProjectID   int64      `json:"project_id"`
+	Name        string     `json:"name"`
+	Description string     `json:"description"`
+	ArchivedAt  *time.Time `json:"archived_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
 }
 
 // TestSuiteCreate represents data needed to create a new test suite
@@ -29,12 +30,13 @@ type TestSuiteUpdate struct {
 
 // TestSuiteResponse represents the test suite data to be returned in API responses
 type TestSuiteResponse struct {
-	ID          int64     `json:"id"`
-	ProjectID   int64     `json:"project_id"`
-	Name        string    `json:"name"`
-	Description string    `json:"description"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	ID          int64      `json:"id"`
+	ProjectID   int64      `json:"project_id"`
+	Name        string     `json:"name"`
+	Description string     `json:"description"`
+	ArchivedAt  *time.Time `json:"archived_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
 }
 
 // ToResponse converts a TestSuite to TestSuiteResponse
@@ -44,6 +46,7 @@ func (ts *TestSuite) ToResponse() TestSuiteResponse {
 		ProjectID:   ts.ProjectID,
 		Name:        ts.Name,
 		Description: ts.Description,
+		ArchivedAt:  ts.ArchivedAt,
 		CreatedAt:   ts.CreatedAt,
 		UpdatedAt:   ts.UpdatedAt,
 	}
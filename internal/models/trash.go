@@ -0,0 +1,31 @@
+package models
+
+import (
+	"time"
+)
+
+// Entity types that can be moved to the trash
+const (
+	TrashProject   = "project"
+	TrashTestSuite = "test_suite"
+	TrashTestCase  = "test_case"
+)
+
+// TrashItem represents a deleted project, test suite or test case that can still be restored
+type TrashItem struct {
+	EntityType string    `json:"entity_type"`
+	ID         int64     `json:"id"`
+	ProjectID  int64     `json:"project_id"`
+	SuiteID    int64     `json:"suite_id,omitempty"`
+	OwnerID    int64     `json:"owner_id,omitempty"`
+	Name       string    `json:"name"`
+	DeletedAt  time.Time `json:"deleted_at"`
+	PurgeAt    time.Time `json:"purge_at"`
+}
+
+// PurgeResult reports how many trashed rows a purge removed permanently
+type PurgeResult struct {
+	Projects   int64 `json:"projects"`
+	TestSuites int64 `json:"test_suites"`
+	TestCases  int64 `json:"test_cases"`
+}
@@ -0,0 +1,62 @@
+package models
+
+import (
+	"time"
+)
+
+// TwoFactor represents the TOTP enrollment of a user
+type TwoFactor struct {
+	UserID          int64      `json:"user_id"`
+	SecretEncrypted string     `json:"-"`
+	EnabledAt       *time.Time `json:"enabled_at,omitempty"`
+	LastUsedStep    int64      `json:"-"` // latest TOTP time step that was accepted
+	CreatedAt       time.Time  `json:"created_at"`
+}
+
+// IsEnabled reports whether the user confirmed the enrollment
+func (t *TwoFactor) IsEnabled() bool {
+	return t.EnabledAt != nil
+}
+
+// TwoFactorStatus represents the two-factor authentication state of the current user
+type TwoFactorStatus struct {
+	Enabled                bool `json:"enabled"`
+	Required               bool `json:"required"`
+	RecoveryCodesRemaining int  `json:"recovery_codes_remaining"`
+}
+
+// TwoFactorSetupResponse represents a new TOTP secret. ProvisioningURI is the otpauth://
+// URI that authenticator apps read from a QR code.
+type TwoFactorSetupResponse struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"`
+}
+
+// TwoFactorCodeRequest represents a request confirmed with a TOTP or recovery code
+type TwoFactorCodeRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+// TwoFactorDisableRequest represents data needed to turn off two-factor authentication
+type TwoFactorDisableRequest struct {
+	Password string `json:"password" binding:"required"`
+	Code     string `json:"code" binding:"required"`
+}
+
+// TwoFactorChallenge is returned by a password login of a user with two-factor
+// authentication instead of tokens
+type TwoFactorChallenge struct {
+	ChallengeToken string    `json:"challenge_token"`
+	ExpiresAt      time.Time `json:"expires_at"`
+}
+
+// TwoFactorVerifyRequest represents data needed to complete a login with a second factor
+type TwoFactorVerifyRequest struct {
+	ChallengeToken string `json:"challenge_token" binding:"required"`
+	Code           string `json:"code" binding:"required"`
+}
+
+// RecoveryCodesResponse represents newly generated recovery codes, which are only shown once
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
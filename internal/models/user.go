@@ -13,23 +13,44 @@ const (
 	RoleTester Role = "tester"
 )
 
+// User statuses that users can be filtered by
+const (
+	UserStatusActive      = "active"
+	UserStatusDeactivated = "deactivated"
+)
+
 // User represents a user in the system
 type User struct {
-	ID           int64     `json:"id"`
-	Username     string    `json:"username"`
-	Email        string    `json:"email"`
-	PasswordHash string    `json:"-"` // Never expose password hash
-	Role         Role      `json:"role"`
-	CreatedAt    time.Time `json:"created_at"`
-	UpdatedAt    time.Time `json:"updated_at"`
+	ID                    int64      `json:"id"`
+	Username              string     `json:"username"`
+	Email                 string     `json:"email"`
+	PasswordHash          string     `json:"-"` // Never expose password hash
+	Role                  Role       `json:"role"`
+	EmailVerifiedAt       *time.Time `json:"email_verified_at,omitempty"`
+	DeactivatedAt         *time.Time `json:"deactivated_at,omitempty"`
+	PasswordResetRequired bool       `json:"password_reset_required"`
+	OIDCIssuer            *string    `json:"-"` // issuer of the single sign-on identity the user logs in with
+	OIDCSubject           *string    `json:"-"` // subject of that identity at the issuer
+	CreatedAt             time.Time  `json:"created_at"`
+	UpdatedAt             time.Time  `json:"updated_at"`
+}
+
+// IsEmailVerified reports whether the user has verified its email address
+func (u *User) IsEmailVerified() bool {
+	return u.EmailVerifiedAt != nil
 }
 
-// UserCreate represents data needed to create a new user
+// IsDeactivated reports whether the user has been deactivated
+func (u *User) IsDeactivated() bool {
+	return u.DeactivatedAt != nil
+}
+
+// UserCreate represents data needed to register a new user. Registered users always get
+// the user role; only admins can grant other roles.
 type UserCreate struct {
 	Username string `json:"username" binding:"required,min=3,max=50"`
 	Email    string `json:"email" binding:"required,email"`
 	Password string `json:"password" binding:"required,min=8"`
-	Role     Role   `json:"role" binding:"omitempty,oneof=admin user tester"`
 }
 
 // UserLogin represents data needed for user login
@@ -38,22 +59,55 @@ type UserLogin struct {
 	Password string `json:"password" binding:"required"`
 }
 
+// UserProfileUpdate represents the profile fields users can change themselves
+type UserProfileUpdate struct {
+	Username string `json:"username" binding:"required,min=3,max=50"`
+	Email    string `json:"email" binding:"required,email"`
+}
+
+// PasswordChange represents data needed to change the password of the current user
+type PasswordChange struct {
+	CurrentPassword string `json:"current_password" binding:"required"`
+	NewPassword     string `json:"new_password" binding:"required,min=8"`
+}
+
+// UserRoleUpdate represents data needed to change the role of a user
+type UserRoleUpdate struct {
+	Role Role `json:"role" binding:"required,oneof=admin user tester"`
+}
+
+// UserFilter represents the criteria of a user query.
+// Zero values do not filter.
+type UserFilter struct {
+	Search string
+	Role   Role
+	Status string
+	Limit  int
+	Offset int
+}
+
 // UserResponse represents the user data to be returned in API responses
 type UserResponse struct {
-	ID        int64     `json:"id"`
-	Username  string    `json:"username"`
-	Email     string    `json:"email"`
-	Role      Role      `json:"role"`
-	CreatedAt time.Time `json:"created_at"`
+	ID                    int64      `json:"id"`
+	Username              string     `json:"username"`
+	Email                 string     `json:"email"`
+	Role                  Role       `json:"role"`
+	EmailVerified         bool       `json:"email_verified"`
+	DeactivatedAt         *time.Time `json:"deactivated_at,omitempty"`
+	PasswordResetRequired bool       `json:"password_reset_required"`
+	CreatedAt             time.Time  `json:"created_at"`
 }
 
 // ToResponse converts a User to UserResponse
 func (u *User) ToResponse() UserResponse {
 	return UserResponse{
-		ID:        u.ID,
-		Username:  u.Username,
-		Email:     u.Email,
-		Role:      u.Role,
-		CreatedAt: u.CreatedAt,
+		ID:                    u.ID,
+		Username:              u.Username,
+		Email:                 u.Email,
+		Role:                  u.Role,
+		EmailVerified:         u.IsEmailVerified(),
+		DeactivatedAt:         u.DeactivatedAt,
+		PasswordResetRequired: u.PasswordResetRequired,
+		CreatedAt:             u.CreatedAt,
 	}
 }
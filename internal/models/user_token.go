@@ -0,0 +1,41 @@
+package models
+
+import (
+	"time"
+)
+
+// UserTokenPurpose is what a user token can be used for
+type UserTokenPurpose string
+
+const (
+	UserTokenEmailVerification UserTokenPurpose = "email_verification"
+	UserTokenPasswordReset     UserTokenPurpose = "password_reset"
+)
+
+// UserToken represents a single-use token sent to a user by email. Only its hash is stored.
+type UserToken struct {
+	ID        int64            `json:"id"`
+	UserID    int64            `json:"user_id"`
+	Purpose   UserTokenPurpose `json:"purpose"`
+	TokenHash string           `json:"-"`
+	Email     string           `json:"email"`
+	ExpiresAt time.Time        `json:"expires_at"`
+	UsedAt    *time.Time       `json:"used_at,omitempty"`
+	CreatedAt time.Time        `json:"created_at"`
+}
+
+// EmailVerificationRequest represents data needed to verify an email address
+type EmailVerificationRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+// ForgotPasswordRequest represents data needed to request a password reset email
+type ForgotPasswordRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+// PasswordResetRequest represents data needed to reset a password with a token from a reset email
+type PasswordResetRequest struct {
+	Token       string `json:"token" binding:"required"`
+	NewPassword string `json:"new_password" binding:"required,min=8"`
+}
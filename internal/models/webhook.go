@@ -0,0 +1,109 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Event types webhooks can subscribe to
+const (
+	WebhookEventTestCaseCreated     = "test_case.created"
+	WebhookEventTestCaseUpdated     = "test_case.updated"
+	WebhookEventTestCaseDeleted     = "test_case.deleted"
+	WebhookEventTestRunStarted      = "test_run.started"
+	WebhookEventTestRunCompleted    = "test_run.completed"
+	WebhookEventTestExecutionFailed = "test_execution.failed"
+	WebhookEventDefectOpened        = "defect.opened"
+)
+
+// Headers of webhook requests
+const (
+	WebhookEventHeader     = "X-Webhook-Event"
+	WebhookDeliveryHeader  = "X-Webhook-Delivery"
+	WebhookTimestampHeader = "X-Webhook-Timestamp"
+	WebhookSignatureHeader = "X-Webhook-Signature"
+)
+
+// Webhook represents a subscription of an external URL to events of a project
+type Webhook struct {
+	ID        int64     `json:"id"`
+	ProjectID int64     `json:"project_id"`
+	Name      string    `json:"name"`
+	URL       string    `json:"url"`
+	Secret    string    `json:"-"`
+	Events    []string  `json:"events"`
+	Active    bool      `json:"active"`
+	CreatedBy *int64    `json:"created_by,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Subscribes reports whether the webhook is sent events of a type
+func (w *Webhook) Subscribes(eventType string) bool {
+	for _, e := range w.Events {
+		if e == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookCreate represents data needed to create a webhook
+type WebhookCreate struct {
+	ProjectID int64    `json:"project_id" binding:"required"`
+	Name      string   `json:"name" binding:"required,max=100"`
+	URL       string   `json:"url" binding:"required,url,max=500"`
+	Events    []string `json:"events" binding:"required,min=1,dive,oneof=test_case.created test_case.updated test_case.deleted test_run.started test_run.completed test_execution.failed defect.opened"`
+	Active    *bool    `json:"active"`
+}
+
+// WebhookUpdate represents data needed to update a webhook
+type WebhookUpdate struct {
+	Name   string   `json:"name" binding:"required,max=100"`
+	URL    string   `json:"url" binding:"required,url,max=500"`
+	Events []string `json:"events" binding:"required,min=1,dive,oneof=test_case.created test_case.updated test_case.deleted test_run.started test_run.completed test_execution.failed defect.opened"`
+	Active bool     `json:"active"`
+}
+
+// WebhookCreatedResponse represents a newly created webhook. The secret its payloads are
+// signed with is only returned here.
+type WebhookCreatedResponse struct {
+	*Webhook
+	Secret string `json:"secret"`
+}
+
+// WebhookDeliveryStatus represents the state of a webhook delivery
+type WebhookDeliveryStatus string
+
+const (
+	WebhookDeliveryPending   WebhookDeliveryStatus = "pending"
+	WebhookDeliverySucceeded WebhookDeliveryStatus = "succeeded"
+	WebhookDeliveryFailed    WebhookDeliveryStatus = "failed"
+)
+
+// WebhookDelivery represents an event sent to a webhook, with the outcome of its latest attempt
+type WebhookDelivery struct {
+	ID             int64                 `json:"id"`
+	WebhookID      int64                 `json:"webhook_id"`
+	EventType      string                `json:"event_type"`
+	Payload        json.RawMessage       `json:"payload"`
+	Status         WebhookDeliveryStatus `json:"status"`
+	Attempts       int                   `json:"attempts"`
+	NextAttemptAt  *time.Time            `json:"next_attempt_at,omitempty"`
+	ResponseStatus *int                  `json:"response_status,omitempty"`
+	ResponseBody   string                `json:"response_body,omitempty"`
+	Error          string                `json:"error,omitempty"`
+	RedeliveryOf   *int64                `json:"redelivery_of,omitempty"`
+	CreatedAt      time.Time             `json:"created_at"`
+	DeliveredAt    *time.Time            `json:"delivered_at,omitempty"`
+}
+
+// WebhookPayload is the JSON body of a webhook request
+type WebhookPayload struct {
+	Event      string      `json:"event"`
+	ProjectID  int64       `json:"project_id"`
+	TestRunID  int64       `json:"test_run_id,omitempty"`
+	ActorID    int64       `json:"actor_id,omitempty"`
+	OccurredAt time.Time   `json:"occurred_at"`
+	Data       interface{} `json:"data"`
+}
@@ -0,0 +1,114 @@
+package models
+
+// WorkflowStatus represents a test case status available in a project
+type WorkflowStatus struct {
+	Name     string `json:"name" binding:"required,max=50"`
+	Label    string `json:"label" binding:"required,max=100"`
+	Color    string `json:"color" binding:"omitempty,hexcolor"`
+	Position int    `json:"position"`
+	Terminal bool   `json:"terminal"`
+}
+
+// WorkflowPriority represents a test case priority available in a project
+type WorkflowPriority struct {
+	Name     string `json:"name" binding:"required,max=50"`
+	Label    string `json:"label" binding:"required,max=100"`
+	Color    string `json:"color" binding:"omitempty,hexcolor"`
+	Position int    `json:"position"`
+	Default  bool   `json:"default"`
+}
+
+// WorkflowTransition represents an allowed change from one status to another
+type WorkflowTransition struct {
+	From string `json:"from" binding:"required"`
+	To   string `json:"to" binding:"required"`
+}
+
+// Workflow represents the statuses, priorities and status transitions of a project.
+// Projects that have not configured a workflow use the built-in one.
+type Workflow struct {
+	ProjectID   int64                 `json:"project_id"`
+	Statuses    []*WorkflowStatus     `json:"statuses"`
+	Priorities  []*WorkflowPriority   `json:"priorities"`
+	Transitions []*WorkflowTransition `json:"transitions"`
+	BuiltIn     bool                  `json:"built_in"`
+}
+
+// WorkflowUpdate represents data needed to replace the workflow of a project.
+// The mappings move test cases from statuses and priorities that are removed to new ones.
+type WorkflowUpdate struct {
+	Statuses        []*WorkflowStatus     `json:"statuses" binding:"required,min=1,dive"`
+	Priorities      []*WorkflowPriority   `json:"priorities" binding:"required,min=1,dive"`
+	Transitions     []*WorkflowTransition `json:"transitions" binding:"dive"`
+	StatusMapping   map[string]string     `json:"status_mapping"`
+	PriorityMapping map[string]string     `json:"priority_mapping"`
+}
+
+// DefaultWorkflow returns the built-in workflow, which allows every status change
+func DefaultWorkflow(projectID int64) *Workflow {
+	return &Workflow{
+		ProjectID: projectID,
+		Statuses: []*WorkflowStatus{
+			{Name: string(StatusDraft), Label: "Draft", Color: "#9e9e9e", Position: 0},
+			{Name: string(StatusInReview), Label: "In Review", Color: "#2196f3", Position: 1},
+			{Name: string(StatusApproved), Label: "Approved", Color: "#00bcd4", Position: 2},
+			{Name: string(StatusRejected), Label: "Rejected", Color: "#ff9800", Position: 3},
+			{Name: string(StatusActive), Label: "Active", Color: "#4caf50", Position: 4},
+			{Name: string(StatusDeprecated), Label: "Deprecated", Color: "#607d8b", Position: 5, Terminal: true},
+		},
+		Priorities: []*WorkflowPriority{
+			{Name: string(PriorityLow), Label: "Low", Color: "#8bc34a", Position: 0},
+			{Name: string(PriorityMedium), Label: "Medium", Color: "#ffc107", Position: 1, Default: true},
+			{Name: string(PriorityHigh), Label: "High", Color: "#f44336", Position: 2},
+		},
+		Transitions: []*WorkflowTransition{},
+		BuiltIn:     true,
+	}
+}
+
+// Status returns the status with the given name, or nil if the workflow does not have it
+func (w *Workflow) Status(name TestCaseStatus) *WorkflowStatus {
+	for _, status := range w.Statuses {
+		if status.Name == string(name) {
+			return status
+		}
+	}
+	return nil
+}
+
+// Priority returns the priority with the given name, or nil if the workflow does not have it
+func (w *Workflow) Priority(name TestCasePriority) *WorkflowPriority {
+	for _, priority := range w.Priorities {
+		if priority.Name == string(name) {
+			return priority
+		}
+	}
+	return nil
+}
+
+// DefaultPriority returns the priority of new test cases that do not set one
+func (w *Workflow) DefaultPriority() TestCasePriority {
+	for _, priority := range w.Priorities {
+		if priority.Default {
+			return TestCasePriority(priority.Name)
+		}
+	}
+	if len(w.Priorities) > 0 {
+		return TestCasePriority(w.Priorities[0].Name)
+	}
+	return PriorityMedium
+}
+
+// AllowsTransition reports whether a test case may move between two statuses.
+// Every change is allowed when the workflow has no transitions.
+func (w *Workflow) AllowsTransition(from, to TestCaseStatus) bool {
+	if from == to || len(w.Transitions) == 0 {
+		return true
+	}
+	for _, transition := range w.Transitions {
+		if transition.From == string(from) && transition.To == string(to) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,173 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mihaamiharu/test-case-management-be/internal/models"
+)
+
+var (
+	ErrAPITokenNotFound = errors.New("API token not found")
+)
+
+// APITokenRepositoryInterface defines the interface for API token repository operations
+type APITokenRepositoryInterface interface {
+	Create(token *models.APIToken) error
+	GetByHash(tokenHash string) (*models.APIToken, error)
+	ListByUser(userID int64) ([]*models.APIToken, error)
+	Revoke(id, userID int64) error
+	UpdateLastUsed(id int64, lastUsedAt time.Time) error
+}
+
+// APITokenRepository handles database operations for personal API tokens
+type APITokenRepository struct {
+	db *sql.DB
+}
+
+// NewAPITokenRepository creates a new API token repository
+func NewAPITokenRepository(db *sql.DB) *APITokenRepository {
+	return &APITokenRepository{db: db}
+}
+
+// Create adds a new API token to the database
+func (r *APITokenRepository) Create(token *models.APIToken) error {
+	query := `
+		INSERT INTO api_tokens (user_id, name, token_prefix, token_hash, scopes, project_id, expires_at, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`
+
+	now := time.Now()
+	result, err := r.db.Exec(query, token.UserID, token.Name, token.TokenPrefix, token.TokenHash,
+		joinScopes(token.Scopes), token.ProjectID, token.ExpiresAt, now)
+	if err != nil {
+		return fmt.Errorf("failed to create API token: %v", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to get API token ID: %v", err)
+	}
+
+	token.ID = id
+	token.CreatedAt = now
+	return nil
+}
+
+// GetByHash retrieves an API token by the hash of its value, including revoked and expired ones
+func (r *APITokenRepository) GetByHash(tokenHash string) (*models.APIToken, error) {
+	query := `
+		SELECT id, user_id, name, token_prefix, token_hash, scopes, project_id, expires_at, last_used_at, revoked_at, created_at
+		FROM api_tokens
+		WHERE token_hash = ?`
+
+	token, err := scanAPIToken(r.db.QueryRow(query, tokenHash))
+	if err == sql.ErrNoRows {
+		return nil, ErrAPITokenNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get API token: %v", err)
+	}
+
+	return token, nil
+}
+
+// ListByUser retrieves the API tokens of a user that have not been revoked, newest first
+func (r *APITokenRepository) ListByUser(userID int64) ([]*models.APIToken, error) {
+	query := `
+		SELECT id, user_id, name, token_prefix, token_hash, scopes, project_id, expires_at, last_used_at, revoked_at, created_at
+		FROM api_tokens
+		WHERE user_id = ? AND revoked_at IS NULL
+		ORDER BY created_at DESC`
+
+	rows, err := r.db.Query(query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list API tokens: %v", err)
+	}
+	defer rows.Close()
+
+	var tokens []*models.APIToken
+	for rows.Next() {
+		token, err := scanAPIToken(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan API token: %v", err)
+		}
+		tokens = append(tokens, token)
+	}
+
+	return tokens, rows.Err()
+}
+
+// Revoke revokes an API token of a user
+func (r *APITokenRepository) Revoke(id, userID int64) error {
+	result, err := r.db.Exec("UPDATE api_tokens SET revoked_at = ? WHERE id = ? AND user_id = ? AND revoked_at IS NULL",
+		time.Now(), id, userID)
+	if err != nil {
+		return fmt.Errorf("failed to revoke API token: %v", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %v", err)
+	}
+	if rowsAffected == 0 {
+		return ErrAPITokenNotFound
+	}
+
+	return nil
+}
+
+// UpdateLastUsed records when an API token was last used
+func (r *APITokenRepository) UpdateLastUsed(id int64, lastUsedAt time.Time) error {
+	if _, err := r.db.Exec("UPDATE api_tokens SET last_used_at = ? WHERE id = ?", lastUsedAt, id); err != nil {
+		return fmt.Errorf("failed to update API token: %v", err)
+	}
+	return nil
+}
+
+// scanAPIToken scans an API token row
+func scanAPIToken(row rowScanner) (*models.APIToken, error) {
+	var (
+		token     models.APIToken
+		scopes    string
+		projectID sql.NullInt64
+	)
+	err := row.Scan(
+		&token.ID,
+		&token.UserID,
+		&token.Name,
+		&token.TokenPrefix,
+		&token.TokenHash,
+		&scopes,
+		&projectID,
+		&token.ExpiresAt,
+		&token.LastUsedAt,
+		&token.RevokedAt,
+		&token.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, scope := range strings.Split(scopes, ",") {
+		if scope != "" {
+			token.Scopes = append(token.Scopes, models.APITokenScope(scope))
+		}
+	}
+	if projectID.Valid {
+		token.ProjectID = &projectID.Int64
+	}
+
+	return &token, nil
+}
+
+// joinScopes stores scopes as a comma-separated list
+func joinScopes(scopes []models.APITokenScope) string {
+	names := make([]string, len(scopes))
+	for i, scope := range scopes {
+		names[i] = string(scope)
+	}
+	return strings.Join(names, ",")
+}
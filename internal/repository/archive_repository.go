@@ -0,0 +1,124 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// ArchiveRepositoryInterface defines the interface for looking up the project of entities and its archived state
+type ArchiveRepositoryInterface interface {
+	GetArchivedState(entityType string, id int64) (projectArchived, suiteArchived bool, err error)
+	GetProjectID(entityType string, id int64) (int64, error)
+}
+
+// ArchiveRepository looks up the project and suite an entity belongs to and whether they are archived
+type ArchiveRepository struct {
+	db *sql.DB
+}
+
+// NewArchiveRepository creates a new archive repository
+func NewArchiveRepository(db *sql.DB) *ArchiveRepository {
+	return &ArchiveRepository{db: db}
+}
+
+const (
+	// projectStateQuery selects the archived state of a project, joined to the entity that belongs to it
+	projectStateQuery = `
+		SELECT p.id, p.archived_at IS NOT NULL, FALSE
+		FROM projects p`
+
+	// testCaseStateQuery selects the archived state of the project and suite of a test case,
+	// joined to the entity that belongs to it
+	testCaseStateQuery = `
+		SELECT p.id, p.archived_at IS NOT NULL, COALESCE(s.archived_at IS NOT NULL, FALSE)
+		FROM test_cases tc
+		JOIN projects p ON p.id = tc.project_id
+		LEFT JOIN test_suites s ON s.id = tc.suite_id`
+)
+
+// archivedStateQueries select the project of each entity type and the archived state of the project and suite
+var archivedStateQueries = map[string]string{
+	"project": projectStateQuery + `
+		WHERE p.id = ?`,
+	"test_suite": `
+		SELECT p.id, p.archived_at IS NOT NULL, s.archived_at IS NOT NULL
+		FROM test_suites s
+		JOIN projects p ON p.id = s.project_id
+		WHERE s.id = ?`,
+	"custom_field": projectStateQuery + `
+		JOIN custom_field_definitions d ON d.project_id = p.id
+		WHERE d.id = ?`,
+	"shared_step": projectStateQuery + `
+		JOIN shared_steps ss ON ss.project_id = p.id
+		WHERE ss.id = ?`,
+	"test_run": projectStateQuery + `
+		JOIN test_runs tr ON tr.project_id = p.id
+		WHERE tr.id = ?`,
+	"test_execution": projectStateQuery + `
+		JOIN test_runs tr ON tr.project_id = p.id
+		JOIN test_executions e ON e.test_run_id = tr.id
+		WHERE e.id = ?`,
+	"defect": projectStateQuery + `
+		JOIN test_runs tr ON tr.project_id = p.id
+		JOIN test_executions e ON e.test_run_id = tr.id
+		JOIN defects d ON d.test_execution_id = e.id
+		WHERE d.id = ?`,
+	"webhook": projectStateQuery + `
+		JOIN webhooks w ON w.project_id = p.id
+		WHERE w.id = ?`,
+	"test_case": testCaseStateQuery + `
+		WHERE tc.id = ?`,
+	"test_step": testCaseStateQuery + `
+		JOIN test_steps st ON st.test_case_id = tc.id
+		WHERE st.id = ?`,
+	"step_note": testCaseStateQuery + `
+		JOIN test_steps st ON st.test_case_id = tc.id
+		JOIN step_notes n ON n.step_id = st.id
+		WHERE n.id = ?`,
+	"step_attachment": testCaseStateQuery + `
+		JOIN test_steps st ON st.test_case_id = tc.id
+		JOIN step_attachments a ON a.step_id = st.id
+		WHERE a.id = ?`,
+	"comment": testCaseStateQuery + `
+		JOIN comments cm ON cm.test_case_id = tc.id
+		WHERE cm.id = ?`,
+	"review": testCaseStateQuery + `
+		JOIN test_case_reviews r ON r.test_case_id = tc.id
+		WHERE r.id = ?`,
+}
+
+// GetArchivedState reports whether the project and the test suite an entity belongs to are
+// archived. Entities that do not exist are reported as not archived.
+func (r *ArchiveRepository) GetArchivedState(entityType string, id int64) (bool, bool, error) {
+	_, projectArchived, suiteArchived, err := r.lookup(entityType, id)
+	return projectArchived, suiteArchived, err
+}
+
+// GetProjectID retrieves the ID of the project an entity belongs to. Entities that do not
+// exist are reported with a project ID of 0.
+func (r *ArchiveRepository) GetProjectID(entityType string, id int64) (int64, error) {
+	projectID, _, _, err := r.lookup(entityType, id)
+	return projectID, err
+}
+
+// lookup retrieves the project of an entity and whether its project and suite are archived
+func (r *ArchiveRepository) lookup(entityType string, id int64) (int64, bool, bool, error) {
+	query, ok := archivedStateQueries[entityType]
+	if !ok {
+		return 0, false, false, fmt.Errorf("unknown entity type: %s", entityType)
+	}
+
+	var (
+		projectID                      int64
+		projectArchived, suiteArchived bool
+	)
+	err := r.db.QueryRow(query, id).Scan(&projectID, &projectArchived, &suiteArchived)
+	if err == sql.ErrNoRows {
+		return 0, false, false, nil
+	}
+	if err != nil {
+		return 0, false, false, fmt.Errorf("failed to look up entity: %v", err)
+	}
+
+	return projectID, projectArchived, suiteArchived, nil
+}
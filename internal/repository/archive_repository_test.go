@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestArchiveRepository_Lookup(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewArchiveRepository(db)
+
+	// Test case: a test step whose suite is archived
+	t.Run("SuiteArchived", func(t *testing.T) {
+		mock.ExpectQuery("FROM test_cases tc .* JOIN test_steps st ON st.test_case_id = tc.id WHERE st.id = ?").
+			WithArgs(5).
+			WillReturnRows(sqlmock.NewRows([]string{"project_id", "project_archived", "suite_archived"}).AddRow(2, false, true))
+
+		projectArchived, suiteArchived, err := repo.GetArchivedState("test_step", 5)
+		assert.NoError(t, err)
+		assert.False(t, projectArchived)
+		assert.True(t, suiteArchived)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	// Test case: an entity that does not exist is not archived
+	t.Run("NotFound", func(t *testing.T) {
+		mock.ExpectQuery("FROM projects p WHERE p.id = ?").
+			WithArgs(9).
+			WillReturnRows(sqlmock.NewRows([]string{"project_id", "project_archived", "suite_archived"}))
+
+		projectArchived, suiteArchived, err := repo.GetArchivedState("project", 9)
+		assert.NoError(t, err)
+		assert.False(t, projectArchived)
+		assert.False(t, suiteArchived)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	// Test case: the project of a comment
+	t.Run("ProjectID", func(t *testing.T) {
+		mock.ExpectQuery("JOIN comments cm ON cm.test_case_id = tc.id WHERE cm.id = ?").
+			WithArgs(7).
+			WillReturnRows(sqlmock.NewRows([]string{"project_id", "project_archived", "suite_archived"}).AddRow(3, true, false))
+
+		projectID, err := repo.GetProjectID("comment", 7)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(3), projectID)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	// Test case: an unknown entity type
+	t.Run("UnknownEntity", func(t *testing.T) {
+		_, _, err := repo.GetArchivedState("widget", 1)
+		assert.Error(t, err)
+	})
+}
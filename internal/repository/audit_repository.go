@@ -0,0 +1,186 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mihaamiharu/test-case-management-be/internal/models"
+)
+
+// AuditRepositoryInterface defines the interface for audit log repository operations
+type AuditRepositoryInterface interface {
+	Create(entry *models.AuditEntry) error
+	List(filter *models.AuditFilter) ([]*models.AuditEntry, error)
+}
+
+// AuditRepository handles database operations for the audit log
+type AuditRepository struct {
+	db *sql.DB
+}
+
+// NewAuditRepository creates a new audit repository
+func NewAuditRepository(db *sql.DB) *AuditRepository {
+	return &AuditRepository{db: db}
+}
+
+// Create records an audit entry
+func (r *AuditRepository) Create(entry *models.AuditEntry) error {
+	now := time.Now()
+	query := `
+		INSERT INTO audit_log (
+			actor_id, action, entity_type, entity_id, project_id, organization_id,
+			method, path, ip_address, before_data, after_data, created_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+
+	result, err := r.db.Exec(
+		query,
+		entry.ActorID,
+		entry.Action,
+		entry.EntityType,
+		entry.EntityID,
+		entry.ProjectID,
+		entry.OrganizationID,
+		entry.Method,
+		entry.Path,
+		entry.IPAddress,
+		nullJSON(entry.Before),
+		nullJSON(entry.After),
+		now,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create audit entry: %v", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to get last insert ID: %v", err)
+	}
+
+	entry.ID = id
+	entry.CreatedAt = now
+
+	return nil
+}
+
+// List retrieves audit entries matching a filter, newest first
+func (r *AuditRepository) List(filter *models.AuditFilter) ([]*models.AuditEntry, error) {
+	var (
+		conditions []string
+		args       []interface{}
+	)
+	switch {
+	case filter.OrganizationID != 0 && filter.IncludeInstallation:
+		conditions = append(conditions, "(organization_id = ? OR organization_id IS NULL)")
+		args = append(args, filter.OrganizationID)
+	case filter.OrganizationID != 0:
+		conditions = append(conditions, "organization_id = ?")
+		args = append(args, filter.OrganizationID)
+	}
+	if filter.ActorID != 0 {
+		conditions = append(conditions, "actor_id = ?")
+		args = append(args, filter.ActorID)
+	}
+	if filter.ProjectID != 0 {
+		conditions = append(conditions, "project_id = ?")
+		args = append(args, filter.ProjectID)
+	}
+	if filter.EntityType != "" {
+		conditions = append(conditions, "entity_type = ?")
+		args = append(args, filter.EntityType)
+	}
+	if filter.EntityID != 0 {
+		conditions = append(conditions, "entity_id = ?")
+		args = append(args, filter.EntityID)
+	}
+	if filter.Action != "" {
+		conditions = append(conditions, "action = ?")
+		args = append(args, filter.Action)
+	}
+	if !filter.From.IsZero() {
+		conditions = append(conditions, "created_at >= ?")
+		args = append(args, filter.From)
+	}
+	if !filter.To.IsZero() {
+		conditions = append(conditions, "created_at < ?")
+		args = append(args, filter.To)
+	}
+
+	query := `
+		SELECT id, actor_id, action, entity_type, entity_id, project_id, organization_id,
+			method, path, ip_address, before_data, after_data, created_at
+		FROM audit_log`
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += " ORDER BY created_at DESC, id DESC"
+	if filter.Limit > 0 {
+		query += " LIMIT ? OFFSET ?"
+		args = append(args, filter.Limit, filter.Offset)
+	}
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list audit entries: %v", err)
+	}
+	defer rows.Close()
+
+	var entries []*models.AuditEntry
+	for rows.Next() {
+		entry := &models.AuditEntry{}
+		var (
+			actorID, entityID, projectID, organizationID sql.NullInt64
+			before, after                                []byte
+		)
+		err := rows.Scan(
+			&entry.ID,
+			&actorID,
+			&entry.Action,
+			&entry.EntityType,
+			&entityID,
+			&projectID,
+			&organizationID,
+			&entry.Method,
+			&entry.Path,
+			&entry.IPAddress,
+			&before,
+			&after,
+			&entry.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan audit entry: %v", err)
+		}
+
+		if actorID.Valid {
+			entry.ActorID = &actorID.Int64
+		}
+		if entityID.Valid {
+			entry.EntityID = &entityID.Int64
+		}
+		if projectID.Valid {
+			entry.ProjectID = &projectID.Int64
+		}
+		if organizationID.Valid {
+			entry.OrganizationID = &organizationID.Int64
+		}
+		if len(before) > 0 {
+			entry.Before = before
+		}
+		if len(after) > 0 {
+			entry.After = after
+		}
+
+		entries = append(entries, entry)
+	}
+
+	return entries, rows.Err()
+}
+
+// nullJSON stores empty JSON documents as NULL
+func nullJSON(data []byte) interface{} {
+	if len(data) == 0 {
+		return nil
+	}
+	return data
+}
@@ -0,0 +1,109 @@
+package repository
+
+import (
+	"encoding/json"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/mihaamiharu/test-case-management-be/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuditRepository_Create(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewAuditRepository(db)
+
+	actorID, entityID, organizationID := int64(1), int64(5), int64(2)
+	entry := &models.AuditEntry{
+		ActorID:        &actorID,
+		Action:         models.AuditActionUpdate,
+		EntityType:     "test_suite",
+		EntityID:       &entityID,
+		OrganizationID: &organizationID,
+		Method:         "PUT",
+		Path:           "/api/v1/test-suites/5",
+		IPAddress:      "127.0.0.1",
+		After:          json.RawMessage(`{"name":"Smoke"}`),
+	}
+
+	// Test case: an entry without a before state stores NULL
+	mock.ExpectExec("INSERT INTO audit_log").
+		WithArgs(&actorID, models.AuditActionUpdate, "test_suite", &entityID, nil, &organizationID, "PUT", "/api/v1/test-suites/5", "127.0.0.1", nil, []byte(`{"name":"Smoke"}`), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(3, 1))
+
+	err = repo.Create(entry)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), entry.ID)
+	assert.NotZero(t, entry.CreatedAt)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAuditRepository_List(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewAuditRepository(db)
+	columns := []string{"id", "actor_id", "action", "entity_type", "entity_id", "project_id", "organization_id", "method", "path", "ip_address", "before_data", "after_data", "created_at"}
+	now := time.Now()
+
+	// Test case: filters become conditions and a page is requested
+	t.Run("WithFilters", func(t *testing.T) {
+		from := now.Add(-time.Hour)
+		mock.ExpectQuery(regexp.QuoteMeta("WHERE organization_id = ? AND project_id = ? AND action = ? AND created_at >= ? ORDER BY created_at DESC, id DESC LIMIT ? OFFSET ?")).
+			WithArgs(int64(3), int64(2), models.AuditActionDelete, from, 10, 20).
+			WillReturnRows(sqlmock.NewRows(columns).
+				AddRow(7, 1, "delete", "test_case", 9, 2, 3, "DELETE", "/api/v1/test-cases/9", "127.0.0.1", []byte(`{"title":"Login"}`), nil, now))
+
+		entries, err := repo.List(&models.AuditFilter{OrganizationID: 3, ProjectID: 2, Action: models.AuditActionDelete, From: from, Limit: 10, Offset: 20})
+
+		assert.NoError(t, err)
+		assert.Len(t, entries, 1)
+		assert.Equal(t, int64(9), *entries[0].EntityID)
+		assert.Equal(t, int64(2), *entries[0].ProjectID)
+		assert.Equal(t, int64(3), *entries[0].OrganizationID)
+		assert.JSONEq(t, `{"title":"Login"}`, string(entries[0].Before))
+		assert.Nil(t, entries[0].After)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	// Test case: no filters and no limit list every entry
+	t.Run("Unfiltered", func(t *testing.T) {
+		mock.ExpectQuery(regexp.QuoteMeta("FROM audit_log ORDER BY created_at DESC, id DESC")).
+			WillReturnRows(sqlmock.NewRows(columns).
+				AddRow(8, nil, "create", "tag", 4, nil, nil, "POST", "/api/v1/tags", "127.0.0.1", nil, []byte(`{}`), now))
+
+		entries, err := repo.List(&models.AuditFilter{})
+
+		assert.NoError(t, err)
+		assert.Len(t, entries, 1)
+		assert.Nil(t, entries[0].ActorID)
+		assert.Nil(t, entries[0].ProjectID)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	// Test case: admins of the installation also get the entries of no organization
+	t.Run("WithInstallation", func(t *testing.T) {
+		mock.ExpectQuery(regexp.QuoteMeta("WHERE (organization_id = ? OR organization_id IS NULL) ORDER BY created_at DESC, id DESC")).
+			WithArgs(int64(3)).
+			WillReturnRows(sqlmock.NewRows(columns).
+				AddRow(9, nil, "lock", "user", 4, nil, nil, "POST", "/api/v1/auth/login", "127.0.0.1", nil, []byte(`{}`), now))
+
+		entries, err := repo.List(&models.AuditFilter{OrganizationID: 3, IncludeInstallation: true})
+
+		assert.NoError(t, err)
+		assert.Len(t, entries, 1)
+		assert.Nil(t, entries[0].OrganizationID)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -0,0 +1,333 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mihaamiharu/test-case-management-be/internal/models"
+)
+
+var (
+	ErrCommentNotFound = errors.New("comment not found")
+)
+
+// CommentRepositoryInterface defines the interface for comment repository operations
+type CommentRepositoryInterface interface {
+	Create(comment *models.Comment) error
+	GetByID(id int64) (*models.Comment, error)
+	Update(comment *models.Comment) error
+	Delete(id int64) error
+	SetResolved(id int64, resolved bool, userID int64) error
+	ListByTestCase(testCaseID int64) ([]*models.Comment, error)
+}
+
+// CommentRepository handles database operations for comments
+type CommentRepository struct {
+	db *sql.DB
+}
+
+// NewCommentRepository creates a new comment repository
+func NewCommentRepository(db *sql.DB) *CommentRepository {
+	return &CommentRepository{db: db}
+}
+
+// Create adds a new comment together with its mentions
+func (r *CommentRepository) Create(comment *models.Comment) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	query := `
+		INSERT INTO comments (
+			test_case_id, step_id, parent_id, body, created_by, created_at, updated_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?)`
+
+	result, err := tx.Exec(
+		query,
+		comment.TestCaseID,
+		comment.StepID,
+		comment.ParentID,
+		comment.Body,
+		comment.CreatedBy,
+		now,
+		now,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create comment: %v", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to get last insert ID: %v", err)
+	}
+
+	if err := insertCommentMentions(tx, id, comment.Mentions); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %v", err)
+	}
+
+	comment.ID = id
+	comment.CreatedAt = now
+	comment.UpdatedAt = now
+
+	return nil
+}
+
+// GetByID retrieves a comment by ID including its mentions
+func (r *CommentRepository) GetByID(id int64) (*models.Comment, error) {
+	query := `
+		SELECT id, test_case_id, step_id, parent_id, body, created_by, is_resolved,
+			resolved_by, resolved_at, edited_at, created_at, updated_at
+		FROM comments
+		WHERE id = ?`
+
+	comment, err := scanComment(r.db.QueryRow(query, id))
+	if err == sql.ErrNoRows {
+		return nil, ErrCommentNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get comment: %v", err)
+	}
+
+	mentions, err := r.getMentions("cm.comment_id = ?", id)
+	if err != nil {
+		return nil, err
+	}
+	comment.Mentions = mentions[comment.ID]
+
+	return comment, nil
+}
+
+// Update changes the body of a comment and replaces its mentions
+func (r *CommentRepository) Update(comment *models.Comment) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	result, err := tx.Exec("UPDATE comments SET body = ?, edited_at = ?, updated_at = ? WHERE id = ?",
+		comment.Body, now, now, comment.ID)
+	if err != nil {
+		return fmt.Errorf("failed to update comment: %v", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %v", err)
+	}
+	if rowsAffected == 0 {
+		return ErrCommentNotFound
+	}
+
+	if _, err := tx.Exec("DELETE FROM comment_mentions WHERE comment_id = ?", comment.ID); err != nil {
+		return fmt.Errorf("failed to delete comment mentions: %v", err)
+	}
+	if err := insertCommentMentions(tx, comment.ID, comment.Mentions); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %v", err)
+	}
+
+	comment.EditedAt = &now
+	comment.UpdatedAt = now
+
+	return nil
+}
+
+// Delete removes a comment and its replies
+func (r *CommentRepository) Delete(id int64) error {
+	result, err := r.db.Exec("DELETE FROM comments WHERE id = ?", id)
+	if err != nil {
+		return fmt.Errorf("failed to delete comment: %v", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %v", err)
+	}
+	if rowsAffected == 0 {
+		return ErrCommentNotFound
+	}
+
+	return nil
+}
+
+// SetResolved marks a comment as resolved or unresolved
+func (r *CommentRepository) SetResolved(id int64, resolved bool, userID int64) error {
+	var (
+		resolvedBy *int64
+		resolvedAt *time.Time
+	)
+	now := time.Now()
+	if resolved {
+		resolvedBy = &userID
+		resolvedAt = &now
+	}
+
+	result, err := r.db.Exec(
+		"UPDATE comments SET is_resolved = ?, resolved_by = ?, resolved_at = ?, updated_at = ? WHERE id = ?",
+		resolved, resolvedBy, resolvedAt, now, id,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update comment: %v", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %v", err)
+	}
+	if rowsAffected == 0 {
+		return ErrCommentNotFound
+	}
+
+	return nil
+}
+
+// ListByTestCase retrieves all comments of a test case, oldest first, including their mentions
+func (r *CommentRepository) ListByTestCase(testCaseID int64) ([]*models.Comment, error) {
+	query := `
+		SELECT id, test_case_id, step_id, parent_id, body, created_by, is_resolved,
+			resolved_by, resolved_at, edited_at, created_at, updated_at
+		FROM comments
+		WHERE test_case_id = ?
+		ORDER BY created_at, id`
+
+	rows, err := r.db.Query(query, testCaseID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list comments: %v", err)
+	}
+	defer rows.Close()
+
+	var comments []*models.Comment
+	for rows.Next() {
+		comment, err := scanComment(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan comment: %v", err)
+		}
+		comments = append(comments, comment)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	mentions, err := r.getMentions("c.test_case_id = ?", testCaseID)
+	if err != nil {
+		return nil, err
+	}
+	for _, comment := range comments {
+		comment.Mentions = mentions[comment.ID]
+	}
+
+	return comments, nil
+}
+
+// getMentions retrieves mentioned users grouped by comment ID
+func (r *CommentRepository) getMentions(condition string, arg interface{}) (map[int64][]*models.UserSummary, error) {
+	query := `
+		SELECT cm.comment_id, u.id, u.username
+		FROM comment_mentions cm
+		JOIN comments c ON c.id = cm.comment_id
+		JOIN users u ON u.id = cm.user_id
+		WHERE ` + condition + `
+		ORDER BY u.username`
+
+	rows, err := r.db.Query(query, arg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get comment mentions: %v", err)
+	}
+	defer rows.Close()
+
+	mentions := make(map[int64][]*models.UserSummary)
+	for rows.Next() {
+		var commentID int64
+		user := &models.UserSummary{}
+		if err := rows.Scan(&commentID, &user.ID, &user.Username); err != nil {
+			return nil, fmt.Errorf("failed to scan comment mention: %v", err)
+		}
+		mentions[commentID] = append(mentions[commentID], user)
+	}
+
+	return mentions, rows.Err()
+}
+
+// insertCommentMentions links the mentioned users to a comment
+func insertCommentMentions(tx *sql.Tx, commentID int64, mentions []*models.UserSummary) error {
+	if len(mentions) == 0 {
+		return nil
+	}
+
+	placeholders := make([]string, len(mentions))
+	args := make([]interface{}, 0, len(mentions)*2)
+	for i, user := range mentions {
+		placeholders[i] = "(?, ?)"
+		args = append(args, commentID, user.ID)
+	}
+
+	query := "INSERT IGNORE INTO comment_mentions (comment_id, user_id) VALUES " + strings.Join(placeholders, ", ")
+	if _, err := tx.Exec(query, args...); err != nil {
+		return fmt.Errorf("failed to create comment mentions: %v", err)
+	}
+
+	return nil
+}
+
+// scanComment scans a comment row including its nullable columns
+func scanComment(row rowScanner) (*models.Comment, error) {
+	comment := &models.Comment{}
+	var (
+		stepID     sql.NullInt64
+		parentID   sql.NullInt64
+		resolvedBy sql.NullInt64
+		resolvedAt sql.NullTime
+		editedAt   sql.NullTime
+	)
+
+	err := row.Scan(
+		&comment.ID,
+		&comment.TestCaseID,
+		&stepID,
+		&parentID,
+		&comment.Body,
+		&comment.CreatedBy,
+		&comment.IsResolved,
+		&resolvedBy,
+		&resolvedAt,
+		&editedAt,
+		&comment.CreatedAt,
+		&comment.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if stepID.Valid {
+		comment.StepID = &stepID.Int64
+	}
+	if parentID.Valid {
+		comment.ParentID = &parentID.Int64
+	}
+	if resolvedBy.Valid {
+		comment.ResolvedBy = &resolvedBy.Int64
+	}
+	if resolvedAt.Valid {
+		comment.ResolvedAt = &resolvedAt.Time
+	}
+	if editedAt.Valid {
+		comment.EditedAt = &editedAt.Time
+	}
+
+	return comment, nil
+}
@@ -0,0 +1,348 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/mihaamiharu/test-case-management-be/internal/models"
+)
+
+var (
+	ErrCustomFieldNotFound = errors.New("custom field not found")
+	ErrCustomFieldExists   = errors.New("custom field with this name already exists in the project")
+)
+
+// CustomFieldRepositoryInterface defines the interface for custom field repository operations
+type CustomFieldRepositoryInterface interface {
+	CreateDefinition(definition *models.CustomFieldDefinition) error
+	GetDefinition(id int64) (*models.CustomFieldDefinition, error)
+	UpdateDefinition(definition *models.CustomFieldDefinition) error
+	DeleteDefinition(id int64) error
+	ListDefinitions(projectID int64) ([]*models.CustomFieldDefinition, error)
+	GetValues(testCaseID int64) (map[int64]interface{}, error)
+	ListValuesByProject(projectID int64) (map[int64]map[int64]interface{}, error)
+	SetValues(testCaseID int64, values map[int64]interface{}) error
+}
+
+// CustomFieldRepository handles database operations for custom fields
+type CustomFieldRepository struct {
+	db *sql.DB
+}
+
+// NewCustomFieldRepository creates a new custom field repository
+func NewCustomFieldRepository(db *sql.DB) *CustomFieldRepository {
+	return &CustomFieldRepository{db: db}
+}
+
+// CreateDefinition adds a new custom field to a project
+func (r *CustomFieldRepository) CreateDefinition(definition *models.CustomFieldDefinition) error {
+	var count int
+	err := r.db.QueryRow("SELECT COUNT(*) FROM custom_field_definitions WHERE project_id = ? AND name = ?",
+		definition.ProjectID, definition.Name).Scan(&count)
+	if err != nil {
+		return fmt.Errorf("failed to check existing custom fields: %v", err)
+	}
+	if count > 0 {
+		return ErrCustomFieldExists
+	}
+
+	options, err := encodeOptions(definition.Options)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	query := `
+		INSERT INTO custom_field_definitions (
+			project_id, name, label, field_type, options, is_required, position,
+			created_by, created_at, updated_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+
+	result, err := r.db.Exec(
+		query,
+		definition.ProjectID,
+		definition.Name,
+		definition.Label,
+		definition.Type,
+		options,
+		definition.Required,
+		definition.Position,
+		definition.CreatedBy,
+		now,
+		now,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create custom field: %v", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to get last insert ID: %v", err)
+	}
+
+	definition.ID = id
+	definition.CreatedAt = now
+	definition.UpdatedAt = now
+
+	return nil
+}
+
+// GetDefinition retrieves a custom field by ID
+func (r *CustomFieldRepository) GetDefinition(id int64) (*models.CustomFieldDefinition, error) {
+	query := `
+		SELECT id, project_id, name, label, field_type, options, is_required, position,
+			created_by, created_at, updated_at
+		FROM custom_field_definitions
+		WHERE id = ?`
+
+	definition, err := scanCustomFieldDefinition(r.db.QueryRow(query, id))
+	if err == sql.ErrNoRows {
+		return nil, ErrCustomFieldNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get custom field: %v", err)
+	}
+
+	return definition, nil
+}
+
+// UpdateDefinition updates the label, options, required flag and position of a custom field
+func (r *CustomFieldRepository) UpdateDefinition(definition *models.CustomFieldDefinition) error {
+	options, err := encodeOptions(definition.Options)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	query := `
+		UPDATE custom_field_definitions SET
+			label = ?,
+			options = ?,
+			is_required = ?,
+			position = ?,
+			updated_at = ?
+		WHERE id = ?`
+
+	result, err := r.db.Exec(
+		query,
+		definition.Label,
+		options,
+		definition.Required,
+		definition.Position,
+		now,
+		definition.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update custom field: %v", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %v", err)
+	}
+	if rowsAffected == 0 {
+		return ErrCustomFieldNotFound
+	}
+
+	definition.UpdatedAt = now
+	return nil
+}
+
+// DeleteDefinition removes a custom field and all of its values
+func (r *CustomFieldRepository) DeleteDefinition(id int64) error {
+	result, err := r.db.Exec("DELETE FROM custom_field_definitions WHERE id = ?", id)
+	if err != nil {
+		return fmt.Errorf("failed to delete custom field: %v", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %v", err)
+	}
+	if rowsAffected == 0 {
+		return ErrCustomFieldNotFound
+	}
+
+	return nil
+}
+
+// ListDefinitions retrieves the custom fields of a project in display order
+func (r *CustomFieldRepository) ListDefinitions(projectID int64) ([]*models.CustomFieldDefinition, error) {
+	query := `
+		SELECT id, project_id, name, label, field_type, options, is_required, position,
+			created_by, created_at, updated_at
+		FROM custom_field_definitions
+		WHERE project_id = ?
+		ORDER BY position, id`
+
+	rows, err := r.db.Query(query, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list custom fields: %v", err)
+	}
+	defer rows.Close()
+
+	var definitions []*models.CustomFieldDefinition
+	for rows.Next() {
+		definition, err := scanCustomFieldDefinition(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan custom field: %v", err)
+		}
+		definitions = append(definitions, definition)
+	}
+
+	return definitions, rows.Err()
+}
+
+// GetValues retrieves the custom field values of a test case keyed by field ID
+func (r *CustomFieldRepository) GetValues(testCaseID int64) (map[int64]interface{}, error) {
+	rows, err := r.db.Query("SELECT field_id, value FROM test_case_custom_values WHERE test_case_id = ?", testCaseID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get custom field values: %v", err)
+	}
+	defer rows.Close()
+
+	values := make(map[int64]interface{})
+	for rows.Next() {
+		var (
+			fieldID int64
+			raw     []byte
+		)
+		if err := rows.Scan(&fieldID, &raw); err != nil {
+			return nil, fmt.Errorf("failed to scan custom field value: %v", err)
+		}
+
+		var value interface{}
+		if err := json.Unmarshal(raw, &value); err != nil {
+			return nil, fmt.Errorf("failed to decode custom field value: %v", err)
+		}
+		values[fieldID] = value
+	}
+
+	return values, rows.Err()
+}
+
+// ListValuesByProject retrieves the custom field values of every test case in a project,
+// keyed by test case ID and then by field ID
+func (r *CustomFieldRepository) ListValuesByProject(projectID int64) (map[int64]map[int64]interface{}, error) {
+	query := `
+		SELECT v.test_case_id, v.field_id, v.value
+		FROM test_case_custom_values v
+		JOIN test_cases tc ON tc.id = v.test_case_id
+		WHERE tc.project_id = ? AND tc.deleted_at IS NULL`
+
+	rows, err := r.db.Query(query, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list custom field values: %v", err)
+	}
+	defer rows.Close()
+
+	values := make(map[int64]map[int64]interface{})
+	for rows.Next() {
+		var (
+			testCaseID int64
+			fieldID    int64
+			raw        []byte
+		)
+		if err := rows.Scan(&testCaseID, &fieldID, &raw); err != nil {
+			return nil, fmt.Errorf("failed to scan custom field value: %v", err)
+		}
+
+		var value interface{}
+		if err := json.Unmarshal(raw, &value); err != nil {
+			return nil, fmt.Errorf("failed to decode custom field value: %v", err)
+		}
+		if values[testCaseID] == nil {
+			values[testCaseID] = make(map[int64]interface{})
+		}
+		values[testCaseID][fieldID] = value
+	}
+
+	return values, rows.Err()
+}
+
+// SetValues stores custom field values of a test case. A nil value removes the value of that field;
+// fields that are not listed keep their current value.
+func (r *CustomFieldRepository) SetValues(testCaseID int64, values map[int64]interface{}) error {
+	if len(values) == 0 {
+		return nil
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	for fieldID, value := range values {
+		if value == nil {
+			_, err := tx.Exec("DELETE FROM test_case_custom_values WHERE test_case_id = ? AND field_id = ?", testCaseID, fieldID)
+			if err != nil {
+				return fmt.Errorf("failed to delete custom field value: %v", err)
+			}
+			continue
+		}
+
+		raw, err := json.Marshal(value)
+		if err != nil {
+			return fmt.Errorf("failed to encode custom field value: %v", err)
+		}
+
+		_, err = tx.Exec(`
+			INSERT INTO test_case_custom_values (test_case_id, field_id, value)
+			VALUES (?, ?, ?)
+			ON DUPLICATE KEY UPDATE value = VALUES(value)`,
+			testCaseID, fieldID, raw,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to save custom field value: %v", err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// encodeOptions encodes the allowed values of a field, storing NULL when there are none
+func encodeOptions(options []string) ([]byte, error) {
+	if len(options) == 0 {
+		return nil, nil
+	}
+	encoded, err := json.Marshal(options)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode custom field options: %v", err)
+	}
+	return encoded, nil
+}
+
+// scanCustomFieldDefinition scans a custom field definition row
+func scanCustomFieldDefinition(row rowScanner) (*models.CustomFieldDefinition, error) {
+	definition := &models.CustomFieldDefinition{}
+	var options []byte
+
+	err := row.Scan(
+		&definition.ID,
+		&definition.ProjectID,
+		&definition.Name,
+		&definition.Label,
+		&definition.Type,
+		&options,
+		&definition.Required,
+		&definition.Position,
+		&definition.CreatedBy,
+		&definition.CreatedAt,
+		&definition.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(options) > 0 {
+		if err := json.Unmarshal(options, &definition.Options); err != nil {
+			return nil, err
+		}
+	}
+
+	return definition, nil
+}
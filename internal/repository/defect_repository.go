@@ -0,0 +1,219 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/mihaamiharu/test-case-management-be/internal/models"
+)
+
+var (
+	ErrDefectNotFound      = errors.New("defect not found")
+	ErrDefectAlreadyLinked = errors.New("defect is already linked to an external issue")
+)
+
+// DefectRepositoryInterface defines the interface for defect repository operations
+type DefectRepositoryInterface interface {
+	Create(defect *models.Defect) error
+	GetByID(id int64) (*models.Defect, error)
+	ListByExecution(executionID int64) ([]*models.Defect, error)
+	GetByExternalID(projectID int64, externalID string) (*models.Defect, error)
+	ListLinked(projectID int64) ([]*models.Defect, error)
+	LinkExternalIssue(id int64, externalID, externalURL, externalStatus string) error
+	SyncExternalStatus(id int64, status models.DefectStatus, externalStatus string) error
+}
+
+// DefectRepository handles database operations for defects
+type DefectRepository struct {
+	db *sql.DB
+}
+
+// NewDefectRepository creates a new defect repository
+func NewDefectRepository(db *sql.DB) *DefectRepository {
+	return &DefectRepository{db: db}
+}
+
+// defectQuery selects the columns scanned by scanDefect, with the run, test case and project
+// of the execution that found the defect
+const defectQuery = `
+	SELECT d.id, d.test_execution_id, e.test_run_id, e.test_case_id, tr.project_id, d.title, d.description,
+		d.severity, d.status, d.reported_by, d.assigned_to, d.external_id, d.external_url, d.external_status,
+		d.external_synced_at, d.created_at, d.updated_at
+	FROM defects d
+	JOIN test_executions e ON e.id = d.test_execution_id
+	JOIN test_runs tr ON tr.id = e.test_run_id`
+
+// Create adds a new defect to the database
+func (r *DefectRepository) Create(defect *models.Defect) error {
+	now := time.Now()
+	result, err := r.db.Exec(`
+		INSERT INTO defects (test_execution_id, title, description, severity, status, reported_by, assigned_to, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		defect.TestExecutionID, defect.Title, defect.Description, defect.Severity, defect.Status,
+		defect.ReportedBy, defect.AssignedTo, now, now)
+	if err != nil {
+		return fmt.Errorf("failed to create defect: %v", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to get defect ID: %v", err)
+	}
+
+	defect.ID = id
+	defect.CreatedAt = now
+	defect.UpdatedAt = now
+	return nil
+}
+
+// GetByID retrieves a defect by ID
+func (r *DefectRepository) GetByID(id int64) (*models.Defect, error) {
+	defect, err := scanDefect(r.db.QueryRow(defectQuery+`
+	WHERE d.id = ?`, id))
+	if err == sql.ErrNoRows {
+		return nil, ErrDefectNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get defect: %v", err)
+	}
+	return defect, nil
+}
+
+// ListByExecution retrieves the defects found by an execution, oldest first
+func (r *DefectRepository) ListByExecution(executionID int64) ([]*models.Defect, error) {
+	return r.listDefects(defectQuery+`
+	WHERE d.test_execution_id = ?
+	ORDER BY d.id`, executionID)
+}
+
+// GetByExternalID retrieves the defect of a project that was reported as an external issue
+func (r *DefectRepository) GetByExternalID(projectID int64, externalID string) (*models.Defect, error) {
+	defect, err := scanDefect(r.db.QueryRow(defectQuery+`
+	WHERE tr.project_id = ? AND d.external_id = ?`, projectID, externalID))
+	if err == sql.ErrNoRows {
+		return nil, ErrDefectNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get defect: %v", err)
+	}
+	return defect, nil
+}
+
+// ListLinked retrieves the defects of a project that were reported as external issues and
+// are not closed yet
+func (r *DefectRepository) ListLinked(projectID int64) ([]*models.Defect, error) {
+	return r.listDefects(defectQuery+`
+	WHERE tr.project_id = ? AND d.external_id IS NOT NULL AND d.status <> ?
+	ORDER BY d.id`, projectID, models.DefectStatusClosed)
+}
+
+// LinkExternalIssue records the external issue a defect was reported as, unless the defect
+// was already linked to one
+func (r *DefectRepository) LinkExternalIssue(id int64, externalID, externalURL, externalStatus string) error {
+	now := time.Now()
+	result, err := r.db.Exec(`
+		UPDATE defects
+		SET external_id = ?, external_url = ?, external_status = ?, external_synced_at = ?, updated_at = ?
+		WHERE id = ? AND external_id IS NULL`,
+		externalID, externalURL, externalStatus, now, now, id)
+	if err != nil {
+		return fmt.Errorf("failed to link defect: %v", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %v", err)
+	}
+	if rows == 0 {
+		return ErrDefectAlreadyLinked
+	}
+	return nil
+}
+
+// SyncExternalStatus records the status of the external issue of a defect and the defect
+// status it maps to. The defect only counts as updated when either status changed.
+func (r *DefectRepository) SyncExternalStatus(id int64, status models.DefectStatus, externalStatus string) error {
+	now := time.Now()
+	// MySQL assigns from left to right, so updated_at compares the statuses before the sync
+	_, err := r.db.Exec(`
+		UPDATE defects
+		SET updated_at = IF(status <> ? OR NOT (external_status <=> ?), ?, updated_at),
+			status = ?, external_status = ?, external_synced_at = ?
+		WHERE id = ?`, status, externalStatus, now, status, externalStatus, now, id)
+	if err != nil {
+		return fmt.Errorf("failed to sync defect status: %v", err)
+	}
+	return nil
+}
+
+// listDefects runs a query of defectQuery
+func (r *DefectRepository) listDefects(query string, args ...interface{}) ([]*models.Defect, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list defects: %v", err)
+	}
+	defer rows.Close()
+
+	var defects []*models.Defect
+	for rows.Next() {
+		defect, err := scanDefect(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan defect: %v", err)
+		}
+		defects = append(defects, defect)
+	}
+	return defects, rows.Err()
+}
+
+// scanDefect scans a row of defectQuery
+func scanDefect(row rowScanner) (*models.Defect, error) {
+	defect := &models.Defect{}
+	var (
+		assignedTo     sql.NullInt64
+		externalID     sql.NullString
+		externalURL    sql.NullString
+		externalStatus sql.NullString
+		externalSynced sql.NullTime
+	)
+	err := row.Scan(
+		&defect.ID,
+		&defect.TestExecutionID,
+		&defect.TestRunID,
+		&defect.TestCaseID,
+		&defect.ProjectID,
+		&defect.Title,
+		&defect.Description,
+		&defect.Severity,
+		&defect.Status,
+		&defect.ReportedBy,
+		&assignedTo,
+		&externalID,
+		&externalURL,
+		&externalStatus,
+		&externalSynced,
+		&defect.CreatedAt,
+		&defect.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if assignedTo.Valid {
+		defect.AssignedTo = &assignedTo.Int64
+	}
+	if externalID.Valid {
+		defect.ExternalID = &externalID.String
+	}
+	if externalURL.Valid {
+		defect.ExternalURL = &externalURL.String
+	}
+	if externalStatus.Valid {
+		defect.ExternalStatus = &externalStatus.String
+	}
+	if externalSynced.Valid {
+		defect.ExternalSyncedAt = &externalSynced.Time
+	}
+	return defect, nil
+}
@@ -0,0 +1,127 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/mihaamiharu/test-case-management-be/internal/models"
+)
+
+var (
+	ErrIssueTrackerNotFound = errors.New("issue tracker not found")
+)
+
+// IssueTrackerRepositoryInterface defines the interface for issue tracker repository operations
+type IssueTrackerRepositoryInterface interface {
+	Get(projectID int64) (*models.IssueTracker, error)
+	List() ([]*models.IssueTracker, error)
+	Save(tracker *models.IssueTracker) error
+	Delete(projectID int64) error
+}
+
+// IssueTrackerRepository handles database operations for the issue trackers of projects
+type IssueTrackerRepository struct {
+	db *sql.DB
+}
+
+// NewIssueTrackerRepository creates a new issue tracker repository
+func NewIssueTrackerRepository(db *sql.DB) *IssueTrackerRepository {
+	return &IssueTrackerRepository{db: db}
+}
+
+// issueTrackerColumns are the columns scanned by scanIssueTracker
+const issueTrackerColumns = `project_id, provider, base_url, project_key, username, token, webhook_secret,
+	created_by, created_at, updated_at`
+
+// Get retrieves the issue tracker of a project
+func (r *IssueTrackerRepository) Get(projectID int64) (*models.IssueTracker, error) {
+	tracker, err := scanIssueTracker(r.db.QueryRow("SELECT "+issueTrackerColumns+" FROM project_issue_trackers WHERE project_id = ?", projectID))
+	if err == sql.ErrNoRows {
+		return nil, ErrIssueTrackerNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get issue tracker: %v", err)
+	}
+	return tracker, nil
+}
+
+// List retrieves the issue trackers of every project
+func (r *IssueTrackerRepository) List() ([]*models.IssueTracker, error) {
+	rows, err := r.db.Query("SELECT " + issueTrackerColumns + " FROM project_issue_trackers ORDER BY project_id")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list issue trackers: %v", err)
+	}
+	defer rows.Close()
+
+	var trackers []*models.IssueTracker
+	for rows.Next() {
+		tracker, err := scanIssueTracker(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan issue tracker: %v", err)
+		}
+		trackers = append(trackers, tracker)
+	}
+	return trackers, rows.Err()
+}
+
+// Save connects a project to an issue tracker, replacing the one it had
+func (r *IssueTrackerRepository) Save(tracker *models.IssueTracker) error {
+	now := time.Now()
+	_, err := r.db.Exec(`
+		INSERT INTO project_issue_trackers
+			(project_id, provider, base_url, project_key, username, token, webhook_secret, created_by, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE provider = VALUES(provider), base_url = VALUES(base_url), project_key = VALUES(project_key),
+			username = VALUES(username), token = VALUES(token), webhook_secret = VALUES(webhook_secret), updated_at = VALUES(updated_at)`,
+		tracker.ProjectID, tracker.Provider, tracker.BaseURL, tracker.ProjectKey, tracker.Username, tracker.Token,
+		tracker.WebhookSecret, tracker.CreatedBy, now, now)
+	if err != nil {
+		return fmt.Errorf("failed to save issue tracker: %v", err)
+	}
+
+	if tracker.CreatedAt.IsZero() {
+		tracker.CreatedAt = now
+	}
+	tracker.UpdatedAt = now
+	return nil
+}
+
+// Delete disconnects a project from its issue tracker
+func (r *IssueTrackerRepository) Delete(projectID int64) error {
+	result, err := r.db.Exec("DELETE FROM project_issue_trackers WHERE project_id = ?", projectID)
+	if err != nil {
+		return fmt.Errorf("failed to delete issue tracker: %v", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %v", err)
+	}
+	if rows == 0 {
+		return ErrIssueTrackerNotFound
+	}
+	return nil
+}
+
+// scanIssueTracker scans a row of issueTrackerColumns
+func scanIssueTracker(row rowScanner) (*models.IssueTracker, error) {
+	tracker := &models.IssueTracker{}
+	err := row.Scan(
+		&tracker.ProjectID,
+		&tracker.Provider,
+		&tracker.BaseURL,
+		&tracker.ProjectKey,
+		&tracker.Username,
+		&tracker.Token,
+		&tracker.WebhookSecret,
+		&tracker.CreatedBy,
+		&tracker.CreatedAt,
+		&tracker.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return tracker, nil
+}
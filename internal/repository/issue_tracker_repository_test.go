@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/mihaamiharu/test-case-management-be/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIssueTrackerRepository_Save(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewIssueTrackerRepository(db)
+	userID := int64(7)
+	tracker := &models.IssueTracker{ProjectID: 1, Provider: models.IssueTrackerGitHub, ProjectKey: "acme/shop", Token: "tok", CreatedBy: &userID}
+
+	// Test case: a project has one tracker, which is replaced on the next save
+	mock.ExpectExec("INSERT INTO project_issue_trackers .* ON DUPLICATE KEY UPDATE provider = VALUES\\(provider\\)").
+		WithArgs(1, models.IssueTrackerGitHub, "", "acme/shop", "", "tok", "", &userID, sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err = repo.Save(tracker)
+
+	require.NoError(t, err)
+	assert.False(t, tracker.CreatedAt.IsZero())
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDefectRepository_LinkExternalIssue(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewDefectRepository(db)
+	query := regexp.QuoteMeta("WHERE id = ? AND external_id IS NULL")
+
+	// Test case: an unlinked defect is linked to its issue
+	mock.ExpectExec(query).
+		WithArgs("QA-7", "https://example.atlassian.net/browse/QA-7", "", sqlmock.AnyArg(), sqlmock.AnyArg(), 3).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	assert.NoError(t, repo.LinkExternalIssue(3, "QA-7", "https://example.atlassian.net/browse/QA-7", ""))
+
+	// Test case: a defect that is already linked keeps its issue
+	mock.ExpectExec(query).
+		WithArgs("QA-8", "https://example.atlassian.net/browse/QA-8", "", sqlmock.AnyArg(), sqlmock.AnyArg(), 3).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	assert.ErrorIs(t, repo.LinkExternalIssue(3, "QA-8", "https://example.atlassian.net/browse/QA-8", ""), ErrDefectAlreadyLinked)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,127 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/mihaamiharu/test-case-management-be/internal/models"
+)
+
+var (
+	ErrLoginThrottleNotFound = errors.New("login throttle not found")
+)
+
+// LoginThrottleRepositoryInterface defines the interface for login throttle repository operations
+type LoginThrottleRepositoryInterface interface {
+	Get(scope models.LoginThrottleScope, subject string) (*models.LoginThrottle, error)
+	RecordFailure(scope models.LoginThrottleScope, subject string, windowStart time.Time) (*models.LoginThrottle, error)
+	Lock(scope models.LoginThrottleScope, subject string, until time.Time) error
+	Reset(scope models.LoginThrottleScope, subject string) error
+}
+
+// LoginThrottleRepository handles database operations for failed login tracking
+type LoginThrottleRepository struct {
+	db *sql.DB
+}
+
+// NewLoginThrottleRepository creates a new login throttle repository
+func NewLoginThrottleRepository(db *sql.DB) *LoginThrottleRepository {
+	return &LoginThrottleRepository{db: db}
+}
+
+// Get retrieves the failed logins of a subject
+func (r *LoginThrottleRepository) Get(scope models.LoginThrottleScope, subject string) (*models.LoginThrottle, error) {
+	throttle, err := getLoginThrottle(r.db, scope, subject)
+	if err == sql.ErrNoRows {
+		return nil, ErrLoginThrottleNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get login throttle: %v", err)
+	}
+	return throttle, nil
+}
+
+// RecordFailure counts a failed login of a subject and returns its failures. Failures
+// before windowStart are forgotten, and so are expired entries of other subjects.
+func (r *LoginThrottleRepository) RecordFailure(scope models.LoginThrottleScope, subject string, windowStart time.Time) (*models.LoginThrottle, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	_, err = tx.Exec("DELETE FROM login_throttles WHERE last_failure_at < ? AND (locked_until IS NULL OR locked_until < ?)",
+		windowStart, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to clean up login throttles: %v", err)
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO login_throttles (scope, subject, failures, last_failure_at) VALUES (?, ?, 1, ?)
+		ON DUPLICATE KEY UPDATE
+			failures = IF(last_failure_at < ?, 1, failures + 1),
+			last_failure_at = VALUES(last_failure_at)`,
+		scope, subject, now, windowStart)
+	if err != nil {
+		return nil, fmt.Errorf("failed to record failed login: %v", err)
+	}
+
+	throttle, err := getLoginThrottle(tx, scope, subject)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get login throttle: %v", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %v", err)
+	}
+	return throttle, nil
+}
+
+// Lock locks a subject out until a time. Its failures start again from zero afterwards.
+func (r *LoginThrottleRepository) Lock(scope models.LoginThrottleScope, subject string, until time.Time) error {
+	_, err := r.db.Exec("UPDATE login_throttles SET failures = 0, locked_until = ? WHERE scope = ? AND subject = ?",
+		until, scope, subject)
+	if err != nil {
+		return fmt.Errorf("failed to lock login: %v", err)
+	}
+	return nil
+}
+
+// Reset forgets the failed logins and lockout of a subject
+func (r *LoginThrottleRepository) Reset(scope models.LoginThrottleScope, subject string) error {
+	_, err := r.db.Exec("DELETE FROM login_throttles WHERE scope = ? AND subject = ?", scope, subject)
+	if err != nil {
+		return fmt.Errorf("failed to reset login throttle: %v", err)
+	}
+	return nil
+}
+
+// queryRower is implemented by *sql.DB and *sql.Tx
+type queryRower interface {
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
+// getLoginThrottle retrieves the failed logins of a subject
+func getLoginThrottle(q queryRower, scope models.LoginThrottleScope, subject string) (*models.LoginThrottle, error) {
+	query := `
+		SELECT id, scope, subject, failures, last_failure_at, locked_until
+		FROM login_throttles
+		WHERE scope = ? AND subject = ?`
+
+	throttle := &models.LoginThrottle{}
+	err := q.QueryRow(query, scope, subject).Scan(
+		&throttle.ID,
+		&throttle.Scope,
+		&throttle.Subject,
+		&throttle.Failures,
+		&throttle.LastFailureAt,
+		&throttle.LockedUntil,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return throttle, nil
+}
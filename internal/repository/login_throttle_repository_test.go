@@ -0,0 +1,44 @@
+package repository
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/mihaamiharu/test-case-management-be/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoginThrottleRepository_RecordFailure(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewLoginThrottleRepository(db)
+	now := time.Now()
+	windowStart := now.Add(-15 * time.Minute)
+
+	// Test case: expired entries are removed and the failure restarts its count outside the window
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM login_throttles WHERE last_failure_at < ?")).
+		WithArgs(windowStart, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec(regexp.QuoteMeta("failures = IF(last_failure_at < ?, 1, failures + 1)")).
+		WithArgs(models.LoginThrottleAccount, "alice@example.com", sqlmock.AnyArg(), windowStart).
+		WillReturnResult(sqlmock.NewResult(1, 2))
+	mock.ExpectQuery("SELECT (.+) FROM login_throttles WHERE scope = \\? AND subject = \\?").
+		WithArgs(models.LoginThrottleAccount, "alice@example.com").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "scope", "subject", "failures", "last_failure_at", "locked_until"}).
+			AddRow(1, "account", "alice@example.com", 3, now, nil))
+	mock.ExpectCommit()
+
+	throttle, err := repo.RecordFailure(models.LoginThrottleAccount, "alice@example.com", windowStart)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 3, throttle.Failures)
+	assert.Nil(t, throttle.LockedUntil)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
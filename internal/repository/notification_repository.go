@@ -0,0 +1,254 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mihaamiharu/test-case-management-be/internal/models"
+)
+
+var (
+	ErrNotificationNotFound = errors.New("notification not found")
+)
+
+// NotificationRepositoryInterface defines the interface for notification repository operations
+type NotificationRepositoryInterface interface {
+	Create(notification *models.Notification) error
+	List(organizationID, userID int64, filter *models.NotificationFilter) ([]*models.Notification, error)
+	CountUnread(organizationID, userID int64) (int, error)
+	SetRead(organizationID, userID, id int64, read bool) error
+	MarkAllRead(organizationID, userID int64) (int64, error)
+	ListDigest() ([]*models.Notification, error)
+	MarkEmailed(ids []int64) error
+	GetPreferences(userID int64) ([]*models.NotificationPreference, error)
+	SavePreferences(userID int64, preferences []models.NotificationPreference) error
+}
+
+// NotificationRepository handles database operations for notifications and notification preferences
+type NotificationRepository struct {
+	db *sql.DB
+}
+
+// NewNotificationRepository creates a new notification repository
+func NewNotificationRepository(db *sql.DB) *NotificationRepository {
+	return &NotificationRepository{db: db}
+}
+
+// notificationColumns are the columns scanned by scanNotification
+const notificationColumns = `n.id, n.user_id, n.organization_id, n.project_id, n.type, n.title, n.entity_type, n.entity_id,
+	n.actor_id, n.read_at, n.emailed_at, n.created_at`
+
+// Create adds a new notification to the database
+func (r *NotificationRepository) Create(notification *models.Notification) error {
+	now := time.Now()
+	result, err := r.db.Exec(`
+		INSERT INTO notifications (user_id, organization_id, project_id, type, title, entity_type, entity_id, actor_id, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		notification.UserID, notification.OrganizationID, notification.ProjectID, notification.Type, notification.Title,
+		notification.EntityType, notification.EntityID, notification.ActorID, now)
+	if err != nil {
+		return fmt.Errorf("failed to create notification: %v", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to get notification ID: %v", err)
+	}
+
+	notification.ID = id
+	notification.CreatedAt = now
+	return nil
+}
+
+// List retrieves a page of the notifications of a user in an organization, newest first
+func (r *NotificationRepository) List(organizationID, userID int64, filter *models.NotificationFilter) ([]*models.Notification, error) {
+	query := "SELECT " + notificationColumns + " FROM notifications n WHERE n.user_id = ? AND n.organization_id = ?"
+	args := []interface{}{userID, organizationID}
+	if filter.UnreadOnly {
+		query += " AND n.read_at IS NULL"
+	}
+	query += " ORDER BY n.id DESC LIMIT ? OFFSET ?"
+	args = append(args, filter.Limit, filter.Offset)
+
+	return r.listNotifications(query, args...)
+}
+
+// CountUnread counts the unread notifications of a user in an organization
+func (r *NotificationRepository) CountUnread(organizationID, userID int64) (int, error) {
+	var count int
+	err := r.db.QueryRow("SELECT COUNT(*) FROM notifications WHERE user_id = ? AND organization_id = ? AND read_at IS NULL",
+		userID, organizationID).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count unread notifications: %v", err)
+	}
+	return count, nil
+}
+
+// SetRead marks a notification of a user in an organization as read or unread
+func (r *NotificationRepository) SetRead(organizationID, userID, id int64, read bool) error {
+	var count int
+	err := r.db.QueryRow("SELECT COUNT(*) FROM notifications WHERE id = ? AND user_id = ? AND organization_id = ?",
+		id, userID, organizationID).Scan(&count)
+	if err != nil {
+		return fmt.Errorf("failed to check notification existence: %v", err)
+	}
+	if count == 0 {
+		return ErrNotificationNotFound
+	}
+
+	if read {
+		// Keep the time a notification was first read
+		_, err = r.db.Exec("UPDATE notifications SET read_at = COALESCE(read_at, ?) WHERE id = ?", time.Now(), id)
+	} else {
+		_, err = r.db.Exec("UPDATE notifications SET read_at = NULL WHERE id = ?", id)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update notification: %v", err)
+	}
+	return nil
+}
+
+// MarkAllRead marks every unread notification of a user in an organization as read and
+// returns how many it marked
+func (r *NotificationRepository) MarkAllRead(organizationID, userID int64) (int64, error) {
+	result, err := r.db.Exec("UPDATE notifications SET read_at = ? WHERE user_id = ? AND organization_id = ? AND read_at IS NULL",
+		time.Now(), userID, organizationID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to mark notifications read: %v", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get affected rows: %v", err)
+	}
+	return rows, nil
+}
+
+// ListDigest retrieves the unread notifications that were not emailed yet and whose type
+// their user wants in the email digest, ordered by user
+func (r *NotificationRepository) ListDigest() ([]*models.Notification, error) {
+	return r.listNotifications("SELECT " + notificationColumns + `
+		FROM notifications n
+		LEFT JOIN notification_preferences p ON p.user_id = n.user_id AND p.type = n.type
+		WHERE n.emailed_at IS NULL AND n.read_at IS NULL AND COALESCE(p.email_digest, TRUE)
+		ORDER BY n.user_id, n.id`)
+}
+
+// MarkEmailed records that notifications were sent in an email digest
+func (r *NotificationRepository) MarkEmailed(ids []int64) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(ids)), ", ")
+	args := []interface{}{time.Now()}
+	for _, id := range ids {
+		args = append(args, id)
+	}
+	_, err := r.db.Exec("UPDATE notifications SET emailed_at = ? WHERE id IN ("+placeholders+")", args...)
+	if err != nil {
+		return fmt.Errorf("failed to mark notifications emailed: %v", err)
+	}
+	return nil
+}
+
+// GetPreferences retrieves the notification preferences a user has saved
+func (r *NotificationRepository) GetPreferences(userID int64) ([]*models.NotificationPreference, error) {
+	rows, err := r.db.Query(`
+		SELECT type, in_app, email_digest
+		FROM notification_preferences
+		WHERE user_id = ?
+		ORDER BY type`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get notification preferences: %v", err)
+	}
+	defer rows.Close()
+
+	var preferences []*models.NotificationPreference
+	for rows.Next() {
+		preference := &models.NotificationPreference{}
+		if err := rows.Scan(&preference.Type, &preference.InApp, &preference.EmailDigest); err != nil {
+			return nil, fmt.Errorf("failed to scan notification preference: %v", err)
+		}
+		preferences = append(preferences, preference)
+	}
+	return preferences, rows.Err()
+}
+
+// SavePreferences stores notification preferences of a user, replacing those of the same types
+func (r *NotificationRepository) SavePreferences(userID int64, preferences []models.NotificationPreference) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	for _, preference := range preferences {
+		_, err := tx.Exec(`
+			INSERT INTO notification_preferences (user_id, type, in_app, email_digest, updated_at)
+			VALUES (?, ?, ?, ?, ?)
+			ON DUPLICATE KEY UPDATE in_app = VALUES(in_app), email_digest = VALUES(email_digest), updated_at = VALUES(updated_at)`,
+			userID, preference.Type, preference.InApp, preference.EmailDigest, time.Now())
+		if err != nil {
+			return fmt.Errorf("failed to save notification preference: %v", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %v", err)
+	}
+	return nil
+}
+
+// listNotifications runs a query of notificationColumns
+func (r *NotificationRepository) listNotifications(query string, args ...interface{}) ([]*models.Notification, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list notifications: %v", err)
+	}
+	defer rows.Close()
+
+	var notifications []*models.Notification
+	for rows.Next() {
+		notification, err := scanNotification(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan notification: %v", err)
+		}
+		notifications = append(notifications, notification)
+	}
+	return notifications, rows.Err()
+}
+
+// scanNotification scans a row of notificationColumns
+func scanNotification(row rowScanner) (*models.Notification, error) {
+	notification := &models.Notification{}
+	var readAt, emailedAt sql.NullTime
+	err := row.Scan(
+		&notification.ID,
+		&notification.UserID,
+		&notification.OrganizationID,
+		&notification.ProjectID,
+		&notification.Type,
+		&notification.Title,
+		&notification.EntityType,
+		&notification.EntityID,
+		&notification.ActorID,
+		&readAt,
+		&emailedAt,
+		&notification.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if readAt.Valid {
+		notification.ReadAt = &readAt.Time
+	}
+	if emailedAt.Valid {
+		notification.EmailedAt = &emailedAt.Time
+	}
+	return notification, nil
+}
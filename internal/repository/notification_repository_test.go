@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNotificationRepository_SetRead(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewNotificationRepository(db)
+
+	// Test case: reading keeps the time a notification was first read
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT COUNT(*) FROM notifications WHERE id = ? AND user_id = ? AND organization_id = ?")).
+		WithArgs(3, 1, 5).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE notifications SET read_at = COALESCE(read_at, ?) WHERE id = ?")).
+		WithArgs(sqlmock.AnyArg(), 3).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	assert.NoError(t, repo.SetRead(5, 1, 3, true))
+
+	// Test case: notifications can be marked unread again
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT COUNT(*) FROM notifications WHERE id = ? AND user_id = ? AND organization_id = ?")).
+		WithArgs(3, 1, 5).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE notifications SET read_at = NULL WHERE id = ?")).
+		WithArgs(3).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	assert.NoError(t, repo.SetRead(5, 1, 3, false))
+
+	// Test case: notifications of other users are not found
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT COUNT(*) FROM notifications WHERE id = ? AND user_id = ? AND organization_id = ?")).
+		WithArgs(3, 2, 5).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+
+	assert.Equal(t, ErrNotificationNotFound, repo.SetRead(5, 2, 3, true))
+
+	// Test case: notifications of other organizations are not found
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT COUNT(*) FROM notifications WHERE id = ? AND user_id = ? AND organization_id = ?")).
+		WithArgs(3, 1, 6).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+
+	assert.Equal(t, ErrNotificationNotFound, repo.SetRead(6, 1, 3, true))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestNotificationRepository_MarkEmailed(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewNotificationRepository(db)
+
+	// Test case: every notification of a digest is marked in one statement
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE notifications SET emailed_at = ? WHERE id IN (?, ?, ?)")).
+		WithArgs(sqlmock.AnyArg(), 4, 5, 9).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+
+	assert.NoError(t, repo.MarkEmailed([]int64{4, 5, 9}))
+
+	// Test case: an empty digest needs no statement
+	assert.NoError(t, repo.MarkEmailed(nil))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,325 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/mihaamiharu/test-case-management-be/internal/models"
+)
+
+var (
+	ErrOrganizationNotFound       = errors.New("organization not found")
+	ErrOrganizationExists         = errors.New("organization with this slug already exists")
+	ErrOrganizationMemberNotFound = errors.New("user is not a member of this organization")
+	ErrOrganizationMemberExists   = errors.New("user already is a member of this organization")
+)
+
+// OrganizationRepositoryInterface defines the interface for organization repository operations
+type OrganizationRepositoryInterface interface {
+	Create(organization *models.Organization) error
+	GetByID(id int64) (*models.Organization, error)
+	Update(organization *models.Organization) error
+	ListByUser(userID int64) ([]*models.Organization, error)
+	GetMember(organizationID, userID int64) (*models.OrganizationMember, error)
+	AddMember(member *models.OrganizationMember) error
+	UpdateMemberRole(organizationID, userID int64, role models.OrganizationRole) error
+	RemoveMember(organizationID, userID int64) error
+	ListMembers(organizationID int64) ([]*models.OrganizationMember, error)
+	CountAdmins(organizationID int64) (int, error)
+}
+
+// OrganizationRepository handles database operations for organizations and their members
+type OrganizationRepository struct {
+	db *sql.DB
+}
+
+// NewOrganizationRepository creates a new organization repository
+func NewOrganizationRepository(db *sql.DB) *OrganizationRepository {
+	return &OrganizationRepository{db: db}
+}
+
+// organizationMemberColumns are the columns scanned by scanOrganizationMember
+const organizationMemberColumns = `m.organization_id, m.user_id, u.username, u.email, m.role, m.created_at`
+
+// Create adds a new organization to the database and makes its creator an admin of it
+func (r *OrganizationRepository) Create(organization *models.Organization) error {
+	var count int
+	err := r.db.QueryRow("SELECT COUNT(*) FROM organizations WHERE slug = ?", organization.Slug).Scan(&count)
+	if err != nil {
+		return fmt.Errorf("failed to check organization slug: %v", err)
+	}
+	if count > 0 {
+		return ErrOrganizationExists
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	result, err := tx.Exec(`
+		INSERT INTO organizations (name, slug, created_by, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?)`,
+		organization.Name, organization.Slug, organization.CreatedBy, now, now)
+	if err != nil {
+		return fmt.Errorf("failed to create organization: %v", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to get organization ID: %v", err)
+	}
+
+	if organization.CreatedBy != nil {
+		_, err = tx.Exec(`
+			INSERT INTO organization_members (organization_id, user_id, role, created_at)
+			VALUES (?, ?, ?, ?)`,
+			id, *organization.CreatedBy, models.OrganizationRoleAdmin, now)
+		if err != nil {
+			return fmt.Errorf("failed to add organization admin: %v", err)
+		}
+		organization.Role = models.OrganizationRoleAdmin
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %v", err)
+	}
+
+	organization.ID = id
+	organization.CreatedAt = now
+	organization.UpdatedAt = now
+	return nil
+}
+
+// GetByID retrieves an organization by ID
+func (r *OrganizationRepository) GetByID(id int64) (*models.Organization, error) {
+	organization := &models.Organization{}
+	err := r.db.QueryRow(`
+		SELECT id, name, slug, created_by, created_at, updated_at
+		FROM organizations
+		WHERE id = ?`, id).Scan(
+		&organization.ID,
+		&organization.Name,
+		&organization.Slug,
+		&organization.CreatedBy,
+		&organization.CreatedAt,
+		&organization.UpdatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, ErrOrganizationNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get organization: %v", err)
+	}
+	return organization, nil
+}
+
+// Update renames an organization
+func (r *OrganizationRepository) Update(organization *models.Organization) error {
+	now := time.Now()
+	result, err := r.db.Exec("UPDATE organizations SET name = ?, updated_at = ? WHERE id = ?",
+		organization.Name, now, organization.ID)
+	if err != nil {
+		return fmt.Errorf("failed to update organization: %v", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %v", err)
+	}
+	if rows == 0 {
+		return ErrOrganizationNotFound
+	}
+
+	organization.UpdatedAt = now
+	return nil
+}
+
+// ListByUser retrieves the organizations of a user with its role in each, oldest first
+func (r *OrganizationRepository) ListByUser(userID int64) ([]*models.Organization, error) {
+	rows, err := r.db.Query(`
+		SELECT o.id, o.name, o.slug, o.created_by, m.role, o.created_at, o.updated_at
+		FROM organizations o
+		JOIN organization_members m ON m.organization_id = o.id
+		WHERE m.user_id = ?
+		ORDER BY o.id`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list organizations: %v", err)
+	}
+	defer rows.Close()
+
+	var organizations []*models.Organization
+	for rows.Next() {
+		organization := &models.Organization{}
+		err := rows.Scan(
+			&organization.ID,
+			&organization.Name,
+			&organization.Slug,
+			&organization.CreatedBy,
+			&organization.Role,
+			&organization.CreatedAt,
+			&organization.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan organization: %v", err)
+		}
+		organizations = append(organizations, organization)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list organizations: %v", err)
+	}
+	return organizations, nil
+}
+
+// GetMember retrieves the membership of a user in an organization
+func (r *OrganizationRepository) GetMember(organizationID, userID int64) (*models.OrganizationMember, error) {
+	query := "SELECT " + organizationMemberColumns + `
+		FROM organization_members m
+		JOIN users u ON u.id = m.user_id
+		WHERE m.organization_id = ? AND m.user_id = ?`
+
+	member, err := scanOrganizationMember(r.db.QueryRow(query, organizationID, userID))
+	if err == sql.ErrNoRows {
+		return nil, ErrOrganizationMemberNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get organization member: %v", err)
+	}
+	return member, nil
+}
+
+// AddMember adds a user to an organization
+func (r *OrganizationRepository) AddMember(member *models.OrganizationMember) error {
+	var count int
+	err := r.db.QueryRow("SELECT COUNT(*) FROM organization_members WHERE organization_id = ? AND user_id = ?",
+		member.OrganizationID, member.UserID).Scan(&count)
+	if err != nil {
+		return fmt.Errorf("failed to check organization member: %v", err)
+	}
+	if count > 0 {
+		return ErrOrganizationMemberExists
+	}
+
+	now := time.Now()
+	_, err = r.db.Exec(`
+		INSERT INTO organization_members (organization_id, user_id, role, created_at)
+		VALUES (?, ?, ?, ?)`,
+		member.OrganizationID, member.UserID, member.Role, now)
+	if err != nil {
+		return fmt.Errorf("failed to add organization member: %v", err)
+	}
+
+	member.CreatedAt = now
+	return nil
+}
+
+// UpdateMemberRole changes the role of a user in an organization
+func (r *OrganizationRepository) UpdateMemberRole(organizationID, userID int64, role models.OrganizationRole) error {
+	result, err := r.db.Exec("UPDATE organization_members SET role = ? WHERE organization_id = ? AND user_id = ?",
+		role, organizationID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to update organization member: %v", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %v", err)
+	}
+	if rows == 0 {
+		// The role may already be the requested one
+		if _, err := r.GetMember(organizationID, userID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RemoveMember removes a user from an organization together with its project grants and
+// team memberships in the organization. Projects it owns stay in the organization.
+func (r *OrganizationRepository) RemoveMember(organizationID, userID int64) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec("DELETE FROM organization_members WHERE organization_id = ? AND user_id = ?",
+		organizationID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to remove organization member: %v", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %v", err)
+	}
+	if rows == 0 {
+		return ErrOrganizationMemberNotFound
+	}
+
+	if _, err := tx.Exec(`
+		DELETE pa FROM project_access pa
+		JOIN projects p ON p.id = pa.project_id
+		WHERE p.organization_id = ? AND pa.user_id = ?`, organizationID, userID); err != nil {
+		return fmt.Errorf("failed to remove project access: %v", err)
+	}
+	if _, err := tx.Exec(`
+		DELETE tm FROM team_members tm
+		JOIN teams t ON t.id = tm.team_id
+		WHERE t.organization_id = ? AND tm.user_id = ?`, organizationID, userID); err != nil {
+		return fmt.Errorf("failed to remove team memberships: %v", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %v", err)
+	}
+	return nil
+}
+
+// ListMembers retrieves the members of an organization ordered by username
+func (r *OrganizationRepository) ListMembers(organizationID int64) ([]*models.OrganizationMember, error) {
+	rows, err := r.db.Query("SELECT "+organizationMemberColumns+`
+		FROM organization_members m
+		JOIN users u ON u.id = m.user_id
+		WHERE m.organization_id = ?
+		ORDER BY u.username`, organizationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list organization members: %v", err)
+	}
+	defer rows.Close()
+
+	var members []*models.OrganizationMember
+	for rows.Next() {
+		member, err := scanOrganizationMember(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan organization member: %v", err)
+		}
+		members = append(members, member)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list organization members: %v", err)
+	}
+	return members, nil
+}
+
+// CountAdmins counts the admins of an organization
+func (r *OrganizationRepository) CountAdmins(organizationID int64) (int, error) {
+	var count int
+	err := r.db.QueryRow("SELECT COUNT(*) FROM organization_members WHERE organization_id = ? AND role = ?",
+		organizationID, models.OrganizationRoleAdmin).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count organization admins: %v", err)
+	}
+	return count, nil
+}
+
+// scanOrganizationMember scans a row of organizationMemberColumns
+func scanOrganizationMember(row rowScanner) (*models.OrganizationMember, error) {
+	member := &models.OrganizationMember{}
+	err := row.Scan(&member.OrganizationID, &member.UserID, &member.Username, &member.Email, &member.Role, &member.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return member, nil
+}
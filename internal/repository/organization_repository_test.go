@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/mihaamiharu/test-case-management-be/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOrganizationRepository_Create(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewOrganizationRepository(db)
+
+	// Test case: the organization and its creator as admin are added in one transaction
+	creatorID := int64(2)
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT COUNT(*) FROM organizations WHERE slug = ?")).
+		WithArgs("acme").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO organizations").
+		WithArgs("Acme", "acme", &creatorID, sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(4, 1))
+	mock.ExpectExec("INSERT INTO organization_members").
+		WithArgs(4, 2, models.OrganizationRoleAdmin, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	organization := &models.Organization{Name: "Acme", Slug: "acme", CreatedBy: &creatorID}
+	err = repo.Create(organization)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(4), organization.ID)
+	assert.Equal(t, models.OrganizationRoleAdmin, organization.Role)
+
+	// Test case: slugs are unique
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT COUNT(*) FROM organizations WHERE slug = ?")).
+		WithArgs("acme").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+
+	err = repo.Create(&models.Organization{Name: "Acme", Slug: "acme"})
+
+	assert.Equal(t, ErrOrganizationExists, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestOrganizationRepository_RemoveMember(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewOrganizationRepository(db)
+
+	// Test case: the project grants and team memberships of the member go with it
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM organization_members WHERE organization_id = ? AND user_id = ?")).
+		WithArgs(1, 3).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("DELETE pa FROM project_access pa").
+		WithArgs(1, 3).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec("DELETE tm FROM team_members tm").
+		WithArgs(1, 3).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	assert.NoError(t, repo.RemoveMember(1, 3))
+
+	// Test case: users who are not members are reported
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM organization_members WHERE organization_id = ? AND user_id = ?")).
+		WithArgs(1, 9).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	assert.Equal(t, ErrOrganizationMemberNotFound, repo.RemoveMember(1, 9))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
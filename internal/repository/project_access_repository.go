@@ -23,8 +23,6 @@ type ProjectAccessRepositoryInterface interface {
 	ListByProject(projectID int64) ([]*models.ProjectAccess, error)
 	ListByUser(userID int64) ([]*models.ProjectAccess, error)
 	GetProjectIDsByUserAccess(userID int64) ([]int64, error)
-	HasEditAccess(projectID, userID int64) (bool, error)
-	HasViewAccess(projectID, userID int64) (bool, error)
 }
 
 // ProjectAccessRepository handles database operations for project access
@@ -265,33 +263,3 @@ func (r *ProjectAccessRepository) GetProjectIDsByUserAccess(userID int64) ([]int
 
 	return projectIDs, nil
 }
-
-// HasEditAccess checks if a user has edit access to a project
-func (r *ProjectAccessRepository) HasEditAccess(projectID, userID int64) (bool, error) {
-	query := `
-		SELECT COUNT(*)
-		FROM project_access
-		WHERE project_id = ? AND user_id = ? AND level = 'edit'
-	`
-	var count int
-	err := r.db.QueryRow(query, projectID, userID).Scan(&count)
-	if err != nil {
-		return false, err
-	}
-	return count > 0, nil
-}
-
-// HasViewAccess checks if a user has at least view access to a project
-func (r *ProjectAccessRepository) HasViewAccess(projectID, userID int64) (bool, error) {
-	query := `
-		SELECT COUNT(*)
-		FROM project_access
-		WHERE project_id = ? AND user_id = ?
-	`
-	var count int
-	err := r.db.QueryRow(query, projectID, userID).Scan(&count)
-	if err != nil {
-		return false, err
-	}
-	return count > 0, nil
-}
@@ -19,9 +19,12 @@ type ProjectRepositoryInterface interface {
 	GetByID(id int64) (*models.Project, error)
 	Update(project *models.Project) error
 	Delete(id int64) error
-	ListByOwner(ownerID int64) ([]*models.Project, error)
-	List(page, pageSize int) ([]*models.Project, error)
+	ListByOwner(organizationID, ownerID int64, includeArchived bool) ([]*models.Project, error)
+	List(organizationID int64, page, pageSize int, includeArchived bool) ([]*models.Project, error)
 	IsOwner(projectID, userID int64) (bool, error)
+	GetOrganizationID(id int64) (int64, error)
+	SetArchived(id int64, archived bool) error
+	TransferOwnership(id, newOwnerID int64, previousOwnerLevel models.AccessLevel) error
 }
 
 // ProjectRepository handles database operations for projects
@@ -34,12 +37,15 @@ func NewProjectRepository(db *sql.DB) *ProjectRepository {
 	return &ProjectRepository{db: db}
 }
 
+// projectColumns are the columns scanned by scanProject
+const projectColumns = `id, organization_id, name, description, owner_id, archived_at, created_at, updated_at`
+
 // Create adds a new project to the database
 func (r *ProjectRepository) Create(project *models.Project) error {
-	// Check if project with name already exists for this owner
+	// Check if project with name already exists for this owner in the organization
 	var count int
-	err := r.db.QueryRow("SELECT COUNT(*) FROM projects WHERE name = ? AND owner_id = ?",
-		project.Name, project.OwnerID).Scan(&count)
+	err := r.db.QueryRow("SELECT COUNT(*) FROM projects WHERE organization_id = ? AND name = ? AND owner_id = ?",
+		project.OrganizationID, project.Name, project.OwnerID).Scan(&count)
 	if err != nil {
 		return err
 	}
@@ -49,11 +55,11 @@ func (r *ProjectRepository) Create(project *models.Project) error {
 
 	// Insert new project
 	query := `
-		INSERT INTO projects (name, description, owner_id, created_at, updated_at)
-		VALUES (?, ?, ?, ?, ?)
+		INSERT INTO projects (organization_id, name, description, owner_id, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?)
 	`
 	now := time.Now()
-	result, err := r.db.Exec(query, project.Name, project.Description, project.OwnerID, now, now)
+	result, err := r.db.Exec(query, project.OrganizationID, project.Name, project.Description, project.OwnerID, now, now)
 	if err != nil {
 		return err
 	}
@@ -71,20 +77,11 @@ func (r *ProjectRepository) Create(project *models.Project) error {
 
 // GetByID retrieves a project by ID
 func (r *ProjectRepository) GetByID(id int64) (*models.Project, error) {
-	query := `
-		SELECT id, name, description, owner_id, created_at, updated_at
+	query := "SELECT " + projectColumns + `
 		FROM projects
-		WHERE id = ?
+		WHERE id = ? AND deleted_at IS NULL
 	`
-	project := &models.Project{}
-	err := r.db.QueryRow(query, id).Scan(
-		&project.ID,
-		&project.Name,
-		&project.Description,
-		&project.OwnerID,
-		&project.CreatedAt,
-		&project.UpdatedAt,
-	)
+	project, err := scanProject(r.db.QueryRow(query, id))
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, ErrProjectNotFound
@@ -118,7 +115,7 @@ func (r *ProjectRepository) Update(project *models.Project) error {
 	return nil
 }
 
-// Delete removes a project from the database
+// Delete moves a project to the trash together with its test suites and test cases
 func (r *ProjectRepository) Delete(id int64) error {
 	// Check if project exists
 	_, err := r.GetByID(id)
@@ -126,59 +123,58 @@ func (r *ProjectRepository) Delete(id int64) error {
 		return err
 	}
 
-	// Delete project
-	query := `DELETE FROM projects WHERE id = ?`
-	_, err = r.db.Exec(query, id)
-	return err
-}
-
-// ListByOwner retrieves all projects for a specific owner
-func (r *ProjectRepository) ListByOwner(ownerID int64) ([]*models.Project, error) {
-	query := `
-		SELECT id, name, description, owner_id, created_at, updated_at
-		FROM projects
-		WHERE owner_id = ?
-		ORDER BY created_at DESC
-	`
-	rows, err := r.db.Query(query, ownerID)
+	tx, err := r.db.Begin()
 	if err != nil {
-		return nil, err
+		return err
 	}
-	defer rows.Close()
+	defer tx.Rollback()
 
-	var projects []*models.Project
-	for rows.Next() {
-		project := &models.Project{}
-		err := rows.Scan(
-			&project.ID,
-			&project.Name,
-			&project.Description,
-			&project.OwnerID,
-			&project.CreatedAt,
-			&project.UpdatedAt,
-		)
-		if err != nil {
-			return nil, err
-		}
-		projects = append(projects, project)
+	now := time.Now()
+	if _, err := tx.Exec("UPDATE projects SET deleted_at = ? WHERE id = ?", now, id); err != nil {
+		return err
 	}
 
-	if err = rows.Err(); err != nil {
-		return nil, err
+	// Children already in the trash keep their own deletion so they are not restored with the project
+	if _, err := tx.Exec(`
+		UPDATE test_suites SET deleted_at = ?, deleted_with_parent = TRUE
+		WHERE project_id = ? AND deleted_at IS NULL`, now, id); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`
+		UPDATE test_cases SET deleted_at = ?, deleted_with_parent = TRUE
+		WHERE project_id = ? AND deleted_at IS NULL`, now, id); err != nil {
+		return err
 	}
 
-	return projects, nil
+	return tx.Commit()
 }
 
-// List retrieves all projects with optional pagination
-func (r *ProjectRepository) List(limit, offset int) ([]*models.Project, error) {
-	query := `
-		SELECT id, name, description, owner_id, created_at, updated_at
+// ListByOwner retrieves all projects of an organization for a specific owner, optionally
+// including archived ones
+func (r *ProjectRepository) ListByOwner(organizationID, ownerID int64, includeArchived bool) ([]*models.Project, error) {
+	query := "SELECT " + projectColumns + `
+		FROM projects
+		WHERE organization_id = ? AND owner_id = ? AND deleted_at IS NULL AND (? OR archived_at IS NULL)
+		ORDER BY created_at DESC
+	`
+	return r.listProjects(query, organizationID, ownerID, includeArchived)
+}
+
+// List retrieves all projects of an organization with optional pagination, optionally
+// including archived ones
+func (r *ProjectRepository) List(organizationID int64, limit, offset int, includeArchived bool) ([]*models.Project, error) {
+	query := "SELECT " + projectColumns + `
 		FROM projects
+		WHERE organization_id = ? AND deleted_at IS NULL AND (? OR archived_at IS NULL)
 		ORDER BY created_at DESC
 		LIMIT ? OFFSET ?
 	`
-	rows, err := r.db.Query(query, limit, offset)
+	return r.listProjects(query, organizationID, includeArchived, limit, offset)
+}
+
+// listProjects runs a query for projects
+func (r *ProjectRepository) listProjects(query string, args ...interface{}) ([]*models.Project, error) {
+	rows, err := r.db.Query(query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -186,15 +182,7 @@ func (r *ProjectRepository) List(limit, offset int) ([]*models.Project, error) {
 
 	var projects []*models.Project
 	for rows.Next() {
-		project := &models.Project{}
-		err := rows.Scan(
-			&project.ID,
-			&project.Name,
-			&project.Description,
-			&project.OwnerID,
-			&project.CreatedAt,
-			&project.UpdatedAt,
-		)
+		project, err := scanProject(rows)
 		if err != nil {
 			return nil, err
 		}
@@ -208,7 +196,7 @@ func (r *ProjectRepository) List(limit, offset int) ([]*models.Project, error) {
 	return projects, nil
 }
 
-// IsOwner checks if a user is the owner of a project
+// IsOwner checks if a user is the owner of a project, including a project in the trash
 func (r *ProjectRepository) IsOwner(projectID, userID int64) (bool, error) {
 	var count int
 	err := r.db.QueryRow("SELECT COUNT(*) FROM projects WHERE id = ? AND owner_id = ?", projectID, userID).Scan(&count)
@@ -217,3 +205,96 @@ func (r *ProjectRepository) IsOwner(projectID, userID int64) (bool, error) {
 	}
 	return count > 0, nil
 }
+
+// GetOrganizationID retrieves the organization of a project, including a project in the trash
+func (r *ProjectRepository) GetOrganizationID(id int64) (int64, error) {
+	var organizationID int64
+	err := r.db.QueryRow("SELECT organization_id FROM projects WHERE id = ?", id).Scan(&organizationID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return 0, ErrProjectNotFound
+		}
+		return 0, err
+	}
+	return organizationID, nil
+}
+
+// SetArchived archives or unarchives a project
+func (r *ProjectRepository) SetArchived(id int64, archived bool) error {
+	// Check if project exists
+	_, err := r.GetByID(id)
+	if err != nil {
+		return err
+	}
+
+	var archivedAt interface{}
+	if archived {
+		archivedAt = time.Now()
+	}
+
+	_, err = r.db.Exec("UPDATE projects SET archived_at = ? WHERE id = ?", archivedAt, id)
+	return err
+}
+
+// TransferOwnership makes another user the owner of a project. The new owner's own grant
+// is removed, and the previous owner keeps access to the project with previousOwnerLevel.
+func (r *ProjectRepository) TransferOwnership(id, newOwnerID int64, previousOwnerLevel models.AccessLevel) error {
+	project, err := r.GetByID(id)
+	if err != nil {
+		return err
+	}
+
+	// Project names are unique per owner in an organization
+	var count int
+	err = r.db.QueryRow("SELECT COUNT(*) FROM projects WHERE organization_id = ? AND name = ? AND owner_id = ?",
+		project.OrganizationID, project.Name, newOwnerID).Scan(&count)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return ErrProjectExists
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	if _, err := tx.Exec("UPDATE projects SET owner_id = ?, updated_at = ? WHERE id = ?", newOwnerID, now, id); err != nil {
+		return err
+	}
+	if _, err := tx.Exec("DELETE FROM project_access WHERE project_id = ? AND user_id = ?", id, newOwnerID); err != nil {
+		return err
+	}
+	_, err = tx.Exec(`
+		INSERT INTO project_access (project_id, user_id, level, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE level = VALUES(level), updated_at = VALUES(updated_at)`,
+		id, project.OwnerID, previousOwnerLevel, now, now)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// scanProject scans a row of projectColumns
+func scanProject(row rowScanner) (*models.Project, error) {
+	project := &models.Project{}
+	err := row.Scan(
+		&project.ID,
+		&project.OrganizationID,
+		&project.Name,
+		&project.Description,
+		&project.OwnerID,
+		&project.ArchivedAt,
+		&project.CreatedAt,
+		&project.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return project, nil
+}
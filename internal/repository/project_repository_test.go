@@ -24,18 +24,19 @@ func TestProjectRepository_Create(t *testing.T) {
 	t.Run("Success", func(t *testing.T) {
 		// Setup expectations
 		mock.ExpectQuery("SELECT COUNT").
-			WithArgs("Test Project", int64(1)).
+			WithArgs(int64(1), "Test Project", int64(1)).
 			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
 
 		mock.ExpectExec("INSERT INTO projects").
-			WithArgs("Test Project", "Test Description", int64(1), sqlmock.AnyArg(), sqlmock.AnyArg()).
+			WithArgs(int64(1), "Test Project", "Test Description", int64(1), sqlmock.AnyArg(), sqlmock.AnyArg()).
 			WillReturnResult(sqlmock.NewResult(1, 1))
 
 		// Create project
 		project := &models.Project{
-			Name:        "Test Project",
-			Description: "Test Description",
-			OwnerID:     1,
+			OrganizationID: 1,
+			Name:           "Test Project",
+			Description:    "Test Description",
+			OwnerID:        1,
 		}
 
 		// Execute
@@ -53,14 +54,15 @@ func TestProjectRepository_Create(t *testing.T) {
 	t.Run("ProjectExists", func(t *testing.T) {
 		// Setup expectations
 		mock.ExpectQuery("SELECT COUNT").
-			WithArgs("Existing Project", int64(1)).
+			WithArgs(int64(1), "Existing Project", int64(1)).
 			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
 
 		// Create project
 		project := &models.Project{
-			Name:        "Existing Project",
-			Description: "Test Description",
-			OwnerID:     1,
+			OrganizationID: 1,
+			Name:           "Existing Project",
+			Description:    "Test Description",
+			OwnerID:        1,
 		}
 
 		// Execute
@@ -75,18 +77,19 @@ func TestProjectRepository_Create(t *testing.T) {
 	t.Run("DatabaseError", func(t *testing.T) {
 		// Setup expectations
 		mock.ExpectQuery("SELECT COUNT").
-			WithArgs("Test Project", int64(1)).
+			WithArgs(int64(1), "Test Project", int64(1)).
 			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
 
 		mock.ExpectExec("INSERT INTO projects").
-			WithArgs("Test Project", "Test Description", int64(1), sqlmock.AnyArg(), sqlmock.AnyArg()).
+			WithArgs(int64(1), "Test Project", "Test Description", int64(1), sqlmock.AnyArg(), sqlmock.AnyArg()).
 			WillReturnError(sql.ErrConnDone)
 
 		// Create project
 		project := &models.Project{
-			Name:        "Test Project",
-			Description: "Test Description",
-			OwnerID:     1,
+			OrganizationID: 1,
+			Name:           "Test Project",
+			Description:    "Test Description",
+			OwnerID:        1,
 		}
 
 		// Execute
@@ -112,10 +115,10 @@ func TestProjectRepository_GetByID(t *testing.T) {
 	// Test case: project found
 	t.Run("Success", func(t *testing.T) {
 		// Setup expectations
-		rows := sqlmock.NewRows([]string{"id", "name", "description", "owner_id", "created_at", "updated_at"}).
-			AddRow(1, "Test Project", "Test Description", 1, now, now)
+		rows := sqlmock.NewRows([]string{"id", "organization_id", "name", "description", "owner_id", "archived_at", "created_at", "updated_at"}).
+			AddRow(1, 1, "Test Project", "Test Description", 1, nil, now, now)
 
-		mock.ExpectQuery(regexp.QuoteMeta("SELECT id, name, description, owner_id, created_at, updated_at FROM projects WHERE id = ?")).
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT id, organization_id, name, description, owner_id, archived_at, created_at, updated_at FROM projects WHERE id = ?")).
 			WithArgs(1).
 			WillReturnRows(rows)
 
@@ -137,7 +140,7 @@ func TestProjectRepository_GetByID(t *testing.T) {
 	// Test case: project not found
 	t.Run("NotFound", func(t *testing.T) {
 		// Setup expectations
-		mock.ExpectQuery(regexp.QuoteMeta("SELECT id, name, description, owner_id, created_at, updated_at FROM projects WHERE id = ?")).
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT id, organization_id, name, description, owner_id, archived_at, created_at, updated_at FROM projects WHERE id = ?")).
 			WithArgs(2).
 			WillReturnError(sql.ErrNoRows)
 
@@ -165,10 +168,10 @@ func TestProjectRepository_Update(t *testing.T) {
 	// Test case: successful update
 	t.Run("Success", func(t *testing.T) {
 		// Setup expectations for GetByID
-		rows := sqlmock.NewRows([]string{"id", "name", "description", "owner_id", "created_at", "updated_at"}).
-			AddRow(1, "Old Name", "Old Description", 1, now, now)
+		rows := sqlmock.NewRows([]string{"id", "organization_id", "name", "description", "owner_id", "archived_at", "created_at", "updated_at"}).
+			AddRow(1, 1, "Old Name", "Old Description", 1, nil, now, now)
 
-		mock.ExpectQuery(regexp.QuoteMeta("SELECT id, name, description, owner_id, created_at, updated_at FROM projects WHERE id = ?")).
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT id, organization_id, name, description, owner_id, archived_at, created_at, updated_at FROM projects WHERE id = ?")).
 			WithArgs(1).
 			WillReturnRows(rows)
 
@@ -197,7 +200,7 @@ func TestProjectRepository_Update(t *testing.T) {
 	// Test case: project not found
 	t.Run("NotFound", func(t *testing.T) {
 		// Setup expectations
-		mock.ExpectQuery(regexp.QuoteMeta("SELECT id, name, description, owner_id, created_at, updated_at FROM projects WHERE id = ?")).
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT id, organization_id, name, description, owner_id, archived_at, created_at, updated_at FROM projects WHERE id = ?")).
 			WithArgs(2).
 			WillReturnError(sql.ErrNoRows)
 
@@ -232,17 +235,25 @@ func TestProjectRepository_Delete(t *testing.T) {
 	// Test case: successful delete
 	t.Run("Success", func(t *testing.T) {
 		// Setup expectations for GetByID
-		rows := sqlmock.NewRows([]string{"id", "name", "description", "owner_id", "created_at", "updated_at"}).
-			AddRow(1, "Test Project", "Test Description", 1, now, now)
+		rows := sqlmock.NewRows([]string{"id", "organization_id", "name", "description", "owner_id", "archived_at", "created_at", "updated_at"}).
+			AddRow(1, 1, "Test Project", "Test Description", 1, nil, now, now)
 
-		mock.ExpectQuery(regexp.QuoteMeta("SELECT id, name, description, owner_id, created_at, updated_at FROM projects WHERE id = ?")).
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT id, organization_id, name, description, owner_id, archived_at, created_at, updated_at FROM projects WHERE id = ?")).
 			WithArgs(1).
 			WillReturnRows(rows)
 
-		// Setup expectations for Delete
-		mock.ExpectExec(regexp.QuoteMeta("DELETE FROM projects WHERE id = ?")).
-			WithArgs(1).
+		// Setup expectations for moving the project and its children to the trash
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta("UPDATE projects SET deleted_at = ? WHERE id = ?")).
+			WithArgs(sqlmock.AnyArg(), 1).
 			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(regexp.QuoteMeta("UPDATE test_suites SET deleted_at = ?, deleted_with_parent = TRUE WHERE project_id = ? AND deleted_at IS NULL")).
+			WithArgs(sqlmock.AnyArg(), 1).
+			WillReturnResult(sqlmock.NewResult(0, 2))
+		mock.ExpectExec(regexp.QuoteMeta("UPDATE test_cases SET deleted_at = ?, deleted_with_parent = TRUE WHERE project_id = ? AND deleted_at IS NULL")).
+			WithArgs(sqlmock.AnyArg(), 1).
+			WillReturnResult(sqlmock.NewResult(0, 5))
+		mock.ExpectCommit()
 
 		// Execute
 		err := repo.Delete(1)
@@ -255,7 +266,7 @@ func TestProjectRepository_Delete(t *testing.T) {
 	// Test case: project not found
 	t.Run("NotFound", func(t *testing.T) {
 		// Setup expectations
-		mock.ExpectQuery(regexp.QuoteMeta("SELECT id, name, description, owner_id, created_at, updated_at FROM projects WHERE id = ?")).
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT id, organization_id, name, description, owner_id, archived_at, created_at, updated_at FROM projects WHERE id = ?")).
 			WithArgs(2).
 			WillReturnError(sql.ErrNoRows)
 
@@ -282,16 +293,16 @@ func TestProjectRepository_ListByOwner(t *testing.T) {
 	// Test case: successful list
 	t.Run("Success", func(t *testing.T) {
 		// Setup expectations
-		rows := sqlmock.NewRows([]string{"id", "name", "description", "owner_id", "created_at", "updated_at"}).
-			AddRow(1, "Project 1", "Description 1", 1, now, now).
-			AddRow(2, "Project 2", "Description 2", 1, now, now)
+		rows := sqlmock.NewRows([]string{"id", "organization_id", "name", "description", "owner_id", "archived_at", "created_at", "updated_at"}).
+			AddRow(1, 1, "Project 1", "Description 1", 1, nil, now, now).
+			AddRow(2, 1, "Project 2", "Description 2", 1, nil, now, now)
 
-		mock.ExpectQuery(regexp.QuoteMeta("SELECT id, name, description, owner_id, created_at, updated_at FROM projects WHERE owner_id = ? ORDER BY created_at DESC")).
-			WithArgs(1).
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT id, organization_id, name, description, owner_id, archived_at, created_at, updated_at FROM projects WHERE organization_id = ? AND owner_id = ? AND deleted_at IS NULL AND (? OR archived_at IS NULL) ORDER BY created_at DESC")).
+			WithArgs(1, 1, false).
 			WillReturnRows(rows)
 
 		// Execute
-		projects, err := repo.ListByOwner(1)
+		projects, err := repo.ListByOwner(1, 1, false)
 
 		// Assert
 		assert.NoError(t, err)
@@ -306,14 +317,14 @@ func TestProjectRepository_ListByOwner(t *testing.T) {
 	// Test case: no projects found
 	t.Run("NoProjects", func(t *testing.T) {
 		// Setup expectations
-		rows := sqlmock.NewRows([]string{"id", "name", "description", "owner_id", "created_at", "updated_at"})
+		rows := sqlmock.NewRows([]string{"id", "organization_id", "name", "description", "owner_id", "archived_at", "created_at", "updated_at"})
 
-		mock.ExpectQuery(regexp.QuoteMeta("SELECT id, name, description, owner_id, created_at, updated_at FROM projects WHERE owner_id = ? ORDER BY created_at DESC")).
-			WithArgs(2).
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT id, organization_id, name, description, owner_id, archived_at, created_at, updated_at FROM projects WHERE organization_id = ? AND owner_id = ? AND deleted_at IS NULL AND (? OR archived_at IS NULL) ORDER BY created_at DESC")).
+			WithArgs(1, 2, true).
 			WillReturnRows(rows)
 
 		// Execute
-		projects, err := repo.ListByOwner(2)
+		projects, err := repo.ListByOwner(1, 2, true)
 
 		// Assert
 		assert.NoError(t, err)
@@ -321,3 +332,55 @@ func TestProjectRepository_ListByOwner(t *testing.T) {
 		assert.NoError(t, mock.ExpectationsWereMet())
 	})
 }
+
+func TestProjectRepository_TransferOwnership(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewProjectRepository(db)
+	now := time.Now()
+	projectRows := func() *sqlmock.Rows {
+		return sqlmock.NewRows([]string{"id", "organization_id", "name", "description", "owner_id", "archived_at", "created_at", "updated_at"}).
+			AddRow(1, 1, "Test Project", "Test Description", 1, nil, now, now)
+	}
+
+	// Test case: the new owner loses its grant and the previous owner keeps access
+	t.Run("Success", func(t *testing.T) {
+		mock.ExpectQuery("SELECT id, organization_id, name").WithArgs(1).WillReturnRows(projectRows())
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT COUNT(*) FROM projects WHERE organization_id = ? AND name = ? AND owner_id = ?")).
+			WithArgs(1, "Test Project", 2).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta("UPDATE projects SET owner_id = ?, updated_at = ? WHERE id = ?")).
+			WithArgs(2, sqlmock.AnyArg(), 1).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(regexp.QuoteMeta("DELETE FROM project_access WHERE project_id = ? AND user_id = ?")).
+			WithArgs(1, 2).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("INSERT INTO project_access").
+			WithArgs(1, 1, models.AccessLevelMaintainer, sqlmock.AnyArg(), sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(7, 1))
+		mock.ExpectCommit()
+
+		err := repo.TransferOwnership(1, 2, models.AccessLevelMaintainer)
+
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	// Test case: the new owner already owns a project with the same name
+	t.Run("NameTaken", func(t *testing.T) {
+		mock.ExpectQuery("SELECT id, organization_id, name").WithArgs(1).WillReturnRows(projectRows())
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT COUNT(*) FROM projects WHERE organization_id = ? AND name = ? AND owner_id = ?")).
+			WithArgs(1, "Test Project", 3).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+
+		err := repo.TransferOwnership(1, 3, models.AccessLevelMaintainer)
+
+		assert.Equal(t, ErrProjectExists, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -0,0 +1,242 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/mihaamiharu/test-case-management-be/internal/models"
+)
+
+var (
+	ErrReviewNotFound         = errors.New("review not found")
+	ErrReviewAlreadyRequested = errors.New("review already requested from this reviewer for the current version")
+)
+
+// ReviewRepositoryInterface defines the interface for review repository operations
+type ReviewRepositoryInterface interface {
+	GetSettings(projectID int64) (*models.ReviewSettings, error)
+	UpsertSettings(settings *models.ReviewSettings) error
+	CreateRequests(reviews []*models.TestCaseReview) error
+	GetByID(id int64) (*models.TestCaseReview, error)
+	ListByTestCase(testCaseID int64) ([]*models.TestCaseReview, error)
+	Decide(review *models.TestCaseReview) error
+	CountApprovals(testCaseID int64, version int) (int, error)
+}
+
+// ReviewRepository handles database operations for test case reviews
+type ReviewRepository struct {
+	db *sql.DB
+}
+
+// NewReviewRepository creates a new review repository
+func NewReviewRepository(db *sql.DB) *ReviewRepository {
+	return &ReviewRepository{db: db}
+}
+
+// GetSettings retrieves the review rules of a project.
+// Projects without settings require no approvals.
+func (r *ReviewRepository) GetSettings(projectID int64) (*models.ReviewSettings, error) {
+	settings := &models.ReviewSettings{ProjectID: projectID}
+	err := r.db.QueryRow(
+		"SELECT required_approvals, updated_by, updated_at FROM project_review_settings WHERE project_id = ?",
+		projectID,
+	).Scan(&settings.RequiredApprovals, &settings.UpdatedBy, &settings.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return settings, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get review settings: %v", err)
+	}
+	return settings, nil
+}
+
+// UpsertSettings creates or replaces the review rules of a project
+func (r *ReviewRepository) UpsertSettings(settings *models.ReviewSettings) error {
+	now := time.Now()
+	query := `
+		INSERT INTO project_review_settings (project_id, required_approvals, updated_by, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE
+			required_approvals = VALUES(required_approvals),
+			updated_by = VALUES(updated_by),
+			updated_at = VALUES(updated_at)`
+
+	_, err := r.db.Exec(query, settings.ProjectID, settings.RequiredApprovals, settings.UpdatedBy, now, now)
+	if err != nil {
+		return fmt.Errorf("failed to save review settings: %v", err)
+	}
+
+	settings.UpdatedAt = now
+	return nil
+}
+
+// CreateRequests inserts pending reviews for a test case version in a single transaction
+func (r *ReviewRepository) CreateRequests(reviews []*models.TestCaseReview) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	for _, review := range reviews {
+		var count int
+		err := tx.QueryRow(
+			"SELECT COUNT(*) FROM test_case_reviews WHERE test_case_id = ? AND version = ? AND reviewer_id = ?",
+			review.TestCaseID, review.Version, review.ReviewerID,
+		).Scan(&count)
+		if err != nil {
+			return fmt.Errorf("failed to check existing reviews: %v", err)
+		}
+		if count > 0 {
+			return ErrReviewAlreadyRequested
+		}
+
+		result, err := tx.Exec(`
+			INSERT INTO test_case_reviews (
+				test_case_id, version, reviewer_id, requested_by, status, created_at, updated_at
+			) VALUES (?, ?, ?, ?, ?, ?, ?)`,
+			review.TestCaseID,
+			review.Version,
+			review.ReviewerID,
+			review.RequestedBy,
+			models.ReviewStatusPending,
+			now,
+			now,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to create review: %v", err)
+		}
+
+		id, err := result.LastInsertId()
+		if err != nil {
+			return fmt.Errorf("failed to get last insert ID: %v", err)
+		}
+
+		review.ID = id
+		review.Status = models.ReviewStatusPending
+		review.CreatedAt = now
+		review.UpdatedAt = now
+	}
+
+	return tx.Commit()
+}
+
+// GetByID retrieves a review by ID
+func (r *ReviewRepository) GetByID(id int64) (*models.TestCaseReview, error) {
+	query := `
+		SELECT id, test_case_id, version, reviewer_id, requested_by, status,
+			comment_id, decided_at, created_at, updated_at
+		FROM test_case_reviews
+		WHERE id = ?`
+
+	review, err := scanReview(r.db.QueryRow(query, id))
+	if err == sql.ErrNoRows {
+		return nil, ErrReviewNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get review: %v", err)
+	}
+
+	return review, nil
+}
+
+// ListByTestCase retrieves all reviews of a test case, newest version first
+func (r *ReviewRepository) ListByTestCase(testCaseID int64) ([]*models.TestCaseReview, error) {
+	query := `
+		SELECT id, test_case_id, version, reviewer_id, requested_by, status,
+			comment_id, decided_at, created_at, updated_at
+		FROM test_case_reviews
+		WHERE test_case_id = ?
+		ORDER BY version DESC, id`
+
+	rows, err := r.db.Query(query, testCaseID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list reviews: %v", err)
+	}
+	defer rows.Close()
+
+	var reviews []*models.TestCaseReview
+	for rows.Next() {
+		review, err := scanReview(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan review: %v", err)
+		}
+		reviews = append(reviews, review)
+	}
+
+	return reviews, rows.Err()
+}
+
+// Decide records the reviewer's decision
+func (r *ReviewRepository) Decide(review *models.TestCaseReview) error {
+	now := time.Now()
+	result, err := r.db.Exec(
+		"UPDATE test_case_reviews SET status = ?, comment_id = ?, decided_at = ?, updated_at = ? WHERE id = ?",
+		review.Status, review.CommentID, now, now, review.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update review: %v", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %v", err)
+	}
+	if rowsAffected == 0 {
+		return ErrReviewNotFound
+	}
+
+	review.DecidedAt = &now
+	review.UpdatedAt = now
+	return nil
+}
+
+// CountApprovals counts the approvals of one version of a test case
+func (r *ReviewRepository) CountApprovals(testCaseID int64, version int) (int, error) {
+	var count int
+	err := r.db.QueryRow(
+		"SELECT COUNT(*) FROM test_case_reviews WHERE test_case_id = ? AND version = ? AND status = ?",
+		testCaseID, version, models.ReviewStatusApproved,
+	).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count approvals: %v", err)
+	}
+	return count, nil
+}
+
+// scanReview scans a review row including its nullable columns
+func scanReview(row rowScanner) (*models.TestCaseReview, error) {
+	review := &models.TestCaseReview{}
+	var (
+		commentID sql.NullInt64
+		decidedAt sql.NullTime
+	)
+
+	err := row.Scan(
+		&review.ID,
+		&review.TestCaseID,
+		&review.Version,
+		&review.ReviewerID,
+		&review.RequestedBy,
+		&review.Status,
+		&commentID,
+		&decidedAt,
+		&review.CreatedAt,
+		&review.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if commentID.Valid {
+		review.CommentID = &commentID.Int64
+	}
+	if decidedAt.Valid {
+		review.DecidedAt = &decidedAt.Time
+	}
+
+	return review, nil
+}
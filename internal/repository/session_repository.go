@@ -0,0 +1,330 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/mihaamiharu/test-case-management-be/internal/models"
+)
+
+var (
+	ErrSessionNotFound      = errors.New("session not found")
+	ErrRefreshTokenNotFound = errors.New("refresh token not found")
+	ErrRefreshTokenUsed     = errors.New("refresh token has already been used")
+)
+
+// SessionRepositoryInterface defines the interface for session repository operations
+type SessionRepositoryInterface interface {
+	Create(session *models.Session, token *models.RefreshToken) error
+	GetByID(id int64) (*models.Session, error)
+	ListActiveByUser(userID int64) ([]*models.Session, error)
+	GetRefreshToken(tokenHash string) (*models.RefreshToken, error)
+	RotateRefreshToken(usedTokenID int64, session *models.Session, token *models.RefreshToken, deniedUntil time.Time) error
+	Revoke(id int64, deniedUntil time.Time) error
+	RevokeAllByUser(userID, exceptSessionID int64, deniedUntil time.Time) error
+	IsTokenDenied(tokenID string) (bool, error)
+}
+
+// SessionRepository handles database operations for sessions, refresh tokens and the token denylist
+type SessionRepository struct {
+	db *sql.DB
+}
+
+// NewSessionRepository creates a new session repository
+func NewSessionRepository(db *sql.DB) *SessionRepository {
+	return &SessionRepository{db: db}
+}
+
+// Create adds a new session with its first refresh token
+func (r *SessionRepository) Create(session *models.Session, token *models.RefreshToken) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	result, err := tx.Exec(`
+		INSERT INTO sessions (user_id, access_token_id, user_agent, ip_address, created_at, last_used_at, expires_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		session.UserID, session.AccessTokenID, session.UserAgent, session.IPAddress, now, now, session.ExpiresAt)
+	if err != nil {
+		return fmt.Errorf("failed to create session: %v", err)
+	}
+
+	session.ID, err = result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to get session ID: %v", err)
+	}
+	session.CreatedAt = now
+	session.LastUsedAt = now
+
+	token.SessionID = session.ID
+	if err := insertRefreshToken(tx, token, now); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// GetByID retrieves a session by ID
+func (r *SessionRepository) GetByID(id int64) (*models.Session, error) {
+	query := `
+		SELECT id, user_id, access_token_id, user_agent, ip_address, created_at, last_used_at, expires_at, revoked_at
+		FROM sessions
+		WHERE id = ?`
+
+	session, err := scanSession(r.db.QueryRow(query, id))
+	if err == sql.ErrNoRows {
+		return nil, ErrSessionNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get session: %v", err)
+	}
+
+	return session, nil
+}
+
+// ListActiveByUser retrieves the sessions of a user that are neither revoked nor expired,
+// most recently used first
+func (r *SessionRepository) ListActiveByUser(userID int64) ([]*models.Session, error) {
+	query := `
+		SELECT id, user_id, access_token_id, user_agent, ip_address, created_at, last_used_at, expires_at, revoked_at
+		FROM sessions
+		WHERE user_id = ? AND revoked_at IS NULL AND expires_at > ?
+		ORDER BY last_used_at DESC`
+
+	rows, err := r.db.Query(query, userID, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sessions: %v", err)
+	}
+	defer rows.Close()
+
+	var sessions []*models.Session
+	for rows.Next() {
+		session, err := scanSession(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan session: %v", err)
+		}
+		sessions = append(sessions, session)
+	}
+
+	return sessions, rows.Err()
+}
+
+// GetRefreshToken retrieves a refresh token by the hash of its value
+func (r *SessionRepository) GetRefreshToken(tokenHash string) (*models.RefreshToken, error) {
+	query := `
+		SELECT id, session_id, token_hash, expires_at, used_at, created_at
+		FROM refresh_tokens
+		WHERE token_hash = ?`
+
+	token := &models.RefreshToken{}
+	err := r.db.QueryRow(query, tokenHash).Scan(
+		&token.ID,
+		&token.SessionID,
+		&token.TokenHash,
+		&token.ExpiresAt,
+		&token.UsedAt,
+		&token.CreatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, ErrRefreshTokenNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get refresh token: %v", err)
+	}
+
+	return token, nil
+}
+
+// RotateRefreshToken marks a refresh token as used and replaces it with a new one. The
+// session records its new access token and expiry, and the access token it replaces is
+// denied until deniedUntil. ErrRefreshTokenUsed is returned when the token was used in
+// the meantime.
+func (r *SessionRepository) RotateRefreshToken(usedTokenID int64, session *models.Session, token *models.RefreshToken, deniedUntil time.Time) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	result, err := tx.Exec("UPDATE refresh_tokens SET used_at = ? WHERE id = ? AND used_at IS NULL", now, usedTokenID)
+	if err != nil {
+		return fmt.Errorf("failed to mark refresh token as used: %v", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %v", err)
+	}
+	if rowsAffected == 0 {
+		return ErrRefreshTokenUsed
+	}
+
+	token.SessionID = session.ID
+	if err := insertRefreshToken(tx, token, now); err != nil {
+		return err
+	}
+
+	var replacedTokenID string
+	err = tx.QueryRow("SELECT access_token_id FROM sessions WHERE id = ?", session.ID).Scan(&replacedTokenID)
+	if err != nil {
+		return fmt.Errorf("failed to get session: %v", err)
+	}
+	if err := denyToken(tx, replacedTokenID, deniedUntil, now); err != nil {
+		return err
+	}
+
+	_, err = tx.Exec("UPDATE sessions SET access_token_id = ?, last_used_at = ?, expires_at = ? WHERE id = ?",
+		session.AccessTokenID, now, session.ExpiresAt, session.ID)
+	if err != nil {
+		return fmt.Errorf("failed to update session: %v", err)
+	}
+	session.LastUsedAt = now
+
+	return tx.Commit()
+}
+
+// Revoke ends a session and denies its latest access token until deniedUntil
+func (r *SessionRepository) Revoke(id int64, deniedUntil time.Time) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	var accessTokenID string
+	err = tx.QueryRow("SELECT access_token_id FROM sessions WHERE id = ? AND revoked_at IS NULL", id).Scan(&accessTokenID)
+	if err == sql.ErrNoRows {
+		return ErrSessionNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get session: %v", err)
+	}
+
+	now := time.Now()
+	if _, err := tx.Exec("UPDATE sessions SET revoked_at = ? WHERE id = ?", now, id); err != nil {
+		return fmt.Errorf("failed to revoke session: %v", err)
+	}
+
+	if err := denyToken(tx, accessTokenID, deniedUntil, now); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// RevokeAllByUser ends every active session of a user except exceptSessionID (0 for none)
+// and denies their latest access tokens until deniedUntil
+func (r *SessionRepository) RevokeAllByUser(userID, exceptSessionID int64, deniedUntil time.Time) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.Query("SELECT id, access_token_id FROM sessions WHERE user_id = ? AND id != ? AND revoked_at IS NULL", userID, exceptSessionID)
+	if err != nil {
+		return fmt.Errorf("failed to list sessions: %v", err)
+	}
+	var (
+		sessionIDs     []int64
+		accessTokenIDs []string
+	)
+	for rows.Next() {
+		var (
+			sessionID     int64
+			accessTokenID string
+		)
+		if err := rows.Scan(&sessionID, &accessTokenID); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan session: %v", err)
+		}
+		sessionIDs = append(sessionIDs, sessionID)
+		accessTokenIDs = append(accessTokenIDs, accessTokenID)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to list sessions: %v", err)
+	}
+
+	now := time.Now()
+	for i, sessionID := range sessionIDs {
+		if _, err := tx.Exec("UPDATE sessions SET revoked_at = ? WHERE id = ?", now, sessionID); err != nil {
+			return fmt.Errorf("failed to revoke session: %v", err)
+		}
+		if err := denyToken(tx, accessTokenIDs[i], deniedUntil, now); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// IsTokenDenied reports whether an access token is on the denylist
+func (r *SessionRepository) IsTokenDenied(tokenID string) (bool, error) {
+	var count int
+	err := r.db.QueryRow("SELECT COUNT(*) FROM token_denylist WHERE token_id = ?", tokenID).Scan(&count)
+	if err != nil {
+		return false, fmt.Errorf("failed to check token denylist: %v", err)
+	}
+	return count > 0, nil
+}
+
+// denyToken adds an access token to the denylist within a transaction
+func denyToken(tx *sql.Tx, tokenID string, deniedUntil, now time.Time) error {
+	// Entries are only needed until the access token would have expired
+	if _, err := tx.Exec("DELETE FROM token_denylist WHERE expires_at < ?", now); err != nil {
+		return fmt.Errorf("failed to clean up token denylist: %v", err)
+	}
+
+	_, err := tx.Exec(`
+		INSERT INTO token_denylist (token_id, expires_at) VALUES (?, ?)
+		ON DUPLICATE KEY UPDATE expires_at = VALUES(expires_at)`,
+		tokenID, deniedUntil)
+	if err != nil {
+		return fmt.Errorf("failed to deny access token: %v", err)
+	}
+	return nil
+}
+
+// insertRefreshToken adds a refresh token within a transaction
+func insertRefreshToken(tx *sql.Tx, token *models.RefreshToken, now time.Time) error {
+	result, err := tx.Exec(`
+		INSERT INTO refresh_tokens (session_id, token_hash, expires_at, created_at)
+		VALUES (?, ?, ?, ?)`,
+		token.SessionID, token.TokenHash, token.ExpiresAt, now)
+	if err != nil {
+		return fmt.Errorf("failed to create refresh token: %v", err)
+	}
+
+	token.ID, err = result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to get refresh token ID: %v", err)
+	}
+	token.CreatedAt = now
+	return nil
+}
+
+// scanSession scans a session row
+func scanSession(row rowScanner) (*models.Session, error) {
+	session := &models.Session{}
+	err := row.Scan(
+		&session.ID,
+		&session.UserID,
+		&session.AccessTokenID,
+		&session.UserAgent,
+		&session.IPAddress,
+		&session.CreatedAt,
+		&session.LastUsedAt,
+		&session.ExpiresAt,
+		&session.RevokedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return session, nil
+}
@@ -0,0 +1,379 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/mihaamiharu/test-case-management-be/internal/models"
+)
+
+var (
+	ErrSharedStepNotFound = errors.New("shared step not found")
+	ErrSharedStepExists   = errors.New("shared step with this name already exists in the project")
+	ErrSharedStepInUse    = errors.New("shared step is still used by test cases, including test cases in the trash")
+)
+
+// SharedStepRepositoryInterface defines the interface for shared step repository operations
+type SharedStepRepositoryInterface interface {
+	Create(sharedStep *models.SharedStep) error
+	GetByID(id int64) (*models.SharedStep, error)
+	Update(sharedStep *models.SharedStep) error
+	Delete(id int64) error
+	ListByProject(projectID int64) ([]*models.SharedStep, error)
+	GetItems(sharedStepID int64) ([]*models.SharedStepItem, error)
+	ListUsage(sharedStepID int64) ([]*models.SharedStepUsage, error)
+}
+
+// SharedStepRepository handles database operations for shared steps
+type SharedStepRepository struct {
+	db *sql.DB
+}
+
+// NewSharedStepRepository creates a new shared step repository
+func NewSharedStepRepository(db *sql.DB) *SharedStepRepository {
+	return &SharedStepRepository{db: db}
+}
+
+// Create adds a new shared step and its items to the database
+func (r *SharedStepRepository) Create(sharedStep *models.SharedStep) error {
+	// Check if a shared step with this name already exists in the project
+	var count int
+	err := r.db.QueryRow("SELECT COUNT(*) FROM shared_steps WHERE name = ? AND project_id = ?",
+		sharedStep.Name, sharedStep.ProjectID).Scan(&count)
+	if err != nil {
+		return fmt.Errorf("failed to check existing shared step: %v", err)
+	}
+	if count > 0 {
+		return ErrSharedStepExists
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	query := `
+		INSERT INTO shared_steps (
+			project_id, name, description, version,
+			created_by, updated_by, created_at, updated_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`
+
+	result, err := tx.Exec(
+		query,
+		sharedStep.ProjectID,
+		sharedStep.Name,
+		sharedStep.Description,
+		1, // Initial version
+		sharedStep.CreatedBy,
+		sharedStep.UpdatedBy,
+		now,
+		now,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create shared step: %v", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to get last insert ID: %v", err)
+	}
+
+	sharedStep.ID = id
+	sharedStep.Version = 1
+	sharedStep.CreatedAt = now
+	sharedStep.UpdatedAt = now
+
+	if err := insertSharedStepItems(tx, sharedStep, now); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// GetByID retrieves a shared step with its items by ID
+func (r *SharedStepRepository) GetByID(id int64) (*models.SharedStep, error) {
+	query := `
+		SELECT id, project_id, name, description, version,
+			created_by, updated_by, created_at, updated_at
+		FROM shared_steps
+		WHERE id = ?`
+
+	sharedStep := &models.SharedStep{}
+	err := r.db.QueryRow(query, id).Scan(
+		&sharedStep.ID,
+		&sharedStep.ProjectID,
+		&sharedStep.Name,
+		&sharedStep.Description,
+		&sharedStep.Version,
+		&sharedStep.CreatedBy,
+		&sharedStep.UpdatedBy,
+		&sharedStep.CreatedAt,
+		&sharedStep.UpdatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, ErrSharedStepNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get shared step: %v", err)
+	}
+
+	items, err := r.GetItems(id)
+	if err != nil {
+		return nil, err
+	}
+	sharedStep.Items = items
+
+	return sharedStep, nil
+}
+
+// Update updates a shared step and replaces its items. Test cases reference the
+// shared step by ID, so the change is visible in every test case that uses it.
+func (r *SharedStepRepository) Update(sharedStep *models.SharedStep) error {
+	// Check if the new name conflicts with another shared step in the same project
+	var count int
+	err := r.db.QueryRow("SELECT COUNT(*) FROM shared_steps WHERE name = ? AND project_id = ? AND id != ?",
+		sharedStep.Name, sharedStep.ProjectID, sharedStep.ID).Scan(&count)
+	if err != nil {
+		return fmt.Errorf("failed to check existing shared step: %v", err)
+	}
+	if count > 0 {
+		return ErrSharedStepExists
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	query := `
+		UPDATE shared_steps SET
+			name = ?,
+			description = ?,
+			updated_by = ?,
+			version = version + 1,
+			updated_at = ?
+		WHERE id = ?`
+
+	result, err := tx.Exec(
+		query,
+		sharedStep.Name,
+		sharedStep.Description,
+		sharedStep.UpdatedBy,
+		now,
+		sharedStep.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update shared step: %v", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %v", err)
+	}
+	if rowsAffected == 0 {
+		return ErrSharedStepNotFound
+	}
+
+	sharedStep.Version++
+	sharedStep.UpdatedAt = now
+
+	// Items are only referenced through their group, so they can be replaced wholesale
+	if sharedStep.Items != nil {
+		_, err = tx.Exec("DELETE FROM shared_step_items WHERE shared_step_id = ?", sharedStep.ID)
+		if err != nil {
+			return fmt.Errorf("failed to delete existing shared step items: %v", err)
+		}
+
+		if err := insertSharedStepItems(tx, sharedStep, now); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// Delete removes a shared step that is no longer referenced by any test step
+func (r *SharedStepRepository) Delete(id int64) error {
+	var count int
+	err := r.db.QueryRow("SELECT COUNT(*) FROM test_steps WHERE shared_step_id = ?", id).Scan(&count)
+	if err != nil {
+		return fmt.Errorf("failed to check shared step usage: %v", err)
+	}
+	if count > 0 {
+		return ErrSharedStepInUse
+	}
+
+	result, err := r.db.Exec("DELETE FROM shared_steps WHERE id = ?", id)
+	if err != nil {
+		return fmt.Errorf("failed to delete shared step: %v", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %v", err)
+	}
+	if rowsAffected == 0 {
+		return ErrSharedStepNotFound
+	}
+
+	return nil
+}
+
+// ListByProject retrieves all shared steps with their items for a project
+func (r *SharedStepRepository) ListByProject(projectID int64) ([]*models.SharedStep, error) {
+	query := `
+		SELECT id, project_id, name, description, version,
+			created_by, updated_by, created_at, updated_at
+		FROM shared_steps
+		WHERE project_id = ?
+		ORDER BY name`
+
+	rows, err := r.db.Query(query, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list shared steps: %v", err)
+	}
+	defer rows.Close()
+
+	var sharedSteps []*models.SharedStep
+	for rows.Next() {
+		sharedStep := &models.SharedStep{}
+		err := rows.Scan(
+			&sharedStep.ID,
+			&sharedStep.ProjectID,
+			&sharedStep.Name,
+			&sharedStep.Description,
+			&sharedStep.Version,
+			&sharedStep.CreatedBy,
+			&sharedStep.UpdatedBy,
+			&sharedStep.CreatedAt,
+			&sharedStep.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan shared step: %v", err)
+		}
+		sharedSteps = append(sharedSteps, sharedStep)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list shared steps: %v", err)
+	}
+
+	// Get items for each shared step
+	for _, sharedStep := range sharedSteps {
+		items, err := r.GetItems(sharedStep.ID)
+		if err != nil {
+			return nil, err
+		}
+		sharedStep.Items = items
+	}
+
+	return sharedSteps, nil
+}
+
+// GetItems retrieves the items of a shared step in order
+func (r *SharedStepRepository) GetItems(sharedStepID int64) ([]*models.SharedStepItem, error) {
+	query := `
+		SELECT id, shared_step_id, step_number, step_type, description,
+			expected_result, created_at, updated_at
+		FROM shared_step_items
+		WHERE shared_step_id = ?
+		ORDER BY step_number`
+
+	rows, err := r.db.Query(query, sharedStepID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get shared step items: %v", err)
+	}
+	defer rows.Close()
+
+	var items []*models.SharedStepItem
+	for rows.Next() {
+		item := &models.SharedStepItem{}
+		err := rows.Scan(
+			&item.ID,
+			&item.SharedStepID,
+			&item.StepNumber,
+			&item.StepType,
+			&item.Description,
+			&item.ExpectedResult,
+			&item.CreatedAt,
+			&item.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan shared step item: %v", err)
+		}
+		items = append(items, item)
+	}
+
+	return items, rows.Err()
+}
+
+// ListUsage retrieves the test cases whose steps reference a shared step
+func (r *SharedStepRepository) ListUsage(sharedStepID int64) ([]*models.SharedStepUsage, error) {
+	query := `
+		SELECT tc.id, tc.title, COALESCE(tc.suite_id, 0), ts.id, ts.step_number
+		FROM test_steps ts
+		JOIN test_cases tc ON tc.id = ts.test_case_id
+		WHERE ts.shared_step_id = ? AND tc.deleted_at IS NULL
+		ORDER BY tc.title, ts.step_number`
+
+	rows, err := r.db.Query(query, sharedStepID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list shared step usage: %v", err)
+	}
+	defer rows.Close()
+
+	var usage []*models.SharedStepUsage
+	for rows.Next() {
+		u := &models.SharedStepUsage{}
+		if err := rows.Scan(&u.TestCaseID, &u.Title, &u.SuiteID, &u.StepID, &u.StepNumber); err != nil {
+			return nil, fmt.Errorf("failed to scan shared step usage: %v", err)
+		}
+		usage = append(usage, u)
+	}
+
+	return usage, rows.Err()
+}
+
+// insertSharedStepItems inserts the items of a shared step, numbering them in order
+func insertSharedStepItems(tx *sql.Tx, sharedStep *models.SharedStep, now time.Time) error {
+	query := `
+		INSERT INTO shared_step_items (
+			shared_step_id, step_number, step_type, description,
+			expected_result, created_at, updated_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?)`
+
+	for i, item := range sharedStep.Items {
+		item.SharedStepID = sharedStep.ID
+		item.StepNumber = i + 1
+
+		result, err := tx.Exec(
+			query,
+			item.SharedStepID,
+			item.StepNumber,
+			item.StepType,
+			item.Description,
+			item.ExpectedResult,
+			now,
+			now,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to create shared step item: %v", err)
+		}
+
+		id, err := result.LastInsertId()
+		if err != nil {
+			return fmt.Errorf("failed to get shared step item last insert ID: %v", err)
+		}
+
+		item.ID = id
+		item.CreatedAt = now
+		item.UpdatedAt = now
+	}
+
+	return nil
+}
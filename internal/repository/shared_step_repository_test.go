@@ -0,0 +1,118 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/mihaamiharu/test-case-management-be/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSharedStepRepository_Create(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewSharedStepRepository(db)
+
+	// Test case: shared step and its items are created in one transaction
+	t.Run("Success", func(t *testing.T) {
+		// Setup expectations
+		mock.ExpectQuery("SELECT COUNT").
+			WithArgs("Login as admin", int64(1)).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+		mock.ExpectBegin()
+		mock.ExpectExec("INSERT INTO shared_steps").
+			WithArgs(int64(1), "Login as admin", "", 1, int64(2), int64(2), sqlmock.AnyArg(), sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(10, 1))
+		mock.ExpectExec("INSERT INTO shared_step_items").
+			WithArgs(int64(10), 1, models.StepTypeGiven, "the user is on the login page", "", sqlmock.AnyArg(), sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(100, 1))
+		mock.ExpectExec("INSERT INTO shared_step_items").
+			WithArgs(int64(10), 2, models.StepTypeWhen, "the user logs in as admin", "", sqlmock.AnyArg(), sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(101, 1))
+		mock.ExpectCommit()
+
+		sharedStep := &models.SharedStep{
+			ProjectID: 1,
+			Name:      "Login as admin",
+			CreatedBy: 2,
+			UpdatedBy: 2,
+			Items: []*models.SharedStepItem{
+				{StepType: models.StepTypeGiven, Description: "the user is on the login page"},
+				{StepType: models.StepTypeWhen, Description: "the user logs in as admin"},
+			},
+		}
+
+		// Execute
+		err := repo.Create(sharedStep)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, int64(10), sharedStep.ID)
+		assert.Equal(t, 1, sharedStep.Version)
+		assert.Equal(t, int64(101), sharedStep.Items[1].ID)
+		assert.Equal(t, 2, sharedStep.Items[1].StepNumber)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	// Test case: name already taken in the project
+	t.Run("SharedStepExists", func(t *testing.T) {
+		// Setup expectations
+		mock.ExpectQuery("SELECT COUNT").
+			WithArgs("Login as admin", int64(1)).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+
+		// Execute
+		err := repo.Create(&models.SharedStep{ProjectID: 1, Name: "Login as admin"})
+
+		// Assert
+		assert.Equal(t, ErrSharedStepExists, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestSharedStepRepository_Delete(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewSharedStepRepository(db)
+
+	// Test case: unused shared step is deleted
+	t.Run("Success", func(t *testing.T) {
+		// Setup expectations
+		mock.ExpectQuery("SELECT COUNT").
+			WithArgs(int64(10)).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+		mock.ExpectExec("DELETE FROM shared_steps").
+			WithArgs(int64(10)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		// Execute
+		err := repo.Delete(10)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	// Test case: shared step still referenced by test steps
+	t.Run("InUse", func(t *testing.T) {
+		// Setup expectations
+		mock.ExpectQuery("SELECT COUNT").
+			WithArgs(int64(10)).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+
+		// Execute
+		err := repo.Delete(10)
+
+		// Assert
+		assert.Equal(t, ErrSharedStepInUse, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
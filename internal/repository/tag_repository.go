@@ -18,14 +18,14 @@ var (
 type TagRepositoryInterface interface {
 	Create(tag *models.Tag) error
 	GetByID(id int64) (*models.Tag, error)
-	GetByName(name string) (*models.Tag, error)
-	Delete(id int64) error
-	List() ([]*models.Tag, error)
+	GetByName(organizationID int64, name string) (*models.Tag, error)
+	Delete(organizationID, id int64) error
+	List(organizationID int64) ([]*models.Tag, error)
 	GetTagsByTestCase(testCaseID int64) ([]*models.Tag, error)
 	AddTagToTestCase(testCaseID, tagID int64) error
 	RemoveTagFromTestCase(testCaseID, tagID int64) error
 	UpdateTestCaseTags(testCaseID int64, tagIDs []int64) error
-	GetOrCreateTag(name string) (*models.Tag, error)
+	GetOrCreateTag(organizationID int64, name string) (*models.Tag, error)
 }
 
 // TagRepository handles database operations for tags
@@ -38,10 +38,20 @@ func NewTagRepository(db *sql.DB) *TagRepository {
 	return &TagRepository{db: db}
 }
 
+// tagTestCaseQuery tags a test case with a tag of the organization of its project; tags of
+// other organizations are not inserted
+const tagTestCaseQuery = `
+	INSERT INTO test_case_tags (test_case_id, tag_id)
+	SELECT tc.id, t.id
+	FROM test_cases tc
+	JOIN projects p ON p.id = tc.project_id
+	JOIN tags t ON t.organization_id = p.organization_id
+	WHERE tc.id = ? AND t.id = ?`
+
 // Create adds a new tag to the database
 func (r *TagRepository) Create(tag *models.Tag) error {
-	// Check if tag with name already exists
-	existingTag, err := r.GetByName(tag.Name)
+	// Check if tag with name already exists in the organization
+	existingTag, err := r.GetByName(tag.OrganizationID, tag.Name)
 	if err == nil && existingTag != nil {
 		return ErrTagExists
 	}
@@ -50,11 +60,11 @@ func (r *TagRepository) Create(tag *models.Tag) error {
 	}
 
 	query := `
-		INSERT INTO tags (name, created_at)
-		VALUES (?, ?)`
+		INSERT INTO tags (organization_id, name, created_at)
+		VALUES (?, ?, ?)`
 
 	now := time.Now()
-	result, err := r.db.Exec(query, tag.Name, now)
+	result, err := r.db.Exec(query, tag.OrganizationID, tag.Name, now)
 	if err != nil {
 		return fmt.Errorf("failed to create tag: %v", err)
 	}
@@ -73,11 +83,11 @@ func (r *TagRepository) Create(tag *models.Tag) error {
 func (r *TagRepository) GetByID(id int64) (*models.Tag, error) {
 	tag := &models.Tag{}
 	query := `
-		SELECT id, name, created_at
+		SELECT id, organization_id, name, created_at
 		FROM tags
 		WHERE id = ?`
 
-	err := r.db.QueryRow(query, id).Scan(&tag.ID, &tag.Name, &tag.CreatedAt)
+	err := r.db.QueryRow(query, id).Scan(&tag.ID, &tag.OrganizationID, &tag.Name, &tag.CreatedAt)
 	if err == sql.ErrNoRows {
 		return nil, ErrTagNotFound
 	}
@@ -88,15 +98,15 @@ func (r *TagRepository) GetByID(id int64) (*models.Tag, error) {
 	return tag, nil
 }
 
-// GetByName retrieves a tag by name
-func (r *TagRepository) GetByName(name string) (*models.Tag, error) {
+// GetByName retrieves a tag of an organization by name
+func (r *TagRepository) GetByName(organizationID int64, name string) (*models.Tag, error) {
 	tag := &models.Tag{}
 	query := `
-		SELECT id, name, created_at
+		SELECT id, organization_id, name, created_at
 		FROM tags
-		WHERE name = ?`
+		WHERE organization_id = ? AND name = ?`
 
-	err := r.db.QueryRow(query, name).Scan(&tag.ID, &tag.Name, &tag.CreatedAt)
+	err := r.db.QueryRow(query, organizationID, name).Scan(&tag.ID, &tag.OrganizationID, &tag.Name, &tag.CreatedAt)
 	if err == sql.ErrNoRows {
 		return nil, ErrTagNotFound
 	}
@@ -107,9 +117,9 @@ func (r *TagRepository) GetByName(name string) (*models.Tag, error) {
 	return tag, nil
 }
 
-// Delete removes a tag from the database
-func (r *TagRepository) Delete(id int64) error {
-	result, err := r.db.Exec("DELETE FROM tags WHERE id = ?", id)
+// Delete removes a tag of an organization from the database
+func (r *TagRepository) Delete(organizationID, id int64) error {
+	result, err := r.db.Exec("DELETE FROM tags WHERE id = ? AND organization_id = ?", id, organizationID)
 	if err != nil {
 		return fmt.Errorf("failed to delete tag: %v", err)
 	}
@@ -126,14 +136,15 @@ func (r *TagRepository) Delete(id int64) error {
 	return nil
 }
 
-// List retrieves all tags
-func (r *TagRepository) List() ([]*models.Tag, error) {
+// List retrieves all tags of an organization
+func (r *TagRepository) List(organizationID int64) ([]*models.Tag, error) {
 	query := `
-		SELECT id, name, created_at
+		SELECT id, organization_id, name, created_at
 		FROM tags
+		WHERE organization_id = ?
 		ORDER BY name`
 
-	rows, err := r.db.Query(query)
+	rows, err := r.db.Query(query, organizationID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list tags: %v", err)
 	}
@@ -142,7 +153,7 @@ func (r *TagRepository) List() ([]*models.Tag, error) {
 	var tags []*models.Tag
 	for rows.Next() {
 		tag := &models.Tag{}
-		err := rows.Scan(&tag.ID, &tag.Name, &tag.CreatedAt)
+		err := rows.Scan(&tag.ID, &tag.OrganizationID, &tag.Name, &tag.CreatedAt)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan tag: %v", err)
 		}
@@ -155,7 +166,7 @@ func (r *TagRepository) List() ([]*models.Tag, error) {
 // GetTagsByTestCase retrieves all tags for a specific test case
 func (r *TagRepository) GetTagsByTestCase(testCaseID int64) ([]*models.Tag, error) {
 	query := `
-		SELECT t.id, t.name, t.created_at
+		SELECT t.id, t.organization_id, t.name, t.created_at
 		FROM tags t
 		JOIN test_case_tags tct ON t.id = tct.tag_id
 		WHERE tct.test_case_id = ?
@@ -170,7 +181,7 @@ func (r *TagRepository) GetTagsByTestCase(testCaseID int64) ([]*models.Tag, erro
 	var tags []*models.Tag
 	for rows.Next() {
 		tag := &models.Tag{}
-		err := rows.Scan(&tag.ID, &tag.Name, &tag.CreatedAt)
+		err := rows.Scan(&tag.ID, &tag.OrganizationID, &tag.Name, &tag.CreatedAt)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan tag: %v", err)
 		}
@@ -197,15 +208,19 @@ func (r *TagRepository) AddTagToTestCase(testCaseID, tagID int64) error {
 		return nil
 	}
 
-	query := `
-		INSERT INTO test_case_tags (test_case_id, tag_id)
-		VALUES (?, ?)`
-
-	_, err = r.db.Exec(query, testCaseID, tagID)
+	result, err := r.db.Exec(tagTestCaseQuery, testCaseID, tagID)
 	if err != nil {
 		return fmt.Errorf("failed to add tag to test case: %v", err)
 	}
 
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %v", err)
+	}
+	if rowsAffected == 0 {
+		return ErrTagNotFound
+	}
+
 	return nil
 }
 
@@ -246,25 +261,27 @@ func (r *TagRepository) UpdateTestCaseTags(testCaseID int64, tagIDs []int64) err
 	}
 
 	// Add new tags
-	if len(tagIDs) > 0 {
-		query := `
-			INSERT INTO test_case_tags (test_case_id, tag_id)
-			VALUES (?, ?)`
-
-		for _, tagID := range tagIDs {
-			_, err = tx.Exec(query, testCaseID, tagID)
-			if err != nil {
-				return fmt.Errorf("failed to add tag %d: %v", tagID, err)
-			}
+	for _, tagID := range tagIDs {
+		result, err := tx.Exec(tagTestCaseQuery, testCaseID, tagID)
+		if err != nil {
+			return fmt.Errorf("failed to add tag %d: %v", tagID, err)
+		}
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("failed to get rows affected: %v", err)
+		}
+		if rowsAffected == 0 {
+			return ErrTagNotFound
 		}
 	}
 
 	return tx.Commit()
 }
 
-func (r *TagRepository) GetOrCreateTag(name string) (*models.Tag, error) {
+// GetOrCreateTag retrieves a tag of an organization by name, creating it when it does not exist
+func (r *TagRepository) GetOrCreateTag(organizationID int64, name string) (*models.Tag, error) {
 	// First try to get the existing tag
-	tag, err := r.GetByName(name)
+	tag, err := r.GetByName(organizationID, name)
 	if err == nil {
 		return tag, nil
 	}
@@ -273,7 +290,7 @@ func (r *TagRepository) GetOrCreateTag(name string) (*models.Tag, error) {
 	}
 
 	// Tag doesn't exist, create it
-	tag = &models.Tag{Name: name}
+	tag = &models.Tag{OrganizationID: organizationID, Name: name}
 	err = r.Create(tag)
 	if err != nil {
 		return nil, err
@@ -0,0 +1,425 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mihaamiharu/test-case-management-be/internal/models"
+)
+
+var (
+	ErrTeamNotFound              = errors.New("team not found")
+	ErrTeamExists                = errors.New("team with this name already exists")
+	ErrTeamMemberNotFound        = errors.New("user is not a member of this team")
+	ErrProjectTeamAccessNotFound = errors.New("project team access not found")
+	ErrProjectTeamAccessExists   = errors.New("team already has access to this project")
+)
+
+// TeamRepositoryInterface defines the interface for team repository operations
+type TeamRepositoryInterface interface {
+	Create(team *models.Team) error
+	GetByID(id int64) (*models.Team, error)
+	Update(team *models.Team) error
+	Delete(id int64) error
+	List(organizationID int64) ([]*models.Team, error)
+	ListByMember(organizationID, userID int64) ([]*models.Team, error)
+	AddMembers(teamID int64, userIDs []int64) error
+	RemoveMember(teamID, userID int64) error
+	ListMembers(teamID int64) ([]*models.TeamMember, error)
+	CreateProjectAccess(access *models.ProjectTeamAccess) error
+	GetProjectAccess(id int64) (*models.ProjectTeamAccess, error)
+	UpdateProjectAccess(access *models.ProjectTeamAccess) error
+	DeleteProjectAccess(id int64) error
+	ListProjectAccess(projectID int64) ([]*models.ProjectTeamAccess, error)
+	ListProjectAccessByMember(projectID, userID int64) ([]*models.ProjectTeamAccess, error)
+	GetProjectIDsByMember(userID int64) ([]int64, error)
+}
+
+// TeamRepository handles database operations for teams, their members and their project access
+type TeamRepository struct {
+	db *sql.DB
+}
+
+// NewTeamRepository creates a new team repository
+func NewTeamRepository(db *sql.DB) *TeamRepository {
+	return &TeamRepository{db: db}
+}
+
+// teamColumns are the columns scanned by scanTeam
+const teamColumns = `t.id, t.organization_id, t.name, t.description, t.created_by, t.created_at, t.updated_at,
+	(SELECT COUNT(*) FROM team_members tm WHERE tm.team_id = t.id)`
+
+// projectTeamAccessColumns are the columns scanned by scanProjectTeamAccess
+const projectTeamAccessColumns = `pta.id, pta.project_id, pta.team_id, t.name, pta.level, pta.created_at, pta.updated_at`
+
+// Create adds a new team to the database
+func (r *TeamRepository) Create(team *models.Team) error {
+	if err := r.checkNameAvailable(team.OrganizationID, team.Name, 0); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	result, err := r.db.Exec(`
+		INSERT INTO teams (organization_id, name, description, created_by, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?)`,
+		team.OrganizationID, team.Name, team.Description, team.CreatedBy, now, now)
+	if err != nil {
+		return fmt.Errorf("failed to create team: %v", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to get team ID: %v", err)
+	}
+
+	team.ID = id
+	team.CreatedAt = now
+	team.UpdatedAt = now
+	return nil
+}
+
+// GetByID retrieves a team by ID
+func (r *TeamRepository) GetByID(id int64) (*models.Team, error) {
+	query := "SELECT " + teamColumns + " FROM teams t WHERE t.id = ?"
+
+	team, err := scanTeam(r.db.QueryRow(query, id))
+	if err == sql.ErrNoRows {
+		return nil, ErrTeamNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get team: %v", err)
+	}
+	return team, nil
+}
+
+// Update updates the name and description of a team
+func (r *TeamRepository) Update(team *models.Team) error {
+	if err := r.checkNameAvailable(team.OrganizationID, team.Name, team.ID); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	result, err := r.db.Exec("UPDATE teams SET name = ?, description = ?, updated_at = ? WHERE id = ?",
+		team.Name, team.Description, now, team.ID)
+	if err != nil {
+		return fmt.Errorf("failed to update team: %v", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %v", err)
+	}
+	if rows == 0 {
+		return ErrTeamNotFound
+	}
+
+	team.UpdatedAt = now
+	return nil
+}
+
+// Delete removes a team; its memberships and project access are removed with it
+func (r *TeamRepository) Delete(id int64) error {
+	result, err := r.db.Exec("DELETE FROM teams WHERE id = ?", id)
+	if err != nil {
+		return fmt.Errorf("failed to delete team: %v", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %v", err)
+	}
+	if rows == 0 {
+		return ErrTeamNotFound
+	}
+	return nil
+}
+
+// List retrieves all teams of an organization ordered by name
+func (r *TeamRepository) List(organizationID int64) ([]*models.Team, error) {
+	return r.listTeams("SELECT "+teamColumns+" FROM teams t WHERE t.organization_id = ? ORDER BY t.name", organizationID)
+}
+
+// ListByMember retrieves the teams of an organization a user is a member of, ordered by name
+func (r *TeamRepository) ListByMember(organizationID, userID int64) ([]*models.Team, error) {
+	return r.listTeams("SELECT "+teamColumns+`
+		FROM teams t
+		JOIN team_members m ON m.team_id = t.id
+		WHERE t.organization_id = ? AND m.user_id = ?
+		ORDER BY t.name`, organizationID, userID)
+}
+
+// AddMembers adds users to a team; users who already are members are skipped
+func (r *TeamRepository) AddMembers(teamID int64, userIDs []int64) error {
+	if len(userIDs) == 0 {
+		return nil
+	}
+
+	placeholders := make([]string, len(userIDs))
+	args := make([]interface{}, 0, len(userIDs)*3)
+	now := time.Now()
+	for i, userID := range userIDs {
+		placeholders[i] = "(?, ?, ?)"
+		args = append(args, teamID, userID, now)
+	}
+
+	query := "INSERT IGNORE INTO team_members (team_id, user_id, created_at) VALUES " + strings.Join(placeholders, ", ")
+	if _, err := r.db.Exec(query, args...); err != nil {
+		return fmt.Errorf("failed to add team members: %v", err)
+	}
+	return nil
+}
+
+// RemoveMember removes a user from a team
+func (r *TeamRepository) RemoveMember(teamID, userID int64) error {
+	result, err := r.db.Exec("DELETE FROM team_members WHERE team_id = ? AND user_id = ?", teamID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to remove team member: %v", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %v", err)
+	}
+	if rows == 0 {
+		return ErrTeamMemberNotFound
+	}
+	return nil
+}
+
+// ListMembers retrieves the members of a team ordered by username
+func (r *TeamRepository) ListMembers(teamID int64) ([]*models.TeamMember, error) {
+	rows, err := r.db.Query(`
+		SELECT m.team_id, m.user_id, u.username, u.email, m.created_at
+		FROM team_members m
+		JOIN users u ON u.id = m.user_id
+		WHERE m.team_id = ?
+		ORDER BY u.username`, teamID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list team members: %v", err)
+	}
+	defer rows.Close()
+
+	var members []*models.TeamMember
+	for rows.Next() {
+		member := &models.TeamMember{}
+		if err := rows.Scan(&member.TeamID, &member.UserID, &member.Username, &member.Email, &member.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan team member: %v", err)
+		}
+		members = append(members, member)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list team members: %v", err)
+	}
+	return members, nil
+}
+
+// CreateProjectAccess grants a team access to a project
+func (r *TeamRepository) CreateProjectAccess(access *models.ProjectTeamAccess) error {
+	var count int
+	err := r.db.QueryRow("SELECT COUNT(*) FROM project_team_access WHERE project_id = ? AND team_id = ?",
+		access.ProjectID, access.TeamID).Scan(&count)
+	if err != nil {
+		return fmt.Errorf("failed to check project team access: %v", err)
+	}
+	if count > 0 {
+		return ErrProjectTeamAccessExists
+	}
+
+	now := time.Now()
+	result, err := r.db.Exec(`
+		INSERT INTO project_team_access (project_id, team_id, level, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?)`,
+		access.ProjectID, access.TeamID, access.Level, now, now)
+	if err != nil {
+		return fmt.Errorf("failed to create project team access: %v", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to get project team access ID: %v", err)
+	}
+
+	access.ID = id
+	access.CreatedAt = now
+	access.UpdatedAt = now
+	return nil
+}
+
+// GetProjectAccess retrieves the project access of a team by ID
+func (r *TeamRepository) GetProjectAccess(id int64) (*models.ProjectTeamAccess, error) {
+	query := "SELECT " + projectTeamAccessColumns + `
+		FROM project_team_access pta
+		JOIN teams t ON t.id = pta.team_id
+		WHERE pta.id = ?`
+
+	access, err := scanProjectTeamAccess(r.db.QueryRow(query, id))
+	if err == sql.ErrNoRows {
+		return nil, ErrProjectTeamAccessNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get project team access: %v", err)
+	}
+	return access, nil
+}
+
+// UpdateProjectAccess changes the level of the project access of a team
+func (r *TeamRepository) UpdateProjectAccess(access *models.ProjectTeamAccess) error {
+	now := time.Now()
+	result, err := r.db.Exec("UPDATE project_team_access SET level = ?, updated_at = ? WHERE id = ?",
+		access.Level, now, access.ID)
+	if err != nil {
+		return fmt.Errorf("failed to update project team access: %v", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %v", err)
+	}
+	if rows == 0 {
+		return ErrProjectTeamAccessNotFound
+	}
+
+	access.UpdatedAt = now
+	return nil
+}
+
+// DeleteProjectAccess revokes the project access of a team
+func (r *TeamRepository) DeleteProjectAccess(id int64) error {
+	result, err := r.db.Exec("DELETE FROM project_team_access WHERE id = ?", id)
+	if err != nil {
+		return fmt.Errorf("failed to delete project team access: %v", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %v", err)
+	}
+	if rows == 0 {
+		return ErrProjectTeamAccessNotFound
+	}
+	return nil
+}
+
+// ListProjectAccess retrieves the teams with access to a project ordered by team name
+func (r *TeamRepository) ListProjectAccess(projectID int64) ([]*models.ProjectTeamAccess, error) {
+	return r.listProjectAccess("SELECT "+projectTeamAccessColumns+`
+		FROM project_team_access pta
+		JOIN teams t ON t.id = pta.team_id
+		WHERE pta.project_id = ?
+		ORDER BY t.name`, projectID)
+}
+
+// ListProjectAccessByMember retrieves the access to a project a user has through its teams
+func (r *TeamRepository) ListProjectAccessByMember(projectID, userID int64) ([]*models.ProjectTeamAccess, error) {
+	return r.listProjectAccess("SELECT "+projectTeamAccessColumns+`
+		FROM project_team_access pta
+		JOIN teams t ON t.id = pta.team_id
+		JOIN team_members m ON m.team_id = pta.team_id
+		WHERE pta.project_id = ? AND m.user_id = ?
+		ORDER BY t.name`, projectID, userID)
+}
+
+// GetProjectIDsByMember retrieves the IDs of the projects a user has access to through its teams
+func (r *TeamRepository) GetProjectIDsByMember(userID int64) ([]int64, error) {
+	rows, err := r.db.Query(`
+		SELECT DISTINCT pta.project_id
+		FROM project_team_access pta
+		JOIN team_members m ON m.team_id = pta.team_id
+		WHERE m.user_id = ?`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list team projects: %v", err)
+	}
+	defer rows.Close()
+
+	var projectIDs []int64
+	for rows.Next() {
+		var projectID int64
+		if err := rows.Scan(&projectID); err != nil {
+			return nil, fmt.Errorf("failed to scan team project: %v", err)
+		}
+		projectIDs = append(projectIDs, projectID)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list team projects: %v", err)
+	}
+	return projectIDs, nil
+}
+
+// listTeams runs a query for teams
+func (r *TeamRepository) listTeams(query string, args ...interface{}) ([]*models.Team, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list teams: %v", err)
+	}
+	defer rows.Close()
+
+	var teams []*models.Team
+	for rows.Next() {
+		team, err := scanTeam(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan team: %v", err)
+		}
+		teams = append(teams, team)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list teams: %v", err)
+	}
+	return teams, nil
+}
+
+// listProjectAccess runs a query for project team access
+func (r *TeamRepository) listProjectAccess(query string, args ...interface{}) ([]*models.ProjectTeamAccess, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list project team access: %v", err)
+	}
+	defer rows.Close()
+
+	var accessList []*models.ProjectTeamAccess
+	for rows.Next() {
+		access, err := scanProjectTeamAccess(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan project team access: %v", err)
+		}
+		accessList = append(accessList, access)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list project team access: %v", err)
+	}
+	return accessList, nil
+}
+
+// scanTeam scans a row of teamColumns
+func scanTeam(row rowScanner) (*models.Team, error) {
+	team := &models.Team{}
+	var description sql.NullString
+	err := row.Scan(&team.ID, &team.OrganizationID, &team.Name, &description, &team.CreatedBy, &team.CreatedAt, &team.UpdatedAt, &team.MemberCount)
+	if err != nil {
+		return nil, err
+	}
+	team.Description = description.String
+	return team, nil
+}
+
+// scanProjectTeamAccess scans a row of projectTeamAccessColumns
+func scanProjectTeamAccess(row rowScanner) (*models.ProjectTeamAccess, error) {
+	access := &models.ProjectTeamAccess{}
+	err := row.Scan(&access.ID, &access.ProjectID, &access.TeamID, &access.TeamName, &access.Level, &access.CreatedAt, &access.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return access, nil
+}
+
+// checkNameAvailable reports ErrTeamExists when another team of an organization than
+// excludeID has a name
+func (r *TeamRepository) checkNameAvailable(organizationID int64, name string, excludeID int64) error {
+	var count int
+	err := r.db.QueryRow("SELECT COUNT(*) FROM teams WHERE organization_id = ? AND name = ? AND id != ?",
+		organizationID, name, excludeID).Scan(&count)
+	if err != nil {
+		return fmt.Errorf("failed to check team name: %v", err)
+	}
+	if count > 0 {
+		return ErrTeamExists
+	}
+	return nil
+}
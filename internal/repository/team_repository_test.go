@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/mihaamiharu/test-case-management-be/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTeamRepository_Create(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewTeamRepository(db)
+
+	// Test case: a team with a new name is created
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT COUNT(*) FROM teams WHERE organization_id = ? AND name = ? AND id != ?")).
+		WithArgs(1, "QA", 0).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	mock.ExpectExec("INSERT INTO teams").
+		WithArgs(1, "QA", "Testers", nil, sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(3, 1))
+
+	team := &models.Team{OrganizationID: 1, Name: "QA", Description: "Testers"}
+	err = repo.Create(team)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), team.ID)
+
+	// Test case: team names are unique
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT COUNT(*) FROM teams WHERE organization_id = ? AND name = ? AND id != ?")).
+		WithArgs(1, "QA", 0).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+
+	err = repo.Create(&models.Team{OrganizationID: 1, Name: "QA"})
+
+	assert.Equal(t, ErrTeamExists, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestTeamRepository_AddMembers(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewTeamRepository(db)
+
+	// Test case: all users are added in one statement that skips existing members
+	mock.ExpectExec(regexp.QuoteMeta("INSERT IGNORE INTO team_members (team_id, user_id, created_at) VALUES (?, ?, ?), (?, ?, ?)")).
+		WithArgs(1, 2, sqlmock.AnyArg(), 1, 3, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err = repo.AddMembers(1, []int64{2, 3})
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestTeamRepository_ListProjectAccessByMember(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewTeamRepository(db)
+
+	// Test case: the access of every team of the user is listed
+	now := time.Now()
+	rows := sqlmock.NewRows([]string{"id", "project_id", "team_id", "name", "level", "created_at", "updated_at"}).
+		AddRow(1, 5, 2, "QA", models.AccessLevelEdit, now, now).
+		AddRow(2, 5, 4, "Support", models.AccessLevelView, now, now)
+	mock.ExpectQuery("SELECT (.+) FROM project_team_access pta JOIN teams t ON t.id = pta.team_id JOIN team_members m").
+		WithArgs(5, 7).
+		WillReturnRows(rows)
+
+	accessList, err := repo.ListProjectAccessByMember(5, 7)
+
+	assert.NoError(t, err)
+	assert.Len(t, accessList, 2)
+	assert.Equal(t, "QA", accessList[0].TeamName)
+	assert.Equal(t, models.AccessLevelView, accessList[1].Level)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
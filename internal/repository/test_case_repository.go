@@ -2,6 +2,7 @@ package repository
 
 import (
 	"database/sql"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"time"
@@ -10,7 +11,10 @@ import (
 )
 
 var (
-	ErrTestCaseNotFound = errors.New("test case not found")
+	ErrTestCaseNotFound  = errors.New("test case not found")
+	ErrTestStepNotFound  = errors.New("test step not found")
+	ErrInvalidStepOrder  = errors.New("step order must list every step of the test case exactly once")
+	ErrStepNotInTestCase = errors.New("step does not belong to this test case or is listed more than once")
 )
 
 type TestCaseRepositoryInterface interface {
@@ -22,14 +26,23 @@ type TestCaseRepositoryInterface interface {
 	ListBySuite(suiteID int64) ([]*models.TestCase, error)
 	GetSteps(testCaseID int64) ([]*models.TestStep, error)
 	CreateStep(step *models.TestStep) error
+	InsertStep(step *models.TestStep, position int) error
+	ReorderSteps(testCaseID int64, stepIDs []int64) error
 	UpdateStep(step *models.TestStep) error
 	DeleteStep(stepID int64) error
 	CreateStepNote(note *models.StepNote) error
 	DeleteStepNote(noteID int64) error
+	GetStepNoteByID(noteID int64) (*models.StepNote, error)
+	GetProjectID(testCaseID int64) (int64, error)
 	CreateStepAttachment(attachment *models.StepAttachment) error
 	DeleteStepAttachment(attachmentID int64) error
 	GetStepByID(stepID int64) (*models.TestStep, error)
 	GetStepAttachmentByID(attachmentID int64) (*models.StepAttachment, error)
+	GetDataRows(testCaseID int64) ([]*models.TestCaseDataRow, error)
+	ReplaceDataRows(testCaseID int64, rows []*models.TestCaseDataRow) error
+	UpdateStatus(id int64, status models.TestCaseStatus, updatedBy int64) error
+	CreateHistory(history *models.TestCaseHistory) error
+	ListHistory(testCaseID int64) ([]*models.TestCaseHistory, error)
 }
 
 type TestCaseRepository struct {
@@ -85,6 +98,7 @@ func (r *TestCaseRepository) Create(testCase *models.TestCase) error {
 	}
 
 	testCase.ID = id
+	testCase.Version = 1
 	testCase.CreatedAt = now
 	testCase.UpdatedAt = now
 
@@ -92,9 +106,9 @@ func (r *TestCaseRepository) Create(testCase *models.TestCase) error {
 	if len(testCase.Steps) > 0 {
 		stepQuery := `
 			INSERT INTO test_steps (
-				test_case_id, step_number, step_type, description,
+				test_case_id, shared_step_id, step_number, step_type, description,
 				expected_result, created_at, updated_at
-			) VALUES (?, ?, ?, ?, ?, ?, ?)`
+			) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`
 
 		for i, step := range testCase.Steps {
 			step.TestCaseID = testCase.ID
@@ -103,6 +117,7 @@ func (r *TestCaseRepository) Create(testCase *models.TestCase) error {
 			stepResult, err := tx.Exec(
 				stepQuery,
 				step.TestCaseID,
+				step.SharedStepID,
 				step.StepNumber,
 				step.StepType,
 				step.Description,
@@ -137,7 +152,7 @@ func (r *TestCaseRepository) GetByID(id int64) (*models.TestCase, error) {
 			status, priority, created_by, updated_by, version,
 			created_at, updated_at
 		FROM test_cases
-		WHERE id = ?`
+		WHERE id = ? AND deleted_at IS NULL`
 
 	err := r.db.QueryRow(query, id).Scan(
 		&testCase.ID,
@@ -228,55 +243,123 @@ func (r *TestCaseRepository) Update(testCase *models.TestCase) error {
 	testCase.UpdatedAt = now
 	testCase.Version++
 
-	// Delete existing steps
-	_, err = tx.Exec("DELETE FROM test_steps WHERE test_case_id = ?", testCase.ID)
+	if err := syncSteps(tx, testCase.ID, testCase.Steps, now); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// syncSteps makes the stored steps of a test case match the given steps.
+// Steps with an ID are updated in place so their notes and attachments survive,
+// steps without an ID are inserted, and stored steps that are not listed are deleted.
+func syncSteps(tx *sql.Tx, testCaseID int64, steps []*models.TestStep, now time.Time) error {
+	existingIDs, err := getStepIDs(tx, testCaseID)
 	if err != nil {
-		return fmt.Errorf("failed to delete existing steps: %v", err)
+		return err
 	}
 
-	// Insert updated steps
-	if len(testCase.Steps) > 0 {
-		stepQuery := `
-			INSERT INTO test_steps (
-				test_case_id, step_number, step_type, description,
-				expected_result, created_at, updated_at
-			) VALUES (?, ?, ?, ?, ?, ?, ?)`
+	remaining := make(map[int64]bool, len(existingIDs))
+	for _, id := range existingIDs {
+		remaining[id] = true
+	}
 
-		for i, step := range testCase.Steps {
-			step.TestCaseID = testCase.ID
-			step.StepNumber = i + 1
+	kept := make(map[int64]bool, len(steps))
+	for _, step := range steps {
+		if step.ID == 0 {
+			continue
+		}
+		if !remaining[step.ID] || kept[step.ID] {
+			return ErrStepNotInTestCase
+		}
+		kept[step.ID] = true
+	}
 
-			stepResult, err := tx.Exec(
-				stepQuery,
-				step.TestCaseID,
+	for _, id := range existingIDs {
+		if kept[id] {
+			continue
+		}
+		if _, err := tx.Exec("DELETE FROM test_steps WHERE id = ?", id); err != nil {
+			return fmt.Errorf("failed to delete test step: %v", err)
+		}
+	}
+
+	// Free the step numbers of the surviving steps before they are reassigned
+	if err := negateStepNumbers(tx, testCaseID); err != nil {
+		return err
+	}
+
+	updateQuery := `
+		UPDATE test_steps SET
+			shared_step_id = ?,
+			step_number = ?,
+			step_type = ?,
+			description = ?,
+			expected_result = ?,
+			updated_at = ?
+		WHERE id = ?`
+	insertQuery := `
+		INSERT INTO test_steps (
+			test_case_id, shared_step_id, step_number, step_type, description,
+			expected_result, created_at, updated_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`
+
+	for i, step := range steps {
+		step.TestCaseID = testCaseID
+		step.StepNumber = i + 1
+
+		if step.ID != 0 {
+			_, err := tx.Exec(
+				updateQuery,
+				step.SharedStepID,
 				step.StepNumber,
 				step.StepType,
 				step.Description,
 				step.ExpectedResult,
 				now,
-				now,
+				step.ID,
 			)
-
 			if err != nil {
-				return fmt.Errorf("failed to create test step: %v", err)
+				return fmt.Errorf("failed to update test step: %v", err)
 			}
+			step.UpdatedAt = now
+			continue
+		}
 
-			stepID, err := stepResult.LastInsertId()
-			if err != nil {
-				return fmt.Errorf("failed to get step last insert ID: %v", err)
-			}
+		stepResult, err := tx.Exec(
+			insertQuery,
+			step.TestCaseID,
+			step.SharedStepID,
+			step.StepNumber,
+			step.StepType,
+			step.Description,
+			step.ExpectedResult,
+			now,
+			now,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to create test step: %v", err)
+		}
 
-			step.ID = stepID
-			step.CreatedAt = now
-			step.UpdatedAt = now
+		stepID, err := stepResult.LastInsertId()
+		if err != nil {
+			return fmt.Errorf("failed to get step last insert ID: %v", err)
 		}
+
+		step.ID = stepID
+		step.CreatedAt = now
+		step.UpdatedAt = now
 	}
 
-	return tx.Commit()
+	return nil
 }
 
+// Delete moves a test case to the trash
 func (r *TestCaseRepository) Delete(id int64) error {
-	result, err := r.db.Exec("DELETE FROM test_cases WHERE id = ?", id)
+	result, err := r.db.Exec(
+		"UPDATE test_cases SET deleted_at = ?, deleted_with_parent = FALSE WHERE id = ? AND deleted_at IS NULL",
+		time.Now(), id,
+	)
 	if err != nil {
 		return fmt.Errorf("failed to delete test case: %v", err)
 	}
@@ -300,7 +383,7 @@ func (r *TestCaseRepository) ListByProject(projectID int64) ([]*models.TestCase,
 			status, priority, created_by, updated_by, version,
 			created_at, updated_at
 		FROM test_cases
-		WHERE project_id = ?
+		WHERE project_id = ? AND deleted_at IS NULL
 		ORDER BY title`
 
 	rows, err := r.db.Query(query, projectID)
@@ -351,7 +434,7 @@ func (r *TestCaseRepository) ListBySuite(suiteID int64) ([]*models.TestCase, err
 			status, priority, created_by, updated_by, version,
 			created_at, updated_at
 		FROM test_cases
-		WHERE suite_id = ?
+		WHERE suite_id = ? AND deleted_at IS NULL
 		ORDER BY title`
 
 	rows, err := r.db.Query(query, suiteID)
@@ -398,7 +481,7 @@ func (r *TestCaseRepository) ListBySuite(suiteID int64) ([]*models.TestCase, err
 func (r *TestCaseRepository) GetSteps(testCaseID int64) ([]*models.TestStep, error) {
 	query := `
 		SELECT 
-			id, test_case_id, step_number, step_type, description,
+			id, test_case_id, shared_step_id, step_number, step_type, description,
 			expected_result, created_at, updated_at
 		FROM test_steps
 		WHERE test_case_id = ?
@@ -413,9 +496,11 @@ func (r *TestCaseRepository) GetSteps(testCaseID int64) ([]*models.TestStep, err
 	var steps []*models.TestStep
 	for rows.Next() {
 		step := &models.TestStep{}
+		var sharedStepID sql.NullInt64
 		err := rows.Scan(
 			&step.ID,
 			&step.TestCaseID,
+			&sharedStepID,
 			&step.StepNumber,
 			&step.StepType,
 			&step.Description,
@@ -426,6 +511,9 @@ func (r *TestCaseRepository) GetSteps(testCaseID int64) ([]*models.TestStep, err
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan test step: %v", err)
 		}
+		if sharedStepID.Valid {
+			step.SharedStepID = &sharedStepID.Int64
+		}
 
 		// Get notes for each step
 		notes, err := r.getStepNotes(step.ID)
@@ -451,13 +539,14 @@ func (r *TestCaseRepository) CreateStep(step *models.TestStep) error {
 	now := time.Now()
 	query := `
 		INSERT INTO test_steps (
-			test_case_id, step_number, step_type, description,
+			test_case_id, shared_step_id, step_number, step_type, description,
 			expected_result, created_at, updated_at
-		) VALUES (?, ?, ?, ?, ?, ?, ?)`
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`
 
 	result, err := r.db.Exec(
 		query,
 		step.TestCaseID,
+		step.SharedStepID,
 		step.StepNumber,
 		step.StepType,
 		step.Description,
@@ -486,6 +575,7 @@ func (r *TestCaseRepository) UpdateStep(step *models.TestStep) error {
 	now := time.Now()
 	query := `
 		UPDATE test_steps SET
+			shared_step_id = ?,
 			step_number = ?,
 			step_type = ?,
 			description = ?,
@@ -495,6 +585,7 @@ func (r *TestCaseRepository) UpdateStep(step *models.TestStep) error {
 
 	result, err := r.db.Exec(
 		query,
+		step.SharedStepID,
 		step.StepNumber,
 		step.StepType,
 		step.Description,
@@ -513,7 +604,7 @@ func (r *TestCaseRepository) UpdateStep(step *models.TestStep) error {
 	}
 
 	if rowsAffected == 0 {
-		return fmt.Errorf("test step not found")
+		return ErrTestStepNotFound
 	}
 
 	step.UpdatedAt = now
@@ -521,20 +612,186 @@ func (r *TestCaseRepository) UpdateStep(step *models.TestStep) error {
 }
 
 func (r *TestCaseRepository) DeleteStep(stepID int64) error {
-	result, err := r.db.Exec("DELETE FROM test_steps WHERE id = ?", stepID)
+	tx, err := r.db.Begin()
 	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	var testCaseID int64
+	err = tx.QueryRow("SELECT test_case_id FROM test_steps WHERE id = ?", stepID).Scan(&testCaseID)
+	if err == sql.ErrNoRows {
+		return ErrTestStepNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get test step: %v", err)
+	}
+
+	if _, err := tx.Exec("DELETE FROM test_steps WHERE id = ?", stepID); err != nil {
 		return fmt.Errorf("failed to delete test step: %v", err)
 	}
 
-	rowsAffected, err := result.RowsAffected()
+	// Close the gap left by the deleted step
+	stepIDs, err := getStepIDs(tx, testCaseID)
 	if err != nil {
-		return fmt.Errorf("failed to get rows affected: %v", err)
+		return err
+	}
+	if err := renumberSteps(tx, testCaseID, stepIDs); err != nil {
+		return err
 	}
 
-	if rowsAffected == 0 {
-		return fmt.Errorf("test step not found")
+	return tx.Commit()
+}
+
+// InsertStep inserts a step at the given 1-based position, shifting the following
+// steps down. Existing steps keep their IDs, notes and attachments.
+func (r *TestCaseRepository) InsertStep(step *models.TestStep, position int) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	stepIDs, err := getStepIDs(tx, step.TestCaseID)
+	if err != nil {
+		return err
+	}
+	if position < 1 || position > len(stepIDs)+1 {
+		position = len(stepIDs) + 1
+	}
+
+	// Move the existing steps out of the way before the new step takes its number
+	if err := negateStepNumbers(tx, step.TestCaseID); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	query := `
+		INSERT INTO test_steps (
+			test_case_id, shared_step_id, step_number, step_type, description,
+			expected_result, created_at, updated_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`
+
+	result, err := tx.Exec(
+		query,
+		step.TestCaseID,
+		step.SharedStepID,
+		position,
+		step.StepType,
+		step.Description,
+		step.ExpectedResult,
+		now,
+		now,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create test step: %v", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to get last insert ID: %v", err)
+	}
+
+	order := make([]int64, 0, len(stepIDs)+1)
+	order = append(order, stepIDs[:position-1]...)
+	order = append(order, id)
+	order = append(order, stepIDs[position-1:]...)
+	if err := assignStepNumbers(tx, order); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %v", err)
+	}
+
+	step.ID = id
+	step.StepNumber = position
+	step.CreatedAt = now
+	step.UpdatedAt = now
+
+	return nil
+}
+
+// ReorderSteps renumbers the steps of a test case in the given order.
+// stepIDs must contain every step of the test case exactly once.
+func (r *TestCaseRepository) ReorderSteps(testCaseID int64, stepIDs []int64) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	existingIDs, err := getStepIDs(tx, testCaseID)
+	if err != nil {
+		return err
+	}
+	if len(existingIDs) != len(stepIDs) {
+		return ErrInvalidStepOrder
+	}
+
+	remaining := make(map[int64]bool, len(existingIDs))
+	for _, id := range existingIDs {
+		remaining[id] = true
+	}
+	for _, id := range stepIDs {
+		if !remaining[id] {
+			return ErrInvalidStepOrder
+		}
+		delete(remaining, id)
+	}
+
+	if err := renumberSteps(tx, testCaseID, stepIDs); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// getStepIDs returns the step IDs of a test case in their current order
+func getStepIDs(tx *sql.Tx, testCaseID int64) ([]int64, error) {
+	rows, err := tx.Query("SELECT id FROM test_steps WHERE test_case_id = ? ORDER BY step_number", testCaseID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get test steps: %v", err)
+	}
+	defer rows.Close()
+
+	var stepIDs []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan test step: %v", err)
+		}
+		stepIDs = append(stepIDs, id)
 	}
 
+	return stepIDs, rows.Err()
+}
+
+// renumberSteps numbers the given steps 1..n in order
+func renumberSteps(tx *sql.Tx, testCaseID int64, stepIDs []int64) error {
+	if err := negateStepNumbers(tx, testCaseID); err != nil {
+		return err
+	}
+	return assignStepNumbers(tx, stepIDs)
+}
+
+// negateStepNumbers flips the step numbers of a test case to negative values so
+// they can be reassigned without violating the unique (test_case_id, step_number) key
+func negateStepNumbers(tx *sql.Tx, testCaseID int64) error {
+	_, err := tx.Exec("UPDATE test_steps SET step_number = -step_number WHERE test_case_id = ? AND step_number > 0", testCaseID)
+	if err != nil {
+		return fmt.Errorf("failed to renumber test steps: %v", err)
+	}
+	return nil
+}
+
+// assignStepNumbers sets the step number of each step to its 1-based index
+func assignStepNumbers(tx *sql.Tx, stepIDs []int64) error {
+	for i, id := range stepIDs {
+		if _, err := tx.Exec("UPDATE test_steps SET step_number = ? WHERE id = ?", i+1, id); err != nil {
+			return fmt.Errorf("failed to renumber test steps: %v", err)
+		}
+	}
 	return nil
 }
 
@@ -600,6 +857,45 @@ func (r *TestCaseRepository) CreateStepNote(note *models.StepNote) error {
 	return nil
 }
 
+// GetStepNoteByID retrieves a step note by ID
+func (r *TestCaseRepository) GetStepNoteByID(noteID int64) (*models.StepNote, error) {
+	query := `
+		SELECT id, step_id, note_text, created_by, created_at
+		FROM step_notes
+		WHERE id = ?`
+
+	note := &models.StepNote{}
+	err := r.db.QueryRow(query, noteID).Scan(
+		&note.ID,
+		&note.StepID,
+		&note.Content,
+		&note.CreatedBy,
+		&note.CreatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("step note not found")
+		}
+		return nil, fmt.Errorf("failed to get step note: %v", err)
+	}
+
+	return note, nil
+}
+
+// GetProjectID retrieves the project a test case belongs to
+func (r *TestCaseRepository) GetProjectID(testCaseID int64) (int64, error) {
+	var projectID int64
+	err := r.db.QueryRow("SELECT project_id FROM test_cases WHERE id = ?", testCaseID).Scan(&projectID)
+	if err == sql.ErrNoRows {
+		return 0, ErrTestCaseNotFound
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to get test case project: %v", err)
+	}
+
+	return projectID, nil
+}
+
 func (r *TestCaseRepository) DeleteStepNote(noteID int64) error {
 	result, err := r.db.Exec("DELETE FROM step_notes WHERE id = ?", noteID)
 	if err != nil {
@@ -707,15 +1003,17 @@ func (r *TestCaseRepository) DeleteStepAttachment(attachmentID int64) error {
 func (r *TestCaseRepository) GetStepByID(stepID int64) (*models.TestStep, error) {
 	query := `
 		SELECT 
-			id, test_case_id, step_number, step_type, description,
+			id, test_case_id, shared_step_id, step_number, step_type, description,
 			expected_result, created_at, updated_at
 		FROM test_steps
 		WHERE id = ?`
 
 	step := &models.TestStep{}
+	var sharedStepID sql.NullInt64
 	err := r.db.QueryRow(query, stepID).Scan(
 		&step.ID,
 		&step.TestCaseID,
+		&sharedStepID,
 		&step.StepNumber,
 		&step.StepType,
 		&step.Description,
@@ -726,10 +1024,13 @@ func (r *TestCaseRepository) GetStepByID(stepID int64) (*models.TestStep, error)
 
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("test step not found")
+			return nil, ErrTestStepNotFound
 		}
 		return nil, fmt.Errorf("failed to get test step: %v", err)
 	}
+	if sharedStepID.Valid {
+		step.SharedStepID = &sharedStepID.Int64
+	}
 
 	// Get notes for the step
 	notes, err := r.getStepNotes(step.ID)
@@ -775,3 +1076,187 @@ func (r *TestCaseRepository) GetStepAttachmentByID(attachmentID int64) (*models.
 
 	return attachment, nil
 }
+
+// GetDataRows retrieves the parameter data rows of a test case in order
+func (r *TestCaseRepository) GetDataRows(testCaseID int64) ([]*models.TestCaseDataRow, error) {
+	query := `
+		SELECT id, test_case_id, row_index, COALESCE(name, ''), parameter_values, created_at, updated_at
+		FROM test_case_data_rows
+		WHERE test_case_id = ?
+		ORDER BY row_index`
+
+	rows, err := r.db.Query(query, testCaseID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get data rows: %v", err)
+	}
+	defer rows.Close()
+
+	var dataRows []*models.TestCaseDataRow
+	for rows.Next() {
+		dataRow := &models.TestCaseDataRow{}
+		var values []byte
+		err := rows.Scan(
+			&dataRow.ID,
+			&dataRow.TestCaseID,
+			&dataRow.RowNumber,
+			&dataRow.Name,
+			&values,
+			&dataRow.CreatedAt,
+			&dataRow.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan data row: %v", err)
+		}
+		if err := json.Unmarshal(values, &dataRow.Values); err != nil {
+			return nil, fmt.Errorf("failed to decode data row values: %v", err)
+		}
+		dataRows = append(dataRows, dataRow)
+	}
+
+	return dataRows, rows.Err()
+}
+
+// ReplaceDataRows replaces the whole data table of a test case
+func (r *TestCaseRepository) ReplaceDataRows(testCaseID int64, dataRows []*models.TestCaseDataRow) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec("DELETE FROM test_case_data_rows WHERE test_case_id = ?", testCaseID)
+	if err != nil {
+		return fmt.Errorf("failed to delete existing data rows: %v", err)
+	}
+
+	now := time.Now()
+	query := `
+		INSERT INTO test_case_data_rows (
+			test_case_id, row_index, name, parameter_values, created_at, updated_at
+		) VALUES (?, ?, ?, ?, ?, ?)`
+
+	for i, dataRow := range dataRows {
+		dataRow.TestCaseID = testCaseID
+		dataRow.RowNumber = i + 1
+
+		values, err := json.Marshal(dataRow.Values)
+		if err != nil {
+			return fmt.Errorf("failed to encode data row values: %v", err)
+		}
+
+		result, err := tx.Exec(query, dataRow.TestCaseID, dataRow.RowNumber, dataRow.Name, values, now, now)
+		if err != nil {
+			return fmt.Errorf("failed to create data row: %v", err)
+		}
+
+		id, err := result.LastInsertId()
+		if err != nil {
+			return fmt.Errorf("failed to get data row last insert ID: %v", err)
+		}
+
+		dataRow.ID = id
+		dataRow.CreatedAt = now
+		dataRow.UpdatedAt = now
+	}
+
+	return tx.Commit()
+}
+
+// UpdateStatus changes the status of a test case without creating a new version,
+// so reviews of the current version stay valid
+func (r *TestCaseRepository) UpdateStatus(id int64, status models.TestCaseStatus, updatedBy int64) error {
+	result, err := r.db.Exec(
+		"UPDATE test_cases SET status = ?, updated_by = ?, updated_at = ? WHERE id = ?",
+		status, updatedBy, time.Now(), id,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update test case status: %v", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %v", err)
+	}
+	if rowsAffected == 0 {
+		return ErrTestCaseNotFound
+	}
+
+	return nil
+}
+
+// CreateHistory records a version of a test case in its edit history
+func (r *TestCaseRepository) CreateHistory(history *models.TestCaseHistory) error {
+	now := time.Now()
+	query := `
+		INSERT INTO test_case_history (
+			test_case_id, title, description, preconditions, status, priority,
+			version, changed_by, change_summary, created_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+
+	result, err := r.db.Exec(
+		query,
+		history.TestCaseID,
+		history.Title,
+		history.Description,
+		history.Preconditions,
+		history.Status,
+		history.Priority,
+		history.Version,
+		history.ChangedBy,
+		history.ChangeSummary,
+		now,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create test case history: %v", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to get last insert ID: %v", err)
+	}
+
+	history.ID = id
+	history.CreatedAt = now
+
+	return nil
+}
+
+// ListHistory retrieves the edit history of a test case, newest first
+func (r *TestCaseRepository) ListHistory(testCaseID int64) ([]*models.TestCaseHistory, error) {
+	query := `
+		SELECT id, test_case_id, title, COALESCE(description, ''), COALESCE(preconditions, ''),
+			status, priority, version, changed_by, COALESCE(change_summary, ''), created_at
+		FROM test_case_history
+		WHERE test_case_id = ?
+		ORDER BY version DESC, id DESC`
+
+	rows, err := r.db.Query(query, testCaseID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list test case history: %v", err)
+	}
+	defer rows.Close()
+
+	var history []*models.TestCaseHistory
+	for rows.Next() {
+		entry := &models.TestCaseHistory{}
+		err := rows.Scan(
+			&entry.ID,
+			&entry.TestCaseID,
+			&entry.Title,
+			&entry.Description,
+			&entry.Preconditions,
+			&entry.Status,
+			&entry.Priority,
+			&entry.Version,
+			&entry.ChangedBy,
+			&entry.ChangeSummary,
+			&entry.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan test case history: %v", err)
+		}
+		history = append(history, entry)
+	}
+
+	return history, rows.Err()
+}
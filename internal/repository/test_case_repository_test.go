@@ -0,0 +1,257 @@
+package repository
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/mihaamiharu/test-case-management-be/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTestCaseRepository_InsertStep(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewTestCaseRepository(db)
+
+	// Test case: a step inserted in the middle shifts the following steps down
+	t.Run("Success", func(t *testing.T) {
+		// Setup expectations
+		mock.ExpectBegin()
+		mock.ExpectQuery("SELECT id FROM test_steps").
+			WithArgs(int64(1)).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(11).AddRow(12))
+		mock.ExpectExec("UPDATE test_steps SET step_number = -step_number").
+			WithArgs(int64(1)).
+			WillReturnResult(sqlmock.NewResult(0, 2))
+		mock.ExpectExec("INSERT INTO test_steps").
+			WithArgs(int64(1), nil, 2, models.StepTypeWhen, "the user submits the form", "", sqlmock.AnyArg(), sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(13, 1))
+		mock.ExpectExec("UPDATE test_steps SET step_number = \\?").
+			WithArgs(1, int64(11)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("UPDATE test_steps SET step_number = \\?").
+			WithArgs(2, int64(13)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("UPDATE test_steps SET step_number = \\?").
+			WithArgs(3, int64(12)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		step := &models.TestStep{
+			TestCaseID:  1,
+			StepType:    models.StepTypeWhen,
+			Description: "the user submits the form",
+		}
+
+		// Execute
+		err := repo.InsertStep(step, 2)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, int64(13), step.ID)
+		assert.Equal(t, 2, step.StepNumber)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestTestCaseRepository_ReorderSteps(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewTestCaseRepository(db)
+
+	// Test case: steps are renumbered in the requested order
+	t.Run("Success", func(t *testing.T) {
+		// Setup expectations
+		mock.ExpectBegin()
+		mock.ExpectQuery("SELECT id FROM test_steps").
+			WithArgs(int64(1)).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(11).AddRow(12))
+		mock.ExpectExec("UPDATE test_steps SET step_number = -step_number").
+			WithArgs(int64(1)).
+			WillReturnResult(sqlmock.NewResult(0, 2))
+		mock.ExpectExec("UPDATE test_steps SET step_number = \\?").
+			WithArgs(1, int64(12)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("UPDATE test_steps SET step_number = \\?").
+			WithArgs(2, int64(11)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		// Execute
+		err := repo.ReorderSteps(1, []int64{12, 11})
+
+		// Assert
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	// Test case: an order that does not list every step exactly once is rejected
+	t.Run("InvalidOrder", func(t *testing.T) {
+		// Setup expectations
+		mock.ExpectBegin()
+		mock.ExpectQuery("SELECT id FROM test_steps").
+			WithArgs(int64(1)).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(11).AddRow(12))
+		mock.ExpectRollback()
+
+		// Execute
+		err := repo.ReorderSteps(1, []int64{11, 11})
+
+		// Assert
+		assert.Equal(t, ErrInvalidStepOrder, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestTestCaseRepository_Update(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewTestCaseRepository(db)
+
+	testCase := func(steps ...*models.TestStep) *models.TestCase {
+		return &models.TestCase{
+			ID:        1,
+			ProjectID: 1,
+			Title:     "Login",
+			Status:    models.StatusDraft,
+			Priority:  models.PriorityMedium,
+			UpdatedBy: 2,
+			Steps:     steps,
+		}
+	}
+
+	// Test case: listed steps are updated in place, new ones inserted and the rest deleted
+	t.Run("PreservesStepIdentity", func(t *testing.T) {
+		// Setup expectations
+		mock.ExpectBegin()
+		mock.ExpectExec("UPDATE test_cases SET").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectQuery("SELECT id FROM test_steps").
+			WithArgs(int64(1)).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(11).AddRow(12))
+		mock.ExpectExec("DELETE FROM test_steps WHERE id = \\?").
+			WithArgs(int64(11)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("UPDATE test_steps SET step_number = -step_number").
+			WithArgs(int64(1)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("INSERT INTO test_steps").
+			WithArgs(int64(1), nil, 1, models.StepTypeGiven, "a new first step", "", sqlmock.AnyArg(), sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(13, 1))
+		mock.ExpectExec("UPDATE test_steps SET").
+			WithArgs(nil, 2, models.StepTypeThen, "the dashboard is shown", "", sqlmock.AnyArg(), int64(12)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		update := testCase(
+			&models.TestStep{StepType: models.StepTypeGiven, Description: "a new first step"},
+			&models.TestStep{ID: 12, StepType: models.StepTypeThen, Description: "the dashboard is shown"},
+		)
+
+		// Execute
+		err := repo.Update(update)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, int64(13), update.Steps[0].ID)
+		assert.Equal(t, int64(12), update.Steps[1].ID)
+		assert.Equal(t, 2, update.Steps[1].StepNumber)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	// Test case: a step ID from another test case is rejected
+	t.Run("ForeignStep", func(t *testing.T) {
+		// Setup expectations
+		mock.ExpectBegin()
+		mock.ExpectExec("UPDATE test_cases SET").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectQuery("SELECT id FROM test_steps").
+			WithArgs(int64(1)).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(11))
+		mock.ExpectRollback()
+
+		// Execute
+		err := repo.Update(testCase(&models.TestStep{ID: 99, StepType: models.StepTypeGiven, Description: "foreign"}))
+
+		// Assert
+		assert.Equal(t, ErrStepNotInTestCase, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestTestCaseRepository_GetDataRows(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewTestCaseRepository(db)
+	now := time.Now()
+
+	// Test case: data rows are read in the order of their index
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT id, test_case_id, row_index, COALESCE(name, ''), parameter_values, created_at, updated_at")).
+		WithArgs(int64(1)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "test_case_id", "row_index", "name", "parameter_values", "created_at", "updated_at"}).
+			AddRow(10, 1, 1, "admin", []byte(`{"username":"admin"}`), now, now).
+			AddRow(11, 1, 2, "", []byte(`{"username":"viewer"}`), now, now))
+
+	dataRows, err := repo.GetDataRows(1)
+
+	require.NoError(t, err)
+	require.Len(t, dataRows, 2)
+	assert.Equal(t, 1, dataRows[0].RowNumber)
+	assert.Equal(t, "admin", dataRows[0].Name)
+	assert.Equal(t, map[string]string{"username": "viewer"}, dataRows[1].Values)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestTestCaseRepository_ReplaceDataRows(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewTestCaseRepository(db)
+	insert := regexp.QuoteMeta("INSERT INTO test_case_data_rows ( test_case_id, row_index, name, parameter_values, created_at, updated_at )")
+
+	// Test case: the data rows are replaced and numbered in the order given
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM test_case_data_rows WHERE test_case_id = ?")).
+		WithArgs(int64(1)).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+	mock.ExpectExec(insert).
+		WithArgs(int64(1), 1, "admin", []byte(`{"username":"admin"}`), sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(20, 1))
+	mock.ExpectExec(insert).
+		WithArgs(int64(1), 2, "viewer", []byte(`{"username":"viewer"}`), sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(21, 1))
+	mock.ExpectCommit()
+
+	dataRows := []*models.TestCaseDataRow{
+		{Name: "admin", Values: map[string]string{"username": "admin"}},
+		{Name: "viewer", Values: map[string]string{"username": "viewer"}},
+	}
+	err = repo.ReplaceDataRows(1, dataRows)
+
+	require.NoError(t, err)
+	assert.Equal(t, int64(21), dataRows[1].ID)
+	assert.Equal(t, 2, dataRows[1].RowNumber)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
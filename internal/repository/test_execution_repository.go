@@ -0,0 +1,355 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/mihaamiharu/test-case-management-be/internal/models"
+)
+
+var (
+	ErrTestRunNotFound       = errors.New("test run not found")
+	ErrTestExecutionNotFound = errors.New("test execution not found")
+	ErrTestCaseAlreadyInRun  = errors.New("test case is already part of this test run")
+)
+
+// TestExecutionRepositoryInterface defines the interface for test execution repository operations
+type TestExecutionRepositoryInterface interface {
+	GetRunProjectID(runID int64) (int64, error)
+	CreateBatch(executions []*models.TestExecution) error
+	GetByID(id int64) (*models.TestExecution, error)
+	ListByRun(runID int64) ([]*models.TestExecution, error)
+	UpdateResult(execution *models.TestExecution) error
+	GetRun(runID int64) (*models.TestRun, error)
+	StartRun(runID int64) (bool, error)
+	CompleteRun(runID int64) (bool, error)
+	AssignRun(runID int64, userID *int64) error
+}
+
+// TestExecutionRepository handles database operations for test executions
+type TestExecutionRepository struct {
+	db *sql.DB
+}
+
+// NewTestExecutionRepository creates a new test execution repository
+func NewTestExecutionRepository(db *sql.DB) *TestExecutionRepository {
+	return &TestExecutionRepository{db: db}
+}
+
+// GetRunProjectID retrieves the project a test run belongs to
+func (r *TestExecutionRepository) GetRunProjectID(runID int64) (int64, error) {
+	var projectID int64
+	err := r.db.QueryRow("SELECT project_id FROM test_runs WHERE id = ?", runID).Scan(&projectID)
+	if err == sql.ErrNoRows {
+		return 0, ErrTestRunNotFound
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to get test run: %v", err)
+	}
+	return projectID, nil
+}
+
+// GetRun retrieves a test run with the number of its executions per status
+func (r *TestExecutionRepository) GetRun(runID int64) (*models.TestRun, error) {
+	run := &models.TestRun{Results: make(map[models.ExecutionStatus]int)}
+	var startedAt, completedAt sql.NullTime
+	err := r.db.QueryRow(`
+		SELECT id, project_id, name, status, started_at, completed_at, assigned_to
+		FROM test_runs
+		WHERE id = ?`, runID).Scan(&run.ID, &run.ProjectID, &run.Name, &run.Status, &startedAt, &completedAt, &run.AssignedTo)
+	if err == sql.ErrNoRows {
+		return nil, ErrTestRunNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get test run: %v", err)
+	}
+	if startedAt.Valid {
+		run.StartedAt = &startedAt.Time
+	}
+	if completedAt.Valid {
+		run.CompletedAt = &completedAt.Time
+	}
+
+	rows, err := r.db.Query("SELECT status, COUNT(*) FROM test_executions WHERE test_run_id = ? GROUP BY status", runID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count test executions: %v", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var status models.ExecutionStatus
+		var count int
+		if err := rows.Scan(&status, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan test execution count: %v", err)
+		}
+		run.Results[status] = count
+	}
+
+	return run, rows.Err()
+}
+
+// AssignRun assigns a test run to a user, or unassigns it when userID is nil
+func (r *TestExecutionRepository) AssignRun(runID int64, userID *int64) error {
+	result, err := r.db.Exec("UPDATE test_runs SET assigned_to = ?, updated_at = ? WHERE id = ?", userID, time.Now(), runID)
+	if err != nil {
+		return fmt.Errorf("failed to assign test run: %v", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %v", err)
+	}
+	if rows == 0 {
+		return ErrTestRunNotFound
+	}
+	return nil
+}
+
+// StartRun moves a planned test run in progress and reports whether it did
+func (r *TestExecutionRepository) StartRun(runID int64) (bool, error) {
+	now := time.Now()
+	result, err := r.db.Exec(`
+		UPDATE test_runs SET status = ?, started_at = ?, updated_at = ?
+		WHERE id = ? AND status = ?`,
+		models.TestRunStatusInProgress, now, now, runID, models.TestRunStatusPlanned)
+	if err != nil {
+		return false, fmt.Errorf("failed to start test run: %v", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %v", err)
+	}
+	return rows > 0, nil
+}
+
+// CompleteRun completes a test run in progress once none of its executions is pending, and
+// reports whether it did
+func (r *TestExecutionRepository) CompleteRun(runID int64) (bool, error) {
+	now := time.Now()
+	result, err := r.db.Exec(`
+		UPDATE test_runs SET status = ?, completed_at = ?, updated_at = ?
+		WHERE id = ? AND status = ?
+			AND NOT EXISTS (SELECT 1 FROM test_executions WHERE test_run_id = ? AND status = ?)`,
+		models.TestRunStatusCompleted, now, now, runID, models.TestRunStatusInProgress, runID, models.ExecutionStatusPending)
+	if err != nil {
+		return false, fmt.Errorf("failed to complete test run: %v", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %v", err)
+	}
+	return rows > 0, nil
+}
+
+// CreateBatch inserts the executions of one test case in a single transaction
+func (r *TestExecutionRepository) CreateBatch(executions []*models.TestExecution) error {
+	if len(executions) == 0 {
+		return nil
+	}
+
+	// A test case is added to a run once; its data rows are expanded together
+	var count int
+	err := r.db.QueryRow("SELECT COUNT(*) FROM test_executions WHERE test_run_id = ? AND test_case_id = ?",
+		executions[0].TestRunID, executions[0].TestCaseID).Scan(&count)
+	if err != nil {
+		return fmt.Errorf("failed to check existing executions: %v", err)
+	}
+	if count > 0 {
+		return ErrTestCaseAlreadyInRun
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	query := `
+		INSERT INTO test_executions (
+			test_run_id, test_case_id, data_row_id, parameter_values,
+			status, created_at, updated_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?)`
+
+	for _, execution := range executions {
+		var parameters []byte
+		if execution.Parameters != nil {
+			parameters, err = json.Marshal(execution.Parameters)
+			if err != nil {
+				return fmt.Errorf("failed to encode execution parameters: %v", err)
+			}
+		}
+
+		result, err := tx.Exec(
+			query,
+			execution.TestRunID,
+			execution.TestCaseID,
+			execution.DataRowID,
+			parameters,
+			execution.Status,
+			now,
+			now,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to create test execution: %v", err)
+		}
+
+		id, err := result.LastInsertId()
+		if err != nil {
+			return fmt.Errorf("failed to get last insert ID: %v", err)
+		}
+
+		execution.ID = id
+		execution.CreatedAt = now
+		execution.UpdatedAt = now
+	}
+
+	return tx.Commit()
+}
+
+// GetByID retrieves a test execution by ID
+func (r *TestExecutionRepository) GetByID(id int64) (*models.TestExecution, error) {
+	query := `
+		SELECT id, test_run_id, test_case_id, data_row_id, parameter_values, status,
+			executed_by, execution_time, COALESCE(notes, ''), executed_at, created_at, updated_at
+		FROM test_executions
+		WHERE id = ?`
+
+	execution, err := scanTestExecution(r.db.QueryRow(query, id))
+	if err == sql.ErrNoRows {
+		return nil, ErrTestExecutionNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get test execution: %v", err)
+	}
+
+	return execution, nil
+}
+
+// ListByRun retrieves all executions of a test run
+func (r *TestExecutionRepository) ListByRun(runID int64) ([]*models.TestExecution, error) {
+	query := `
+		SELECT id, test_run_id, test_case_id, data_row_id, parameter_values, status,
+			executed_by, execution_time, COALESCE(notes, ''), executed_at, created_at, updated_at
+		FROM test_executions
+		WHERE test_run_id = ?
+		ORDER BY test_case_id, id`
+
+	rows, err := r.db.Query(query, runID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list test executions: %v", err)
+	}
+	defer rows.Close()
+
+	var executions []*models.TestExecution
+	for rows.Next() {
+		execution, err := scanTestExecution(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan test execution: %v", err)
+		}
+		executions = append(executions, execution)
+	}
+
+	return executions, rows.Err()
+}
+
+// UpdateResult records the result of a test execution
+func (r *TestExecutionRepository) UpdateResult(execution *models.TestExecution) error {
+	now := time.Now()
+	query := `
+		UPDATE test_executions SET
+			status = ?,
+			executed_by = ?,
+			execution_time = ?,
+			notes = ?,
+			executed_at = ?,
+			updated_at = ?
+		WHERE id = ?`
+
+	result, err := r.db.Exec(
+		query,
+		execution.Status,
+		execution.ExecutedBy,
+		execution.ExecutionTime,
+		execution.Notes,
+		now,
+		now,
+		execution.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update test execution: %v", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %v", err)
+	}
+	if rowsAffected == 0 {
+		return ErrTestExecutionNotFound
+	}
+
+	execution.ExecutedAt = &now
+	execution.UpdatedAt = now
+	return nil
+}
+
+// rowScanner is implemented by both *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanTestExecution scans a test execution row including its nullable columns
+func scanTestExecution(row rowScanner) (*models.TestExecution, error) {
+	execution := &models.TestExecution{}
+	var (
+		dataRowID     sql.NullInt64
+		parameters    []byte
+		executedBy    sql.NullInt64
+		executionTime sql.NullInt64
+		executedAt    sql.NullTime
+	)
+
+	err := row.Scan(
+		&execution.ID,
+		&execution.TestRunID,
+		&execution.TestCaseID,
+		&dataRowID,
+		&parameters,
+		&execution.Status,
+		&executedBy,
+		&executionTime,
+		&execution.Notes,
+		&executedAt,
+		&execution.CreatedAt,
+		&execution.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if dataRowID.Valid {
+		execution.DataRowID = &dataRowID.Int64
+	}
+	if len(parameters) > 0 {
+		if err := json.Unmarshal(parameters, &execution.Parameters); err != nil {
+			return nil, err
+		}
+	}
+	if executedBy.Valid {
+		execution.ExecutedBy = &executedBy.Int64
+	}
+	if executionTime.Valid {
+		seconds := int(executionTime.Int64)
+		execution.ExecutionTime = &seconds
+	}
+	if executedAt.Valid {
+		execution.ExecutedAt = &executedAt.Time
+	}
+
+	return execution, nil
+}
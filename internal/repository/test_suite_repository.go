@@ -11,6 +11,7 @@ import (
 var (
 	ErrTestSuiteNotFound = errors.New("test suite not found")
 	ErrTestSuiteExists   = errors.New("test suite with this name already exists in the project")
+	ErrTestSuiteInTrash  = errors.New("a test suite with this name is in the trash of the project; restore it or delete it permanently first")
 )
 
 // TestSuiteRepositoryInterface defines the interface for test suite repository operations
@@ -20,7 +21,7 @@ type TestSuiteRepositoryInterface interface {
 	Update(suite *models.TestSuite) error
 	Delete(id int64) error
 	ListByProject(projectID int64) ([]*models.TestSuite, error)
-	List() ([]*models.TestSuite, error)
+	SetArchived(id int64, archived bool) error
 }
 
 // TestSuiteRepository handles database operations for test suites
@@ -36,15 +37,9 @@ func NewTestSuiteRepository(db *sql.DB) *TestSuiteRepository {
 // Create adds a new test suite to the database
 func (r *TestSuiteRepository) Create(suite *models.TestSuite) error {
 	// Check if suite with name already exists in this project
-	var count int
-	err := r.db.QueryRow("SELECT COUNT(*) FROM test_suites WHERE name = ? AND project_id = ?",
-		suite.Name, suite.ProjectID).Scan(&count)
-	if err != nil {
+	if err := r.checkName(suite.ProjectID, suite.Name, 0); err != nil {
 		return err
 	}
-	if count > 0 {
-		return ErrTestSuiteExists
-	}
 
 	// Insert new test suite
 	query := `
@@ -68,12 +63,33 @@ func (r *TestSuiteRepository) Create(suite *models.TestSuite) error {
 	return nil
 }
 
+// checkName checks that no other suite of a project has a name. Suites in the trash keep
+// their name until they are purged, so that they can be restored.
+func (r *TestSuiteRepository) checkName(projectID int64, name string, suiteID int64) error {
+	var live, trashed int
+	err := r.db.QueryRow(`
+		SELECT COUNT(CASE WHEN deleted_at IS NULL THEN 1 END), COUNT(deleted_at)
+		FROM test_suites
+		WHERE name = ? AND project_id = ? AND id != ?`,
+		name, projectID, suiteID).Scan(&live, &trashed)
+	if err != nil {
+		return err
+	}
+	if live > 0 {
+		return ErrTestSuiteExists
+	}
+	if trashed > 0 {
+		return ErrTestSuiteInTrash
+	}
+	return nil
+}
+
 // GetByID retrieves a test suite by ID
 func (r *TestSuiteRepository) GetByID(id int64) (*models.TestSuite, error) {
 	query := `
-		SELECT id, project_id, name, description, created_at, updated_at
+		SELECT id, project_id, name, description, archived_at, created_at, updated_at
 		FROM test_suites
-		WHERE id = ?
+		WHERE id = ? AND deleted_at IS NULL
 	`
 	suite := &models.TestSuite{}
 	err := r.db.QueryRow(query, id).Scan(
@@ -81,6 +97,7 @@ func (r *TestSuiteRepository) GetByID(id int64) (*models.TestSuite, error) {
 		&suite.ProjectID,
 		&suite.Name,
 		&suite.Description,
+		&suite.ArchivedAt,
 		&suite.CreatedAt,
 		&suite.UpdatedAt,
 	)
@@ -103,15 +120,9 @@ func (r *TestSuiteRepository) Update(suite *models.TestSuite) error {
 
 	// Check if the new name conflicts with another suite in the same project
 	if suite.Name != "" {
-		var count int
-		err := r.db.QueryRow("SELECT COUNT(*) FROM test_suites WHERE name = ? AND project_id = ? AND id != ?",
-			suite.Name, suite.ProjectID, suite.ID).Scan(&count)
-		if err != nil {
+		if err := r.checkName(suite.ProjectID, suite.Name, suite.ID); err != nil {
 			return err
 		}
-		if count > 0 {
-			return ErrTestSuiteExists
-		}
 	}
 
 	// Update suite
@@ -130,7 +141,7 @@ func (r *TestSuiteRepository) Update(suite *models.TestSuite) error {
 	return nil
 }
 
-// Delete removes a test suite from the database
+// Delete moves a test suite to the trash together with its test cases
 func (r *TestSuiteRepository) Delete(id int64) error {
 	// Check if suite exists
 	_, err := r.GetByID(id)
@@ -138,18 +149,33 @@ func (r *TestSuiteRepository) Delete(id int64) error {
 		return err
 	}
 
-	// Delete suite
-	query := `DELETE FROM test_suites WHERE id = ?`
-	_, err = r.db.Exec(query, id)
-	return err
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	if _, err := tx.Exec("UPDATE test_suites SET deleted_at = ?, deleted_with_parent = FALSE WHERE id = ?", now, id); err != nil {
+		return err
+	}
+
+	// Test cases already in the trash keep their own deletion so they are not restored with the suite
+	if _, err := tx.Exec(`
+		UPDATE test_cases SET deleted_at = ?, deleted_with_parent = TRUE
+		WHERE suite_id = ? AND deleted_at IS NULL`, now, id); err != nil {
+		return err
+	}
+
+	return tx.Commit()
 }
 
 // ListByProject retrieves all test suites for a specific project
 func (r *TestSuiteRepository) ListByProject(projectID int64) ([]*models.TestSuite, error) {
 	query := `
-		SELECT id, project_id, name, description, created_at, updated_at
+		SELECT id, project_id, name, description, archived_at, created_at, updated_at
 		FROM test_suites
-		WHERE project_id = ?
+		WHERE project_id = ? AND deleted_at IS NULL
 		ORDER BY name ASC
 	`
 	rows, err := r.db.Query(query, projectID)
@@ -166,6 +192,7 @@ func (r *TestSuiteRepository) ListByProject(projectID int64) ([]*models.TestSuit
 			&suite.ProjectID,
 			&suite.Name,
 			&suite.Description,
+			&suite.ArchivedAt,
 			&suite.CreatedAt,
 			&suite.UpdatedAt,
 		)
@@ -182,39 +209,19 @@ func (r *TestSuiteRepository) ListByProject(projectID int64) ([]*models.TestSuit
 	return suites, nil
 }
 
-// List retrieves all test suites
-func (r *TestSuiteRepository) List() ([]*models.TestSuite, error) {
-	query := `
-		SELECT id, project_id, name, description, created_at, updated_at
-		FROM test_suites
-		ORDER BY name ASC
-	`
-	rows, err := r.db.Query(query)
+// SetArchived archives or unarchives a test suite
+func (r *TestSuiteRepository) SetArchived(id int64, archived bool) error {
+	// Check if suite exists
+	_, err := r.GetByID(id)
 	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-
-	var suites []*models.TestSuite
-	for rows.Next() {
-		suite := &models.TestSuite{}
-		err := rows.Scan(
-			&suite.ID,
-			&suite.ProjectID,
-			&suite.Name,
-			&suite.Description,
-			&suite.CreatedAt,
-			&suite.UpdatedAt,
-		)
-		if err != nil {
-			return nil, err
-		}
-		suites = append(suites, suite)
+		return err
 	}
 
-	if err = rows.Err(); err != nil {
-		return nil, err
+	var archivedAt interface{}
+	if archived {
+		archivedAt = time.Now()
 	}
 
-	return suites, nil
+	_, err = r.db.Exec("UPDATE test_suites SET archived_at = ? WHERE id = ?", archivedAt, id)
+	return err
 }
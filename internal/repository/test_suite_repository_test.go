@@ -0,0 +1,46 @@
+package repository
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/mihaamiharu/test-case-management-be/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTestSuiteRepository_Create(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewTestSuiteRepository(db)
+	nameCheck := regexp.QuoteMeta("SELECT COUNT(CASE WHEN deleted_at IS NULL THEN 1 END), COUNT(deleted_at) FROM test_suites WHERE name = ? AND project_id = ? AND id != ?")
+
+	// Test case: the name of a suite in the project is taken
+	mock.ExpectQuery(nameCheck).
+		WithArgs("Checkout", int64(1), int64(0)).
+		WillReturnRows(sqlmock.NewRows([]string{"live", "trashed"}).AddRow(1, 0))
+	assert.Equal(t, ErrTestSuiteExists, repo.Create(&models.TestSuite{ProjectID: 1, Name: "Checkout"}))
+
+	// Test case: the name of a suite in the trash is reported as such
+	mock.ExpectQuery(nameCheck).
+		WithArgs("Checkout", int64(1), int64(0)).
+		WillReturnRows(sqlmock.NewRows([]string{"live", "trashed"}).AddRow(0, 1))
+	assert.Equal(t, ErrTestSuiteInTrash, repo.Create(&models.TestSuite{ProjectID: 1, Name: "Checkout"}))
+
+	// Test case: a free name creates the suite
+	mock.ExpectQuery(nameCheck).
+		WithArgs("Payments", int64(1), int64(0)).
+		WillReturnRows(sqlmock.NewRows([]string{"live", "trashed"}).AddRow(0, 0))
+	mock.ExpectExec("INSERT INTO test_suites").
+		WithArgs(int64(1), "Payments", "", sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(4, 1))
+	suite := &models.TestSuite{ProjectID: 1, Name: "Payments"}
+	assert.NoError(t, repo.Create(suite))
+	assert.Equal(t, int64(4), suite.ID)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,321 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/mihaamiharu/test-case-management-be/internal/models"
+)
+
+var (
+	ErrNotInTrash    = errors.New("item not found in the trash")
+	ErrParentInTrash = errors.New("the project or test suite of this item is in the trash and must be restored first")
+)
+
+// TrashRepositoryInterface defines the interface for trash repository operations
+type TrashRepositoryInterface interface {
+	GetItem(entityType string, id int64) (*models.TrashItem, error)
+	ListByProject(projectID int64) ([]*models.TrashItem, error)
+	ListProjects(organizationID, ownerID int64) ([]*models.TrashItem, error)
+	RestoreProject(id int64) error
+	RestoreTestSuite(id int64) error
+	RestoreTestCase(id int64) error
+	PurgeDeletedBefore(cutoff time.Time) (*models.PurgeResult, error)
+}
+
+// TrashRepository handles database operations for trashed projects, test suites and test cases
+type TrashRepository struct {
+	db *sql.DB
+}
+
+// NewTrashRepository creates a new trash repository
+func NewTrashRepository(db *sql.DB) *TrashRepository {
+	return &TrashRepository{db: db}
+}
+
+// trashQueries select a trashed item of each entity type as a TrashItem
+var trashQueries = map[string]string{
+	models.TrashProject: `
+		SELECT id, id, 0, owner_id, name, deleted_at
+		FROM projects
+		WHERE id = ? AND deleted_at IS NOT NULL`,
+	models.TrashTestSuite: `
+		SELECT id, project_id, 0, 0, name, deleted_at
+		FROM test_suites
+		WHERE id = ? AND deleted_at IS NOT NULL`,
+	models.TrashTestCase: `
+		SELECT id, project_id, COALESCE(suite_id, 0), 0, title, deleted_at
+		FROM test_cases
+		WHERE id = ? AND deleted_at IS NOT NULL`,
+}
+
+// GetItem retrieves a trashed project, test suite or test case
+func (r *TrashRepository) GetItem(entityType string, id int64) (*models.TrashItem, error) {
+	query, ok := trashQueries[entityType]
+	if !ok {
+		return nil, fmt.Errorf("unknown trash entity type: %s", entityType)
+	}
+
+	item := &models.TrashItem{EntityType: entityType}
+	err := r.db.QueryRow(query, id).Scan(&item.ID, &item.ProjectID, &item.SuiteID, &item.OwnerID, &item.Name, &item.DeletedAt)
+	if err == sql.ErrNoRows {
+		return nil, ErrNotInTrash
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get trash item: %v", err)
+	}
+
+	return item, nil
+}
+
+// ListByProject retrieves the test suites and test cases of a project that were deleted on
+// their own, newest first. Items deleted together with their suite are restored with it and
+// are not listed.
+func (r *TrashRepository) ListByProject(projectID int64) ([]*models.TrashItem, error) {
+	query := `
+		SELECT 'test_suite', id, project_id, 0, 0, name, deleted_at
+		FROM test_suites
+		WHERE project_id = ? AND deleted_at IS NOT NULL AND deleted_with_parent = FALSE
+		UNION ALL
+		SELECT 'test_case', id, project_id, COALESCE(suite_id, 0), 0, title, deleted_at
+		FROM test_cases
+		WHERE project_id = ? AND deleted_at IS NOT NULL AND deleted_with_parent = FALSE
+		ORDER BY deleted_at DESC`
+
+	return r.listItems(query, projectID, projectID)
+}
+
+// ListProjects retrieves the trashed projects of an organization for an owner, newest first.
+// An ownerID of 0 lists the trashed projects of every owner.
+func (r *TrashRepository) ListProjects(organizationID, ownerID int64) ([]*models.TrashItem, error) {
+	query := `
+		SELECT 'project', id, id, 0, owner_id, name, deleted_at
+		FROM projects
+		WHERE organization_id = ? AND deleted_at IS NOT NULL AND (? = 0 OR owner_id = ?)
+		ORDER BY deleted_at DESC`
+
+	return r.listItems(query, organizationID, ownerID, ownerID)
+}
+
+// listItems runs a query that returns trash items
+func (r *TrashRepository) listItems(query string, args ...interface{}) ([]*models.TrashItem, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list trash: %v", err)
+	}
+	defer rows.Close()
+
+	var items []*models.TrashItem
+	for rows.Next() {
+		item := &models.TrashItem{}
+		if err := rows.Scan(&item.EntityType, &item.ID, &item.ProjectID, &item.SuiteID, &item.OwnerID, &item.Name, &item.DeletedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan trash item: %v", err)
+		}
+		items = append(items, item)
+	}
+
+	return items, rows.Err()
+}
+
+// RestoreProject takes a project out of the trash together with the test suites and test
+// cases that were deleted with it
+func (r *TrashRepository) RestoreProject(id int64) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec("UPDATE projects SET deleted_at = NULL WHERE id = ? AND deleted_at IS NOT NULL", id)
+	if err != nil {
+		return fmt.Errorf("failed to restore project: %v", err)
+	}
+	if err := requireRestored(result); err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`
+		UPDATE test_suites SET deleted_at = NULL, deleted_with_parent = FALSE
+		WHERE project_id = ? AND deleted_with_parent = TRUE`, id)
+	if err != nil {
+		return fmt.Errorf("failed to restore test suites: %v", err)
+	}
+
+	// Test cases of a suite that is still in the trash stay there with their suite
+	_, err = tx.Exec(`
+		UPDATE test_cases SET deleted_at = NULL, deleted_with_parent = FALSE
+		WHERE project_id = ? AND deleted_with_parent = TRUE
+			AND (suite_id IS NULL OR suite_id IN (SELECT id FROM test_suites WHERE project_id = ? AND deleted_at IS NULL))`,
+		id, id)
+	if err != nil {
+		return fmt.Errorf("failed to restore test cases: %v", err)
+	}
+
+	return tx.Commit()
+}
+
+// RestoreTestSuite takes a test suite out of the trash together with the test cases that
+// were deleted with it
+func (r *TrashRepository) RestoreTestSuite(id int64) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	var (
+		projectID  int64
+		withParent bool
+	)
+	err = tx.QueryRow("SELECT project_id, deleted_with_parent FROM test_suites WHERE id = ? AND deleted_at IS NOT NULL", id).
+		Scan(&projectID, &withParent)
+	if err == sql.ErrNoRows {
+		return ErrNotInTrash
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get test suite: %v", err)
+	}
+	if withParent {
+		return ErrParentInTrash
+	}
+
+	// A suite deleted on its own can outlive a later deletion of its project
+	var count int
+	err = tx.QueryRow("SELECT COUNT(*) FROM projects WHERE id = ? AND deleted_at IS NOT NULL", projectID).Scan(&count)
+	if err != nil {
+		return fmt.Errorf("failed to check project: %v", err)
+	}
+	if count > 0 {
+		return ErrParentInTrash
+	}
+
+	if _, err := tx.Exec("UPDATE test_suites SET deleted_at = NULL WHERE id = ?", id); err != nil {
+		return fmt.Errorf("failed to restore test suite: %v", err)
+	}
+
+	_, err = tx.Exec(`
+		UPDATE test_cases SET deleted_at = NULL, deleted_with_parent = FALSE
+		WHERE suite_id = ? AND deleted_with_parent = TRUE`, id)
+	if err != nil {
+		return fmt.Errorf("failed to restore test cases: %v", err)
+	}
+
+	return tx.Commit()
+}
+
+// RestoreTestCase takes a test case out of the trash. The project and suite of the test case
+// must not be in the trash.
+func (r *TrashRepository) RestoreTestCase(id int64) error {
+	var (
+		suiteID    sql.NullInt64
+		withParent bool
+	)
+	err := r.db.QueryRow("SELECT suite_id, deleted_with_parent FROM test_cases WHERE id = ? AND deleted_at IS NOT NULL", id).
+		Scan(&suiteID, &withParent)
+	if err == sql.ErrNoRows {
+		return ErrNotInTrash
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get test case: %v", err)
+	}
+	if withParent {
+		return ErrParentInTrash
+	}
+
+	// A test case deleted on its own can outlive a later deletion of its suite
+	if suiteID.Valid {
+		var count int
+		err := r.db.QueryRow("SELECT COUNT(*) FROM test_suites WHERE id = ? AND deleted_at IS NOT NULL", suiteID.Int64).Scan(&count)
+		if err != nil {
+			return fmt.Errorf("failed to check test suite: %v", err)
+		}
+		if count > 0 {
+			return ErrParentInTrash
+		}
+	}
+
+	result, err := r.db.Exec(`
+		UPDATE test_cases SET deleted_at = NULL
+		WHERE id = ? AND deleted_at IS NOT NULL
+			AND project_id IN (SELECT id FROM projects WHERE deleted_at IS NULL)`, id)
+	if err != nil {
+		return fmt.Errorf("failed to restore test case: %v", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %v", err)
+	}
+	if rowsAffected == 0 {
+		return ErrParentInTrash
+	}
+
+	return nil
+}
+
+// PurgeDeletedBefore permanently removes the projects, test suites and test cases that were
+// moved to the trash before the cutoff. Their children are removed with them.
+func (r *TrashRepository) PurgeDeletedBefore(cutoff time.Time) (*models.PurgeResult, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	purged := &models.PurgeResult{}
+
+	// Suites, test cases and everything below them are removed by the foreign keys
+	result, err := tx.Exec("DELETE FROM projects WHERE deleted_at < ?", cutoff)
+	if err != nil {
+		return nil, fmt.Errorf("failed to purge projects: %v", err)
+	}
+	if purged.Projects, err = result.RowsAffected(); err != nil {
+		return nil, fmt.Errorf("failed to get rows affected: %v", err)
+	}
+
+	// Removing a suite only detaches its test cases, so the ones trashed with it go first
+	_, err = tx.Exec(`
+		DELETE FROM test_cases
+		WHERE deleted_with_parent = TRUE AND suite_id IN (
+			SELECT id FROM test_suites WHERE deleted_at < ? AND deleted_with_parent = FALSE
+		)`, cutoff)
+	if err != nil {
+		return nil, fmt.Errorf("failed to purge test cases: %v", err)
+	}
+
+	result, err = tx.Exec("DELETE FROM test_suites WHERE deleted_at < ? AND deleted_with_parent = FALSE", cutoff)
+	if err != nil {
+		return nil, fmt.Errorf("failed to purge test suites: %v", err)
+	}
+	if purged.TestSuites, err = result.RowsAffected(); err != nil {
+		return nil, fmt.Errorf("failed to get rows affected: %v", err)
+	}
+
+	result, err = tx.Exec("DELETE FROM test_cases WHERE deleted_at < ? AND deleted_with_parent = FALSE", cutoff)
+	if err != nil {
+		return nil, fmt.Errorf("failed to purge test cases: %v", err)
+	}
+	if purged.TestCases, err = result.RowsAffected(); err != nil {
+		return nil, fmt.Errorf("failed to get rows affected: %v", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit purge: %v", err)
+	}
+
+	return purged, nil
+}
+
+// requireRestored reports ErrNotInTrash when a restore did not change any row
+func requireRestored(result sql.Result) error {
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %v", err)
+	}
+	if rowsAffected == 0 {
+		return ErrNotInTrash
+	}
+	return nil
+}
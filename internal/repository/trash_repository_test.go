@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTrashRepository_RestoreTestCase(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewTrashRepository(db)
+
+	// Test case: a test case deleted on its own is restored
+	t.Run("Success", func(t *testing.T) {
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT suite_id, deleted_with_parent FROM test_cases WHERE id = ? AND deleted_at IS NOT NULL")).
+			WithArgs(1).
+			WillReturnRows(sqlmock.NewRows([]string{"suite_id", "deleted_with_parent"}).AddRow(3, false))
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT COUNT(*) FROM test_suites WHERE id = ? AND deleted_at IS NOT NULL")).
+			WithArgs(int64(3)).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+		mock.ExpectExec("UPDATE test_cases SET deleted_at = NULL").
+			WithArgs(1).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		assert.NoError(t, repo.RestoreTestCase(1))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	// Test case: a test case trashed with its suite is restored with the suite
+	t.Run("DeletedWithParent", func(t *testing.T) {
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT suite_id, deleted_with_parent FROM test_cases WHERE id = ? AND deleted_at IS NOT NULL")).
+			WithArgs(2).
+			WillReturnRows(sqlmock.NewRows([]string{"suite_id", "deleted_with_parent"}).AddRow(3, true))
+
+		assert.Equal(t, ErrParentInTrash, repo.RestoreTestCase(2))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	// Test case: the test case is not in the trash
+	t.Run("NotInTrash", func(t *testing.T) {
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT suite_id, deleted_with_parent FROM test_cases WHERE id = ? AND deleted_at IS NOT NULL")).
+			WithArgs(4).
+			WillReturnRows(sqlmock.NewRows([]string{"suite_id", "deleted_with_parent"}))
+
+		assert.Equal(t, ErrNotInTrash, repo.RestoreTestCase(4))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestTrashRepository_PurgeDeletedBefore(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewTrashRepository(db)
+	cutoff := time.Now().Add(-30 * 24 * time.Hour)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM projects WHERE deleted_at < ?")).
+		WithArgs(cutoff).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM test_cases WHERE deleted_with_parent = TRUE AND suite_id IN")).
+		WithArgs(cutoff).
+		WillReturnResult(sqlmock.NewResult(0, 4))
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM test_suites WHERE deleted_at < ? AND deleted_with_parent = FALSE")).
+		WithArgs(cutoff).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM test_cases WHERE deleted_at < ? AND deleted_with_parent = FALSE")).
+		WithArgs(cutoff).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+	mock.ExpectCommit()
+
+	purged, err := repo.PurgeDeletedBefore(cutoff)
+
+	assert.NoError(t, err)
+	// Test case: test cases purged with their suite are not counted on their own
+	assert.Equal(t, int64(1), purged.Projects)
+	assert.Equal(t, int64(2), purged.TestSuites)
+	assert.Equal(t, int64(3), purged.TestCases)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,196 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/mihaamiharu/test-case-management-be/internal/models"
+)
+
+var (
+	ErrTwoFactorNotFound    = errors.New("two-factor authentication is not set up")
+	ErrTOTPStepUsed         = errors.New("TOTP code has already been used")
+	ErrRecoveryCodeNotFound = errors.New("recovery code not found")
+)
+
+// TwoFactorRepositoryInterface defines the interface for two-factor authentication repository operations
+type TwoFactorRepositoryInterface interface {
+	Get(userID int64) (*models.TwoFactor, error)
+	SaveSecret(userID int64, secretEncrypted string) error
+	Enable(userID int64, recoveryCodeHashes []string) error
+	Delete(userID int64) error
+	UseStep(userID, step int64) error
+	ReplaceRecoveryCodes(userID int64, codeHashes []string) error
+	UseRecoveryCode(userID int64, codeHash string) error
+	CountRecoveryCodes(userID int64) (int, error)
+}
+
+// TwoFactorRepository handles database operations for TOTP enrollments and recovery codes
+type TwoFactorRepository struct {
+	db *sql.DB
+}
+
+// NewTwoFactorRepository creates a new two-factor authentication repository
+func NewTwoFactorRepository(db *sql.DB) *TwoFactorRepository {
+	return &TwoFactorRepository{db: db}
+}
+
+// Get retrieves the TOTP enrollment of a user, confirmed or not
+func (r *TwoFactorRepository) Get(userID int64) (*models.TwoFactor, error) {
+	query := `
+		SELECT user_id, secret_encrypted, enabled_at, last_used_step, created_at
+		FROM user_two_factor
+		WHERE user_id = ?`
+
+	twoFactor := &models.TwoFactor{}
+	err := r.db.QueryRow(query, userID).Scan(
+		&twoFactor.UserID,
+		&twoFactor.SecretEncrypted,
+		&twoFactor.EnabledAt,
+		&twoFactor.LastUsedStep,
+		&twoFactor.CreatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, ErrTwoFactorNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get two-factor authentication: %v", err)
+	}
+	return twoFactor, nil
+}
+
+// SaveSecret starts a new, unconfirmed enrollment of a user, replacing an unconfirmed one
+func (r *TwoFactorRepository) SaveSecret(userID int64, secretEncrypted string) error {
+	_, err := r.db.Exec(`
+		INSERT INTO user_two_factor (user_id, secret_encrypted, created_at) VALUES (?, ?, ?)
+		ON DUPLICATE KEY UPDATE secret_encrypted = VALUES(secret_encrypted), enabled_at = NULL,
+			last_used_step = 0, created_at = VALUES(created_at)`,
+		userID, secretEncrypted, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to save TOTP secret: %v", err)
+	}
+	return nil
+}
+
+// Enable confirms the enrollment of a user and stores its first recovery codes
+func (r *TwoFactorRepository) Enable(userID int64, recoveryCodeHashes []string) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec("UPDATE user_two_factor SET enabled_at = ? WHERE user_id = ?", time.Now(), userID)
+	if err != nil {
+		return fmt.Errorf("failed to enable two-factor authentication: %v", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %v", err)
+	}
+	if rows == 0 {
+		return ErrTwoFactorNotFound
+	}
+
+	if err := replaceRecoveryCodes(tx, userID, recoveryCodeHashes); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Delete turns off two-factor authentication for a user and removes its recovery codes
+func (r *TwoFactorRepository) Delete(userID int64) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("DELETE FROM recovery_codes WHERE user_id = ?", userID); err != nil {
+		return fmt.Errorf("failed to delete recovery codes: %v", err)
+	}
+	if _, err := tx.Exec("DELETE FROM user_two_factor WHERE user_id = ?", userID); err != nil {
+		return fmt.Errorf("failed to delete two-factor authentication: %v", err)
+	}
+
+	return tx.Commit()
+}
+
+// UseStep records that the TOTP code of a time step was accepted. Codes of that step and
+// earlier ones are rejected afterwards, so that an observed code cannot be replayed.
+func (r *TwoFactorRepository) UseStep(userID, step int64) error {
+	result, err := r.db.Exec("UPDATE user_two_factor SET last_used_step = ? WHERE user_id = ? AND last_used_step < ?",
+		step, userID, step)
+	if err != nil {
+		return fmt.Errorf("failed to use TOTP code: %v", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %v", err)
+	}
+	if rows == 0 {
+		return ErrTOTPStepUsed
+	}
+	return nil
+}
+
+// ReplaceRecoveryCodes replaces all recovery codes of a user
+func (r *TwoFactorRepository) ReplaceRecoveryCodes(userID int64, codeHashes []string) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	if err := replaceRecoveryCodes(tx, userID, codeHashes); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// UseRecoveryCode marks an unused recovery code of a user as used
+func (r *TwoFactorRepository) UseRecoveryCode(userID int64, codeHash string) error {
+	result, err := r.db.Exec("UPDATE recovery_codes SET used_at = ? WHERE user_id = ? AND code_hash = ? AND used_at IS NULL",
+		time.Now(), userID, codeHash)
+	if err != nil {
+		return fmt.Errorf("failed to use recovery code: %v", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %v", err)
+	}
+	if rows == 0 {
+		return ErrRecoveryCodeNotFound
+	}
+	return nil
+}
+
+// CountRecoveryCodes counts the unused recovery codes of a user
+func (r *TwoFactorRepository) CountRecoveryCodes(userID int64) (int, error) {
+	var count int
+	err := r.db.QueryRow("SELECT COUNT(*) FROM recovery_codes WHERE user_id = ? AND used_at IS NULL", userID).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count recovery codes: %v", err)
+	}
+	return count, nil
+}
+
+// replaceRecoveryCodes replaces all recovery codes of a user within a transaction
+func replaceRecoveryCodes(tx *sql.Tx, userID int64, codeHashes []string) error {
+	if _, err := tx.Exec("DELETE FROM recovery_codes WHERE user_id = ?", userID); err != nil {
+		return fmt.Errorf("failed to delete recovery codes: %v", err)
+	}
+
+	now := time.Now()
+	for _, codeHash := range codeHashes {
+		_, err := tx.Exec("INSERT INTO recovery_codes (user_id, code_hash, created_at) VALUES (?, ?, ?)", userID, codeHash, now)
+		if err != nil {
+			return fmt.Errorf("failed to create recovery code: %v", err)
+		}
+	}
+	return nil
+}
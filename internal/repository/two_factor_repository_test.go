@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTwoFactorRepository_Enable(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewTwoFactorRepository(db)
+
+	// Test case: enabling stores the recovery codes in the same transaction
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE user_two_factor SET enabled_at = ? WHERE user_id = ?")).
+		WithArgs(sqlmock.AnyArg(), 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM recovery_codes WHERE user_id = ?")).
+		WithArgs(1).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO recovery_codes (user_id, code_hash, created_at) VALUES (?, ?, ?)")).
+		WithArgs(1, "hash-1", sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO recovery_codes (user_id, code_hash, created_at) VALUES (?, ?, ?)")).
+		WithArgs(1, "hash-2", sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(2, 1))
+	mock.ExpectCommit()
+
+	err = repo.Enable(1, []string{"hash-1", "hash-2"})
+
+	assert.NoError(t, err)
+
+	// Test case: users without a set up secret cannot enable two-factor authentication
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE user_two_factor SET enabled_at = ? WHERE user_id = ?")).
+		WithArgs(sqlmock.AnyArg(), 2).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	err = repo.Enable(2, []string{"hash-1"})
+
+	assert.Equal(t, ErrTwoFactorNotFound, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestTwoFactorRepository_UseStep(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewTwoFactorRepository(db)
+	query := regexp.QuoteMeta("UPDATE user_two_factor SET last_used_step = ? WHERE user_id = ? AND last_used_step < ?")
+
+	// Test case: a new time step is recorded
+	mock.ExpectExec(query).WithArgs(100, 1, 100).WillReturnResult(sqlmock.NewResult(0, 1))
+	assert.NoError(t, repo.UseStep(1, 100))
+
+	// Test case: a time step that was already used is rejected
+	mock.ExpectExec(query).WithArgs(100, 1, 100).WillReturnResult(sqlmock.NewResult(0, 0))
+	assert.Equal(t, ErrTOTPStepUsed, repo.UseStep(1, 100))
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -3,6 +3,8 @@ package repository
 import (
 	"database/sql"
 	"errors"
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/mihaamiharu/test-case-management-be/internal/models"
@@ -17,7 +19,17 @@ var (
 type UserRepositoryInterface interface {
 	Create(user *models.User) error
 	GetByEmail(email string) (*models.User, error)
+	GetByOIDCIdentity(issuer, subject string) (*models.User, error)
 	GetByID(id int64) (*models.User, error)
+	GetByUsernames(usernames []string) ([]*models.User, error)
+	List(filter *models.UserFilter) ([]*models.User, error)
+	UpdateProfile(user *models.User) error
+	UpdateRole(id int64, role models.Role) error
+	UpdatePassword(id int64, passwordHash string) error
+	SetDeactivated(id int64, deactivated bool) error
+	SetPasswordResetRequired(id int64, required bool) error
+	SetEmailVerified(id int64) error
+	SetOIDCIdentity(id int64, issuer, subject string) error
 }
 
 // UserRepository handles database operations for users
@@ -44,11 +56,11 @@ func (r *UserRepository) Create(user *models.User) error {
 
 	// Insert new user
 	query := `
-		INSERT INTO users (username, email, password_hash, role, created_at, updated_at)
-		VALUES (?, ?, ?, ?, ?, ?)
+		INSERT INTO users (username, email, password_hash, role, email_verified_at, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
 	`
 	now := time.Now()
-	result, err := r.db.Exec(query, user.Username, user.Email, user.PasswordHash, user.Role, now, now)
+	result, err := r.db.Exec(query, user.Username, user.Email, user.PasswordHash, user.Role, user.EmailVerifiedAt, now, now)
 	if err != nil {
 		return err
 	}
@@ -64,23 +76,34 @@ func (r *UserRepository) Create(user *models.User) error {
 	return nil
 }
 
+// userColumns are the columns scanned by scanUser
+const userColumns = "id, username, email, password_hash, role, email_verified_at, deactivated_at, password_reset_required, oidc_issuer, oidc_subject, created_at, updated_at"
+
 // GetByEmail retrieves a user by email
 func (r *UserRepository) GetByEmail(email string) (*models.User, error) {
 	query := `
-		SELECT id, username, email, password_hash, role, created_at, updated_at
+		SELECT ` + userColumns + `
 		FROM users
 		WHERE email = ?
 	`
-	user := &models.User{}
-	err := r.db.QueryRow(query, email).Scan(
-		&user.ID,
-		&user.Username,
-		&user.Email,
-		&user.PasswordHash,
-		&user.Role,
-		&user.CreatedAt,
-		&user.UpdatedAt,
-	)
+	user, err := scanUser(r.db.QueryRow(query, email))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+	return user, nil
+}
+
+// GetByOIDCIdentity retrieves the user that logs in with a single sign-on identity
+func (r *UserRepository) GetByOIDCIdentity(issuer, subject string) (*models.User, error) {
+	query := `
+		SELECT ` + userColumns + `
+		FROM users
+		WHERE oidc_issuer = ? AND oidc_subject = ?
+	`
+	user, err := scanUser(r.db.QueryRow(query, issuer, subject))
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, ErrUserNotFound
@@ -93,24 +116,185 @@ func (r *UserRepository) GetByEmail(email string) (*models.User, error) {
 // GetByID retrieves a user by ID
 func (r *UserRepository) GetByID(id int64) (*models.User, error) {
 	query := `
-		SELECT id, username, email, password_hash, role, created_at, updated_at
+		SELECT ` + userColumns + `
 		FROM users
 		WHERE id = ?
 	`
+	user, err := scanUser(r.db.QueryRow(query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+	return user, nil
+}
+
+// GetByUsernames retrieves the users with any of the given usernames
+func (r *UserRepository) GetByUsernames(usernames []string) ([]*models.User, error) {
+	if len(usernames) == 0 {
+		return nil, nil
+	}
+
+	placeholders := make([]string, len(usernames))
+	args := make([]interface{}, len(usernames))
+	for i, username := range usernames {
+		placeholders[i] = "?"
+		args[i] = username
+	}
+
+	query := `
+		SELECT ` + userColumns + `
+		FROM users
+		WHERE username IN (` + strings.Join(placeholders, ", ") + `)
+	`
+	return r.listUsers(query, args...)
+}
+
+// List retrieves the users matching a filter, ordered by username
+func (r *UserRepository) List(filter *models.UserFilter) ([]*models.User, error) {
+	var (
+		conditions []string
+		args       []interface{}
+	)
+	if filter.Search != "" {
+		conditions = append(conditions, "(username LIKE ? OR email LIKE ?)")
+		pattern := "%" + filter.Search + "%"
+		args = append(args, pattern, pattern)
+	}
+	if filter.Role != "" {
+		conditions = append(conditions, "role = ?")
+		args = append(args, filter.Role)
+	}
+	switch filter.Status {
+	case models.UserStatusActive:
+		conditions = append(conditions, "deactivated_at IS NULL")
+	case models.UserStatusDeactivated:
+		conditions = append(conditions, "deactivated_at IS NOT NULL")
+	}
+
+	query := `
+		SELECT ` + userColumns + `
+		FROM users`
+	if len(conditions) > 0 {
+		query += `
+		WHERE ` + strings.Join(conditions, " AND ")
+	}
+	query += `
+		ORDER BY username, id
+		LIMIT ? OFFSET ?`
+	args = append(args, filter.Limit, filter.Offset)
+
+	users, err := r.listUsers(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list users: %v", err)
+	}
+	return users, nil
+}
+
+// UpdateProfile updates the username, email and email verification of a user
+func (r *UserRepository) UpdateProfile(user *models.User) error {
+	// Check if another user has the email
+	var count int
+	err := r.db.QueryRow("SELECT COUNT(*) FROM users WHERE email = ? AND id != ?", user.Email, user.ID).Scan(&count)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return ErrUserExists
+	}
+
+	now := time.Now()
+	if err := r.update(user.ID, "username = ?, email = ?, email_verified_at = ?", user.Username, user.Email, user.EmailVerifiedAt); err != nil {
+		return err
+	}
+	user.UpdatedAt = now
+	return nil
+}
+
+// UpdateRole changes the role of a user
+func (r *UserRepository) UpdateRole(id int64, role models.Role) error {
+	return r.update(id, "role = ?", role)
+}
+
+// UpdatePassword sets a new password hash and clears a required password reset
+func (r *UserRepository) UpdatePassword(id int64, passwordHash string) error {
+	return r.update(id, "password_hash = ?, password_reset_required = FALSE", passwordHash)
+}
+
+// SetDeactivated deactivates or reactivates a user
+func (r *UserRepository) SetDeactivated(id int64, deactivated bool) error {
+	var deactivatedAt interface{}
+	if deactivated {
+		deactivatedAt = time.Now()
+	}
+	return r.update(id, "deactivated_at = ?", deactivatedAt)
+}
+
+// SetPasswordResetRequired sets whether a user must change their password before doing anything else
+func (r *UserRepository) SetPasswordResetRequired(id int64, required bool) error {
+	return r.update(id, "password_reset_required = ?", required)
+}
+
+// SetEmailVerified marks the email address of a user as verified
+func (r *UserRepository) SetEmailVerified(id int64) error {
+	return r.update(id, "email_verified_at = ?", time.Now())
+}
+
+// SetOIDCIdentity links a user to the single sign-on identity it logs in with
+func (r *UserRepository) SetOIDCIdentity(id int64, issuer, subject string) error {
+	return r.update(id, "oidc_issuer = ?, oidc_subject = ?", issuer, subject)
+}
+
+// update sets columns of an existing user
+func (r *UserRepository) update(id int64, assignments string, args ...interface{}) error {
+	// Check if user exists
+	if _, err := r.GetByID(id); err != nil {
+		return err
+	}
+
+	args = append(args, time.Now(), id)
+	_, err := r.db.Exec("UPDATE users SET "+assignments+", updated_at = ? WHERE id = ?", args...)
+	return err
+}
+
+// listUsers runs a query that returns users
+func (r *UserRepository) listUsers(query string, args ...interface{}) ([]*models.User, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var users []*models.User
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, user)
+	}
+	return users, rows.Err()
+}
+
+// scanUser scans a user row selected with userColumns
+func scanUser(row rowScanner) (*models.User, error) {
 	user := &models.User{}
-	err := r.db.QueryRow(query, id).Scan(
+	err := row.Scan(
 		&user.ID,
 		&user.Username,
 		&user.Email,
 		&user.PasswordHash,
 		&user.Role,
+		&user.EmailVerifiedAt,
+		&user.DeactivatedAt,
+		&user.PasswordResetRequired,
+		&user.OIDCIssuer,
+		&user.OIDCSubject,
 		&user.CreatedAt,
 		&user.UpdatedAt,
 	)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			return nil, ErrUserNotFound
-		}
 		return nil, err
 	}
 	return user, nil
@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/mihaamiharu/test-case-management-be/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+var userColumnNames = []string{"id", "username", "email", "password_hash", "role", "email_verified_at", "deactivated_at", "password_reset_required", "oidc_issuer", "oidc_subject", "created_at", "updated_at"}
+
+func TestUserRepository_List(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewUserRepository(db)
+	now := time.Now()
+
+	// Test case: search, role and status become conditions and a page is requested
+	mock.ExpectQuery(regexp.QuoteMeta("WHERE (username LIKE ? OR email LIKE ?) AND role = ? AND deactivated_at IS NOT NULL ORDER BY username, id LIMIT ? OFFSET ?")).
+		WithArgs("%dana%", "%dana%", models.RoleTester, 10, 20).
+		WillReturnRows(sqlmock.NewRows(userColumnNames).
+			AddRow(4, "dana", "dana@example.com", "hash", "tester", now, now, true, nil, nil, now, now))
+
+	users, err := repo.List(&models.UserFilter{Search: "dana", Role: models.RoleTester, Status: models.UserStatusDeactivated, Limit: 10, Offset: 20})
+
+	assert.NoError(t, err)
+	assert.Len(t, users, 1)
+	assert.True(t, users[0].IsDeactivated())
+	assert.True(t, users[0].PasswordResetRequired)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUserRepository_SetDeactivated(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewUserRepository(db)
+	now := time.Now()
+
+	// Test case: reactivating clears deactivated_at
+	mock.ExpectQuery("SELECT (.+) FROM users WHERE id = ?").
+		WithArgs(int64(4)).
+		WillReturnRows(sqlmock.NewRows(userColumnNames).
+			AddRow(4, "dana", "dana@example.com", "hash", "tester", now, now, false, nil, nil, now, now))
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE users SET deactivated_at = ?, updated_at = ? WHERE id = ?")).
+		WithArgs(nil, sqlmock.AnyArg(), int64(4)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	assert.NoError(t, repo.SetDeactivated(4, false))
+
+	// Test case: an unknown user
+	mock.ExpectQuery("SELECT (.+) FROM users WHERE id = ?").
+		WithArgs(int64(5)).
+		WillReturnRows(sqlmock.NewRows(userColumnNames))
+
+	assert.Equal(t, ErrUserNotFound, repo.SetDeactivated(5, true))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
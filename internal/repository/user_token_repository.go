@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/mihaamiharu/test-case-management-be/internal/models"
+)
+
+var (
+	ErrUserTokenNotFound = errors.New("user token not found")
+	ErrUserTokenUsed     = errors.New("user token has already been used")
+)
+
+// UserTokenRepositoryInterface defines the interface for user token repository operations
+type UserTokenRepositoryInterface interface {
+	Create(token *models.UserToken) error
+	GetByHash(tokenHash string) (*models.UserToken, error)
+	Use(id int64) error
+}
+
+// UserTokenRepository handles database operations for email verification and password reset tokens
+type UserTokenRepository struct {
+	db *sql.DB
+}
+
+// NewUserTokenRepository creates a new user token repository
+func NewUserTokenRepository(db *sql.DB) *UserTokenRepository {
+	return &UserTokenRepository{db: db}
+}
+
+// Create adds a new token and retires the unused tokens of the user with the same purpose,
+// so that only the latest email works
+func (r *UserTokenRepository) Create(token *models.UserToken) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	_, err = tx.Exec("UPDATE user_tokens SET used_at = ? WHERE user_id = ? AND purpose = ? AND used_at IS NULL",
+		now, token.UserID, token.Purpose)
+	if err != nil {
+		return fmt.Errorf("failed to retire user tokens: %v", err)
+	}
+
+	query := `
+		INSERT INTO user_tokens (user_id, purpose, token_hash, email, expires_at, created_at)
+		VALUES (?, ?, ?, ?, ?, ?)`
+	result, err := tx.Exec(query, token.UserID, token.Purpose, token.TokenHash, token.Email, token.ExpiresAt, now)
+	if err != nil {
+		return fmt.Errorf("failed to create user token: %v", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to get user token ID: %v", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %v", err)
+	}
+
+	token.ID = id
+	token.CreatedAt = now
+	return nil
+}
+
+// GetByHash retrieves a token by the hash of its value, including used and expired ones
+func (r *UserTokenRepository) GetByHash(tokenHash string) (*models.UserToken, error) {
+	query := `
+		SELECT id, user_id, purpose, token_hash, email, expires_at, used_at, created_at
+		FROM user_tokens
+		WHERE token_hash = ?`
+
+	token := &models.UserToken{}
+	err := r.db.QueryRow(query, tokenHash).Scan(
+		&token.ID,
+		&token.UserID,
+		&token.Purpose,
+		&token.TokenHash,
+		&token.Email,
+		&token.ExpiresAt,
+		&token.UsedAt,
+		&token.CreatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, ErrUserTokenNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user token: %v", err)
+	}
+
+	return token, nil
+}
+
+// Use marks a token as used. Only one of several concurrent uses succeeds.
+func (r *UserTokenRepository) Use(id int64) error {
+	result, err := r.db.Exec("UPDATE user_tokens SET used_at = ? WHERE id = ? AND used_at IS NULL", time.Now(), id)
+	if err != nil {
+		return fmt.Errorf("failed to use user token: %v", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %v", err)
+	}
+	if rows == 0 {
+		return ErrUserTokenUsed
+	}
+	return nil
+}
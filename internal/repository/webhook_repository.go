@@ -0,0 +1,336 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mihaamiharu/test-case-management-be/internal/models"
+)
+
+var (
+	ErrWebhookNotFound         = errors.New("webhook not found")
+	ErrWebhookDeliveryNotFound = errors.New("webhook delivery not found")
+)
+
+// WebhookRepositoryInterface defines the interface for webhook repository operations
+type WebhookRepositoryInterface interface {
+	Create(webhook *models.Webhook) error
+	GetByID(id int64) (*models.Webhook, error)
+	Update(webhook *models.Webhook) error
+	Delete(id int64) error
+	ListByProject(projectID int64) ([]*models.Webhook, error)
+	ListActiveByEvent(projectID int64, eventType string) ([]*models.Webhook, error)
+	CreateDelivery(delivery *models.WebhookDelivery) error
+	GetDelivery(id int64) (*models.WebhookDelivery, error)
+	ListDeliveries(webhookID int64, limit int) ([]*models.WebhookDelivery, error)
+	ListDueDeliveries(now time.Time, limit int) ([]*models.WebhookDelivery, error)
+	ClaimDelivery(delivery *models.WebhookDelivery, until time.Time) (bool, error)
+	UpdateDelivery(delivery *models.WebhookDelivery) error
+}
+
+// WebhookRepository handles database operations for webhooks and their deliveries
+type WebhookRepository struct {
+	db *sql.DB
+}
+
+// NewWebhookRepository creates a new webhook repository
+func NewWebhookRepository(db *sql.DB) *WebhookRepository {
+	return &WebhookRepository{db: db}
+}
+
+const (
+	// webhookColumns are the columns scanned by scanWebhook
+	webhookColumns = `id, project_id, name, url, secret, events, active, created_by, created_at, updated_at`
+
+	// webhookDeliveryColumns are the columns scanned by scanWebhookDelivery
+	webhookDeliveryColumns = `id, webhook_id, event_type, payload, status, attempts, next_attempt_at,
+		response_status, COALESCE(response_body, ''), COALESCE(error, ''), redelivery_of, created_at, delivered_at`
+)
+
+// Create adds a new webhook to the database
+func (r *WebhookRepository) Create(webhook *models.Webhook) error {
+	now := time.Now()
+	result, err := r.db.Exec(`
+		INSERT INTO webhooks (project_id, name, url, secret, events, active, created_by, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		webhook.ProjectID, webhook.Name, webhook.URL, webhook.Secret, strings.Join(webhook.Events, ","),
+		webhook.Active, webhook.CreatedBy, now, now)
+	if err != nil {
+		return fmt.Errorf("failed to create webhook: %v", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to get webhook ID: %v", err)
+	}
+
+	webhook.ID = id
+	webhook.CreatedAt = now
+	webhook.UpdatedAt = now
+	return nil
+}
+
+// GetByID retrieves a webhook by ID
+func (r *WebhookRepository) GetByID(id int64) (*models.Webhook, error) {
+	webhook, err := scanWebhook(r.db.QueryRow("SELECT "+webhookColumns+" FROM webhooks WHERE id = ?", id))
+	if err == sql.ErrNoRows {
+		return nil, ErrWebhookNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhook: %v", err)
+	}
+	return webhook, nil
+}
+
+// Update changes the name, URL, events and active state of a webhook
+func (r *WebhookRepository) Update(webhook *models.Webhook) error {
+	now := time.Now()
+	result, err := r.db.Exec(`
+		UPDATE webhooks SET name = ?, url = ?, events = ?, active = ?, updated_at = ?
+		WHERE id = ?`,
+		webhook.Name, webhook.URL, strings.Join(webhook.Events, ","), webhook.Active, now, webhook.ID)
+	if err != nil {
+		return fmt.Errorf("failed to update webhook: %v", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %v", err)
+	}
+	if rows == 0 {
+		return ErrWebhookNotFound
+	}
+
+	webhook.UpdatedAt = now
+	return nil
+}
+
+// Delete removes a webhook together with its deliveries
+func (r *WebhookRepository) Delete(id int64) error {
+	result, err := r.db.Exec("DELETE FROM webhooks WHERE id = ?", id)
+	if err != nil {
+		return fmt.Errorf("failed to delete webhook: %v", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %v", err)
+	}
+	if rows == 0 {
+		return ErrWebhookNotFound
+	}
+	return nil
+}
+
+// ListByProject retrieves the webhooks of a project ordered by name
+func (r *WebhookRepository) ListByProject(projectID int64) ([]*models.Webhook, error) {
+	return r.listWebhooks("SELECT "+webhookColumns+" FROM webhooks WHERE project_id = ? ORDER BY name, id", projectID)
+}
+
+// ListActiveByEvent retrieves the active webhooks of a project that subscribe to an event type
+func (r *WebhookRepository) ListActiveByEvent(projectID int64, eventType string) ([]*models.Webhook, error) {
+	return r.listWebhooks("SELECT "+webhookColumns+`
+		FROM webhooks
+		WHERE project_id = ? AND active = TRUE AND FIND_IN_SET(?, events) > 0
+		ORDER BY id`, projectID, eventType)
+}
+
+// listWebhooks runs a query of webhookColumns
+func (r *WebhookRepository) listWebhooks(query string, args ...interface{}) ([]*models.Webhook, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhooks: %v", err)
+	}
+	defer rows.Close()
+
+	var webhooks []*models.Webhook
+	for rows.Next() {
+		webhook, err := scanWebhook(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan webhook: %v", err)
+		}
+		webhooks = append(webhooks, webhook)
+	}
+	return webhooks, rows.Err()
+}
+
+// CreateDelivery adds a new delivery of an event to a webhook
+func (r *WebhookRepository) CreateDelivery(delivery *models.WebhookDelivery) error {
+	now := time.Now()
+	result, err := r.db.Exec(`
+		INSERT INTO webhook_deliveries (webhook_id, event_type, payload, status, attempts, next_attempt_at, redelivery_of, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		delivery.WebhookID, delivery.EventType, string(delivery.Payload), delivery.Status, delivery.Attempts,
+		delivery.NextAttemptAt, delivery.RedeliveryOf, now)
+	if err != nil {
+		return fmt.Errorf("failed to create webhook delivery: %v", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to get webhook delivery ID: %v", err)
+	}
+
+	delivery.ID = id
+	delivery.CreatedAt = now
+	return nil
+}
+
+// GetDelivery retrieves a webhook delivery by ID
+func (r *WebhookRepository) GetDelivery(id int64) (*models.WebhookDelivery, error) {
+	delivery, err := scanWebhookDelivery(r.db.QueryRow("SELECT "+webhookDeliveryColumns+" FROM webhook_deliveries WHERE id = ?", id))
+	if err == sql.ErrNoRows {
+		return nil, ErrWebhookDeliveryNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhook delivery: %v", err)
+	}
+	return delivery, nil
+}
+
+// ListDeliveries retrieves the latest deliveries of a webhook, newest first
+func (r *WebhookRepository) ListDeliveries(webhookID int64, limit int) ([]*models.WebhookDelivery, error) {
+	return r.listDeliveries("SELECT "+webhookDeliveryColumns+`
+		FROM webhook_deliveries
+		WHERE webhook_id = ?
+		ORDER BY id DESC
+		LIMIT ?`, webhookID, limit)
+}
+
+// ListDueDeliveries retrieves the pending deliveries whose next attempt is due, oldest first
+func (r *WebhookRepository) ListDueDeliveries(now time.Time, limit int) ([]*models.WebhookDelivery, error) {
+	return r.listDeliveries("SELECT "+webhookDeliveryColumns+`
+		FROM webhook_deliveries
+		WHERE status = ? AND next_attempt_at <= ?
+		ORDER BY next_attempt_at, id
+		LIMIT ?`, models.WebhookDeliveryPending, now, limit)
+}
+
+// listDeliveries runs a query of webhookDeliveryColumns
+func (r *WebhookRepository) listDeliveries(query string, args ...interface{}) ([]*models.WebhookDelivery, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhook deliveries: %v", err)
+	}
+	defer rows.Close()
+
+	var deliveries []*models.WebhookDelivery
+	for rows.Next() {
+		delivery, err := scanWebhookDelivery(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan webhook delivery: %v", err)
+		}
+		deliveries = append(deliveries, delivery)
+	}
+	return deliveries, rows.Err()
+}
+
+// ClaimDelivery postpones the next attempt of a due delivery until a time, so that no other
+// server attempts it meanwhile, and reports whether the delivery was still due
+func (r *WebhookRepository) ClaimDelivery(delivery *models.WebhookDelivery, until time.Time) (bool, error) {
+	result, err := r.db.Exec(`
+		UPDATE webhook_deliveries SET next_attempt_at = ?
+		WHERE id = ? AND status = ? AND next_attempt_at = ?`,
+		until, delivery.ID, models.WebhookDeliveryPending, delivery.NextAttemptAt)
+	if err != nil {
+		return false, fmt.Errorf("failed to claim webhook delivery: %v", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get affected rows: %v", err)
+	}
+	if rows == 0 {
+		return false, nil
+	}
+
+	delivery.NextAttemptAt = &until
+	return true, nil
+}
+
+// UpdateDelivery records the outcome of an attempt of a delivery
+func (r *WebhookRepository) UpdateDelivery(delivery *models.WebhookDelivery) error {
+	_, err := r.db.Exec(`
+		UPDATE webhook_deliveries
+		SET status = ?, attempts = ?, next_attempt_at = ?, response_status = ?, response_body = ?, error = ?, delivered_at = ?
+		WHERE id = ?`,
+		delivery.Status, delivery.Attempts, delivery.NextAttemptAt, delivery.ResponseStatus,
+		delivery.ResponseBody, delivery.Error, delivery.DeliveredAt, delivery.ID)
+	if err != nil {
+		return fmt.Errorf("failed to update webhook delivery: %v", err)
+	}
+	return nil
+}
+
+// scanWebhook scans a row of webhookColumns
+func scanWebhook(row rowScanner) (*models.Webhook, error) {
+	webhook := &models.Webhook{}
+	var events string
+	err := row.Scan(
+		&webhook.ID,
+		&webhook.ProjectID,
+		&webhook.Name,
+		&webhook.URL,
+		&webhook.Secret,
+		&events,
+		&webhook.Active,
+		&webhook.CreatedBy,
+		&webhook.CreatedAt,
+		&webhook.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	webhook.Events = strings.Split(events, ",")
+	return webhook, nil
+}
+
+// scanWebhookDelivery scans a row of webhookDeliveryColumns
+func scanWebhookDelivery(row rowScanner) (*models.WebhookDelivery, error) {
+	delivery := &models.WebhookDelivery{}
+	var (
+		payload        string
+		nextAttemptAt  sql.NullTime
+		responseStatus sql.NullInt64
+		redeliveryOf   sql.NullInt64
+		deliveredAt    sql.NullTime
+	)
+	err := row.Scan(
+		&delivery.ID,
+		&delivery.WebhookID,
+		&delivery.EventType,
+		&payload,
+		&delivery.Status,
+		&delivery.Attempts,
+		&nextAttemptAt,
+		&responseStatus,
+		&delivery.ResponseBody,
+		&delivery.Error,
+		&redeliveryOf,
+		&delivery.CreatedAt,
+		&deliveredAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	delivery.Payload = []byte(payload)
+	if nextAttemptAt.Valid {
+		delivery.NextAttemptAt = &nextAttemptAt.Time
+	}
+	if responseStatus.Valid {
+		status := int(responseStatus.Int64)
+		delivery.ResponseStatus = &status
+	}
+	if redeliveryOf.Valid {
+		delivery.RedeliveryOf = &redeliveryOf.Int64
+	}
+	if deliveredAt.Valid {
+		delivery.DeliveredAt = &deliveredAt.Time
+	}
+	return delivery, nil
+}
@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/mihaamiharu/test-case-management-be/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhookRepository_ListActiveByEvent(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewWebhookRepository(db)
+	now := time.Now()
+
+	// Test case: subscribed events are stored comma-separated and split when scanned
+	mock.ExpectQuery("FROM webhooks\\s+WHERE project_id = \\? AND active = TRUE AND FIND_IN_SET\\(\\?, events\\) > 0").
+		WithArgs(1, models.WebhookEventDefectOpened).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "project_id", "name", "url", "secret", "events", "active", "created_by", "created_at", "updated_at"}).
+			AddRow(3, 1, "CI", "https://ci.example.com/hook", "whsec_abc", "test_case.created,defect.opened", true, nil, now, now))
+
+	webhooks, err := repo.ListActiveByEvent(1, models.WebhookEventDefectOpened)
+
+	require.NoError(t, err)
+	require.Len(t, webhooks, 1)
+	assert.Equal(t, []string{models.WebhookEventTestCaseCreated, models.WebhookEventDefectOpened}, webhooks[0].Events)
+	assert.Nil(t, webhooks[0].CreatedBy)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestWebhookRepository_ClaimDelivery(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewWebhookRepository(db)
+	due := time.Now()
+	until := due.Add(time.Minute)
+
+	// Test case: a due delivery is claimed by postponing its next attempt
+	mock.ExpectExec("UPDATE webhook_deliveries SET next_attempt_at = \\?").
+		WithArgs(until, 5, models.WebhookDeliveryPending, &due).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	delivery := &models.WebhookDelivery{ID: 5, NextAttemptAt: &due}
+	claimed, err := repo.ClaimDelivery(delivery, until)
+
+	assert.NoError(t, err)
+	assert.True(t, claimed)
+	assert.Equal(t, until, *delivery.NextAttemptAt)
+
+	// Test case: a delivery another server claimed first is left alone
+	mock.ExpectExec("UPDATE webhook_deliveries SET next_attempt_at = \\?").
+		WithArgs(until, 6, models.WebhookDeliveryPending, &due).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	claimed, err = repo.ClaimDelivery(&models.WebhookDelivery{ID: 6, NextAttemptAt: &due}, until)
+
+	assert.NoError(t, err)
+	assert.False(t, claimed)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
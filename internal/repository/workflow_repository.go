@@ -0,0 +1,220 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/mihaamiharu/test-case-management-be/internal/models"
+)
+
+// WorkflowRepositoryInterface defines the interface for workflow repository operations
+type WorkflowRepositoryInterface interface {
+	GetWorkflow(projectID int64) (*models.Workflow, error)
+	SaveWorkflow(workflow *models.Workflow, statusMapping, priorityMapping map[string]string) error
+	ListUsedValues(projectID int64) (statuses []string, priorities []string, err error)
+}
+
+// WorkflowRepository handles database operations for project workflows
+type WorkflowRepository struct {
+	db *sql.DB
+}
+
+// NewWorkflowRepository creates a new workflow repository
+func NewWorkflowRepository(db *sql.DB) *WorkflowRepository {
+	return &WorkflowRepository{db: db}
+}
+
+// GetWorkflow retrieves the workflow of a project. Projects that have not configured
+// a workflow get the built-in one.
+func (r *WorkflowRepository) GetWorkflow(projectID int64) (*models.Workflow, error) {
+	workflow := &models.Workflow{ProjectID: projectID}
+
+	statusRows, err := r.db.Query(`
+		SELECT name, label, color, position, is_terminal
+		FROM project_workflow_statuses
+		WHERE project_id = ?
+		ORDER BY position, id`, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get workflow statuses: %v", err)
+	}
+	defer statusRows.Close()
+
+	for statusRows.Next() {
+		status := &models.WorkflowStatus{}
+		if err := statusRows.Scan(&status.Name, &status.Label, &status.Color, &status.Position, &status.Terminal); err != nil {
+			return nil, fmt.Errorf("failed to scan workflow status: %v", err)
+		}
+		workflow.Statuses = append(workflow.Statuses, status)
+	}
+	if err := statusRows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(workflow.Statuses) == 0 {
+		return models.DefaultWorkflow(projectID), nil
+	}
+
+	priorityRows, err := r.db.Query(`
+		SELECT name, label, color, position, is_default
+		FROM project_workflow_priorities
+		WHERE project_id = ?
+		ORDER BY position, id`, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get workflow priorities: %v", err)
+	}
+	defer priorityRows.Close()
+
+	for priorityRows.Next() {
+		priority := &models.WorkflowPriority{}
+		if err := priorityRows.Scan(&priority.Name, &priority.Label, &priority.Color, &priority.Position, &priority.Default); err != nil {
+			return nil, fmt.Errorf("failed to scan workflow priority: %v", err)
+		}
+		workflow.Priorities = append(workflow.Priorities, priority)
+	}
+	if err := priorityRows.Err(); err != nil {
+		return nil, err
+	}
+
+	transitionRows, err := r.db.Query(`
+		SELECT from_status, to_status
+		FROM project_workflow_transitions
+		WHERE project_id = ?
+		ORDER BY from_status, to_status`, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get workflow transitions: %v", err)
+	}
+	defer transitionRows.Close()
+
+	workflow.Transitions = []*models.WorkflowTransition{}
+	for transitionRows.Next() {
+		transition := &models.WorkflowTransition{}
+		if err := transitionRows.Scan(&transition.From, &transition.To); err != nil {
+			return nil, fmt.Errorf("failed to scan workflow transition: %v", err)
+		}
+		workflow.Transitions = append(workflow.Transitions, transition)
+	}
+
+	return workflow, transitionRows.Err()
+}
+
+// SaveWorkflow replaces the workflow of a project and moves test cases to new statuses
+// and priorities according to the mappings, all in a single transaction
+func (r *WorkflowRepository) SaveWorkflow(workflow *models.Workflow, statusMapping, priorityMapping map[string]string) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	for _, table := range []string{"project_workflow_statuses", "project_workflow_priorities", "project_workflow_transitions"} {
+		if _, err := tx.Exec("DELETE FROM "+table+" WHERE project_id = ?", workflow.ProjectID); err != nil {
+			return fmt.Errorf("failed to clear workflow: %v", err)
+		}
+	}
+
+	for _, status := range workflow.Statuses {
+		_, err := tx.Exec(`
+			INSERT INTO project_workflow_statuses (project_id, name, label, color, position, is_terminal)
+			VALUES (?, ?, ?, ?, ?, ?)`,
+			workflow.ProjectID, status.Name, status.Label, status.Color, status.Position, status.Terminal,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to save workflow status: %v", err)
+		}
+	}
+
+	for _, priority := range workflow.Priorities {
+		_, err := tx.Exec(`
+			INSERT INTO project_workflow_priorities (project_id, name, label, color, position, is_default)
+			VALUES (?, ?, ?, ?, ?, ?)`,
+			workflow.ProjectID, priority.Name, priority.Label, priority.Color, priority.Position, priority.Default,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to save workflow priority: %v", err)
+		}
+	}
+
+	for _, transition := range workflow.Transitions {
+		_, err := tx.Exec(`
+			INSERT INTO project_workflow_transitions (project_id, from_status, to_status)
+			VALUES (?, ?, ?)`,
+			workflow.ProjectID, transition.From, transition.To,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to save workflow transition: %v", err)
+		}
+	}
+
+	for from, to := range statusMapping {
+		_, err := tx.Exec("UPDATE test_cases SET status = ? WHERE project_id = ? AND status = ?", to, workflow.ProjectID, from)
+		if err != nil {
+			return fmt.Errorf("failed to migrate test case statuses: %v", err)
+		}
+	}
+
+	for from, to := range priorityMapping {
+		_, err := tx.Exec("UPDATE test_cases SET priority = ? WHERE project_id = ? AND priority = ?", to, workflow.ProjectID, from)
+		if err != nil {
+			return fmt.Errorf("failed to migrate test case priorities: %v", err)
+		}
+	}
+
+	// Test cases in the trash do not hold back a workflow change, so those that still have a
+	// value the workflow no longer has are reset, which lets them be restored later
+	_, err = tx.Exec(`
+		UPDATE test_cases SET status = ?
+		WHERE project_id = ? AND deleted_at IS NOT NULL
+		AND status NOT IN (SELECT name FROM project_workflow_statuses WHERE project_id = ?)`,
+		models.StatusDraft, workflow.ProjectID, workflow.ProjectID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to reset statuses of trashed test cases: %v", err)
+	}
+	_, err = tx.Exec(`
+		UPDATE test_cases SET priority = ?
+		WHERE project_id = ? AND deleted_at IS NOT NULL
+		AND priority NOT IN (SELECT name FROM project_workflow_priorities WHERE project_id = ?)`,
+		workflow.DefaultPriority(), workflow.ProjectID, workflow.ProjectID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to reset priorities of trashed test cases: %v", err)
+	}
+
+	return tx.Commit()
+}
+
+// ListUsedValues retrieves the distinct statuses and priorities of the test cases in a
+// project, leaving out those in the trash, which SaveWorkflow resets
+func (r *WorkflowRepository) ListUsedValues(projectID int64) ([]string, []string, error) {
+	statuses, err := r.listDistinct("SELECT DISTINCT status FROM test_cases WHERE project_id = ? AND deleted_at IS NULL", projectID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list used statuses: %v", err)
+	}
+
+	priorities, err := r.listDistinct("SELECT DISTINCT priority FROM test_cases WHERE project_id = ? AND deleted_at IS NULL", projectID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list used priorities: %v", err)
+	}
+
+	return statuses, priorities, nil
+}
+
+// listDistinct runs a query that returns a single string column
+func (r *WorkflowRepository) listDistinct(query string, projectID int64) ([]string, error) {
+	rows, err := r.db.Query(query, projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var values []string
+	for rows.Next() {
+		var value string
+		if err := rows.Scan(&value); err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+
+	return values, rows.Err()
+}
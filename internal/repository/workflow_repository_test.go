@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/mihaamiharu/test-case-management-be/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWorkflowRepository_ListUsedValues(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewWorkflowRepository(db)
+
+	// Test case: only the values of test cases outside the trash are in use
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT DISTINCT status FROM test_cases WHERE project_id = ? AND deleted_at IS NULL")).
+		WithArgs(int64(1)).
+		WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow("draft").AddRow("approved"))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT DISTINCT priority FROM test_cases WHERE project_id = ? AND deleted_at IS NULL")).
+		WithArgs(int64(1)).
+		WillReturnRows(sqlmock.NewRows([]string{"priority"}).AddRow("high"))
+
+	statuses, priorities, err := repo.ListUsedValues(1)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"draft", "approved"}, statuses)
+	assert.Equal(t, []string{"high"}, priorities)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestWorkflowRepository_SaveWorkflow(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewWorkflowRepository(db)
+	workflow := &models.Workflow{
+		ProjectID:  1,
+		Statuses:   []*models.WorkflowStatus{{Name: "draft", Label: "Draft"}},
+		Priorities: []*models.WorkflowPriority{{Name: "low", Label: "Low"}, {Name: "normal", Label: "Normal", Position: 1, Default: true}},
+	}
+
+	// Test case: mapped values move test cases, and trashed test cases with removed values
+	// get the draft status and the default priority
+	mock.ExpectBegin()
+	for _, table := range []string{"project_workflow_statuses", "project_workflow_priorities", "project_workflow_transitions"} {
+		mock.ExpectExec(regexp.QuoteMeta("DELETE FROM " + table + " WHERE project_id = ?")).
+			WithArgs(int64(1)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+	}
+	mock.ExpectExec("INSERT INTO project_workflow_statuses").
+		WithArgs(int64(1), "draft", "Draft", "", 0, false).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("INSERT INTO project_workflow_priorities").
+		WithArgs(int64(1), "low", "Low", "", 0, false).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("INSERT INTO project_workflow_priorities").
+		WithArgs(int64(1), "normal", "Normal", "", 1, true).
+		WillReturnResult(sqlmock.NewResult(2, 1))
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE test_cases SET status = ? WHERE project_id = ? AND status = ?")).
+		WithArgs("draft", int64(1), "ready").
+		WillReturnResult(sqlmock.NewResult(0, 3))
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE test_cases SET status = ? WHERE project_id = ? AND deleted_at IS NOT NULL AND status NOT IN (SELECT name FROM project_workflow_statuses WHERE project_id = ?)")).
+		WithArgs(models.StatusDraft, int64(1), int64(1)).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE test_cases SET priority = ? WHERE project_id = ? AND deleted_at IS NOT NULL AND priority NOT IN (SELECT name FROM project_workflow_priorities WHERE project_id = ?)")).
+		WithArgs(models.TestCasePriority("normal"), int64(1), int64(1)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	err = repo.SaveWorkflow(workflow, map[string]string{"ready": "draft"}, nil)
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,228 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/mihaamiharu/test-case-management-be/internal/config"
+	"github.com/mihaamiharu/test-case-management-be/internal/mail"
+	"github.com/mihaamiharu/test-case-management-be/internal/models"
+	"github.com/mihaamiharu/test-case-management-be/internal/repository"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var (
+	ErrInvalidUserToken     = errors.New("invalid or expired token")
+	ErrEmailAlreadyVerified = errors.New("email address is already verified")
+)
+
+// AccountService handles email verification and forgotten passwords
+type AccountService struct {
+	userRepo      repository.UserRepositoryInterface
+	userTokenRepo repository.UserTokenRepositoryInterface
+	sessionRepo   repository.SessionRepositoryInterface
+	mailer        mail.Sender
+	policy        *PasswordPolicy
+	cfg           config.AccountConfig
+	accessExpiry  time.Duration
+	ssoDomains    map[string]bool
+}
+
+// NewAccountService creates a new account service
+func NewAccountService(
+	userRepo repository.UserRepositoryInterface,
+	userTokenRepo repository.UserTokenRepositoryInterface,
+	sessionRepo repository.SessionRepositoryInterface,
+	mailer mail.Sender,
+	cfg *config.Config,
+) *AccountService {
+	return &AccountService{
+		userRepo:      userRepo,
+		userTokenRepo: userTokenRepo,
+		sessionRepo:   sessionRepo,
+		mailer:        mailer,
+		policy:        NewPasswordPolicy(cfg.Accounts.PasswordMinLength),
+		cfg:           cfg.Accounts,
+		accessExpiry:  cfg.JWTAccessExpiry,
+		ssoDomains:    parseSSODomains(cfg.OIDC.SSODomains),
+	}
+}
+
+// SendVerification emails a link that verifies the email address of a user
+func (s *AccountService) SendVerification(user *models.User) error {
+	if user.IsEmailVerified() {
+		return ErrEmailAlreadyVerified
+	}
+
+	token, err := s.createToken(user, models.UserTokenEmailVerification, s.cfg.EmailVerificationExpiry)
+	if err != nil {
+		return err
+	}
+
+	return s.mailer.Send(&mail.Message{
+		To:      user.Email,
+		Subject: "Verify your email address",
+		Body: fmt.Sprintf("Hi %s,\n\nPlease verify your email address by opening this link:\n\n%s\n\nThe link expires in %s.\n",
+			user.Username, s.link("verify-email", token), s.cfg.EmailVerificationExpiry),
+	})
+}
+
+// VerifyEmail verifies the email address a verification token was sent to
+func (s *AccountService) VerifyEmail(value string) (*models.User, error) {
+	user, err := s.useToken(value, models.UserTokenEmailVerification)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.userRepo.SetEmailVerified(user.ID); err != nil {
+		return nil, err
+	}
+	return s.userRepo.GetByID(user.ID)
+}
+
+// ForgotPassword emails a password reset link to the user with an email address. Nothing
+// tells the caller whether the address belongs to a user.
+func (s *AccountService) ForgotPassword(email string) error {
+	user, err := s.userRepo.GetByEmail(email)
+	if err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			return nil
+		}
+		return err
+	}
+	// Users of SSO domains have no password to reset
+	if user.IsDeactivated() || isSSOEmail(s.ssoDomains, user.Email) {
+		return nil
+	}
+
+	token, err := s.createToken(user, models.UserTokenPasswordReset, s.cfg.PasswordResetExpiry)
+	if err != nil {
+		return err
+	}
+
+	err = s.mailer.Send(&mail.Message{
+		To:      user.Email,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf("Hi %s,\n\nSomeone asked to reset your password. To choose a new one, open this link:\n\n%s\n\nThe link expires in %s. If you did not ask for this, you can ignore this email.\n",
+			user.Username, s.link("reset-password", token), s.cfg.PasswordResetExpiry),
+	})
+	if err != nil {
+		// Failing the request would tell the caller that the address belongs to a user
+		log.Printf("failed to send password reset email to user %d: %v", user.ID, err)
+	}
+	return nil
+}
+
+// ResetPassword sets a new password with a password reset token and ends all sessions
+// of the user. The reset also proves that the user controls its email address.
+func (s *AccountService) ResetPassword(value, newPassword string) error {
+	token, err := s.userTokenRepo.GetByHash(hashToken(value))
+	if err != nil {
+		if errors.Is(err, repository.ErrUserTokenNotFound) {
+			return ErrInvalidUserToken
+		}
+		return err
+	}
+	user, err := s.checkToken(token, models.UserTokenPasswordReset)
+	if err != nil {
+		return err
+	}
+
+	// Check the password before using the token, so that a rejected password can be retried
+	if err := s.policy.Check(newPassword, user); err != nil {
+		return err
+	}
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+
+	if err := s.userTokenRepo.Use(token.ID); err != nil {
+		if errors.Is(err, repository.ErrUserTokenUsed) {
+			return ErrInvalidUserToken
+		}
+		return err
+	}
+
+	if err := s.userRepo.UpdatePassword(user.ID, string(hashedPassword)); err != nil {
+		return err
+	}
+	if !user.IsEmailVerified() {
+		if err := s.userRepo.SetEmailVerified(user.ID); err != nil {
+			return err
+		}
+	}
+	return s.sessionRepo.RevokeAllByUser(user.ID, 0, time.Now().Add(s.accessExpiry))
+}
+
+// createToken stores a new token for a user and returns its value
+func (s *AccountService) createToken(user *models.User, purpose models.UserTokenPurpose, expiry time.Duration) (string, error) {
+	value, err := randomToken(32)
+	if err != nil {
+		return "", err
+	}
+
+	token := &models.UserToken{
+		UserID:    user.ID,
+		Purpose:   purpose,
+		TokenHash: hashToken(value),
+		Email:     user.Email,
+		ExpiresAt: time.Now().Add(expiry),
+	}
+	if err := s.userTokenRepo.Create(token); err != nil {
+		return "", err
+	}
+	return value, nil
+}
+
+// useToken looks up a token, checks it and marks it as used
+func (s *AccountService) useToken(value string, purpose models.UserTokenPurpose) (*models.User, error) {
+	token, err := s.userTokenRepo.GetByHash(hashToken(value))
+	if err != nil {
+		if errors.Is(err, repository.ErrUserTokenNotFound) {
+			return nil, ErrInvalidUserToken
+		}
+		return nil, err
+	}
+	user, err := s.checkToken(token, purpose)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.userTokenRepo.Use(token.ID); err != nil {
+		if errors.Is(err, repository.ErrUserTokenUsed) {
+			return nil, ErrInvalidUserToken
+		}
+		return nil, err
+	}
+	return user, nil
+}
+
+// checkToken reports ErrInvalidUserToken unless a token has the purpose, is unused and
+// unexpired, and was sent to the current email address of an active user
+func (s *AccountService) checkToken(token *models.UserToken, purpose models.UserTokenPurpose) (*models.User, error) {
+	if token.Purpose != purpose || token.UsedAt != nil || time.Now().After(token.ExpiresAt) {
+		return nil, ErrInvalidUserToken
+	}
+
+	user, err := s.userRepo.GetByID(token.UserID)
+	if err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			return nil, ErrInvalidUserToken
+		}
+		return nil, err
+	}
+	if user.IsDeactivated() || !strings.EqualFold(user.Email, token.Email) {
+		return nil, ErrInvalidUserToken
+	}
+	return user, nil
+}
+
+// link builds a frontend link that carries a token
+func (s *AccountService) link(path, token string) string {
+	return strings.TrimSuffix(s.cfg.AppURL, "/") + "/" + path + "?token=" + url.QueryEscape(token)
+}
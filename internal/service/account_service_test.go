@@ -0,0 +1,183 @@
+package service
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/mihaamiharu/test-case-management-be/internal/config"
+	"github.com/mihaamiharu/test-case-management-be/internal/mail"
+	"github.com/mihaamiharu/test-case-management-be/internal/models"
+	"github.com/mihaamiharu/test-case-management-be/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// fakeUserTokenRepository keeps email verification and password reset tokens in memory
+type fakeUserTokenRepository struct {
+	tokens []*models.UserToken
+}
+
+func (r *fakeUserTokenRepository) Create(token *models.UserToken) error {
+	now := time.Now()
+	for _, other := range r.tokens {
+		if other.UserID == token.UserID && other.Purpose == token.Purpose && other.UsedAt == nil {
+			other.UsedAt = &now
+		}
+	}
+	token.ID = int64(len(r.tokens) + 1)
+	r.tokens = append(r.tokens, token)
+	return nil
+}
+
+func (r *fakeUserTokenRepository) GetByHash(tokenHash string) (*models.UserToken, error) {
+	for _, token := range r.tokens {
+		if token.TokenHash == tokenHash {
+			copied := *token
+			return &copied, nil
+		}
+	}
+	return nil, repository.ErrUserTokenNotFound
+}
+
+func (r *fakeUserTokenRepository) Use(id int64) error {
+	for _, token := range r.tokens {
+		if token.ID == id {
+			if token.UsedAt != nil {
+				return repository.ErrUserTokenUsed
+			}
+			now := time.Now()
+			token.UsedAt = &now
+			return nil
+		}
+	}
+	return repository.ErrUserTokenNotFound
+}
+
+var mailTokenPattern = regexp.MustCompile(`token=([0-9a-f]+)`)
+
+// lastMailToken returns the token in the link of the latest email written to dir
+func lastMailToken(t *testing.T, dir string) string {
+	names, err := filepath.Glob(filepath.Join(dir, "*.eml"))
+	require.NoError(t, err)
+	require.NotEmpty(t, names)
+	sort.Strings(names)
+
+	content, err := os.ReadFile(names[len(names)-1])
+	require.NoError(t, err)
+	match := mailTokenPattern.FindStringSubmatch(string(content))
+	require.NotNil(t, match)
+	return match[1]
+}
+
+func newTestAccountService(t *testing.T) (*AccountService, *AuthService, *fakeUserRepository, string) {
+	hash, err := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.MinCost)
+	require.NoError(t, err)
+
+	users := &fakeUserRepository{users: map[int64]*models.User{
+		1: {ID: 1, Username: "alice", Email: "alice@example.com", PasswordHash: string(hash), Role: models.RoleUser},
+	}}
+	sessions := newFakeSessionRepository()
+	cfg := &config.Config{
+		JWTSecret:          "secret",
+		JWTAccessExpiry:    15 * time.Minute,
+		RefreshTokenExpiry: time.Hour,
+		Accounts: config.AccountConfig{
+			AppURL:                   "https://tcm.example.com/",
+			RequireEmailVerification: true,
+			EmailVerificationExpiry:  time.Hour,
+			PasswordResetExpiry:      time.Hour,
+		},
+	}
+	dir := t.TempDir()
+	accounts := NewAccountService(users, &fakeUserTokenRepository{}, sessions, mail.NewFileSender(dir, "tcm@example.com"), cfg)
+
+	return accounts, NewAuthService(users, sessions, NewTwoFactorService(newFakeTwoFactorRepository(), users, cfg), cfg), users, dir
+}
+
+func TestAccountService_VerifyEmail(t *testing.T) {
+	s, auth, users, dir := newTestAccountService(t)
+
+	// Test case: unverified users cannot log in when verification is required
+	_, _, err := auth.Login("alice@example.com", "password123", SessionClient{})
+	assert.Equal(t, ErrEmailNotVerified, err)
+
+	require.NoError(t, s.SendVerification(users.users[1]))
+	first := lastMailToken(t, dir)
+	require.NoError(t, s.SendVerification(users.users[1]))
+	second := lastMailToken(t, dir)
+
+	// Test case: only the latest email works
+	_, err = s.VerifyEmail(first)
+	assert.Equal(t, ErrInvalidUserToken, err)
+
+	user, err := s.VerifyEmail(second)
+	require.NoError(t, err)
+	assert.True(t, user.IsEmailVerified())
+	_, _, err = auth.Login("alice@example.com", "password123", SessionClient{})
+	assert.NoError(t, err)
+
+	// Test case: tokens are single-use
+	_, err = s.VerifyEmail(second)
+	assert.Equal(t, ErrInvalidUserToken, err)
+	assert.Equal(t, ErrEmailAlreadyVerified, s.SendVerification(user))
+}
+
+func TestAccountService_ResetPassword(t *testing.T) {
+	s, auth, users, dir := newTestAccountService(t)
+
+	// Test case: unknown email addresses are not reported
+	require.NoError(t, s.ForgotPassword("nobody@example.com"))
+	names, err := filepath.Glob(filepath.Join(dir, "*.eml"))
+	require.NoError(t, err)
+	assert.Empty(t, names)
+
+	require.NoError(t, s.ForgotPassword("alice@example.com"))
+	token := lastMailToken(t, dir)
+
+	// Test case: a verification token cannot reset a password
+	require.NoError(t, s.SendVerification(users.users[1]))
+	assert.Equal(t, ErrInvalidUserToken, s.ResetPassword(lastMailToken(t, dir), "n3w-secret"))
+
+	// Test case: a weak password is rejected without using the token
+	err = s.ResetPassword(token, "alice2024!")
+	assert.True(t, errors.Is(err, ErrWeakPassword))
+
+	require.NoError(t, s.ResetPassword(token, "n3w-secret"))
+	assert.True(t, users.users[1].IsEmailVerified())
+	_, _, err = auth.Login("alice@example.com", "n3w-secret", SessionClient{})
+	assert.NoError(t, err)
+
+	assert.Equal(t, ErrInvalidUserToken, s.ResetPassword(token, "an0ther-secret"))
+}
+
+func TestPasswordPolicy_Check(t *testing.T) {
+	policy := NewPasswordPolicy(10)
+	user := &models.User{Username: "dana", Email: "dana.scully@example.com"}
+
+	tests := []struct {
+		password string
+		valid    bool
+	}{
+		{"short1!", false},
+		{"onlyletterslong", false},
+		{"1234567890", false},
+		{"Password123", false},
+		{"dana-rocks-2024", false},
+		{"my-dana.scully-1", false},
+		{"correct horse 9", true},
+	}
+	for _, tt := range tests {
+		err := policy.Check(tt.password, user)
+		if tt.valid {
+			assert.NoError(t, err, tt.password)
+		} else {
+			assert.True(t, errors.Is(err, ErrWeakPassword), tt.password)
+		}
+	}
+}
@@ -0,0 +1,162 @@
+package service
+
+import (
+	"errors"
+	"log"
+	"time"
+
+	"github.com/mihaamiharu/test-case-management-be/internal/models"
+	"github.com/mihaamiharu/test-case-management-be/internal/repository"
+)
+
+var (
+	ErrInvalidAPIToken         = errors.New("invalid, expired or revoked API token")
+	ErrAPITokenExpiryInPast    = errors.New("expires_at must be in the future")
+	ErrAPITokenScopeDenied     = errors.New("API token does not have the scope for this request")
+	ErrAPITokenProjectDenied   = errors.New("API token is restricted to another project")
+	ErrAPITokenProjectRequired = errors.New("API token is restricted to a project; this request must name it")
+)
+
+// apiTokenLastUsedInterval limits how often the last-used time of a token is written
+const apiTokenLastUsedInterval = time.Minute
+
+// APITokenService handles personal API tokens for CI pipelines and scripts
+type APITokenService struct {
+	apiTokenRepo repository.APITokenRepositoryInterface
+	userRepo     repository.UserRepositoryInterface
+	archiveRepo  repository.ArchiveRepositoryInterface
+}
+
+// NewAPITokenService creates a new API token service
+func NewAPITokenService(
+	apiTokenRepo repository.APITokenRepositoryInterface,
+	userRepo repository.UserRepositoryInterface,
+	archiveRepo repository.ArchiveRepositoryInterface,
+) *APITokenService {
+	return &APITokenService{
+		apiTokenRepo: apiTokenRepo,
+		userRepo:     userRepo,
+		archiveRepo:  archiveRepo,
+	}
+}
+
+// Create creates an API token for a user and returns it with its value, which is not stored
+func (s *APITokenService) Create(userID int64, tokenCreate *models.APITokenCreate) (*models.APIToken, string, error) {
+	if tokenCreate.ExpiresAt != nil && !tokenCreate.ExpiresAt.After(time.Now()) {
+		return nil, "", ErrAPITokenExpiryInPast
+	}
+
+	secret, err := randomToken(20)
+	if err != nil {
+		return nil, "", err
+	}
+	value := models.APITokenPrefix + secret
+
+	token := &models.APIToken{
+		UserID:      userID,
+		Name:        tokenCreate.Name,
+		TokenPrefix: value[:len(models.APITokenPrefix)+8],
+		TokenHash:   hashToken(value),
+		Scopes:      uniqueScopes(tokenCreate.Scopes),
+		ProjectID:   tokenCreate.ProjectID,
+		ExpiresAt:   tokenCreate.ExpiresAt,
+	}
+	if err := s.apiTokenRepo.Create(token); err != nil {
+		return nil, "", err
+	}
+
+	return token, value, nil
+}
+
+// List lists the API tokens of a user
+func (s *APITokenService) List(userID int64) ([]*models.APIToken, error) {
+	return s.apiTokenRepo.ListByUser(userID)
+}
+
+// Revoke revokes an API token of a user
+func (s *APITokenService) Revoke(userID, id int64) error {
+	return s.apiTokenRepo.Revoke(id, userID)
+}
+
+// Authenticate looks up the user of an API token and records that the token was used
+func (s *APITokenService) Authenticate(value string) (*models.User, *models.APIToken, error) {
+	token, err := s.apiTokenRepo.GetByHash(hashToken(value))
+	if err != nil {
+		if errors.Is(err, repository.ErrAPITokenNotFound) {
+			return nil, nil, ErrInvalidAPIToken
+		}
+		return nil, nil, err
+	}
+
+	now := time.Now()
+	if token.RevokedAt != nil || (token.ExpiresAt != nil && now.After(*token.ExpiresAt)) {
+		return nil, nil, ErrInvalidAPIToken
+	}
+
+	user, err := s.userRepo.GetByID(token.UserID)
+	if err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			return nil, nil, ErrInvalidAPIToken
+		}
+		return nil, nil, err
+	}
+	if user.IsDeactivated() {
+		return nil, nil, ErrInvalidAPIToken
+	}
+
+	if token.LastUsedAt == nil || now.Sub(*token.LastUsedAt) >= apiTokenLastUsedInterval {
+		if err := s.apiTokenRepo.UpdateLastUsed(token.ID, now); err != nil {
+			log.Printf("failed to record use of API token %d: %v", token.ID, err)
+		}
+		token.LastUsedAt = &now
+	}
+
+	return user, token, nil
+}
+
+// CheckScope reports ErrAPITokenScopeDenied when a token may not make a request. Reading
+// is allowed with any scope; writing needs the write scope, or the results scope for
+// requests that record test results.
+func (s *APITokenService) CheckScope(token *models.APIToken, write, recordsResults bool) error {
+	if !write {
+		return nil
+	}
+	if token.HasScope(models.APITokenScopeWrite) {
+		return nil
+	}
+	if recordsResults && token.HasScope(models.APITokenScopeResults) {
+		return nil
+	}
+	return ErrAPITokenScopeDenied
+}
+
+// CheckProject reports ErrAPITokenProjectDenied when a token restricted to a project is
+// used for an entity of another project
+func (s *APITokenService) CheckProject(token *models.APIToken, entityType string, id int64) error {
+	if token.ProjectID == nil {
+		return nil
+	}
+
+	projectID, err := s.archiveRepo.GetProjectID(entityType, id)
+	if err != nil {
+		return err
+	}
+	// Entities that do not exist are left to the handler to report
+	if projectID != 0 && projectID != *token.ProjectID {
+		return ErrAPITokenProjectDenied
+	}
+	return nil
+}
+
+// uniqueScopes removes repeated scopes
+func uniqueScopes(scopes []models.APITokenScope) []models.APITokenScope {
+	seen := make(map[models.APITokenScope]bool)
+	var unique []models.APITokenScope
+	for _, scope := range scopes {
+		if !seen[scope] {
+			seen[scope] = true
+			unique = append(unique, scope)
+		}
+	}
+	return unique
+}
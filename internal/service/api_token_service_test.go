@@ -0,0 +1,127 @@
+package service
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mihaamiharu/test-case-management-be/internal/models"
+	"github.com/mihaamiharu/test-case-management-be/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeAPITokenRepository keeps API tokens in memory
+type fakeAPITokenRepository struct {
+	tokens []*models.APIToken
+}
+
+func (r *fakeAPITokenRepository) Create(token *models.APIToken) error {
+	token.ID = int64(len(r.tokens) + 1)
+	r.tokens = append(r.tokens, token)
+	return nil
+}
+
+func (r *fakeAPITokenRepository) GetByHash(tokenHash string) (*models.APIToken, error) {
+	for _, token := range r.tokens {
+		if token.TokenHash == tokenHash {
+			return token, nil
+		}
+	}
+	return nil, repository.ErrAPITokenNotFound
+}
+
+func (r *fakeAPITokenRepository) ListByUser(userID int64) ([]*models.APIToken, error) {
+	return r.tokens, nil
+}
+
+func (r *fakeAPITokenRepository) Revoke(id, userID int64) error {
+	for _, token := range r.tokens {
+		if token.ID == id && token.UserID == userID && token.RevokedAt == nil {
+			now := time.Now()
+			token.RevokedAt = &now
+			return nil
+		}
+	}
+	return repository.ErrAPITokenNotFound
+}
+
+func (r *fakeAPITokenRepository) UpdateLastUsed(id int64, lastUsedAt time.Time) error {
+	return nil
+}
+
+// fakeArchiveRepository maps entities to their projects
+type fakeArchiveRepository struct {
+	projects map[string]int64
+}
+
+func (r *fakeArchiveRepository) GetArchivedState(entityType string, id int64) (bool, bool, error) {
+	return false, false, nil
+}
+
+func (r *fakeArchiveRepository) GetProjectID(entityType string, id int64) (int64, error) {
+	return r.projects[entityType], nil
+}
+
+func newTestAPITokenService() *APITokenService {
+	users := &fakeUserRepository{users: map[int64]*models.User{1: {ID: 1, Email: "ci@example.com"}}}
+	archive := &fakeArchiveRepository{projects: map[string]int64{"test_run": 2, "test_execution": 3}}
+	return NewAPITokenService(&fakeAPITokenRepository{}, users, archive)
+}
+
+func TestAPITokenService_Authenticate(t *testing.T) {
+	s := newTestAPITokenService()
+
+	token, value, err := s.Create(1, &models.APITokenCreate{
+		Name:   "CI",
+		Scopes: []models.APITokenScope{models.APITokenScopeResults, models.APITokenScopeResults},
+	})
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(value, models.APITokenPrefix))
+	assert.True(t, strings.HasPrefix(value, token.TokenPrefix))
+	assert.NotContains(t, token.TokenHash, value)
+	assert.Equal(t, []models.APITokenScope{models.APITokenScopeResults}, token.Scopes)
+
+	user, authenticated, err := s.Authenticate(value)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), user.ID)
+	assert.NotNil(t, authenticated.LastUsedAt)
+
+	// Test case: a revoked token is rejected
+	require.NoError(t, s.Revoke(1, token.ID))
+	_, _, err = s.Authenticate(value)
+	assert.Equal(t, ErrInvalidAPIToken, err)
+
+	// Test case: an expiry in the past is rejected
+	past := time.Now().Add(-time.Hour)
+	_, _, err = s.Create(1, &models.APITokenCreate{Name: "Old", Scopes: []models.APITokenScope{models.APITokenScopeRead}, ExpiresAt: &past})
+	assert.Equal(t, ErrAPITokenExpiryInPast, err)
+}
+
+func TestAPITokenService_CheckScope(t *testing.T) {
+	s := newTestAPITokenService()
+	read := &models.APIToken{Scopes: []models.APITokenScope{models.APITokenScopeRead}}
+	results := &models.APIToken{Scopes: []models.APITokenScope{models.APITokenScopeResults}}
+	write := &models.APIToken{Scopes: []models.APITokenScope{models.APITokenScopeWrite}}
+
+	assert.NoError(t, s.CheckScope(read, false, false))
+	assert.Equal(t, ErrAPITokenScopeDenied, s.CheckScope(read, true, true))
+	assert.NoError(t, s.CheckScope(results, true, true))
+	assert.Equal(t, ErrAPITokenScopeDenied, s.CheckScope(results, true, false))
+	assert.NoError(t, s.CheckScope(write, true, false))
+}
+
+func TestAPITokenService_CheckProject(t *testing.T) {
+	s := newTestAPITokenService()
+	projectID := int64(2)
+	token := &models.APIToken{ProjectID: &projectID}
+
+	assert.NoError(t, s.CheckProject(token, "test_run", 10))
+	assert.Equal(t, ErrAPITokenProjectDenied, s.CheckProject(token, "test_execution", 11))
+
+	// Test case: entities that do not exist are left to the handler
+	assert.NoError(t, s.CheckProject(token, "test_case", 12))
+
+	// Test case: unrestricted tokens can be used for every project
+	assert.NoError(t, s.CheckProject(&models.APIToken{}, "test_execution", 11))
+}
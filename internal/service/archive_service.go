@@ -0,0 +1,79 @@
+package service
+
+import (
+	"errors"
+
+	"github.com/mihaamiharu/test-case-management-be/internal/models"
+	"github.com/mihaamiharu/test-case-management-be/internal/repository"
+)
+
+var (
+	ErrProjectArchived   = errors.New("project is archived and read-only; unarchive it to make changes")
+	ErrTestSuiteArchived = errors.New("test suite is archived and read-only; unarchive it to make changes")
+)
+
+// ArchiveService handles archiving projects and test suites, which makes them read-only
+type ArchiveService struct {
+	archiveRepo   repository.ArchiveRepositoryInterface
+	projectRepo   repository.ProjectRepositoryInterface
+	testSuiteRepo repository.TestSuiteRepositoryInterface
+}
+
+// NewArchiveService creates a new archive service
+func NewArchiveService(
+	archiveRepo repository.ArchiveRepositoryInterface,
+	projectRepo repository.ProjectRepositoryInterface,
+	testSuiteRepo repository.TestSuiteRepositoryInterface,
+) *ArchiveService {
+	return &ArchiveService{
+		archiveRepo:   archiveRepo,
+		projectRepo:   projectRepo,
+		testSuiteRepo: testSuiteRepo,
+	}
+}
+
+// SetProjectArchived archives or unarchives a project
+func (s *ArchiveService) SetProjectArchived(id int64, archived bool) (*models.Project, error) {
+	if err := s.projectRepo.SetArchived(id, archived); err != nil {
+		return nil, err
+	}
+	return s.projectRepo.GetByID(id)
+}
+
+// SetTestSuiteArchived archives or unarchives a test suite. The suites of an archived
+// project cannot change until the project is unarchived.
+func (s *ArchiveService) SetTestSuiteArchived(id int64, archived bool) (*models.TestSuite, error) {
+	suite, err := s.testSuiteRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	projectArchived, _, err := s.archiveRepo.GetArchivedState("project", suite.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+	if projectArchived {
+		return nil, ErrProjectArchived
+	}
+
+	if err := s.testSuiteRepo.SetArchived(id, archived); err != nil {
+		return nil, err
+	}
+	return s.testSuiteRepo.GetByID(id)
+}
+
+// CheckWritable reports ErrProjectArchived or ErrTestSuiteArchived when an entity belongs
+// to an archived project or test suite
+func (s *ArchiveService) CheckWritable(entityType string, id int64) error {
+	projectArchived, suiteArchived, err := s.archiveRepo.GetArchivedState(entityType, id)
+	if err != nil {
+		return err
+	}
+	if projectArchived {
+		return ErrProjectArchived
+	}
+	if suiteArchived {
+		return ErrTestSuiteArchived
+	}
+	return nil
+}
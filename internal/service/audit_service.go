@@ -0,0 +1,96 @@
+package service
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/mihaamiharu/test-case-management-be/internal/models"
+	"github.com/mihaamiharu/test-case-management-be/internal/repository"
+)
+
+const (
+	defaultAuditPageSize = 100
+	maxAuditPageSize     = 500
+)
+
+// AuditService handles recording and querying the audit log
+type AuditService struct {
+	auditRepo repository.AuditRepositoryInterface
+}
+
+// NewAuditService creates a new audit service
+func NewAuditService(auditRepo repository.AuditRepositoryInterface) *AuditService {
+	return &AuditService{
+		auditRepo: auditRepo,
+	}
+}
+
+// Record stores an audit entry
+func (s *AuditService) Record(entry *models.AuditEntry) error {
+	return s.auditRepo.Create(entry)
+}
+
+// List retrieves a page of audit entries matching a filter, newest first
+func (s *AuditService) List(filter *models.AuditFilter) ([]*models.AuditEntry, error) {
+	if filter.Limit <= 0 {
+		filter.Limit = defaultAuditPageSize
+	}
+	if filter.Limit > maxAuditPageSize {
+		filter.Limit = maxAuditPageSize
+	}
+	if filter.Offset < 0 {
+		filter.Offset = 0
+	}
+
+	return s.auditRepo.List(filter)
+}
+
+// ExportCSV writes every audit entry matching a filter as CSV
+func (s *AuditService) ExportCSV(filter *models.AuditFilter, w io.Writer) error {
+	filter.Limit = 0
+	filter.Offset = 0
+
+	entries, err := s.auditRepo.List(filter)
+	if err != nil {
+		return err
+	}
+
+	writer := csv.NewWriter(w)
+	header := []string{"id", "created_at", "actor_id", "action", "entity_type", "entity_id", "project_id", "method", "path", "ip_address", "before", "after"}
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		record := []string{
+			strconv.FormatInt(entry.ID, 10),
+			entry.CreatedAt.UTC().Format(time.RFC3339),
+			formatOptionalID(entry.ActorID),
+			string(entry.Action),
+			entry.EntityType,
+			formatOptionalID(entry.EntityID),
+			formatOptionalID(entry.ProjectID),
+			entry.Method,
+			entry.Path,
+			entry.IPAddress,
+			string(entry.Before),
+			string(entry.After),
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// formatOptionalID renders a nullable ID, leaving the cell empty when it is not set
+func formatOptionalID(id *int64) string {
+	if id == nil {
+		return ""
+	}
+	return strconv.FormatInt(*id, 10)
+}
@@ -1,7 +1,11 @@
 package service
 
 import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
+	"strings"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
@@ -12,44 +16,74 @@ import (
 )
 
 var (
-	ErrInvalidCredentials = errors.New("invalid credentials")
+	ErrInvalidCredentials  = errors.New("invalid credentials")
+	ErrInvalidRefreshToken = errors.New("invalid or expired refresh token")
+	ErrRefreshTokenReused  = errors.New("refresh token was already used; the session has been revoked")
+	ErrSSORequired         = errors.New("accounts of this email domain must log in with single sign-on")
+	ErrUserDeactivated     = errors.New("this account has been deactivated")
+	ErrEmailNotVerified    = errors.New("email address must be verified before logging in")
 )
 
+// SessionClient describes the client a session is started from
+type SessionClient struct {
+	UserAgent string
+	IPAddress string
+}
+
 // AuthService handles authentication business logic
 type AuthService struct {
-	userRepo  repository.UserRepositoryInterface
-	jwtSecret string
-	jwtExpiry int
+	userRepo      repository.UserRepositoryInterface
+	sessionRepo   repository.SessionRepositoryInterface
+	jwtSecret     string
+	accessExpiry  time.Duration
+	refreshExpiry time.Duration
+	ssoDomains    map[string]bool
+	policy        *PasswordPolicy
+	requireVerify bool
+	twoFactor     *TwoFactorService
 }
 
 // NewAuthService creates a new auth service
-func NewAuthService(userRepo repository.UserRepositoryInterface, cfg *config.Config) *AuthService {
+func NewAuthService(
+	userRepo repository.UserRepositoryInterface,
+	sessionRepo repository.SessionRepositoryInterface,
+	twoFactorService *TwoFactorService,
+	cfg *config.Config,
+) *AuthService {
 	return &AuthService{
-		userRepo:  userRepo,
-		jwtSecret: cfg.JWTSecret,
-		jwtExpiry: cfg.JWTExpiryHours,
+		userRepo:      userRepo,
+		sessionRepo:   sessionRepo,
+		jwtSecret:     cfg.JWTSecret,
+		accessExpiry:  cfg.JWTAccessExpiry,
+		refreshExpiry: cfg.RefreshTokenExpiry,
+		ssoDomains:    parseSSODomains(cfg.OIDC.SSODomains),
+		policy:        NewPasswordPolicy(cfg.Accounts.PasswordMinLength),
+		requireVerify: cfg.Accounts.RequireEmailVerification,
+		twoFactor:     twoFactorService,
 	}
 }
 
-// Register registers a new user
+// Register registers a new user. Users of SSO domains are created on their first single sign-on instead.
 func (s *AuthService) Register(userCreate *models.UserCreate) (*models.User, error) {
+	if s.requiresSSO(userCreate.Email) {
+		return nil, ErrSSORequired
+	}
+
+	if err := s.policy.Check(userCreate.Password, &models.User{Username: userCreate.Username, Email: userCreate.Email}); err != nil {
+		return nil, err
+	}
+
 	// Hash the password
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(userCreate.Password), bcrypt.DefaultCost)
 	if err != nil {
 		return nil, err
 	}
 
-	// Set default role if not provided
-	role := userCreate.Role
-	if role == "" {
-		role = models.RoleUser
-	}
-
 	user := &models.User{
 		Username:     userCreate.Username,
 		Email:        userCreate.Email,
 		PasswordHash: string(hashedPassword),
-		Role:         role,
+		Role:         models.RoleUser,
 	}
 
 	if err := s.userRepo.Create(user); err != nil {
@@ -59,28 +93,179 @@ func (s *AuthService) Register(userCreate *models.UserCreate) (*models.User, err
 	return user, nil
 }
 
-// Login authenticates a user and returns a JWT token
-func (s *AuthService) Login(email, password string) (string, error) {
+// Login authenticates a user and starts a session with an access and a refresh token.
+// Users with two-factor authentication get a challenge to complete with VerifyTwoFactor instead.
+func (s *AuthService) Login(email, password string, client SessionClient) (*models.AuthTokens, *models.TwoFactorChallenge, error) {
+	if s.requiresSSO(email) {
+		return nil, nil, ErrSSORequired
+	}
+
 	user, err := s.userRepo.GetByEmail(email)
 	if err != nil {
 		if errors.Is(err, repository.ErrUserNotFound) {
-			return "", ErrInvalidCredentials
+			return nil, nil, ErrInvalidCredentials
 		}
-		return "", err
+		return nil, nil, err
 	}
 
 	// Verify password
 	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)); err != nil {
-		return "", ErrInvalidCredentials
+		return nil, nil, ErrInvalidCredentials
+	}
+	if user.IsDeactivated() {
+		return nil, nil, ErrUserDeactivated
+	}
+	if s.requireVerify && !user.IsEmailVerified() {
+		return nil, nil, ErrEmailNotVerified
 	}
 
-	// Generate JWT token
-	token, err := s.generateToken(user)
+	return s.CompleteLogin(user, client)
+}
+
+// CompleteLogin starts a session for a user whose first factor was checked, or issues a
+// two-factor challenge when the user has two-factor authentication enabled
+func (s *AuthService) CompleteLogin(user *models.User, client SessionClient) (*models.AuthTokens, *models.TwoFactorChallenge, error) {
+	enabled, err := s.twoFactor.IsEnabled(user.ID)
 	if err != nil {
-		return "", err
+		return nil, nil, err
+	}
+	if enabled {
+		challenge, err := s.twoFactor.IssueChallenge(user)
+		return nil, challenge, err
+	}
+
+	tokens, err := s.StartSession(user, client)
+	return tokens, nil, err
+}
+
+// VerifyTwoFactor completes a login with the code of the challenge issued for it and starts
+// the session
+func (s *AuthService) VerifyTwoFactor(challengeToken, code string, client SessionClient) (*models.AuthTokens, *models.User, error) {
+	user, err := s.TwoFactorChallengeUser(challengeToken)
+	if err != nil {
+		return nil, nil, err
+	}
+	if user.IsDeactivated() {
+		return nil, nil, ErrUserDeactivated
+	}
+
+	if err := s.twoFactor.Verify(user.ID, code); err != nil {
+		if errors.Is(err, ErrTwoFactorNotEnabled) {
+			return nil, nil, ErrInvalidTwoFactorChallenge
+		}
+		return nil, nil, err
+	}
+
+	tokens, err := s.StartSession(user, client)
+	if err != nil {
+		return nil, nil, err
+	}
+	return tokens, user, nil
+}
+
+// TwoFactorChallengeUser returns the user a two-factor challenge was issued to
+func (s *AuthService) TwoFactorChallengeUser(challengeToken string) (*models.User, error) {
+	userID, err := s.twoFactor.ParseChallenge(challengeToken)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			return nil, ErrInvalidTwoFactorChallenge
+		}
+		return nil, err
+	}
+	return user, nil
+}
+
+// RequiresTwoFactorSetup reports whether a user must enable two-factor authentication
+// before doing anything else
+func (s *AuthService) RequiresTwoFactorSetup(user *models.User) (bool, error) {
+	return s.twoFactor.RequiresSetup(user)
+}
+
+// Refresh exchanges a refresh token for a new access and refresh token. Each refresh token
+// can be used once; using one again revokes its session, since it has probably been stolen.
+func (s *AuthService) Refresh(refreshToken string) (*models.AuthTokens, error) {
+	token, err := s.sessionRepo.GetRefreshToken(hashToken(refreshToken))
+	if err != nil {
+		if errors.Is(err, repository.ErrRefreshTokenNotFound) {
+			return nil, ErrInvalidRefreshToken
+		}
+		return nil, err
+	}
+
+	session, err := s.sessionRepo.GetByID(token.SessionID)
+	if err != nil {
+		if errors.Is(err, repository.ErrSessionNotFound) {
+			return nil, ErrInvalidRefreshToken
+		}
+		return nil, err
+	}
+
+	now := time.Now()
+	if session.RevokedAt != nil || now.After(session.ExpiresAt) || now.After(token.ExpiresAt) {
+		return nil, ErrInvalidRefreshToken
+	}
+	if token.UsedAt != nil {
+		return nil, s.revokeReusedSession(session.ID)
+	}
+
+	user, err := s.userRepo.GetByID(session.UserID)
+	if err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			return nil, ErrInvalidRefreshToken
+		}
+		return nil, err
+	}
+	if user.IsDeactivated() {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	tokenID, tokens, next, err := s.newTokens()
+	if err != nil {
+		return nil, err
+	}
+	session.AccessTokenID = tokenID
+	session.ExpiresAt = next.ExpiresAt
+
+	err = s.sessionRepo.RotateRefreshToken(token.ID, session, next, now.Add(s.accessExpiry))
+	if err != nil {
+		if errors.Is(err, repository.ErrRefreshTokenUsed) {
+			return nil, s.revokeReusedSession(session.ID)
+		}
+		return nil, err
 	}
 
-	return token, nil
+	if err := s.signAccessToken(tokens, user, tokenID, session.ID); err != nil {
+		return nil, err
+	}
+	return tokens, nil
+}
+
+// Logout revokes a session
+func (s *AuthService) Logout(sessionID int64) error {
+	return s.sessionRepo.Revoke(sessionID, time.Now().Add(s.accessExpiry))
+}
+
+// ListSessions lists the active sessions of a user
+func (s *AuthService) ListSessions(userID int64) ([]*models.Session, error) {
+	return s.sessionRepo.ListActiveByUser(userID)
+}
+
+// RevokeSession revokes a session of a user. Sessions of other users are reported as not found.
+func (s *AuthService) RevokeSession(userID, sessionID int64) error {
+	session, err := s.sessionRepo.GetByID(sessionID)
+	if err != nil {
+		return err
+	}
+	if session.UserID != userID {
+		return repository.ErrSessionNotFound
+	}
+
+	return s.Logout(sessionID)
 }
 
 // GetUserByEmail retrieves a user by email
@@ -88,14 +273,83 @@ func (s *AuthService) GetUserByEmail(email string) (*models.User, error) {
 	return s.userRepo.GetByEmail(email)
 }
 
+// StartSession creates a session for an authenticated user and issues its first tokens
+func (s *AuthService) StartSession(user *models.User, client SessionClient) (*models.AuthTokens, error) {
+	tokenID, tokens, refresh, err := s.newTokens()
+	if err != nil {
+		return nil, err
+	}
+
+	session := &models.Session{
+		UserID:        user.ID,
+		AccessTokenID: tokenID,
+		UserAgent:     truncate(client.UserAgent, 255),
+		IPAddress:     client.IPAddress,
+		ExpiresAt:     refresh.ExpiresAt,
+	}
+	if err := s.sessionRepo.Create(session, refresh); err != nil {
+		return nil, err
+	}
+
+	if err := s.signAccessToken(tokens, user, tokenID, session.ID); err != nil {
+		return nil, err
+	}
+	return tokens, nil
+}
+
+// revokeReusedSession revokes a session whose refresh token was used twice
+func (s *AuthService) revokeReusedSession(sessionID int64) error {
+	if err := s.Logout(sessionID); err != nil && !errors.Is(err, repository.ErrSessionNotFound) {
+		return err
+	}
+	return ErrRefreshTokenReused
+}
+
+// newTokens generates a refresh token and the ID of the access token issued with it. The
+// access token is signed once the session is stored.
+func (s *AuthService) newTokens() (string, *models.AuthTokens, *models.RefreshToken, error) {
+	tokenID, err := randomToken(16)
+	if err != nil {
+		return "", nil, nil, err
+	}
+	refreshToken, err := randomToken(32)
+	if err != nil {
+		return "", nil, nil, err
+	}
+
+	now := time.Now()
+	tokens := &models.AuthTokens{
+		AccessExpiresAt:  now.Add(s.accessExpiry),
+		RefreshToken:     refreshToken,
+		RefreshExpiresAt: now.Add(s.refreshExpiry),
+	}
+	refresh := &models.RefreshToken{
+		TokenHash: hashToken(refreshToken),
+		ExpiresAt: tokens.RefreshExpiresAt,
+	}
+	return tokenID, tokens, refresh, nil
+}
+
+// signAccessToken signs the access token of tokens
+func (s *AuthService) signAccessToken(tokens *models.AuthTokens, user *models.User, tokenID string, sessionID int64) error {
+	tokenString, err := s.generateToken(user, tokenID, sessionID, tokens.AccessExpiresAt)
+	if err != nil {
+		return err
+	}
+	tokens.AccessToken = tokenString
+	return nil
+}
+
 // generateToken creates a new JWT token for a user
-func (s *AuthService) generateToken(user *models.User) (string, error) {
+func (s *AuthService) generateToken(user *models.User, tokenID string, sessionID int64, expiresAt time.Time) (string, error) {
 	// Create the Claims
 	claims := jwt.MapClaims{
 		"user_id": user.ID,
 		"email":   user.Email,
 		"role":    user.Role,
-		"exp":     time.Now().Add(time.Hour * time.Duration(s.jwtExpiry)).Unix(),
+		"jti":     tokenID,
+		"sid":     sessionID,
+		"exp":     expiresAt.Unix(),
 	}
 
 	// Create token
@@ -121,6 +375,37 @@ func (s *AuthService) ValidateToken(tokenString string) (*jwt.Token, error) {
 	})
 }
 
+// IsTokenRevoked reports whether a validated token was revoked by logging out, revoking its
+// session or refreshing it. Tokens without an ID predate sessions and count as revoked.
+func (s *AuthService) IsTokenRevoked(token *jwt.Token) (bool, error) {
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return true, nil
+	}
+
+	tokenID, ok := claims["jti"].(string)
+	if !ok || tokenID == "" {
+		return true, nil
+	}
+
+	return s.sessionRepo.IsTokenDenied(tokenID)
+}
+
+// GetSessionIDFromToken extracts the session ID from a validated token
+func (s *AuthService) GetSessionIDFromToken(token *jwt.Token) (int64, error) {
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return 0, errors.New("invalid token claims")
+	}
+
+	sessionID, ok := claims["sid"].(float64)
+	if !ok {
+		return 0, errors.New("invalid sid in token")
+	}
+
+	return int64(sessionID), nil
+}
+
 // GetUserFromToken extracts the user from a validated token
 func (s *AuthService) GetUserFromToken(token *jwt.Token) (*models.User, error) {
 	claims, ok := token.Claims.(jwt.MapClaims)
@@ -135,3 +420,49 @@ func (s *AuthService) GetUserFromToken(token *jwt.Token) (*models.User, error) {
 
 	return s.userRepo.GetByID(int64(userID))
 }
+
+// requiresSSO reports whether an email belongs to a domain that must log in with single sign-on
+func (s *AuthService) requiresSSO(email string) bool {
+	return isSSOEmail(s.ssoDomains, email)
+}
+
+// isSSOEmail reports whether an email address belongs to one of the SSO domains
+func isSSOEmail(domains map[string]bool, email string) bool {
+	at := strings.LastIndex(email, "@")
+	return at >= 0 && domains[strings.ToLower(email[at+1:])]
+}
+
+// parseSSODomains parses a comma-separated list of email domains
+func parseSSODomains(value string) map[string]bool {
+	domains := make(map[string]bool)
+	for _, domain := range strings.Split(value, ",") {
+		if domain = strings.ToLower(strings.TrimSpace(domain)); domain != "" {
+			domains[domain] = true
+		}
+	}
+	return domains
+}
+
+// randomToken generates a random hex token from n random bytes
+func randomToken(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// hashToken hashes a refresh token for storage
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// truncate shortens s to at most n characters
+func truncate(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n])
+}
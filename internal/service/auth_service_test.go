@@ -0,0 +1,327 @@
+package service
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/mihaamiharu/test-case-management-be/internal/config"
+	"github.com/mihaamiharu/test-case-management-be/internal/models"
+	"github.com/mihaamiharu/test-case-management-be/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// fakeUserRepository keeps users in memory
+type fakeUserRepository struct {
+	users map[int64]*models.User
+}
+
+func (r *fakeUserRepository) Create(user *models.User) error {
+	user.ID = int64(len(r.users) + 1)
+	r.users[user.ID] = user
+	return nil
+}
+
+func (r *fakeUserRepository) GetByEmail(email string) (*models.User, error) {
+	for _, user := range r.users {
+		if user.Email == email {
+			return user, nil
+		}
+	}
+	return nil, repository.ErrUserNotFound
+}
+
+func (r *fakeUserRepository) GetByOIDCIdentity(issuer, subject string) (*models.User, error) {
+	for _, user := range r.users {
+		if user.OIDCIssuer != nil && *user.OIDCIssuer == issuer && user.OIDCSubject != nil && *user.OIDCSubject == subject {
+			return user, nil
+		}
+	}
+	return nil, repository.ErrUserNotFound
+}
+
+func (r *fakeUserRepository) GetByID(id int64) (*models.User, error) {
+	if user, ok := r.users[id]; ok {
+		return user, nil
+	}
+	return nil, repository.ErrUserNotFound
+}
+
+func (r *fakeUserRepository) GetByUsernames(usernames []string) ([]*models.User, error) {
+	return nil, nil
+}
+
+func (r *fakeUserRepository) List(filter *models.UserFilter) ([]*models.User, error) {
+	var users []*models.User
+	for id := int64(1); id <= int64(len(r.users)); id++ {
+		user, ok := r.users[id]
+		if !ok || (filter.Role != "" && user.Role != filter.Role) {
+			continue
+		}
+		if (filter.Status == models.UserStatusActive && user.IsDeactivated()) ||
+			(filter.Status == models.UserStatusDeactivated && !user.IsDeactivated()) {
+			continue
+		}
+		users = append(users, user)
+	}
+	return users, nil
+}
+
+func (r *fakeUserRepository) UpdateProfile(user *models.User) error {
+	for _, other := range r.users {
+		if other.ID != user.ID && other.Email == user.Email {
+			return repository.ErrUserExists
+		}
+	}
+	return r.update(user.ID, func(stored *models.User) {
+		stored.Username = user.Username
+		stored.Email = user.Email
+		stored.EmailVerifiedAt = user.EmailVerifiedAt
+	})
+}
+
+func (r *fakeUserRepository) UpdateRole(id int64, role models.Role) error {
+	return r.update(id, func(user *models.User) { user.Role = role })
+}
+
+func (r *fakeUserRepository) UpdatePassword(id int64, passwordHash string) error {
+	return r.update(id, func(user *models.User) {
+		user.PasswordHash = passwordHash
+		user.PasswordResetRequired = false
+	})
+}
+
+func (r *fakeUserRepository) SetDeactivated(id int64, deactivated bool) error {
+	return r.update(id, func(user *models.User) {
+		user.DeactivatedAt = nil
+		if deactivated {
+			now := time.Now()
+			user.DeactivatedAt = &now
+		}
+	})
+}
+
+func (r *fakeUserRepository) SetPasswordResetRequired(id int64, required bool) error {
+	return r.update(id, func(user *models.User) { user.PasswordResetRequired = required })
+}
+
+func (r *fakeUserRepository) SetEmailVerified(id int64) error {
+	return r.update(id, func(user *models.User) {
+		now := time.Now()
+		user.EmailVerifiedAt = &now
+	})
+}
+
+func (r *fakeUserRepository) SetOIDCIdentity(id int64, issuer, subject string) error {
+	return r.update(id, func(user *models.User) {
+		user.OIDCIssuer = &issuer
+		user.OIDCSubject = &subject
+	})
+}
+
+func (r *fakeUserRepository) update(id int64, apply func(user *models.User)) error {
+	user, ok := r.users[id]
+	if !ok {
+		return repository.ErrUserNotFound
+	}
+	apply(user)
+	return nil
+}
+
+// fakeSessionRepository keeps sessions, refresh tokens and the denylist in memory
+type fakeSessionRepository struct {
+	sessions map[int64]*models.Session
+	tokens   map[string]*models.RefreshToken
+	denied   map[string]bool
+}
+
+func newFakeSessionRepository() *fakeSessionRepository {
+	return &fakeSessionRepository{
+		sessions: map[int64]*models.Session{},
+		tokens:   map[string]*models.RefreshToken{},
+		denied:   map[string]bool{},
+	}
+}
+
+func (r *fakeSessionRepository) Create(session *models.Session, token *models.RefreshToken) error {
+	session.ID = int64(len(r.sessions) + 1)
+	r.sessions[session.ID] = session
+	token.ID = int64(len(r.tokens) + 1)
+	token.SessionID = session.ID
+	r.tokens[token.TokenHash] = token
+	return nil
+}
+
+func (r *fakeSessionRepository) GetByID(id int64) (*models.Session, error) {
+	if session, ok := r.sessions[id]; ok {
+		copied := *session
+		return &copied, nil
+	}
+	return nil, repository.ErrSessionNotFound
+}
+
+func (r *fakeSessionRepository) ListActiveByUser(userID int64) ([]*models.Session, error) {
+	var sessions []*models.Session
+	for _, session := range r.sessions {
+		if session.UserID == userID && session.RevokedAt == nil {
+			sessions = append(sessions, session)
+		}
+	}
+	return sessions, nil
+}
+
+func (r *fakeSessionRepository) GetRefreshToken(tokenHash string) (*models.RefreshToken, error) {
+	if token, ok := r.tokens[tokenHash]; ok {
+		copied := *token
+		return &copied, nil
+	}
+	return nil, repository.ErrRefreshTokenNotFound
+}
+
+func (r *fakeSessionRepository) RotateRefreshToken(usedTokenID int64, session *models.Session, token *models.RefreshToken, deniedUntil time.Time) error {
+	now := time.Now()
+	for _, used := range r.tokens {
+		if used.ID == usedTokenID {
+			if used.UsedAt != nil {
+				return repository.ErrRefreshTokenUsed
+			}
+			used.UsedAt = &now
+		}
+	}
+	token.ID = int64(len(r.tokens) + 1)
+	token.SessionID = session.ID
+	r.tokens[token.TokenHash] = token
+	r.denied[r.sessions[session.ID].AccessTokenID] = true
+	r.sessions[session.ID] = session
+	return nil
+}
+
+func (r *fakeSessionRepository) Revoke(id int64, deniedUntil time.Time) error {
+	session, ok := r.sessions[id]
+	if !ok || session.RevokedAt != nil {
+		return repository.ErrSessionNotFound
+	}
+	now := time.Now()
+	session.RevokedAt = &now
+	r.denied[session.AccessTokenID] = true
+	return nil
+}
+
+func (r *fakeSessionRepository) RevokeAllByUser(userID, exceptSessionID int64, deniedUntil time.Time) error {
+	now := time.Now()
+	for _, session := range r.sessions {
+		if session.UserID == userID && session.ID != exceptSessionID && session.RevokedAt == nil {
+			session.RevokedAt = &now
+			r.denied[session.AccessTokenID] = true
+		}
+	}
+	return nil
+}
+
+func (r *fakeSessionRepository) IsTokenDenied(tokenID string) (bool, error) {
+	return r.denied[tokenID], nil
+}
+
+func newTestAuthService(t *testing.T) (*AuthService, *fakeSessionRepository) {
+	hash, err := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.MinCost)
+	require.NoError(t, err)
+
+	users := &fakeUserRepository{users: map[int64]*models.User{
+		1: {ID: 1, Email: "alice@example.com", PasswordHash: string(hash), Role: models.RoleUser},
+	}}
+	sessions := newFakeSessionRepository()
+	cfg := &config.Config{JWTSecret: "secret", JWTAccessExpiry: 15 * time.Minute, RefreshTokenExpiry: time.Hour}
+
+	return NewAuthService(users, sessions, NewTwoFactorService(newFakeTwoFactorRepository(), users, cfg), cfg), sessions
+}
+
+// isRevoked validates an access token and reports whether it was revoked
+func isRevoked(t *testing.T, s *AuthService, accessToken string) bool {
+	token, err := s.ValidateToken(accessToken)
+	require.NoError(t, err)
+	revoked, err := s.IsTokenRevoked(token)
+	require.NoError(t, err)
+	return revoked
+}
+
+func TestAuthService_Refresh(t *testing.T) {
+	// Test case: refreshing rotates the refresh token and retires the old access token
+	t.Run("Rotation", func(t *testing.T) {
+		s, _ := newTestAuthService(t)
+
+		login, _, err := s.Login("alice@example.com", "password123", SessionClient{UserAgent: "test"})
+		require.NoError(t, err)
+
+		refreshed, err := s.Refresh(login.RefreshToken)
+		require.NoError(t, err)
+		assert.NotEqual(t, login.RefreshToken, refreshed.RefreshToken)
+		assert.True(t, isRevoked(t, s, login.AccessToken))
+		assert.False(t, isRevoked(t, s, refreshed.AccessToken))
+	})
+
+	// Test case: reusing a rotated refresh token revokes the session
+	t.Run("ReuseDetection", func(t *testing.T) {
+		s, sessions := newTestAuthService(t)
+
+		login, _, err := s.Login("alice@example.com", "password123", SessionClient{})
+		require.NoError(t, err)
+		refreshed, err := s.Refresh(login.RefreshToken)
+		require.NoError(t, err)
+
+		_, err = s.Refresh(login.RefreshToken)
+		assert.Equal(t, ErrRefreshTokenReused, err)
+		assert.NotNil(t, sessions.sessions[1].RevokedAt)
+		assert.True(t, isRevoked(t, s, refreshed.AccessToken))
+
+		_, err = s.Refresh(refreshed.RefreshToken)
+		assert.Equal(t, ErrInvalidRefreshToken, err)
+	})
+
+	// Test case: an unknown refresh token
+	t.Run("UnknownToken", func(t *testing.T) {
+		s, _ := newTestAuthService(t)
+
+		_, err := s.Refresh("unknown")
+		assert.Equal(t, ErrInvalidRefreshToken, err)
+	})
+}
+
+func TestAuthService_Logout(t *testing.T) {
+	s, _ := newTestAuthService(t)
+
+	login, _, err := s.Login("alice@example.com", "password123", SessionClient{})
+	require.NoError(t, err)
+	token, err := s.ValidateToken(login.AccessToken)
+	require.NoError(t, err)
+	sessionID, err := s.GetSessionIDFromToken(token)
+	require.NoError(t, err)
+
+	require.NoError(t, s.Logout(sessionID))
+	assert.True(t, isRevoked(t, s, login.AccessToken))
+
+	_, err = s.Refresh(login.RefreshToken)
+	assert.Equal(t, ErrInvalidRefreshToken, err)
+
+	// Test case: sessions of other users cannot be revoked
+	other, _, err := s.Login("alice@example.com", "password123", SessionClient{})
+	require.NoError(t, err)
+	token, err = s.ValidateToken(other.AccessToken)
+	require.NoError(t, err)
+	sessionID, err = s.GetSessionIDFromToken(token)
+	require.NoError(t, err)
+	assert.Equal(t, repository.ErrSessionNotFound, s.RevokeSession(2, sessionID))
+}
+
+func TestAuthService_RegisterIgnoresRole(t *testing.T) {
+	s, _ := newTestAuthService(t)
+
+	var userCreate models.UserCreate
+	require.NoError(t, json.Unmarshal([]byte(`{"username":"mallory","email":"mallory@example.com","password":"Sup3r-secret!","role":"admin"}`), &userCreate))
+
+	// Test case: a role in the registration request is ignored
+	user, err := s.Register(&userCreate)
+	require.NoError(t, err)
+	assert.Equal(t, models.RoleUser, user.Role)
+}
@@ -0,0 +1,286 @@
+package service
+
+import (
+	"errors"
+	"log"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/mihaamiharu/test-case-management-be/internal/events"
+	"github.com/mihaamiharu/test-case-management-be/internal/models"
+	"github.com/mihaamiharu/test-case-management-be/internal/repository"
+)
+
+var (
+	ErrNotCommentAuthor      = errors.New("only the author can change this comment")
+	ErrCommentParentMismatch = errors.New("parent comment belongs to a different test case")
+	ErrCommentStepMismatch   = errors.New("step belongs to a different test case")
+)
+
+// mentionPattern matches @username mentions that are not part of an email address
+var mentionPattern = regexp.MustCompile(`(?:^|[^A-Za-z0-9_.@])@([A-Za-z0-9_.-]{3,50})`)
+
+// CommentService handles comment and activity feed business logic
+type CommentService struct {
+	commentRepo  repository.CommentRepositoryInterface
+	testCaseRepo repository.TestCaseRepositoryInterface
+	userRepo     repository.UserRepositoryInterface
+	publisher    events.Publisher
+}
+
+// NewCommentService creates a new comment service
+func NewCommentService(
+	commentRepo repository.CommentRepositoryInterface,
+	testCaseRepo repository.TestCaseRepositoryInterface,
+	userRepo repository.UserRepositoryInterface,
+	publisher events.Publisher,
+) *CommentService {
+	return &CommentService{
+		commentRepo:  commentRepo,
+		testCaseRepo: testCaseRepo,
+		userRepo:     userRepo,
+		publisher:    publisher,
+	}
+}
+
+// CreateComment adds a comment or reply to a test case
+func (s *CommentService) CreateComment(comment *models.Comment) error {
+	testCase, err := s.testCaseRepo.GetByID(comment.TestCaseID)
+	if err != nil {
+		return err
+	}
+
+	if comment.StepID != nil {
+		step, err := s.testCaseRepo.GetStepByID(*comment.StepID)
+		if err != nil {
+			return err
+		}
+		if step.TestCaseID != comment.TestCaseID {
+			return ErrCommentStepMismatch
+		}
+	}
+
+	if comment.ParentID != nil {
+		parent, err := s.commentRepo.GetByID(*comment.ParentID)
+		if err != nil {
+			return err
+		}
+		if parent.TestCaseID != comment.TestCaseID {
+			return ErrCommentParentMismatch
+		}
+		// Replies stay attached to the step of the thread they answer
+		comment.StepID = parent.StepID
+	}
+
+	mentions, err := s.resolveMentions(comment.Body)
+	if err != nil {
+		return err
+	}
+	comment.Mentions = mentions
+
+	if err := s.commentRepo.Create(comment); err != nil {
+		return err
+	}
+
+	s.publisher.Publish(&events.Event{
+		Type:      events.CommentCreated,
+		ProjectID: testCase.ProjectID,
+		ActorID:   comment.CreatedBy,
+		Data:      comment.ToResponse(),
+	})
+	return nil
+}
+
+// UpdateComment edits the body of a comment; only its author may do so
+func (s *CommentService) UpdateComment(id int64, body string, userID int64) (*models.Comment, error) {
+	comment, err := s.commentRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if comment.CreatedBy != userID {
+		return nil, ErrNotCommentAuthor
+	}
+
+	mentions, err := s.resolveMentions(body)
+	if err != nil {
+		return nil, err
+	}
+	comment.Body = body
+	comment.Mentions = mentions
+
+	if err := s.commentRepo.Update(comment); err != nil {
+		return nil, err
+	}
+
+	s.publish(events.CommentUpdated, comment, userID)
+	return comment, nil
+}
+
+// DeleteComment deletes a comment and its replies; only its author may do so
+func (s *CommentService) DeleteComment(id int64, userID int64) error {
+	comment, err := s.commentRepo.GetByID(id)
+	if err != nil {
+		return err
+	}
+	if comment.CreatedBy != userID {
+		return ErrNotCommentAuthor
+	}
+
+	if err := s.commentRepo.Delete(id); err != nil {
+		return err
+	}
+
+	s.publish(events.CommentDeleted, comment, userID)
+	return nil
+}
+
+// SetCommentResolved marks a comment thread as resolved or reopens it
+func (s *CommentService) SetCommentResolved(id int64, resolved bool, userID int64) (*models.Comment, error) {
+	if err := s.commentRepo.SetResolved(id, resolved, userID); err != nil {
+		return nil, err
+	}
+
+	comment, err := s.commentRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	s.publish(events.CommentResolved, comment, userID)
+	return comment, nil
+}
+
+// publish announces a change to a comment to the subscribers of the project of its test case.
+// Failing to find the project only loses the event, as the change itself succeeded.
+func (s *CommentService) publish(eventType events.Type, comment *models.Comment, actorID int64) {
+	projectID, err := s.testCaseRepo.GetProjectID(comment.TestCaseID)
+	if err != nil {
+		log.Printf("failed to publish %s event for comment %d: %v", eventType, comment.ID, err)
+		return
+	}
+
+	s.publisher.Publish(&events.Event{
+		Type:      eventType,
+		ProjectID: projectID,
+		ActorID:   actorID,
+		Data:      comment.ToResponse(),
+	})
+}
+
+// GetComment retrieves a comment by ID
+func (s *CommentService) GetComment(id int64) (*models.Comment, error) {
+	return s.commentRepo.GetByID(id)
+}
+
+// ListCommentThreads retrieves the comments of a test case as threads of replies
+func (s *CommentService) ListCommentThreads(testCaseID int64) ([]*models.Comment, error) {
+	if _, err := s.testCaseRepo.GetByID(testCaseID); err != nil {
+		return nil, err
+	}
+
+	comments, err := s.commentRepo.ListByTestCase(testCaseID)
+	if err != nil {
+		return nil, err
+	}
+
+	return buildCommentThreads(comments), nil
+}
+
+// GetActivity retrieves the activity feed of a test case: its comments and
+// edit history merged into a single list, newest first
+func (s *CommentService) GetActivity(testCaseID int64) ([]*models.ActivityItem, error) {
+	if _, err := s.testCaseRepo.GetByID(testCaseID); err != nil {
+		return nil, err
+	}
+
+	comments, err := s.commentRepo.ListByTestCase(testCaseID)
+	if err != nil {
+		return nil, err
+	}
+
+	history, err := s.testCaseRepo.ListHistory(testCaseID)
+	if err != nil {
+		return nil, err
+	}
+
+	activity := make([]*models.ActivityItem, 0, len(comments)+len(history))
+	for _, comment := range comments {
+		activity = append(activity, &models.ActivityItem{
+			Type:       models.ActivityTypeComment,
+			UserID:     comment.CreatedBy,
+			OccurredAt: comment.CreatedAt,
+			Comment:    comment.ToResponse(),
+		})
+	}
+	for _, entry := range history {
+		activity = append(activity, &models.ActivityItem{
+			Type:       models.ActivityTypeEdit,
+			UserID:     entry.ChangedBy,
+			OccurredAt: entry.CreatedAt,
+			Edit:       entry,
+		})
+	}
+
+	sort.SliceStable(activity, func(i, j int) bool {
+		return activity[i].OccurredAt.After(activity[j].OccurredAt)
+	})
+
+	return activity, nil
+}
+
+// resolveMentions looks up the users mentioned in a comment body.
+// Mentions of unknown usernames are left as plain text.
+func (s *CommentService) resolveMentions(body string) ([]*models.UserSummary, error) {
+	usernames := ExtractMentions(body)
+	if len(usernames) == 0 {
+		return nil, nil
+	}
+
+	users, err := s.userRepo.GetByUsernames(usernames)
+	if err != nil {
+		return nil, err
+	}
+
+	mentions := make([]*models.UserSummary, len(users))
+	for i, user := range users {
+		mentions[i] = &models.UserSummary{ID: user.ID, Username: user.Username}
+	}
+
+	return mentions, nil
+}
+
+// ExtractMentions returns the distinct usernames mentioned with @ in a comment body
+func ExtractMentions(body string) []string {
+	var usernames []string
+	seen := make(map[string]bool)
+	for _, match := range mentionPattern.FindAllStringSubmatch(body, -1) {
+		// Punctuation that ends a sentence is not part of the username
+		username := strings.TrimRight(match[1], ".-")
+		if len(username) >= 3 && !seen[username] {
+			seen[username] = true
+			usernames = append(usernames, username)
+		}
+	}
+	return usernames
+}
+
+// buildCommentThreads nests replies under their parent comments
+func buildCommentThreads(comments []*models.Comment) []*models.Comment {
+	byID := make(map[int64]*models.Comment, len(comments))
+	for _, comment := range comments {
+		byID[comment.ID] = comment
+	}
+
+	var threads []*models.Comment
+	for _, comment := range comments {
+		if comment.ParentID != nil {
+			if parent, ok := byID[*comment.ParentID]; ok {
+				parent.Replies = append(parent.Replies, comment)
+				continue
+			}
+		}
+		threads = append(threads, comment)
+	}
+
+	return threads
+}
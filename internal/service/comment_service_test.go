@@ -0,0 +1,47 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/mihaamiharu/test-case-management-be/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExtractMentions(t *testing.T) {
+	body := "Thanks @alice, can @bob.smith take a look? Mail me at carol@example.com. cc @alice and @ab."
+
+	assert.Equal(t, []string{"alice", "bob.smith"}, ExtractMentions(body))
+}
+
+func TestBuildCommentThreads(t *testing.T) {
+	rootID := int64(1)
+	comments := []*models.Comment{
+		{ID: 1, Body: "Should this step wait for the page to load?"},
+		{ID: 2, Body: "Unrelated question"},
+		{ID: 3, ParentID: &rootID, Body: "Yes, added a wait"},
+	}
+
+	threads := buildCommentThreads(comments)
+
+	assert.Len(t, threads, 2)
+	assert.Equal(t, int64(1), threads[0].ID)
+	assert.Len(t, threads[0].Replies, 1)
+	assert.Equal(t, int64(3), threads[0].Replies[0].ID)
+	assert.Empty(t, threads[1].Replies)
+}
+
+func TestSummarizeChanges(t *testing.T) {
+	before := &models.TestCase{
+		Title:  "Login",
+		Status: models.StatusDraft,
+		Steps:  []*models.TestStep{{ID: 1, StepType: models.StepTypeGiven, Description: "the login page"}},
+	}
+	after := &models.TestCase{
+		Title:  "Login with valid credentials",
+		Status: models.StatusActive,
+		Steps:  []*models.TestStep{{ID: 1, StepType: models.StepTypeGiven, Description: "the login page"}},
+	}
+
+	assert.Equal(t, "Updated title, status", summarizeChanges(before, after))
+	assert.Empty(t, summarizeChanges(before, before))
+}
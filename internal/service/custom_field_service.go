@@ -0,0 +1,422 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mihaamiharu/test-case-management-be/internal/models"
+	"github.com/mihaamiharu/test-case-management-be/internal/repository"
+)
+
+var (
+	ErrInvalidCustomFieldName    = errors.New("custom field name must start with a letter and contain only lowercase letters, digits and underscores")
+	ErrCustomFieldOptionsMissing = errors.New("enum and multi_select custom fields need at least one option")
+	ErrUnknownCustomField        = errors.New("unknown custom field")
+	ErrInvalidCustomFieldValue   = errors.New("invalid custom field value")
+	ErrCustomFieldRequired       = errors.New("custom field is required")
+	ErrInvalidSort               = errors.New("invalid sort field")
+)
+
+// customFieldNamePattern restricts custom field names to keys usable in query strings and CSV headers
+var customFieldNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// customFieldDateLayout is the format of date custom field values
+const customFieldDateLayout = "2006-01-02"
+
+// CustomFieldService handles custom field definition business logic
+type CustomFieldService struct {
+	customFieldRepo repository.CustomFieldRepositoryInterface
+}
+
+// NewCustomFieldService creates a new custom field service
+func NewCustomFieldService(customFieldRepo repository.CustomFieldRepositoryInterface) *CustomFieldService {
+	return &CustomFieldService{
+		customFieldRepo: customFieldRepo,
+	}
+}
+
+// CreateDefinition defines a new custom field for a project
+func (s *CustomFieldService) CreateDefinition(definition *models.CustomFieldDefinition) error {
+	if !customFieldNamePattern.MatchString(definition.Name) {
+		return ErrInvalidCustomFieldName
+	}
+	if err := checkOptions(definition); err != nil {
+		return err
+	}
+
+	return s.customFieldRepo.CreateDefinition(definition)
+}
+
+// GetDefinition retrieves a custom field by ID
+func (s *CustomFieldService) GetDefinition(id int64) (*models.CustomFieldDefinition, error) {
+	return s.customFieldRepo.GetDefinition(id)
+}
+
+// UpdateDefinition updates a custom field
+func (s *CustomFieldService) UpdateDefinition(id int64, update *models.CustomFieldDefinitionUpdate) (*models.CustomFieldDefinition, error) {
+	definition, err := s.customFieldRepo.GetDefinition(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if update.Label != "" {
+		definition.Label = update.Label
+	}
+	if update.Options != nil {
+		definition.Options = update.Options
+	}
+	if update.Required != nil {
+		definition.Required = *update.Required
+	}
+	if update.Position != nil {
+		definition.Position = *update.Position
+	}
+
+	if err := checkOptions(definition); err != nil {
+		return nil, err
+	}
+
+	if err := s.customFieldRepo.UpdateDefinition(definition); err != nil {
+		return nil, err
+	}
+
+	return definition, nil
+}
+
+// DeleteDefinition deletes a custom field and its values
+func (s *CustomFieldService) DeleteDefinition(id int64) error {
+	return s.customFieldRepo.DeleteDefinition(id)
+}
+
+// ListDefinitions retrieves the custom fields of a project
+func (s *CustomFieldService) ListDefinitions(projectID int64) ([]*models.CustomFieldDefinition, error) {
+	return s.customFieldRepo.ListDefinitions(projectID)
+}
+
+// checkOptions verifies that choice fields have options and other fields have none
+func checkOptions(definition *models.CustomFieldDefinition) error {
+	switch definition.Type {
+	case models.CustomFieldEnum, models.CustomFieldMultiSelect:
+		if len(definition.Options) == 0 {
+			return ErrCustomFieldOptionsMissing
+		}
+	default:
+		definition.Options = nil
+	}
+	return nil
+}
+
+// NormalizeCustomFieldValue validates a value for a custom field and converts it to its
+// stored form. Values may be typed JSON values or strings, as read from a CSV file;
+// multi_select strings separate options with semicolons. An empty value clears the field.
+func NormalizeCustomFieldValue(definition *models.CustomFieldDefinition, value interface{}) (interface{}, error) {
+	if value == nil {
+		return nil, nil
+	}
+	if text, ok := value.(string); ok && strings.TrimSpace(text) == "" && definition.Type != models.CustomFieldText {
+		return nil, nil
+	}
+
+	invalid := func(reason string) error {
+		return fmt.Errorf("%w: %s %s", ErrInvalidCustomFieldValue, definition.Name, reason)
+	}
+
+	switch definition.Type {
+	case models.CustomFieldText:
+		text, ok := value.(string)
+		if !ok {
+			return nil, invalid("must be a string")
+		}
+		if text == "" {
+			return nil, nil
+		}
+		if len(text) > 1000 {
+			return nil, invalid("must be at most 1000 characters")
+		}
+		return text, nil
+
+	case models.CustomFieldNumber:
+		switch v := value.(type) {
+		case float64:
+			return v, nil
+		case int:
+			return float64(v), nil
+		case int64:
+			return float64(v), nil
+		case string:
+			number, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+			if err != nil {
+				return nil, invalid("must be a number")
+			}
+			return number, nil
+		}
+		return nil, invalid("must be a number")
+
+	case models.CustomFieldEnum:
+		option, ok := value.(string)
+		if !ok || !containsOption(definition.Options, option) {
+			return nil, invalid("must be one of " + strings.Join(definition.Options, ", "))
+		}
+		return option, nil
+
+	case models.CustomFieldMultiSelect:
+		var selected []string
+		switch v := value.(type) {
+		case []string:
+			selected = v
+		case []interface{}:
+			for _, item := range v {
+				option, ok := item.(string)
+				if !ok {
+					return nil, invalid("must be a list of options")
+				}
+				selected = append(selected, option)
+			}
+		case string:
+			for _, option := range strings.Split(v, ";") {
+				if option = strings.TrimSpace(option); option != "" {
+					selected = append(selected, option)
+				}
+			}
+		default:
+			return nil, invalid("must be a list of options")
+		}
+		if len(selected) == 0 {
+			return nil, nil
+		}
+		for _, option := range selected {
+			if !containsOption(definition.Options, option) {
+				return nil, invalid("options must be among " + strings.Join(definition.Options, ", "))
+			}
+		}
+		return selected, nil
+
+	case models.CustomFieldUser:
+		var userID float64
+		switch v := value.(type) {
+		case float64:
+			userID = v
+		case int64:
+			userID = float64(v)
+		case int:
+			userID = float64(v)
+		case string:
+			parsed, err := strconv.ParseInt(strings.TrimSpace(v), 10, 64)
+			if err != nil {
+				return nil, invalid("must be a user ID")
+			}
+			userID = float64(parsed)
+		default:
+			return nil, invalid("must be a user ID")
+		}
+		if userID <= 0 || userID != math.Trunc(userID) {
+			return nil, invalid("must be a user ID")
+		}
+		return int64(userID), nil
+
+	case models.CustomFieldDate:
+		date, ok := value.(string)
+		if !ok {
+			return nil, invalid("must be a date in YYYY-MM-DD format")
+		}
+		if _, err := time.Parse(customFieldDateLayout, strings.TrimSpace(date)); err != nil {
+			return nil, invalid("must be a date in YYYY-MM-DD format")
+		}
+		return strings.TrimSpace(date), nil
+
+	case models.CustomFieldBoolean:
+		switch v := value.(type) {
+		case bool:
+			return v, nil
+		case string:
+			switch strings.ToLower(strings.TrimSpace(v)) {
+			case "true", "yes", "1":
+				return true, nil
+			case "false", "no", "0":
+				return false, nil
+			}
+		}
+		return nil, invalid("must be true or false")
+	}
+
+	return nil, invalid("has an unsupported type")
+}
+
+// FormatCustomFieldValue renders a stored custom field value as text, as written to CSV files
+func FormatCustomFieldValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case bool:
+		return strconv.FormatBool(v)
+	case []string:
+		return strings.Join(v, ";")
+	case []interface{}:
+		parts := make([]string, len(v))
+		for i, item := range v {
+			parts[i] = FormatCustomFieldValue(item)
+		}
+		return strings.Join(parts, ";")
+	}
+	return fmt.Sprint(value)
+}
+
+// FilterAndSortTestCases applies custom field filters and sorting to a list of test cases.
+// Multi-select fields match when the filter value is one of the selected options.
+// Sort accepts title, priority, status, created_at, updated_at or cf.<name>, prefixed
+// with - for descending order. Statuses and priorities sort in their workflow order.
+func FilterAndSortTestCases(testCases []*models.TestCase, definitions []*models.CustomFieldDefinition, workflow *models.Workflow, options *models.TestCaseListOptions) ([]*models.TestCase, error) {
+	byName := make(map[string]*models.CustomFieldDefinition, len(definitions))
+	for _, definition := range definitions {
+		byName[definition.Name] = definition
+	}
+
+	// Normalize the filter values once so that "5" matches 5 and "yes" matches true
+	filters := make(map[string]string, len(options.CustomFieldFilters))
+	for name, raw := range options.CustomFieldFilters {
+		definition, ok := byName[name]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownCustomField, name)
+		}
+		if definition.Type == models.CustomFieldMultiSelect {
+			filters[name] = raw
+			continue
+		}
+		value, err := NormalizeCustomFieldValue(definition, raw)
+		if err != nil {
+			return nil, err
+		}
+		filters[name] = FormatCustomFieldValue(value)
+	}
+
+	filtered := make([]*models.TestCase, 0, len(testCases))
+	for _, testCase := range testCases {
+		if matchesFilters(testCase, byName, filters) {
+			filtered = append(filtered, testCase)
+		}
+	}
+
+	if options.Sort == "" {
+		return filtered, nil
+	}
+
+	less, err := testCaseComparator(options.Sort, byName, workflow)
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(filtered, func(i, j int) bool {
+		return less(filtered[i], filtered[j])
+	})
+
+	return filtered, nil
+}
+
+// matchesFilters reports whether a test case has the filtered custom field values
+func matchesFilters(testCase *models.TestCase, byName map[string]*models.CustomFieldDefinition, filters map[string]string) bool {
+	for name, want := range filters {
+		value := testCase.CustomFields[name]
+		if byName[name].Type == models.CustomFieldMultiSelect {
+			if !strings.Contains(";"+FormatCustomFieldValue(value)+";", ";"+want+";") {
+				return false
+			}
+			continue
+		}
+		if FormatCustomFieldValue(value) != want {
+			return false
+		}
+	}
+	return true
+}
+
+// testCaseComparator builds the ordering function for a sort expression.
+// Test cases without a value for the sorted custom field always come last.
+func testCaseComparator(sortBy string, byName map[string]*models.CustomFieldDefinition, workflow *models.Workflow) (func(a, b *models.TestCase) bool, error) {
+	descending := strings.HasPrefix(sortBy, "-")
+	field := strings.TrimPrefix(sortBy, "-")
+
+	var (
+		compare func(a, b *models.TestCase) int
+		missing func(tc *models.TestCase) bool
+	)
+	switch field {
+	case "title":
+		compare = func(a, b *models.TestCase) int {
+			return strings.Compare(strings.ToLower(a.Title), strings.ToLower(b.Title))
+		}
+	case "status":
+		rank := make(map[models.TestCaseStatus]int, len(workflow.Statuses))
+		for i, status := range workflow.Statuses {
+			rank[models.TestCaseStatus(status.Name)] = i
+		}
+		compare = func(a, b *models.TestCase) int { return rank[a.Status] - rank[b.Status] }
+	case "priority":
+		rank := make(map[models.TestCasePriority]int, len(workflow.Priorities))
+		for i, priority := range workflow.Priorities {
+			rank[models.TestCasePriority(priority.Name)] = i
+		}
+		compare = func(a, b *models.TestCase) int { return rank[a.Priority] - rank[b.Priority] }
+	case "created_at":
+		compare = func(a, b *models.TestCase) int { return a.CreatedAt.Compare(b.CreatedAt) }
+	case "updated_at":
+		compare = func(a, b *models.TestCase) int { return a.UpdatedAt.Compare(b.UpdatedAt) }
+	default:
+		name, ok := strings.CutPrefix(field, "cf.")
+		definition := byName[name]
+		if !ok || definition == nil {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidSort, field)
+		}
+		compare = func(a, b *models.TestCase) int {
+			return compareCustomFieldValues(definition, a.CustomFields[name], b.CustomFields[name])
+		}
+		missing = func(tc *models.TestCase) bool { return tc.CustomFields[name] == nil }
+	}
+
+	return func(a, b *models.TestCase) bool {
+		if missing != nil && (missing(a) || missing(b)) {
+			return !missing(a)
+		}
+		if descending {
+			return compare(a, b) > 0
+		}
+		return compare(a, b) < 0
+	}, nil
+}
+
+// compareCustomFieldValues orders two present values of a custom field
+func compareCustomFieldValues(definition *models.CustomFieldDefinition, a, b interface{}) int {
+	if definition.Type == models.CustomFieldNumber {
+		x, _ := a.(float64)
+		y, _ := b.(float64)
+		switch {
+		case x < y:
+			return -1
+		case x > y:
+			return 1
+		}
+		return 0
+	}
+
+	return strings.Compare(FormatCustomFieldValue(a), FormatCustomFieldValue(b))
+}
+
+// containsOption reports whether an option is allowed
+func containsOption(options []string, option string) bool {
+	for _, allowed := range options {
+		if allowed == option {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,150 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mihaamiharu/test-case-management-be/internal/models"
+	"github.com/mihaamiharu/test-case-management-be/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeCustomFieldRepository serves custom field definitions from memory
+type fakeCustomFieldRepository struct {
+	repository.CustomFieldRepositoryInterface
+	definitions []*models.CustomFieldDefinition
+}
+
+func (r *fakeCustomFieldRepository) ListDefinitions(projectID int64) ([]*models.CustomFieldDefinition, error) {
+	var definitions []*models.CustomFieldDefinition
+	for _, definition := range r.definitions {
+		if definition.ProjectID == projectID {
+			definitions = append(definitions, definition)
+		}
+	}
+	return definitions, nil
+}
+
+func TestNormalizeCustomFieldValue(t *testing.T) {
+	t.Run("NumberFromString", func(t *testing.T) {
+		definition := &models.CustomFieldDefinition{Name: "estimate", Type: models.CustomFieldNumber}
+		value, err := NormalizeCustomFieldValue(definition, "2.5")
+		assert.NoError(t, err)
+		assert.Equal(t, 2.5, value)
+	})
+
+	t.Run("EnumOutsideOptions", func(t *testing.T) {
+		definition := &models.CustomFieldDefinition{Name: "component", Type: models.CustomFieldEnum, Options: []string{"api", "ui"}}
+		_, err := NormalizeCustomFieldValue(definition, "db")
+		assert.True(t, errors.Is(err, ErrInvalidCustomFieldValue))
+	})
+
+	t.Run("MultiSelectFromCSV", func(t *testing.T) {
+		definition := &models.CustomFieldDefinition{Name: "platforms", Type: models.CustomFieldMultiSelect, Options: []string{"ios", "android", "web"}}
+		value, err := NormalizeCustomFieldValue(definition, "ios; web")
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"ios", "web"}, value)
+	})
+
+	t.Run("UserFromJSONNumber", func(t *testing.T) {
+		definition := &models.CustomFieldDefinition{Name: "owner", Type: models.CustomFieldUser}
+		value, err := NormalizeCustomFieldValue(definition, float64(7))
+		assert.NoError(t, err)
+		assert.Equal(t, int64(7), value)
+	})
+
+	t.Run("InvalidDate", func(t *testing.T) {
+		definition := &models.CustomFieldDefinition{Name: "due", Type: models.CustomFieldDate}
+		_, err := NormalizeCustomFieldValue(definition, "18/10/2026")
+		assert.True(t, errors.Is(err, ErrInvalidCustomFieldValue))
+	})
+
+	t.Run("EmptyClearsValue", func(t *testing.T) {
+		definition := &models.CustomFieldDefinition{Name: "automated", Type: models.CustomFieldBoolean}
+		value, err := NormalizeCustomFieldValue(definition, "")
+		assert.NoError(t, err)
+		assert.Nil(t, value)
+	})
+}
+
+func TestFilterAndSortTestCases(t *testing.T) {
+	definitions := []*models.CustomFieldDefinition{
+		{ID: 1, Name: "estimate", Type: models.CustomFieldNumber},
+		{ID: 2, Name: "platforms", Type: models.CustomFieldMultiSelect, Options: []string{"ios", "web"}},
+	}
+	testCases := []*models.TestCase{
+		{ID: 1, Title: "A", CustomFields: map[string]interface{}{"estimate": 3.0, "platforms": []interface{}{"ios", "web"}}},
+		{ID: 2, Title: "B", CustomFields: map[string]interface{}{"estimate": 1.0, "platforms": []interface{}{"web"}}},
+		{ID: 3, Title: "C", CustomFields: map[string]interface{}{"platforms": []interface{}{"ios"}}},
+	}
+
+	t.Run("MultiSelectContains", func(t *testing.T) {
+		options := &models.TestCaseListOptions{CustomFieldFilters: map[string]string{"platforms": "web"}}
+		result, err := FilterAndSortTestCases(testCases, definitions, models.DefaultWorkflow(1), options)
+		assert.NoError(t, err)
+		assert.Len(t, result, 2)
+	})
+
+	t.Run("SortByCustomFieldDescending", func(t *testing.T) {
+		options := &models.TestCaseListOptions{Sort: "-cf.estimate"}
+		result, err := FilterAndSortTestCases(testCases, definitions, models.DefaultWorkflow(1), options)
+		assert.NoError(t, err)
+		// Test case: missing values sort last in either direction
+		assert.Equal(t, []int64{1, 2, 3}, []int64{result[0].ID, result[1].ID, result[2].ID})
+	})
+
+	t.Run("UnknownField", func(t *testing.T) {
+		options := &models.TestCaseListOptions{CustomFieldFilters: map[string]string{"missing": "x"}}
+		_, err := FilterAndSortTestCases(testCases, definitions, models.DefaultWorkflow(1), options)
+		assert.True(t, errors.Is(err, ErrUnknownCustomField))
+	})
+
+	t.Run("InvalidSort", func(t *testing.T) {
+		_, err := FilterAndSortTestCases(testCases, definitions, models.DefaultWorkflow(1), &models.TestCaseListOptions{Sort: "owner"})
+		assert.True(t, errors.Is(err, ErrInvalidSort))
+	})
+}
+
+func TestParseCSVSteps(t *testing.T) {
+	steps, err := parseCSVSteps("given|a user|\nwhen|shared:4\nthen|a result|is shown")
+	assert.NoError(t, err)
+	assert.Len(t, steps, 3)
+	assert.Equal(t, int64(4), *steps[1].SharedStepID)
+	assert.Equal(t, "is shown", steps[2].ExpectedResult)
+
+	_, err = parseCSVSteps("later|something")
+	assert.Error(t, err)
+}
+
+func TestTestCaseService_ResolveCustomFieldUsers(t *testing.T) {
+	deactivatedAt := time.Now()
+	s := NewTestCaseService(nil, nil, nil, nil,
+		&fakeCustomFieldRepository{definitions: []*models.CustomFieldDefinition{
+			{ID: 3, ProjectID: 1, Name: "owner", Type: models.CustomFieldUser},
+		}},
+		&fakeUserRepository{users: map[int64]*models.User{
+			1: {ID: 1, Username: "alice"},
+			2: {ID: 2, Username: "bob", DeactivatedAt: &deactivatedAt},
+			3: {ID: 3, Username: "carol"},
+		}},
+		&fakeProjectRepository{projects: map[int64]*models.Project{1: {ID: 1, OrganizationID: 5}}},
+		&fakeOrganizationRepository{members: []*models.OrganizationMember{
+			{OrganizationID: 5, UserID: 1},
+			{OrganizationID: 5, UserID: 2},
+			{OrganizationID: 6, UserID: 3},
+		}},
+		nil, nil)
+
+	// Test case: an active member of the organization of the project is stored
+	values, err := s.resolveCustomFields(&models.TestCase{ProjectID: 1, CustomFields: map[string]interface{}{"owner": float64(1)}})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), values[3])
+
+	// Test case: unknown, deactivated and foreign users are rejected
+	for _, userID := range []float64{9, 2, 3} {
+		_, err := s.resolveCustomFields(&models.TestCase{ProjectID: 1, CustomFields: map[string]interface{}{"owner": userID}})
+		assert.True(t, errors.Is(err, ErrInvalidCustomFieldValue), "user %v", userID)
+	}
+}
@@ -0,0 +1,79 @@
+package service
+
+import (
+	"github.com/mihaamiharu/test-case-management-be/internal/events"
+	"github.com/mihaamiharu/test-case-management-be/internal/models"
+	"github.com/mihaamiharu/test-case-management-be/internal/repository"
+)
+
+// DefectService handles defects found by test executions
+type DefectService struct {
+	defectRepo    repository.DefectRepositoryInterface
+	executionRepo repository.TestExecutionRepositoryInterface
+	publisher     events.Publisher
+}
+
+// NewDefectService creates a new defect service
+func NewDefectService(
+	defectRepo repository.DefectRepositoryInterface,
+	executionRepo repository.TestExecutionRepositoryInterface,
+	publisher events.Publisher,
+) *DefectService {
+	return &DefectService{
+		defectRepo:    defectRepo,
+		executionRepo: executionRepo,
+		publisher:     publisher,
+	}
+}
+
+// CreateDefect records an open defect found by an execution on behalf of a user
+func (s *DefectService) CreateDefect(executionID int64, defectCreate *models.DefectCreate, userID int64) (*models.Defect, error) {
+	if _, err := s.executionRepo.GetByID(executionID); err != nil {
+		return nil, err
+	}
+
+	severity := defectCreate.Severity
+	if severity == "" {
+		severity = models.DefectSeverityMedium
+	}
+	defect := &models.Defect{
+		TestExecutionID: executionID,
+		Title:           defectCreate.Title,
+		Description:     defectCreate.Description,
+		Severity:        severity,
+		Status:          models.DefectStatusOpen,
+		ReportedBy:      userID,
+		AssignedTo:      defectCreate.AssignedTo,
+	}
+	if err := s.defectRepo.Create(defect); err != nil {
+		return nil, err
+	}
+
+	// Reload the defect for the run, test case and project of its execution
+	defect, err := s.defectRepo.GetByID(defect.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.publisher.Publish(&events.Event{
+		Type:      events.DefectOpened,
+		ProjectID: defect.ProjectID,
+		TestRunID: defect.TestRunID,
+		ActorID:   userID,
+		Data:      defect,
+	})
+	return defect, nil
+}
+
+// GetDefect retrieves a defect by ID
+func (s *DefectService) GetDefect(id int64) (*models.Defect, error) {
+	return s.defectRepo.GetByID(id)
+}
+
+// ListDefectsByExecution retrieves the defects found by an execution
+func (s *DefectService) ListDefectsByExecution(executionID int64) ([]*models.Defect, error) {
+	if _, err := s.executionRepo.GetByID(executionID); err != nil {
+		return nil, err
+	}
+	return s.defectRepo.ListByExecution(executionID)
+}
@@ -0,0 +1,92 @@
+package service
+
+import (
+	"strings"
+
+	"github.com/mihaamiharu/test-case-management-be/internal/models"
+)
+
+// recordHistory stores the current state of a test case as a new history version
+func (s *TestCaseService) recordHistory(testCase *models.TestCase, changedBy int64, summary string) error {
+	return s.testCaseRepo.CreateHistory(&models.TestCaseHistory{
+		TestCaseID:    testCase.ID,
+		Title:         testCase.Title,
+		Description:   testCase.Description,
+		Preconditions: testCase.Preconditions,
+		Status:        testCase.Status,
+		Priority:      testCase.Priority,
+		Version:       testCase.Version,
+		ChangedBy:     changedBy,
+		ChangeSummary: summary,
+	})
+}
+
+// summarizeChanges describes which fields of a test case differ between two versions, and
+// is empty when none do
+func summarizeChanges(before, after *models.TestCase) string {
+	changed := changedFields(before, after)
+	if len(changed) == 0 {
+		return ""
+	}
+	return "Updated " + strings.Join(changed, ", ")
+}
+
+// contentChanged reports whether anything other than the status differs
+func contentChanged(before, after *models.TestCase) bool {
+	for _, field := range changedFields(before, after) {
+		if field != "status" {
+			return true
+		}
+	}
+	return false
+}
+
+// changedFields lists the fields of a test case that differ between two versions
+func changedFields(before, after *models.TestCase) []string {
+	var changed []string
+	if before.Title != after.Title {
+		changed = append(changed, "title")
+	}
+	if before.Description != after.Description {
+		changed = append(changed, "description")
+	}
+	if before.Preconditions != after.Preconditions {
+		changed = append(changed, "preconditions")
+	}
+	if before.Status != after.Status {
+		changed = append(changed, "status")
+	}
+	if before.Priority != after.Priority {
+		changed = append(changed, "priority")
+	}
+	if before.SuiteID != after.SuiteID {
+		changed = append(changed, "suite")
+	}
+	if stepsChanged(before.Steps, after.Steps) {
+		changed = append(changed, "steps")
+	}
+	return changed
+}
+
+// stepsChanged reports whether the content or order of the steps differs
+func stepsChanged(before, after []*models.TestStep) bool {
+	if len(before) != len(after) {
+		return true
+	}
+	for i := range before {
+		b, a := before[i], after[i]
+		if b.ID != a.ID || b.StepType != a.StepType || b.Description != a.Description ||
+			b.ExpectedResult != a.ExpectedResult || !sameID(b.SharedStepID, a.SharedStepID) {
+			return true
+		}
+	}
+	return false
+}
+
+// sameID compares two optional IDs
+func sameID(a, b *int64) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
@@ -0,0 +1,315 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/mihaamiharu/test-case-management-be/internal/config"
+	"github.com/mihaamiharu/test-case-management-be/internal/issuetracker"
+	"github.com/mihaamiharu/test-case-management-be/internal/models"
+	"github.com/mihaamiharu/test-case-management-be/internal/repository"
+)
+
+var (
+	ErrIssueTrackerTokenRequired  = errors.New("an API token is required to connect an issue tracker")
+	ErrInvalidIssueTracker        = errors.New("invalid issue tracker")
+	ErrIssueTrackerRequest        = errors.New("issue tracker request failed")
+	ErrIssueTrackerHostNotAllowed = errors.New("issue tracker host resolves to an address outside the public internet that is not allowed")
+)
+
+// IssueTrackerService connects projects to external issue trackers, reports defects to
+// them and keeps the status of defects in step with their issues. Issue statuses are
+// pulled by the sync job and pushed by the webhooks of the trackers.
+type IssueTrackerService struct {
+	trackerRepo repository.IssueTrackerRepositoryInterface
+	defectRepo  repository.DefectRepositoryInterface
+	client      *http.Client
+}
+
+// NewIssueTrackerService creates a new issue tracker service
+func NewIssueTrackerService(
+	trackerRepo repository.IssueTrackerRepositoryInterface,
+	defectRepo repository.DefectRepositoryInterface,
+	cfg *config.Config,
+) *IssueTrackerService {
+	return &IssueTrackerService{
+		trackerRepo: trackerRepo,
+		defectRepo:  defectRepo,
+		client:      newIssueTrackerClient(cfg.IssueTrackers),
+	}
+}
+
+// newIssueTrackerClient creates the client tracker requests are sent with. It only connects
+// to public addresses and the allowed networks of the configuration.
+func newIssueTrackerClient(cfg config.IssueTrackersConfig) *http.Client {
+	var allowed []*net.IPNet
+	for _, entry := range cfg.AllowedNetworks {
+		if !strings.Contains(entry, "/") {
+			if ip := net.ParseIP(entry); ip != nil && ip.To4() != nil {
+				entry += "/32"
+			} else {
+				entry += "/128"
+			}
+		}
+		_, network, err := net.ParseCIDR(entry)
+		if err != nil {
+			log.Printf("ignoring invalid issue tracker network %q: %v", entry, err)
+			continue
+		}
+		allowed = append(allowed, network)
+	}
+
+	allowIP := func(ip net.IP) bool {
+		if publicIP(ip) {
+			return true
+		}
+		for _, network := range allowed {
+			if network.Contains(ip) {
+				return true
+			}
+		}
+		return false
+	}
+	return newOutboundClient(cfg.Timeout, allowIP, ErrIssueTrackerHostNotAllowed)
+}
+
+// GetTracker retrieves the issue tracker of a project
+func (s *IssueTrackerService) GetTracker(projectID int64) (*models.IssueTracker, error) {
+	return s.trackerRepo.Get(projectID)
+}
+
+// ConfigureTracker connects a project to an issue tracker, replacing the one it had. The
+// token and webhook secret of the current tracker are kept when none are given, but only
+// while the provider, base URL and username stay the same, so that saved credentials are
+// never sent to a different server or account.
+func (s *IssueTrackerService) ConfigureTracker(projectID int64, trackerConfig *models.IssueTrackerConfig, userID int64) (*models.IssueTracker, error) {
+	tracker := &models.IssueTracker{
+		ProjectID:     projectID,
+		Provider:      trackerConfig.Provider,
+		BaseURL:       trackerConfig.BaseURL,
+		ProjectKey:    trackerConfig.ProjectKey,
+		Username:      trackerConfig.Username,
+		Token:         trackerConfig.Token,
+		WebhookSecret: trackerConfig.WebhookSecret,
+		CreatedBy:     &userID,
+	}
+
+	current, err := s.trackerRepo.Get(projectID)
+	if err != nil && err != repository.ErrIssueTrackerNotFound {
+		return nil, err
+	}
+	if current != nil {
+		tracker.CreatedBy = current.CreatedBy
+		tracker.CreatedAt = current.CreatedAt
+	}
+	if current != nil && sameTrackerAccount(current, tracker) {
+		if tracker.Token == "" {
+			tracker.Token = current.Token
+		}
+		if tracker.WebhookSecret == "" {
+			tracker.WebhookSecret = current.WebhookSecret
+		}
+	}
+	if tracker.Token == "" {
+		return nil, ErrIssueTrackerTokenRequired
+	}
+	if tracker.BaseURL != "" && !validHTTPURL(tracker.BaseURL) {
+		return nil, fmt.Errorf("%w: the base URL must be an http or https URL", ErrInvalidIssueTracker)
+	}
+
+	if _, err := issuetracker.New(tracker, s.client); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidIssueTracker, err)
+	}
+	if err := s.trackerRepo.Save(tracker); err != nil {
+		return nil, err
+	}
+	return tracker, nil
+}
+
+// DeleteTracker disconnects a project from its issue tracker. Defects keep the issues they
+// were reported as, but are no longer synced.
+func (s *IssueTrackerService) DeleteTracker(projectID int64) error {
+	return s.trackerRepo.Delete(projectID)
+}
+
+// CreateExternalIssue reports a defect to the issue tracker of its project and links the
+// defect to the new issue
+func (s *IssueTrackerService) CreateExternalIssue(ctx context.Context, defectID int64) (*models.Defect, error) {
+	defect, err := s.defectRepo.GetByID(defectID)
+	if err != nil {
+		return nil, err
+	}
+	if defect.ExternalID != nil {
+		return nil, repository.ErrDefectAlreadyLinked
+	}
+
+	tracker, err := s.tracker(defect.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+	issue, err := tracker.CreateIssue(ctx, defect)
+	if err != nil {
+		return nil, requestError(defect.ProjectID, err)
+	}
+
+	if err := s.defectRepo.LinkExternalIssue(defect.ID, issue.Key, issue.URL, issue.Status); err != nil {
+		// A concurrent request linked the defect first; the issue created here is left over
+		// in the tracker
+		if err == repository.ErrDefectAlreadyLinked {
+			log.Printf("defect %d was linked while issue %s was created", defect.ID, issue.Key)
+		}
+		return nil, err
+	}
+	return s.defectRepo.GetByID(defect.ID)
+}
+
+// RunSyncJob syncs the linked defects of every project at every interval until the
+// context is done
+func (s *IssueTrackerService) RunSyncJob(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.SyncAll(ctx); err != nil {
+				log.Printf("failed to sync issue trackers: %v", err)
+			}
+		}
+	}
+}
+
+// SyncAll syncs the linked defects of every project with an issue tracker. A tracker that
+// cannot be reached does not stop the others from being synced.
+func (s *IssueTrackerService) SyncAll(ctx context.Context) error {
+	trackers, err := s.trackerRepo.List()
+	if err != nil {
+		return err
+	}
+
+	for _, cfg := range trackers {
+		if ctx.Err() != nil {
+			return nil
+		}
+		tracker, err := issuetracker.New(cfg, s.client)
+		if err != nil {
+			log.Printf("invalid issue tracker of project %d: %v", cfg.ProjectID, err)
+			continue
+		}
+		if _, err := s.syncProject(ctx, cfg.ProjectID, tracker); err != nil {
+			log.Printf("failed to sync issue tracker of project %d: %v", cfg.ProjectID, err)
+		}
+	}
+	return nil
+}
+
+// SyncProject reads the status of the issue of every linked defect of a project that is
+// not closed, and returns the number of defects whose status changed
+func (s *IssueTrackerService) SyncProject(ctx context.Context, projectID int64) (int, error) {
+	tracker, err := s.tracker(projectID)
+	if err != nil {
+		return 0, err
+	}
+	return s.syncProject(ctx, projectID, tracker)
+}
+
+func (s *IssueTrackerService) syncProject(ctx context.Context, projectID int64, tracker issuetracker.Tracker) (int, error) {
+	defects, err := s.defectRepo.ListLinked(projectID)
+	if err != nil {
+		return 0, err
+	}
+
+	changed := 0
+	for _, defect := range defects {
+		if ctx.Err() != nil {
+			break
+		}
+		issue, err := tracker.GetIssue(ctx, *defect.ExternalID)
+		if err != nil {
+			// The defect is retried by the next sync, unless its issue was deleted
+			log.Printf("failed to sync defect %d: %v", defect.ID, err)
+			continue
+		}
+
+		updated, err := s.applyIssue(defect, issue)
+		if err != nil {
+			return changed, err
+		}
+		if updated {
+			changed++
+		}
+	}
+	return changed, nil
+}
+
+// HandleWebhook applies an issue change reported by the webhook of the issue tracker of a
+// project. Requests about anything other than a linked issue are ignored.
+func (s *IssueTrackerService) HandleWebhook(projectID int64, header http.Header, body []byte) error {
+	tracker, err := s.tracker(projectID)
+	if err != nil {
+		return err
+	}
+	issue, err := tracker.ParseWebhook(header, body)
+	if err != nil || issue == nil {
+		return err
+	}
+
+	defect, err := s.defectRepo.GetByExternalID(projectID, issue.Key)
+	if err == repository.ErrDefectNotFound {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	_, err = s.applyIssue(defect, issue)
+	return err
+}
+
+// applyIssue records the status of the issue of a defect, and reports whether the defect
+// status changed
+func (s *IssueTrackerService) applyIssue(defect *models.Defect, issue *issuetracker.Issue) (bool, error) {
+	changed := defect.Status != issue.DefectStatus
+	if err := s.defectRepo.SyncExternalStatus(defect.ID, issue.DefectStatus, issue.Status); err != nil {
+		return false, err
+	}
+	return changed, nil
+}
+
+// sameTrackerAccount reports whether two tracker configurations send their credentials to
+// the same server and account
+func sameTrackerAccount(a, b *models.IssueTracker) bool {
+	return a.Provider == b.Provider &&
+		strings.TrimSuffix(a.BaseURL, "/") == strings.TrimSuffix(b.BaseURL, "/") &&
+		a.Username == b.Username
+}
+
+// requestError logs a failed tracker request and returns the error reported to the user,
+// which leaves out the addresses and responses of the tracker
+func requestError(projectID int64, err error) error {
+	log.Printf("issue tracker request of project %d failed: %v", projectID, err)
+	if errors.Is(err, ErrIssueTrackerHostNotAllowed) {
+		return ErrIssueTrackerHostNotAllowed
+	}
+	return ErrIssueTrackerRequest
+}
+
+// tracker returns the tracker client of the issue tracker of a project
+func (s *IssueTrackerService) tracker(projectID int64) (issuetracker.Tracker, error) {
+	cfg, err := s.trackerRepo.Get(projectID)
+	if err != nil {
+		return nil, err
+	}
+	tracker, err := issuetracker.New(cfg, s.client)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidIssueTracker, err)
+	}
+	return tracker, nil
+}
@@ -0,0 +1,363 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/mihaamiharu/test-case-management-be/internal/config"
+	"github.com/mihaamiharu/test-case-management-be/internal/issuetracker"
+	"github.com/mihaamiharu/test-case-management-be/internal/models"
+	"github.com/mihaamiharu/test-case-management-be/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeIssueTrackerRepository keeps the issue trackers of projects in memory
+type fakeIssueTrackerRepository struct {
+	repository.IssueTrackerRepositoryInterface
+	trackers map[int64]*models.IssueTracker
+}
+
+func (r *fakeIssueTrackerRepository) Get(projectID int64) (*models.IssueTracker, error) {
+	if tracker, ok := r.trackers[projectID]; ok {
+		return tracker, nil
+	}
+	return nil, repository.ErrIssueTrackerNotFound
+}
+
+func (r *fakeIssueTrackerRepository) List() ([]*models.IssueTracker, error) {
+	var trackers []*models.IssueTracker
+	for _, tracker := range r.trackers {
+		trackers = append(trackers, tracker)
+	}
+	return trackers, nil
+}
+
+func (r *fakeIssueTrackerRepository) Save(tracker *models.IssueTracker) error {
+	r.trackers[tracker.ProjectID] = tracker
+	return nil
+}
+
+// fakeDefectRepository keeps defects in memory
+type fakeDefectRepository struct {
+	repository.DefectRepositoryInterface
+	defects map[int64]*models.Defect
+}
+
+func (r *fakeDefectRepository) GetByID(id int64) (*models.Defect, error) {
+	if defect, ok := r.defects[id]; ok {
+		return defect, nil
+	}
+	return nil, repository.ErrDefectNotFound
+}
+
+func (r *fakeDefectRepository) GetByExternalID(projectID int64, externalID string) (*models.Defect, error) {
+	for _, defect := range r.defects {
+		if defect.ProjectID == projectID && defect.ExternalID != nil && *defect.ExternalID == externalID {
+			return defect, nil
+		}
+	}
+	return nil, repository.ErrDefectNotFound
+}
+
+func (r *fakeDefectRepository) ListLinked(projectID int64) ([]*models.Defect, error) {
+	var defects []*models.Defect
+	for _, defect := range r.defects {
+		if defect.ProjectID == projectID && defect.ExternalID != nil && defect.Status != models.DefectStatusClosed {
+			defects = append(defects, defect)
+		}
+	}
+	return defects, nil
+}
+
+func (r *fakeDefectRepository) LinkExternalIssue(id int64, externalID, externalURL, externalStatus string) error {
+	defect := r.defects[id]
+	if defect.ExternalID != nil {
+		return repository.ErrDefectAlreadyLinked
+	}
+	now := time.Now()
+	defect.ExternalID, defect.ExternalURL, defect.ExternalStatus, defect.ExternalSyncedAt = &externalID, &externalURL, &externalStatus, &now
+	return nil
+}
+
+func (r *fakeDefectRepository) SyncExternalStatus(id int64, status models.DefectStatus, externalStatus string) error {
+	defect := r.defects[id]
+	now := time.Now()
+	defect.Status, defect.ExternalStatus, defect.ExternalSyncedAt = status, &externalStatus, &now
+	return nil
+}
+
+// trackerStub is a local HTTP server that answers the issue APIs of Jira, GitHub and
+// GitLab with canned issues
+type trackerStub struct {
+	server   *httptest.Server
+	requests map[string]map[string]interface{}
+	headers  map[string]http.Header
+}
+
+func newTrackerStub(t *testing.T) *trackerStub {
+	stub := &trackerStub{requests: map[string]map[string]interface{}{}, headers: map[string]http.Header{}}
+	stub.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		route := r.Method + " " + r.URL.EscapedPath()
+		stub.headers[route] = r.Header
+		if r.Method == http.MethodPost {
+			body := map[string]interface{}{}
+			json.NewDecoder(r.Body).Decode(&body)
+			stub.requests[route] = body
+		}
+
+		responses := map[string]string{
+			"POST /rest/api/2/issue":                    `{"id":"10001","key":"QA-7"}`,
+			"GET /rest/api/2/issue/QA-7":                `{"key":"QA-7","fields":{"status":{"name":"In Review","statusCategory":{"key":"indeterminate"}}}}`,
+			"POST /repos/acme/shop/issues":              `{"number":42,"html_url":"https://github.com/acme/shop/issues/42","state":"open"}`,
+			"GET /repos/acme/shop/issues/42":            `{"number":42,"html_url":"https://github.com/acme/shop/issues/42","state":"closed","state_reason":"completed"}`,
+			"POST /api/v4/projects/acme%2Fshop/issues":  `{"iid":5,"web_url":"https://gitlab.com/acme/shop/-/issues/5","state":"opened"}`,
+			"GET /api/v4/projects/acme%2Fshop/issues/5": `{"iid":5,"web_url":"https://gitlab.com/acme/shop/-/issues/5","state":"closed"}`,
+		}
+		response, ok := responses[route]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method == http.MethodPost {
+			w.WriteHeader(http.StatusCreated)
+		}
+		w.Write([]byte(response))
+	}))
+	t.Cleanup(stub.server.Close)
+	return stub
+}
+
+func newTestIssueTrackerService() (*IssueTrackerService, *fakeIssueTrackerRepository, *fakeDefectRepository) {
+	trackerRepo := &fakeIssueTrackerRepository{trackers: map[int64]*models.IssueTracker{}}
+	defectRepo := &fakeDefectRepository{defects: map[int64]*models.Defect{
+		1: {ID: 1, ProjectID: 1, Title: "Checkout fails", Description: "500 on pay", Severity: models.DefectSeverityHigh, Status: models.DefectStatusOpen},
+	}}
+	return NewIssueTrackerService(trackerRepo, defectRepo, &config.Config{IssueTrackers: config.IssueTrackersConfig{
+		Timeout:         time.Second,
+		AllowedNetworks: []string{"127.0.0.0/8", "::1"},
+	}}), trackerRepo, defectRepo
+}
+
+func TestIssueTrackerService_CreateAndSyncIssue(t *testing.T) {
+	tests := []struct {
+		name           string
+		config         models.IssueTrackerConfig
+		createRoute    string
+		wantKey        string
+		wantURL        string
+		wantAuth       string
+		wantStatus     models.DefectStatus
+		wantExternal   string
+		wantTitleField string
+	}{
+		{
+			name:           "Jira",
+			config:         models.IssueTrackerConfig{Provider: models.IssueTrackerJira, ProjectKey: "QA", Username: "qa@example.com", Token: "tok"},
+			createRoute:    "POST /rest/api/2/issue",
+			wantKey:        "QA-7",
+			wantAuth:       "Basic cWFAZXhhbXBsZS5jb206dG9r",
+			wantStatus:     models.DefectStatusInProgress,
+			wantExternal:   "In Review",
+			wantTitleField: "summary",
+		},
+		{
+			name:           "GitHub",
+			config:         models.IssueTrackerConfig{Provider: models.IssueTrackerGitHub, ProjectKey: "acme/shop", Token: "tok"},
+			createRoute:    "POST /repos/acme/shop/issues",
+			wantKey:        "42",
+			wantURL:        "https://github.com/acme/shop/issues/42",
+			wantAuth:       "Bearer tok",
+			wantStatus:     models.DefectStatusResolved,
+			wantExternal:   "closed (completed)",
+			wantTitleField: "title",
+		},
+		{
+			name:           "GitLab",
+			config:         models.IssueTrackerConfig{Provider: models.IssueTrackerGitLab, ProjectKey: "acme/shop", Token: "tok"},
+			createRoute:    "POST /api/v4/projects/acme%2Fshop/issues",
+			wantKey:        "5",
+			wantURL:        "https://gitlab.com/acme/shop/-/issues/5",
+			wantStatus:     models.DefectStatusResolved,
+			wantExternal:   "closed",
+			wantTitleField: "title",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, _, defectRepo := newTestIssueTrackerService()
+			stub := newTrackerStub(t)
+			tt.config.BaseURL = stub.server.URL
+
+			_, err := s.ConfigureTracker(1, &tt.config, 7)
+			require.NoError(t, err)
+
+			// Test case: the defect is reported as an issue and linked to it
+			defect, err := s.CreateExternalIssue(context.Background(), 1)
+			require.NoError(t, err)
+			require.NotNil(t, defect.ExternalID)
+			assert.Equal(t, tt.wantKey, *defect.ExternalID)
+			if tt.wantURL == "" {
+				tt.wantURL = stub.server.URL + "/browse/" + tt.wantKey
+			}
+			assert.Equal(t, tt.wantURL, *defect.ExternalURL)
+			require.Contains(t, stub.requests, tt.createRoute)
+			if tt.wantAuth != "" {
+				assert.Equal(t, tt.wantAuth, stub.headers[tt.createRoute].Get("Authorization"))
+			} else {
+				assert.Equal(t, "tok", stub.headers[tt.createRoute].Get("Private-Token"))
+			}
+			body := stub.requests[tt.createRoute]
+			if fields, ok := body["fields"].(map[string]interface{}); ok {
+				body = fields
+			}
+			assert.Equal(t, "Checkout fails", body[tt.wantTitleField])
+
+			// Test case: a linked defect is not reported twice
+			_, err = s.CreateExternalIssue(context.Background(), 1)
+			assert.ErrorIs(t, err, repository.ErrDefectAlreadyLinked)
+
+			// Test case: the sync maps the status of the issue to the defect
+			changed, err := s.SyncProject(context.Background(), 1)
+			require.NoError(t, err)
+			assert.Equal(t, 1, changed)
+			assert.Equal(t, tt.wantStatus, defectRepo.defects[1].Status)
+			assert.Equal(t, tt.wantExternal, *defectRepo.defects[1].ExternalStatus)
+		})
+	}
+}
+
+func TestIssueTrackerService_ConfigureTracker(t *testing.T) {
+	s, trackerRepo, _ := newTestIssueTrackerService()
+
+	// Test case: a token is required when the project has no tracker yet
+	_, err := s.ConfigureTracker(1, &models.IssueTrackerConfig{Provider: models.IssueTrackerGitHub, ProjectKey: "acme/shop"}, 7)
+	assert.ErrorIs(t, err, ErrIssueTrackerTokenRequired)
+
+	// Test case: settings the provider cannot work with are rejected
+	_, err = s.ConfigureTracker(1, &models.IssueTrackerConfig{Provider: models.IssueTrackerJira, ProjectKey: "QA", Token: "tok"}, 7)
+	assert.ErrorIs(t, err, ErrInvalidIssueTracker)
+	_, err = s.ConfigureTracker(1, &models.IssueTrackerConfig{Provider: models.IssueTrackerGitHub, ProjectKey: "shop", Token: "tok"}, 7)
+	assert.ErrorIs(t, err, ErrInvalidIssueTracker)
+
+	// Test case: reconfiguring without a token or secret keeps the current ones
+	_, err = s.ConfigureTracker(1, &models.IssueTrackerConfig{Provider: models.IssueTrackerGitHub, ProjectKey: "acme/shop", Token: "tok", WebhookSecret: "shh"}, 7)
+	require.NoError(t, err)
+	tracker, err := s.ConfigureTracker(1, &models.IssueTrackerConfig{Provider: models.IssueTrackerGitHub, ProjectKey: "acme/store"}, 8)
+	require.NoError(t, err)
+	assert.Equal(t, "tok", tracker.Token)
+	assert.Equal(t, "shh", tracker.WebhookSecret)
+	assert.Equal(t, int64(7), *tracker.CreatedBy)
+	assert.Equal(t, "acme/store", trackerRepo.trackers[1].ProjectKey)
+
+	// Test case: the saved token is not reused for another server, provider or account
+	for _, changed := range []models.IssueTrackerConfig{
+		{Provider: models.IssueTrackerGitHub, BaseURL: "https://attacker.example.com", ProjectKey: "acme/store"},
+		{Provider: models.IssueTrackerGitLab, ProjectKey: "acme/store"},
+		{Provider: models.IssueTrackerJira, BaseURL: "https://example.atlassian.net", ProjectKey: "QA", Username: "qa@example.com"},
+	} {
+		_, err = s.ConfigureTracker(1, &changed, 8)
+		assert.ErrorIs(t, err, ErrIssueTrackerTokenRequired, changed.Provider)
+	}
+	assert.Equal(t, models.IssueTrackerGitHub, trackerRepo.trackers[1].Provider)
+	assert.Empty(t, trackerRepo.trackers[1].BaseURL)
+
+	// Test case: a new server needs its own token, and does not inherit the webhook secret
+	tracker, err = s.ConfigureTracker(1, &models.IssueTrackerConfig{Provider: models.IssueTrackerGitLab, BaseURL: "https://gitlab.example.com", ProjectKey: "acme/shop", Token: "other"}, 8)
+	require.NoError(t, err)
+	assert.Equal(t, "other", tracker.Token)
+	assert.Empty(t, tracker.WebhookSecret)
+}
+
+func TestIssueTrackerService_RejectsInternalTrackers(t *testing.T) {
+	trackerRepo := &fakeIssueTrackerRepository{trackers: map[int64]*models.IssueTracker{}}
+	defectRepo := &fakeDefectRepository{defects: map[int64]*models.Defect{1: {ID: 1, ProjectID: 1, Title: "Checkout fails"}}}
+	s := NewIssueTrackerService(trackerRepo, defectRepo, &config.Config{IssueTrackers: config.IssueTrackersConfig{Timeout: time.Second}})
+	stub := newTrackerStub(t)
+
+	// Test case: only http and https base URLs are accepted
+	_, err := s.ConfigureTracker(1, &models.IssueTrackerConfig{Provider: models.IssueTrackerGitLab, BaseURL: "file:///etc/passwd", ProjectKey: "acme/shop", Token: "tok"}, 7)
+	assert.ErrorIs(t, err, ErrInvalidIssueTracker)
+
+	// Test case: trackers on loopback addresses are not reached unless their network is allowed
+	_, err = s.ConfigureTracker(1, &models.IssueTrackerConfig{Provider: models.IssueTrackerGitLab, BaseURL: stub.server.URL, ProjectKey: "acme/shop", Token: "tok"}, 7)
+	require.NoError(t, err)
+	_, err = s.CreateExternalIssue(context.Background(), 1)
+	assert.Equal(t, ErrIssueTrackerHostNotAllowed, err)
+	assert.Empty(t, stub.requests)
+}
+
+func TestIssueTrackerService_HidesTrackerResponses(t *testing.T) {
+	s, _, _ := newTestIssueTrackerService()
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(`{"secret":"internal details"}`))
+	}))
+	t.Cleanup(upstream.Close)
+
+	_, err := s.ConfigureTracker(1, &models.IssueTrackerConfig{Provider: models.IssueTrackerGitLab, BaseURL: upstream.URL, ProjectKey: "acme/shop", Token: "tok"}, 7)
+	require.NoError(t, err)
+
+	// Test case: a failed request is reported without the address or response of the tracker
+	_, err = s.CreateExternalIssue(context.Background(), 1)
+	assert.Equal(t, ErrIssueTrackerRequest, err)
+}
+
+func TestIssueTrackerService_HandleWebhook(t *testing.T) {
+	s, trackerRepo, defectRepo := newTestIssueTrackerService()
+	key := "42"
+	defectRepo.defects[1].ExternalID = &key
+	sign := func(secret string, body []byte) string {
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write(body)
+		return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+	}
+
+	// Test case: projects without an issue tracker reject webhooks
+	err := s.HandleWebhook(1, http.Header{}, []byte(`{}`))
+	assert.ErrorIs(t, err, repository.ErrIssueTrackerNotFound)
+
+	trackerRepo.trackers[1] = &models.IssueTracker{ProjectID: 1, Provider: models.IssueTrackerGitHub, ProjectKey: "acme/shop", Token: "tok", WebhookSecret: "shh"}
+	body := []byte(`{"action":"closed","issue":{"number":42,"state":"closed","state_reason":"not_planned"}}`)
+
+	// Test case: a bad signature is rejected and changes nothing
+	header := http.Header{"X-Github-Event": {"issues"}, "X-Hub-Signature-256": {sign("wrong", body)}}
+	err = s.HandleWebhook(1, header, body)
+	assert.ErrorIs(t, err, issuetracker.ErrInvalidSignature)
+	assert.Equal(t, models.DefectStatusOpen, defectRepo.defects[1].Status)
+
+	// Test case: a signed issue change is applied to the linked defect
+	header.Set("X-Hub-Signature-256", sign("shh", body))
+	require.NoError(t, s.HandleWebhook(1, header, body))
+	assert.Equal(t, models.DefectStatusClosed, defectRepo.defects[1].Status)
+	assert.Equal(t, "closed (not_planned)", *defectRepo.defects[1].ExternalStatus)
+
+	// Test case: other events and issues that are not linked are ignored
+	header.Set("X-Github-Event", "ping")
+	assert.NoError(t, s.HandleWebhook(1, header, body))
+	other := []byte(`{"issue":{"number":99,"state":"closed"}}`)
+	err = s.HandleWebhook(1, http.Header{"X-Github-Event": {"issues"}, "X-Hub-Signature-256": {sign("shh", other)}}, other)
+	assert.NoError(t, err)
+
+	// Test case: GitLab hooks are authenticated with the secret token
+	trackerRepo.trackers[1] = &models.IssueTracker{ProjectID: 1, Provider: models.IssueTrackerGitLab, ProjectKey: "acme/shop", Token: "tok", WebhookSecret: "shh"}
+	body = []byte(`{"object_kind":"issue","object_attributes":{"iid":42,"state":"opened"}}`)
+	err = s.HandleWebhook(1, http.Header{"X-Gitlab-Token": {"wrong"}}, body)
+	assert.ErrorIs(t, err, issuetracker.ErrInvalidSignature)
+	require.NoError(t, s.HandleWebhook(1, http.Header{"X-Gitlab-Token": {"shh"}}, body))
+	assert.Equal(t, models.DefectStatusOpen, defectRepo.defects[1].Status)
+
+	// Test case: webhooks are rejected while the tracker has no secret
+	trackerRepo.trackers[1].WebhookSecret = ""
+	err = s.HandleWebhook(1, http.Header{"X-Gitlab-Token": {""}}, body)
+	assert.ErrorIs(t, err, issuetracker.ErrInvalidSignature)
+}
@@ -0,0 +1,135 @@
+package service
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/mihaamiharu/test-case-management-be/internal/config"
+	"github.com/mihaamiharu/test-case-management-be/internal/models"
+	"github.com/mihaamiharu/test-case-management-be/internal/repository"
+)
+
+var (
+	ErrLoginThrottled = errors.New("too many failed logins; wait before trying again")
+	ErrLoginLocked    = errors.New("too many failed logins; logins are locked for a while")
+)
+
+// LoginThrottleService protects password logins against brute-force attacks. Failed
+// logins are counted per email address and per IP address; each failure makes the next
+// attempt wait longer, and too many failures lock the address for a while.
+type LoginThrottleService struct {
+	throttleRepo repository.LoginThrottleRepositoryInterface
+	cfg          config.LoginProtectionConfig
+}
+
+// NewLoginThrottleService creates a new login throttle service
+func NewLoginThrottleService(throttleRepo repository.LoginThrottleRepositoryInterface, cfg *config.Config) *LoginThrottleService {
+	return &LoginThrottleService{
+		throttleRepo: throttleRepo,
+		cfg:          cfg.LoginProtection,
+	}
+}
+
+// Check reports ErrLoginLocked or ErrLoginThrottled, with how long to wait, when a login
+// attempt for an email address from an IP address must be rejected without checking it
+func (s *LoginThrottleService) Check(email, ip string) (time.Duration, error) {
+	now := time.Now()
+	var (
+		wait   time.Duration
+		reason error
+	)
+	for _, subject := range s.subjects(email, ip) {
+		throttle, err := s.throttleRepo.Get(subject.scope, subject.value)
+		if err != nil {
+			if errors.Is(err, repository.ErrLoginThrottleNotFound) {
+				continue
+			}
+			return 0, err
+		}
+
+		if throttle.IsLocked(now) {
+			if lockWait := throttle.LockedUntil.Sub(now); reason != ErrLoginLocked || lockWait > wait {
+				wait, reason = lockWait, ErrLoginLocked
+			}
+			continue
+		}
+		if reason == ErrLoginLocked || throttle.Failures == 0 || throttle.LastFailureAt.Before(now.Add(-s.cfg.FailureWindow)) {
+			continue
+		}
+		if delayWait := throttle.LastFailureAt.Add(s.delay(throttle.Failures)).Sub(now); delayWait > wait {
+			wait, reason = delayWait, ErrLoginThrottled
+		}
+	}
+
+	return wait, reason
+}
+
+// RecordFailure counts a failed login and returns the subjects it locked out
+func (s *LoginThrottleService) RecordFailure(email, ip string) ([]*models.LoginThrottle, error) {
+	now := time.Now()
+	var locked []*models.LoginThrottle
+	for _, subject := range s.subjects(email, ip) {
+		throttle, err := s.throttleRepo.RecordFailure(subject.scope, subject.value, now.Add(-s.cfg.FailureWindow))
+		if err != nil {
+			return nil, err
+		}
+
+		if subject.maxFailures > 0 && throttle.Failures >= subject.maxFailures {
+			until := now.Add(s.cfg.LockoutDuration)
+			if err := s.throttleRepo.Lock(subject.scope, subject.value, until); err != nil {
+				return nil, err
+			}
+			throttle.LockedUntil = &until
+			locked = append(locked, throttle)
+		}
+	}
+	return locked, nil
+}
+
+// RecordSuccess forgets the failed logins of an email address. Failures from the IP
+// address are kept, so that one known password does not hide guesses at others.
+func (s *LoginThrottleService) RecordSuccess(email string) error {
+	return s.Unlock(email)
+}
+
+// Unlock forgets the failed logins and lockout of an email address
+func (s *LoginThrottleService) Unlock(email string) error {
+	return s.throttleRepo.Reset(models.LoginThrottleAccount, normalizeEmail(email))
+}
+
+// delay returns how long to wait after a number of failures
+func (s *LoginThrottleService) delay(failures int) time.Duration {
+	delay := s.cfg.DelayBase
+	for i := 1; i < failures && delay < s.cfg.DelayMax; i++ {
+		delay *= 2
+	}
+	if delay > s.cfg.DelayMax {
+		delay = s.cfg.DelayMax
+	}
+	return delay
+}
+
+// throttleSubject is an email or IP address failed logins are counted for
+type throttleSubject struct {
+	scope       models.LoginThrottleScope
+	value       string
+	maxFailures int
+}
+
+// subjects returns the subjects of a login attempt
+func (s *LoginThrottleService) subjects(email, ip string) []throttleSubject {
+	var subjects []throttleSubject
+	if email = normalizeEmail(email); email != "" {
+		subjects = append(subjects, throttleSubject{models.LoginThrottleAccount, email, s.cfg.MaxAccountFailures})
+	}
+	if ip != "" {
+		subjects = append(subjects, throttleSubject{models.LoginThrottleIP, ip, s.cfg.MaxIPFailures})
+	}
+	return subjects
+}
+
+// normalizeEmail lowercases and trims an email address
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
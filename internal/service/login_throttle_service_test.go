@@ -0,0 +1,127 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mihaamiharu/test-case-management-be/internal/config"
+	"github.com/mihaamiharu/test-case-management-be/internal/models"
+	"github.com/mihaamiharu/test-case-management-be/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeLoginThrottleRepository keeps failed logins in memory
+type fakeLoginThrottleRepository struct {
+	throttles map[string]*models.LoginThrottle
+}
+
+func (r *fakeLoginThrottleRepository) Get(scope models.LoginThrottleScope, subject string) (*models.LoginThrottle, error) {
+	if throttle, ok := r.throttles[string(scope)+":"+subject]; ok {
+		copied := *throttle
+		return &copied, nil
+	}
+	return nil, repository.ErrLoginThrottleNotFound
+}
+
+func (r *fakeLoginThrottleRepository) RecordFailure(scope models.LoginThrottleScope, subject string, windowStart time.Time) (*models.LoginThrottle, error) {
+	key := string(scope) + ":" + subject
+	throttle, ok := r.throttles[key]
+	if !ok || throttle.LastFailureAt.Before(windowStart) {
+		throttle = &models.LoginThrottle{Scope: scope, Subject: subject}
+		r.throttles[key] = throttle
+	}
+	throttle.Failures++
+	throttle.LastFailureAt = time.Now()
+	copied := *throttle
+	return &copied, nil
+}
+
+func (r *fakeLoginThrottleRepository) Lock(scope models.LoginThrottleScope, subject string, until time.Time) error {
+	throttle := r.throttles[string(scope)+":"+subject]
+	throttle.Failures = 0
+	throttle.LockedUntil = &until
+	return nil
+}
+
+func (r *fakeLoginThrottleRepository) Reset(scope models.LoginThrottleScope, subject string) error {
+	delete(r.throttles, string(scope)+":"+subject)
+	return nil
+}
+
+func newTestLoginThrottleService(delayBase time.Duration) *LoginThrottleService {
+	repo := &fakeLoginThrottleRepository{throttles: map[string]*models.LoginThrottle{}}
+	return NewLoginThrottleService(repo, &config.Config{LoginProtection: config.LoginProtectionConfig{
+		MaxAccountFailures: 3,
+		MaxIPFailures:      5,
+		FailureWindow:      time.Hour,
+		LockoutDuration:    time.Hour,
+		DelayBase:          delayBase,
+		DelayMax:           time.Minute,
+	}})
+}
+
+func TestLoginThrottleService_Lockout(t *testing.T) {
+	s := newTestLoginThrottleService(0)
+
+	for i := 0; i < 2; i++ {
+		locked, err := s.RecordFailure("Alice@Example.com", "10.0.0.1")
+		require.NoError(t, err)
+		assert.Empty(t, locked)
+	}
+	_, err := s.Check("alice@example.com", "10.0.0.1")
+	assert.NoError(t, err)
+
+	// Test case: the last allowed failure locks the email address, whatever its case
+	locked, err := s.RecordFailure("alice@example.com", "10.0.0.1")
+	require.NoError(t, err)
+	require.Len(t, locked, 1)
+	assert.Equal(t, models.LoginThrottleAccount, locked[0].Scope)
+
+	wait, err := s.Check("ALICE@example.com", "10.0.0.2")
+	assert.Equal(t, ErrLoginLocked, err)
+	assert.True(t, wait > 59*time.Minute)
+
+	// Test case: other email addresses from the same IP address can still log in
+	_, err = s.Check("bob@example.com", "10.0.0.1")
+	assert.NoError(t, err)
+
+	// Test case: the IP address is locked after failures for several email addresses
+	_, err = s.RecordFailure("bob@example.com", "10.0.0.1")
+	require.NoError(t, err)
+	locked, err = s.RecordFailure("carol@example.com", "10.0.0.1")
+	require.NoError(t, err)
+	require.Len(t, locked, 1)
+	assert.Equal(t, models.LoginThrottleIP, locked[0].Scope)
+	_, err = s.Check("dave@example.com", "10.0.0.1")
+	assert.Equal(t, ErrLoginLocked, err)
+
+	// Test case: unlocking lifts the lockout of the email address
+	require.NoError(t, s.Unlock("alice@example.com"))
+	_, err = s.Check("alice@example.com", "10.0.0.2")
+	assert.NoError(t, err)
+}
+
+func TestLoginThrottleService_ProgressiveDelay(t *testing.T) {
+	s := newTestLoginThrottleService(10 * time.Second)
+
+	_, err := s.RecordFailure("alice@example.com", "")
+	require.NoError(t, err)
+	wait, err := s.Check("alice@example.com", "")
+	assert.Equal(t, ErrLoginThrottled, err)
+	assert.True(t, wait > 9*time.Second && wait <= 10*time.Second)
+
+	// Test case: each further failure doubles the wait
+	_, err = s.RecordFailure("alice@example.com", "")
+	require.NoError(t, err)
+	wait, err = s.Check("alice@example.com", "")
+	assert.Equal(t, ErrLoginThrottled, err)
+	assert.True(t, wait > 19*time.Second && wait <= 20*time.Second)
+
+	assert.Equal(t, time.Minute, s.delay(10))
+
+	// Test case: a successful login forgets the failures
+	require.NoError(t, s.RecordSuccess("alice@example.com"))
+	_, err = s.Check("alice@example.com", "")
+	assert.NoError(t, err)
+}
@@ -0,0 +1,390 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/mihaamiharu/test-case-management-be/internal/config"
+	"github.com/mihaamiharu/test-case-management-be/internal/events"
+	"github.com/mihaamiharu/test-case-management-be/internal/mail"
+	"github.com/mihaamiharu/test-case-management-be/internal/models"
+	"github.com/mihaamiharu/test-case-management-be/internal/repository"
+)
+
+var (
+	ErrUnknownNotificationType = errors.New("unknown notification type")
+)
+
+const (
+	defaultNotificationPageSize = 50
+	maxNotificationPageSize     = 100
+)
+
+// ProjectAccessChecker reports whether a user has a permission in a project of an organization
+type ProjectAccessChecker interface {
+	Can(organizationID, projectID int64, user *models.User, permission models.Permission) (bool, error)
+}
+
+// NotificationService notifies users about changes to their test cases and test runs. It
+// is an events publisher: test case edits notify the owner of the test case, run
+// assignments the assignee and defects the owner of the test case they were opened
+// against, unless they made the change themselves or can no longer view the project.
+// Notifications belong to the organization of their project. Unread notifications are
+// also emailed in a daily digest.
+type NotificationService struct {
+	notificationRepo repository.NotificationRepositoryInterface
+	testCaseRepo     repository.TestCaseRepositoryInterface
+	userRepo         repository.UserRepositoryInterface
+	projectRepo      repository.ProjectRepositoryInterface
+	projectAccess    ProjectAccessChecker
+	mailer           mail.Sender
+	appURL           string
+	digestHour       int
+	digestMinute     int
+}
+
+// NewNotificationService creates a new notification service
+func NewNotificationService(
+	notificationRepo repository.NotificationRepositoryInterface,
+	testCaseRepo repository.TestCaseRepositoryInterface,
+	userRepo repository.UserRepositoryInterface,
+	projectRepo repository.ProjectRepositoryInterface,
+	projectAccess ProjectAccessChecker,
+	mailer mail.Sender,
+	cfg *config.Config,
+) (*NotificationService, error) {
+	digestTime, err := time.Parse("15:04", cfg.Notifications.DigestTime)
+	if err != nil {
+		return nil, fmt.Errorf("NOTIFICATION_DIGEST_TIME must be a time of day like 08:00")
+	}
+
+	return &NotificationService{
+		notificationRepo: notificationRepo,
+		testCaseRepo:     testCaseRepo,
+		userRepo:         userRepo,
+		projectRepo:      projectRepo,
+		projectAccess:    projectAccess,
+		mailer:           mailer,
+		appURL:           strings.TrimSuffix(cfg.Accounts.AppURL, "/"),
+		digestHour:       digestTime.Hour(),
+		digestMinute:     digestTime.Minute(),
+	}, nil
+}
+
+// List retrieves a page of the notifications of a user in an organization with the number
+// of unread ones
+func (s *NotificationService) List(organizationID, userID int64, filter *models.NotificationFilter) (*models.NotificationList, error) {
+	if filter.Limit <= 0 {
+		filter.Limit = defaultNotificationPageSize
+	}
+	if filter.Limit > maxNotificationPageSize {
+		filter.Limit = maxNotificationPageSize
+	}
+	if filter.Offset < 0 {
+		filter.Offset = 0
+	}
+
+	notifications, err := s.notificationRepo.List(organizationID, userID, filter)
+	if err != nil {
+		return nil, err
+	}
+	unread, err := s.notificationRepo.CountUnread(organizationID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if notifications == nil {
+		notifications = []*models.Notification{}
+	}
+	return &models.NotificationList{Notifications: notifications, UnreadCount: unread}, nil
+}
+
+// CountUnread counts the unread notifications of a user in an organization
+func (s *NotificationService) CountUnread(organizationID, userID int64) (int, error) {
+	return s.notificationRepo.CountUnread(organizationID, userID)
+}
+
+// MarkRead marks a notification of a user in an organization as read
+func (s *NotificationService) MarkRead(organizationID, userID, id int64) error {
+	return s.notificationRepo.SetRead(organizationID, userID, id, true)
+}
+
+// MarkUnread marks a notification of a user in an organization as unread again
+func (s *NotificationService) MarkUnread(organizationID, userID, id int64) error {
+	return s.notificationRepo.SetRead(organizationID, userID, id, false)
+}
+
+// MarkAllRead marks every notification of a user in an organization as read and returns
+// how many were unread
+func (s *NotificationService) MarkAllRead(organizationID, userID int64) (int64, error) {
+	return s.notificationRepo.MarkAllRead(organizationID, userID)
+}
+
+// GetPreferences retrieves the preferences of a user for every notification type; types
+// the user has not chosen for are notified in the app and in the digest
+func (s *NotificationService) GetPreferences(userID int64) ([]*models.NotificationPreference, error) {
+	saved, err := s.notificationRepo.GetPreferences(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	byType := make(map[models.NotificationType]*models.NotificationPreference, len(saved))
+	for _, preference := range saved {
+		byType[preference.Type] = preference
+	}
+
+	preferences := make([]*models.NotificationPreference, len(models.NotificationTypes))
+	for i, notificationType := range models.NotificationTypes {
+		if preference, ok := byType[notificationType]; ok {
+			preferences[i] = preference
+		} else {
+			preferences[i] = &models.NotificationPreference{Type: notificationType, InApp: true, EmailDigest: true}
+		}
+	}
+	return preferences, nil
+}
+
+// UpdatePreferences changes the preferences of a user for the notification types given and
+// returns the preferences for every type
+func (s *NotificationService) UpdatePreferences(userID int64, update *models.NotificationPreferencesUpdate) ([]*models.NotificationPreference, error) {
+	for _, preference := range update.Preferences {
+		if !preference.Type.IsValid() {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownNotificationType, preference.Type)
+		}
+	}
+
+	if err := s.notificationRepo.SavePreferences(userID, update.Preferences); err != nil {
+		return nil, err
+	}
+	return s.GetPreferences(userID)
+}
+
+// Publish notifies the users an event concerns. Failures are logged, since they must not
+// fail the change that caused the event.
+func (s *NotificationService) Publish(event *events.Event) {
+	switch event.Type {
+	case events.TestCaseUpdated:
+		testCase, ok := event.Data.(*models.TestCaseResponse)
+		if !ok {
+			return
+		}
+		s.notify(testCase.CreatedBy, event, &models.Notification{
+			Type:       models.NotificationTestCaseUpdated,
+			Title:      fmt.Sprintf("Test case %q was edited", testCase.Title),
+			EntityType: "test_case",
+			EntityID:   testCase.ID,
+		})
+
+	case events.TestRunAssigned:
+		run, ok := event.Data.(*models.TestRun)
+		if !ok || run.AssignedTo == nil {
+			return
+		}
+		s.notify(*run.AssignedTo, event, &models.Notification{
+			Type:       models.NotificationTestRunAssigned,
+			Title:      fmt.Sprintf("Test run %q was assigned to you", run.Name),
+			EntityType: "test_run",
+			EntityID:   run.ID,
+		})
+
+	case events.DefectOpened:
+		defect, ok := event.Data.(*models.Defect)
+		if !ok {
+			return
+		}
+		testCase, err := s.testCaseRepo.GetByID(defect.TestCaseID)
+		if err != nil {
+			log.Printf("failed to find the owner of test case %d for defect %d: %v", defect.TestCaseID, defect.ID, err)
+			return
+		}
+		s.notify(testCase.CreatedBy, event, &models.Notification{
+			Type:       models.NotificationDefectOpened,
+			Title:      fmt.Sprintf("Defect %q was opened against test case %q", defect.Title, testCase.Title),
+			EntityType: "defect",
+			EntityID:   defect.ID,
+		})
+	}
+}
+
+// notify records a notification for a user unless the user caused the event, can no
+// longer view its project or does not want notifications of its type
+func (s *NotificationService) notify(userID int64, event *events.Event, notification *models.Notification) {
+	if userID == 0 || userID == event.ActorID || event.ProjectID == 0 {
+		return
+	}
+
+	project, err := s.projectRepo.GetByID(event.ProjectID)
+	if err != nil {
+		log.Printf("failed to find project %d to notify user %d: %v", event.ProjectID, userID, err)
+		return
+	}
+	if allowed, err := s.canView(project.OrganizationID, event.ProjectID, userID); err != nil {
+		log.Printf("failed to check the access of user %d to project %d: %v", userID, event.ProjectID, err)
+		return
+	} else if !allowed {
+		return
+	}
+
+	preferences, err := s.GetPreferences(userID)
+	if err != nil {
+		log.Printf("failed to get notification preferences of user %d: %v", userID, err)
+		return
+	}
+	for _, preference := range preferences {
+		if preference.Type == notification.Type && !preference.InApp {
+			return
+		}
+	}
+
+	notification.UserID = userID
+	notification.OrganizationID = project.OrganizationID
+	notification.ProjectID = &event.ProjectID
+	if event.ActorID != 0 {
+		notification.ActorID = &event.ActorID
+	}
+	if err := s.notificationRepo.Create(notification); err != nil {
+		log.Printf("failed to notify user %d of %s: %v", userID, notification.Type, err)
+	}
+}
+
+// canView reports whether a user is active and can still view a project of an organization
+func (s *NotificationService) canView(organizationID, projectID, userID int64) (bool, error) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	if user.IsDeactivated() {
+		return false, nil
+	}
+
+	allowed, err := s.projectAccess.Can(organizationID, projectID, user, models.PermissionViewProject)
+	if errors.Is(err, repository.ErrProjectNotFound) {
+		return false, nil
+	}
+	return allowed, err
+}
+
+// RunDigestJob sends the email digest every day at the configured time until the context
+// is done
+func (s *NotificationService) RunDigestJob(ctx context.Context) {
+	for {
+		timer := time.NewTimer(s.nextDigest(time.Now()).Sub(time.Now()))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		sent, err := s.SendDigests()
+		if err != nil {
+			log.Printf("failed to send notification digests: %v", err)
+		} else if sent > 0 {
+			log.Printf("sent %d notification digests", sent)
+		}
+	}
+}
+
+// SendDigests emails every user a summary of their unread notifications that were not in
+// an earlier digest, and returns the number of emails sent. Deactivated users get no
+// digest, and notifications of projects the user can no longer view, including those of
+// organizations the user left, are left out of it.
+func (s *NotificationService) SendDigests() (int, error) {
+	notifications, err := s.notificationRepo.ListDigest()
+	if err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	for start := 0; start < len(notifications); {
+		end := start
+		for end < len(notifications) && notifications[end].UserID == notifications[start].UserID {
+			end++
+		}
+		batch := notifications[start:end]
+		start = end
+
+		user, err := s.userRepo.GetByID(batch[0].UserID)
+		if err != nil {
+			log.Printf("failed to find user %d for a notification digest: %v", batch[0].UserID, err)
+			continue
+		}
+		if user.IsDeactivated() {
+			continue
+		}
+
+		visible, err := s.visibleNotifications(user, batch)
+		if err != nil {
+			log.Printf("failed to check the notification digest of user %d: %v", user.ID, err)
+			continue
+		}
+		if len(visible) > 0 {
+			if err := s.mailer.Send(s.digestMessage(user, visible)); err != nil {
+				log.Printf("failed to email the notification digest of user %d: %v", user.ID, err)
+				continue
+			}
+			sent++
+		}
+
+		// Notifications left out are not considered for later digests either
+		ids := make([]int64, len(batch))
+		for i, notification := range batch {
+			ids[i] = notification.ID
+		}
+		if err := s.notificationRepo.MarkEmailed(ids); err != nil {
+			return sent, err
+		}
+	}
+	return sent, nil
+}
+
+// visibleNotifications returns the notifications of projects a user can still view
+func (s *NotificationService) visibleNotifications(user *models.User, notifications []*models.Notification) ([]*models.Notification, error) {
+	var visible []*models.Notification
+	for _, notification := range notifications {
+		if notification.ProjectID == nil {
+			continue
+		}
+		allowed, err := s.projectAccess.Can(notification.OrganizationID, *notification.ProjectID, user, models.PermissionViewProject)
+		if err != nil && !errors.Is(err, repository.ErrProjectNotFound) {
+			return nil, err
+		}
+		if allowed {
+			visible = append(visible, notification)
+		}
+	}
+	return visible, nil
+}
+
+// digestMessage writes the digest email of a user
+func (s *NotificationService) digestMessage(user *models.User, notifications []*models.Notification) *mail.Message {
+	subject := "You have 1 unread notification"
+	if len(notifications) > 1 {
+		subject = fmt.Sprintf("You have %d unread notifications", len(notifications))
+	}
+
+	var body strings.Builder
+	fmt.Fprintf(&body, "Hi %s,\n\nHere is what happened since your last digest:\n\n", user.Username)
+	for _, notification := range notifications {
+		fmt.Fprintf(&body, "- %s (%s)\n", notification.Title, notification.CreatedAt.Format("2006-01-02 15:04"))
+	}
+	fmt.Fprintf(&body, "\nSee all your notifications at %s/notifications\n\nYou can choose which notifications you get by email in your notification preferences.\n", s.appURL)
+
+	return &mail.Message{To: user.Email, Subject: subject, Body: body.String()}
+}
+
+// nextDigest returns the first digest time after a moment
+func (s *NotificationService) nextDigest(after time.Time) time.Time {
+	next := time.Date(after.Year(), after.Month(), after.Day(), s.digestHour, s.digestMinute, 0, 0, after.Location())
+	if !next.After(after) {
+		next = next.AddDate(0, 0, 1)
+	}
+	return next
+}
@@ -0,0 +1,271 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mihaamiharu/test-case-management-be/internal/config"
+	"github.com/mihaamiharu/test-case-management-be/internal/events"
+	"github.com/mihaamiharu/test-case-management-be/internal/mail"
+	"github.com/mihaamiharu/test-case-management-be/internal/models"
+	"github.com/mihaamiharu/test-case-management-be/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeNotificationRepository keeps notifications and preferences in memory
+type fakeNotificationRepository struct {
+	repository.NotificationRepositoryInterface
+	notifications []*models.Notification
+	preferences   map[int64][]*models.NotificationPreference
+}
+
+func (r *fakeNotificationRepository) Create(notification *models.Notification) error {
+	notification.ID = int64(len(r.notifications) + 1)
+	notification.CreatedAt = time.Now()
+	r.notifications = append(r.notifications, notification)
+	return nil
+}
+
+func (r *fakeNotificationRepository) ListDigest() ([]*models.Notification, error) {
+	var digest []*models.Notification
+	for _, notification := range r.notifications {
+		if notification.EmailedAt != nil || notification.ReadAt != nil {
+			continue
+		}
+		included := true
+		for _, preference := range r.preferences[notification.UserID] {
+			if preference.Type == notification.Type {
+				included = preference.EmailDigest
+			}
+		}
+		if included {
+			digest = append(digest, notification)
+		}
+	}
+	return digest, nil
+}
+
+func (r *fakeNotificationRepository) MarkEmailed(ids []int64) error {
+	now := time.Now()
+	for _, id := range ids {
+		r.notifications[id-1].EmailedAt = &now
+	}
+	return nil
+}
+
+func (r *fakeNotificationRepository) GetPreferences(userID int64) ([]*models.NotificationPreference, error) {
+	return r.preferences[userID], nil
+}
+
+func (r *fakeNotificationRepository) SavePreferences(userID int64, preferences []models.NotificationPreference) error {
+	for _, preference := range preferences {
+		preference := preference
+		r.preferences[userID] = append(r.preferences[userID], &preference)
+	}
+	return nil
+}
+
+// fakeTestCaseRepository only looks up test cases by ID
+type fakeTestCaseRepository struct {
+	repository.TestCaseRepositoryInterface
+	testCases map[int64]*models.TestCase
+}
+
+func (r *fakeTestCaseRepository) GetByID(id int64) (*models.TestCase, error) {
+	if testCase, ok := r.testCases[id]; ok {
+		return testCase, nil
+	}
+	return nil, repository.ErrTestCaseNotFound
+}
+
+// fakeProjectAccessChecker grants the view permission to the users listed per project of
+// organization 5
+type fakeProjectAccessChecker struct {
+	viewers map[int64][]int64
+}
+
+func (c *fakeProjectAccessChecker) Can(organizationID, projectID int64, user *models.User, permission models.Permission) (bool, error) {
+	if organizationID != 5 {
+		return false, repository.ErrProjectNotFound
+	}
+	for _, userID := range c.viewers[projectID] {
+		if userID == user.ID {
+			return permission == models.PermissionViewProject, nil
+		}
+	}
+	return false, nil
+}
+
+// recordingMailSender keeps the messages it is asked to send
+type recordingMailSender struct {
+	messages []*mail.Message
+}
+
+func (s *recordingMailSender) Send(msg *mail.Message) error {
+	s.messages = append(s.messages, msg)
+	return nil
+}
+
+func newTestNotificationService(t *testing.T) (*NotificationService, *fakeNotificationRepository, *recordingMailSender, *fakeProjectAccessChecker) {
+	repo := &fakeNotificationRepository{preferences: map[int64][]*models.NotificationPreference{}}
+	testCases := &fakeTestCaseRepository{testCases: map[int64]*models.TestCase{
+		4: {ID: 4, ProjectID: 1, Title: "Login works", CreatedBy: 1},
+	}}
+	users := &fakeUserRepository{users: map[int64]*models.User{
+		1: {ID: 1, Username: "alice", Email: "alice@example.com"},
+		2: {ID: 2, Username: "bob", Email: "bob@example.com"},
+		3: {ID: 3, Username: "carol", Email: "carol@example.com"},
+	}}
+	projects := &fakeProjectRepository{projects: map[int64]*models.Project{1: {ID: 1, OrganizationID: 5}}}
+	access := &fakeProjectAccessChecker{viewers: map[int64][]int64{1: {1, 2}}}
+	mailer := &recordingMailSender{}
+
+	s, err := NewNotificationService(repo, testCases, users, projects, access, mailer, &config.Config{
+		Accounts:      config.AccountConfig{AppURL: "https://tcm.example.com/"},
+		Notifications: config.NotificationsConfig{DigestTime: "08:30"},
+	})
+	require.NoError(t, err)
+	return s, repo, mailer, access
+}
+
+func TestNotificationService_Publish(t *testing.T) {
+	s, repo, _, access := newTestNotificationService(t)
+	testCase := &models.TestCaseResponse{ID: 4, ProjectID: 1, Title: "Login works", CreatedBy: 1}
+
+	// Test case: owners are not notified of their own edits
+	s.Publish(&events.Event{Type: events.TestCaseUpdated, ProjectID: 1, ActorID: 1, Data: testCase})
+	assert.Empty(t, repo.notifications)
+
+	// Test case: owners are notified of edits by others
+	s.Publish(&events.Event{Type: events.TestCaseUpdated, ProjectID: 1, ActorID: 2, Data: testCase})
+	require.Len(t, repo.notifications, 1)
+	assert.Equal(t, int64(1), repo.notifications[0].UserID)
+	assert.Equal(t, int64(5), repo.notifications[0].OrganizationID)
+	assert.Equal(t, models.NotificationTestCaseUpdated, repo.notifications[0].Type)
+	assert.Equal(t, "test_case", repo.notifications[0].EntityType)
+	assert.Equal(t, int64(2), *repo.notifications[0].ActorID)
+
+	// Test case: assignees are notified of runs assigned to them, and unassigning notifies nobody
+	assignee := int64(2)
+	s.Publish(&events.Event{Type: events.TestRunAssigned, ProjectID: 1, ActorID: 1, Data: &models.TestRun{ID: 9, Name: "Nightly", AssignedTo: &assignee}})
+	s.Publish(&events.Event{Type: events.TestRunAssigned, ProjectID: 1, ActorID: 1, Data: &models.TestRun{ID: 9, Name: "Nightly"}})
+	require.Len(t, repo.notifications, 2)
+	assert.Equal(t, int64(2), repo.notifications[1].UserID)
+	assert.Equal(t, `Test run "Nightly" was assigned to you`, repo.notifications[1].Title)
+
+	// Test case: the owner of a test case is notified of defects opened against it
+	s.Publish(&events.Event{Type: events.DefectOpened, ProjectID: 1, ActorID: 2, Data: &models.Defect{ID: 3, TestCaseID: 4, Title: "Button missing"}})
+	require.Len(t, repo.notifications, 3)
+	assert.Equal(t, `Defect "Button missing" was opened against test case "Login works"`, repo.notifications[2].Title)
+
+	// Test case: notification types turned off in the app are not recorded
+	_, err := s.UpdatePreferences(1, &models.NotificationPreferencesUpdate{Preferences: []models.NotificationPreference{
+		{Type: models.NotificationDefectOpened, InApp: false, EmailDigest: false},
+	}})
+	require.NoError(t, err)
+	s.Publish(&events.Event{Type: events.DefectOpened, ProjectID: 1, ActorID: 2, Data: &models.Defect{ID: 4, TestCaseID: 4, Title: "Typo"}})
+	assert.Len(t, repo.notifications, 3)
+
+	// Test case: users who can no longer view the project are not notified
+	carol := int64(3)
+	s.Publish(&events.Event{Type: events.TestRunAssigned, ProjectID: 1, ActorID: 1, Data: &models.TestRun{ID: 9, Name: "Nightly", AssignedTo: &carol}})
+	access.viewers[1] = []int64{1}
+	s.Publish(&events.Event{Type: events.TestCaseUpdated, ProjectID: 1, ActorID: 1, Data: &models.TestCaseResponse{ID: 5, ProjectID: 1, Title: "Logout works", CreatedBy: 2}})
+	assert.Len(t, repo.notifications, 3)
+}
+
+func TestNotificationService_Preferences(t *testing.T) {
+	s, _, _, _ := newTestNotificationService(t)
+
+	// Test case: every type defaults to in the app and in the digest
+	preferences, err := s.GetPreferences(1)
+	require.NoError(t, err)
+	require.Len(t, preferences, len(models.NotificationTypes))
+	for _, preference := range preferences {
+		assert.True(t, preference.InApp)
+		assert.True(t, preference.EmailDigest)
+	}
+
+	// Test case: saved choices replace the defaults of their type only
+	preferences, err = s.UpdatePreferences(1, &models.NotificationPreferencesUpdate{Preferences: []models.NotificationPreference{
+		{Type: models.NotificationTestCaseUpdated, InApp: true, EmailDigest: false},
+	}})
+	require.NoError(t, err)
+	assert.Equal(t, models.NotificationTestCaseUpdated, preferences[0].Type)
+	assert.False(t, preferences[0].EmailDigest)
+	assert.True(t, preferences[1].EmailDigest)
+
+	// Test case: unknown types are rejected
+	_, err = s.UpdatePreferences(1, &models.NotificationPreferencesUpdate{Preferences: []models.NotificationPreference{
+		{Type: "comment.created", InApp: true},
+	}})
+	assert.ErrorIs(t, err, ErrUnknownNotificationType)
+}
+
+func TestNotificationService_SendDigests(t *testing.T) {
+	s, repo, mailer, access := newTestNotificationService(t)
+	projectID := int64(1)
+	for _, notification := range []*models.Notification{
+		{UserID: 1, Type: models.NotificationTestCaseUpdated, Title: `Test case "Login works" was edited`},
+		{UserID: 1, Type: models.NotificationDefectOpened, Title: `Defect "Typo" was opened against test case "Login works"`},
+		{UserID: 2, Type: models.NotificationTestRunAssigned, Title: `Test run "Nightly" was assigned to you`},
+		{UserID: 2, Type: models.NotificationTestCaseUpdated, Title: `Test case "Logout works" was edited`},
+	} {
+		notification.OrganizationID = 5
+		notification.ProjectID = &projectID
+		require.NoError(t, repo.Create(notification))
+	}
+	readAt := time.Now()
+	repo.notifications[3].ReadAt = &readAt
+
+	// Test case: each user gets one email of their unread notifications
+	sent, err := s.SendDigests()
+	require.NoError(t, err)
+	assert.Equal(t, 2, sent)
+	require.Len(t, mailer.messages, 2)
+	assert.Equal(t, "alice@example.com", mailer.messages[0].To)
+	assert.Equal(t, "You have 2 unread notifications", mailer.messages[0].Subject)
+	assert.Contains(t, mailer.messages[0].Body, `Defect "Typo" was opened`)
+	assert.Contains(t, mailer.messages[0].Body, "https://tcm.example.com/notifications")
+	assert.Equal(t, "You have 1 unread notification", mailer.messages[1].Subject)
+	assert.NotContains(t, mailer.messages[1].Body, "Logout works")
+
+	// Test case: notifications are only emailed once
+	sent, err = s.SendDigests()
+	require.NoError(t, err)
+	assert.Equal(t, 0, sent)
+
+	// Test case: deactivated users get no digest
+	repo.notifications[0].EmailedAt = nil
+	deactivatedAt := time.Now()
+	s.userRepo.(*fakeUserRepository).users[1].DeactivatedAt = &deactivatedAt
+	sent, err = s.SendDigests()
+	require.NoError(t, err)
+	assert.Equal(t, 0, sent)
+
+	// Test case: notifications of projects the user can no longer view are left out, also
+	// from later digests
+	require.NoError(t, repo.Create(&models.Notification{UserID: 2, OrganizationID: 5, ProjectID: &projectID, Type: models.NotificationTestRunAssigned, Title: `Test run "Weekly" was assigned to you`}))
+	access.viewers[1] = []int64{1}
+	sent, err = s.SendDigests()
+	require.NoError(t, err)
+	assert.Equal(t, 0, sent)
+	access.viewers[1] = []int64{1, 2}
+	sent, err = s.SendDigests()
+	require.NoError(t, err)
+	assert.Equal(t, 0, sent)
+}
+
+func TestNotificationService_NextDigest(t *testing.T) {
+	s, _, _, _ := newTestNotificationService(t)
+
+	// Test case: the digest goes out at the configured time today, or tomorrow once it has passed
+	morning := time.Date(2026, 3, 2, 7, 0, 0, 0, time.UTC)
+	assert.Equal(t, time.Date(2026, 3, 2, 8, 30, 0, 0, time.UTC), s.nextDigest(morning))
+	assert.Equal(t, time.Date(2026, 3, 3, 8, 30, 0, 0, time.UTC), s.nextDigest(time.Date(2026, 3, 2, 8, 30, 0, 0, time.UTC)))
+
+	// Test case: digest times must be times of day
+	_, err := NewNotificationService(nil, nil, nil, nil, nil, nil, &config.Config{Notifications: config.NotificationsConfig{DigestTime: "8am"}})
+	assert.Error(t, err)
+}
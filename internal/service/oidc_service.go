@@ -0,0 +1,644 @@
+package service
+
+import (
+	"context"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/mihaamiharu/test-case-management-be/internal/config"
+	"github.com/mihaamiharu/test-case-management-be/internal/models"
+	"github.com/mihaamiharu/test-case-management-be/internal/repository"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var (
+	ErrOIDCDisabled     = errors.New("single sign-on is not configured")
+	ErrOIDCLoginFailed  = errors.New("single sign-on login failed")
+	ErrInvalidOIDCState = errors.New("invalid or expired single sign-on state; start the login again")
+)
+
+const (
+	// oidcStateExpiry is how long a user has to log in at the identity provider
+	oidcStateExpiry = 10 * time.Minute
+
+	// oidcKeyRefetchInterval is the least time between fetches of the signing keys of the
+	// provider, so that ID tokens with made-up key IDs cannot make a request each
+	oidcKeyRefetchInterval = time.Minute
+)
+
+// OIDCIdentity is the identity an OpenID Connect provider reports for a user
+type OIDCIdentity struct {
+	Issuer  string
+	Subject string
+	Email   string
+	Name    string
+	Groups  []string
+}
+
+// oidcRoleMapping gives the members of an identity provider group a role
+type oidcRoleMapping struct {
+	group string
+	role  models.Role
+}
+
+// oidcProjectGrant gives the members of an identity provider group access to a project
+type oidcProjectGrant struct {
+	projectID int64
+	level     models.AccessLevel
+}
+
+// oidcDiscovery is the part of the provider configuration document the login flow uses
+type oidcDiscovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// OIDCService handles single sign-on with an OpenID Connect provider using the
+// authorization code flow with PKCE
+type OIDCService struct {
+	cfg               config.OIDCConfig
+	stateKey          []byte
+	userRepo          repository.UserRepositoryInterface
+	projectRepo       repository.ProjectRepositoryInterface
+	projectAccessRepo repository.ProjectAccessRepositoryInterface
+	organizationRepo  repository.OrganizationRepositoryInterface
+	httpClient        *http.Client
+	roleMappings      []oidcRoleMapping
+	projectGrants     map[string][]oidcProjectGrant
+
+	mu            sync.Mutex
+	discovery     *oidcDiscovery
+	keys          map[string]*rsa.PublicKey
+	keysFetchedAt time.Time
+}
+
+// NewOIDCService creates a new OIDC service. It fails when the group mappings cannot be parsed.
+func NewOIDCService(
+	cfg *config.Config,
+	userRepo repository.UserRepositoryInterface,
+	projectRepo repository.ProjectRepositoryInterface,
+	projectAccessRepo repository.ProjectAccessRepositoryInterface,
+	organizationRepo repository.OrganizationRepositoryInterface,
+) (*OIDCService, error) {
+	roleMappings, err := parseRoleMappings(cfg.OIDC.RoleMapping)
+	if err != nil {
+		return nil, err
+	}
+	projectGrants, err := parseProjectGrants(cfg.OIDC.ProjectMapping)
+	if err != nil {
+		return nil, err
+	}
+
+	// The login state is signed with its own key so it can never pass as an access token
+	stateKey := sha256.Sum256([]byte("oidc-state:" + cfg.JWTSecret))
+
+	return &OIDCService{
+		cfg:               cfg.OIDC,
+		stateKey:          stateKey[:],
+		userRepo:          userRepo,
+		projectRepo:       projectRepo,
+		projectAccessRepo: projectAccessRepo,
+		organizationRepo:  organizationRepo,
+		httpClient:        &http.Client{Timeout: 10 * time.Second},
+		roleMappings:      roleMappings,
+		projectGrants:     projectGrants,
+	}, nil
+}
+
+// Enabled reports whether single sign-on is configured
+func (s *OIDCService) Enabled() bool {
+	return s.cfg.IssuerURL != ""
+}
+
+// PostLoginRedirect returns the frontend URL that receives the tokens after single sign-on,
+// or an empty string to return them as JSON
+func (s *OIDCService) PostLoginRedirect() string {
+	return s.cfg.PostLoginRedirect
+}
+
+// StartLogin returns the URL of the provider's login page and the signed login state,
+// which the client must send back with the callback
+func (s *OIDCService) StartLogin(ctx context.Context) (string, string, error) {
+	if !s.Enabled() {
+		return "", "", ErrOIDCDisabled
+	}
+
+	discovery, err := s.getDiscovery(ctx)
+	if err != nil {
+		return "", "", err
+	}
+
+	state, err := randomToken(16)
+	if err != nil {
+		return "", "", err
+	}
+	nonce, err := randomToken(16)
+	if err != nil {
+		return "", "", err
+	}
+	verifier, err := randomToken(32)
+	if err != nil {
+		return "", "", err
+	}
+
+	authURL, err := url.Parse(discovery.AuthorizationEndpoint)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid authorization endpoint: %v", err)
+	}
+	challenge := sha256.Sum256([]byte(verifier))
+	query := authURL.Query()
+	query.Set("response_type", "code")
+	query.Set("client_id", s.cfg.ClientID)
+	query.Set("redirect_uri", s.cfg.RedirectURL)
+	query.Set("scope", s.cfg.Scopes)
+	query.Set("state", state)
+	query.Set("nonce", nonce)
+	query.Set("code_challenge", base64.RawURLEncoding.EncodeToString(challenge[:]))
+	query.Set("code_challenge_method", "S256")
+	authURL.RawQuery = query.Encode()
+
+	signedState, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"state":    state,
+		"nonce":    nonce,
+		"verifier": verifier,
+		"exp":      time.Now().Add(oidcStateExpiry).Unix(),
+	}).SignedString(s.stateKey)
+	if err != nil {
+		return "", "", err
+	}
+
+	return authURL.String(), signedState, nil
+}
+
+// FinishLogin exchanges the authorization code from the provider's callback for the user's
+// identity, then creates or updates the user
+func (s *OIDCService) FinishLogin(ctx context.Context, signedState, state, code string) (*models.User, error) {
+	if !s.Enabled() {
+		return nil, ErrOIDCDisabled
+	}
+
+	claims := jwt.MapClaims{}
+	_, err := jwt.ParseWithClaims(signedState, claims, func(token *jwt.Token) (interface{}, error) {
+		return s.stateKey, nil
+	}, jwt.WithValidMethods([]string{"HS256"}), jwt.WithExpirationRequired())
+	if err != nil {
+		return nil, ErrInvalidOIDCState
+	}
+	expectedState, _ := claims["state"].(string)
+	nonce, _ := claims["nonce"].(string)
+	verifier, _ := claims["verifier"].(string)
+	if expectedState == "" || subtle.ConstantTimeCompare([]byte(expectedState), []byte(state)) != 1 {
+		return nil, ErrInvalidOIDCState
+	}
+
+	identity, err := s.exchangeCode(ctx, code, verifier, nonce)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrOIDCLoginFailed, err)
+	}
+
+	return s.provision(identity)
+}
+
+// exchangeCode redeems an authorization code at the token endpoint and verifies the ID token
+func (s *OIDCService) exchangeCode(ctx context.Context, code, verifier, nonce string) (*OIDCIdentity, error) {
+	discovery, err := s.getDiscovery(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {s.cfg.RedirectURL},
+		"code_verifier": {verifier},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, discovery.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth(url.QueryEscape(s.cfg.ClientID), url.QueryEscape(s.cfg.ClientSecret))
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to redeem authorization code: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var tokenResponse struct {
+		IDToken          string `json:"id_token"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&tokenResponse); err != nil {
+		return nil, fmt.Errorf("failed to decode token response: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("token endpoint returned %d: %s %s", resp.StatusCode, tokenResponse.Error, tokenResponse.ErrorDescription)
+	}
+	if tokenResponse.IDToken == "" {
+		return nil, errors.New("token response has no id_token")
+	}
+
+	return s.verifyIDToken(ctx, discovery, tokenResponse.IDToken, nonce)
+}
+
+// verifyIDToken checks the signature, issuer, audience, expiry and nonce of an ID token,
+// and that the provider verified the email address it reports
+func (s *OIDCService) verifyIDToken(ctx context.Context, discovery *oidcDiscovery, idToken, nonce string) (*OIDCIdentity, error) {
+	claims := jwt.MapClaims{}
+	_, err := jwt.ParseWithClaims(idToken, claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		return s.getKey(ctx, discovery, kid)
+	},
+		jwt.WithValidMethods([]string{"RS256"}),
+		jwt.WithIssuer(discovery.Issuer),
+		jwt.WithAudience(s.cfg.ClientID),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ID token: %v", err)
+	}
+
+	if tokenNonce, _ := claims["nonce"].(string); subtle.ConstantTimeCompare([]byte(tokenNonce), []byte(nonce)) != 1 {
+		return nil, errors.New("ID token nonce does not match")
+	}
+
+	identity := &OIDCIdentity{Issuer: discovery.Issuer}
+	identity.Subject, _ = claims["sub"].(string)
+	if identity.Subject == "" {
+		return nil, errors.New("ID token has no subject")
+	}
+	identity.Email, _ = claims["email"].(string)
+	if identity.Email == "" {
+		return nil, errors.New("ID token has no email; request the email scope")
+	}
+	// Accounts are linked by email address, so providers that do not say the address is
+	// verified could let anyone take over the account of an address they type in. Some
+	// providers send the claim as a string.
+	switch verified := claims["email_verified"].(type) {
+	case bool:
+		if !verified {
+			return nil, errors.New("email address is not verified by the identity provider")
+		}
+	case string:
+		if verified != "true" {
+			return nil, errors.New("email address is not verified by the identity provider")
+		}
+	default:
+		return nil, errors.New("identity provider does not report whether the email address is verified")
+	}
+	identity.Name, _ = claims["name"].(string)
+	if identity.Name == "" {
+		identity.Name, _ = claims["preferred_username"].(string)
+	}
+
+	switch groups := claims[s.cfg.GroupsClaim].(type) {
+	case []interface{}:
+		for _, group := range groups {
+			if name, ok := group.(string); ok {
+				identity.Groups = append(identity.Groups, name)
+			}
+		}
+	case string:
+		identity.Groups = []string{groups}
+	}
+
+	return identity, nil
+}
+
+// provision finds the user of an identity by its issuer and subject, links the identity
+// to the user with its email address or creates the user on first login, and applies the
+// role and project access of its groups
+func (s *OIDCService) provision(identity *OIDCIdentity) (*models.User, error) {
+	role, roleMapped := s.mapRole(identity.Groups)
+
+	user, err := s.userRepo.GetByOIDCIdentity(identity.Issuer, identity.Subject)
+	if errors.Is(err, repository.ErrUserNotFound) {
+		user, err = s.linkUser(identity, role)
+	}
+	switch {
+	case err != nil:
+		return nil, err
+	case user.IsDeactivated():
+		// Deactivated users stay deactivated whatever their groups are
+		return nil, ErrUserDeactivated
+	case roleMapped && user.Role != role:
+		if err := s.userRepo.UpdateRole(user.ID, role); err != nil {
+			return nil, err
+		}
+		user.Role = role
+	}
+
+	if err := s.grantProjectAccess(user, identity.Groups); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// linkUser links an identity to the user with its email address, which verifyIDToken
+// checked the provider verified, or creates a user for it when there is none. Users that
+// log in with another identity are not linked, so that a changed or reassigned email
+// address at the provider does not give access to their account.
+func (s *OIDCService) linkUser(identity *OIDCIdentity, role models.Role) (*models.User, error) {
+	user, err := s.userRepo.GetByEmail(identity.Email)
+	switch {
+	case errors.Is(err, repository.ErrUserNotFound):
+		user, err = s.createUser(identity, role)
+		if err != nil {
+			return nil, err
+		}
+	case err != nil:
+		return nil, err
+	case user.OIDCSubject != nil:
+		return nil, fmt.Errorf("%w: %s belongs to a user that logs in with another identity", ErrOIDCLoginFailed, identity.Email)
+	}
+
+	if err := s.userRepo.SetOIDCIdentity(user.ID, identity.Issuer, identity.Subject); err != nil {
+		return nil, err
+	}
+	user.OIDCIssuer = &identity.Issuer
+	user.OIDCSubject = &identity.Subject
+	return user, nil
+}
+
+// createUser creates a user for an identity. The user gets a random password, so it can
+// only log in with single sign-on until the password is reset.
+func (s *OIDCService) createUser(identity *OIDCIdentity, role models.Role) (*models.User, error) {
+	password, err := randomToken(32)
+	if err != nil {
+		return nil, err
+	}
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, err
+	}
+
+	username := identity.Name
+	if len([]rune(username)) < 3 {
+		username = identity.Email[:strings.LastIndex(identity.Email, "@")]
+	}
+
+	// The provider vouches for the email address
+	now := time.Now()
+	user := &models.User{
+		Username:        truncate(username, 50),
+		Email:           identity.Email,
+		PasswordHash:    string(hashedPassword),
+		Role:            role,
+		EmailVerifiedAt: &now,
+	}
+	if err := s.userRepo.Create(user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// mapRole returns the role of the first role mapping that matches one of the groups. Users
+// in no mapped group keep their role, and new users become regular users.
+func (s *OIDCService) mapRole(groups []string) (models.Role, bool) {
+	for _, mapping := range s.roleMappings {
+		for _, group := range groups {
+			if group == mapping.group {
+				return mapping.role, true
+			}
+		}
+	}
+	return models.RoleUser, false
+}
+
+// grantProjectAccess gives a user the role its groups map to on each mapped project and
+// makes it a member of the organization of the project. The highest role wins when groups
+// map to different roles in the same project.
+func (s *OIDCService) grantProjectAccess(user *models.User, groups []string) error {
+	levels := make(map[int64]models.AccessLevel)
+	for _, group := range groups {
+		for _, grant := range s.projectGrants[group] {
+			if !levels[grant.projectID].Includes(grant.level) {
+				levels[grant.projectID] = grant.level
+			}
+		}
+	}
+
+	for projectID, level := range levels {
+		project, err := s.projectRepo.GetByID(projectID)
+		if err != nil {
+			if errors.Is(err, repository.ErrProjectNotFound) {
+				log.Printf("OIDC project mapping refers to missing project %d", projectID)
+				continue
+			}
+			return err
+		}
+		if err := s.joinOrganization(project.OrganizationID, user.ID); err != nil {
+			return err
+		}
+		if project.OwnerID == user.ID {
+			continue
+		}
+
+		access, err := s.projectAccessRepo.GetByProjectAndUser(projectID, user.ID)
+		if errors.Is(err, repository.ErrProjectAccessNotFound) {
+			access = &models.ProjectAccess{ProjectID: projectID, UserID: user.ID, Level: level}
+			if err := s.projectAccessRepo.Create(access); err != nil {
+				return err
+			}
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if access.Level != level {
+			access.Level = level
+			if err := s.projectAccessRepo.Update(access); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// joinOrganization makes a user a member of an organization unless it already is one
+func (s *OIDCService) joinOrganization(organizationID, userID int64) error {
+	_, err := s.organizationRepo.GetMember(organizationID, userID)
+	if !errors.Is(err, repository.ErrOrganizationMemberNotFound) {
+		return err
+	}
+
+	return s.organizationRepo.AddMember(&models.OrganizationMember{
+		OrganizationID: organizationID,
+		UserID:         userID,
+		Role:           models.OrganizationRoleMember,
+	})
+}
+
+// getDiscovery fetches the provider configuration once
+func (s *OIDCService) getDiscovery(ctx context.Context) (*oidcDiscovery, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.discovery != nil {
+		return s.discovery, nil
+	}
+
+	issuer := strings.TrimSuffix(s.cfg.IssuerURL, "/")
+	discovery := &oidcDiscovery{}
+	if err := s.getJSON(ctx, issuer+"/.well-known/openid-configuration", discovery); err != nil {
+		return nil, fmt.Errorf("failed to discover OIDC provider: %v", err)
+	}
+	if strings.TrimSuffix(discovery.Issuer, "/") != issuer {
+		return nil, fmt.Errorf("OIDC provider reports issuer %q instead of %q", discovery.Issuer, s.cfg.IssuerURL)
+	}
+
+	s.discovery = discovery
+	return discovery, nil
+}
+
+// getKey returns the provider's signing key with an ID, fetching the key set again when
+// the key is unknown, such as after the provider rotated its keys. The key set is fetched
+// at most once per oidcKeyRefetchInterval, without holding up other logins; unknown keys
+// are rejected in between.
+func (s *OIDCService) getKey(ctx context.Context, discovery *oidcDiscovery, kid string) (*rsa.PublicKey, error) {
+	s.mu.Lock()
+	key, ok := s.keys[kid]
+	refetch := !ok && time.Since(s.keysFetchedAt) >= oidcKeyRefetchInterval
+	if refetch {
+		s.keysFetchedAt = time.Now()
+	}
+	s.mu.Unlock()
+
+	if ok {
+		return key, nil
+	}
+	if !refetch {
+		return nil, fmt.Errorf("unknown OIDC signing key %q", kid)
+	}
+
+	keys, err := s.fetchKeys(ctx, discovery)
+	if err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	s.keys = keys
+	s.mu.Unlock()
+
+	key, ok = keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown OIDC signing key %q", kid)
+	}
+	return key, nil
+}
+
+// fetchKeys fetches the RSA signing keys of the provider by key ID
+func (s *OIDCService) fetchKeys(ctx context.Context, discovery *oidcDiscovery) (map[string]*rsa.PublicKey, error) {
+	var keySet struct {
+		Keys []struct {
+			Kid string `json:"kid"`
+			Kty string `json:"kty"`
+			Use string `json:"use"`
+			N   string `json:"n"`
+			E   string `json:"e"`
+		} `json:"keys"`
+	}
+	if err := s.getJSON(ctx, discovery.JWKSURI, &keySet); err != nil {
+		return nil, fmt.Errorf("failed to fetch OIDC signing keys: %v", err)
+	}
+
+	keys := make(map[string]*rsa.PublicKey)
+	for _, jwk := range keySet.Keys {
+		if jwk.Kty != "RSA" || (jwk.Use != "" && jwk.Use != "sig") {
+			continue
+		}
+		n, err := base64.RawURLEncoding.DecodeString(jwk.N)
+		if err != nil {
+			continue
+		}
+		e, err := base64.RawURLEncoding.DecodeString(jwk.E)
+		if err != nil {
+			continue
+		}
+		keys[jwk.Kid] = &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+	}
+	return keys, nil
+}
+
+// getJSON fetches and decodes a JSON document
+func (s *OIDCService) getJSON(ctx context.Context, url string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s returned %d", url, resp.StatusCode)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+// parseRoleMappings parses group=role pairs, keeping their order
+func parseRoleMappings(value string) ([]oidcRoleMapping, error) {
+	var mappings []oidcRoleMapping
+	for _, pair := range splitMappings(value) {
+		group, role, ok := strings.Cut(pair, "=")
+		switch models.Role(role) {
+		case models.RoleAdmin, models.RoleUser, models.RoleTester:
+		default:
+			ok = false
+		}
+		if !ok || group == "" {
+			return nil, fmt.Errorf("invalid OIDC role mapping %q; use group=admin|user|tester", pair)
+		}
+		mappings = append(mappings, oidcRoleMapping{group: group, role: models.Role(role)})
+	}
+	return mappings, nil
+}
+
+// parseProjectGrants parses group=projectID:role pairs
+func parseProjectGrants(value string) (map[string][]oidcProjectGrant, error) {
+	grants := make(map[string][]oidcProjectGrant)
+	for _, pair := range splitMappings(value) {
+		group, target, ok := strings.Cut(pair, "=")
+		project, level, ok2 := strings.Cut(target, ":")
+		projectID, err := strconv.ParseInt(project, 10, 64)
+		access := models.AccessLevel(level).Normalize()
+		if !ok || !ok2 || group == "" || err != nil || !access.IsGrantable() {
+			return nil, fmt.Errorf("invalid OIDC project mapping %q; use group=projectID:viewer|tester|editor|maintainer", pair)
+		}
+		grants[group] = append(grants[group], oidcProjectGrant{projectID: projectID, level: access})
+	}
+	return grants, nil
+}
+
+// splitMappings splits a comma-separated list of mappings, ignoring blanks
+func splitMappings(value string) []string {
+	var pairs []string
+	for _, pair := range strings.Split(value, ",") {
+		if pair = strings.TrimSpace(pair); pair != "" {
+			pairs = append(pairs, pair)
+		}
+	}
+	return pairs
+}
@@ -0,0 +1,277 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/mihaamiharu/test-case-management-be/internal/config"
+	"github.com/mihaamiharu/test-case-management-be/internal/models"
+	"github.com/mihaamiharu/test-case-management-be/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mockIdP is a minimal OpenID Connect provider that issues an ID token for any code
+type mockIdP struct {
+	server *httptest.Server
+	key    *rsa.PrivateKey
+	claims jwt.MapClaims
+	nonce  string
+	// keyFetches counts the requests for the key set
+	keyFetches int
+}
+
+func newMockIdP(t *testing.T) *mockIdP {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	idp := &mockIdP{key: key}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 idp.server.URL,
+			"authorization_endpoint": idp.server.URL + "/authorize",
+			"token_endpoint":         idp.server.URL + "/token",
+			"jwks_uri":               idp.server.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		idp.keyFetches++
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"keys": []map[string]string{{
+				"kid": "test-key",
+				"kty": "RSA",
+				"use": "sig",
+				"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			}},
+		})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		if id, secret, ok := r.BasicAuth(); !ok || id != "tcm" || secret != "secret" || r.FormValue("code_verifier") == "" {
+			w.WriteHeader(http.StatusUnauthorized)
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid_client"})
+			return
+		}
+
+		claims := jwt.MapClaims{
+			"iss":   idp.server.URL,
+			"aud":   "tcm",
+			"sub":   "user-1",
+			"nonce": idp.nonce,
+			"exp":   time.Now().Add(time.Minute).Unix(),
+		}
+		for name, value := range idp.claims {
+			claims[name] = value
+		}
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+		token.Header["kid"] = "test-key"
+		idToken, err := token.SignedString(key)
+		require.NoError(t, err)
+		json.NewEncoder(w).Encode(map[string]string{"id_token": idToken})
+	})
+	idp.server = httptest.NewServer(mux)
+	t.Cleanup(idp.server.Close)
+
+	return idp
+}
+
+// fakeProjectRepository serves projects from memory
+type fakeProjectRepository struct {
+	repository.ProjectRepositoryInterface
+	projects map[int64]*models.Project
+}
+
+func (r *fakeProjectRepository) GetByID(id int64) (*models.Project, error) {
+	if project, ok := r.projects[id]; ok {
+		return project, nil
+	}
+	return nil, repository.ErrProjectNotFound
+}
+
+// fakeProjectAccessRepository keeps project access in memory
+type fakeProjectAccessRepository struct {
+	repository.ProjectAccessRepositoryInterface
+	access []*models.ProjectAccess
+}
+
+func (r *fakeProjectAccessRepository) GetByProjectAndUser(projectID, userID int64) (*models.ProjectAccess, error) {
+	for _, access := range r.access {
+		if access.ProjectID == projectID && access.UserID == userID {
+			return access, nil
+		}
+	}
+	return nil, repository.ErrProjectAccessNotFound
+}
+
+func (r *fakeProjectAccessRepository) Create(access *models.ProjectAccess) error {
+	r.access = append(r.access, access)
+	return nil
+}
+
+func (r *fakeProjectAccessRepository) Update(access *models.ProjectAccess) error {
+	return nil
+}
+
+// login runs the login flow against the mock provider
+func (idp *mockIdP) login(t *testing.T, s *OIDCService) (*models.User, error) {
+	authURL, state, err := s.StartLogin(context.Background())
+	require.NoError(t, err)
+
+	parsed, err := url.Parse(authURL)
+	require.NoError(t, err)
+	query := parsed.Query()
+	assert.Equal(t, "S256", query.Get("code_challenge_method"))
+	idp.nonce = query.Get("nonce")
+
+	return s.FinishLogin(context.Background(), state, query.Get("state"), "code")
+}
+
+func TestOIDCService_Login(t *testing.T) {
+	idp := newMockIdP(t)
+	users := &fakeUserRepository{users: map[int64]*models.User{}}
+	access := &fakeProjectAccessRepository{}
+	projects := &fakeProjectRepository{projects: map[int64]*models.Project{5: {ID: 5, OrganizationID: 3, OwnerID: 99}}}
+	organizations := &fakeOrganizationRepository{}
+
+	cfg := &config.Config{JWTSecret: "jwt-secret", OIDC: config.OIDCConfig{
+		IssuerURL:      idp.server.URL,
+		ClientID:       "tcm",
+		ClientSecret:   "secret",
+		RedirectURL:    "http://localhost/callback",
+		Scopes:         "openid email",
+		GroupsClaim:    "groups",
+		RoleMapping:    "qa-leads=admin,qa=tester",
+		ProjectMapping: "qa=5:view,qa-leads=5:edit,qa=404:edit",
+	}}
+	s, err := NewOIDCService(cfg, users, projects, access, organizations)
+	require.NoError(t, err)
+
+	// Test case: the first login creates the user with the role and access of its groups and
+	// joins the organizations of its projects
+	idp.claims = jwt.MapClaims{"email": "dana@example.com", "email_verified": true, "name": "Dana", "groups": []string{"qa", "qa-leads"}}
+	user, err := idp.login(t, s)
+	require.NoError(t, err)
+	assert.Equal(t, "Dana", user.Username)
+	require.NotNil(t, user.OIDCSubject)
+	assert.Equal(t, idp.server.URL, *user.OIDCIssuer)
+	assert.Equal(t, "user-1", *user.OIDCSubject)
+	assert.Equal(t, models.RoleAdmin, user.Role)
+	require.Len(t, access.access, 1)
+	assert.Equal(t, models.AccessLevelEditor, access.access[0].Level)
+	require.Len(t, organizations.members, 1)
+	assert.Equal(t, int64(3), organizations.members[0].OrganizationID)
+	assert.Equal(t, models.OrganizationRoleMember, organizations.members[0].Role)
+
+	// Test case: a later login finds the user by its subject, even with a new email
+	// address, and applies its new groups
+	idp.claims = jwt.MapClaims{"email": "dana@example.org", "email_verified": "true", "groups": []string{"qa"}}
+	again, err := idp.login(t, s)
+	require.NoError(t, err)
+	assert.Equal(t, user.ID, again.ID)
+	assert.Equal(t, "dana@example.com", again.Email)
+	assert.Equal(t, models.RoleTester, again.Role)
+	assert.Equal(t, models.AccessLevelViewer, access.access[0].Level)
+	assert.Len(t, organizations.members, 1)
+
+	// Test case: unverified email addresses are rejected
+	idp.claims = jwt.MapClaims{"sub": "user-2", "email": "eve@example.com", "email_verified": false}
+	_, err = idp.login(t, s)
+	assert.True(t, errors.Is(err, ErrOIDCLoginFailed))
+
+	// Test case: email addresses the provider does not say are verified are rejected
+	idp.claims = jwt.MapClaims{"sub": "user-2", "email": "eve@example.com"}
+	_, err = idp.login(t, s)
+	assert.True(t, errors.Is(err, ErrOIDCLoginFailed))
+
+	// Test case: another identity with the email address of a linked user is rejected
+	idp.claims = jwt.MapClaims{"sub": "user-2", "email": "dana@example.com", "email_verified": true}
+	_, err = idp.login(t, s)
+	assert.True(t, errors.Is(err, ErrOIDCLoginFailed))
+
+	// Test case: a password user is linked to the identity with its verified email address
+	password := &models.User{Username: "frank", Email: "frank@example.com", Role: models.RoleUser}
+	require.NoError(t, users.Create(password))
+	idp.claims = jwt.MapClaims{"sub": "user-3", "email": "frank@example.com", "email_verified": true}
+	linked, err := idp.login(t, s)
+	require.NoError(t, err)
+	assert.Equal(t, password.ID, linked.ID)
+	require.NotNil(t, password.OIDCSubject)
+	assert.Equal(t, "user-3", *password.OIDCSubject)
+
+	// Test case: a callback with another state is rejected
+	_, state, err := s.StartLogin(context.Background())
+	require.NoError(t, err)
+	_, err = s.FinishLogin(context.Background(), state, "forged", "code")
+	assert.Equal(t, ErrInvalidOIDCState, err)
+}
+
+func TestOIDCService_KeyRefetchLimit(t *testing.T) {
+	idp := newMockIdP(t)
+	cfg := &config.Config{JWTSecret: "jwt-secret", OIDC: config.OIDCConfig{IssuerURL: idp.server.URL, ClientID: "tcm", ClientSecret: "secret", GroupsClaim: "groups"}}
+	s, err := NewOIDCService(cfg, &fakeUserRepository{users: map[int64]*models.User{}}, &fakeProjectRepository{}, &fakeProjectAccessRepository{}, &fakeOrganizationRepository{})
+	require.NoError(t, err)
+	discovery, err := s.getDiscovery(context.Background())
+	require.NoError(t, err)
+
+	// Test case: the first unknown key fetches the key set
+	_, err = s.getKey(context.Background(), discovery, "made-up-1")
+	assert.Error(t, err)
+	assert.Equal(t, 1, idp.keyFetches)
+
+	// Test case: known keys are served from the fetched set, and other unknown keys are
+	// rejected without fetching it again
+	key, err := s.getKey(context.Background(), discovery, "test-key")
+	require.NoError(t, err)
+	assert.NotNil(t, key)
+	_, err = s.getKey(context.Background(), discovery, "made-up-2")
+	assert.Error(t, err)
+	assert.Equal(t, 1, idp.keyFetches)
+
+	// Test case: unknown keys fetch the key set again once the interval passed
+	s.keysFetchedAt = time.Now().Add(-oidcKeyRefetchInterval)
+	_, err = s.getKey(context.Background(), discovery, "made-up-3")
+	assert.Error(t, err)
+	assert.Equal(t, 2, idp.keyFetches)
+}
+
+func TestParseOIDCMappings(t *testing.T) {
+	_, err := parseRoleMappings("qa=superuser")
+	assert.Error(t, err)
+
+	// Test case: the former view and edit levels name the viewer and editor roles
+	grants, err := parseProjectGrants(" qa=1:view , ops=2:edit, leads=2:maintainer ")
+	require.NoError(t, err)
+	assert.Equal(t, []oidcProjectGrant{{projectID: 1, level: models.AccessLevelViewer}}, grants["qa"])
+	assert.Equal(t, []oidcProjectGrant{{projectID: 2, level: models.AccessLevelEditor}}, grants["ops"])
+	assert.Equal(t, []oidcProjectGrant{{projectID: 2, level: models.AccessLevelMaintainer}}, grants["leads"])
+
+	_, err = parseProjectGrants("qa=one:view")
+	assert.Error(t, err)
+
+	// Test case: ownership cannot be granted
+	_, err = parseProjectGrants("qa=1:owner")
+	assert.Error(t, err)
+}
+
+func TestAuthService_SSODomains(t *testing.T) {
+	users := &fakeUserRepository{users: map[int64]*models.User{}}
+	cfg := &config.Config{OIDC: config.OIDCConfig{SSODomains: "Example.com"}}
+	s := NewAuthService(users, newFakeSessionRepository(), NewTwoFactorService(newFakeTwoFactorRepository(), users, cfg), cfg)
+
+	_, _, err := s.Login("dana@EXAMPLE.com", "password123", SessionClient{})
+	assert.Equal(t, ErrSSORequired, err)
+	_, err = s.Register(&models.UserCreate{Email: "dana@example.com", Password: "password123"})
+	assert.Equal(t, ErrSSORequired, err)
+}
@@ -0,0 +1,227 @@
+package service
+
+import (
+	"errors"
+	"regexp"
+
+	"github.com/mihaamiharu/test-case-management-be/internal/models"
+	"github.com/mihaamiharu/test-case-management-be/internal/repository"
+)
+
+var (
+	ErrNoOrganization            = errors.New("you are not a member of any organization; create one or ask an organization admin to add you")
+	ErrNotOrganizationMember     = errors.New("you are not a member of this organization")
+	ErrNotOrganizationAdmin      = errors.New("only organization admins can do this")
+	ErrLastOrganizationAdmin     = errors.New("an organization must keep at least one admin")
+	ErrInvalidOrganizationSlug   = errors.New("slug may only contain lowercase letters, digits and single hyphens")
+	ErrOrganizationProjectDenied = errors.New("API token is restricted to a project of another organization")
+)
+
+// organizationSlugPattern matches slugs such as acme or acme-qa
+var organizationSlugPattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+// OrganizationService handles organizations, the tenants that own projects, tags and teams,
+// and their members
+type OrganizationService struct {
+	organizationRepo repository.OrganizationRepositoryInterface
+	userRepo         repository.UserRepositoryInterface
+	projectRepo      repository.ProjectRepositoryInterface
+}
+
+// NewOrganizationService creates a new organization service
+func NewOrganizationService(
+	organizationRepo repository.OrganizationRepositoryInterface,
+	userRepo repository.UserRepositoryInterface,
+	projectRepo repository.ProjectRepositoryInterface,
+) *OrganizationService {
+	return &OrganizationService{
+		organizationRepo: organizationRepo,
+		userRepo:         userRepo,
+		projectRepo:      projectRepo,
+	}
+}
+
+// Create creates an organization with the actor as its first admin
+func (s *OrganizationService) Create(actorID int64, organizationCreate *models.OrganizationCreate) (*models.Organization, error) {
+	if !organizationSlugPattern.MatchString(organizationCreate.Slug) {
+		return nil, ErrInvalidOrganizationSlug
+	}
+
+	organization := &models.Organization{
+		Name:      organizationCreate.Name,
+		Slug:      organizationCreate.Slug,
+		CreatedBy: &actorID,
+	}
+	if err := s.organizationRepo.Create(organization); err != nil {
+		return nil, err
+	}
+	return organization, nil
+}
+
+// ListByUser lists the organizations of a user with its role in each
+func (s *OrganizationService) ListByUser(userID int64) ([]*models.Organization, error) {
+	return s.organizationRepo.ListByUser(userID)
+}
+
+// Resolve finds the organization a request of a user acts in. An organizationID of 0 picks
+// the oldest organization of the user.
+func (s *OrganizationService) Resolve(userID, organizationID int64) (*models.OrganizationMember, error) {
+	if organizationID == 0 {
+		organizations, err := s.organizationRepo.ListByUser(userID)
+		if err != nil {
+			return nil, err
+		}
+		if len(organizations) == 0 {
+			return nil, ErrNoOrganization
+		}
+		organizationID = organizations[0].ID
+	}
+
+	member, err := s.organizationRepo.GetMember(organizationID, userID)
+	if errors.Is(err, repository.ErrOrganizationMemberNotFound) {
+		return nil, ErrNotOrganizationMember
+	}
+	return member, err
+}
+
+// ProjectOrganizationID returns the organization of a project, such as the project an API
+// token is restricted to
+func (s *OrganizationService) ProjectOrganizationID(projectID int64) (int64, error) {
+	return s.projectRepo.GetOrganizationID(projectID)
+}
+
+// Get retrieves an organization of a user with its role in it
+func (s *OrganizationService) Get(userID, id int64) (*models.Organization, error) {
+	member, err := s.Resolve(userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	organization, err := s.organizationRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	organization.Role = member.Role
+	return organization, nil
+}
+
+// Update renames an organization on behalf of one of its admins
+func (s *OrganizationService) Update(actorID, id int64, organizationUpdate *models.OrganizationUpdate) (*models.Organization, error) {
+	if err := s.requireAdmin(actorID, id); err != nil {
+		return nil, err
+	}
+
+	organization, err := s.organizationRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	organization.Name = organizationUpdate.Name
+	if err := s.organizationRepo.Update(organization); err != nil {
+		return nil, err
+	}
+	organization.Role = models.OrganizationRoleAdmin
+	return organization, nil
+}
+
+// ListMembers lists the members of an organization for one of its members
+func (s *OrganizationService) ListMembers(actorID, id int64) ([]*models.OrganizationMember, error) {
+	if _, err := s.Resolve(actorID, id); err != nil {
+		return nil, err
+	}
+	return s.organizationRepo.ListMembers(id)
+}
+
+// AddMember adds the user with an email address to an organization on behalf of one of its admins
+func (s *OrganizationService) AddMember(actorID, id int64, memberAdd *models.OrganizationMemberAdd) (*models.OrganizationMember, error) {
+	if err := s.requireAdmin(actorID, id); err != nil {
+		return nil, err
+	}
+
+	user, err := s.userRepo.GetByEmail(memberAdd.Email)
+	if err != nil {
+		return nil, err
+	}
+
+	member := &models.OrganizationMember{
+		OrganizationID: id,
+		UserID:         user.ID,
+		Username:       user.Username,
+		Email:          user.Email,
+		Role:           memberAdd.Role,
+	}
+	if err := s.organizationRepo.AddMember(member); err != nil {
+		return nil, err
+	}
+	return member, nil
+}
+
+// UpdateMemberRole changes the role of a member on behalf of an admin of the organization.
+// The last admin cannot be demoted.
+func (s *OrganizationService) UpdateMemberRole(actorID, id, userID int64, role models.OrganizationRole) (*models.OrganizationMember, error) {
+	if err := s.requireAdmin(actorID, id); err != nil {
+		return nil, err
+	}
+
+	member, err := s.organizationRepo.GetMember(id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if member.Role == models.OrganizationRoleAdmin && role != models.OrganizationRoleAdmin {
+		if err := s.checkNotLastAdmin(id); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.organizationRepo.UpdateMemberRole(id, userID, role); err != nil {
+		return nil, err
+	}
+	member.Role = role
+	return member, nil
+}
+
+// RemoveMember removes a user from an organization on behalf of one of its admins, or lets
+// a member leave. The member loses its project grants and team memberships in the
+// organization. The last admin cannot leave.
+func (s *OrganizationService) RemoveMember(actorID, id, userID int64) error {
+	if actorID != userID {
+		if err := s.requireAdmin(actorID, id); err != nil {
+			return err
+		}
+	}
+
+	member, err := s.organizationRepo.GetMember(id, userID)
+	if err != nil {
+		return err
+	}
+	if member.Role == models.OrganizationRoleAdmin {
+		if err := s.checkNotLastAdmin(id); err != nil {
+			return err
+		}
+	}
+
+	return s.organizationRepo.RemoveMember(id, userID)
+}
+
+// requireAdmin reports ErrNotOrganizationAdmin unless a user is an admin of an organization
+func (s *OrganizationService) requireAdmin(userID, id int64) error {
+	member, err := s.Resolve(userID, id)
+	if err != nil {
+		return err
+	}
+	if member.Role != models.OrganizationRoleAdmin {
+		return ErrNotOrganizationAdmin
+	}
+	return nil
+}
+
+// checkNotLastAdmin reports ErrLastOrganizationAdmin when an organization has a single admin
+func (s *OrganizationService) checkNotLastAdmin(id int64) error {
+	admins, err := s.organizationRepo.CountAdmins(id)
+	if err != nil {
+		return err
+	}
+	if admins <= 1 {
+		return ErrLastOrganizationAdmin
+	}
+	return nil
+}
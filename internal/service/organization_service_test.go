@@ -0,0 +1,152 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/mihaamiharu/test-case-management-be/internal/models"
+	"github.com/mihaamiharu/test-case-management-be/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeOrganizationRepository keeps organization memberships in memory
+type fakeOrganizationRepository struct {
+	repository.OrganizationRepositoryInterface
+	members []*models.OrganizationMember
+}
+
+func (r *fakeOrganizationRepository) ListByUser(userID int64) ([]*models.Organization, error) {
+	var organizations []*models.Organization
+	for _, member := range r.members {
+		if member.UserID == userID {
+			organizations = append(organizations, &models.Organization{ID: member.OrganizationID, Role: member.Role})
+		}
+	}
+	return organizations, nil
+}
+
+func (r *fakeOrganizationRepository) GetMember(organizationID, userID int64) (*models.OrganizationMember, error) {
+	for _, member := range r.members {
+		if member.OrganizationID == organizationID && member.UserID == userID {
+			copied := *member
+			return &copied, nil
+		}
+	}
+	return nil, repository.ErrOrganizationMemberNotFound
+}
+
+func (r *fakeOrganizationRepository) AddMember(member *models.OrganizationMember) error {
+	if _, err := r.GetMember(member.OrganizationID, member.UserID); err == nil {
+		return repository.ErrOrganizationMemberExists
+	}
+	r.members = append(r.members, member)
+	return nil
+}
+
+func (r *fakeOrganizationRepository) UpdateMemberRole(organizationID, userID int64, role models.OrganizationRole) error {
+	for _, member := range r.members {
+		if member.OrganizationID == organizationID && member.UserID == userID {
+			member.Role = role
+			return nil
+		}
+	}
+	return repository.ErrOrganizationMemberNotFound
+}
+
+func (r *fakeOrganizationRepository) RemoveMember(organizationID, userID int64) error {
+	for i, member := range r.members {
+		if member.OrganizationID == organizationID && member.UserID == userID {
+			r.members = append(r.members[:i], r.members[i+1:]...)
+			return nil
+		}
+	}
+	return repository.ErrOrganizationMemberNotFound
+}
+
+func (r *fakeOrganizationRepository) CountAdmins(organizationID int64) (int, error) {
+	count := 0
+	for _, member := range r.members {
+		if member.OrganizationID == organizationID && member.Role == models.OrganizationRoleAdmin {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// newTestOrganizationService creates a service where user 1 is the admin and user 2 a member
+// of organization 1, user 2 is also a member of organization 2 and user 3 is in none
+func newTestOrganizationService() (*OrganizationService, *fakeOrganizationRepository) {
+	organizations := &fakeOrganizationRepository{members: []*models.OrganizationMember{
+		{OrganizationID: 1, UserID: 1, Role: models.OrganizationRoleAdmin},
+		{OrganizationID: 1, UserID: 2, Role: models.OrganizationRoleMember},
+		{OrganizationID: 2, UserID: 2, Role: models.OrganizationRoleMember},
+	}}
+	users := &fakeUserRepository{users: map[int64]*models.User{
+		1: {ID: 1, Username: "alice", Email: "alice@example.com"},
+		2: {ID: 2, Username: "bob", Email: "bob@example.com"},
+		3: {ID: 3, Username: "carol", Email: "carol@example.com"},
+	}}
+	return NewOrganizationService(organizations, users, &fakeProjectRepository{}), organizations
+}
+
+func TestOrganizationService_Resolve(t *testing.T) {
+	s, _ := newTestOrganizationService()
+
+	// Test case: without an organization, the oldest organization of the user is used
+	member, err := s.Resolve(2, 0)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), member.OrganizationID)
+
+	// Test case: a named organization is used when the user is a member of it
+	member, err = s.Resolve(2, 2)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), member.OrganizationID)
+
+	// Test case: organizations of other users are rejected
+	_, err = s.Resolve(1, 2)
+	assert.Equal(t, ErrNotOrganizationMember, err)
+
+	// Test case: users without organizations are told to create one
+	_, err = s.Resolve(3, 0)
+	assert.Equal(t, ErrNoOrganization, err)
+}
+
+func TestOrganizationService_Create(t *testing.T) {
+	s, _ := newTestOrganizationService()
+
+	// Test case: slugs must be lowercase words joined by hyphens
+	for _, slug := range []string{"Acme", "acme--qa", "-acme", "acme qa"} {
+		_, err := s.Create(3, &models.OrganizationCreate{Name: "Acme", Slug: slug})
+		assert.Equal(t, ErrInvalidOrganizationSlug, err, slug)
+	}
+}
+
+func TestOrganizationService_Members(t *testing.T) {
+	s, organizations := newTestOrganizationService()
+
+	// Test case: members cannot manage the members of an organization
+	_, err := s.AddMember(2, 1, &models.OrganizationMemberAdd{Email: "carol@example.com", Role: models.OrganizationRoleMember})
+	assert.Equal(t, ErrNotOrganizationAdmin, err)
+
+	// Test case: admins add users by email
+	member, err := s.AddMember(1, 1, &models.OrganizationMemberAdd{Email: "carol@example.com", Role: models.OrganizationRoleMember})
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), member.UserID)
+	assert.Equal(t, "carol", member.Username)
+
+	// Test case: the last admin can neither be demoted nor leave
+	_, err = s.UpdateMemberRole(1, 1, 1, models.OrganizationRoleMember)
+	assert.Equal(t, ErrLastOrganizationAdmin, err)
+	assert.Equal(t, ErrLastOrganizationAdmin, s.RemoveMember(1, 1, 1))
+
+	// Test case: once another admin exists, the first one can leave
+	_, err = s.UpdateMemberRole(1, 1, 2, models.OrganizationRoleAdmin)
+	require.NoError(t, err)
+	require.NoError(t, s.RemoveMember(1, 1, 1))
+	_, err = organizations.GetMember(1, 1)
+	assert.Equal(t, repository.ErrOrganizationMemberNotFound, err)
+
+	// Test case: members can leave an organization but not remove others
+	assert.Equal(t, ErrNotOrganizationAdmin, s.RemoveMember(3, 1, 2))
+	assert.NoError(t, s.RemoveMember(3, 1, 3))
+}
@@ -0,0 +1,115 @@
+package service
+
+import (
+	"net"
+	"net/http"
+	"net/url"
+	"syscall"
+	"time"
+)
+
+// nonPublicNetworks are the address ranges that do not reach the public internet:
+// loopback, private, shared, link-local, multicast, reserved and documentation ranges
+var nonPublicNetworks = parseNetworks(
+	"0.0.0.0/8",       // this network
+	"10.0.0.0/8",      // private
+	"100.64.0.0/10",   // carrier-grade NAT
+	"127.0.0.0/8",     // loopback
+	"169.254.0.0/16",  // link-local, including cloud metadata services
+	"172.16.0.0/12",   // private
+	"192.0.0.0/24",    // protocol assignments
+	"192.0.2.0/24",    // documentation
+	"192.168.0.0/16",  // private
+	"198.18.0.0/15",   // benchmarking
+	"198.51.100.0/24", // documentation
+	"203.0.113.0/24",  // documentation
+	"224.0.0.0/4",     // multicast
+	"240.0.0.0/4",     // reserved and broadcast
+	"::/128",          // unspecified
+	"::1/128",         // loopback
+	"64:ff9b:1::/48",  // local-use NAT64
+	"100::/64",        // discard
+	"2001::/32",       // Teredo
+	"2001:db8::/32",   // documentation
+	"fc00::/7",        // unique local
+	"fe80::/10",       // link-local
+	"ff00::/8",        // multicast
+)
+
+var (
+	// nat64Network embeds an IPv4 address in its last four bytes
+	nat64Network = parseNetworks("64:ff9b::/96")[0]
+	// sixToFourNetwork embeds an IPv4 address in the two bytes after the prefix
+	sixToFourNetwork = parseNetworks("2002::/16")[0]
+)
+
+// parseNetworks parses CIDR ranges, which must be valid
+func parseNetworks(cidrs ...string) []*net.IPNet {
+	networks := make([]*net.IPNet, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(err)
+		}
+		networks = append(networks, network)
+	}
+	return networks
+}
+
+// publicIP reports whether an address is on the public internet. Addresses that embed an
+// IPv4 address, like IPv4-mapped, NAT64 and 6to4 addresses, are judged by that address.
+func publicIP(ip net.IP) bool {
+	if ip4 := ip.To4(); ip4 != nil {
+		ip = ip4
+	}
+	for _, network := range nonPublicNetworks {
+		if network.Contains(ip) {
+			return false
+		}
+	}
+	switch {
+	case nat64Network.Contains(ip):
+		return publicIP(net.IP(ip[12:16]))
+	case sixToFourNetwork.Contains(ip):
+		return publicIP(net.IP(ip[2:6]))
+	}
+	return true
+}
+
+// validHTTPURL reports whether a URL is an absolute http or https URL
+func validHTTPURL(rawURL string) bool {
+	parsed, err := url.Parse(rawURL)
+	return err == nil && (parsed.Scheme == "http" || parsed.Scheme == "https") && parsed.Hostname() != ""
+}
+
+// newOutboundClient creates a client for requests to URLs that users configure. Unless
+// allowIP is nil, connections to addresses it rejects fail with errNotAllowed after DNS
+// resolution, so that a URL cannot be pointed at the internal network, also not through
+// a proxy or a host name that resolves differently later. Redirects are not followed; the
+// redirect response is the response of the request.
+func newOutboundClient(timeout time.Duration, allowIP func(net.IP) bool, errNotAllowed error) *http.Client {
+	dialer := &net.Dialer{Timeout: timeout}
+	if allowIP != nil {
+		dialer.Control = func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || !allowIP(ip) {
+				return errNotAllowed
+			}
+			return nil
+		}
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
@@ -0,0 +1,26 @@
+package service
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPublicIP(t *testing.T) {
+	// Test case: addresses that do not reach the public internet are rejected
+	for _, ip := range []string{
+		"127.0.0.1", "10.0.0.1", "172.16.0.1", "192.168.1.1", "169.254.169.254",
+		"0.0.0.0", "0.1.2.3", "100.64.0.1", "100.127.255.254", "192.0.0.170", "198.18.0.1",
+		"198.19.255.255", "224.0.0.1", "255.255.255.255",
+		"::", "::1", "fe80::1", "fd00::1", "ff02::1", "::ffff:10.0.0.1",
+		"64:ff9b::a00:1", "64:ff9b::a9fe:a9fe", "64:ff9b:1::1", "2002:a00:1::1", "2001::1",
+	} {
+		assert.False(t, publicIP(net.ParseIP(ip)), ip)
+	}
+
+	// Test case: public addresses are allowed, also when embedded in NAT64 and 6to4 addresses
+	for _, ip := range []string{"93.184.216.34", "100.128.0.1", "2606:2800:220:1::1", "::ffff:93.184.216.34", "64:ff9b::5db8:d822", "2002:5db8:d822::1"} {
+		assert.True(t, publicIP(net.ParseIP(ip)), ip)
+	}
+}
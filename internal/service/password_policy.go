@@ -0,0 +1,80 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/mihaamiharu/test-case-management-be/internal/models"
+)
+
+var ErrWeakPassword = errors.New("password does not meet the password policy")
+
+const (
+	minPasswordLength = 8
+	// bcrypt ignores everything after the first 72 bytes
+	maxPasswordBytes = 72
+)
+
+// commonPasswords are passwords that are guessed first and are rejected whatever the rules say
+var commonPasswords = map[string]bool{
+	"password1": true, "password123": true, "passw0rd": true, "qwerty123": true,
+	"12345678a": true, "abc12345": true, "letmein1": true, "welcome1": true,
+	"iloveyou1": true, "admin123": true, "changeme1": true, "p@ssw0rd": true,
+}
+
+// PasswordPolicy checks new passwords
+type PasswordPolicy struct {
+	minLength int
+}
+
+// NewPasswordPolicy creates a password policy. Passwords are never shorter than 8 characters.
+func NewPasswordPolicy(minLength int) *PasswordPolicy {
+	if minLength < minPasswordLength {
+		minLength = minPasswordLength
+	}
+	return &PasswordPolicy{minLength: minLength}
+}
+
+// Check reports an ErrWeakPassword when a password is too short or too long, has only
+// letters or no letters, is a common password, or contains the username or email of user
+func (p *PasswordPolicy) Check(password string, user *models.User) error {
+	if len([]rune(password)) < p.minLength {
+		return fmt.Errorf("%w: it must be at least %d characters long", ErrWeakPassword, p.minLength)
+	}
+	if len(password) > maxPasswordBytes {
+		return fmt.Errorf("%w: it must be at most %d bytes long", ErrWeakPassword, maxPasswordBytes)
+	}
+
+	var letters, others bool
+	for _, r := range password {
+		if unicode.IsLetter(r) {
+			letters = true
+		} else {
+			others = true
+		}
+	}
+	if !letters || !others {
+		return fmt.Errorf("%w: it must contain letters and digits or symbols", ErrWeakPassword)
+	}
+
+	lower := strings.ToLower(password)
+	if commonPasswords[lower] {
+		return fmt.Errorf("%w: it is too common", ErrWeakPassword)
+	}
+
+	if user != nil {
+		local := user.Email
+		if at := strings.LastIndex(local, "@"); at >= 0 {
+			local = local[:at]
+		}
+		for _, personal := range []string{user.Username, local} {
+			if personal = strings.ToLower(personal); len(personal) >= 3 && strings.Contains(lower, personal) {
+				return fmt.Errorf("%w: it must not contain your username or email address", ErrWeakPassword)
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,247 @@
+package service
+
+import (
+	"errors"
+
+	"github.com/mihaamiharu/test-case-management-be/internal/models"
+	"github.com/mihaamiharu/test-case-management-be/internal/repository"
+)
+
+var (
+	ErrApprovalRequired      = errors.New("test case needs the required number of approvals of its current version before it can become active")
+	ErrStatusManagedByReview = errors.New("review statuses are set by the review workflow")
+	ErrSelfReview            = errors.New("authors cannot review their own request")
+	ErrNotReviewer           = errors.New("only the assigned reviewer can decide this review")
+	ErrReviewAlreadyDecided  = errors.New("review has already been decided")
+	ErrReviewOutdated        = errors.New("test case has changed since the review was requested")
+	ErrReviewCommentRequired = errors.New("a comment is required when requesting changes")
+	ErrTestCaseNotReviewable = errors.New("test cases in a terminal status cannot be reviewed")
+)
+
+// ReviewService handles the review and approval workflow of test cases
+type ReviewService struct {
+	reviewRepo     repository.ReviewRepositoryInterface
+	testCaseRepo   repository.TestCaseRepositoryInterface
+	userRepo       repository.UserRepositoryInterface
+	commentService *CommentService
+	workflowRepo   repository.WorkflowRepositoryInterface
+}
+
+// NewReviewService creates a new review service
+func NewReviewService(
+	reviewRepo repository.ReviewRepositoryInterface,
+	testCaseRepo repository.TestCaseRepositoryInterface,
+	userRepo repository.UserRepositoryInterface,
+	commentService *CommentService,
+	workflowRepo repository.WorkflowRepositoryInterface,
+) *ReviewService {
+	return &ReviewService{
+		reviewRepo:     reviewRepo,
+		testCaseRepo:   testCaseRepo,
+		userRepo:       userRepo,
+		commentService: commentService,
+		workflowRepo:   workflowRepo,
+	}
+}
+
+// GetSettings retrieves the review rules of a project
+func (s *ReviewService) GetSettings(projectID int64) (*models.ReviewSettings, error) {
+	return s.reviewRepo.GetSettings(projectID)
+}
+
+// UpdateSettings changes the number of approvals a project requires
+func (s *ReviewService) UpdateSettings(projectID int64, requiredApprovals int, userID int64) (*models.ReviewSettings, error) {
+	settings := &models.ReviewSettings{
+		ProjectID:         projectID,
+		RequiredApprovals: requiredApprovals,
+		UpdatedBy:         userID,
+	}
+
+	if err := s.reviewRepo.UpsertSettings(settings); err != nil {
+		return nil, err
+	}
+
+	return settings, nil
+}
+
+// RequestReview asks the given users to review the current version of a test case
+// and moves the test case to in_review
+func (s *ReviewService) RequestReview(testCaseID int64, reviewerIDs []int64, requesterID int64) ([]*models.TestCaseReview, error) {
+	testCase, err := s.testCaseRepo.GetByID(testCaseID)
+	if err != nil {
+		return nil, err
+	}
+	workflow, err := s.workflowRepo.GetWorkflow(testCase.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+	if status := workflow.Status(testCase.Status); status != nil && status.Terminal {
+		return nil, ErrTestCaseNotReviewable
+	}
+
+	reviews := make([]*models.TestCaseReview, 0, len(reviewerIDs))
+	seen := make(map[int64]bool, len(reviewerIDs))
+	for _, reviewerID := range reviewerIDs {
+		if seen[reviewerID] {
+			continue
+		}
+		seen[reviewerID] = true
+
+		if reviewerID == requesterID {
+			return nil, ErrSelfReview
+		}
+		if _, err := s.userRepo.GetByID(reviewerID); err != nil {
+			return nil, err
+		}
+
+		reviews = append(reviews, &models.TestCaseReview{
+			TestCaseID:  testCaseID,
+			Version:     testCase.Version,
+			ReviewerID:  reviewerID,
+			RequestedBy: requesterID,
+		})
+	}
+
+	if err := s.reviewRepo.CreateRequests(reviews); err != nil {
+		return nil, err
+	}
+
+	if err := s.testCaseRepo.UpdateStatus(testCaseID, models.StatusInReview, requesterID); err != nil {
+		return nil, err
+	}
+
+	return reviews, nil
+}
+
+// ListReviews retrieves all reviews of a test case
+func (s *ReviewService) ListReviews(testCaseID int64) ([]*models.TestCaseReview, error) {
+	if _, err := s.testCaseRepo.GetByID(testCaseID); err != nil {
+		return nil, err
+	}
+
+	return s.reviewRepo.ListByTestCase(testCaseID)
+}
+
+// Approve records an approval. Once the version has the approvals the project
+// requires, the test case moves to approved.
+func (s *ReviewService) Approve(reviewID, userID int64, comment string) (*models.TestCaseReview, error) {
+	review, testCase, err := s.pendingReview(reviewID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.decide(review, models.ReviewStatusApproved, comment, userID); err != nil {
+		return nil, err
+	}
+
+	settings, err := s.reviewRepo.GetSettings(testCase.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+	approvals, err := s.reviewRepo.CountApprovals(testCase.ID, testCase.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	// A single approval is enough when the project does not require more
+	required := settings.RequiredApprovals
+	if required < 1 {
+		required = 1
+	}
+	if approvals >= required {
+		if err := s.testCaseRepo.UpdateStatus(testCase.ID, models.StatusApproved, userID); err != nil {
+			return nil, err
+		}
+	}
+
+	return review, nil
+}
+
+// RequestChanges records that the reviewer wants changes and moves the test case to rejected
+func (s *ReviewService) RequestChanges(reviewID, userID int64, comment string) (*models.TestCaseReview, error) {
+	if comment == "" {
+		return nil, ErrReviewCommentRequired
+	}
+
+	review, testCase, err := s.pendingReview(reviewID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.decide(review, models.ReviewStatusChangesRequested, comment, userID); err != nil {
+		return nil, err
+	}
+
+	if err := s.testCaseRepo.UpdateStatus(testCase.ID, models.StatusRejected, userID); err != nil {
+		return nil, err
+	}
+
+	return review, nil
+}
+
+// pendingReview loads a review that the user may still decide
+func (s *ReviewService) pendingReview(reviewID, userID int64) (*models.TestCaseReview, *models.TestCase, error) {
+	review, err := s.reviewRepo.GetByID(reviewID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if review.ReviewerID != userID {
+		return nil, nil, ErrNotReviewer
+	}
+	if review.Status != models.ReviewStatusPending {
+		return nil, nil, ErrReviewAlreadyDecided
+	}
+
+	testCase, err := s.testCaseRepo.GetByID(review.TestCaseID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if testCase.Version != review.Version {
+		return nil, nil, ErrReviewOutdated
+	}
+
+	return review, testCase, nil
+}
+
+// decide stores the decision together with the reviewer's comment, if any
+func (s *ReviewService) decide(review *models.TestCaseReview, status models.ReviewStatus, body string, userID int64) error {
+	if body != "" {
+		comment := &models.Comment{
+			TestCaseID: review.TestCaseID,
+			Body:       body,
+			CreatedBy:  userID,
+		}
+		if err := s.commentService.CreateComment(comment); err != nil {
+			return err
+		}
+		review.CommentID = &comment.ID
+	}
+
+	review.Status = status
+	return s.reviewRepo.Decide(review)
+}
+
+// checkActivation enforces the approval rule of the project when a test case becomes active.
+// Approvals apply to the version that was reviewed, so the content must not change in the same update.
+func checkActivation(reviewRepo repository.ReviewRepositoryInterface, before, after *models.TestCase) error {
+	settings, err := reviewRepo.GetSettings(after.ProjectID)
+	if err != nil {
+		return err
+	}
+	if settings.RequiredApprovals == 0 {
+		return nil
+	}
+	if before == nil || contentChanged(before, after) {
+		return ErrApprovalRequired
+	}
+
+	approvals, err := reviewRepo.CountApprovals(before.ID, before.Version)
+	if err != nil {
+		return err
+	}
+	if approvals < settings.RequiredApprovals {
+		return ErrApprovalRequired
+	}
+
+	return nil
+}
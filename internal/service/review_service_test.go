@@ -0,0 +1,60 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/mihaamiharu/test-case-management-be/internal/models"
+	"github.com/mihaamiharu/test-case-management-be/internal/repository"
+	"github.com/stretchr/testify/assert"
+)
+
+// stubReviewRepository returns fixed review settings and approval counts
+type stubReviewRepository struct {
+	repository.ReviewRepositoryInterface
+	requiredApprovals int
+	approvals         map[int]int
+}
+
+func (r *stubReviewRepository) GetSettings(projectID int64) (*models.ReviewSettings, error) {
+	return &models.ReviewSettings{ProjectID: projectID, RequiredApprovals: r.requiredApprovals}, nil
+}
+
+func (r *stubReviewRepository) CountApprovals(testCaseID int64, version int) (int, error) {
+	return r.approvals[version], nil
+}
+
+func TestCheckActivation(t *testing.T) {
+	before := &models.TestCase{ID: 1, ProjectID: 1, Title: "Login", Status: models.StatusApproved, Version: 3}
+	activate := func() *models.TestCase {
+		after := *before
+		after.Status = models.StatusActive
+		return &after
+	}
+
+	t.Run("NoApprovalsRequired", func(t *testing.T) {
+		repo := &stubReviewRepository{}
+		assert.NoError(t, checkActivation(repo, nil, activate()))
+	})
+
+	t.Run("EnoughApprovals", func(t *testing.T) {
+		repo := &stubReviewRepository{requiredApprovals: 2, approvals: map[int]int{3: 2}}
+		assert.NoError(t, checkActivation(repo, before, activate()))
+	})
+
+	t.Run("ApprovalsOfAnOlderVersion", func(t *testing.T) {
+		repo := &stubReviewRepository{requiredApprovals: 2, approvals: map[int]int{2: 2, 3: 1}}
+		assert.Equal(t, ErrApprovalRequired, checkActivation(repo, before, activate()))
+	})
+
+	t.Run("ContentChangedInSameUpdate", func(t *testing.T) {
+		repo := &stubReviewRepository{requiredApprovals: 1, approvals: map[int]int{3: 1}}
+		after := activate()
+		after.Title = "Login with SSO"
+		assert.Equal(t, ErrApprovalRequired, checkActivation(repo, before, after))
+	})
+
+	t.Run("NewTestCase", func(t *testing.T) {
+		repo := &stubReviewRepository{requiredApprovals: 1}
+		assert.Equal(t, ErrApprovalRequired, checkActivation(repo, nil, activate()))
+	})
+}
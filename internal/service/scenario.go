@@ -0,0 +1,119 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+
+	"github.com/mihaamiharu/test-case-management-be/internal/models"
+)
+
+var (
+	ErrMissingParameterValue = errors.New("data row is missing a value for a parameter")
+)
+
+// parameterPattern matches Scenario Outline placeholders such as <username>
+var parameterPattern = regexp.MustCompile(`<([A-Za-z_][A-Za-z0-9_]*)>`)
+
+// ExtractParameters returns the placeholder names used by the steps, in order of first use.
+// Steps that reference a shared step contribute the placeholders of the shared step items.
+func ExtractParameters(steps []*models.TestStep) []string {
+	var names []string
+	seen := make(map[string]bool)
+
+	collect := func(texts ...string) {
+		for _, text := range texts {
+			for _, match := range parameterPattern.FindAllStringSubmatch(text, -1) {
+				if !seen[match[1]] {
+					seen[match[1]] = true
+					names = append(names, match[1])
+				}
+			}
+		}
+	}
+
+	for _, step := range steps {
+		collect(step.Description, step.ExpectedResult)
+		if step.SharedStep != nil {
+			for _, item := range step.SharedStep.Items {
+				collect(item.Description, item.ExpectedResult)
+			}
+		}
+	}
+
+	return names
+}
+
+// SubstituteParameters replaces placeholders with their values; unknown placeholders are kept
+func SubstituteParameters(text string, values map[string]string) string {
+	return parameterPattern.ReplaceAllStringFunc(text, func(placeholder string) string {
+		name := placeholder[1 : len(placeholder)-1]
+		if value, ok := values[name]; ok {
+			return value
+		}
+		return placeholder
+	})
+}
+
+// ValidateDataRows checks that every row provides a value for each parameter
+func ValidateDataRows(parameters []string, rows []*models.TestCaseDataRow) error {
+	for _, row := range rows {
+		for _, name := range parameters {
+			if _, ok := row.Values[name]; !ok {
+				return fmt.Errorf("%w: row %d has no value for <%s>", ErrMissingParameterValue, row.RowNumber, name)
+			}
+		}
+	}
+	return nil
+}
+
+// ExpandScenarios expands a test case into one scenario per data row.
+// A test case without data rows expands into a single scenario with its steps unchanged.
+func ExpandScenarios(testCase *models.TestCase) []*models.TestScenario {
+	if len(testCase.DataRows) == 0 {
+		return []*models.TestScenario{{
+			TestCaseID: testCase.ID,
+			Steps:      testCase.Steps,
+		}}
+	}
+
+	scenarios := make([]*models.TestScenario, len(testCase.DataRows))
+	for i, row := range testCase.DataRows {
+		rowID := row.ID
+		scenarios[i] = &models.TestScenario{
+			TestCaseID: testCase.ID,
+			DataRowID:  &rowID,
+			RowNumber:  row.RowNumber,
+			Name:       row.Name,
+			Values:     row.Values,
+			Steps:      substituteSteps(testCase.Steps, row.Values),
+		}
+	}
+
+	return scenarios
+}
+
+// substituteSteps returns copies of the steps with placeholders replaced
+func substituteSteps(steps []*models.TestStep, values map[string]string) []*models.TestStep {
+	substituted := make([]*models.TestStep, len(steps))
+	for i, step := range steps {
+		stepCopy := *step
+		stepCopy.Description = SubstituteParameters(step.Description, values)
+		stepCopy.ExpectedResult = SubstituteParameters(step.ExpectedResult, values)
+
+		if step.SharedStep != nil {
+			sharedStepCopy := *step.SharedStep
+			sharedStepCopy.Items = make([]*models.SharedStepItem, len(step.SharedStep.Items))
+			for j, item := range step.SharedStep.Items {
+				itemCopy := *item
+				itemCopy.Description = SubstituteParameters(item.Description, values)
+				itemCopy.ExpectedResult = SubstituteParameters(item.ExpectedResult, values)
+				sharedStepCopy.Items[j] = &itemCopy
+			}
+			stepCopy.SharedStep = &sharedStepCopy
+		}
+
+		substituted[i] = &stepCopy
+	}
+	return substituted
+}
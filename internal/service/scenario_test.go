@@ -0,0 +1,86 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/mihaamiharu/test-case-management-be/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExtractParameters(t *testing.T) {
+	steps := []*models.TestStep{
+		{Description: "Log in as <username> with <password>", ExpectedResult: "Welcome <username>"},
+		{
+			SharedStep: &models.SharedStep{
+				Items: []*models.SharedStepItem{
+					{Description: "Open <page>"},
+				},
+			},
+		},
+		{Description: "Click <not a placeholder>"},
+	}
+
+	assert.Equal(t, []string{"username", "password", "page"}, ExtractParameters(steps))
+}
+
+func TestSubstituteParameters(t *testing.T) {
+	values := map[string]string{"username": "alice"}
+
+	assert.Equal(t, "Log in as alice with <password>",
+		SubstituteParameters("Log in as <username> with <password>", values))
+}
+
+func TestValidateDataRows(t *testing.T) {
+	t.Run("Complete", func(t *testing.T) {
+		rows := []*models.TestCaseDataRow{
+			{RowNumber: 1, Values: map[string]string{"username": "alice", "extra": "ignored"}},
+		}
+		assert.NoError(t, ValidateDataRows([]string{"username"}, rows))
+	})
+
+	t.Run("MissingValue", func(t *testing.T) {
+		rows := []*models.TestCaseDataRow{
+			{RowNumber: 1, Values: map[string]string{"username": "alice"}},
+			{RowNumber: 2, Values: map[string]string{}},
+		}
+		err := ValidateDataRows([]string{"username"}, rows)
+		assert.True(t, errors.Is(err, ErrMissingParameterValue))
+		assert.Contains(t, err.Error(), "row 2")
+	})
+}
+
+func TestExpandScenarios(t *testing.T) {
+	steps := []*models.TestStep{
+		{StepNumber: 1, Description: "Log in as <username>", ExpectedResult: "Dashboard is shown"},
+	}
+
+	t.Run("WithoutDataRows", func(t *testing.T) {
+		testCase := &models.TestCase{ID: 1, Steps: steps}
+
+		scenarios := ExpandScenarios(testCase)
+		assert.Len(t, scenarios, 1)
+		assert.Nil(t, scenarios[0].DataRowID)
+		assert.Equal(t, "Log in as <username>", scenarios[0].Steps[0].Description)
+	})
+
+	t.Run("WithDataRows", func(t *testing.T) {
+		testCase := &models.TestCase{
+			ID:    1,
+			Steps: steps,
+			DataRows: []*models.TestCaseDataRow{
+				{ID: 10, RowNumber: 1, Name: "admin", Values: map[string]string{"username": "admin"}},
+				{ID: 11, RowNumber: 2, Name: "viewer", Values: map[string]string{"username": "viewer"}},
+			},
+		}
+
+		scenarios := ExpandScenarios(testCase)
+		assert.Len(t, scenarios, 2)
+		assert.Equal(t, int64(10), *scenarios[0].DataRowID)
+		assert.Equal(t, "Log in as admin", scenarios[0].Steps[0].Description)
+		assert.Equal(t, "Log in as viewer", scenarios[1].Steps[0].Description)
+
+		// The original steps are left untouched
+		assert.Equal(t, "Log in as <username>", steps[0].Description)
+	})
+}
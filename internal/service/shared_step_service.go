@@ -0,0 +1,59 @@
+package service
+
+import (
+	"errors"
+
+	"github.com/mihaamiharu/test-case-management-be/internal/models"
+	"github.com/mihaamiharu/test-case-management-be/internal/repository"
+)
+
+var (
+	ErrSharedStepProjectMismatch = errors.New("shared step belongs to a different project")
+)
+
+// SharedStepService handles shared step library business logic
+type SharedStepService struct {
+	sharedStepRepo repository.SharedStepRepositoryInterface
+}
+
+// NewSharedStepService creates a new shared step service
+func NewSharedStepService(sharedStepRepo repository.SharedStepRepositoryInterface) *SharedStepService {
+	return &SharedStepService{
+		sharedStepRepo: sharedStepRepo,
+	}
+}
+
+// CreateSharedStep creates a new shared step with its items
+func (s *SharedStepService) CreateSharedStep(sharedStep *models.SharedStep) error {
+	return s.sharedStepRepo.Create(sharedStep)
+}
+
+// GetSharedStepByID retrieves a shared step by ID
+func (s *SharedStepService) GetSharedStepByID(id int64) (*models.SharedStep, error) {
+	return s.sharedStepRepo.GetByID(id)
+}
+
+// UpdateSharedStep updates a shared step; every test case referencing it sees the change
+func (s *SharedStepService) UpdateSharedStep(sharedStep *models.SharedStep) error {
+	return s.sharedStepRepo.Update(sharedStep)
+}
+
+// DeleteSharedStep deletes a shared step that is not referenced by any test case
+func (s *SharedStepService) DeleteSharedStep(id int64) error {
+	return s.sharedStepRepo.Delete(id)
+}
+
+// ListSharedStepsByProject retrieves the shared step library of a project
+func (s *SharedStepService) ListSharedStepsByProject(projectID int64) ([]*models.SharedStep, error) {
+	return s.sharedStepRepo.ListByProject(projectID)
+}
+
+// GetSharedStepUsage lists the test cases that reference a shared step
+func (s *SharedStepService) GetSharedStepUsage(id int64) ([]*models.SharedStepUsage, error) {
+	// Ensure the shared step exists
+	if _, err := s.sharedStepRepo.GetByID(id); err != nil {
+		return nil, err
+	}
+
+	return s.sharedStepRepo.ListUsage(id)
+}
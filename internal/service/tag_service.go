@@ -22,19 +22,27 @@ func (s *TagService) CreateTag(tag *models.Tag) error {
 	return s.tagRepo.Create(tag)
 }
 
-// GetTagByID retrieves a tag by ID
-func (s *TagService) GetTagByID(id int64) (*models.Tag, error) {
-	return s.tagRepo.GetByID(id)
+// GetTagByID retrieves a tag of an organization by ID. Tags of other organizations are
+// reported as not found.
+func (s *TagService) GetTagByID(organizationID, id int64) (*models.Tag, error) {
+	tag, err := s.tagRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if tag.OrganizationID != organizationID {
+		return nil, repository.ErrTagNotFound
+	}
+	return tag, nil
 }
 
-// DeleteTag deletes a tag
-func (s *TagService) DeleteTag(id int64) error {
-	return s.tagRepo.Delete(id)
+// DeleteTag deletes a tag of an organization
+func (s *TagService) DeleteTag(organizationID, id int64) error {
+	return s.tagRepo.Delete(organizationID, id)
 }
 
-// ListTags retrieves all tags
-func (s *TagService) ListTags() ([]*models.Tag, error) {
-	return s.tagRepo.List()
+// ListTags retrieves all tags of an organization
+func (s *TagService) ListTags(organizationID int64) ([]*models.Tag, error) {
+	return s.tagRepo.List(organizationID)
 }
 
 // GetTagsByTestCase retrieves all tags for a test case
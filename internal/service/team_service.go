@@ -0,0 +1,123 @@
+package service
+
+import (
+	"github.com/mihaamiharu/test-case-management-be/internal/models"
+	"github.com/mihaamiharu/test-case-management-be/internal/repository"
+)
+
+// TeamService handles teams, groups of users of an organization that can be granted project
+// access as a unit
+type TeamService struct {
+	teamRepo         repository.TeamRepositoryInterface
+	organizationRepo repository.OrganizationRepositoryInterface
+}
+
+// NewTeamService creates a new team service
+func NewTeamService(teamRepo repository.TeamRepositoryInterface, organizationRepo repository.OrganizationRepositoryInterface) *TeamService {
+	return &TeamService{
+		teamRepo:         teamRepo,
+		organizationRepo: organizationRepo,
+	}
+}
+
+// Create creates a team in an organization
+func (s *TeamService) Create(organizationID, actorID int64, teamCreate *models.TeamCreate) (*models.Team, error) {
+	team := &models.Team{
+		OrganizationID: organizationID,
+		Name:           teamCreate.Name,
+		Description:    teamCreate.Description,
+		CreatedBy:      &actorID,
+	}
+	if err := s.teamRepo.Create(team); err != nil {
+		return nil, err
+	}
+	return team, nil
+}
+
+// Get retrieves a team of an organization by ID. Teams of other organizations are reported
+// as not found.
+func (s *TeamService) Get(organizationID, id int64) (*models.Team, error) {
+	team, err := s.teamRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if team.OrganizationID != organizationID {
+		return nil, repository.ErrTeamNotFound
+	}
+	return team, nil
+}
+
+// Update changes the name and description of a team
+func (s *TeamService) Update(organizationID, id int64, teamUpdate *models.TeamUpdate) (*models.Team, error) {
+	team, err := s.Get(organizationID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	team.Name = teamUpdate.Name
+	team.Description = teamUpdate.Description
+	if err := s.teamRepo.Update(team); err != nil {
+		return nil, err
+	}
+	return team, nil
+}
+
+// Delete deletes a team. Its members lose the project access they had through it.
+func (s *TeamService) Delete(organizationID, id int64) error {
+	if _, err := s.Get(organizationID, id); err != nil {
+		return err
+	}
+	return s.teamRepo.Delete(id)
+}
+
+// List lists all teams of an organization
+func (s *TeamService) List(organizationID int64) ([]*models.Team, error) {
+	return s.teamRepo.List(organizationID)
+}
+
+// ListByMember lists the teams of a user in an organization
+func (s *TeamService) ListByMember(organizationID, userID int64) ([]*models.Team, error) {
+	return s.teamRepo.ListByMember(organizationID, userID)
+}
+
+// ListMembers lists the members of a team
+func (s *TeamService) ListMembers(organizationID, teamID int64) ([]*models.TeamMember, error) {
+	if _, err := s.Get(organizationID, teamID); err != nil {
+		return nil, err
+	}
+	return s.teamRepo.ListMembers(teamID)
+}
+
+// AddMembers adds members of the organization to a team and returns its members. Users who
+// already are members of the team are skipped.
+func (s *TeamService) AddMembers(organizationID, teamID int64, userIDs []int64) ([]*models.TeamMember, error) {
+	if _, err := s.Get(organizationID, teamID); err != nil {
+		return nil, err
+	}
+
+	seen := make(map[int64]bool)
+	var unique []int64
+	for _, userID := range userIDs {
+		if seen[userID] {
+			continue
+		}
+		if _, err := s.organizationRepo.GetMember(organizationID, userID); err != nil {
+			return nil, err
+		}
+		seen[userID] = true
+		unique = append(unique, userID)
+	}
+
+	if err := s.teamRepo.AddMembers(teamID, unique); err != nil {
+		return nil, err
+	}
+	return s.teamRepo.ListMembers(teamID)
+}
+
+// RemoveMember removes a user from a team
+func (s *TeamService) RemoveMember(organizationID, teamID, userID int64) error {
+	if _, err := s.Get(organizationID, teamID); err != nil {
+		return err
+	}
+	return s.teamRepo.RemoveMember(teamID, userID)
+}
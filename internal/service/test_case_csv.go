@@ -0,0 +1,267 @@
+package service
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/mihaamiharu/test-case-management-be/internal/models"
+	"github.com/mihaamiharu/test-case-management-be/internal/repository"
+)
+
+var (
+	ErrInvalidCSV = errors.New("invalid CSV file")
+)
+
+// csvColumns are the fixed columns of a test case CSV file. Custom fields follow as cf:<name>.
+var csvColumns = []string{"id", "suite_id", "title", "description", "preconditions", "status", "priority", "steps"}
+
+// csvCustomFieldPrefix marks custom field columns in a test case CSV file
+const csvCustomFieldPrefix = "cf:"
+
+// csvSharedStepPrefix marks a step that references a shared step in the steps column
+const csvSharedStepPrefix = "shared:"
+
+// ExportTestCasesCSV writes all test cases of a project as CSV. Steps are written one per line
+// as "type|description|expected result", or "type|shared:<id>" for shared step references.
+func (s *TestCaseService) ExportTestCasesCSV(projectID int64, w io.Writer) error {
+	testCases, err := s.ListTestCasesByProject(projectID, nil)
+	if err != nil {
+		return err
+	}
+	definitions, err := s.customFieldRepo.ListDefinitions(projectID)
+	if err != nil {
+		return err
+	}
+
+	header := append([]string{}, csvColumns...)
+	for _, definition := range definitions {
+		header = append(header, csvCustomFieldPrefix+definition.Name)
+	}
+
+	writer := csv.NewWriter(w)
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+
+	for _, tc := range testCases {
+		record := []string{
+			strconv.FormatInt(tc.ID, 10),
+			strconv.FormatInt(tc.SuiteID, 10),
+			tc.Title,
+			tc.Description,
+			tc.Preconditions,
+			string(tc.Status),
+			string(tc.Priority),
+			formatCSVSteps(tc.Steps),
+		}
+		for _, definition := range definitions {
+			record = append(record, FormatCustomFieldValue(tc.CustomFields[definition.Name]))
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// ImportTestCasesCSV creates or updates test cases of a project from CSV. Rows with an id
+// update that test case and only change the columns present in the file; rows without one
+// create a new test case. Rows that fail are reported and do not stop the import.
+func (s *TestCaseService) ImportTestCasesCSV(projectID, userID int64, r io.Reader) (*models.TestCaseImportResult, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("%w: missing header row", ErrInvalidCSV)
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.TrimSpace(strings.TrimPrefix(name, "\ufeff"))] = i
+	}
+	if _, ok := columns["title"]; !ok {
+		return nil, fmt.Errorf("%w: title column is required", ErrInvalidCSV)
+	}
+
+	result := &models.TestCaseImportResult{Errors: []*models.TestCaseImportError{}}
+	for row := 2; ; row++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidCSV, err)
+		}
+
+		created, err := s.importCSVRow(projectID, userID, columns, record)
+		switch {
+		case err != nil:
+			result.Errors = append(result.Errors, &models.TestCaseImportError{Row: row, Error: err.Error()})
+		case created:
+			result.Created++
+		default:
+			result.Updated++
+		}
+	}
+
+	return result, nil
+}
+
+// importCSVRow applies a single CSV row and reports whether it created a test case
+func (s *TestCaseService) importCSVRow(projectID, userID int64, columns map[string]int, record []string) (bool, error) {
+	cell := func(name string) (string, bool) {
+		i, ok := columns[name]
+		if !ok || i >= len(record) {
+			return "", false
+		}
+		return strings.TrimSpace(record[i]), true
+	}
+
+	testCase := &models.TestCase{
+		ProjectID: projectID,
+		CreatedBy: userID,
+	}
+	idText, _ := cell("id")
+	if idText != "" {
+		id, err := strconv.ParseInt(idText, 10, 64)
+		if err != nil {
+			return false, errors.New("id must be a number")
+		}
+		existing, err := s.GetTestCaseByID(id)
+		if err != nil {
+			return false, err
+		}
+		if existing.ProjectID != projectID {
+			return false, repository.ErrTestCaseNotFound
+		}
+		testCase = existing
+	}
+	testCase.UpdatedBy = userID
+
+	if value, ok := cell("suite_id"); ok && value != "" {
+		suiteID, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return false, errors.New("suite_id must be a number")
+		}
+		testCase.SuiteID = suiteID
+	}
+	if testCase.SuiteID == 0 {
+		return false, errors.New("suite_id is required")
+	}
+
+	if value, ok := cell("title"); ok {
+		testCase.Title = value
+	}
+	if testCase.Title == "" {
+		return false, errors.New("title is required")
+	}
+	if value, ok := cell("description"); ok {
+		testCase.Description = value
+	}
+	if value, ok := cell("preconditions"); ok {
+		testCase.Preconditions = value
+	}
+
+	// Statuses and priorities are checked against the project workflow when the row is saved
+	if value, ok := cell("status"); ok && value != "" {
+		testCase.Status = models.TestCaseStatus(value)
+	}
+	if value, ok := cell("priority"); ok && value != "" {
+		testCase.Priority = models.TestCasePriority(value)
+	}
+
+	if value, ok := cell("steps"); ok {
+		steps, err := parseCSVSteps(value)
+		if err != nil {
+			return false, err
+		}
+		testCase.Steps = keepStepIdentity(testCase.Steps, steps)
+	}
+
+	customFields := make(map[string]interface{}, len(testCase.CustomFields))
+	for name, value := range testCase.CustomFields {
+		customFields[name] = value
+	}
+	for column, i := range columns {
+		name, ok := strings.CutPrefix(column, csvCustomFieldPrefix)
+		if !ok || i >= len(record) {
+			continue
+		}
+		customFields[name] = record[i]
+	}
+	testCase.CustomFields = customFields
+
+	if testCase.ID == 0 {
+		return true, s.CreateTestCase(testCase, nil)
+	}
+	return false, s.UpdateTestCase(testCase, nil)
+}
+
+// formatCSVSteps writes steps one per line for the steps column
+func formatCSVSteps(steps []*models.TestStep) string {
+	lines := make([]string, len(steps))
+	for i, step := range steps {
+		if step.SharedStepID != nil {
+			lines[i] = fmt.Sprintf("%s|%s%d", step.StepType, csvSharedStepPrefix, *step.SharedStepID)
+			continue
+		}
+		lines[i] = strings.Join([]string{string(step.StepType), step.Description, step.ExpectedResult}, "|")
+	}
+	return strings.Join(lines, "\n")
+}
+
+// parseCSVSteps reads the steps column written by formatCSVSteps
+func parseCSVSteps(text string) ([]*models.TestStep, error) {
+	steps := []*models.TestStep{}
+	for i, line := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		parts := strings.SplitN(line, "|", 3)
+		step := &models.TestStep{StepType: models.StepType(strings.ToLower(strings.TrimSpace(parts[0])))}
+		switch step.StepType {
+		case models.StepTypeGiven, models.StepTypeWhen, models.StepTypeThen, models.StepTypeAnd, models.StepTypeBut:
+		default:
+			return nil, fmt.Errorf("step %d: invalid step type %q", i+1, parts[0])
+		}
+		if len(parts) < 2 || strings.TrimSpace(parts[1]) == "" {
+			return nil, fmt.Errorf("step %d: description is required", i+1)
+		}
+
+		description := strings.TrimSpace(parts[1])
+		if ref, ok := strings.CutPrefix(description, csvSharedStepPrefix); ok && len(parts) == 2 {
+			sharedStepID, err := strconv.ParseInt(ref, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("step %d: invalid shared step reference", i+1)
+			}
+			step.SharedStepID = &sharedStepID
+		} else {
+			step.Description = description
+			if len(parts) == 3 {
+				step.ExpectedResult = strings.TrimSpace(parts[2])
+			}
+		}
+
+		steps = append(steps, step)
+	}
+	return steps, nil
+}
+
+// keepStepIdentity reuses the IDs of existing steps by position so that notes and
+// attachments stay with steps that an import rewrites
+func keepStepIdentity(existing, steps []*models.TestStep) []*models.TestStep {
+	for i, step := range steps {
+		if i < len(existing) {
+			step.ID = existing[i].ID
+		}
+	}
+	return steps
+}
@@ -1,31 +1,111 @@
 package service
 
 import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/mihaamiharu/test-case-management-be/internal/events"
 	"github.com/mihaamiharu/test-case-management-be/internal/models"
 	"github.com/mihaamiharu/test-case-management-be/internal/repository"
 )
 
+var (
+	ErrInvalidStepPosition = errors.New("step position is out of range")
+)
+
 // TestCaseService handles test case business logic
 type TestCaseService struct {
-	testCaseRepo repository.TestCaseRepositoryInterface
-	tagRepo      repository.TagRepositoryInterface
+	testCaseRepo     repository.TestCaseRepositoryInterface
+	tagRepo          repository.TagRepositoryInterface
+	sharedStepRepo   repository.SharedStepRepositoryInterface
+	reviewRepo       repository.ReviewRepositoryInterface
+	customFieldRepo  repository.CustomFieldRepositoryInterface
+	userRepo         repository.UserRepositoryInterface
+	projectRepo      repository.ProjectRepositoryInterface
+	organizationRepo repository.OrganizationRepositoryInterface
+	workflowRepo     repository.WorkflowRepositoryInterface
+	publisher        events.Publisher
 }
 
 // NewTestCaseService creates a new test case service
-func NewTestCaseService(testCaseRepo repository.TestCaseRepositoryInterface, tagRepo repository.TagRepositoryInterface) *TestCaseService {
+func NewTestCaseService(
+	testCaseRepo repository.TestCaseRepositoryInterface,
+	tagRepo repository.TagRepositoryInterface,
+	sharedStepRepo repository.SharedStepRepositoryInterface,
+	reviewRepo repository.ReviewRepositoryInterface,
+	customFieldRepo repository.CustomFieldRepositoryInterface,
+	userRepo repository.UserRepositoryInterface,
+	projectRepo repository.ProjectRepositoryInterface,
+	organizationRepo repository.OrganizationRepositoryInterface,
+	workflowRepo repository.WorkflowRepositoryInterface,
+	publisher events.Publisher,
+) *TestCaseService {
 	return &TestCaseService{
-		testCaseRepo: testCaseRepo,
-		tagRepo:      tagRepo,
+		testCaseRepo:     testCaseRepo,
+		tagRepo:          tagRepo,
+		sharedStepRepo:   sharedStepRepo,
+		reviewRepo:       reviewRepo,
+		customFieldRepo:  customFieldRepo,
+		userRepo:         userRepo,
+		projectRepo:      projectRepo,
+		organizationRepo: organizationRepo,
+		workflowRepo:     workflowRepo,
+		publisher:        publisher,
 	}
 }
 
 // CreateTestCase creates a new test case with tags
 func (s *TestCaseService) CreateTestCase(testCase *models.TestCase, tagIDs []int64) error {
+	// Shared steps can only be referenced within their own project
+	if err := s.checkSharedSteps(testCase.ProjectID, testCase.Steps); err != nil {
+		return err
+	}
+
+	// Statuses and priorities come from the project workflow
+	workflow, err := s.workflowRepo.GetWorkflow(testCase.ProjectID)
+	if err != nil {
+		return err
+	}
+	if testCase.Status == "" {
+		testCase.Status = models.StatusDraft
+	}
+	if testCase.Priority == "" {
+		testCase.Priority = workflow.DefaultPriority()
+	}
+	if err := checkStatusChange(workflow, "", testCase.Status); err != nil {
+		return err
+	}
+	if err := checkPriority(workflow, testCase.Priority); err != nil {
+		return err
+	}
+
+	// Projects that require approvals only get active test cases through review
+	if testCase.Status == models.StatusActive {
+		if err := checkActivation(s.reviewRepo, nil, testCase); err != nil {
+			return err
+		}
+	}
+
+	customValues, err := s.resolveCustomFields(testCase)
+	if err != nil {
+		return err
+	}
+
 	// Create the test case
 	if err := s.testCaseRepo.Create(testCase); err != nil {
 		return err
 	}
 
+	if err := s.customFieldRepo.SetValues(testCase.ID, customValues); err != nil {
+		return err
+	}
+
+	if err := s.recordHistory(testCase, testCase.CreatedBy, "Created test case"); err != nil {
+		return err
+	}
+
 	// Add tags if provided
 	if len(tagIDs) > 0 {
 		for _, tagID := range tagIDs {
@@ -35,6 +115,7 @@ func (s *TestCaseService) CreateTestCase(testCase *models.TestCase, tagIDs []int
 		}
 	}
 
+	s.publish(events.TestCaseCreated, testCase, testCase.CreatedBy)
 	return nil
 }
 
@@ -45,22 +126,102 @@ func (s *TestCaseService) GetTestCaseByID(id int64) (*models.TestCase, error) {
 		return nil, err
 	}
 
-	// Get tags for the test case
-	tags, err := s.tagRepo.GetTagsByTestCase(id)
-	if err != nil {
+	if err := s.loadDetails(testCase); err != nil {
+		return nil, err
+	}
+
+	if err := s.loadCustomFields(testCase.ProjectID, []*models.TestCase{testCase}); err != nil {
 		return nil, err
 	}
-	testCase.Tags = tags
 
 	return testCase, nil
 }
 
 // UpdateTestCase updates a test case with tags
 func (s *TestCaseService) UpdateTestCase(testCase *models.TestCase, tagIDs []int64) error {
+	if err := s.checkSharedSteps(testCase.ProjectID, testCase.Steps); err != nil {
+		return err
+	}
+
+	existing, err := s.testCaseRepo.GetByID(testCase.ID)
+	if err != nil {
+		return err
+	}
+
+	workflow, err := s.workflowRepo.GetWorkflow(testCase.ProjectID)
+	if err != nil {
+		return err
+	}
+	if err := checkStatusChange(workflow, existing.Status, testCase.Status); err != nil {
+		return err
+	}
+	if testCase.Priority != existing.Priority {
+		if err := checkPriority(workflow, testCase.Priority); err != nil {
+			return err
+		}
+	}
+
+	if testCase.Status != existing.Status && testCase.Status == models.StatusActive {
+		if err := checkActivation(s.reviewRepo, existing, testCase); err != nil {
+			return err
+		}
+	}
+
+	// Reviews cover a single version, so editing a reviewed test case sends it back to draft
+	if testCase.Status == existing.Status && contentChanged(existing, testCase) {
+		switch existing.Status {
+		case models.StatusInReview, models.StatusApproved:
+			testCase.Status = models.StatusDraft
+		}
+	}
+
+	// Custom fields are only replaced when the caller provides them
+	var customValues map[int64]interface{}
+	if testCase.CustomFields != nil {
+		customValues, err = s.resolveCustomFields(testCase)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Remember the attachments of steps that the update removes
+	kept := make(map[int64]bool, len(testCase.Steps))
+	for _, step := range testCase.Steps {
+		kept[step.ID] = true
+	}
+	var orphaned []*models.StepAttachment
+	for _, step := range existing.Steps {
+		if !kept[step.ID] {
+			orphaned = append(orphaned, step.Attachments...)
+		}
+	}
+
 	// Update the test case
 	if err := s.testCaseRepo.Update(testCase); err != nil {
 		return err
 	}
+	removeAttachmentFiles(orphaned)
+
+	if err := s.customFieldRepo.SetValues(testCase.ID, customValues); err != nil {
+		return err
+	}
+
+	// Updates that change none of the recorded fields add nothing to the history
+	if summary := summarizeChanges(existing, testCase); summary != "" {
+		if err := s.recordHistory(testCase, testCase.UpdatedBy, summary); err != nil {
+			return err
+		}
+	}
+
+	// Reload the steps so surviving notes and attachments are included
+	steps, err := s.testCaseRepo.GetSteps(testCase.ID)
+	if err != nil {
+		return err
+	}
+	if err := s.expandSharedSteps(steps); err != nil {
+		return err
+	}
+	testCase.Steps = steps
 
 	// Update tags if provided
 	if tagIDs != nil {
@@ -69,80 +230,162 @@ func (s *TestCaseService) UpdateTestCase(testCase *models.TestCase, tagIDs []int
 		}
 	}
 
+	s.publish(events.TestCaseUpdated, testCase, testCase.UpdatedBy)
+	return nil
+}
+
+// DeleteTestCase deletes a test case on behalf of a user
+func (s *TestCaseService) DeleteTestCase(id, userID int64) error {
+	testCase, err := s.testCaseRepo.GetByID(id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.testCaseRepo.Delete(id); err != nil {
+		return err
+	}
+
+	s.publish(events.TestCaseDeleted, testCase, userID)
 	return nil
 }
 
-// DeleteTestCase deletes a test case
-func (s *TestCaseService) DeleteTestCase(id int64) error {
-	return s.testCaseRepo.Delete(id)
+// publish announces a change to a test case to the subscribers of its project
+func (s *TestCaseService) publish(eventType events.Type, testCase *models.TestCase, actorID int64) {
+	s.publisher.Publish(&events.Event{
+		Type:      eventType,
+		ProjectID: testCase.ProjectID,
+		ActorID:   actorID,
+		Data:      testCase.ToResponse(),
+	})
 }
 
-// ListTestCasesByProject retrieves all test cases for a project
-func (s *TestCaseService) ListTestCasesByProject(projectID int64) ([]*models.TestCase, error) {
+// ListTestCasesByProject retrieves the test cases of a project, filtered and sorted
+// by the given options
+func (s *TestCaseService) ListTestCasesByProject(projectID int64, options *models.TestCaseListOptions) ([]*models.TestCase, error) {
 	testCases, err := s.testCaseRepo.ListByProject(projectID)
 	if err != nil {
 		return nil, err
 	}
 
-	// Get tags for each test case
-	for _, tc := range testCases {
-		tags, err := s.tagRepo.GetTagsByTestCase(tc.ID)
-		if err != nil {
-			return nil, err
-		}
-		tc.Tags = tags
-	}
-
-	return testCases, nil
+	return s.prepareList(projectID, testCases, options)
 }
 
-// ListTestCasesBySuite retrieves all test cases for a suite
-func (s *TestCaseService) ListTestCasesBySuite(suiteID int64) ([]*models.TestCase, error) {
+// ListTestCasesBySuite retrieves the test cases of a suite, filtered and sorted
+// by the given options
+func (s *TestCaseService) ListTestCasesBySuite(suiteID int64, options *models.TestCaseListOptions) ([]*models.TestCase, error) {
 	testCases, err := s.testCaseRepo.ListBySuite(suiteID)
 	if err != nil {
 		return nil, err
 	}
+	if len(testCases) == 0 {
+		return testCases, nil
+	}
+
+	return s.prepareList(testCases[0].ProjectID, testCases, options)
+}
 
-	// Get tags for each test case
+// prepareList loads the details of listed test cases and applies the list options
+func (s *TestCaseService) prepareList(projectID int64, testCases []*models.TestCase, options *models.TestCaseListOptions) ([]*models.TestCase, error) {
+	// Get tags and data rows for each test case
 	for _, tc := range testCases {
-		tags, err := s.tagRepo.GetTagsByTestCase(tc.ID)
-		if err != nil {
+		if err := s.loadDetails(tc); err != nil {
 			return nil, err
 		}
-		tc.Tags = tags
 	}
 
-	return testCases, nil
+	definitions, err := s.customFieldRepo.ListDefinitions(projectID)
+	if err != nil {
+		return nil, err
+	}
+	values, err := s.customFieldRepo.ListValuesByProject(projectID)
+	if err != nil {
+		return nil, err
+	}
+	for _, tc := range testCases {
+		tc.CustomFields = customFieldsByName(definitions, values[tc.ID])
+	}
+
+	if options == nil {
+		return testCases, nil
+	}
+
+	workflow, err := s.workflowRepo.GetWorkflow(projectID)
+	if err != nil {
+		return nil, err
+	}
+	return FilterAndSortTestCases(testCases, definitions, workflow, options)
 }
 
-// AddTestStep adds a step to a test case
-func (s *TestCaseService) AddTestStep(testCaseID int64, step *models.TestStep) error {
+// AddTestStep adds a step to a test case at the given 1-based position.
+// A position of 0 appends the step after the existing steps.
+func (s *TestCaseService) AddTestStep(testCaseID int64, step *models.TestStep, position int) error {
 	// Ensure the test case exists
-	_, err := s.testCaseRepo.GetByID(testCaseID)
+	testCase, err := s.testCaseRepo.GetByID(testCaseID)
 	if err != nil {
 		return err
 	}
 
-	// Get the current highest step number
-	steps, err := s.testCaseRepo.GetSteps(testCaseID)
-	if err != nil {
+	if err := s.checkSharedSteps(testCase.ProjectID, []*models.TestStep{step}); err != nil {
 		return err
 	}
 
-	// Set the step number to be the next in sequence
-	if len(steps) > 0 {
-		highestStepNumber := 0
-		for _, existingStep := range steps {
-			if existingStep.StepNumber > highestStepNumber {
-				highestStepNumber = existingStep.StepNumber
-			}
+	if position > len(testCase.Steps)+1 {
+		return ErrInvalidStepPosition
+	}
+	if position == 0 {
+		position = len(testCase.Steps) + 1
+	}
+
+	step.TestCaseID = testCaseID
+	return s.testCaseRepo.InsertStep(step, position)
+}
+
+// MoveTestStep moves a step to the given 1-based position within its test case
+func (s *TestCaseService) MoveTestStep(stepID int64, position int) ([]*models.TestStep, error) {
+	step, err := s.testCaseRepo.GetStepByID(stepID)
+	if err != nil {
+		return nil, err
+	}
+
+	steps, err := s.testCaseRepo.GetSteps(step.TestCaseID)
+	if err != nil {
+		return nil, err
+	}
+	if position < 1 || position > len(steps) {
+		return nil, ErrInvalidStepPosition
+	}
+
+	order := make([]int64, 0, len(steps))
+	for _, existingStep := range steps {
+		if existingStep.ID != stepID {
+			order = append(order, existingStep.ID)
 		}
-		step.StepNumber = highestStepNumber + 1
-	} else {
-		step.StepNumber = 1
+	}
+	order = append(order[:position-1], append([]int64{stepID}, order[position-1:]...)...)
+
+	return s.ReorderTestSteps(step.TestCaseID, order)
+}
+
+// ReorderTestSteps renumbers all steps of a test case in the given order
+func (s *TestCaseService) ReorderTestSteps(testCaseID int64, stepIDs []int64) ([]*models.TestStep, error) {
+	// Ensure the test case exists
+	if _, err := s.testCaseRepo.GetByID(testCaseID); err != nil {
+		return nil, err
+	}
+
+	if err := s.testCaseRepo.ReorderSteps(testCaseID, stepIDs); err != nil {
+		return nil, err
+	}
+
+	steps, err := s.testCaseRepo.GetSteps(testCaseID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.expandSharedSteps(steps); err != nil {
+		return nil, err
 	}
 
-	return s.testCaseRepo.CreateStep(step)
+	return steps, nil
 }
 
 // UpdateTestStep updates a test step
@@ -157,12 +400,32 @@ func (s *TestCaseService) UpdateTestStep(stepID int64, step *models.TestStep) er
 	step.StepNumber = existingStep.StepNumber
 	step.TestCaseID = existingStep.TestCaseID
 
+	if step.SharedStepID != nil {
+		testCase, err := s.testCaseRepo.GetByID(existingStep.TestCaseID)
+		if err != nil {
+			return err
+		}
+		if err := s.checkSharedSteps(testCase.ProjectID, []*models.TestStep{step}); err != nil {
+			return err
+		}
+	}
+
 	return s.testCaseRepo.UpdateStep(step)
 }
 
 // DeleteTestStep deletes a test step
 func (s *TestCaseService) DeleteTestStep(stepID int64) error {
-	return s.testCaseRepo.DeleteStep(stepID)
+	step, err := s.testCaseRepo.GetStepByID(stepID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.testCaseRepo.DeleteStep(stepID); err != nil {
+		return err
+	}
+	removeAttachmentFiles(step.Attachments)
+
+	return nil
 }
 
 // AddStepNote adds a note to a test step
@@ -176,6 +439,11 @@ func (s *TestCaseService) AddStepNote(stepID int64, note *models.StepNote) error
 	return s.testCaseRepo.CreateStepNote(note)
 }
 
+// GetStepNote retrieves a step note by ID
+func (s *TestCaseService) GetStepNote(noteID int64) (*models.StepNote, error) {
+	return s.testCaseRepo.GetStepNoteByID(noteID)
+}
+
 // DeleteStepNote deletes a step note
 func (s *TestCaseService) DeleteStepNote(noteID int64) error {
 	return s.testCaseRepo.DeleteStepNote(noteID)
@@ -203,7 +471,234 @@ func (s *TestCaseService) DeleteStepAttachment(attachmentID int64) error {
 	return s.testCaseRepo.DeleteStepAttachment(attachmentID)
 }
 
+// GetProjectID retrieves the project a test case belongs to
+func (s *TestCaseService) GetProjectID(testCaseID int64) (int64, error) {
+	return s.testCaseRepo.GetProjectID(testCaseID)
+}
+
 // GetStepByID gets a step by ID with all its data
 func (s *TestCaseService) GetStepByID(stepID int64) (*models.TestStep, error) {
-	return s.testCaseRepo.GetStepByID(stepID)
+	step, err := s.testCaseRepo.GetStepByID(stepID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.expandSharedSteps([]*models.TestStep{step}); err != nil {
+		return nil, err
+	}
+
+	return step, nil
+}
+
+// UpdateDataRows replaces the parameter data table of a test case
+func (s *TestCaseService) UpdateDataRows(testCaseID int64, rows []*models.TestCaseDataRow) error {
+	testCase, err := s.testCaseRepo.GetByID(testCaseID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.expandSharedSteps(testCase.Steps); err != nil {
+		return err
+	}
+
+	// Number the rows so validation errors can point at them
+	for i, row := range rows {
+		row.RowNumber = i + 1
+	}
+	if err := ValidateDataRows(ExtractParameters(testCase.Steps), rows); err != nil {
+		return err
+	}
+
+	return s.testCaseRepo.ReplaceDataRows(testCaseID, rows)
+}
+
+// GetScenarios expands a test case into one scenario per data row
+func (s *TestCaseService) GetScenarios(testCaseID int64) ([]*models.TestScenario, error) {
+	testCase, err := s.GetTestCaseByID(testCaseID)
+	if err != nil {
+		return nil, err
+	}
+
+	return ExpandScenarios(testCase), nil
+}
+
+// loadDetails attaches tags, expanded shared steps, parameters and data rows to a test case
+func (s *TestCaseService) loadDetails(testCase *models.TestCase) error {
+	tags, err := s.tagRepo.GetTagsByTestCase(testCase.ID)
+	if err != nil {
+		return err
+	}
+	testCase.Tags = tags
+
+	if err := s.expandSharedSteps(testCase.Steps); err != nil {
+		return err
+	}
+	testCase.Parameters = ExtractParameters(testCase.Steps)
+
+	dataRows, err := s.testCaseRepo.GetDataRows(testCase.ID)
+	if err != nil {
+		return err
+	}
+	testCase.DataRows = dataRows
+
+	return nil
+}
+
+// loadCustomFields attaches the custom field values of test cases in a project, keyed by field name
+func (s *TestCaseService) loadCustomFields(projectID int64, testCases []*models.TestCase) error {
+	definitions, err := s.customFieldRepo.ListDefinitions(projectID)
+	if err != nil {
+		return err
+	}
+
+	for _, tc := range testCases {
+		values, err := s.customFieldRepo.GetValues(tc.ID)
+		if err != nil {
+			return err
+		}
+		tc.CustomFields = customFieldsByName(definitions, values)
+	}
+
+	return nil
+}
+
+// resolveCustomFields validates the custom fields of a test case against the definitions
+// of its project. It normalizes testCase.CustomFields and returns the values to store by
+// field ID, with nil for every defined field that has no value.
+func (s *TestCaseService) resolveCustomFields(testCase *models.TestCase) (map[int64]interface{}, error) {
+	definitions, err := s.customFieldRepo.ListDefinitions(testCase.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+
+	byName := make(map[string]*models.CustomFieldDefinition, len(definitions))
+	for _, definition := range definitions {
+		byName[definition.Name] = definition
+	}
+	for name := range testCase.CustomFields {
+		if byName[name] == nil {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownCustomField, name)
+		}
+	}
+
+	values := make(map[int64]interface{}, len(definitions))
+	normalized := make(map[string]interface{}, len(definitions))
+	for _, definition := range definitions {
+		value, err := NormalizeCustomFieldValue(definition, testCase.CustomFields[definition.Name])
+		if err != nil {
+			return nil, err
+		}
+		if value == nil && definition.Required {
+			return nil, fmt.Errorf("%w: %s", ErrCustomFieldRequired, definition.Name)
+		}
+
+		if userID, ok := value.(int64); ok && definition.Type == models.CustomFieldUser {
+			if err := s.checkCustomFieldUser(definition, testCase.ProjectID, userID); err != nil {
+				return nil, err
+			}
+		}
+
+		values[definition.ID] = value
+		if value != nil {
+			normalized[definition.Name] = value
+		}
+	}
+	testCase.CustomFields = normalized
+
+	return values, nil
+}
+
+// checkCustomFieldUser checks that the user of a user field exists, is active and belongs
+// to the organization of the project, so that a value cannot refer to anyone else
+func (s *TestCaseService) checkCustomFieldUser(definition *models.CustomFieldDefinition, projectID, userID int64) error {
+	invalid := fmt.Errorf("%w: %s must be an active user of the organization", ErrInvalidCustomFieldValue, definition.Name)
+
+	user, err := s.userRepo.GetByID(userID)
+	if errors.Is(err, repository.ErrUserNotFound) {
+		return invalid
+	}
+	if err != nil {
+		return err
+	}
+	if user.IsDeactivated() {
+		return invalid
+	}
+
+	project, err := s.projectRepo.GetByID(projectID)
+	if err != nil {
+		return err
+	}
+	if _, err := s.organizationRepo.GetMember(project.OrganizationID, userID); err != nil {
+		if errors.Is(err, repository.ErrOrganizationMemberNotFound) {
+			return invalid
+		}
+		return err
+	}
+	return nil
+}
+
+// customFieldsByName converts stored values keyed by field ID to values keyed by field name
+func customFieldsByName(definitions []*models.CustomFieldDefinition, values map[int64]interface{}) map[string]interface{} {
+	fields := make(map[string]interface{}, len(values))
+	for _, definition := range definitions {
+		if value, ok := values[definition.ID]; ok {
+			fields[definition.Name] = value
+		}
+	}
+	return fields
+}
+
+// checkSharedSteps verifies that every referenced shared step exists in the given project
+func (s *TestCaseService) checkSharedSteps(projectID int64, steps []*models.TestStep) error {
+	for _, step := range steps {
+		if step.SharedStepID == nil {
+			continue
+		}
+
+		sharedStep, err := s.sharedStepRepo.GetByID(*step.SharedStepID)
+		if err != nil {
+			return err
+		}
+		if sharedStep.ProjectID != projectID {
+			return ErrSharedStepProjectMismatch
+		}
+	}
+
+	return nil
+}
+
+// expandSharedSteps attaches the current content of referenced shared steps to the steps
+func (s *TestCaseService) expandSharedSteps(steps []*models.TestStep) error {
+	cache := make(map[int64]*models.SharedStep)
+	for _, step := range steps {
+		if step.SharedStepID == nil {
+			continue
+		}
+
+		sharedStep, ok := cache[*step.SharedStepID]
+		if !ok {
+			var err error
+			sharedStep, err = s.sharedStepRepo.GetByID(*step.SharedStepID)
+			if err != nil {
+				return err
+			}
+			cache[sharedStep.ID] = sharedStep
+		}
+		step.SharedStep = sharedStep
+	}
+
+	return nil
+}
+
+// removeAttachmentFiles deletes the uploaded files of attachments whose records
+// were removed together with their step
+func removeAttachmentFiles(attachments []*models.StepAttachment) {
+	for _, attachment := range attachments {
+		if attachment.FilePath == "" {
+			continue
+		}
+		if err := os.Remove(attachment.FilePath); err != nil && !os.IsNotExist(err) {
+			log.Printf("failed to remove attachment file %s: %v", attachment.FilePath, err)
+		}
+	}
 }
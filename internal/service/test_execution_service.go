@@ -0,0 +1,208 @@
+package service
+
+import (
+	"errors"
+	"log"
+
+	"github.com/mihaamiharu/test-case-management-be/internal/events"
+	"github.com/mihaamiharu/test-case-management-be/internal/models"
+	"github.com/mihaamiharu/test-case-management-be/internal/repository"
+)
+
+var (
+	ErrTestCaseProjectMismatch = errors.New("test case belongs to a different project than the test run")
+)
+
+// TestExecutionService handles test execution business logic
+type TestExecutionService struct {
+	executionRepo   repository.TestExecutionRepositoryInterface
+	testCaseService *TestCaseService
+	publisher       events.Publisher
+}
+
+// NewTestExecutionService creates a new test execution service
+func NewTestExecutionService(
+	executionRepo repository.TestExecutionRepositoryInterface,
+	testCaseService *TestCaseService,
+	publisher events.Publisher,
+) *TestExecutionService {
+	return &TestExecutionService{
+		executionRepo:   executionRepo,
+		testCaseService: testCaseService,
+		publisher:       publisher,
+	}
+}
+
+// GetRunProjectID retrieves the project a test run belongs to
+func (s *TestExecutionService) GetRunProjectID(runID int64) (int64, error) {
+	return s.executionRepo.GetRunProjectID(runID)
+}
+
+// AssignRun assigns a test run to a user on behalf of another user, or unassigns it when
+// assigneeID is nil
+func (s *TestExecutionService) AssignRun(runID int64, assigneeID *int64, userID int64) (*models.TestRun, error) {
+	if err := s.executionRepo.AssignRun(runID, assigneeID); err != nil {
+		return nil, err
+	}
+
+	run, err := s.executionRepo.GetRun(runID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.publisher.Publish(&events.Event{
+		Type:      events.TestRunAssigned,
+		ProjectID: run.ProjectID,
+		TestRunID: runID,
+		ActorID:   userID,
+		Data:      run,
+	})
+	return run, nil
+}
+
+// AddTestCaseToRun adds a test case to a test run on behalf of a user, creating one pending
+// execution per data row (or a single execution if the case has no data rows)
+func (s *TestExecutionService) AddTestCaseToRun(runID, testCaseID, userID int64) ([]*models.TestExecution, error) {
+	projectID, err := s.executionRepo.GetRunProjectID(runID)
+	if err != nil {
+		return nil, err
+	}
+
+	testCase, err := s.testCaseService.GetTestCaseByID(testCaseID)
+	if err != nil {
+		return nil, err
+	}
+	if testCase.ProjectID != projectID {
+		return nil, ErrTestCaseProjectMismatch
+	}
+
+	scenarios := ExpandScenarios(testCase)
+	executions := make([]*models.TestExecution, len(scenarios))
+	for i, scenario := range scenarios {
+		executions[i] = &models.TestExecution{
+			TestRunID:  runID,
+			TestCaseID: testCaseID,
+			DataRowID:  scenario.DataRowID,
+			Parameters: scenario.Values,
+			Status:     models.ExecutionStatusPending,
+			Scenario:   scenario,
+		}
+	}
+
+	if err := s.executionRepo.CreateBatch(executions); err != nil {
+		return nil, err
+	}
+
+	response := make([]*models.TestExecutionResponse, len(executions))
+	for i, execution := range executions {
+		response[i] = execution.ToResponse()
+	}
+	s.publisher.Publish(&events.Event{
+		Type:      events.TestRunCaseAdded,
+		ProjectID: projectID,
+		TestRunID: runID,
+		ActorID:   userID,
+		Data:      response,
+	})
+
+	return executions, nil
+}
+
+// ListExecutionsByRun retrieves all executions of a test run
+func (s *TestExecutionService) ListExecutionsByRun(runID int64) ([]*models.TestExecution, error) {
+	if _, err := s.executionRepo.GetRunProjectID(runID); err != nil {
+		return nil, err
+	}
+
+	return s.executionRepo.ListByRun(runID)
+}
+
+// GetExecution retrieves an execution with the steps of its scenario
+func (s *TestExecutionService) GetExecution(id int64) (*models.TestExecution, error) {
+	execution, err := s.executionRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	testCase, err := s.testCaseService.GetTestCaseByID(execution.TestCaseID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Expand with the values captured when the execution was created, so later
+	// edits to the data table do not change what was executed
+	scenario := &models.TestScenario{
+		TestCaseID: testCase.ID,
+		DataRowID:  execution.DataRowID,
+		Values:     execution.Parameters,
+		Steps:      testCase.Steps,
+	}
+	if execution.Parameters != nil {
+		scenario.Steps = substituteSteps(testCase.Steps, execution.Parameters)
+	}
+	execution.Scenario = scenario
+
+	return execution, nil
+}
+
+// RecordResult records the result of an execution
+func (s *TestExecutionService) RecordResult(id int64, result *models.TestExecutionResult, userID int64) (*models.TestExecution, error) {
+	execution, err := s.executionRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	projectID, err := s.executionRepo.GetRunProjectID(execution.TestRunID)
+	if err != nil {
+		return nil, err
+	}
+
+	execution.Status = result.Status
+	execution.ExecutionTime = result.ExecutionTime
+	execution.Notes = result.Notes
+	execution.ExecutedBy = &userID
+
+	if err := s.executionRepo.UpdateResult(execution); err != nil {
+		return nil, err
+	}
+
+	s.publisher.Publish(&events.Event{
+		Type:      events.TestExecutionResult,
+		ProjectID: projectID,
+		TestRunID: execution.TestRunID,
+		ActorID:   userID,
+		Data:      execution.ToResponse(),
+	})
+	s.advanceRun(execution.TestRunID, userID)
+
+	return execution, nil
+}
+
+// advanceRun starts a planned test run at its first result and completes it once no
+// execution is pending, and announces both. The result is kept when this fails.
+func (s *TestExecutionService) advanceRun(runID, userID int64) {
+	started, err := s.executionRepo.StartRun(runID)
+	if err != nil {
+		log.Printf("failed to start test run %d: %v", runID, err)
+		return
+	}
+	completed, err := s.executionRepo.CompleteRun(runID)
+	if err != nil {
+		log.Printf("failed to complete test run %d: %v", runID, err)
+	}
+	if !started && !completed {
+		return
+	}
+
+	run, err := s.executionRepo.GetRun(runID)
+	if err != nil {
+		log.Printf("failed to publish the progress of test run %d: %v", runID, err)
+		return
+	}
+	if started {
+		s.publisher.Publish(&events.Event{Type: events.TestRunStarted, ProjectID: run.ProjectID, TestRunID: runID, ActorID: userID, Data: run})
+	}
+	if completed {
+		s.publisher.Publish(&events.Event{Type: events.TestRunCompleted, ProjectID: run.ProjectID, TestRunID: runID, ActorID: userID, Data: run})
+	}
+}
@@ -41,8 +41,3 @@ func (s *TestSuiteService) DeleteTestSuite(id int64) error {
 func (s *TestSuiteService) ListTestSuitesByProject(projectID int64) ([]*models.TestSuite, error) {
 	return s.testSuiteRepo.ListByProject(projectID)
 }
-
-// ListTestSuites retrieves all test suites
-func (s *TestSuiteService) ListTestSuites() ([]*models.TestSuite, error) {
-	return s.testSuiteRepo.List()
-}
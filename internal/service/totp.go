@@ -0,0 +1,86 @@
+package service
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Time-based one-time passwords (RFC 6238) with the parameters authenticator apps expect
+const (
+	totpPeriod     = 30 * time.Second
+	totpDigits     = 6
+	totpSecretSize = 20
+	totpSkewSteps  = 1 // codes of one step before and after are accepted for clock drift
+)
+
+// totpEncoding encodes TOTP secrets the way authenticator apps read them
+var totpEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// totpStep returns the time step of a point in time
+func totpStep(at time.Time) int64 {
+	return at.Unix() / int64(totpPeriod/time.Second)
+}
+
+// totpCode computes the code of a secret for a time step (RFC 4226 section 5.3)
+func totpCode(secret []byte, step int64) string {
+	var counter [8]byte
+	binary.BigEndian.PutUint64(counter[:], uint64(step))
+
+	mac := hmac.New(sha1.New, secret)
+	mac.Write(counter[:])
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	return fmt.Sprintf("%0*d", totpDigits, value%1000000)
+}
+
+// matchTOTP returns the time step whose code matches code, allowing for clock drift
+func matchTOTP(secret []byte, code string, at time.Time) (int64, bool) {
+	if !isTOTPCode(code) {
+		return 0, false
+	}
+
+	current := totpStep(at)
+	for step := current - totpSkewSteps; step <= current+totpSkewSteps; step++ {
+		if subtle.ConstantTimeCompare([]byte(totpCode(secret, step)), []byte(code)) == 1 {
+			return step, true
+		}
+	}
+	return 0, false
+}
+
+// isTOTPCode reports whether code looks like a TOTP code rather than a recovery code
+func isTOTPCode(code string) bool {
+	if len(code) != totpDigits {
+		return false
+	}
+	_, err := strconv.Atoi(code)
+	return err == nil
+}
+
+// totpProvisioningURI builds the otpauth:// URI that authenticator apps scan as a QR code
+func totpProvisioningURI(issuer, account string, secret []byte) string {
+	label := url.PathEscape(issuer + ":" + account)
+	query := url.Values{
+		"secret":    {totpEncoding.EncodeToString(secret)},
+		"issuer":    {issuer},
+		"algorithm": {"SHA1"},
+		"digits":    {strconv.Itoa(totpDigits)},
+		"period":    {strconv.Itoa(int(totpPeriod / time.Second))},
+	}
+	return "otpauth://totp/" + label + "?" + query.Encode()
+}
+
+// normalizeTwoFactorCode removes the spaces and dashes users type into codes
+func normalizeTwoFactorCode(code string) string {
+	return strings.ToLower(strings.NewReplacer(" ", "", "-", "").Replace(strings.TrimSpace(code)))
+}
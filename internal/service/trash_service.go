@@ -0,0 +1,107 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/mihaamiharu/test-case-management-be/internal/models"
+	"github.com/mihaamiharu/test-case-management-be/internal/repository"
+)
+
+// TrashService handles listing, restoring and purging deleted projects, test suites and test cases
+type TrashService struct {
+	trashRepo repository.TrashRepositoryInterface
+	retention time.Duration
+}
+
+// NewTrashService creates a new trash service. Items are purged once they have been in
+// the trash for longer than the retention period.
+func NewTrashService(trashRepo repository.TrashRepositoryInterface, retention time.Duration) *TrashService {
+	return &TrashService{
+		trashRepo: trashRepo,
+		retention: retention,
+	}
+}
+
+// GetItem retrieves a trashed project, test suite or test case
+func (s *TrashService) GetItem(entityType string, id int64) (*models.TrashItem, error) {
+	item, err := s.trashRepo.GetItem(entityType, id)
+	if err != nil {
+		return nil, err
+	}
+	s.setPurgeAt(item)
+	return item, nil
+}
+
+// ListProjectTrash retrieves the trashed test suites and test cases of a project
+func (s *TrashService) ListProjectTrash(projectID int64) ([]*models.TrashItem, error) {
+	items, err := s.trashRepo.ListByProject(projectID)
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range items {
+		s.setPurgeAt(item)
+	}
+	return items, nil
+}
+
+// ListTrashedProjects retrieves the trashed projects of an organization for an owner, or of
+// every owner when ownerID is 0
+func (s *TrashService) ListTrashedProjects(organizationID, ownerID int64) ([]*models.TrashItem, error) {
+	items, err := s.trashRepo.ListProjects(organizationID, ownerID)
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range items {
+		s.setPurgeAt(item)
+	}
+	return items, nil
+}
+
+// Restore takes a project, test suite or test case out of the trash together with the
+// children that were deleted with it
+func (s *TrashService) Restore(entityType string, id int64) error {
+	switch entityType {
+	case models.TrashProject:
+		return s.trashRepo.RestoreProject(id)
+	case models.TrashTestSuite:
+		return s.trashRepo.RestoreTestSuite(id)
+	case models.TrashTestCase:
+		return s.trashRepo.RestoreTestCase(id)
+	}
+	return fmt.Errorf("unknown trash entity type: %s", entityType)
+}
+
+// PurgeExpired permanently removes the items that have been in the trash longer than the retention period
+func (s *TrashService) PurgeExpired() (*models.PurgeResult, error) {
+	return s.trashRepo.PurgeDeletedBefore(time.Now().Add(-s.retention))
+}
+
+// RunPurgeJob purges expired items from the trash at every interval until the context is done
+func (s *TrashService) RunPurgeJob(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		purged, err := s.PurgeExpired()
+		if err != nil {
+			log.Printf("failed to purge trash: %v", err)
+		} else if purged.Projects+purged.TestSuites+purged.TestCases > 0 {
+			log.Printf("purged %d projects, %d test suites and %d test cases from the trash",
+				purged.Projects, purged.TestSuites, purged.TestCases)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// setPurgeAt fills in when a trashed item will be purged
+func (s *TrashService) setPurgeAt(item *models.TrashItem) {
+	item.PurgeAt = item.DeletedAt.Add(s.retention)
+}
@@ -0,0 +1,370 @@
+package service
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/mihaamiharu/test-case-management-be/internal/config"
+	"github.com/mihaamiharu/test-case-management-be/internal/models"
+	"github.com/mihaamiharu/test-case-management-be/internal/repository"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var (
+	ErrTwoFactorNotSetUp         = errors.New("two-factor authentication has not been set up")
+	ErrTwoFactorNotEnabled       = errors.New("two-factor authentication is not enabled")
+	ErrTwoFactorAlreadyEnabled   = errors.New("two-factor authentication is already enabled")
+	ErrTwoFactorRequired         = errors.New("two-factor authentication is required for your role and cannot be disabled")
+	ErrInvalidTwoFactorCode      = errors.New("invalid two-factor authentication code")
+	ErrInvalidTwoFactorChallenge = errors.New("invalid or expired two-factor login; log in again")
+)
+
+// recoveryCodeCount is the number of recovery codes generated at a time
+const recoveryCodeCount = 10
+
+// twoFactorChallengePurpose marks the signed tokens that carry a login waiting for its code
+const twoFactorChallengePurpose = "two_factor_login"
+
+// TwoFactorService handles two-factor authentication with time-based one-time passwords.
+// Users enroll by adding a secret to an authenticator app and confirming it with a code;
+// recovery codes let them log in without the app.
+type TwoFactorService struct {
+	twoFactorRepo   repository.TwoFactorRepositoryInterface
+	userRepo        repository.UserRepositoryInterface
+	issuer          string
+	requiredRoles   map[models.Role]bool
+	secretKey       []byte
+	challengeKey    []byte
+	challengeExpiry time.Duration
+}
+
+// NewTwoFactorService creates a new two-factor authentication service
+func NewTwoFactorService(
+	twoFactorRepo repository.TwoFactorRepositoryInterface,
+	userRepo repository.UserRepositoryInterface,
+	cfg *config.Config,
+) *TwoFactorService {
+	secretKey := sha256.Sum256([]byte("two-factor-secret:" + cfg.JWTSecret))
+	if cfg.TwoFactor.EncryptionKey != "" {
+		secretKey = sha256.Sum256([]byte(cfg.TwoFactor.EncryptionKey))
+	}
+	challengeKey := sha256.Sum256([]byte("two-factor-challenge:" + cfg.JWTSecret))
+
+	requiredRoles := make(map[models.Role]bool)
+	for _, role := range strings.Split(cfg.TwoFactor.RequiredRoles, ",") {
+		if role = strings.TrimSpace(role); role != "" {
+			requiredRoles[models.Role(role)] = true
+		}
+	}
+
+	return &TwoFactorService{
+		twoFactorRepo:   twoFactorRepo,
+		userRepo:        userRepo,
+		issuer:          cfg.TwoFactor.Issuer,
+		requiredRoles:   requiredRoles,
+		secretKey:       secretKey[:],
+		challengeKey:    challengeKey[:],
+		challengeExpiry: cfg.TwoFactor.ChallengeExpiry,
+	}
+}
+
+// Status reports whether a user has enabled two-factor authentication and must use it
+func (s *TwoFactorService) Status(user *models.User) (*models.TwoFactorStatus, error) {
+	enabled, err := s.IsEnabled(user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &models.TwoFactorStatus{
+		Enabled:  enabled,
+		Required: s.IsRequired(user),
+	}
+	if enabled {
+		if status.RecoveryCodesRemaining, err = s.twoFactorRepo.CountRecoveryCodes(user.ID); err != nil {
+			return nil, err
+		}
+	}
+	return status, nil
+}
+
+// Setup generates a new TOTP secret for a user. It takes effect once Enable confirms it;
+// setting up again before that replaces the secret.
+func (s *TwoFactorService) Setup(user *models.User) (*models.TwoFactorSetupResponse, error) {
+	enabled, err := s.IsEnabled(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if enabled {
+		return nil, ErrTwoFactorAlreadyEnabled
+	}
+
+	secret := make([]byte, totpSecretSize)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, err
+	}
+	encrypted, err := s.encryptSecret(secret)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.twoFactorRepo.SaveSecret(user.ID, encrypted); err != nil {
+		return nil, err
+	}
+
+	return &models.TwoFactorSetupResponse{
+		Secret:          totpEncoding.EncodeToString(secret),
+		ProvisioningURI: totpProvisioningURI(s.issuer, user.Email, secret),
+	}, nil
+}
+
+// Enable turns on two-factor authentication once the user proves its authenticator app
+// produces codes for the new secret, and returns the first recovery codes
+func (s *TwoFactorService) Enable(user *models.User, code string) ([]string, error) {
+	twoFactor, err := s.twoFactorRepo.Get(user.ID)
+	if err != nil {
+		if errors.Is(err, repository.ErrTwoFactorNotFound) {
+			return nil, ErrTwoFactorNotSetUp
+		}
+		return nil, err
+	}
+	if twoFactor.IsEnabled() {
+		return nil, ErrTwoFactorAlreadyEnabled
+	}
+
+	if err := s.checkTOTP(twoFactor, normalizeTwoFactorCode(code)); err != nil {
+		return nil, err
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.twoFactorRepo.Enable(user.ID, hashes); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// Disable turns off two-factor authentication after checking the password of the user and
+// a code. Users whose role requires two-factor authentication cannot disable it.
+func (s *TwoFactorService) Disable(user *models.User, request *models.TwoFactorDisableRequest) error {
+	if s.IsRequired(user) {
+		return ErrTwoFactorRequired
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(request.Password)); err != nil {
+		return ErrIncorrectPassword
+	}
+	if err := s.Verify(user.ID, request.Code); err != nil {
+		return err
+	}
+
+	return s.twoFactorRepo.Delete(user.ID)
+}
+
+// Reset turns off two-factor authentication of a user on behalf of an administrator, such
+// as after the user lost both its authenticator app and its recovery codes
+func (s *TwoFactorService) Reset(userID int64) error {
+	if _, err := s.userRepo.GetByID(userID); err != nil {
+		return err
+	}
+
+	enabled, err := s.IsEnabled(userID)
+	if err != nil {
+		return err
+	}
+	if !enabled {
+		return ErrTwoFactorNotEnabled
+	}
+	return s.twoFactorRepo.Delete(userID)
+}
+
+// RegenerateRecoveryCodes replaces the recovery codes of a user after checking a code
+func (s *TwoFactorService) RegenerateRecoveryCodes(user *models.User, code string) ([]string, error) {
+	if err := s.Verify(user.ID, code); err != nil {
+		return nil, err
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.twoFactorRepo.ReplaceRecoveryCodes(user.ID, hashes); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// Verify checks a TOTP code or an unused recovery code of a user with two-factor
+// authentication enabled. Each code is accepted once.
+func (s *TwoFactorService) Verify(userID int64, code string) error {
+	twoFactor, err := s.twoFactorRepo.Get(userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrTwoFactorNotFound) {
+			return ErrTwoFactorNotEnabled
+		}
+		return err
+	}
+	if !twoFactor.IsEnabled() {
+		return ErrTwoFactorNotEnabled
+	}
+
+	code = normalizeTwoFactorCode(code)
+	if isTOTPCode(code) {
+		return s.checkTOTP(twoFactor, code)
+	}
+
+	err = s.twoFactorRepo.UseRecoveryCode(userID, hashToken(code))
+	if errors.Is(err, repository.ErrRecoveryCodeNotFound) {
+		return ErrInvalidTwoFactorCode
+	}
+	return err
+}
+
+// IsEnabled reports whether a user has enabled two-factor authentication
+func (s *TwoFactorService) IsEnabled(userID int64) (bool, error) {
+	twoFactor, err := s.twoFactorRepo.Get(userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrTwoFactorNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	return twoFactor.IsEnabled(), nil
+}
+
+// IsRequired reports whether the role of a user requires two-factor authentication
+func (s *TwoFactorService) IsRequired(user *models.User) bool {
+	return s.requiredRoles[user.Role]
+}
+
+// RequiresSetup reports whether a user must enable two-factor authentication before doing
+// anything else
+func (s *TwoFactorService) RequiresSetup(user *models.User) (bool, error) {
+	if !s.IsRequired(user) {
+		return false, nil
+	}
+	enabled, err := s.IsEnabled(user.ID)
+	return !enabled, err
+}
+
+// IssueChallenge signs a short-lived token that lets a user whose password was checked
+// finish logging in with a code
+func (s *TwoFactorService) IssueChallenge(user *models.User) (*models.TwoFactorChallenge, error) {
+	expiresAt := time.Now().Add(s.challengeExpiry)
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"sub":     strconv.FormatInt(user.ID, 10),
+		"purpose": twoFactorChallengePurpose,
+		"exp":     expiresAt.Unix(),
+	}).SignedString(s.challengeKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.TwoFactorChallenge{
+		ChallengeToken: token,
+		ExpiresAt:      expiresAt,
+	}, nil
+}
+
+// ParseChallenge returns the ID of the user a challenge token was issued to
+func (s *TwoFactorService) ParseChallenge(challengeToken string) (int64, error) {
+	claims := jwt.MapClaims{}
+	_, err := jwt.ParseWithClaims(challengeToken, claims, func(token *jwt.Token) (interface{}, error) {
+		return s.challengeKey, nil
+	}, jwt.WithValidMethods([]string{"HS256"}), jwt.WithExpirationRequired())
+	if err != nil || claims["purpose"] != twoFactorChallengePurpose {
+		return 0, ErrInvalidTwoFactorChallenge
+	}
+
+	subject, _ := claims["sub"].(string)
+	userID, err := strconv.ParseInt(subject, 10, 64)
+	if err != nil {
+		return 0, ErrInvalidTwoFactorChallenge
+	}
+	return userID, nil
+}
+
+// checkTOTP checks a TOTP code and records its time step so it cannot be used again
+func (s *TwoFactorService) checkTOTP(twoFactor *models.TwoFactor, code string) error {
+	secret, err := s.decryptSecret(twoFactor.SecretEncrypted)
+	if err != nil {
+		return err
+	}
+
+	step, ok := matchTOTP(secret, code, time.Now())
+	if !ok || step <= twoFactor.LastUsedStep {
+		return ErrInvalidTwoFactorCode
+	}
+
+	err = s.twoFactorRepo.UseStep(twoFactor.UserID, step)
+	if errors.Is(err, repository.ErrTOTPStepUsed) {
+		return ErrInvalidTwoFactorCode
+	}
+	return err
+}
+
+// encryptSecret encrypts a TOTP secret with AES-GCM for storage
+func (s *TwoFactorService) encryptSecret(secret []byte) (string, error) {
+	gcm, err := s.cipher()
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(gcm.Seal(nonce, nonce, secret, nil)), nil
+}
+
+// decryptSecret decrypts a TOTP secret encrypted by encryptSecret
+func (s *TwoFactorService) decryptSecret(encrypted string) ([]byte, error) {
+	gcm, err := s.cipher()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := base64.StdEncoding.DecodeString(encrypted)
+	if err != nil || len(data) < gcm.NonceSize() {
+		return nil, errors.New("failed to decrypt TOTP secret")
+	}
+	secret, err := gcm.Open(nil, data[:gcm.NonceSize()], data[gcm.NonceSize():], nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt TOTP secret: %v", err)
+	}
+	return secret, nil
+}
+
+// cipher returns the AES-GCM cipher TOTP secrets are encrypted with
+func (s *TwoFactorService) cipher() (cipher.AEAD, error) {
+	block, err := aes.NewCipher(s.secretKey)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// newRecoveryCodes generates recovery codes formatted as xxxxx-xxxxx and their hashes
+func newRecoveryCodes() ([]string, []string, error) {
+	codes := make([]string, recoveryCodeCount)
+	hashes := make([]string, recoveryCodeCount)
+	for i := range codes {
+		b := make([]byte, 5)
+		if _, err := rand.Read(b); err != nil {
+			return nil, nil, err
+		}
+		code := hex.EncodeToString(b)
+		codes[i] = code[:5] + "-" + code[5:]
+		hashes[i] = hashToken(code)
+	}
+	return codes, hashes, nil
+}
@@ -0,0 +1,255 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mihaamiharu/test-case-management-be/internal/config"
+	"github.com/mihaamiharu/test-case-management-be/internal/models"
+	"github.com/mihaamiharu/test-case-management-be/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// fakeTwoFactorRepository keeps TOTP enrollments and recovery codes in memory
+type fakeTwoFactorRepository struct {
+	enrollments   map[int64]*models.TwoFactor
+	recoveryCodes map[int64]map[string]bool // code hash -> used
+}
+
+func newFakeTwoFactorRepository() *fakeTwoFactorRepository {
+	return &fakeTwoFactorRepository{
+		enrollments:   map[int64]*models.TwoFactor{},
+		recoveryCodes: map[int64]map[string]bool{},
+	}
+}
+
+func (r *fakeTwoFactorRepository) Get(userID int64) (*models.TwoFactor, error) {
+	if twoFactor, ok := r.enrollments[userID]; ok {
+		copied := *twoFactor
+		return &copied, nil
+	}
+	return nil, repository.ErrTwoFactorNotFound
+}
+
+func (r *fakeTwoFactorRepository) SaveSecret(userID int64, secretEncrypted string) error {
+	r.enrollments[userID] = &models.TwoFactor{UserID: userID, SecretEncrypted: secretEncrypted, CreatedAt: time.Now()}
+	return nil
+}
+
+func (r *fakeTwoFactorRepository) Enable(userID int64, recoveryCodeHashes []string) error {
+	twoFactor, ok := r.enrollments[userID]
+	if !ok {
+		return repository.ErrTwoFactorNotFound
+	}
+	now := time.Now()
+	twoFactor.EnabledAt = &now
+	return r.ReplaceRecoveryCodes(userID, recoveryCodeHashes)
+}
+
+func (r *fakeTwoFactorRepository) Delete(userID int64) error {
+	delete(r.enrollments, userID)
+	delete(r.recoveryCodes, userID)
+	return nil
+}
+
+func (r *fakeTwoFactorRepository) UseStep(userID, step int64) error {
+	twoFactor := r.enrollments[userID]
+	if twoFactor.LastUsedStep >= step {
+		return repository.ErrTOTPStepUsed
+	}
+	twoFactor.LastUsedStep = step
+	return nil
+}
+
+func (r *fakeTwoFactorRepository) ReplaceRecoveryCodes(userID int64, codeHashes []string) error {
+	r.recoveryCodes[userID] = map[string]bool{}
+	for _, hash := range codeHashes {
+		r.recoveryCodes[userID][hash] = false
+	}
+	return nil
+}
+
+func (r *fakeTwoFactorRepository) UseRecoveryCode(userID int64, codeHash string) error {
+	used, ok := r.recoveryCodes[userID][codeHash]
+	if !ok || used {
+		return repository.ErrRecoveryCodeNotFound
+	}
+	r.recoveryCodes[userID][codeHash] = true
+	return nil
+}
+
+func (r *fakeTwoFactorRepository) CountRecoveryCodes(userID int64) (int, error) {
+	count := 0
+	for _, used := range r.recoveryCodes[userID] {
+		if !used {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func newTestTwoFactorService(t *testing.T) (*TwoFactorService, *AuthService, *fakeUserRepository) {
+	hash, err := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.MinCost)
+	require.NoError(t, err)
+
+	users := &fakeUserRepository{users: map[int64]*models.User{
+		1: {ID: 1, Email: "alice@example.com", PasswordHash: string(hash), Role: models.RoleUser},
+		2: {ID: 2, Email: "root@example.com", PasswordHash: string(hash), Role: models.RoleAdmin},
+	}}
+	cfg := &config.Config{
+		JWTSecret:          "secret",
+		JWTAccessExpiry:    15 * time.Minute,
+		RefreshTokenExpiry: time.Hour,
+		TwoFactor: config.TwoFactorConfig{
+			Issuer:          "TCM",
+			RequiredRoles:   "admin",
+			ChallengeExpiry: 5 * time.Minute,
+		},
+	}
+
+	twoFactor := NewTwoFactorService(newFakeTwoFactorRepository(), users, cfg)
+	return twoFactor, NewAuthService(users, newFakeSessionRepository(), twoFactor, cfg), users
+}
+
+// enableTwoFactor enrolls a user and returns its TOTP secret and recovery codes
+func enableTwoFactor(t *testing.T, s *TwoFactorService, user *models.User) ([]byte, []string) {
+	setup, err := s.Setup(user)
+	require.NoError(t, err)
+	secret, err := totpEncoding.DecodeString(setup.Secret)
+	require.NoError(t, err)
+
+	codes, err := s.Enable(user, totpCode(secret, totpStep(time.Now())))
+	require.NoError(t, err)
+	return secret, codes
+}
+
+func TestTOTPCode(t *testing.T) {
+	// Test case: the SHA-1 test vectors of RFC 6238, truncated to six digits
+	secret := []byte("12345678901234567890")
+	assert.Equal(t, "287082", totpCode(secret, totpStep(time.Unix(59, 0))))
+	assert.Equal(t, "081804", totpCode(secret, totpStep(time.Unix(1111111109, 0))))
+	assert.Equal(t, "005924", totpCode(secret, totpStep(time.Unix(1234567890, 0))))
+
+	// Test case: codes of the neighbouring steps are accepted for clock drift
+	now := time.Unix(1234567890, 0)
+	step, ok := matchTOTP(secret, totpCode(secret, totpStep(now)-1), now)
+	assert.True(t, ok)
+	assert.Equal(t, totpStep(now)-1, step)
+	_, ok = matchTOTP(secret, totpCode(secret, totpStep(now)+2), now)
+	assert.False(t, ok)
+}
+
+func TestTwoFactorService_Enrollment(t *testing.T) {
+	s, _, users := newTestTwoFactorService(t)
+	alice := users.users[1]
+
+	// Test case: enabling needs a set up secret
+	_, err := s.Enable(alice, "123456")
+	assert.Equal(t, ErrTwoFactorNotSetUp, err)
+
+	setup, err := s.Setup(alice)
+	require.NoError(t, err)
+	assert.Contains(t, setup.ProvisioningURI, "otpauth://totp/TCM:alice@example.com?")
+	assert.Contains(t, setup.ProvisioningURI, "secret="+setup.Secret)
+	secret, err := totpEncoding.DecodeString(setup.Secret)
+	require.NoError(t, err)
+
+	// Test case: a wrong code does not enable two-factor authentication
+	current := totpStep(time.Now())
+	_, err = s.Enable(alice, totpCode(secret, current+5))
+	assert.Equal(t, ErrInvalidTwoFactorCode, err)
+
+	codes, err := s.Enable(alice, totpCode(secret, current))
+	require.NoError(t, err)
+	assert.Len(t, codes, recoveryCodeCount)
+
+	status, err := s.Status(alice)
+	require.NoError(t, err)
+	assert.Equal(t, &models.TwoFactorStatus{Enabled: true, RecoveryCodesRemaining: recoveryCodeCount}, status)
+
+	// Test case: an enabled enrollment cannot be replaced
+	_, err = s.Setup(alice)
+	assert.Equal(t, ErrTwoFactorAlreadyEnabled, err)
+
+	// Test case: a code cannot be used twice
+	assert.Equal(t, ErrInvalidTwoFactorCode, s.Verify(alice.ID, totpCode(secret, current)))
+	assert.NoError(t, s.Verify(alice.ID, totpCode(secret, current+1)))
+
+	// Test case: recovery codes are accepted once, with or without the dash
+	assert.NoError(t, s.Verify(alice.ID, codes[0]))
+	assert.Equal(t, ErrInvalidTwoFactorCode, s.Verify(alice.ID, codes[0]))
+	assert.NoError(t, s.Verify(alice.ID, " "+codes[1][:5]+codes[1][6:]))
+
+	// Test case: regenerating replaces the old recovery codes
+	fresh, err := s.RegenerateRecoveryCodes(alice, codes[2])
+	require.NoError(t, err)
+	assert.Equal(t, ErrInvalidTwoFactorCode, s.Verify(alice.ID, codes[3]))
+	assert.NoError(t, s.Verify(alice.ID, fresh[0]))
+
+	// Test case: disabling needs the password and a code
+	err = s.Disable(alice, &models.TwoFactorDisableRequest{Password: "wrong", Code: fresh[1]})
+	assert.Equal(t, ErrIncorrectPassword, err)
+	require.NoError(t, s.Disable(alice, &models.TwoFactorDisableRequest{Password: "password123", Code: fresh[1]}))
+	enabled, err := s.IsEnabled(alice.ID)
+	require.NoError(t, err)
+	assert.False(t, enabled)
+}
+
+func TestTwoFactorService_Login(t *testing.T) {
+	s, auth, users := newTestTwoFactorService(t)
+	secret, codes := enableTwoFactor(t, s, users.users[1])
+
+	// Test case: the password only issues a challenge
+	tokens, challenge, err := auth.Login("alice@example.com", "password123", SessionClient{})
+	require.NoError(t, err)
+	assert.Nil(t, tokens)
+	require.NotNil(t, challenge)
+
+	// Test case: a wrong code or a forged challenge does not start a session
+	_, _, err = auth.VerifyTwoFactor(challenge.ChallengeToken, totpCode(secret, totpStep(time.Now())+5), SessionClient{})
+	assert.Equal(t, ErrInvalidTwoFactorCode, err)
+	_, _, err = auth.VerifyTwoFactor("forged", codes[0], SessionClient{})
+	assert.Equal(t, ErrInvalidTwoFactorChallenge, err)
+
+	tokens, user, err := auth.VerifyTwoFactor(challenge.ChallengeToken, totpCode(secret, totpStep(time.Now())+1), SessionClient{})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), user.ID)
+	assert.NotEmpty(t, tokens.AccessToken)
+
+	tokens, _, err = auth.VerifyTwoFactor(challenge.ChallengeToken, codes[0], SessionClient{})
+	require.NoError(t, err)
+	assert.NotEmpty(t, tokens.AccessToken)
+
+	// Test case: users without two-factor authentication get tokens right away
+	require.NoError(t, s.Reset(1))
+	tokens, challenge, err = auth.Login("alice@example.com", "password123", SessionClient{})
+	require.NoError(t, err)
+	assert.Nil(t, challenge)
+	assert.NotEmpty(t, tokens.AccessToken)
+}
+
+func TestTwoFactorService_RequiredRoles(t *testing.T) {
+	s, auth, users := newTestTwoFactorService(t)
+	alice, root := users.users[1], users.users[2]
+
+	// Test case: only roles of the policy must set up two-factor authentication
+	required, err := auth.RequiresTwoFactorSetup(alice)
+	require.NoError(t, err)
+	assert.False(t, required)
+	required, err = auth.RequiresTwoFactorSetup(root)
+	require.NoError(t, err)
+	assert.True(t, required)
+
+	_, codes := enableTwoFactor(t, s, root)
+	required, err = auth.RequiresTwoFactorSetup(root)
+	require.NoError(t, err)
+	assert.False(t, required)
+
+	// Test case: users of those roles cannot disable it, but an administrator can reset it
+	err = s.Disable(root, &models.TwoFactorDisableRequest{Password: "password123", Code: codes[0]})
+	assert.Equal(t, ErrTwoFactorRequired, err)
+	require.NoError(t, s.Reset(root.ID))
+	assert.Equal(t, ErrTwoFactorNotEnabled, s.Reset(root.ID))
+}
@@ -0,0 +1,193 @@
+package service
+
+import (
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/mihaamiharu/test-case-management-be/internal/config"
+	"github.com/mihaamiharu/test-case-management-be/internal/models"
+	"github.com/mihaamiharu/test-case-management-be/internal/repository"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var (
+	ErrCannotModifySelf   = errors.New("administrators cannot change their own role or deactivate themselves")
+	ErrInvalidUserStatus  = errors.New("status must be active or deactivated")
+	ErrIncorrectPassword  = errors.New("current password is incorrect")
+	ErrPasswordUnchanged  = errors.New("new password must differ from the current password")
+	ErrUserNotDeactivated = errors.New("user is not deactivated")
+)
+
+const (
+	defaultUserPageSize = 50
+	maxUserPageSize     = 200
+)
+
+// UserService handles user administration and self-service profile changes
+type UserService struct {
+	userRepo       repository.UserRepositoryInterface
+	sessionRepo    repository.SessionRepositoryInterface
+	accountService *AccountService
+	policy         *PasswordPolicy
+	accessExpiry   time.Duration
+}
+
+// NewUserService creates a new user service
+func NewUserService(
+	userRepo repository.UserRepositoryInterface,
+	sessionRepo repository.SessionRepositoryInterface,
+	accountService *AccountService,
+	cfg *config.Config,
+) *UserService {
+	return &UserService{
+		userRepo:       userRepo,
+		sessionRepo:    sessionRepo,
+		accountService: accountService,
+		policy:         NewPasswordPolicy(cfg.Accounts.PasswordMinLength),
+		accessExpiry:   cfg.JWTAccessExpiry,
+	}
+}
+
+// List retrieves a page of users matching a filter, ordered by username
+func (s *UserService) List(filter *models.UserFilter) ([]*models.User, error) {
+	switch filter.Status {
+	case "", models.UserStatusActive, models.UserStatusDeactivated:
+	default:
+		return nil, ErrInvalidUserStatus
+	}
+
+	if filter.Limit <= 0 {
+		filter.Limit = defaultUserPageSize
+	}
+	if filter.Limit > maxUserPageSize {
+		filter.Limit = maxUserPageSize
+	}
+	if filter.Offset < 0 {
+		filter.Offset = 0
+	}
+
+	return s.userRepo.List(filter)
+}
+
+// Get retrieves a user by ID
+func (s *UserService) Get(id int64) (*models.User, error) {
+	return s.userRepo.GetByID(id)
+}
+
+// UpdateRole changes the role of a user on behalf of an administrator
+func (s *UserService) UpdateRole(actorID, id int64, role models.Role) (*models.User, error) {
+	if actorID == id {
+		return nil, ErrCannotModifySelf
+	}
+
+	if err := s.userRepo.UpdateRole(id, role); err != nil {
+		return nil, err
+	}
+	return s.userRepo.GetByID(id)
+}
+
+// Deactivate prevents a user from authenticating and ends all of its sessions.
+// Its projects, test cases and results are kept.
+func (s *UserService) Deactivate(actorID, id int64) (*models.User, error) {
+	if actorID == id {
+		return nil, ErrCannotModifySelf
+	}
+
+	if err := s.userRepo.SetDeactivated(id, true); err != nil {
+		return nil, err
+	}
+	if err := s.revokeSessions(id, 0); err != nil {
+		return nil, err
+	}
+	return s.userRepo.GetByID(id)
+}
+
+// Reactivate allows a deactivated user to authenticate again
+func (s *UserService) Reactivate(id int64) (*models.User, error) {
+	user, err := s.userRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if !user.IsDeactivated() {
+		return nil, ErrUserNotDeactivated
+	}
+
+	if err := s.userRepo.SetDeactivated(id, false); err != nil {
+		return nil, err
+	}
+	return s.userRepo.GetByID(id)
+}
+
+// ForcePasswordReset ends all sessions of a user and requires it to change its password
+// after logging in again
+func (s *UserService) ForcePasswordReset(id int64) (*models.User, error) {
+	if err := s.userRepo.SetPasswordResetRequired(id, true); err != nil {
+		return nil, err
+	}
+	if err := s.revokeSessions(id, 0); err != nil {
+		return nil, err
+	}
+	return s.userRepo.GetByID(id)
+}
+
+// UpdateProfile changes the username and email of the current user. A new email address
+// must be verified again.
+func (s *UserService) UpdateProfile(id int64, profileUpdate *models.UserProfileUpdate) (*models.User, error) {
+	user, err := s.userRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	emailChanged := !strings.EqualFold(user.Email, profileUpdate.Email)
+	user.Username = profileUpdate.Username
+	user.Email = profileUpdate.Email
+	if emailChanged {
+		user.EmailVerifiedAt = nil
+	}
+	if err := s.userRepo.UpdateProfile(user); err != nil {
+		return nil, err
+	}
+
+	if emailChanged {
+		if err := s.accountService.SendVerification(user); err != nil {
+			log.Printf("failed to send verification email to user %d: %v", user.ID, err)
+		}
+	}
+	return user, nil
+}
+
+// ChangePassword changes the password of the current user, clears a required password
+// reset and ends the other sessions of the user
+func (s *UserService) ChangePassword(id, sessionID int64, passwordChange *models.PasswordChange) error {
+	user, err := s.userRepo.GetByID(id)
+	if err != nil {
+		return err
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(passwordChange.CurrentPassword)); err != nil {
+		return ErrIncorrectPassword
+	}
+	if passwordChange.NewPassword == passwordChange.CurrentPassword {
+		return ErrPasswordUnchanged
+	}
+	if err := s.policy.Check(passwordChange.NewPassword, user); err != nil {
+		return err
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(passwordChange.NewPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+	if err := s.userRepo.UpdatePassword(id, string(hashedPassword)); err != nil {
+		return err
+	}
+
+	return s.revokeSessions(id, sessionID)
+}
+
+// revokeSessions ends the sessions of a user except exceptSessionID (0 for none)
+func (s *UserService) revokeSessions(userID, exceptSessionID int64) error {
+	return s.sessionRepo.RevokeAllByUser(userID, exceptSessionID, time.Now().Add(s.accessExpiry))
+}
@@ -0,0 +1,108 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mihaamiharu/test-case-management-be/internal/config"
+	"github.com/mihaamiharu/test-case-management-be/internal/mail"
+	"github.com/mihaamiharu/test-case-management-be/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func newTestUserService(t *testing.T) (*UserService, *AuthService, *fakeSessionRepository) {
+	hash, err := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.MinCost)
+	require.NoError(t, err)
+
+	users := &fakeUserRepository{users: map[int64]*models.User{
+		1: {ID: 1, Email: "admin@example.com", PasswordHash: string(hash), Role: models.RoleAdmin},
+		2: {ID: 2, Email: "alice@example.com", PasswordHash: string(hash), Role: models.RoleUser},
+	}}
+	sessions := newFakeSessionRepository()
+	cfg := &config.Config{JWTSecret: "secret", JWTAccessExpiry: 15 * time.Minute, RefreshTokenExpiry: time.Hour}
+
+	accounts := NewAccountService(users, &fakeUserTokenRepository{}, sessions, mail.NewFileSender(t.TempDir(), "tcm@example.com"), cfg)
+
+	return NewUserService(users, sessions, accounts, cfg), NewAuthService(users, sessions, NewTwoFactorService(newFakeTwoFactorRepository(), users, cfg), cfg), sessions
+}
+
+func TestUserService_Deactivate(t *testing.T) {
+	s, auth, _ := newTestUserService(t)
+
+	login, _, err := auth.Login("alice@example.com", "password123", SessionClient{})
+	require.NoError(t, err)
+
+	user, err := s.Deactivate(1, 2)
+	require.NoError(t, err)
+	assert.True(t, user.IsDeactivated())
+
+	// Test case: the sessions of a deactivated user are revoked and it cannot log in or refresh
+	assert.True(t, isRevoked(t, auth, login.AccessToken))
+	_, _, err = auth.Login("alice@example.com", "password123", SessionClient{})
+	assert.Equal(t, ErrUserDeactivated, err)
+	_, err = auth.Refresh(login.RefreshToken)
+	assert.Equal(t, ErrInvalidRefreshToken, err)
+
+	// Test case: deactivated users can be filtered
+	deactivated, err := s.List(&models.UserFilter{Status: models.UserStatusDeactivated})
+	require.NoError(t, err)
+	require.Len(t, deactivated, 1)
+	assert.Equal(t, int64(2), deactivated[0].ID)
+
+	// Test case: reactivated users can log in again
+	_, err = s.Reactivate(2)
+	require.NoError(t, err)
+	_, _, err = auth.Login("alice@example.com", "password123", SessionClient{})
+	assert.NoError(t, err)
+	_, err = s.Reactivate(2)
+	assert.Equal(t, ErrUserNotDeactivated, err)
+
+	// Test case: administrators cannot deactivate themselves or change their own role
+	_, err = s.Deactivate(1, 1)
+	assert.Equal(t, ErrCannotModifySelf, err)
+	_, err = s.UpdateRole(1, 1, models.RoleUser)
+	assert.Equal(t, ErrCannotModifySelf, err)
+
+	// Test case: an unknown status is rejected
+	_, err = s.List(&models.UserFilter{Status: "away"})
+	assert.Equal(t, ErrInvalidUserStatus, err)
+}
+
+func TestUserService_ChangePassword(t *testing.T) {
+	s, auth, _ := newTestUserService(t)
+
+	// Test case: forcing a password reset revokes every session
+	before, _, err := auth.Login("alice@example.com", "password123", SessionClient{})
+	require.NoError(t, err)
+	user, err := s.ForcePasswordReset(2)
+	require.NoError(t, err)
+	assert.True(t, user.PasswordResetRequired)
+	assert.True(t, isRevoked(t, auth, before.AccessToken))
+
+	first, _, err := auth.Login("alice@example.com", "password123", SessionClient{})
+	require.NoError(t, err)
+	second, _, err := auth.Login("alice@example.com", "password123", SessionClient{})
+	require.NoError(t, err)
+	token, err := auth.ValidateToken(second.AccessToken)
+	require.NoError(t, err)
+	sessionID, err := auth.GetSessionIDFromToken(token)
+	require.NoError(t, err)
+
+	// Test case: the current password must be correct and the new one must differ
+	err = s.ChangePassword(2, sessionID, &models.PasswordChange{CurrentPassword: "wrong", NewPassword: "new-password"})
+	assert.Equal(t, ErrIncorrectPassword, err)
+	err = s.ChangePassword(2, sessionID, &models.PasswordChange{CurrentPassword: "password123", NewPassword: "password123"})
+	assert.Equal(t, ErrPasswordUnchanged, err)
+
+	// Test case: changing the password clears the reset and revokes the other sessions
+	require.NoError(t, s.ChangePassword(2, sessionID, &models.PasswordChange{CurrentPassword: "password123", NewPassword: "new-password"}))
+	user, err = s.Get(2)
+	require.NoError(t, err)
+	assert.False(t, user.PasswordResetRequired)
+	assert.True(t, isRevoked(t, auth, first.AccessToken))
+	assert.False(t, isRevoked(t, auth, second.AccessToken))
+	_, _, err = auth.Login("alice@example.com", "new-password", SessionClient{})
+	assert.NoError(t, err)
+}
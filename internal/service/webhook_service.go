@@ -0,0 +1,430 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/mihaamiharu/test-case-management-be/internal/config"
+	"github.com/mihaamiharu/test-case-management-be/internal/events"
+	"github.com/mihaamiharu/test-case-management-be/internal/models"
+	"github.com/mihaamiharu/test-case-management-be/internal/repository"
+)
+
+var (
+	ErrWebhookDeliveryMismatch = errors.New("webhook delivery does not belong to the webhook")
+	ErrInvalidWebhookURL       = errors.New("webhook URL must be an http or https URL")
+	ErrWebhookHostNotAllowed   = errors.New("webhook host resolves to a loopback, private or link-local address")
+)
+
+const (
+	// webhookSecretPrefix starts every webhook secret, so that leaked secrets are recognizable
+	webhookSecretPrefix = "whsec_"
+
+	// webhookResponseLimit is the number of bytes of a webhook response kept in the delivery log
+	webhookResponseLimit = 1024
+
+	// webhookDeliveryBatch is the number of due deliveries attempted per poll
+	webhookDeliveryBatch = 50
+
+	// webhookDeliveryLogLimit is the number of deliveries listed per webhook
+	webhookDeliveryLogLimit = 100
+)
+
+// WebhookService manages project webhooks and delivers events to them. It is an events
+// publisher: every published event is queued for the active webhooks of its project that
+// subscribe to it, and sent by the delivery job with retries.
+type WebhookService struct {
+	webhookRepo repository.WebhookRepositoryInterface
+	client      *http.Client
+	cfg         config.WebhooksConfig
+	wake        chan struct{}
+}
+
+// NewWebhookService creates a new webhook service
+func NewWebhookService(webhookRepo repository.WebhookRepositoryInterface, cfg *config.Config) *WebhookService {
+	return &WebhookService{
+		webhookRepo: webhookRepo,
+		client:      newWebhookClient(cfg.Webhooks),
+		cfg:         cfg.Webhooks,
+		wake:        make(chan struct{}, 1),
+	}
+}
+
+// CreateWebhook creates a webhook with a new signing secret
+func (s *WebhookService) CreateWebhook(webhookCreate *models.WebhookCreate, userID int64) (*models.WebhookCreatedResponse, error) {
+	if err := validateWebhookURL(webhookCreate.URL); err != nil {
+		return nil, err
+	}
+
+	secret, err := randomToken(24)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate webhook secret: %v", err)
+	}
+
+	active := true
+	if webhookCreate.Active != nil {
+		active = *webhookCreate.Active
+	}
+	webhook := &models.Webhook{
+		ProjectID: webhookCreate.ProjectID,
+		Name:      webhookCreate.Name,
+		URL:       webhookCreate.URL,
+		Secret:    webhookSecretPrefix + secret,
+		Events:    uniqueStrings(webhookCreate.Events),
+		Active:    active,
+		CreatedBy: &userID,
+	}
+	if err := s.webhookRepo.Create(webhook); err != nil {
+		return nil, err
+	}
+
+	return &models.WebhookCreatedResponse{Webhook: webhook, Secret: webhook.Secret}, nil
+}
+
+// GetWebhook retrieves a webhook by ID
+func (s *WebhookService) GetWebhook(id int64) (*models.Webhook, error) {
+	return s.webhookRepo.GetByID(id)
+}
+
+// ListWebhooks retrieves the webhooks of a project
+func (s *WebhookService) ListWebhooks(projectID int64) ([]*models.Webhook, error) {
+	return s.webhookRepo.ListByProject(projectID)
+}
+
+// UpdateWebhook changes the name, URL, events and active state of a webhook
+func (s *WebhookService) UpdateWebhook(id int64, webhookUpdate *models.WebhookUpdate) (*models.Webhook, error) {
+	if err := validateWebhookURL(webhookUpdate.URL); err != nil {
+		return nil, err
+	}
+	webhook, err := s.webhookRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	webhook.Name = webhookUpdate.Name
+	webhook.URL = webhookUpdate.URL
+	webhook.Events = uniqueStrings(webhookUpdate.Events)
+	webhook.Active = webhookUpdate.Active
+	if err := s.webhookRepo.Update(webhook); err != nil {
+		return nil, err
+	}
+	return webhook, nil
+}
+
+// DeleteWebhook deletes a webhook together with its delivery log
+func (s *WebhookService) DeleteWebhook(id int64) error {
+	return s.webhookRepo.Delete(id)
+}
+
+// ListDeliveries retrieves the latest deliveries of a webhook
+func (s *WebhookService) ListDeliveries(webhookID int64) ([]*models.WebhookDelivery, error) {
+	if _, err := s.webhookRepo.GetByID(webhookID); err != nil {
+		return nil, err
+	}
+	return s.webhookRepo.ListDeliveries(webhookID, webhookDeliveryLogLimit)
+}
+
+// Redeliver queues a new delivery of the payload of an earlier delivery of a webhook
+func (s *WebhookService) Redeliver(webhookID, deliveryID int64) (*models.WebhookDelivery, error) {
+	original, err := s.webhookRepo.GetDelivery(deliveryID)
+	if err != nil {
+		return nil, err
+	}
+	if original.WebhookID != webhookID {
+		return nil, ErrWebhookDeliveryMismatch
+	}
+
+	now := time.Now()
+	delivery := &models.WebhookDelivery{
+		WebhookID:     webhookID,
+		EventType:     original.EventType,
+		Payload:       original.Payload,
+		Status:        models.WebhookDeliveryPending,
+		NextAttemptAt: &now,
+		RedeliveryOf:  &original.ID,
+	}
+	if err := s.webhookRepo.CreateDelivery(delivery); err != nil {
+		return nil, err
+	}
+
+	s.wakeDeliveryJob()
+	return delivery, nil
+}
+
+// Publish queues a delivery of an event for every active webhook of its project that
+// subscribes to it. Failures are logged, since they must not fail the change that
+// caused the event.
+func (s *WebhookService) Publish(event *events.Event) {
+	eventType, ok := webhookEventType(event)
+	if !ok {
+		return
+	}
+
+	webhooks, err := s.webhookRepo.ListActiveByEvent(event.ProjectID, eventType)
+	if err != nil {
+		log.Printf("failed to find webhooks for %s event of project %d: %v", eventType, event.ProjectID, err)
+		return
+	}
+	if len(webhooks) == 0 {
+		return
+	}
+
+	occurredAt := event.OccurredAt
+	if occurredAt.IsZero() {
+		occurredAt = time.Now()
+	}
+	payload, err := json.Marshal(&models.WebhookPayload{
+		Event:      eventType,
+		ProjectID:  event.ProjectID,
+		TestRunID:  event.TestRunID,
+		ActorID:    event.ActorID,
+		OccurredAt: occurredAt,
+		Data:       event.Data,
+	})
+	if err != nil {
+		log.Printf("failed to encode %s webhook payload: %v", eventType, err)
+		return
+	}
+
+	now := time.Now()
+	for _, webhook := range webhooks {
+		delivery := &models.WebhookDelivery{
+			WebhookID:     webhook.ID,
+			EventType:     eventType,
+			Payload:       payload,
+			Status:        models.WebhookDeliveryPending,
+			NextAttemptAt: &now,
+		}
+		if err := s.webhookRepo.CreateDelivery(delivery); err != nil {
+			log.Printf("failed to queue %s delivery for webhook %d: %v", eventType, webhook.ID, err)
+		}
+	}
+
+	s.wakeDeliveryJob()
+}
+
+// RunDeliveryJob attempts the due deliveries at every interval, and as soon as deliveries
+// are queued, until the context is done
+func (s *WebhookService) RunDeliveryJob(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.DeliverDue(ctx); err != nil {
+			log.Printf("failed to deliver webhooks: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-s.wake:
+		}
+	}
+}
+
+// DeliverDue attempts every pending delivery whose next attempt is due
+func (s *WebhookService) DeliverDue(ctx context.Context) error {
+	for {
+		deliveries, err := s.webhookRepo.ListDueDeliveries(time.Now(), webhookDeliveryBatch)
+		if err != nil {
+			return err
+		}
+
+		for _, delivery := range deliveries {
+			if ctx.Err() != nil {
+				return nil
+			}
+			// Claim the delivery for longer than an attempt can take, so that other servers
+			// leave it alone and a crash during the attempt only delays it
+			claimed, err := s.webhookRepo.ClaimDelivery(delivery, time.Now().Add(s.cfg.Timeout+time.Minute))
+			if err != nil {
+				return err
+			}
+			if !claimed {
+				continue
+			}
+			if err := s.attempt(ctx, delivery); err != nil {
+				log.Printf("failed to record attempt of webhook delivery %d: %v", delivery.ID, err)
+			}
+		}
+
+		if len(deliveries) < webhookDeliveryBatch {
+			return nil
+		}
+	}
+}
+
+// attempt sends a delivery to its webhook and records the outcome. Deliveries of deleted
+// or deactivated webhooks fail without being sent.
+func (s *WebhookService) attempt(ctx context.Context, delivery *models.WebhookDelivery) error {
+	webhook, err := s.webhookRepo.GetByID(delivery.WebhookID)
+	if err != nil && !errors.Is(err, repository.ErrWebhookNotFound) {
+		return err
+	}
+
+	delivery.Attempts++
+	delivery.ResponseStatus = nil
+	delivery.ResponseBody = ""
+	delivery.Error = ""
+
+	switch {
+	case webhook == nil:
+		delivery.Error = "webhook was deleted"
+		delivery.Attempts = s.cfg.MaxAttempts
+	case !webhook.Active:
+		delivery.Error = "webhook is inactive"
+		delivery.Attempts = s.cfg.MaxAttempts
+	default:
+		status, body, err := s.send(ctx, webhook, delivery)
+		if err != nil {
+			delivery.Error = err.Error()
+		} else {
+			delivery.ResponseStatus = &status
+			delivery.ResponseBody = body
+		}
+	}
+
+	now := time.Now()
+	switch {
+	case delivery.ResponseStatus != nil && *delivery.ResponseStatus >= 200 && *delivery.ResponseStatus < 300:
+		delivery.Status = models.WebhookDeliverySucceeded
+		delivery.NextAttemptAt = nil
+		delivery.DeliveredAt = &now
+	case delivery.Attempts >= s.cfg.MaxAttempts:
+		delivery.Status = models.WebhookDeliveryFailed
+		delivery.NextAttemptAt = nil
+	default:
+		next := now.Add(s.retryDelay(delivery.Attempts))
+		delivery.Status = models.WebhookDeliveryPending
+		delivery.NextAttemptAt = &next
+	}
+	return s.webhookRepo.UpdateDelivery(delivery)
+}
+
+// send posts the signed payload of a delivery to a webhook and returns the response status
+// and the start of the response body
+func (s *WebhookService) send(ctx context.Context, webhook *models.Webhook, delivery *models.WebhookDelivery) (int, string, error) {
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "test-case-management-webhooks")
+	req.Header.Set(models.WebhookEventHeader, delivery.EventType)
+	req.Header.Set(models.WebhookDeliveryHeader, strconv.FormatInt(delivery.ID, 10))
+	req.Header.Set(models.WebhookTimestampHeader, timestamp)
+	req.Header.Set(models.WebhookSignatureHeader, SignWebhookPayload(webhook.Secret, timestamp, delivery.Payload))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, "", err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, webhookResponseLimit))
+	if err != nil {
+		return 0, "", fmt.Errorf("failed to read response: %v", err)
+	}
+	return resp.StatusCode, string(body), nil
+}
+
+// newWebhookClient creates the client deliveries are sent with. Unless private hosts are
+// allowed, it only connects to public addresses.
+func newWebhookClient(cfg config.WebhooksConfig) *http.Client {
+	if cfg.AllowPrivateHosts {
+		return newOutboundClient(cfg.Timeout, nil, nil)
+	}
+	return newOutboundClient(cfg.Timeout, publicIP, ErrWebhookHostNotAllowed)
+}
+
+// validateWebhookURL checks that a webhook URL is an absolute http or https URL
+func validateWebhookURL(rawURL string) error {
+	if !validHTTPURL(rawURL) {
+		return ErrInvalidWebhookURL
+	}
+	return nil
+}
+
+// retryDelay returns the wait after a failed attempt, doubled after each further failure
+func (s *WebhookService) retryDelay(attempts int) time.Duration {
+	delay := s.cfg.RetryBase
+	for i := 1; i < attempts && delay < s.cfg.RetryMax; i++ {
+		delay *= 2
+	}
+	if delay > s.cfg.RetryMax {
+		delay = s.cfg.RetryMax
+	}
+	return delay
+}
+
+// wakeDeliveryJob makes the delivery job look for due deliveries without waiting for its
+// next poll
+func (s *WebhookService) wakeDeliveryJob() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// SignWebhookPayload returns the signature header value of a webhook request: the hex
+// HMAC-SHA256, keyed with the webhook secret, of the timestamp header, a dot and the body.
+// Receivers recompute it to check that a request is authentic and recent.
+func SignWebhookPayload(secret, timestamp string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(payload)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// webhookEventType maps an event to the webhook event type it is delivered as. Only test
+// execution results that failed are delivered, and events webhooks cannot subscribe to
+// are not delivered at all.
+func webhookEventType(event *events.Event) (string, bool) {
+	switch event.Type {
+	case events.TestCaseCreated:
+		return models.WebhookEventTestCaseCreated, true
+	case events.TestCaseUpdated:
+		return models.WebhookEventTestCaseUpdated, true
+	case events.TestCaseDeleted:
+		return models.WebhookEventTestCaseDeleted, true
+	case events.TestRunStarted:
+		return models.WebhookEventTestRunStarted, true
+	case events.TestRunCompleted:
+		return models.WebhookEventTestRunCompleted, true
+	case events.DefectOpened:
+		return models.WebhookEventDefectOpened, true
+	case events.TestExecutionResult:
+		if execution, ok := event.Data.(*models.TestExecutionResponse); ok && execution.Status == models.ExecutionStatusFailed {
+			return models.WebhookEventTestExecutionFailed, true
+		}
+	}
+	return "", false
+}
+
+// uniqueStrings returns the strings in their original order without repetitions
+func uniqueStrings(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	unique := make([]string, 0, len(values))
+	for _, value := range values {
+		if !seen[value] {
+			seen[value] = true
+			unique = append(unique, value)
+		}
+	}
+	return unique
+}
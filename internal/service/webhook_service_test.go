@@ -0,0 +1,258 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/mihaamiharu/test-case-management-be/internal/config"
+	"github.com/mihaamiharu/test-case-management-be/internal/events"
+	"github.com/mihaamiharu/test-case-management-be/internal/models"
+	"github.com/mihaamiharu/test-case-management-be/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeWebhookRepository keeps webhooks and their deliveries in memory
+type fakeWebhookRepository struct {
+	repository.WebhookRepositoryInterface
+	webhooks   map[int64]*models.Webhook
+	deliveries []*models.WebhookDelivery
+}
+
+func (r *fakeWebhookRepository) Create(webhook *models.Webhook) error {
+	webhook.ID = int64(len(r.webhooks) + 1)
+	r.webhooks[webhook.ID] = webhook
+	return nil
+}
+
+func (r *fakeWebhookRepository) GetByID(id int64) (*models.Webhook, error) {
+	if webhook, ok := r.webhooks[id]; ok {
+		return webhook, nil
+	}
+	return nil, repository.ErrWebhookNotFound
+}
+
+func (r *fakeWebhookRepository) ListActiveByEvent(projectID int64, eventType string) ([]*models.Webhook, error) {
+	var webhooks []*models.Webhook
+	for _, webhook := range r.webhooks {
+		if webhook.ProjectID == projectID && webhook.Active && webhook.Subscribes(eventType) {
+			webhooks = append(webhooks, webhook)
+		}
+	}
+	return webhooks, nil
+}
+
+func (r *fakeWebhookRepository) CreateDelivery(delivery *models.WebhookDelivery) error {
+	delivery.ID = int64(len(r.deliveries) + 1)
+	r.deliveries = append(r.deliveries, delivery)
+	return nil
+}
+
+func (r *fakeWebhookRepository) GetDelivery(id int64) (*models.WebhookDelivery, error) {
+	if id < 1 || int(id) > len(r.deliveries) {
+		return nil, repository.ErrWebhookDeliveryNotFound
+	}
+	return r.deliveries[id-1], nil
+}
+
+func (r *fakeWebhookRepository) ListDueDeliveries(now time.Time, limit int) ([]*models.WebhookDelivery, error) {
+	var due []*models.WebhookDelivery
+	for _, delivery := range r.deliveries {
+		if delivery.Status == models.WebhookDeliveryPending && !delivery.NextAttemptAt.After(now) {
+			due = append(due, delivery)
+		}
+	}
+	return due, nil
+}
+
+func (r *fakeWebhookRepository) ClaimDelivery(delivery *models.WebhookDelivery, until time.Time) (bool, error) {
+	delivery.NextAttemptAt = &until
+	return true, nil
+}
+
+func (r *fakeWebhookRepository) UpdateDelivery(delivery *models.WebhookDelivery) error {
+	return nil
+}
+
+// webhookReceiver is a local webhook endpoint that answers with a configurable status
+type webhookReceiver struct {
+	server   *httptest.Server
+	status   int
+	requests []*http.Request
+	bodies   [][]byte
+}
+
+func newWebhookReceiver(t *testing.T) *webhookReceiver {
+	receiver := &webhookReceiver{status: http.StatusOK}
+	receiver.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		receiver.requests = append(receiver.requests, r)
+		receiver.bodies = append(receiver.bodies, body)
+		w.WriteHeader(receiver.status)
+		w.Write([]byte("received"))
+	}))
+	t.Cleanup(receiver.server.Close)
+	return receiver
+}
+
+func newTestWebhookService() (*WebhookService, *fakeWebhookRepository) {
+	repo := &fakeWebhookRepository{webhooks: map[int64]*models.Webhook{}}
+	return NewWebhookService(repo, &config.Config{Webhooks: config.WebhooksConfig{
+		Timeout:     time.Second,
+		MaxAttempts: 3,
+		RetryBase:   time.Minute,
+		RetryMax:    90 * time.Second,
+
+		AllowPrivateHosts: true,
+	}}), repo
+}
+
+func TestWebhookService_PublishAndDeliver(t *testing.T) {
+	s, repo := newTestWebhookService()
+	receiver := newWebhookReceiver(t)
+
+	created, err := s.CreateWebhook(&models.WebhookCreate{
+		ProjectID: 1,
+		Name:      "CI",
+		URL:       receiver.server.URL,
+		Events:    []string{models.WebhookEventTestExecutionFailed, models.WebhookEventTestCaseCreated, models.WebhookEventTestCaseCreated},
+	}, 7)
+	require.NoError(t, err)
+	assert.True(t, created.Active)
+	assert.Equal(t, []string{models.WebhookEventTestExecutionFailed, models.WebhookEventTestCaseCreated}, created.Events)
+	assert.Contains(t, created.Secret, webhookSecretPrefix)
+
+	// Test case: events the webhook does not subscribe to, other projects and passed results are not queued
+	s.Publish(&events.Event{Type: events.TestCaseUpdated, ProjectID: 1})
+	s.Publish(&events.Event{Type: events.TestCaseCreated, ProjectID: 2})
+	s.Publish(&events.Event{Type: events.TestExecutionResult, ProjectID: 1, Data: &models.TestExecutionResponse{Status: models.ExecutionStatusPassed}})
+	assert.Empty(t, repo.deliveries)
+
+	// Test case: a failed result is delivered as test_execution.failed with a valid signature
+	s.Publish(&events.Event{
+		Type:      events.TestExecutionResult,
+		ProjectID: 1,
+		TestRunID: 3,
+		ActorID:   7,
+		Data:      &models.TestExecutionResponse{ID: 11, Status: models.ExecutionStatusFailed},
+	})
+	require.Len(t, repo.deliveries, 1)
+	require.NoError(t, s.DeliverDue(context.Background()))
+	require.Len(t, receiver.requests, 1)
+
+	req, body := receiver.requests[0], receiver.bodies[0]
+	assert.Equal(t, models.WebhookEventTestExecutionFailed, req.Header.Get(models.WebhookEventHeader))
+	assert.Equal(t, "1", req.Header.Get(models.WebhookDeliveryHeader))
+	assert.Equal(t, SignWebhookPayload(created.Secret, req.Header.Get(models.WebhookTimestampHeader), body),
+		req.Header.Get(models.WebhookSignatureHeader))
+	assert.NotEqual(t, SignWebhookPayload("other-secret", req.Header.Get(models.WebhookTimestampHeader), body),
+		req.Header.Get(models.WebhookSignatureHeader))
+
+	var payload map[string]interface{}
+	require.NoError(t, json.Unmarshal(body, &payload))
+	assert.Equal(t, models.WebhookEventTestExecutionFailed, payload["event"])
+	assert.Equal(t, float64(3), payload["test_run_id"])
+	assert.Equal(t, float64(11), payload["data"].(map[string]interface{})["id"])
+
+	delivery := repo.deliveries[0]
+	assert.Equal(t, models.WebhookDeliverySucceeded, delivery.Status)
+	assert.Equal(t, 1, delivery.Attempts)
+	assert.Equal(t, http.StatusOK, *delivery.ResponseStatus)
+	assert.Equal(t, "received", delivery.ResponseBody)
+	assert.NotNil(t, delivery.DeliveredAt)
+}
+
+func TestWebhookService_Retries(t *testing.T) {
+	s, repo := newTestWebhookService()
+	receiver := newWebhookReceiver(t)
+	receiver.status = http.StatusServiceUnavailable
+
+	_, err := s.CreateWebhook(&models.WebhookCreate{
+		ProjectID: 1,
+		Name:      "CI",
+		URL:       receiver.server.URL,
+		Events:    []string{models.WebhookEventDefectOpened},
+	}, 7)
+	require.NoError(t, err)
+	s.Publish(&events.Event{Type: events.DefectOpened, ProjectID: 1, Data: &models.Defect{ID: 5}})
+	require.Len(t, repo.deliveries, 1)
+	delivery := repo.deliveries[0]
+
+	// Test case: failed attempts are retried after a backoff that doubles up to the maximum
+	for attempt, wait := range []time.Duration{time.Minute, 90 * time.Second} {
+		require.NoError(t, s.attempt(context.Background(), delivery))
+		assert.Equal(t, attempt+1, delivery.Attempts)
+		assert.Equal(t, models.WebhookDeliveryPending, delivery.Status)
+		assert.Equal(t, http.StatusServiceUnavailable, *delivery.ResponseStatus)
+		assert.WithinDuration(t, time.Now().Add(wait), *delivery.NextAttemptAt, 5*time.Second)
+	}
+
+	// Test case: the last allowed attempt fails the delivery for good
+	require.NoError(t, s.attempt(context.Background(), delivery))
+	assert.Equal(t, models.WebhookDeliveryFailed, delivery.Status)
+	assert.Nil(t, delivery.NextAttemptAt)
+	assert.Len(t, receiver.requests, 3)
+
+	// Test case: a redelivery queues the same payload again, and only for its own webhook
+	redelivery, err := s.Redeliver(1, delivery.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.WebhookDeliveryPending, redelivery.Status)
+	assert.Equal(t, delivery.ID, *redelivery.RedeliveryOf)
+	assert.Equal(t, delivery.Payload, redelivery.Payload)
+
+	_, err = s.Redeliver(2, delivery.ID)
+	assert.ErrorIs(t, err, ErrWebhookDeliveryMismatch)
+
+	// Test case: deliveries of deactivated webhooks fail without being sent
+	repo.webhooks[1].Active = false
+	require.NoError(t, s.DeliverDue(context.Background()))
+	assert.Equal(t, models.WebhookDeliveryFailed, redelivery.Status)
+	assert.Equal(t, "webhook is inactive", redelivery.Error)
+	assert.Len(t, receiver.requests, 3)
+}
+
+func TestWebhookService_RejectsInternalTargets(t *testing.T) {
+	repo := &fakeWebhookRepository{webhooks: map[int64]*models.Webhook{}}
+	s := NewWebhookService(repo, &config.Config{Webhooks: config.WebhooksConfig{Timeout: time.Second, MaxAttempts: 1}})
+	receiver := newWebhookReceiver(t)
+
+	// Test case: only http and https URLs are accepted
+	for _, target := range []string{"ftp://example.com/hook", "file:///etc/passwd", "gopher://example.com"} {
+		_, err := s.CreateWebhook(&models.WebhookCreate{ProjectID: 1, Name: "CI", URL: target, Events: []string{models.WebhookEventDefectOpened}}, 7)
+		assert.ErrorIs(t, err, ErrInvalidWebhookURL, target)
+	}
+
+	// Test case: deliveries to loopback addresses fail without reaching the receiver
+	_, err := s.CreateWebhook(&models.WebhookCreate{ProjectID: 1, Name: "CI", URL: receiver.server.URL, Events: []string{models.WebhookEventDefectOpened}}, 7)
+	require.NoError(t, err)
+	s.Publish(&events.Event{Type: events.DefectOpened, ProjectID: 1, Data: &models.Defect{ID: 5}})
+	require.Len(t, repo.deliveries, 1)
+
+	require.NoError(t, s.attempt(context.Background(), repo.deliveries[0]))
+	assert.Equal(t, models.WebhookDeliveryFailed, repo.deliveries[0].Status)
+	assert.Contains(t, repo.deliveries[0].Error, ErrWebhookHostNotAllowed.Error())
+	assert.Empty(t, receiver.requests)
+
+}
+
+func TestWebhookService_DoesNotFollowRedirects(t *testing.T) {
+	s, repo := newTestWebhookService()
+	redirected := newWebhookReceiver(t)
+	redirector := httptest.NewServer(http.RedirectHandler(redirected.server.URL, http.StatusFound))
+	t.Cleanup(redirector.Close)
+
+	_, err := s.CreateWebhook(&models.WebhookCreate{ProjectID: 1, Name: "CI", URL: redirector.URL, Events: []string{models.WebhookEventDefectOpened}}, 7)
+	require.NoError(t, err)
+	s.Publish(&events.Event{Type: events.DefectOpened, ProjectID: 1, Data: &models.Defect{ID: 5}})
+	require.Len(t, repo.deliveries, 1)
+
+	// Test case: the redirect is the response of the delivery and its target is never requested
+	require.NoError(t, s.attempt(context.Background(), repo.deliveries[0]))
+	assert.Equal(t, http.StatusFound, *repo.deliveries[0].ResponseStatus)
+	assert.Empty(t, redirected.requests)
+}
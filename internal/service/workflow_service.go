@@ -0,0 +1,200 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/mihaamiharu/test-case-management-be/internal/models"
+	"github.com/mihaamiharu/test-case-management-be/internal/repository"
+)
+
+var (
+	ErrInvalidStatus        = errors.New("status is not part of the project workflow")
+	ErrInvalidPriority      = errors.New("priority is not part of the project workflow")
+	ErrTransitionNotAllowed = errors.New("status change is not allowed by the project workflow")
+	ErrInvalidWorkflow      = errors.New("invalid workflow")
+	ErrWorkflowValueInUse   = errors.New("test cases still use a removed status or priority")
+)
+
+// reviewStatuses are set by the review workflow and must exist in every project workflow
+var reviewStatuses = []models.TestCaseStatus{models.StatusDraft, models.StatusInReview, models.StatusApproved, models.StatusRejected}
+
+// WorkflowService handles project workflow business logic
+type WorkflowService struct {
+	workflowRepo repository.WorkflowRepositoryInterface
+}
+
+// NewWorkflowService creates a new workflow service
+func NewWorkflowService(workflowRepo repository.WorkflowRepositoryInterface) *WorkflowService {
+	return &WorkflowService{
+		workflowRepo: workflowRepo,
+	}
+}
+
+// GetWorkflow retrieves the workflow of a project
+func (s *WorkflowService) GetWorkflow(projectID int64) (*models.Workflow, error) {
+	return s.workflowRepo.GetWorkflow(projectID)
+}
+
+// UpdateWorkflow replaces the workflow of a project. Test cases that use a status or
+// priority the new workflow drops must be moved with a mapping.
+func (s *WorkflowService) UpdateWorkflow(projectID int64, update *models.WorkflowUpdate) (*models.Workflow, error) {
+	workflow := &models.Workflow{
+		ProjectID:   projectID,
+		Statuses:    update.Statuses,
+		Priorities:  update.Priorities,
+		Transitions: update.Transitions,
+	}
+	if workflow.Transitions == nil {
+		workflow.Transitions = []*models.WorkflowTransition{}
+	}
+
+	if err := ValidateWorkflow(workflow); err != nil {
+		return nil, err
+	}
+	if err := checkMapping(update.StatusMapping, func(name string) bool { return workflow.Status(models.TestCaseStatus(name)) != nil }); err != nil {
+		return nil, err
+	}
+	if err := checkMapping(update.PriorityMapping, func(name string) bool { return workflow.Priority(models.TestCasePriority(name)) != nil }); err != nil {
+		return nil, err
+	}
+
+	// Every value still in use must be kept or mapped to a value of the new workflow
+	statuses, priorities, err := s.workflowRepo.ListUsedValues(projectID)
+	if err != nil {
+		return nil, err
+	}
+	for _, status := range statuses {
+		if workflow.Status(models.TestCaseStatus(status)) == nil && update.StatusMapping[status] == "" {
+			return nil, fmt.Errorf("%w: status %s", ErrWorkflowValueInUse, status)
+		}
+	}
+	for _, priority := range priorities {
+		if workflow.Priority(models.TestCasePriority(priority)) == nil && update.PriorityMapping[priority] == "" {
+			return nil, fmt.Errorf("%w: priority %s", ErrWorkflowValueInUse, priority)
+		}
+	}
+
+	if err := s.workflowRepo.SaveWorkflow(workflow, update.StatusMapping, update.PriorityMapping); err != nil {
+		return nil, err
+	}
+
+	return workflow, nil
+}
+
+// ValidateWorkflow checks that a workflow has unique, well-formed statuses and priorities,
+// keeps the statuses used by reviews and only has transitions between its own statuses
+func ValidateWorkflow(workflow *models.Workflow) error {
+	invalid := func(format string, args ...interface{}) error {
+		return fmt.Errorf("%w: %s", ErrInvalidWorkflow, fmt.Sprintf(format, args...))
+	}
+
+	seen := make(map[string]bool, len(workflow.Statuses))
+	for _, status := range workflow.Statuses {
+		if !customFieldNamePattern.MatchString(status.Name) {
+			return invalid("status name %q must start with a letter and contain only lowercase letters, digits and underscores", status.Name)
+		}
+		if seen[status.Name] {
+			return invalid("duplicate status %s", status.Name)
+		}
+		seen[status.Name] = true
+	}
+	for _, status := range reviewStatuses {
+		if workflow.Status(status) == nil {
+			return invalid("status %s is required by the review workflow", status)
+		}
+		if workflow.Status(status).Terminal {
+			return invalid("status %s cannot be terminal", status)
+		}
+	}
+
+	seen = make(map[string]bool, len(workflow.Priorities))
+	defaults := 0
+	for _, priority := range workflow.Priorities {
+		if !customFieldNamePattern.MatchString(priority.Name) {
+			return invalid("priority name %q must start with a letter and contain only lowercase letters, digits and underscores", priority.Name)
+		}
+		if seen[priority.Name] {
+			return invalid("duplicate priority %s", priority.Name)
+		}
+		seen[priority.Name] = true
+		if priority.Default {
+			defaults++
+		}
+	}
+	if len(workflow.Priorities) == 0 {
+		return invalid("at least one priority is required")
+	}
+	if defaults > 1 {
+		return invalid("only one priority can be the default")
+	}
+
+	for _, transition := range workflow.Transitions {
+		if workflow.Status(models.TestCaseStatus(transition.From)) == nil || workflow.Status(models.TestCaseStatus(transition.To)) == nil {
+			return invalid("transition %s -> %s uses an unknown status", transition.From, transition.To)
+		}
+		if workflow.Status(models.TestCaseStatus(transition.From)).Terminal {
+			return invalid("terminal status %s cannot have outgoing transitions", transition.From)
+		}
+	}
+
+	// Fill in the colors that were left out
+	for _, status := range workflow.Statuses {
+		if status.Color == "" {
+			status.Color = "#9e9e9e"
+		}
+	}
+	for _, priority := range workflow.Priorities {
+		if priority.Color == "" {
+			priority.Color = "#9e9e9e"
+		}
+	}
+
+	return nil
+}
+
+// checkMapping verifies that a mapping only moves removed values to values of the new workflow
+func checkMapping(mapping map[string]string, exists func(name string) bool) error {
+	for from, to := range mapping {
+		if exists(from) {
+			return fmt.Errorf("%w: %s is still part of the workflow and cannot be mapped", ErrInvalidWorkflow, from)
+		}
+		if !exists(to) {
+			return fmt.Errorf("%w: %s is not part of the workflow", ErrInvalidWorkflow, to)
+		}
+	}
+	return nil
+}
+
+// checkStatusChange validates a manual status change against the project workflow.
+// Review statuses can only be set by the review workflow.
+func checkStatusChange(workflow *models.Workflow, from, to models.TestCaseStatus) error {
+	if from == to {
+		return nil
+	}
+	for _, status := range reviewStatuses[1:] {
+		if to == status {
+			return ErrStatusManagedByReview
+		}
+	}
+	if workflow.Status(to) == nil {
+		return fmt.Errorf("%w: %s", ErrInvalidStatus, to)
+	}
+	if from != "" && !workflow.AllowsTransition(from, to) {
+		return fmt.Errorf("%w: %s -> %s", ErrTransitionNotAllowed, from, to)
+	}
+	return nil
+}
+
+// checkPriority validates a priority against the project workflow
+func checkPriority(workflow *models.Workflow, priority models.TestCasePriority) error {
+	if workflow.Priority(priority) == nil {
+		names := make([]string, len(workflow.Priorities))
+		for i, p := range workflow.Priorities {
+			names[i] = p.Name
+		}
+		return fmt.Errorf("%w: %s (expected one of %s)", ErrInvalidPriority, priority, strings.Join(names, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/mihaamiharu/test-case-management-be/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func customWorkflow() *models.Workflow {
+	return &models.Workflow{
+		ProjectID: 1,
+		Statuses: []*models.WorkflowStatus{
+			{Name: "draft", Label: "Draft"},
+			{Name: "in_review", Label: "In Review"},
+			{Name: "approved", Label: "Approved"},
+			{Name: "rejected", Label: "Rejected"},
+			{Name: "ready", Label: "Ready"},
+			{Name: "retired", Label: "Retired", Terminal: true},
+		},
+		Priorities: []*models.WorkflowPriority{
+			{Name: "p1", Label: "P1"},
+			{Name: "p2", Label: "P2", Default: true},
+		},
+		Transitions: []*models.WorkflowTransition{
+			{From: "draft", To: "ready"},
+			{From: "ready", To: "retired"},
+		},
+	}
+}
+
+func TestValidateWorkflow(t *testing.T) {
+	t.Run("Valid", func(t *testing.T) {
+		workflow := customWorkflow()
+		assert.NoError(t, ValidateWorkflow(workflow))
+		// Test case: missing colors get the default color
+		assert.Equal(t, "#9e9e9e", workflow.Statuses[0].Color)
+	})
+
+	t.Run("MissingReviewStatus", func(t *testing.T) {
+		workflow := customWorkflow()
+		workflow.Statuses = workflow.Statuses[1:]
+		assert.True(t, errors.Is(ValidateWorkflow(workflow), ErrInvalidWorkflow))
+	})
+
+	t.Run("TransitionFromTerminalStatus", func(t *testing.T) {
+		workflow := customWorkflow()
+		workflow.Transitions = append(workflow.Transitions, &models.WorkflowTransition{From: "retired", To: "draft"})
+		assert.True(t, errors.Is(ValidateWorkflow(workflow), ErrInvalidWorkflow))
+	})
+
+	t.Run("TwoDefaultPriorities", func(t *testing.T) {
+		workflow := customWorkflow()
+		workflow.Priorities[0].Default = true
+		assert.True(t, errors.Is(ValidateWorkflow(workflow), ErrInvalidWorkflow))
+	})
+}
+
+func TestCheckStatusChange(t *testing.T) {
+	workflow := customWorkflow()
+
+	assert.NoError(t, checkStatusChange(workflow, "draft", "ready"))
+	assert.NoError(t, checkStatusChange(workflow, "", "ready"))
+	assert.True(t, errors.Is(checkStatusChange(workflow, "draft", "retired"), ErrTransitionNotAllowed))
+	assert.True(t, errors.Is(checkStatusChange(workflow, "draft", "active"), ErrInvalidStatus))
+	assert.True(t, errors.Is(checkStatusChange(workflow, "draft", "approved"), ErrStatusManagedByReview))
+
+	// Test case: the built-in workflow allows every change
+	assert.NoError(t, checkStatusChange(models.DefaultWorkflow(1), "deprecated", "active"))
+}
+
+func TestCheckPriority(t *testing.T) {
+	workflow := customWorkflow()
+
+	assert.NoError(t, checkPriority(workflow, "p1"))
+	assert.True(t, errors.Is(checkPriority(workflow, "high"), ErrInvalidPriority))
+	assert.Equal(t, models.TestCasePriority("p2"), workflow.DefaultPriority())
+}
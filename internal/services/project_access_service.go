@@ -1,15 +1,28 @@
 package services
 
 import (
+	"errors"
+	"fmt"
+
 	"github.com/mihaamiharu/test-case-management-be/internal/models"
 	"github.com/mihaamiharu/test-case-management-be/internal/repository"
 )
 
+var (
+	ErrPermissionDenied = errors.New("you don't have permission to do this in this project")
+	ErrAlreadyOwner     = errors.New("user already owns this project")
+	ErrGrantToOwner     = errors.New("the project owner already has every permission")
+	ErrInactiveNewOwner = errors.New("project ownership cannot be transferred to a deactivated user")
+)
+
 // ProjectAccessService handles business logic for project access
 type ProjectAccessService struct {
 	projectAccessRepo repository.ProjectAccessRepositoryInterface
 	projectRepo       repository.ProjectRepositoryInterface
 	userRepo          repository.UserRepositoryInterface
+	teamRepo          repository.TeamRepositoryInterface
+	archiveRepo       repository.ArchiveRepositoryInterface
+	organizationRepo  repository.OrganizationRepositoryInterface
 }
 
 // NewProjectAccessService creates a new project access service
@@ -17,33 +30,45 @@ func NewProjectAccessService(
 	projectAccessRepo repository.ProjectAccessRepositoryInterface,
 	projectRepo repository.ProjectRepositoryInterface,
 	userRepo repository.UserRepositoryInterface,
+	teamRepo repository.TeamRepositoryInterface,
+	archiveRepo repository.ArchiveRepositoryInterface,
+	organizationRepo repository.OrganizationRepositoryInterface,
 ) *ProjectAccessService {
 	return &ProjectAccessService{
 		projectAccessRepo: projectAccessRepo,
 		projectRepo:       projectRepo,
 		userRepo:          userRepo,
+		teamRepo:          teamRepo,
+		archiveRepo:       archiveRepo,
+		organizationRepo:  organizationRepo,
 	}
 }
 
-// GrantAccess grants access to a project for a user
+// GrantAccess grants access to a project for a member of its organization
 func (s *ProjectAccessService) GrantAccess(projectID int64, accessCreate *models.ProjectAccessCreate) (*models.ProjectAccess, error) {
 	// Check if project exists
-	_, err := s.projectRepo.GetByID(projectID)
+	project, err := s.projectRepo.GetByID(projectID)
 	if err != nil {
 		return nil, err
 	}
+	if project.OwnerID == accessCreate.UserID {
+		return nil, ErrGrantToOwner
+	}
 
-	// Check if user exists
+	// Check if user exists and belongs to the organization of the project
 	_, err = s.userRepo.GetByID(accessCreate.UserID)
 	if err != nil {
 		return nil, err
 	}
+	if _, err := s.organizationRepo.GetMember(project.OrganizationID, accessCreate.UserID); err != nil {
+		return nil, err
+	}
 
 	// Create access
 	access := &models.ProjectAccess{
 		ProjectID: projectID,
 		UserID:    accessCreate.UserID,
-		Level:     accessCreate.Level,
+		Level:     accessCreate.Level.Normalize(),
 	}
 
 	if err := s.projectAccessRepo.Create(access); err != nil {
@@ -54,15 +79,15 @@ func (s *ProjectAccessService) GrantAccess(projectID int64, accessCreate *models
 }
 
 // UpdateAccess updates a user's access level to a project
-func (s *ProjectAccessService) UpdateAccess(accessID int64, accessUpdate *models.ProjectAccessUpdate) (*models.ProjectAccess, error) {
+func (s *ProjectAccessService) UpdateAccess(projectID, accessID int64, accessUpdate *models.ProjectAccessUpdate) (*models.ProjectAccess, error) {
 	// Get existing access
-	access, err := s.projectAccessRepo.GetByID(accessID)
+	access, err := s.GetAccess(projectID, accessID)
 	if err != nil {
 		return nil, err
 	}
 
 	// Update access level
-	access.Level = accessUpdate.Level
+	access.Level = accessUpdate.Level.Normalize()
 
 	if err := s.projectAccessRepo.Update(access); err != nil {
 		return nil, err
@@ -72,10 +97,93 @@ func (s *ProjectAccessService) UpdateAccess(accessID int64, accessUpdate *models
 }
 
 // RevokeAccess removes a user's access to a project
-func (s *ProjectAccessService) RevokeAccess(accessID int64) error {
+func (s *ProjectAccessService) RevokeAccess(projectID, accessID int64) error {
+	if _, err := s.GetAccess(projectID, accessID); err != nil {
+		return err
+	}
 	return s.projectAccessRepo.Delete(accessID)
 }
 
+// GetAccess retrieves an access record of a project. Records of other projects are
+// reported as not found.
+func (s *ProjectAccessService) GetAccess(projectID, accessID int64) (*models.ProjectAccess, error) {
+	access, err := s.projectAccessRepo.GetByID(accessID)
+	if err != nil {
+		return nil, err
+	}
+	if access.ProjectID != projectID {
+		return nil, repository.ErrProjectAccessNotFound
+	}
+	return access, nil
+}
+
+// GrantTeamAccess grants every member of a team access to a project. Teams of other
+// organizations are reported as not found.
+func (s *ProjectAccessService) GrantTeamAccess(projectID int64, accessCreate *models.ProjectTeamAccessCreate) (*models.ProjectTeamAccess, error) {
+	project, err := s.projectRepo.GetByID(projectID)
+	if err != nil {
+		return nil, err
+	}
+	team, err := s.teamRepo.GetByID(accessCreate.TeamID)
+	if err != nil {
+		return nil, err
+	}
+	if team.OrganizationID != project.OrganizationID {
+		return nil, repository.ErrTeamNotFound
+	}
+
+	access := &models.ProjectTeamAccess{
+		ProjectID: projectID,
+		TeamID:    team.ID,
+		TeamName:  team.Name,
+		Level:     accessCreate.Level.Normalize(),
+	}
+	if err := s.teamRepo.CreateProjectAccess(access); err != nil {
+		return nil, err
+	}
+	return access, nil
+}
+
+// UpdateTeamAccess changes the access level of a team to a project
+func (s *ProjectAccessService) UpdateTeamAccess(projectID, accessID int64, accessUpdate *models.ProjectAccessUpdate) (*models.ProjectTeamAccess, error) {
+	access, err := s.GetTeamAccess(projectID, accessID)
+	if err != nil {
+		return nil, err
+	}
+
+	access.Level = accessUpdate.Level.Normalize()
+	if err := s.teamRepo.UpdateProjectAccess(access); err != nil {
+		return nil, err
+	}
+	return access, nil
+}
+
+// RevokeTeamAccess removes the access of a team to a project
+func (s *ProjectAccessService) RevokeTeamAccess(projectID, accessID int64) error {
+	if _, err := s.GetTeamAccess(projectID, accessID); err != nil {
+		return err
+	}
+	return s.teamRepo.DeleteProjectAccess(accessID)
+}
+
+// GetTeamAccess retrieves a team access record of a project. Records of other projects
+// are reported as not found.
+func (s *ProjectAccessService) GetTeamAccess(projectID, accessID int64) (*models.ProjectTeamAccess, error) {
+	access, err := s.teamRepo.GetProjectAccess(accessID)
+	if err != nil {
+		return nil, err
+	}
+	if access.ProjectID != projectID {
+		return nil, repository.ErrProjectTeamAccessNotFound
+	}
+	return access, nil
+}
+
+// GetProjectTeamAccess retrieves the teams with access to a project
+func (s *ProjectAccessService) GetProjectTeamAccess(projectID int64) ([]*models.ProjectTeamAccess, error) {
+	return s.teamRepo.ListProjectAccess(projectID)
+}
+
 // GetProjectAccess retrieves all access records for a project
 func (s *ProjectAccessService) GetProjectAccess(projectID int64) ([]*models.ProjectAccess, error) {
 	return s.projectAccessRepo.ListByProject(projectID)
@@ -86,40 +194,178 @@ func (s *ProjectAccessService) GetUserAccess(userID int64) ([]*models.ProjectAcc
 	return s.projectAccessRepo.ListByUser(userID)
 }
 
-// HasEditAccess checks if a user has edit access to a project
-func (s *ProjectAccessService) HasEditAccess(projectID, userID int64) (bool, error) {
-	// Project owner always has edit access
-	project, err := s.projectRepo.GetByID(projectID)
+// Can reports whether a user has a permission in a project of an organization through its
+// ownership, its own grant, the grants of its teams or the organization admin role. Projects
+// of other organizations are reported as not found.
+func (s *ProjectAccessService) Can(organizationID, projectID int64, user *models.User, permission models.Permission) (bool, error) {
+	project, err := s.getOrganizationProject(organizationID, projectID)
 	if err != nil {
 		return false, err
 	}
 
-	if project.OwnerID == userID {
-		return true, nil
+	access, err := s.resolveAccess(project, user)
+	if err != nil {
+		return false, err
 	}
+	return access.Level.Allows(permission), nil
+}
 
-	// Check if user has explicit edit access
-	return s.projectAccessRepo.HasEditAccess(projectID, userID)
+// Authorize reports ErrPermissionDenied, wrapped with the role it takes, unless a user has a
+// permission in a project
+func (s *ProjectAccessService) Authorize(organizationID, projectID int64, user *models.User, permission models.Permission) error {
+	allowed, err := s.Can(organizationID, projectID, user, permission)
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		return fmt.Errorf("%w; it requires the %s role", ErrPermissionDenied, permission.RequiredLevel())
+	}
+	return nil
 }
 
-// HasViewAccess checks if a user has at least view access to a project
-func (s *ProjectAccessService) HasViewAccess(projectID, userID int64) (bool, error) {
-	// Project owner always has view access
+// AuthorizeEntity checks a permission in the project an entity belongs to. Entities of
+// other organizations, including those in the trash, are reported as ErrProjectNotFound;
+// entities that do not exist are left to the handler to report.
+func (s *ProjectAccessService) AuthorizeEntity(organizationID int64, entityType string, id int64, user *models.User, permission models.Permission) error {
+	projectID, err := s.archiveRepo.GetProjectID(entityType, id)
+	if err != nil {
+		return err
+	}
+	if projectID == 0 {
+		return nil
+	}
+
+	projectOrganizationID, err := s.projectRepo.GetOrganizationID(projectID)
+	if err != nil {
+		if errors.Is(err, repository.ErrProjectNotFound) {
+			return nil
+		}
+		return err
+	}
+	if projectOrganizationID != organizationID {
+		return repository.ErrProjectNotFound
+	}
+
+	err = s.Authorize(organizationID, projectID, user, permission)
+	if errors.Is(err, repository.ErrProjectNotFound) {
+		return nil
+	}
+	return err
+}
+
+// EffectiveAccess resolves the role of a user in a project of an organization from its
+// ownership, its own grant, the grants of its teams and the organization admin role, and
+// lists each of them
+func (s *ProjectAccessService) EffectiveAccess(organizationID, projectID, userID int64) (*models.EffectiveAccess, error) {
+	project, err := s.getOrganizationProject(organizationID, projectID)
+	if err != nil {
+		return nil, err
+	}
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.resolveAccess(project, user)
+}
+
+// resolveAccess collects the sources of access of a user to a project. Users outside the
+// organization of the project have no access to it.
+func (s *ProjectAccessService) resolveAccess(project *models.Project, user *models.User) (*models.EffectiveAccess, error) {
+	access := &models.EffectiveAccess{
+		ProjectID: project.ID,
+		UserID:    user.ID,
+		Level:     models.AccessLevelNone,
+		Sources:   []models.AccessSource{},
+	}
+
+	member, err := s.organizationRepo.GetMember(project.OrganizationID, user.ID)
+	if err != nil {
+		if err == repository.ErrOrganizationMemberNotFound {
+			return access, nil
+		}
+		return nil, err
+	}
+
+	if project.OwnerID == user.ID {
+		access.AddSource(models.AccessSource{Type: models.AccessSourceOwner, Level: models.AccessLevelOwner})
+	}
+
+	direct, err := s.projectAccessRepo.GetByProjectAndUser(project.ID, user.ID)
+	if err != nil && err != repository.ErrProjectAccessNotFound {
+		return nil, err
+	}
+	if direct != nil {
+		access.AddSource(models.AccessSource{Type: models.AccessSourceDirect, Level: direct.Level.Normalize(), AccessID: &direct.ID})
+	}
+
+	teamAccess, err := s.teamRepo.ListProjectAccessByMember(project.ID, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, grant := range teamAccess {
+		grant := grant
+		access.AddSource(models.AccessSource{
+			Type:     models.AccessSourceTeam,
+			Level:    grant.Level.Normalize(),
+			AccessID: &grant.ID,
+			TeamID:   &grant.TeamID,
+			TeamName: grant.TeamName,
+		})
+	}
+
+	// Organization admins can do everything the owner can in every project of the organization
+	if member.Role == models.OrganizationRoleAdmin {
+		access.AddSource(models.AccessSource{Type: models.AccessSourceAdmin, Level: models.AccessLevelOwner})
+	}
+
+	return access, nil
+}
+
+// getOrganizationProject retrieves a project of an organization. Projects of other
+// organizations are reported as not found.
+func (s *ProjectAccessService) getOrganizationProject(organizationID, projectID int64) (*models.Project, error) {
 	project, err := s.projectRepo.GetByID(projectID)
 	if err != nil {
-		return false, err
+		return nil, err
+	}
+	if project.OrganizationID != organizationID {
+		return nil, repository.ErrProjectNotFound
+	}
+	return project, nil
+}
+
+// TransferOwnership makes another member of the organization the owner of a project. The
+// previous owner stays on the project as a maintainer.
+func (s *ProjectAccessService) TransferOwnership(projectID, newOwnerID int64) (*models.Project, error) {
+	project, err := s.projectRepo.GetByID(projectID)
+	if err != nil {
+		return nil, err
+	}
+	if project.OwnerID == newOwnerID {
+		return nil, ErrAlreadyOwner
 	}
 
-	if project.OwnerID == userID {
-		return true, nil
+	newOwner, err := s.userRepo.GetByID(newOwnerID)
+	if err != nil {
+		return nil, err
+	}
+	if newOwner.IsDeactivated() {
+		return nil, ErrInactiveNewOwner
+	}
+	if _, err := s.organizationRepo.GetMember(project.OrganizationID, newOwnerID); err != nil {
+		return nil, err
 	}
 
-	// Check if user has explicit view access
-	return s.projectAccessRepo.HasViewAccess(projectID, userID)
+	if err := s.projectRepo.TransferOwnership(projectID, newOwnerID, models.AccessLevelMaintainer); err != nil {
+		return nil, err
+	}
+	return s.projectRepo.GetByID(projectID)
 }
 
-// GetAccessibleProjects retrieves all projects a user has access to
-func (s *ProjectAccessService) GetAccessibleProjects(userID int64, page, pageSize int) ([]*models.Project, error) {
+// GetAccessibleProjects retrieves all projects of an organization a user has access to,
+// optionally including archived ones
+func (s *ProjectAccessService) GetAccessibleProjects(organizationID, userID int64, page, pageSize int, includeArchived bool) ([]*models.Project, error) {
 	if page < 1 {
 		page = 1
 	}
@@ -128,16 +374,21 @@ func (s *ProjectAccessService) GetAccessibleProjects(userID int64, page, pageSiz
 	}
 
 	// Get projects owned by the user
-	ownedProjects, err := s.projectRepo.ListByOwner(userID)
+	ownedProjects, err := s.projectRepo.ListByOwner(organizationID, userID, includeArchived)
 	if err != nil {
 		return nil, err
 	}
 
-	// Get project IDs the user has access to
+	// Get project IDs the user has access to, directly or through its teams
 	accessibleProjectIDs, err := s.projectAccessRepo.GetProjectIDsByUserAccess(userID)
 	if err != nil {
 		return nil, err
 	}
+	teamProjectIDs, err := s.teamRepo.GetProjectIDsByMember(userID)
+	if err != nil {
+		return nil, err
+	}
+	accessibleProjectIDs = append(accessibleProjectIDs, teamProjectIDs...)
 
 	// If user doesn't have access to any projects and doesn't own any, return empty list
 	if len(accessibleProjectIDs) == 0 && len(ownedProjects) == 0 {
@@ -150,11 +401,12 @@ func (s *ProjectAccessService) GetAccessibleProjects(userID int64, page, pageSiz
 		ownedProjectIDs[project.ID] = true
 	}
 
-	// Filter out project IDs that are already owned by the user
+	// Filter out project IDs that are already owned by the user or granted twice
 	var uniqueAccessibleProjectIDs []int64
 	for _, id := range accessibleProjectIDs {
 		if !ownedProjectIDs[id] {
 			uniqueAccessibleProjectIDs = append(uniqueAccessibleProjectIDs, id)
+			ownedProjectIDs[id] = true
 		}
 	}
 
@@ -180,6 +432,12 @@ func (s *ProjectAccessService) GetAccessibleProjects(userID int64, page, pageSiz
 			// Skip projects that can't be retrieved
 			continue
 		}
+		if project.OrganizationID != organizationID {
+			continue
+		}
+		if project.ArchivedAt != nil && !includeArchived {
+			continue
+		}
 		accessibleProjects = append(accessibleProjects, project)
 	}
 
@@ -0,0 +1,379 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mihaamiharu/test-case-management-be/internal/models"
+	"github.com/mihaamiharu/test-case-management-be/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeProjects serves projects from memory
+type fakeProjects struct {
+	repository.ProjectRepositoryInterface
+	projects  map[int64]*models.Project
+	transfers []models.AccessLevel
+}
+
+func (r *fakeProjects) GetByID(id int64) (*models.Project, error) {
+	if project, ok := r.projects[id]; ok {
+		return project, nil
+	}
+	return nil, repository.ErrProjectNotFound
+}
+
+func (r *fakeProjects) TransferOwnership(id, newOwnerID int64, previousOwnerLevel models.AccessLevel) error {
+	r.transfers = append(r.transfers, previousOwnerLevel)
+	r.projects[id].OwnerID = newOwnerID
+	return nil
+}
+
+func (r *fakeProjects) GetOrganizationID(id int64) (int64, error) {
+	if project, ok := r.projects[id]; ok {
+		return project.OrganizationID, nil
+	}
+	return 0, repository.ErrProjectNotFound
+}
+
+func (r *fakeProjects) ListByOwner(organizationID, ownerID int64, includeArchived bool) ([]*models.Project, error) {
+	var projects []*models.Project
+	for id := int64(1); id <= int64(len(r.projects)); id++ {
+		if project := r.projects[id]; project.OrganizationID == organizationID && project.OwnerID == ownerID {
+			projects = append(projects, project)
+		}
+	}
+	return projects, nil
+}
+
+// fakeUsers serves users from memory
+type fakeUsers struct {
+	repository.UserRepositoryInterface
+	users map[int64]*models.User
+}
+
+func (r *fakeUsers) GetByID(id int64) (*models.User, error) {
+	if user, ok := r.users[id]; ok {
+		return user, nil
+	}
+	return nil, repository.ErrUserNotFound
+}
+
+// fakeGrants stores the grants of single users in memory
+type fakeGrants struct {
+	repository.ProjectAccessRepositoryInterface
+	access []*models.ProjectAccess
+}
+
+func (r *fakeGrants) GetByProjectAndUser(projectID, userID int64) (*models.ProjectAccess, error) {
+	for _, access := range r.access {
+		if access.ProjectID == projectID && access.UserID == userID {
+			return access, nil
+		}
+	}
+	return nil, repository.ErrProjectAccessNotFound
+}
+
+func (r *fakeGrants) Create(access *models.ProjectAccess) error {
+	access.ID = int64(len(r.access) + 100)
+	r.access = append(r.access, access)
+	return nil
+}
+
+func (r *fakeGrants) GetProjectIDsByUserAccess(userID int64) ([]int64, error) {
+	var projectIDs []int64
+	for _, access := range r.access {
+		if access.UserID == userID {
+			projectIDs = append(projectIDs, access.ProjectID)
+		}
+	}
+	return projectIDs, nil
+}
+
+// fakeTeams serves teams and their project access from memory
+type fakeTeams struct {
+	repository.TeamRepositoryInterface
+	members map[int64][]int64 // team ID -> user IDs
+	access  []*models.ProjectTeamAccess
+}
+
+func (r *fakeTeams) ListProjectAccessByMember(projectID, userID int64) ([]*models.ProjectTeamAccess, error) {
+	var accessList []*models.ProjectTeamAccess
+	for _, access := range r.access {
+		for _, memberID := range r.members[access.TeamID] {
+			if access.ProjectID == projectID && memberID == userID {
+				accessList = append(accessList, access)
+			}
+		}
+	}
+	return accessList, nil
+}
+
+func (r *fakeTeams) GetProjectIDsByMember(userID int64) ([]int64, error) {
+	var projectIDs []int64
+	for _, access := range r.access {
+		for _, memberID := range r.members[access.TeamID] {
+			if memberID == userID {
+				projectIDs = append(projectIDs, access.ProjectID)
+			}
+		}
+	}
+	return projectIDs, nil
+}
+
+// fakeOrganizations serves organization memberships from memory
+type fakeOrganizations struct {
+	repository.OrganizationRepositoryInterface
+	roles map[int64]map[int64]models.OrganizationRole // organization ID -> user ID -> role
+}
+
+func (r *fakeOrganizations) GetMember(organizationID, userID int64) (*models.OrganizationMember, error) {
+	role, ok := r.roles[organizationID][userID]
+	if !ok {
+		return nil, repository.ErrOrganizationMemberNotFound
+	}
+	return &models.OrganizationMember{OrganizationID: organizationID, UserID: userID, Role: role}, nil
+}
+
+// fakeArchive finds the project of test cases from memory
+type fakeArchive struct {
+	repository.ArchiveRepositoryInterface
+	testCaseProjects map[int64]int64
+}
+
+func (r *fakeArchive) GetProjectID(entityType string, id int64) (int64, error) {
+	return r.testCaseProjects[id], nil
+}
+
+// newTestProjectAccessService creates a service for project 1 of organization 1, owned by
+// user 1. User 2 is a viewer directly and an editor through team 10; user 3 is a tester
+// through team 11; user 4 is an admin of organization 1; user 5 is deactivated. User 6 is an
+// admin of the installation but only a member of organization 2, which owns project 3.
+// Test case 7 belongs to project 1 and test case 8 to project 3.
+func newTestProjectAccessService() *ProjectAccessService {
+	projects := &fakeProjects{projects: map[int64]*models.Project{
+		1: {ID: 1, OrganizationID: 1, Name: "Checkout", OwnerID: 1},
+		2: {ID: 2, OrganizationID: 1, Name: "Billing", OwnerID: 4},
+		3: {ID: 3, OrganizationID: 2, Name: "Payroll", OwnerID: 6},
+	}}
+	deactivatedAt := time.Now()
+	users := &fakeUsers{users: map[int64]*models.User{
+		1: {ID: 1, Role: models.RoleUser},
+		2: {ID: 2, Role: models.RoleUser},
+		3: {ID: 3, Role: models.RoleUser},
+		4: {ID: 4, Role: models.RoleAdmin},
+		5: {ID: 5, Role: models.RoleUser, DeactivatedAt: &deactivatedAt},
+		6: {ID: 6, Role: models.RoleAdmin},
+	}}
+	grants := &fakeGrants{access: []*models.ProjectAccess{
+		{ID: 5, ProjectID: 1, UserID: 2, Level: models.AccessLevelViewer},
+		{ID: 6, ProjectID: 3, UserID: 2, Level: models.AccessLevelViewer},
+	}}
+	teams := &fakeTeams{
+		members: map[int64][]int64{10: {2}, 11: {2, 3}},
+		access: []*models.ProjectTeamAccess{
+			{ID: 7, ProjectID: 1, TeamID: 10, TeamName: "QA", Level: models.AccessLevelEditor},
+			{ID: 8, ProjectID: 1, TeamID: 11, TeamName: "Support", Level: models.AccessLevelTester},
+		},
+	}
+	organizations := &fakeOrganizations{roles: map[int64]map[int64]models.OrganizationRole{
+		1: {
+			1: models.OrganizationRoleMember,
+			2: models.OrganizationRoleMember,
+			3: models.OrganizationRoleMember,
+			4: models.OrganizationRoleAdmin,
+			5: models.OrganizationRoleMember,
+		},
+		2: {6: models.OrganizationRoleAdmin},
+	}}
+	archive := &fakeArchive{testCaseProjects: map[int64]int64{7: 1, 8: 3}}
+	return NewProjectAccessService(grants, projects, users, teams, archive, organizations)
+}
+
+func TestProjectAccessService_Can(t *testing.T) {
+	s := newTestProjectAccessService()
+
+	tests := []struct {
+		name    string
+		userID  int64
+		allowed []models.Permission
+		denied  []models.Permission
+	}{
+		{
+			name:    "owner",
+			userID:  1,
+			allowed: []models.Permission{models.PermissionEditTests, models.PermissionManageProject, models.PermissionOwnProject},
+		},
+		{
+			name:    "editor through a team beats a direct viewer grant",
+			userID:  2,
+			allowed: []models.Permission{models.PermissionExecuteTests, models.PermissionEditTests},
+			denied:  []models.Permission{models.PermissionManageProject},
+		},
+		{
+			name:    "tester through a team",
+			userID:  3,
+			allowed: []models.Permission{models.PermissionViewProject, models.PermissionExecuteTests},
+			denied:  []models.Permission{models.PermissionEditTests},
+		},
+		{
+			name:    "organization admin without grants",
+			userID:  4,
+			allowed: []models.Permission{models.PermissionManageProject, models.PermissionOwnProject},
+		},
+		{
+			name:   "user without grants",
+			userID: 5,
+			denied: []models.Permission{models.PermissionViewProject},
+		},
+		{
+			name:   "admin of the installation outside the organization",
+			userID: 6,
+			denied: []models.Permission{models.PermissionViewProject},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user := &models.User{ID: tt.userID, Role: models.RoleUser}
+			if tt.userID == 6 {
+				user.Role = models.RoleAdmin
+			}
+			for _, permission := range tt.allowed {
+				allowed, err := s.Can(1, 1, user, permission)
+				require.NoError(t, err)
+				assert.True(t, allowed, permission)
+			}
+			for _, permission := range tt.denied {
+				allowed, err := s.Can(1, 1, user, permission)
+				require.NoError(t, err)
+				assert.False(t, allowed, permission)
+			}
+		})
+	}
+}
+
+func TestProjectAccessService_Authorize(t *testing.T) {
+	s := newTestProjectAccessService()
+	tester := &models.User{ID: 3, Role: models.RoleUser}
+
+	// Test case: a denied permission names the role it takes
+	err := s.Authorize(1, 1, tester, models.PermissionEditTests)
+	assert.True(t, errors.Is(err, ErrPermissionDenied))
+	assert.Contains(t, err.Error(), "editor")
+
+	// Test case: entities are checked in the project they belong to
+	assert.NoError(t, s.AuthorizeEntity(1, "test_case", 7, tester, models.PermissionExecuteTests))
+	assert.True(t, errors.Is(s.AuthorizeEntity(1, "test_case", 7, tester, models.PermissionEditTests), ErrPermissionDenied))
+
+	// Test case: entities that do not exist are left to the handler
+	assert.NoError(t, s.AuthorizeEntity(1, "test_case", 404, tester, models.PermissionEditTests))
+
+	_, err = s.Can(1, 4, tester, models.PermissionViewProject)
+	assert.Equal(t, repository.ErrProjectNotFound, err)
+
+	// Test case: projects and entities of other organizations are not found, even for their admins
+	admin := &models.User{ID: 6, Role: models.RoleAdmin}
+	assert.Equal(t, repository.ErrProjectNotFound, s.Authorize(2, 1, admin, models.PermissionViewProject))
+	assert.Equal(t, repository.ErrProjectNotFound, s.AuthorizeEntity(1, "test_case", 8, tester, models.PermissionViewProject))
+	assert.NoError(t, s.AuthorizeEntity(2, "test_case", 8, admin, models.PermissionEditTests))
+}
+
+func TestProjectAccessService_TransferOwnership(t *testing.T) {
+	s := newTestProjectAccessService()
+
+	// Test case: deactivated users and the current owner cannot take over a project
+	_, err := s.TransferOwnership(1, 5)
+	assert.Equal(t, ErrInactiveNewOwner, err)
+	_, err = s.TransferOwnership(1, 1)
+	assert.Equal(t, ErrAlreadyOwner, err)
+
+	// Test case: only members of the organization of the project can own it
+	_, err = s.TransferOwnership(1, 6)
+	assert.Equal(t, repository.ErrOrganizationMemberNotFound, err)
+
+	// Test case: the previous owner stays on as a maintainer
+	project, err := s.TransferOwnership(1, 2)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), project.OwnerID)
+	assert.Equal(t, []models.AccessLevel{models.AccessLevelMaintainer}, s.projectRepo.(*fakeProjects).transfers)
+}
+
+func TestProjectAccessService_GrantAccess(t *testing.T) {
+	s := newTestProjectAccessService()
+
+	// Test case: the former level names are stored as roles
+	access, err := s.GrantAccess(1, &models.ProjectAccessCreate{UserID: 3, Level: models.AccessLevelEdit})
+	require.NoError(t, err)
+	assert.Equal(t, models.AccessLevelEditor, access.Level)
+
+	// Test case: the owner cannot be granted a role
+	_, err = s.GrantAccess(1, &models.ProjectAccessCreate{UserID: 1, Level: models.AccessLevelViewer})
+	assert.Equal(t, ErrGrantToOwner, err)
+
+	// Test case: users outside the organization of the project cannot be granted a role
+	_, err = s.GrantAccess(1, &models.ProjectAccessCreate{UserID: 6, Level: models.AccessLevelViewer})
+	assert.Equal(t, repository.ErrOrganizationMemberNotFound, err)
+}
+
+func TestProjectAccessService_EffectiveAccess(t *testing.T) {
+	s := newTestProjectAccessService()
+
+	// Test case: every source of access is listed and the highest role wins
+	access, err := s.EffectiveAccess(1, 1, 2)
+	require.NoError(t, err)
+	assert.Equal(t, models.AccessLevelEditor, access.Level)
+	require.Len(t, access.Sources, 3)
+	assert.Equal(t, models.AccessSourceDirect, access.Sources[0].Type)
+	assert.Equal(t, int64(5), *access.Sources[0].AccessID)
+	assert.Equal(t, models.AccessSource{
+		Type:     models.AccessSourceTeam,
+		Level:    models.AccessLevelEditor,
+		AccessID: access.Sources[1].AccessID,
+		TeamID:   access.Sources[1].TeamID,
+		TeamName: "QA",
+	}, access.Sources[1])
+	assert.Equal(t, int64(10), *access.Sources[1].TeamID)
+
+	// Test case: the owner and the organization admin role are sources too
+	access, err = s.EffectiveAccess(1, 1, 1)
+	require.NoError(t, err)
+	assert.Equal(t, []models.AccessSource{{Type: models.AccessSourceOwner, Level: models.AccessLevelOwner}}, access.Sources)
+	access, err = s.EffectiveAccess(1, 1, 4)
+	require.NoError(t, err)
+	assert.Equal(t, models.AccessLevelOwner, access.Level)
+	assert.Equal(t, models.AccessSourceAdmin, access.Sources[0].Type)
+
+	// Test case: users without access get level none
+	access, err = s.EffectiveAccess(1, 2, 3)
+	require.NoError(t, err)
+	assert.Equal(t, models.AccessLevelNone, access.Level)
+	assert.Empty(t, access.Sources)
+
+	// Test case: grants do not reach outside the organization of the user
+	access, err = s.EffectiveAccess(2, 3, 2)
+	require.NoError(t, err)
+	assert.Equal(t, models.AccessLevelNone, access.Level)
+	assert.Empty(t, access.Sources)
+
+	_, err = s.EffectiveAccess(1, 3, 1)
+	assert.Equal(t, repository.ErrProjectNotFound, err)
+}
+
+func TestProjectAccessService_GetAccessibleProjects(t *testing.T) {
+	s := newTestProjectAccessService()
+
+	// Test case: a project granted directly and through teams is listed once, and projects
+	// of other organizations are left out
+	projects, err := s.GetAccessibleProjects(1, 2, 1, 10, false)
+	require.NoError(t, err)
+	require.Len(t, projects, 1)
+	assert.Equal(t, int64(1), projects[0].ID)
+
+	projects, err = s.GetAccessibleProjects(1, 3, 1, 10, false)
+	require.NoError(t, err)
+	require.Len(t, projects, 1)
+	assert.Equal(t, "Checkout", projects[0].Name)
+}
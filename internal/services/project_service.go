@@ -17,12 +17,13 @@ func NewProjectService(projectRepo repository.ProjectRepositoryInterface) *Proje
 	}
 }
 
-// Create creates a new project
-func (s *ProjectService) Create(projectCreate *models.ProjectCreate, ownerID int64) (*models.Project, error) {
+// Create creates a new project in an organization
+func (s *ProjectService) Create(projectCreate *models.ProjectCreate, organizationID, ownerID int64) (*models.Project, error) {
 	project := &models.Project{
-		Name:        projectCreate.Name,
-		Description: projectCreate.Description,
-		OwnerID:     ownerID,
+		OrganizationID: organizationID,
+		Name:           projectCreate.Name,
+		Description:    projectCreate.Description,
+		OwnerID:        ownerID,
 	}
 
 	if err := s.projectRepo.Create(project); err != nil {
@@ -64,13 +65,15 @@ func (s *ProjectService) Delete(id int64) error {
 	return s.projectRepo.Delete(id)
 }
 
-// ListByOwner retrieves all projects for a specific owner
-func (s *ProjectService) ListByOwner(ownerID int64) ([]*models.Project, error) {
-	return s.projectRepo.ListByOwner(ownerID)
+// ListByOwner retrieves all projects of an organization for a specific owner, optionally
+// including archived ones
+func (s *ProjectService) ListByOwner(organizationID, ownerID int64, includeArchived bool) ([]*models.Project, error) {
+	return s.projectRepo.ListByOwner(organizationID, ownerID, includeArchived)
 }
 
-// List retrieves all projects with pagination
-func (s *ProjectService) List(page, pageSize int) ([]*models.Project, error) {
+// List retrieves all projects of an organization with pagination, optionally including
+// archived ones
+func (s *ProjectService) List(organizationID int64, page, pageSize int, includeArchived bool) ([]*models.Project, error) {
 	if page < 1 {
 		page = 1
 	}
@@ -79,7 +82,7 @@ func (s *ProjectService) List(page, pageSize int) ([]*models.Project, error) {
 	}
 
 	offset := (page - 1) * pageSize
-	return s.projectRepo.List(pageSize, offset)
+	return s.projectRepo.List(organizationID, pageSize, offset, includeArchived)
 }
 
 // IsOwner checks if a user is the owner of a project
@@ -0,0 +1,35 @@
+-- Create shared_steps table for reusable step groups
+CREATE TABLE IF NOT EXISTS shared_steps (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    project_id BIGINT NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    description TEXT,
+    version INT NOT NULL DEFAULT 1,
+    created_by BIGINT NOT NULL,
+    updated_by BIGINT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    FOREIGN KEY (project_id) REFERENCES projects(id) ON DELETE CASCADE,
+    FOREIGN KEY (created_by) REFERENCES users(id),
+    FOREIGN KEY (updated_by) REFERENCES users(id),
+    UNIQUE KEY unique_shared_step_name_per_project (project_id, name)
+);
+
+-- Create shared_step_items table for the steps inside a shared step group
+CREATE TABLE IF NOT EXISTS shared_step_items (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    shared_step_id BIGINT NOT NULL,
+    step_number INT NOT NULL,
+    step_type ENUM('given', 'when', 'then', 'and', 'but') NOT NULL,
+    description TEXT NOT NULL,
+    expected_result TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    FOREIGN KEY (shared_step_id) REFERENCES shared_steps(id) ON DELETE CASCADE,
+    UNIQUE KEY unique_item_number_per_shared_step (shared_step_id, step_number)
+);
+
+-- Allow a test step to reference a shared step group instead of holding its own text
+ALTER TABLE test_steps
+ADD COLUMN shared_step_id BIGINT NULL AFTER test_case_id,
+ADD CONSTRAINT fk_test_step_shared_step FOREIGN KEY (shared_step_id) REFERENCES shared_steps(id) ON DELETE RESTRICT;
@@ -0,0 +1,19 @@
+-- Create test_case_data_rows table for Scenario Outline style parameter rows
+CREATE TABLE IF NOT EXISTS test_case_data_rows (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    test_case_id BIGINT NOT NULL,
+    -- row_number is a reserved word since MySQL 8.0
+    row_index INT NOT NULL,
+    name VARCHAR(100),
+    parameter_values JSON NOT NULL COMMENT 'Parameter name to value map',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    FOREIGN KEY (test_case_id) REFERENCES test_cases(id) ON DELETE CASCADE,
+    UNIQUE KEY unique_row_index_per_test (test_case_id, row_index)
+);
+
+-- Track which data row an execution was expanded from
+ALTER TABLE test_executions
+ADD COLUMN data_row_id BIGINT NULL AFTER test_case_id,
+ADD COLUMN parameter_values JSON NULL COMMENT 'Snapshot of the data row values used for this execution' AFTER data_row_id,
+ADD CONSTRAINT fk_test_execution_data_row FOREIGN KEY (data_row_id) REFERENCES test_case_data_rows(id) ON DELETE SET NULL;
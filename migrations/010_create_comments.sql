@@ -0,0 +1,30 @@
+-- Create comments table for threaded discussions on test cases and their steps
+CREATE TABLE IF NOT EXISTS comments (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    test_case_id BIGINT NOT NULL,
+    step_id BIGINT NULL COMMENT 'Set when the comment is about a single step',
+    parent_id BIGINT NULL COMMENT 'Set for replies',
+    body TEXT NOT NULL COMMENT 'Markdown',
+    created_by BIGINT NOT NULL,
+    is_resolved BOOLEAN NOT NULL DEFAULT FALSE,
+    resolved_by BIGINT NULL,
+    resolved_at TIMESTAMP NULL,
+    edited_at TIMESTAMP NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    FOREIGN KEY (test_case_id) REFERENCES test_cases(id) ON DELETE CASCADE,
+    FOREIGN KEY (step_id) REFERENCES test_steps(id) ON DELETE SET NULL,
+    FOREIGN KEY (parent_id) REFERENCES comments(id) ON DELETE CASCADE,
+    FOREIGN KEY (created_by) REFERENCES users(id),
+    FOREIGN KEY (resolved_by) REFERENCES users(id),
+    INDEX idx_comments_test_case (test_case_id, created_at)
+);
+
+-- Create comment_mentions table for users mentioned in a comment
+CREATE TABLE IF NOT EXISTS comment_mentions (
+    comment_id BIGINT NOT NULL,
+    user_id BIGINT NOT NULL,
+    PRIMARY KEY (comment_id, user_id),
+    FOREIGN KEY (comment_id) REFERENCES comments(id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
@@ -0,0 +1,36 @@
+-- Add review workflow statuses to test cases and their history
+ALTER TABLE test_cases
+MODIFY COLUMN status ENUM('draft', 'in_review', 'approved', 'rejected', 'active', 'deprecated') NOT NULL DEFAULT 'draft';
+
+ALTER TABLE test_case_history
+MODIFY COLUMN status ENUM('draft', 'in_review', 'approved', 'rejected', 'active', 'deprecated') NOT NULL;
+
+-- Create project_review_settings table for per-project approval rules
+CREATE TABLE IF NOT EXISTS project_review_settings (
+    project_id BIGINT PRIMARY KEY,
+    required_approvals INT NOT NULL DEFAULT 0 COMMENT 'Approvals needed before a test case can become active; 0 disables the check',
+    updated_by BIGINT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    FOREIGN KEY (project_id) REFERENCES projects(id) ON DELETE CASCADE,
+    FOREIGN KEY (updated_by) REFERENCES users(id)
+);
+
+-- Create test_case_reviews table for review requests and decisions per test case version
+CREATE TABLE IF NOT EXISTS test_case_reviews (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    test_case_id BIGINT NOT NULL,
+    version INT NOT NULL COMMENT 'Test case version under review',
+    reviewer_id BIGINT NOT NULL,
+    requested_by BIGINT NOT NULL,
+    status ENUM('pending', 'approved', 'changes_requested') NOT NULL DEFAULT 'pending',
+    comment_id BIGINT NULL COMMENT 'Comment left with the decision',
+    decided_at TIMESTAMP NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    FOREIGN KEY (test_case_id) REFERENCES test_cases(id) ON DELETE CASCADE,
+    FOREIGN KEY (reviewer_id) REFERENCES users(id),
+    FOREIGN KEY (requested_by) REFERENCES users(id),
+    FOREIGN KEY (comment_id) REFERENCES comments(id) ON DELETE SET NULL,
+    UNIQUE KEY unique_reviewer_per_version (test_case_id, version, reviewer_id)
+);
@@ -0,0 +1,27 @@
+-- Create custom_field_definitions table for per-project test case metadata
+CREATE TABLE IF NOT EXISTS custom_field_definitions (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    project_id BIGINT NOT NULL,
+    name VARCHAR(50) NOT NULL COMMENT 'Key used in API payloads, filters and CSV headers',
+    label VARCHAR(100) NOT NULL,
+    field_type ENUM('text', 'number', 'enum', 'multi_select', 'user', 'date', 'boolean') NOT NULL,
+    options JSON NULL COMMENT 'Allowed values for enum and multi_select fields',
+    is_required BOOLEAN NOT NULL DEFAULT FALSE,
+    position INT NOT NULL DEFAULT 0,
+    created_by BIGINT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    FOREIGN KEY (project_id) REFERENCES projects(id) ON DELETE CASCADE,
+    FOREIGN KEY (created_by) REFERENCES users(id),
+    UNIQUE KEY unique_custom_field_name_per_project (project_id, name)
+);
+
+-- Create test_case_custom_values table for the custom field values of each test case
+CREATE TABLE IF NOT EXISTS test_case_custom_values (
+    test_case_id BIGINT NOT NULL,
+    field_id BIGINT NOT NULL,
+    value JSON NOT NULL,
+    PRIMARY KEY (test_case_id, field_id),
+    FOREIGN KEY (test_case_id) REFERENCES test_cases(id) ON DELETE CASCADE,
+    FOREIGN KEY (field_id) REFERENCES custom_field_definitions(id) ON DELETE CASCADE
+);
@@ -0,0 +1,45 @@
+-- Store test case statuses and priorities as plain values so that projects can define their own.
+-- Existing values are kept as they are; projects without a workflow use the built-in one.
+ALTER TABLE test_cases
+MODIFY COLUMN status VARCHAR(50) NOT NULL DEFAULT 'draft',
+MODIFY COLUMN priority VARCHAR(50) NOT NULL DEFAULT 'medium';
+
+ALTER TABLE test_case_history
+MODIFY COLUMN status VARCHAR(50) NOT NULL,
+MODIFY COLUMN priority VARCHAR(50) NOT NULL;
+
+-- Create project_workflow_statuses table for the test case statuses of a project
+CREATE TABLE IF NOT EXISTS project_workflow_statuses (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    project_id BIGINT NOT NULL,
+    name VARCHAR(50) NOT NULL COMMENT 'Value stored on test cases',
+    label VARCHAR(100) NOT NULL,
+    color VARCHAR(7) NOT NULL DEFAULT '#9e9e9e' COMMENT 'Hex color, e.g. #4caf50',
+    position INT NOT NULL DEFAULT 0,
+    is_terminal BOOLEAN NOT NULL DEFAULT FALSE COMMENT 'Retired test cases, which cannot be reviewed',
+    FOREIGN KEY (project_id) REFERENCES projects(id) ON DELETE CASCADE,
+    UNIQUE KEY unique_workflow_status_per_project (project_id, name)
+);
+
+-- Create project_workflow_priorities table for the test case priorities of a project
+CREATE TABLE IF NOT EXISTS project_workflow_priorities (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    project_id BIGINT NOT NULL,
+    name VARCHAR(50) NOT NULL COMMENT 'Value stored on test cases',
+    label VARCHAR(100) NOT NULL,
+    color VARCHAR(7) NOT NULL DEFAULT '#9e9e9e' COMMENT 'Hex color, e.g. #f44336',
+    position INT NOT NULL DEFAULT 0,
+    is_default BOOLEAN NOT NULL DEFAULT FALSE COMMENT 'Priority of new test cases that do not set one',
+    FOREIGN KEY (project_id) REFERENCES projects(id) ON DELETE CASCADE,
+    UNIQUE KEY unique_workflow_priority_per_project (project_id, name)
+);
+
+-- Create project_workflow_transitions table for the allowed status changes of a project.
+-- A project without transitions allows every change.
+CREATE TABLE IF NOT EXISTS project_workflow_transitions (
+    project_id BIGINT NOT NULL,
+    from_status VARCHAR(50) NOT NULL,
+    to_status VARCHAR(50) NOT NULL,
+    PRIMARY KEY (project_id, from_status, to_status),
+    FOREIGN KEY (project_id) REFERENCES projects(id) ON DELETE CASCADE
+);
@@ -0,0 +1,19 @@
+-- Create audit_log table recording every change made through the API.
+-- Entries keep no foreign keys so that they outlive the users, projects and entities they describe.
+CREATE TABLE IF NOT EXISTS audit_log (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    actor_id BIGINT NULL COMMENT 'User who made the change',
+    action ENUM('create', 'update', 'delete') NOT NULL,
+    entity_type VARCHAR(50) NOT NULL COMMENT 'e.g. project, test_case, test_step',
+    entity_id BIGINT NULL,
+    project_id BIGINT NULL COMMENT 'Project the entity belongs to, when known',
+    method VARCHAR(10) NOT NULL,
+    path VARCHAR(255) NOT NULL,
+    ip_address VARCHAR(45) NOT NULL,
+    before_data JSON NULL COMMENT 'Entity before the change',
+    after_data JSON NULL COMMENT 'Entity after the change',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    INDEX idx_audit_log_project (project_id, created_at),
+    INDEX idx_audit_log_entity (entity_type, entity_id),
+    INDEX idx_audit_log_actor (actor_id, created_at)
+);
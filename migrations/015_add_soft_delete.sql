@@ -0,0 +1,16 @@
+-- Deleted projects, test suites and test cases move to the trash instead of being removed.
+-- deleted_at marks a trashed row; rows are purged permanently once the retention period has passed.
+-- deleted_with_parent marks rows trashed together with their project or suite, which are restored with it.
+ALTER TABLE projects
+ADD COLUMN deleted_at TIMESTAMP NULL DEFAULT NULL,
+ADD INDEX idx_projects_deleted_at (deleted_at);
+
+ALTER TABLE test_suites
+ADD COLUMN deleted_at TIMESTAMP NULL DEFAULT NULL,
+ADD COLUMN deleted_with_parent BOOLEAN NOT NULL DEFAULT FALSE COMMENT 'Trashed because its project was deleted',
+ADD INDEX idx_test_suites_deleted_at (deleted_at);
+
+ALTER TABLE test_cases
+ADD COLUMN deleted_at TIMESTAMP NULL DEFAULT NULL,
+ADD COLUMN deleted_with_parent BOOLEAN NOT NULL DEFAULT FALSE COMMENT 'Trashed because its project or suite was deleted',
+ADD INDEX idx_test_cases_deleted_at (deleted_at);
@@ -0,0 +1,7 @@
+-- Archived projects and test suites keep their test history but are read-only.
+-- archived_at marks an archived row; unarchiving clears it.
+ALTER TABLE projects
+ADD COLUMN archived_at TIMESTAMP NULL DEFAULT NULL;
+
+ALTER TABLE test_suites
+ADD COLUMN archived_at TIMESTAMP NULL DEFAULT NULL;
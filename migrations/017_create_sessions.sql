@@ -0,0 +1,34 @@
+-- Create tables for login sessions, their refresh tokens and revoked access tokens.
+-- A session lasts from login until logout, revocation or expiry. Its refresh token is rotated
+-- on every use; presenting a token that was already used revokes the whole session.
+CREATE TABLE IF NOT EXISTS sessions (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    user_id BIGINT NOT NULL,
+    access_token_id VARCHAR(64) NOT NULL COMMENT 'jti of the latest access token issued for the session',
+    user_agent VARCHAR(255) NOT NULL DEFAULT '',
+    ip_address VARCHAR(45) NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_used_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    expires_at TIMESTAMP NOT NULL,
+    revoked_at TIMESTAMP NULL DEFAULT NULL,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    INDEX idx_sessions_user (user_id, revoked_at)
+);
+
+-- Refresh tokens are stored as SHA-256 hashes; used_at is set when a token is rotated
+CREATE TABLE IF NOT EXISTS refresh_tokens (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    session_id BIGINT NOT NULL,
+    token_hash CHAR(64) NOT NULL UNIQUE,
+    expires_at TIMESTAMP NOT NULL,
+    used_at TIMESTAMP NULL DEFAULT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (session_id) REFERENCES sessions(id) ON DELETE CASCADE
+);
+
+-- Access tokens of revoked sessions, kept until they would have expired anyway
+CREATE TABLE IF NOT EXISTS token_denylist (
+    token_id VARCHAR(64) PRIMARY KEY COMMENT 'jti of the revoked access token',
+    expires_at TIMESTAMP NOT NULL,
+    INDEX idx_token_denylist_expires (expires_at)
+);
@@ -0,0 +1,18 @@
+-- Create api_tokens table for personal access tokens used by CI pipelines and scripts.
+-- Only a SHA-256 hash of each token is stored; token_prefix identifies it in listings.
+CREATE TABLE IF NOT EXISTS api_tokens (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    user_id BIGINT NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    token_prefix VARCHAR(16) NOT NULL,
+    token_hash CHAR(64) NOT NULL UNIQUE,
+    scopes VARCHAR(100) NOT NULL COMMENT 'Comma-separated list of read, write and results',
+    project_id BIGINT NULL COMMENT 'Project the token is restricted to, if any',
+    expires_at TIMESTAMP NULL DEFAULT NULL,
+    last_used_at TIMESTAMP NULL DEFAULT NULL,
+    revoked_at TIMESTAMP NULL DEFAULT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (project_id) REFERENCES projects(id) ON DELETE CASCADE,
+    INDEX idx_api_tokens_user (user_id)
+);
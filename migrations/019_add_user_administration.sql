@@ -0,0 +1,5 @@
+-- Deactivated users keep their history but can no longer authenticate.
+-- password_reset_required makes a user change their password before doing anything else.
+ALTER TABLE users
+ADD COLUMN deactivated_at TIMESTAMP NULL DEFAULT NULL,
+ADD COLUMN password_reset_required BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1,20 @@
+-- Email verification and password reset tokens are single-use and expire.
+-- Only a SHA-256 hash of each token is stored.
+ALTER TABLE users
+ADD COLUMN email_verified_at TIMESTAMP NULL DEFAULT NULL;
+
+-- Users created before verification existed are treated as verified
+UPDATE users SET email_verified_at = created_at;
+
+CREATE TABLE IF NOT EXISTS user_tokens (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    user_id BIGINT NOT NULL,
+    purpose ENUM('email_verification', 'password_reset') NOT NULL,
+    token_hash CHAR(64) NOT NULL UNIQUE,
+    email VARCHAR(100) NOT NULL COMMENT 'Address the token was sent to',
+    expires_at TIMESTAMP NOT NULL,
+    used_at TIMESTAMP NULL DEFAULT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    INDEX idx_user_tokens_user (user_id, purpose)
+);
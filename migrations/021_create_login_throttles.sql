@@ -0,0 +1,16 @@
+-- Failed logins are counted per account (email address) and per IP address.
+-- Too many failures lock the subject until locked_until; rows are removed once they expire.
+CREATE TABLE IF NOT EXISTS login_throttles (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    scope ENUM('account', 'ip') NOT NULL,
+    subject VARCHAR(255) NOT NULL COMMENT 'Lowercased email address or IP address',
+    failures INT NOT NULL DEFAULT 0,
+    last_failure_at TIMESTAMP NOT NULL,
+    locked_until TIMESTAMP NULL DEFAULT NULL,
+    UNIQUE KEY uk_login_throttles_subject (scope, subject),
+    INDEX idx_login_throttles_last_failure (last_failure_at)
+);
+
+-- Lockouts and unlocks are recorded in the audit log
+ALTER TABLE audit_log
+MODIFY COLUMN action ENUM('create', 'update', 'delete', 'lock', 'unlock') NOT NULL;
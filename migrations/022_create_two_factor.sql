@@ -0,0 +1,22 @@
+-- Two-factor authentication with time-based one-time passwords (TOTP).
+-- The TOTP secret is stored encrypted; enabled_at stays NULL until the user confirms
+-- enrollment with a valid code. last_used_step stops a code from being used twice.
+CREATE TABLE IF NOT EXISTS user_two_factor (
+    user_id BIGINT PRIMARY KEY,
+    secret_encrypted VARCHAR(255) NOT NULL,
+    enabled_at TIMESTAMP NULL DEFAULT NULL,
+    last_used_step BIGINT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+-- Single-use recovery codes for users who lost their authenticator; only hashes are stored
+CREATE TABLE IF NOT EXISTS recovery_codes (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    user_id BIGINT NOT NULL,
+    code_hash CHAR(64) NOT NULL,
+    used_at TIMESTAMP NULL DEFAULT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    UNIQUE KEY uk_recovery_codes_user_code (user_id, code_hash)
+);
@@ -0,0 +1,15 @@
+-- Replaces the view and edit access levels with the viewer, tester, editor and maintainer roles.
+-- Existing view grants become viewer grants and edit grants become editor grants.
+ALTER TABLE project_access
+    MODIFY level ENUM('view', 'edit', 'viewer', 'tester', 'editor', 'maintainer') NOT NULL;
+UPDATE project_access SET level = 'viewer' WHERE level = 'view';
+UPDATE project_access SET level = 'editor' WHERE level = 'edit';
+ALTER TABLE project_access
+    MODIFY level ENUM('viewer', 'tester', 'editor', 'maintainer') NOT NULL;
+
+ALTER TABLE project_team_access
+    MODIFY level ENUM('view', 'edit', 'viewer', 'tester', 'editor', 'maintainer') NOT NULL;
+UPDATE project_team_access SET level = 'viewer' WHERE level = 'view';
+UPDATE project_team_access SET level = 'editor' WHERE level = 'edit';
+ALTER TABLE project_team_access
+    MODIFY level ENUM('viewer', 'tester', 'editor', 'maintainer') NOT NULL;
@@ -0,0 +1,54 @@
+-- Organizations are the tenants of the system. Users belong to one or more organizations,
+-- and projects, tags and teams belong to exactly one.
+CREATE TABLE IF NOT EXISTS organizations (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    slug VARCHAR(100) NOT NULL,
+    created_by BIGINT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    UNIQUE KEY uk_organizations_slug (slug),
+    FOREIGN KEY (created_by) REFERENCES users(id) ON DELETE SET NULL
+);
+
+-- Organization admins manage the members, teams and every project of their organization
+CREATE TABLE IF NOT EXISTS organization_members (
+    organization_id BIGINT NOT NULL,
+    user_id BIGINT NOT NULL,
+    role ENUM('admin', 'member') NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (organization_id, user_id),
+    INDEX idx_organization_members_user (user_id),
+    FOREIGN KEY (organization_id) REFERENCES organizations(id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+-- Existing data moves into a default organization. Every user joins it, and admins become
+-- its admins.
+INSERT INTO organizations (id, name, slug) VALUES (1, 'Default', 'default');
+INSERT INTO organization_members (organization_id, user_id, role)
+SELECT 1, id, IF(role = 'admin', 'admin', 'member') FROM users;
+
+ALTER TABLE projects
+ADD COLUMN organization_id BIGINT NOT NULL DEFAULT 1 AFTER id;
+ALTER TABLE projects
+ALTER COLUMN organization_id DROP DEFAULT,
+ADD INDEX idx_projects_organization (organization_id),
+ADD CONSTRAINT fk_project_organization FOREIGN KEY (organization_id) REFERENCES organizations(id) ON DELETE CASCADE;
+
+-- Tag and team names are unique per organization
+ALTER TABLE tags
+ADD COLUMN organization_id BIGINT NOT NULL DEFAULT 1 AFTER id;
+ALTER TABLE tags
+ALTER COLUMN organization_id DROP DEFAULT,
+DROP INDEX unique_tag_name,
+ADD UNIQUE KEY unique_tag_name (organization_id, name),
+ADD CONSTRAINT fk_tag_organization FOREIGN KEY (organization_id) REFERENCES organizations(id) ON DELETE CASCADE;
+
+ALTER TABLE teams
+ADD COLUMN organization_id BIGINT NOT NULL DEFAULT 1 AFTER id;
+ALTER TABLE teams
+ALTER COLUMN organization_id DROP DEFAULT,
+DROP INDEX uk_teams_name,
+ADD UNIQUE KEY uk_teams_name (organization_id, name),
+ADD CONSTRAINT fk_team_organization FOREIGN KEY (organization_id) REFERENCES organizations(id) ON DELETE CASCADE;
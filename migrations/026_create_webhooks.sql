@@ -0,0 +1,38 @@
+-- Webhooks send the events of a project to an external URL, signed with their secret
+CREATE TABLE IF NOT EXISTS webhooks (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    project_id BIGINT NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    url VARCHAR(500) NOT NULL,
+    secret VARCHAR(100) NOT NULL COMMENT 'Key of the HMAC-SHA256 signature of each payload',
+    events VARCHAR(255) NOT NULL COMMENT 'Comma-separated list of the event types sent',
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_by BIGINT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    INDEX idx_webhooks_project (project_id),
+    FOREIGN KEY (project_id) REFERENCES projects(id) ON DELETE CASCADE,
+    FOREIGN KEY (created_by) REFERENCES users(id) ON DELETE SET NULL
+);
+
+-- Every event sent to a webhook, with its attempts. Pending deliveries are retried with
+-- exponential backoff at next_attempt_at until they run out of attempts.
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    webhook_id BIGINT NOT NULL,
+    event_type VARCHAR(50) NOT NULL,
+    payload MEDIUMTEXT NOT NULL,
+    status ENUM('pending', 'succeeded', 'failed') NOT NULL DEFAULT 'pending',
+    attempts INT NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMP NULL DEFAULT NULL,
+    response_status INT NULL,
+    response_body TEXT,
+    error TEXT,
+    redelivery_of BIGINT NULL COMMENT 'Delivery whose payload this delivery sends again',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    delivered_at TIMESTAMP NULL DEFAULT NULL,
+    INDEX idx_webhook_deliveries_webhook (webhook_id, id),
+    INDEX idx_webhook_deliveries_due (status, next_attempt_at),
+    FOREIGN KEY (webhook_id) REFERENCES webhooks(id) ON DELETE CASCADE,
+    FOREIGN KEY (redelivery_of) REFERENCES webhook_deliveries(id) ON DELETE SET NULL
+);
@@ -0,0 +1,37 @@
+-- A test run can be assigned to the user who executes it
+ALTER TABLE test_runs
+    ADD COLUMN assigned_to BIGINT NULL AFTER completed_at,
+    ADD CONSTRAINT fk_test_runs_assigned_to FOREIGN KEY (assigned_to) REFERENCES users(id) ON DELETE SET NULL;
+
+-- Notifications tell a user about changes to their test cases and test runs. Unread
+-- notifications that were not emailed yet go into the daily digest.
+CREATE TABLE IF NOT EXISTS notifications (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    user_id BIGINT NOT NULL,
+    project_id BIGINT NULL,
+    type VARCHAR(50) NOT NULL,
+    title VARCHAR(255) NOT NULL,
+    entity_type VARCHAR(50) NOT NULL,
+    entity_id BIGINT NOT NULL,
+    actor_id BIGINT NULL,
+    read_at TIMESTAMP NULL DEFAULT NULL,
+    emailed_at TIMESTAMP NULL DEFAULT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    INDEX idx_notifications_user (user_id, id),
+    INDEX idx_notifications_digest (emailed_at, read_at),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (project_id) REFERENCES projects(id) ON DELETE CASCADE,
+    FOREIGN KEY (actor_id) REFERENCES users(id) ON DELETE SET NULL
+);
+
+-- Choices of a user about a type of notification; types without a row are notified in the
+-- app and in the digest
+CREATE TABLE IF NOT EXISTS notification_preferences (
+    user_id BIGINT NOT NULL,
+    type VARCHAR(50) NOT NULL,
+    in_app BOOLEAN NOT NULL DEFAULT TRUE,
+    email_digest BOOLEAN NOT NULL DEFAULT TRUE,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, type),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
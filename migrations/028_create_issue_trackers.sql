@@ -0,0 +1,22 @@
+-- The external issue tracker of a project that defects are reported to
+CREATE TABLE IF NOT EXISTS project_issue_trackers (
+    project_id BIGINT PRIMARY KEY,
+    provider ENUM('jira', 'github', 'gitlab') NOT NULL,
+    base_url VARCHAR(500) NOT NULL,
+    project_key VARCHAR(255) NOT NULL COMMENT 'Jira project key, GitHub owner/repository or GitLab project path',
+    username VARCHAR(255) NOT NULL DEFAULT '' COMMENT 'Jira account email the token belongs to',
+    token VARCHAR(500) NOT NULL,
+    webhook_secret VARCHAR(255) NOT NULL DEFAULT '',
+    created_by BIGINT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    FOREIGN KEY (project_id) REFERENCES projects(id) ON DELETE CASCADE,
+    FOREIGN KEY (created_by) REFERENCES users(id) ON DELETE SET NULL
+);
+
+-- Defects remember the issue they were reported as and its status in the tracker
+ALTER TABLE defects
+    ADD COLUMN external_url VARCHAR(500) NULL AFTER external_id,
+    ADD COLUMN external_status VARCHAR(100) NULL AFTER external_url,
+    ADD COLUMN external_synced_at TIMESTAMP NULL DEFAULT NULL AFTER external_status,
+    ADD INDEX idx_defects_external_id (external_id);
@@ -0,0 +1,21 @@
+-- Audit entries belong to the organization the change was made in, so that each
+-- organization only sees its own entries. Entries without one, such as login lockouts,
+-- concern the whole installation.
+ALTER TABLE audit_log
+    ADD COLUMN organization_id BIGINT NULL AFTER project_id,
+    ADD INDEX idx_audit_log_organization (organization_id, created_at);
+
+-- Existing entries belong to the organization of their project, tag or team
+UPDATE audit_log a
+JOIN projects p ON p.id = a.project_id
+SET a.organization_id = p.organization_id;
+
+UPDATE audit_log a
+JOIN tags t ON a.entity_type = 'tag' AND t.id = a.entity_id
+SET a.organization_id = t.organization_id
+WHERE a.organization_id IS NULL;
+
+UPDATE audit_log a
+JOIN teams t ON a.entity_type = 'team' AND t.id = a.entity_id
+SET a.organization_id = t.organization_id
+WHERE a.organization_id IS NULL;
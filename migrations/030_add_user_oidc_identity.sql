@@ -0,0 +1,6 @@
+-- Users who log in with single sign-on are matched by the issuer and subject of their
+-- identity rather than by email, which identity providers may let users change
+ALTER TABLE users
+    ADD COLUMN oidc_issuer VARCHAR(255) NULL AFTER password_reset_required,
+    ADD COLUMN oidc_subject VARCHAR(255) NULL AFTER oidc_issuer,
+    ADD UNIQUE KEY unique_user_oidc_identity (oidc_issuer, oidc_subject);
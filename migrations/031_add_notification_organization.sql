@@ -0,0 +1,17 @@
+-- Notifications belong to the organization of their project, so that each organization
+-- only lists the notifications of its own projects
+ALTER TABLE notifications
+    ADD COLUMN organization_id BIGINT NULL AFTER user_id;
+
+-- Existing notifications belong to the organization of their project; those without a
+-- project cannot be placed in an organization
+UPDATE notifications n
+JOIN projects p ON p.id = n.project_id
+SET n.organization_id = p.organization_id;
+
+DELETE FROM notifications WHERE organization_id IS NULL;
+
+ALTER TABLE notifications
+    MODIFY COLUMN organization_id BIGINT NOT NULL,
+    ADD INDEX idx_notifications_organization (user_id, organization_id, id),
+    ADD CONSTRAINT fk_notifications_organization FOREIGN KEY (organization_id) REFERENCES organizations(id) ON DELETE CASCADE;